@@ -0,0 +1,135 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/output"
+)
+
+// itemsReader generates a list response of n items as it is read, so the
+// response itself is never held in memory.
+type itemsReader struct {
+	n, next int
+	buf     bytes.Buffer
+	done    bool
+}
+
+func newItemsReader(n int) *itemsReader {
+	r := &itemsReader{n: n}
+	r.buf.WriteString(`{"total": ` + fmt.Sprint(n) + `, "items": [`)
+	return r
+}
+
+func (r *itemsReader) Read(p []byte) (int, error) {
+	for r.buf.Len() < len(p) && !r.done {
+		if r.next == r.n {
+			r.buf.WriteString("]}")
+			r.done = true
+			break
+		}
+		if r.next > 0 {
+			r.buf.WriteByte(',')
+		}
+		fmt.Fprintf(&r.buf, `{"id": %d, "name": "Item %d", "description": "This is a test description for item %d", "tags": ["tag1", "tag2"], "metadata": {"version": 1}}`, r.next, r.next, r.next)
+		r.next++
+	}
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
+
+// peakHeap runs f and returns the highest heap in use while it ran,
+// above the heap in use before.
+func peakHeap(f func()) uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	base := stats.HeapInuse
+
+	var peak atomic.Uint64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		var s runtime.MemStats
+		for {
+			runtime.ReadMemStats(&s)
+			if s.HeapInuse > base && s.HeapInuse-base > peak.Load() {
+				peak.Store(s.HeapInuse - base)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	f()
+	close(stop)
+	wg.Wait()
+	return peak.Load()
+}
+
+// BenchmarkOutputStreaming compares the peak heap of formatting large list
+// responses whole, as they are read into memory, and streamed. The peak
+// heap of buffered output grows with the response; streamed output stays
+// bounded.
+func BenchmarkOutputStreaming(b *testing.B) {
+	config := output.NewFormatConfig().WithColors(false).WithOutputConfig(&openapi.CLIOutput{
+		Stream: &openapi.OutputStream{ItemsPath: "items"},
+	})
+
+	for _, size := range []int{10000, 100000} {
+		for _, format := range []string{"json", "jsonl", "csv", "table"} {
+			b.Run(fmt.Sprintf("buffered/%s/%d", format, size), func(b *testing.B) {
+				manager := output.NewManager()
+				var peak uint64
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					peak = max(peak, peakHeap(func() {
+						data, err := io.ReadAll(newItemsReader(size))
+						if err != nil {
+							b.Fatal(err)
+						}
+						var result map[string]interface{}
+						if err := json.Unmarshal(data, &result); err != nil {
+							b.Fatal(err)
+						}
+						if err := manager.FormatWithConfig(io.Discard, result["items"], format, config); err != nil {
+							b.Fatalf("failed to format %s: %v", format, err)
+						}
+					}))
+				}
+				b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+			})
+
+			b.Run(fmt.Sprintf("streamed/%s/%d", format, size), func(b *testing.B) {
+				manager := output.NewManager()
+				var peak uint64
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					peak = max(peak, peakHeap(func() {
+						if err := manager.FormatStream(io.Discard, newItemsReader(size), format, config); err != nil {
+							b.Fatalf("failed to stream %s: %v", format, err)
+						}
+					}))
+				}
+				b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+			})
+		}
+	}
+}
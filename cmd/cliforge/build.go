@@ -40,6 +40,7 @@ func newBuildCmd() *cobra.Command {
 		platforms     []string
 		allPlatforms  bool
 		skipChecksums bool
+		embedSpec     bool
 	)
 
 	cmd := &cobra.Command{
@@ -50,9 +51,19 @@ func newBuildCmd() *cobra.Command {
 This command:
   1. Loads and validates your CLI configuration
   2. Fetches and validates the OpenAPI specification
-  3. Embeds configuration into the binary
+  3. Embeds configuration, and the bundled spec, into the binary
   4. Compiles the CLI for specified platforms
-  5. Generates checksums for verification`,
+  5. Generates checksums for verification
+
+The embedded spec has every $ref resolved, so the CLI works offline when
+api.openapi_url is unreachable. CLIs composing api.specs embed each spec.
+Disable it with --embed-spec=false.
+
+With api.signing, the CLI refuses specs not signed with one of its
+public keys. Create a key with "cliforge build keygen" and sign specs
+with "cliforge build sign-spec". The embedded spec is not checked: it is
+trusted because it was bundled at build time, and a refused spec falls
+back to it with a warning.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			verbose, _ := cmd.Flags().GetBool("verbose")
 			debug, _ := cmd.Flags().GetBool("debug")
@@ -75,16 +86,56 @@ This command:
 				return fmt.Errorf("config validation failed: %w", err)
 			}
 
-			// Load OpenAPI spec
-			fmt.Println("Loading OpenAPI specification...")
-			spec, err := loadOpenAPISpec(ctx, config.API.OpenAPIURL, verbose)
-			if err != nil {
-				return fmt.Errorf("failed to load OpenAPI spec: %w", err)
-			}
+			// Load OpenAPI specs
+			var specData []byte
+			if len(config.API.Specs) > 0 {
+				fmt.Println("Loading OpenAPI specifications...")
+				specs := make([]*openapi.ParsedSpec, len(config.API.Specs))
+				bundled := make(map[string][]byte, len(config.API.Specs))
+				for i, apiSpec := range config.API.Specs {
+					if embedSpec {
+						// Each bundled spec is embedded, so it is the one checked
+						bundler := openapi.NewBundler()
+						bundler.CacheDir = defaultBundleCacheDir()
+						bundled[apiSpec.Name], specs[i], err = bundleSpec(ctx, bundler, apiSpec.OpenAPIURL, true)
+					} else {
+						specs[i], err = loadOpenAPISpec(ctx, apiSpec.OpenAPIURL, verbose)
+					}
+					if err != nil {
+						return fmt.Errorf("failed to load OpenAPI spec %s: %w", apiSpec.Name, err)
+					}
+					if verbose {
+						info := specs[i].GetInfo()
+						fmt.Printf("✓ Loaded OpenAPI spec %s: %s v%s\n", apiSpec.Name, info.Title, info.Version)
+					}
+				}
+				if err := validateComposition(config, specs); err != nil {
+					return err
+				}
+				if embedSpec {
+					if specData, err = runtime.EncodeEmbeddedSpecs(bundled); err != nil {
+						return err
+					}
+				}
+			} else {
+				fmt.Println("Loading OpenAPI specification...")
+				var spec *openapi.ParsedSpec
+				if embedSpec {
+					// The bundled spec is embedded, so it is the one checked
+					bundler := openapi.NewBundler()
+					bundler.CacheDir = defaultBundleCacheDir()
+					specData, spec, err = bundleSpec(ctx, bundler, config.API.OpenAPIURL, true)
+				} else {
+					spec, err = loadOpenAPISpec(ctx, config.API.OpenAPIURL, verbose)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to load OpenAPI spec: %w", err)
+				}
 
-			if verbose {
-				info := spec.GetInfo()
-				fmt.Printf("✓ Loaded OpenAPI spec: %s v%s\n", info.Title, info.Version)
+				if verbose {
+					info := spec.GetInfo()
+					fmt.Printf("✓ Loaded OpenAPI spec: %s v%s\n", info.Title, info.Version)
+				}
 			}
 
 			// Determine build targets
@@ -107,7 +158,7 @@ This command:
 
 			// Generate runtime code
 			fmt.Println("Generating runtime code...")
-			buildDir, err := generateRuntimeCode(config, debug, verbose)
+			buildDir, err := generateRuntimeCode(config, specData, debug, verbose)
 			if err != nil {
 				return fmt.Errorf("failed to generate runtime: %w", err)
 			}
@@ -159,6 +210,11 @@ This command:
 	cmd.Flags().StringSliceVarP(&platforms, "platform", "p", nil, "Target platforms (e.g., linux/amd64,darwin/arm64)")
 	cmd.Flags().BoolVarP(&allPlatforms, "all", "a", false, "Build for all supported platforms")
 	cmd.Flags().BoolVar(&skipChecksums, "skip-checksums", false, "Skip checksum generation")
+	cmd.Flags().BoolVar(&embedSpec, "embed-spec", true, "Embed the bundled spec as a fallback, trusted without signature checks")
+
+	// Signing helpers for CLIs built with api.signing
+	cmd.AddCommand(newSignSpecCmd())
+	cmd.AddCommand(newKeygenCmd())
 
 	return cmd
 }
@@ -198,7 +254,7 @@ func getBuildTargets(platforms []string, allPlatforms bool) ([]buildTarget, erro
 	return targets, nil
 }
 
-func generateRuntimeCode(config *cli.Config, debug, verbose bool) (string, error) {
+func generateRuntimeCode(config *cli.Config, specData []byte, debug, verbose bool) (string, error) {
 	// Create temporary build directory
 	buildDir, err := os.MkdirTemp("", "cliforge-build-*")
 	if err != nil {
@@ -214,6 +270,7 @@ func generateRuntimeCode(config *cli.Config, debug, verbose bool) (string, error
 
 	// Generate main.go using runtime template
 	generator := runtime.NewGenerator(config)
+	generator.EmbedSpec = specData != nil
 	mainCode, err := generator.GenerateMain(configData)
 	if err != nil {
 		_ = os.RemoveAll(buildDir)
@@ -235,6 +292,14 @@ func generateRuntimeCode(config *cli.Config, debug, verbose bool) (string, error
 		return "", fmt.Errorf("failed to write embedded config: %w", err)
 	}
 
+	// Write the bundled spec for embedding
+	if specData != nil {
+		if err := os.WriteFile(filepath.Join(buildDir, "spec_embedded.json"), specData, 0644); err != nil {
+			_ = os.RemoveAll(buildDir)
+			return "", fmt.Errorf("failed to write embedded spec: %w", err)
+		}
+	}
+
 	// Initialize go.mod
 	if err := initGoModule(buildDir, config.Metadata.Name, verbose); err != nil {
 		_ = os.RemoveAll(buildDir)
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+)
+
+func newBundleCmd() *cobra.Command {
+	var (
+		outputPath string
+		format     string
+		cacheDir   string
+		refresh    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bundle <spec>",
+		Short: "Bundle a multi-file OpenAPI spec into one self-contained file",
+		Long: `Resolve every external $ref of an OpenAPI spec, in files or over HTTP,
+and write one self-contained spec.
+
+Referenced schemas, parameters, responses and other reusable objects
+become components, once each, and identical ones are merged. Path items
+are inlined. x-cli-* extensions are kept.
+
+Remote documents are cached, so a spec bundles offline once its
+references have been fetched. Use --refresh to fetch them again.
+
+Examples:
+  cliforge bundle openapi.yaml -o dist/openapi.yaml
+  cliforge bundle https://api.example.com/openapi.yaml --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = "yaml"
+				if strings.EqualFold(filepath.Ext(outputPath), ".json") {
+					format = "json"
+				}
+			}
+			if format != "yaml" && format != "json" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
+			bundler := openapi.NewBundler()
+			bundler.CacheDir = cacheDir
+			bundler.Refresh = refresh
+
+			data, _, err := bundleSpec(cmd.Context(), bundler, args[0], format == "json")
+			if err != nil {
+				return err
+			}
+
+			if outputPath == "" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(outputPath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write bundled spec: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ Bundled %s into %s\n", args[0], outputPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Output format: yaml or json (default: from the output file, else yaml)")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", defaultBundleCacheDir(), "Directory caching remote $ref documents")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Fetch remote $ref documents even when cached")
+
+	return cmd
+}
+
+// bundleSpec bundles a spec and parses the result, checking it is valid
+// on its own.
+func bundleSpec(ctx context.Context, bundler *openapi.Bundler, location string, asJSON bool) ([]byte, *openapi.ParsedSpec, error) {
+	data, err := bundler.BundleFile(ctx, location, asJSON)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to bundle spec: %w", err)
+	}
+	spec, err := openapi.NewParser().Parse(ctx, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bundled spec is invalid: %w", err)
+	}
+	return data, spec, nil
+}
+
+// defaultBundleCacheDir returns where remote $ref documents are cached.
+func defaultBundleCacheDir() string {
+	return filepath.Join(xdg.CacheHome, "cliforge", "refs")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the diff command, for gating CI on spec changes.
+const (
+	// diffExitChanges is returned when changes fail the --fail-on gate.
+	diffExitChanges = 2
+)
+
+func newDiffCmd() *cobra.Command {
+	var (
+		format string
+		failOn string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old-spec> <new-spec>",
+		Short: "Report how a new OpenAPI spec changes the generated CLI",
+		Long: `Compare two versions of an OpenAPI spec and report how the generated
+CLI changes for its users: commands removed or renamed, flags removed,
+renamed or retyped, and required flags added.
+
+Specs may be file paths or URLs. Commands are matched by operationId and
+flags by the parameter or body property they are generated from.
+
+Exit codes:
+  0  no changes failing the --fail-on gate
+  1  the specs could not be loaded or compared
+  2  changes fail the --fail-on gate
+
+Examples:
+  # Fail a CI job when a spec change breaks CLI users
+  cliforge diff main/openapi.yaml openapi.yaml
+
+  # Post the changes on a pull request
+  cliforge diff --format markdown --fail-on none old.yaml new.yaml`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch failOn {
+			case "breaking", "any", "none":
+			default:
+				return fmt.Errorf("invalid --fail-on %q: must be breaking, any or none", failOn)
+			}
+
+			loader := openapi.NewLoader(nil)
+			oldSpec, err := loader.Load(cmd.Context(), args[0], nil)
+			if err != nil {
+				return fmt.Errorf("failed to load old spec: %w", err)
+			}
+			newSpec, err := loader.Load(cmd.Context(), args[1], nil)
+			if err != nil {
+				return fmt.Errorf("failed to load new spec: %w", err)
+			}
+
+			changes, err := builder.DiffCommands(oldSpec, newSpec, &builder.BuilderConfig{
+				GroupByTags:             true,
+				FlattenSingleOperations: true,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to compare specs: %w", err)
+			}
+
+			if err := writeDiff(cmd.OutOrStdout(), format, changes); err != nil {
+				return err
+			}
+
+			if (failOn == "breaking" && builder.HasBreakingChanges(changes)) || (failOn == "any" && len(changes) > 0) {
+				return &exitError{code: diffExitChanges, err: fmt.Errorf("spec changes fail the %s gate", failOn)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, markdown)")
+	cmd.Flags().StringVar(&failOn, "fail-on", "breaking", "Changes that exit with code 2 (breaking, any, none)")
+
+	return cmd
+}
+
+// writeDiff writes the changes in the given format.
+func writeDiff(w io.Writer, format string, changes []*builder.CommandChange) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, formatDiffText(changes))
+		return err
+	case "markdown", "md":
+		_, err := io.WriteString(w, formatDiffMarkdown(changes))
+		return err
+	case "json":
+		if changes == nil {
+			changes = []*builder.CommandChange{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Breaking bool                     `json:"breaking"`
+			Changes  []*builder.CommandChange `json:"changes"`
+		}{builder.HasBreakingChanges(changes), changes})
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// formatDiffText lists breaking changes first, like openapi.FormatChangelog.
+func formatDiffText(changes []*builder.CommandChange) string {
+	if len(changes) == 0 {
+		return "No CLI changes detected\n"
+	}
+
+	var sb strings.Builder
+	breaking, other := splitBreaking(changes)
+	if len(breaking) > 0 {
+		sb.WriteString("BREAKING CHANGES:\n")
+		for _, change := range breaking {
+			fmt.Fprintf(&sb, "  - %s\n", change)
+		}
+	}
+	if len(other) > 0 {
+		if len(breaking) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("OTHER CHANGES:\n")
+		for _, change := range other {
+			fmt.Fprintf(&sb, "  - %s\n", change)
+		}
+	}
+	return sb.String()
+}
+
+// formatDiffMarkdown formats the changes for a pull request comment.
+func formatDiffMarkdown(changes []*builder.CommandChange) string {
+	var sb strings.Builder
+	sb.WriteString("## CLI changes\n\n")
+	if len(changes) == 0 {
+		sb.WriteString("No CLI changes detected.\n")
+		return sb.String()
+	}
+
+	breaking, other := splitBreaking(changes)
+	if len(breaking) > 0 {
+		sb.WriteString("### Breaking changes\n\n")
+		for _, change := range breaking {
+			fmt.Fprintf(&sb, "- %s\n", change)
+		}
+		sb.WriteString("\n")
+	}
+	if len(other) > 0 {
+		sb.WriteString("### Other changes\n\n")
+		for _, change := range other {
+			fmt.Fprintf(&sb, "- %s\n", change)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// splitBreaking splits changes into breaking and other changes.
+func splitBreaking(changes []*builder.CommandChange) (breaking, other []*builder.CommandChange) {
+	for _, change := range changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		} else {
+			other = append(other, change)
+		}
+	}
+	return breaking, other
+}
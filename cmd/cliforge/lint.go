@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// lintExitFindings is returned when findings fail the --fail-on gate.
+const lintExitFindings = 2
+
+func newLintCmd() *cobra.Command {
+	var (
+		format string
+		rules  []string
+		failOn string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint <spec>",
+		Short: "Check how an OpenAPI spec will look as a CLI",
+		Long: `Check an OpenAPI spec for problems in the CLI generated from it.
+
+Rules:
+  operation-id-missing      operations without an operationId (error)
+  operation-id-unstable     operationIds generated from the path (warning)
+  command-collision         operations producing the same command (error)
+  global-flag-collision     flags shadowing global flags (error)
+  flag-name-collision       parameters becoming the same flag (error)
+  enum-description          enums without a description (warning)
+  destructive-confirmation  DELETE without x-cli-confirmation (warning)
+  async-terminal-states     x-cli-async without terminal-states (error)
+
+Severities are error, warning, info or off. Set them with --rule, or in
+the spec:
+
+  x-cli-lint:
+    rules:
+      enum-description: off
+
+Silence a rule for the spec, an operation, a parameter or a schema with
+x-cli-lint-ignore, set to a rule ID, a list of them, or true.
+
+Exit codes:
+  0  no findings failing the --fail-on gate
+  1  the spec could not be loaded or linted
+  2  findings fail the --fail-on gate
+
+Examples:
+  cliforge lint openapi.yaml
+  cliforge lint --rule enum-description=error --rule operation-id-unstable=off openapi.yaml
+  cliforge lint --format sarif openapi.yaml > cliforge.sarif`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var gate builder.LintSeverity
+			switch failOn {
+			case "error", "warning", "info":
+				gate = builder.LintSeverity(failOn)
+			case "none":
+			default:
+				return fmt.Errorf("invalid --fail-on %q: must be error, warning, info or none", failOn)
+			}
+
+			linter := builder.NewLinter(&builder.BuilderConfig{
+				GroupByTags:             true,
+				FlattenSingleOperations: true,
+			})
+			for _, rule := range rules {
+				id, name, ok := strings.Cut(rule, "=")
+				if !ok {
+					return fmt.Errorf("invalid --rule %q: must be <rule>=<severity>", rule)
+				}
+				severity, err := builder.ParseLintSeverity(name)
+				if err != nil {
+					return fmt.Errorf("invalid --rule %q: %w", rule, err)
+				}
+				linter.Severities[id] = severity
+			}
+
+			specPath := args[0]
+			spec, err := openapi.NewLoader(nil).Load(cmd.Context(), specPath, nil)
+			if err != nil {
+				return fmt.Errorf("failed to load spec: %w", err)
+			}
+
+			findings, err := linter.Lint(spec)
+			if err != nil {
+				return fmt.Errorf("failed to lint spec: %w", err)
+			}
+
+			lines := newSpecLines(specPath)
+			if err := writeLint(cmd.OutOrStdout(), format, specPath, findings, lines); err != nil {
+				return err
+			}
+
+			if gate != "" && builder.HasLintFindings(findings, gate) {
+				return &exitError{code: lintExitFindings, err: fmt.Errorf("lint findings fail the %s gate", failOn)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, sarif)")
+	cmd.Flags().StringArrayVar(&rules, "rule", nil, "Set a rule severity as <rule>=<error|warning|info|off> (repeatable)")
+	cmd.Flags().StringVar(&failOn, "fail-on", "error", "Lowest severity that exits with code 2 (error, warning, info, none)")
+
+	return cmd
+}
+
+// writeLint writes the findings in the given format.
+func writeLint(w io.Writer, format, specPath string, findings []*builder.LintFinding, lines *specLines) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, formatLintText(specPath, findings, lines))
+		return err
+	case "json":
+		if findings == nil {
+			findings = []*builder.LintFinding{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Findings []*builder.LintFinding `json:"findings"`
+		}{findings})
+	case "sarif":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(lintSARIF(specPath, findings, lines))
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// formatLintText lists the findings like compiler diagnostics, in line
+// order at their line in the spec or else their JSON pointer, followed by
+// a count per severity.
+func formatLintText(specPath string, findings []*builder.LintFinding, lines *specLines) string {
+	if len(findings) == 0 {
+		return "No lint findings\n"
+	}
+
+	ordered := make([]*builder.LintFinding, len(findings))
+	copy(ordered, findings)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, _ := lines.position(ordered[i].Pointer)
+		b, _ := lines.position(ordered[j].Pointer)
+		return a < b
+	})
+
+	var sb strings.Builder
+	counts := make(map[builder.LintSeverity]int)
+	for _, finding := range ordered {
+		location := specPath + "#" + finding.Pointer
+		if line, column := lines.position(finding.Pointer); line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", specPath, line, column)
+		}
+		fmt.Fprintf(&sb, "%s: %s: %s [%s]\n", location, finding.Severity, finding.Message, finding.Rule)
+		counts[finding.Severity]++
+	}
+
+	var summary []string
+	for _, severity := range []builder.LintSeverity{builder.LintError, builder.LintWarning, builder.LintInfo} {
+		if counts[severity] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[severity], pluralSeverity(severity, counts[severity])))
+		}
+	}
+	fmt.Fprintf(&sb, "\n%s\n", strings.Join(summary, ", "))
+	return sb.String()
+}
+
+// pluralSeverity names count findings of a severity.
+func pluralSeverity(severity builder.LintSeverity, count int) string {
+	if severity == builder.LintInfo || count == 1 {
+		return string(severity)
+	}
+	return string(severity) + "s"
+}
+
+// SARIF 2.1.0 log, with the parts lint results use.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// lintSARIF converts the findings to a SARIF log for code scanning tools.
+func lintSARIF(specPath string, findings []*builder.LintFinding, lines *specLines) *sarifLog {
+	driver := sarifDriver{
+		Name:           "cliforge",
+		Version:        version,
+		InformationURI: "https://github.com/CliForge/cliforge",
+	}
+	ruleIndex := make(map[string]int)
+	for i, rule := range builder.LintRules() {
+		ruleIndex[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity)},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: strings.TrimPrefix(specPath, "./")},
+			},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: finding.Pointer}},
+		}
+		if line, column := lines.position(finding.Pointer); line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+		}
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: ruleIndex[finding.Rule],
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	return &sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// sarifLevel converts a severity to a SARIF level.
+func sarifLevel(severity builder.LintSeverity) string {
+	switch severity {
+	case builder.LintError:
+		return "error"
+	case builder.LintWarning:
+		return "warning"
+	case builder.LintInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// specLines finds the lines of JSON pointers in a local spec file.
+type specLines struct {
+	root *yaml.Node
+}
+
+// newSpecLines reads a local spec file. Specs that are URLs or cannot be
+// read have no lines.
+func newSpecLines(specPath string) *specLines {
+	lines := &specLines{}
+	if isURL(specPath) {
+		return lines
+	}
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return lines
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+	lines.root = doc.Content[0]
+	return lines
+}
+
+// position returns the line and column of the object at pointer, or of
+// the deepest object on its way that is in the file. It returns 0 when
+// the spec has no lines.
+func (l *specLines) position(pointer string) (int, int) {
+	if l == nil || l.root == nil {
+		return 0, 0
+	}
+
+	node := l.root
+	line, column := node.Line, node.Column
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					line, column = node.Content[i].Line, node.Content[i].Column
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line, column = next.Line, next.Column
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line, column
+}
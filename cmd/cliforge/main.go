@@ -9,6 +9,10 @@
 //   - init: Initialize a new CLI project with configuration templates
 //   - build: Generate and compile a CLI binary from config and spec
 //   - validate: Validate OpenAPI spec and CLI configuration
+//   - diff: Report how a new OpenAPI spec changes the generated CLI
+//   - bundle: Resolve a multi-file spec into one self-contained file
+//   - lint: Check how an OpenAPI spec will look as a CLI
+//   - mock: Serve an OpenAPI spec as a local mock API
 //
 // # Example Usage
 //
@@ -31,6 +35,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -45,10 +50,29 @@ var (
 func main() {
 	if err := newRootCmd().Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitError is an error that exits with a code other than 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func newRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cliforge",
@@ -70,6 +94,11 @@ enterprise features out of the box.`,
 	cmd.AddCommand(newInitCmd())
 	cmd.AddCommand(newBuildCmd())
 	cmd.AddCommand(newValidateCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newBundleCmd())
+	cmd.AddCommand(newLintCmd())
+	cmd.AddCommand(newMockCmd())
+	cmd.AddCommand(newWorkflowCmd())
 
 	return cmd
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/CliForge/cliforge/internal/mock"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+func newMockCmd() *cobra.Command {
+	var (
+		specPath      string
+		addr          string
+		asyncSteps    int
+		watchInterval time.Duration
+		quiet         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Serve an OpenAPI spec as a local mock API",
+		Long: `Serve an OpenAPI spec as a local mock API, so the generated CLI can be
+demoed and tested without a backend.
+
+Responses come from the spec's examples, or are synthesised from its
+schemas. Choose another response with the Prefer header:
+
+  Prefer: code=404           the 404 response
+  Prefer: example=soldOut    the named example
+  Prefer: dynamic=true       synthesised from the schema
+  Prefer: terminal=error     the terminal state of an async operation
+
+Resources identified by a path parameter, like /pets/{petId} of /pets,
+are kept: created items can be listed, read, updated and deleted.
+
+x-cli-async operations stay pending for --async-steps reads of their
+status endpoint, then reach their first terminal state. x-cli-watch
+endpoints of type sse or websocket stream the watched resource.
+
+A local spec is also served at /<file name>, for CLIs whose
+api.openapi_url points at the mock.
+
+Examples:
+  cliforge mock --spec openapi.yaml
+  cliforge mock --spec openapi.yaml --addr :8080 --async-steps 1
+  curl -H 'Prefer: code=404' http://127.0.0.1:4010/pets/1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := openapi.NewLoader(nil).Load(cmd.Context(), specPath, nil)
+			if err != nil {
+				return fmt.Errorf("failed to load spec: %w", err)
+			}
+
+			opts := &mock.Options{
+				AsyncSteps:    asyncSteps,
+				WatchInterval: watchInterval,
+			}
+			if !quiet {
+				opts.Log = cmd.ErrOrStderr()
+			}
+			// Serve a local spec, for CLIs whose openapi_url is the mock
+			if !isURL(specPath) {
+				if opts.SpecData, err = os.ReadFile(specPath); err != nil {
+					return fmt.Errorf("failed to read spec: %w", err)
+				}
+				opts.SpecPath = "/" + filepath.Base(specPath)
+			}
+
+			server, err := mock.NewServer(spec, opts)
+			if err != nil {
+				return fmt.Errorf("failed to create mock server: %w", err)
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			httpServer := &http.Server{Handler: server, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = httpServer.Shutdown(shutdownCtx)
+			}()
+
+			info := spec.GetInfo()
+			fmt.Fprintf(cmd.ErrOrStderr(), "Mocking %s %s on http://%s (Ctrl+C to stop)\n", info.Title, info.Version, listener.Addr())
+			if opts.SpecPath != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Spec served at http://%s%s\n", listener.Addr(), opts.SpecPath)
+			}
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("mock server failed: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&specPath, "spec", "s", "", "OpenAPI spec file or URL (required)")
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:4010", "Address to listen on")
+	cmd.Flags().IntVar(&asyncSteps, "async-steps", 3, "Status reads before an async operation reaches a terminal state")
+	cmd.Flags().DurationVar(&watchInterval, "watch-interval", time.Second, "Time between watch events")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Do not log requests")
+	_ = cmd.MarkFlagRequired("spec")
+
+	return cmd
+}
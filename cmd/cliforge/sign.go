@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// signingKeyEnv holds the private key when --key is not given, so CI can
+// sign specs from a secret.
+const signingKeyEnv = "CLIFORGE_SPEC_SIGNING_KEY"
+
+func newSignSpecCmd() *cobra.Command {
+	var (
+		keyPath    string
+		outputPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sign-spec <spec>...",
+		Short: "Sign OpenAPI specs for CLIs that require signed specs",
+		Long: `Sign OpenAPI specs with an Ed25519 private key, writing each signature
+next to its spec with ".sig" appended.
+
+Publish the signature next to the spec, or send it in the
+X-Spec-Signature response header. CLIs built with api.signing refuse
+specs without a signature from one of its public keys.
+
+Sign the exact file that is served: any change, even to whitespace,
+invalidates the signature.
+
+The key is read from --key, or from $` + signingKeyEnv + `.
+
+Examples:
+  cliforge build keygen -o spec-signing.pem
+  cliforge build sign-spec --key spec-signing.pem openapi.yaml`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputPath != "" && len(args) > 1 {
+				return fmt.Errorf("--output can only be used with a single spec")
+			}
+
+			key, err := loadSigningKey(keyPath)
+			if err != nil {
+				return err
+			}
+
+			for _, specPath := range args {
+				data, err := os.ReadFile(specPath)
+				if err != nil {
+					return fmt.Errorf("failed to read spec: %w", err)
+				}
+
+				sigPath := outputPath
+				if sigPath == "" {
+					sigPath = specPath + openapi.SignatureSuffix
+				}
+				if err := os.WriteFile(sigPath, []byte(openapi.SignSpec(data, key)+"\n"), 0644); err != nil {
+					return fmt.Errorf("failed to write signature: %w", err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "✓ Signed %s into %s\n", specPath, sigPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&keyPath, "key", "k", "", "Ed25519 private key file (default: $"+signingKeyEnv+")")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Signature file (default: <spec>.sig)")
+
+	return cmd
+}
+
+// loadSigningKey reads the private key from path, or from signingKeyEnv.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	key := os.Getenv(signingKeyEnv)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("no signing key: use --key or set %s", signingKeyEnv)
+	}
+	return openapi.ParsePrivateKey(key)
+}
+
+func newKeygenCmd() *cobra.Command {
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate a key pair for signing specs",
+		Long: `Generate an Ed25519 key pair for signing specs.
+
+The private key is written to --output. Keep it secret: whoever holds it
+can sign specs the CLI accepts. The public key is printed, ready for the
+api.signing section of cli-config.yaml.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(outputPath); err == nil {
+				return fmt.Errorf("%s already exists", outputPath)
+			}
+
+			publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return fmt.Errorf("failed to generate key: %w", err)
+			}
+			der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+			if err != nil {
+				return fmt.Errorf("failed to encode key: %w", err)
+			}
+			keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+			if err := os.WriteFile(outputPath, keyPEM, 0600); err != nil {
+				return fmt.Errorf("failed to write key: %w", err)
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ Wrote private key to %s\n\n", outputPath)
+			fmt.Fprintf(cmd.OutOrStdout(), "api:\n  signing:\n    public_keys:\n      - %s\n", base64.StdEncoding.EncodeToString(publicKey))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "spec-signing.pem", "Private key file")
+
+	return cmd
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/CliForge/cliforge/internal/builder"
 	"github.com/CliForge/cliforge/pkg/cli"
 	"github.com/CliForge/cliforge/pkg/config"
 	"github.com/CliForge/cliforge/pkg/openapi"
@@ -49,12 +50,30 @@ This command checks:
 			}
 			fmt.Println("✓ Configuration is valid")
 
-			// Validate OpenAPI spec
-			fmt.Println("\nValidating OpenAPI specification...")
-			if err := validateOpenAPISpec(config.API.OpenAPIURL, verbose, debug); err != nil {
-				return fmt.Errorf("OpenAPI validation failed: %w", err)
+			// Validate OpenAPI specs
+			if len(config.API.Specs) > 0 {
+				fmt.Println("\nValidating OpenAPI specifications...")
+				specs := make([]*openapi.ParsedSpec, len(config.API.Specs))
+				for i, apiSpec := range config.API.Specs {
+					if verbose {
+						fmt.Printf("  Spec %s:\n", apiSpec.Name)
+					}
+					specs[i], err = validateOpenAPISpec(apiSpec.OpenAPIURL, verbose, debug)
+					if err != nil {
+						return fmt.Errorf("OpenAPI validation failed for spec %s: %w", apiSpec.Name, err)
+					}
+				}
+				if err := validateComposition(config, specs); err != nil {
+					return fmt.Errorf("OpenAPI validation failed: %w", err)
+				}
+				fmt.Printf("✓ %d OpenAPI specifications are valid and compose without conflicts\n", len(specs))
+			} else {
+				fmt.Println("\nValidating OpenAPI specification...")
+				if _, err := validateOpenAPISpec(config.API.OpenAPIURL, verbose, debug); err != nil {
+					return fmt.Errorf("OpenAPI validation failed: %w", err)
+				}
+				fmt.Println("✓ OpenAPI specification is valid")
 			}
-			fmt.Println("✓ OpenAPI specification is valid")
 
 			fmt.Println("\n✓ All validations passed")
 			return nil
@@ -85,7 +104,7 @@ func validateConfig(cfg *cli.Config) error {
 	return validator.Validate(cfg)
 }
 
-func validateOpenAPISpec(specPath string, verbose, debug bool) error {
+func validateOpenAPISpec(specPath string, verbose, debug bool) (*openapi.ParsedSpec, error) {
 	ctx := context.Background()
 	parser := openapi.NewParser()
 
@@ -114,7 +133,7 @@ func validateOpenAPISpec(specPath string, verbose, debug bool) error {
 	}
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	info := spec.GetInfo()
@@ -128,14 +147,35 @@ func validateOpenAPISpec(specPath string, verbose, debug bool) error {
 	// Get operations count
 	operations, err := spec.GetOperations()
 	if err != nil {
-		return fmt.Errorf("failed to parse operations: %w", err)
+		return nil, fmt.Errorf("failed to parse operations: %w", err)
 	}
 
 	if verbose {
 		fmt.Printf("  Operations: %d\n", len(operations))
 	}
 
-	return nil
+	return spec, nil
+}
+
+// validateComposition checks that the specs of a config compose into one
+// command tree without conflicting commands or operationIds.
+func validateComposition(cfg *cli.Config, specs []*openapi.ParsedSpec) error {
+	mounted := make([]*builder.MountedSpec, len(cfg.API.Specs))
+	for i, apiSpec := range cfg.API.Specs {
+		mounted[i] = &builder.MountedSpec{
+			Name:  apiSpec.Name,
+			Mount: apiSpec.Mount,
+			Spec:  specs[i],
+			Config: &builder.BuilderConfig{
+				GroupByTags: true,
+				Tags:        apiSpec.Tags,
+				ExcludeTags: apiSpec.ExcludeTags,
+			},
+		}
+	}
+
+	_, err := builder.NewComposedBuilder(mounted, &builder.BuilderConfig{RootName: cfg.Metadata.Name}).Build()
+	return err
 }
 
 func isURL(path string) bool {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CliForge/cliforge/pkg/cli/builtin"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+func newWorkflowCmd() *cobra.Command {
+	var specPath string
+
+	opts := &builtin.WorkflowOptions{
+		Output: os.Stdout,
+	}
+
+	cmd := builtin.NewWorkflowCommand(opts)
+	cmd.Long += `
+
+Use --spec to resolve test cases that reference x-cli-workflow operations:
+  cliforge workflow test --spec openapi.yaml workflows.test.yaml`
+
+	cmd.PersistentFlags().StringVarP(&specPath, "spec", "s", "", "Path to the OpenAPI spec defining the workflows")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if specPath == "" {
+			return nil
+		}
+
+		spec, err := openapi.NewParser().ParseFile(cmd.Context(), specPath)
+		if err != nil {
+			return fmt.Errorf("failed to load spec: %w", err)
+		}
+		opts.Spec = spec
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,1186 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DefaultBodyDepth is how many levels of nested request body objects get
+// dotted flags. Deeper objects are passed as JSON.
+const DefaultBodyDepth = 3
+
+// Flag annotations describing where a request body flag's value goes.
+const (
+	// bodyPathAnnotation holds the property names leading to the value.
+	bodyPathAnnotation = "body-path"
+
+	// bodyFormatAnnotation tells how the flag's value is parsed: "json",
+	// "map" for key=value pairs or "objects" for repeated objects. Scalars
+	// and arrays of scalars have no format.
+	bodyFormatAnnotation = "body-format"
+
+	// bodyTypeAnnotation holds the schema type of the value, or of the
+	// items of an array or map.
+	bodyTypeAnnotation = "body-type"
+
+	// bodyFieldsAnnotation holds "name=type" for each property of the
+	// objects of an "objects" flag.
+	bodyFieldsAnnotation = "body-fields"
+
+	// bodyVariantsAnnotation lists the discriminator values of the oneOf
+	// variants a flag belongs to.
+	bodyVariantsAnnotation = "body-variants"
+
+	// bodySelectorAnnotation names the flag that selects the variant.
+	bodySelectorAnnotation = "body-selector"
+
+	// bodyNullableAnnotation marks flags that send null for "null".
+	bodyNullableAnnotation = "body-nullable"
+
+	// bodyTupleAnnotation holds the schema types of the leading items of
+	// a tuple array, in order.
+	bodyTupleAnnotation = "body-tuple"
+
+	// bodyDefaultAnnotation holds the JSON value sent when the flag is not
+	// set, for required const properties.
+	bodyDefaultAnnotation = "body-default"
+)
+
+// Command annotations of variant subcommands.
+const (
+	variantAnnotation       = "bodyVariant"
+	discriminatorAnnotation = "bodyDiscriminator"
+)
+
+// Variant modes for oneOf and anyOf schemas with a discriminator.
+const (
+	// VariantsSelector adds a flag named after the discriminator property
+	// that selects the variant.
+	VariantsSelector = "selector"
+
+	// VariantsSubcommands adds a subcommand for each variant.
+	VariantsSubcommands = "subcommands"
+)
+
+// variantsExtension chooses the variant mode of a single schema.
+const variantsExtension = "x-cli-variants"
+
+// addRequestBodyFlags adds flags from request body schema.
+func (fb *FlagBuilder) addRequestBodyFlags(cmd *cobra.Command, requestBody *openapi3.RequestBody, cliFlags []*openapi.CLIFlag) error {
+	schema := requestBodySchema(requestBody)
+	if schema == nil {
+		return nil
+	}
+
+	w := fb.newBodyWalker(cmd, cliFlags)
+	return w.addObject(schema, nil, "", true, nil)
+}
+
+// requestBodySchema returns the flattened application/json schema of a
+// request body, or nil.
+func requestBodySchema(requestBody *openapi3.RequestBody) *openapi3.Schema {
+	content := requestBody.Content.Get("application/json")
+	if content == nil || content.Schema == nil || content.Schema.Value == nil {
+		return nil
+	}
+	return flattenSchema(content.Schema.Value)
+}
+
+// bodyWalker adds the flags of a request body schema to a command.
+type bodyWalker struct {
+	fb      *FlagBuilder
+	cmd     *cobra.Command
+	flagMap map[string]*openapi.CLIFlag
+}
+
+// newBodyWalker creates a walker honouring the x-cli-flags mappings of
+// body properties, keyed by their dotted path.
+func (fb *FlagBuilder) newBodyWalker(cmd *cobra.Command, cliFlags []*openapi.CLIFlag) *bodyWalker {
+	flagMap := make(map[string]*openapi.CLIFlag)
+	for _, cliFlag := range cliFlags {
+		if cliFlag.Source == "body" {
+			flagMap[cliFlag.Name] = cliFlag
+		}
+	}
+	return &bodyWalker{fb: fb, cmd: cmd, flagMap: flagMap}
+}
+
+// bodyProperty is a property to add a flag for.
+type bodyProperty struct {
+	path        []string
+	flagName    string
+	schema      *openapi3.Schema
+	description string
+	required    bool
+	variants    []string
+	selector    string
+}
+
+// addObject adds flags for the properties of an object at path. Property
+// flags are prefixed with prefix, and are required if the object is.
+func (w *bodyWalker) addObject(schema *openapi3.Schema, path []string, prefix string, required bool, variant *bodyVariant) error {
+	for _, name := range sortedProperties(schema) {
+		propSchema := schema.Properties[name].Value
+		if variant != nil && name == variant.discriminator {
+			continue
+		}
+
+		prop := &bodyProperty{
+			path:        appendPath(path, name),
+			flagName:    prefix + toFlagName(name),
+			schema:      propSchema,
+			description: propSchema.Description,
+			required:    required && variant == nil && containsString(schema.Required, name),
+		}
+		if variant != nil {
+			prop.variants = []string{variant.value}
+			prop.selector = variant.selector
+		}
+		if cliFlag, ok := w.flagMap[strings.Join(prop.path, ".")]; ok {
+			prop.flagName = cliFlag.Flag
+			prop.description = cliFlag.Description
+			prop.required = cliFlag.Required
+		}
+
+		if err := w.addProperty(prop); err != nil {
+			return fmt.Errorf("failed to add flag for property %s: %w", strings.Join(prop.path, "."), err)
+		}
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return w.addVariants(schema, path, prefix)
+	}
+	return nil
+}
+
+// addProperty adds the flag of a property, or the flags of its properties
+// if it is an object within the depth limit.
+func (w *bodyWalker) addProperty(prop *bodyProperty) error {
+	schema := flattenSchema(prop.schema)
+	if schema.ReadOnly {
+		return nil
+	}
+
+	if value, ok := openapi.SchemaConst(schema); ok {
+		return w.addConstFlag(prop, value)
+	}
+
+	switch valueType := schemaType(schema); valueType {
+	case "object":
+		expandable := len(schema.Properties) > 0 || len(schema.OneOf) > 0 || len(schema.AnyOf) > 0
+		if expandable && len(prop.path) < w.fb.bodyDepth {
+			if err := w.addObject(schema, prop.path, prop.flagName+".", prop.required, nil); err != nil {
+				return err
+			}
+			// Extra keys of an object with properties go in a map flag
+			if valueSchema, ok := mapValueSchema(schema); ok {
+				return w.addMapFlag(prop, valueSchema)
+			}
+			return nil
+		}
+		if valueSchema, ok := mapValueSchema(schema); ok && !expandable {
+			return w.addMapFlag(prop, valueSchema)
+		}
+		return w.addJSONFlag(prop, "JSON object")
+
+	case "array":
+		var items *openapi3.Schema
+		if schema.Items != nil && schema.Items.Value != nil {
+			items = flattenSchema(schema.Items.Value)
+		}
+		if items == nil {
+			break
+		}
+		if tuple := openapi.SchemaPrefixItems(schema); len(tuple) > 0 {
+			return w.addTupleFlag(prop, tuple, items)
+		}
+		switch itemType := schemaType(items); itemType {
+		case "object":
+			if len(items.Properties) == 0 {
+				return w.addJSONFlag(prop, "JSON array")
+			}
+			return w.addObjectsFlag(prop, items)
+		case "array":
+			return w.addJSONFlag(prop, "JSON array")
+		case "integer", "number", "boolean":
+			if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+				flags.StringArray(prop.flagName, nil, description)
+			}); err != nil {
+				return err
+			}
+			return w.annotate(prop.flagName, bodyTypeAnnotation, itemType)
+		}
+
+	case "string", "integer", "number", "boolean":
+		if openapi.SchemaNullable(schema) {
+			return w.addNullableFlag(prop, schema, valueType)
+		}
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return w.addJSONFlag(prop, "JSON value")
+	}
+
+	return w.define(prop, func(flags *pflag.FlagSet, description string) {
+		// addFlagFromSchema marks required flags itself
+		_ = w.fb.addFlagFromSchema(w.cmd, prop.flagName, &openapi3.SchemaRef{Value: schema}, description, false)
+	})
+}
+
+// addConstFlag adds a flag for a const property, defaulting to its only
+// allowed value. Required const properties are sent without the flag.
+func (w *bodyWalker) addConstFlag(prop *bodyProperty, value interface{}) error {
+	display := fmt.Sprintf("%v", value)
+	required := prop.required
+	prop.required = false
+
+	if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+		flags.String(prop.flagName, display, withHint(description, "constant"))
+	}); err != nil {
+		return err
+	}
+	if err := w.cmd.Flags().SetAnnotation(prop.flagName, "enum", []string{display}); err != nil {
+		return err
+	}
+	switch value.(type) {
+	case float64:
+		if err := w.annotate(prop.flagName, bodyTypeAnnotation, "number"); err != nil {
+			return err
+		}
+	case bool:
+		if err := w.annotate(prop.flagName, bodyTypeAnnotation, "boolean"); err != nil {
+			return err
+		}
+	case map[string]interface{}, []interface{}:
+		if err := w.annotate(prop.flagName, bodyFormatAnnotation, "json"); err != nil {
+			return err
+		}
+	}
+
+	if !required {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("invalid const value: %w", err)
+	}
+	return w.annotate(prop.flagName, bodyDefaultAnnotation, string(data))
+}
+
+// addNullableFlag adds a flag for a scalar that may be null, which takes
+// "null" to send null.
+func (w *bodyWalker) addNullableFlag(prop *bodyProperty, schema *openapi3.Schema, valueType string) error {
+	if valueType == "string" || len(schema.Enum) > 0 {
+		if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+			_ = w.fb.addFlagFromSchema(w.cmd, prop.flagName, &openapi3.SchemaRef{Value: schema}, withHint(description, "null for none"), false)
+		}); err != nil {
+			return err
+		}
+		if enum := w.cmd.Flags().Lookup(prop.flagName).Annotations["enum"]; enum != nil {
+			if err := w.cmd.Flags().SetAnnotation(prop.flagName, "enum", append(enum, "null")); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Typed flags cannot take "null", so the value is converted later
+		if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+			flags.String(prop.flagName, "", withHint(description, valueType+" or null"))
+		}); err != nil {
+			return err
+		}
+	}
+	if err := w.annotate(prop.flagName, bodyTypeAnnotation, valueType); err != nil {
+		return err
+	}
+	return w.annotate(prop.flagName, bodyNullableAnnotation, "true")
+}
+
+// addTupleFlag adds a repeatable flag for a tuple array, converting each
+// value to the type of its position.
+func (w *bodyWalker) addTupleFlag(prop *bodyProperty, tuple []*openapi3.Schema, items *openapi3.Schema) error {
+	types := make([]string, len(tuple))
+	for i, item := range tuple {
+		types[i] = schemaType(item)
+		if types[i] == "" {
+			types[i] = "string"
+		}
+	}
+
+	if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+		flags.StringArray(prop.flagName, nil, withHint(description, "repeat for each of: "+strings.Join(types, ", ")))
+	}); err != nil {
+		return err
+	}
+	if err := w.cmd.Flags().SetAnnotation(prop.flagName, bodyTupleAnnotation, types); err != nil {
+		return err
+	}
+	if itemType := schemaType(items); itemType != "" {
+		return w.annotate(prop.flagName, bodyTypeAnnotation, itemType)
+	}
+	return nil
+}
+
+// addJSONFlag adds a flag taking the property's value as JSON.
+func (w *bodyWalker) addJSONFlag(prop *bodyProperty, kind string) error {
+	if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+		flags.String(prop.flagName, "", withHint(description, kind))
+	}); err != nil {
+		return err
+	}
+	return w.annotate(prop.flagName, bodyFormatAnnotation, "json")
+}
+
+// addMapFlag adds a repeatable key=value flag for additionalProperties.
+func (w *bodyWalker) addMapFlag(prop *bodyProperty, valueSchema *openapi3.Schema) error {
+	if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+		flags.StringArray(prop.flagName, nil, withHint(description, "key=value, repeatable"))
+	}); err != nil {
+		return err
+	}
+	if err := w.annotate(prop.flagName, bodyFormatAnnotation, "map"); err != nil {
+		return err
+	}
+	return w.annotate(prop.flagName, bodyTypeAnnotation, schemaType(valueSchema))
+}
+
+// addObjectsFlag adds a repeatable flag for an array of objects, each
+// given as comma separated key=value pairs or as a JSON object.
+func (w *bodyWalker) addObjectsFlag(prop *bodyProperty, items *openapi3.Schema) error {
+	names := sortedProperties(items)
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, name+"="+schemaType(flattenSchema(items.Properties[name].Value)))
+	}
+
+	if err := w.define(prop, func(flags *pflag.FlagSet, description string) {
+		flags.StringArray(prop.flagName, nil, withHint(description, strings.Join(names, "=..,")+"=.., repeatable"))
+	}); err != nil {
+		return err
+	}
+	if err := w.annotate(prop.flagName, bodyFormatAnnotation, "objects"); err != nil {
+		return err
+	}
+	return w.cmd.Flags().SetAnnotation(prop.flagName, bodyFieldsAnnotation, fields)
+}
+
+// define adds a flag with add unless a variant already added it, records
+// where its value goes, and marks it required.
+func (w *bodyWalker) define(prop *bodyProperty, add func(flags *pflag.FlagSet, description string)) error {
+	flags := w.cmd.Flags()
+
+	if existing := flags.Lookup(prop.flagName); existing != nil {
+		// Variants sharing a property share its flag
+		if prop.selector != "" && strings.Join(existing.Annotations[bodyPathAnnotation], ".") == strings.Join(prop.path, ".") {
+			variants := append(existing.Annotations[bodyVariantsAnnotation], prop.variants...)
+			return flags.SetAnnotation(prop.flagName, bodyVariantsAnnotation, variants)
+		}
+		return fmt.Errorf("flag --%s is already defined", prop.flagName)
+	}
+
+	add(flags, prop.description)
+
+	if err := flags.SetAnnotation(prop.flagName, bodyPathAnnotation, prop.path); err != nil {
+		return err
+	}
+	if prop.selector != "" {
+		if err := flags.SetAnnotation(prop.flagName, bodyVariantsAnnotation, prop.variants); err != nil {
+			return err
+		}
+		if err := w.annotate(prop.flagName, bodySelectorAnnotation, prop.selector); err != nil {
+			return err
+		}
+	}
+
+	if w.cmd.Annotations == nil {
+		w.cmd.Annotations = make(map[string]string)
+	}
+	w.cmd.Annotations[fmt.Sprintf("body:%s", prop.flagName)] = strings.Join(prop.path, ".")
+
+	if prop.required {
+		if err := w.cmd.MarkFlagRequired(prop.flagName); err != nil {
+			return fmt.Errorf("failed to mark flag %s as required: %w", prop.flagName, err)
+		}
+	}
+	return nil
+}
+
+// annotate sets a single-valued flag annotation.
+func (w *bodyWalker) annotate(flagName, key, value string) error {
+	return w.cmd.Flags().SetAnnotation(flagName, key, []string{value})
+}
+
+// bodyVariant is one schema of a oneOf or anyOf.
+type bodyVariant struct {
+	value         string
+	schema        *openapi3.Schema
+	discriminator string
+	selector      string
+}
+
+// variantsOf returns the variants of a oneOf or anyOf schema and its
+// discriminator property, which is empty without a discriminator. A
+// variant is named by its discriminator mapping, the single enum value of
+// its discriminator property, or its schema name, in that order.
+func variantsOf(schema *openapi3.Schema) (string, []*bodyVariant) {
+	choices := schema.OneOf
+	if len(choices) == 0 {
+		choices = schema.AnyOf
+	}
+
+	discriminator := ""
+	if schema.Discriminator != nil {
+		discriminator = schema.Discriminator.PropertyName
+	}
+
+	var variants []*bodyVariant
+	for i, ref := range choices {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		v := &bodyVariant{schema: flattenSchema(ref.Value), discriminator: discriminator}
+
+		if schema.Discriminator != nil {
+			for value, target := range schema.Discriminator.Mapping {
+				if target == ref.Ref || (ref.Ref != "" && schemaName(target) == schemaName(ref.Ref)) {
+					v.value = value
+					break
+				}
+			}
+		}
+		if v.value == "" && discriminator != "" {
+			if prop, ok := v.schema.Properties[discriminator]; ok && prop.Value != nil && len(prop.Value.Enum) == 1 {
+				v.value = fmt.Sprintf("%v", prop.Value.Enum[0])
+			}
+		}
+		if v.value == "" && ref.Ref != "" {
+			v.value = schemaName(ref.Ref)
+		}
+		if v.value == "" {
+			v.value = fmt.Sprintf("variant%d", i+1)
+		}
+		variants = append(variants, v)
+	}
+	return discriminator, variants
+}
+
+// addVariants adds the flags of every variant of a oneOf or anyOf schema.
+// With a discriminator, a flag named after it selects the variant, and
+// each variant's flags are only accepted for that variant. Without one,
+// the flags of all variants are offered.
+func (w *bodyWalker) addVariants(schema *openapi3.Schema, path []string, prefix string) error {
+	discriminator, variants := variantsOf(schema)
+	if len(variants) == 0 {
+		return nil
+	}
+
+	selector := ""
+	if discriminator != "" {
+		selector = prefix + toFlagName(discriminator)
+		values := make([]string, len(variants))
+		for i, v := range variants {
+			values[i] = v.value
+		}
+
+		if w.cmd.Flags().Lookup(selector) == nil {
+			description := fmt.Sprintf("Variant to send (%s)", strings.Join(values, ", "))
+			if prop, ok := schema.Properties[discriminator]; ok && prop.Value != nil && prop.Value.Description != "" {
+				description = fmt.Sprintf("%s (%s)", prop.Value.Description, strings.Join(values, ", "))
+			}
+			w.cmd.Flags().String(selector, "", description)
+			if err := w.cmd.Flags().SetAnnotation(selector, bodyPathAnnotation, appendPath(path, discriminator)); err != nil {
+				return err
+			}
+			if w.cmd.Annotations == nil {
+				w.cmd.Annotations = make(map[string]string)
+			}
+			w.cmd.Annotations[fmt.Sprintf("body:%s", selector)] = strings.Join(appendPath(path, discriminator), ".")
+		}
+		if err := w.cmd.Flags().SetAnnotation(selector, "enum", values); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range variants {
+		v.selector = selector
+		if selector == "" {
+			// Without a discriminator there is nothing to check flags
+			// against, so they are offered as plain optional flags
+			if err := w.addObject(v.schema, path, prefix, false, nil); err != nil && !strings.Contains(err.Error(), "already defined") {
+				return err
+			}
+			continue
+		}
+		if err := w.addObject(v.schema, path, prefix, false, v); err != nil {
+			return err
+		}
+	}
+
+	if selector != "" {
+		appendLong(w.cmd, variantHelp(w.cmd, "Variants (--"+selector+"):", variants, selector))
+	}
+	return nil
+}
+
+// variantHelp lists the flags of each variant.
+func variantHelp(cmd *cobra.Command, title string, variants []*bodyVariant, selector string) string {
+	var b strings.Builder
+	b.WriteString(title)
+
+	width := 0
+	for _, v := range variants {
+		width = max(width, len(v.value))
+	}
+
+	for _, v := range variants {
+		var flags []string
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Annotations[bodySelectorAnnotation] == nil || flag.Annotations[bodySelectorAnnotation][0] != selector {
+				return
+			}
+			if containsString(flag.Annotations[bodyVariantsAnnotation], v.value) {
+				flags = append(flags, "--"+flag.Name)
+			}
+		})
+		if len(flags) == 0 {
+			flags = []string{"(no flags)"}
+		}
+		fmt.Fprintf(&b, "\n  %-*s  %s", width, v.value, strings.Join(flags, ", "))
+	}
+	return b.String()
+}
+
+// appendLong appends a section to a command's long help.
+func appendLong(cmd *cobra.Command, section string) {
+	long := cmd.Long
+	if long == "" {
+		long = cmd.Short
+	}
+	if long != "" {
+		long += "\n\n"
+	}
+	cmd.Long = long + section
+}
+
+// variantMode returns how the variants of a request body are offered.
+func (fb *FlagBuilder) variantMode(schema *openapi3.Schema) string {
+	if mode, ok := schema.Extensions[variantsExtension].(string); ok {
+		return mode
+	}
+	return fb.variants
+}
+
+// addVariantCommands adds a subcommand for each variant of a request body
+// with a discriminator. Each subcommand has the operation's flags, the
+// body's common flags and the variant's own, and sends the variant's
+// discriminator value. The operation's command only groups them.
+func (fb *FlagBuilder) addVariantCommands(cmd *cobra.Command, op *openapi.Operation, schema *openapi3.Schema) error {
+	discriminator, variants := variantsOf(schema)
+
+	common := *schema
+	common.OneOf = nil
+	common.AnyOf = nil
+
+	for _, v := range variants {
+		v.discriminator = discriminator
+
+		short := v.schema.Description
+		if short == "" {
+			short = fmt.Sprintf("%s (%s %s)", cmd.Short, discriminator, v.value)
+		}
+		child := &cobra.Command{
+			Use:   toCommandName(v.value),
+			Short: short,
+			Args:  cmd.Args,
+			RunE:  cmd.RunE,
+		}
+		child.Annotations = make(map[string]string, len(cmd.Annotations)+2)
+		for k, val := range cmd.Annotations {
+			child.Annotations[k] = val
+		}
+		child.Annotations[variantAnnotation] = v.value
+		child.Annotations[discriminatorAnnotation] = discriminator
+
+		if err := fb.addParameterFlags(child, op.Operation.Parameters); err != nil {
+			return fmt.Errorf("failed to add parameter flags: %w", err)
+		}
+
+		w := fb.newBodyWalker(child, op.CLIFlags)
+		if err := w.addObject(&common, nil, "", true, nil); err != nil {
+			return fmt.Errorf("failed to add request body flags: %w", err)
+		}
+		if err := w.addObject(v.schema, nil, "", true, v); err != nil {
+			return fmt.Errorf("failed to add request body flags for %s: %w", v.value, err)
+		}
+
+		if err := fb.addCustomFlags(child, op.CLIFlags); err != nil {
+			return fmt.Errorf("failed to add custom flags: %w", err)
+		}
+
+		cmd.AddCommand(child)
+	}
+
+	// The operation is run through its variants
+	cmd.RunE = nil
+
+	var b strings.Builder
+	b.WriteString("Variants:")
+	for _, child := range cmd.Commands() {
+		if child.Annotations[variantAnnotation] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n  %s %s", cmd.Name(), child.Name())
+	}
+	appendLong(cmd, b.String())
+	return nil
+}
+
+// flattenSchema merges the allOf schemas of schema into one: properties
+// and required properties are combined, and the first type, map values,
+// variants and discriminator found are kept.
+func flattenSchema(schema *openapi3.Schema) *openapi3.Schema {
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	flat := *schema
+	flat.AllOf = nil
+	flat.Properties = make(openapi3.Schemas, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		flat.Properties[name] = prop
+	}
+	flat.Required = append([]string(nil), schema.Required...)
+
+	for _, ref := range schema.AllOf {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		part := flattenSchema(ref.Value)
+
+		for name, prop := range part.Properties {
+			if _, exists := flat.Properties[name]; !exists {
+				flat.Properties[name] = prop
+			}
+		}
+		for _, name := range part.Required {
+			if !containsString(flat.Required, name) {
+				flat.Required = append(flat.Required, name)
+			}
+		}
+		if flat.Type == nil || len(flat.Type.Slice()) == 0 {
+			flat.Type = part.Type
+		}
+		if flat.AdditionalProperties.Has == nil && flat.AdditionalProperties.Schema == nil {
+			flat.AdditionalProperties = part.AdditionalProperties
+		}
+		if flat.Discriminator == nil {
+			flat.Discriminator = part.Discriminator
+		}
+		if len(flat.OneOf) == 0 {
+			flat.OneOf = part.OneOf
+		}
+		if len(flat.AnyOf) == 0 {
+			flat.AnyOf = part.AnyOf
+		}
+		if flat.Description == "" {
+			flat.Description = part.Description
+		}
+	}
+	return &flat
+}
+
+// schemaType returns the type of a schema, inferring "object" and
+// "array" from their keywords. A "null" type alongside another is ignored.
+func schemaType(schema *openapi3.Schema) string {
+	if schema.Type != nil {
+		for _, t := range schema.Type.Slice() {
+			if t != "null" {
+				return t
+			}
+		}
+	}
+	switch {
+	case len(schema.Properties) > 0, schema.AdditionalProperties.Schema != nil,
+		schema.AdditionalProperties.Has != nil && *schema.AdditionalProperties.Has:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// mapValueSchema returns the schema of the additional properties of an
+// object, if it allows them.
+func mapValueSchema(schema *openapi3.Schema) (*openapi3.Schema, bool) {
+	if ref := schema.AdditionalProperties.Schema; ref != nil && ref.Value != nil {
+		return flattenSchema(ref.Value), true
+	}
+	if has := schema.AdditionalProperties.Has; has != nil && *has {
+		return &openapi3.Schema{}, true
+	}
+	return nil, false
+}
+
+// sortedProperties returns the names of the properties of a schema with a
+// value, sorted.
+func sortedProperties(schema *openapi3.Schema) []string {
+	names := make([]string, 0, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		if prop != nil && prop.Value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaName returns the name of the schema a $ref points to.
+func schemaName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// appendPath returns path with name appended, without sharing path's
+// backing array.
+func appendPath(path []string, name string) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), name)
+}
+
+// withHint appends a format hint to a flag description.
+func withHint(description, hint string) string {
+	if description == "" {
+		return "(" + hint + ")"
+	}
+	return description + " (" + hint + ")"
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildRequestBody builds request body from flags. Values of nested
+// properties are placed at their path, map and object flags are parsed,
+// and the flags of oneOf variants are checked against the selected
+// variant, which is inferred from them when not given.
+func BuildRequestBody(cmd *cobra.Command) (map[string]interface{}, error) {
+	body := make(map[string]interface{})
+	var errs []string
+
+	// Variant subcommands send their discriminator value
+	if variant := cmd.Annotations[variantAnnotation]; variant != "" {
+		body[cmd.Annotations[discriminatorAnnotation]] = variant
+	}
+
+	// Flags of each variant selector that were set
+	selected := make(map[string][]*pflag.Flag)
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+
+		path := bodyPath(cmd, flag)
+		if path == nil {
+			return
+		}
+
+		if selector := flag.Annotations[bodySelectorAnnotation]; selector != nil {
+			selected[selector[0]] = append(selected[selector[0]], flag)
+		}
+
+		value, err := bodyValue(cmd.Flags(), flag)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("--%s: %v", flag.Name, err))
+			return
+		}
+		if err := setPath(body, path, value); err != nil {
+			errs = append(errs, fmt.Sprintf("--%s: %v", flag.Name, err))
+		}
+	})
+
+	// Required const properties are sent with their enclosing object
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		value := firstAnnotation(flag, bodyDefaultAnnotation)
+		path := bodyPath(cmd, flag)
+		if flag.Changed || value == "" || path == nil || !hasObject(body, path[:len(path)-1]) {
+			return
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+			errs = append(errs, fmt.Sprintf("--%s: invalid default: %v", flag.Name, err))
+			return
+		}
+		if err := setPath(body, path, decoded); err != nil {
+			errs = append(errs, fmt.Sprintf("--%s: %v", flag.Name, err))
+		}
+	})
+
+	selectors := make([]string, 0, len(selected))
+	for selector := range selected {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+	for _, selector := range selectors {
+		if err := checkVariant(cmd, body, selector, selected[selector]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid request body flags:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return body, nil
+}
+
+// bodyPath returns where a flag's value goes in the body, or nil if it is
+// not a body flag.
+func bodyPath(cmd *cobra.Command, flag *pflag.Flag) []string {
+	if path := flag.Annotations[bodyPathAnnotation]; len(path) > 0 {
+		return path
+	}
+	if cmd.Annotations != nil {
+		if fieldName, ok := cmd.Annotations[fmt.Sprintf("body:%s", flag.Name)]; ok {
+			return []string{fieldName}
+		}
+	}
+	return nil
+}
+
+// checkVariant checks that the flags of a oneOf variant belong to the
+// variant its selector chose. When the selector was not set and the
+// flags all belong to one variant, that variant is selected.
+func checkVariant(cmd *cobra.Command, body map[string]interface{}, selector string, flags []*pflag.Flag) error {
+	selectorFlag := cmd.Flags().Lookup(selector)
+	if selectorFlag == nil {
+		return nil
+	}
+
+	if selectorFlag.Changed {
+		value := selectorFlag.Value.String()
+		for _, flag := range flags {
+			if !containsString(flag.Annotations[bodyVariantsAnnotation], value) {
+				return fmt.Errorf("--%s does not apply to --%s %s (only to %s)",
+					flag.Name, selector, value, strings.Join(flag.Annotations[bodyVariantsAnnotation], ", "))
+			}
+		}
+		return nil
+	}
+
+	candidates := flags[0].Annotations[bodyVariantsAnnotation]
+	for _, flag := range flags[1:] {
+		var common []string
+		for _, v := range candidates {
+			if containsString(flag.Annotations[bodyVariantsAnnotation], v) {
+				common = append(common, v)
+			}
+		}
+		candidates = common
+	}
+
+	switch len(candidates) {
+	case 0:
+		names := make([]string, len(flags))
+		for i, flag := range flags {
+			names[i] = "--" + flag.Name
+		}
+		return fmt.Errorf("%s belong to different variants; choose one with --%s", strings.Join(names, ", "), selector)
+	case 1:
+		return setPath(body, bodyPath(cmd, selectorFlag), candidates[0])
+	default:
+		return nil
+	}
+}
+
+// bodyValue returns the body value of a flag.
+func bodyValue(flags *pflag.FlagSet, flag *pflag.Flag) (interface{}, error) {
+	format := firstAnnotation(flag, bodyFormatAnnotation)
+	valueType := firstAnnotation(flag, bodyTypeAnnotation)
+
+	if firstAnnotation(flag, bodyNullableAnnotation) != "" {
+		if flag.Value.String() == "null" {
+			return nil, nil
+		}
+		if flag.Value.Type() == "string" {
+			return convertValue(flag.Value.String(), valueType)
+		}
+	}
+
+	if tuple := flag.Annotations[bodyTupleAnnotation]; tuple != nil {
+		items, err := flags.GetStringArray(flag.Name)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, 0, len(items))
+		for i, item := range items {
+			itemType := valueType
+			if i < len(tuple) {
+				itemType = tuple[i]
+			}
+			value, err := convertValue(item, itemType)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+
+	switch format {
+	case "json":
+		var value interface{}
+		if err := json.Unmarshal([]byte(flag.Value.String()), &value); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return value, nil
+
+	case "map":
+		pairs, err := flags.GetStringArray(flag.Name)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{}, len(pairs))
+		for _, pair := range pairs {
+			key, raw, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("expected key=value, got %q", pair)
+			}
+			value, err := convertValue(raw, valueType)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			result[key] = value
+		}
+		return result, nil
+
+	case "objects":
+		items, err := flags.GetStringArray(flag.Name)
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]string)
+		for _, field := range flag.Annotations[bodyFieldsAnnotation] {
+			name, fieldType, _ := strings.Cut(field, "=")
+			fields[name] = fieldType
+		}
+		result := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			object, err := parseObject(item, fields)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, object)
+		}
+		return result, nil
+	}
+
+	if valueType != "" && flag.Value.Type() == "stringArray" {
+		items, err := flags.GetStringArray(flag.Name)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			value, err := convertValue(item, valueType)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+
+	return GetFlagValue(flags, flag.Name)
+}
+
+// parseObject parses an object given as JSON or as comma separated
+// key=value pairs. Dotted keys set nested properties.
+func parseObject(s string, fields map[string]string) (map[string]interface{}, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &object); err != nil {
+			return nil, fmt.Errorf("invalid JSON object: %w", err)
+		}
+		return object, nil
+	}
+
+	object := make(map[string]interface{})
+	for _, pair := range splitPairs(s) {
+		key, raw, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		path := strings.Split(key, ".")
+		value, err := convertValue(raw, fields[path[0]])
+		if len(path) > 1 {
+			value, err = convertValue(raw, "")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if err := setPath(object, path, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return object, nil
+}
+
+// splitPairs splits s on commas outside quotes, brackets and braces, so
+// values can hold JSON.
+func splitPairs(s string) []string {
+	var pairs []string
+	depth := 0
+	inQuote := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			pairs = append(pairs, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(pairs, s[start:])
+}
+
+// convertValue converts a flag value to the JSON value of a schema type.
+// Values of unknown type are strings.
+func convertValue(s, schemaType string) (interface{}, error) {
+	switch schemaType {
+	case "integer":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", s)
+		}
+		return b, nil
+	case "object", "array":
+		var value interface{}
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return value, nil
+	}
+	return s, nil
+}
+
+// setPath sets the value at path in body, creating objects on the way.
+// Objects already at path are merged with an object value.
+func setPath(body map[string]interface{}, path []string, value interface{}) error {
+	current := body
+	for i, key := range path[:len(path)-1] {
+		next, exists := current[key]
+		if !exists {
+			child := make(map[string]interface{})
+			current[key] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", strings.Join(path[:i+1], "."))
+		}
+		current = child
+	}
+
+	key := path[len(path)-1]
+	existing, isObject := current[key].(map[string]interface{})
+	object, valueIsObject := value.(map[string]interface{})
+	if isObject && valueIsObject {
+		for k, v := range object {
+			if _, set := existing[k]; !set {
+				existing[k] = v
+			}
+		}
+		return nil
+	}
+	if _, exists := current[key]; exists && isObject {
+		return fmt.Errorf("%s is set by more than one flag", strings.Join(path, "."))
+	}
+	current[key] = value
+	return nil
+}
+
+// hasObject reports whether body has an object at path.
+func hasObject(body map[string]interface{}, path []string) bool {
+	current := body
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	return true
+}
+
+// firstAnnotation returns the first value of a flag annotation.
+func firstAnnotation(flag *pflag.Flag, key string) string {
+	if values := flag.Annotations[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// BodyFlagName returns the name of the flag that sets the request body
+// value at path, or of the closest enclosing value with a flag. It
+// returns "" if no flag sets any part of path.
+func BodyFlagName(cmd *cobra.Command, path []string) string {
+	name, depth := "", 0
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		flagPath := bodyPath(cmd, flag)
+		if len(flagPath) <= depth || len(flagPath) > len(path) {
+			return
+		}
+		for i := range flagPath {
+			if flagPath[i] != path[i] {
+				return
+			}
+		}
+		name, depth = flag.Name, len(flagPath)
+	})
+	return name
+}
+
+// BodyFieldFlag returns the name of the flag that sets exactly the request
+// body value at path, or "" if there is none.
+func BodyFieldFlag(cmd *cobra.Command, path []string) string {
+	name := ""
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flagPath := bodyPath(cmd, flag); name == "" && len(flagPath) > 0 && strings.Join(flagPath, ".") == strings.Join(path, ".") {
+			name = flag.Name
+		}
+	})
+	return name
+}
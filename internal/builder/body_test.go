@@ -0,0 +1,411 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+)
+
+// bodySpec is a spec whose createCluster body exercises nested, map,
+// array and polymorphic properties.
+const bodySpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Clusters", "version": "1.0.0"},
+  "paths": {
+    "/clusters": {
+      "post": {
+        "operationId": "createCluster",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Cluster"}}}
+        },
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/sources": {
+      "post": {
+        "operationId": "createSource",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Source"}}}
+        },
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Named": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {"name": {"type": "string"}}
+      },
+      "Cluster": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Named"},
+          {
+            "type": "object",
+            "required": ["network"],
+            "properties": {
+              "id": {"type": "string", "readOnly": true},
+              "network": {
+                "type": "object",
+                "required": ["cidr"],
+                "properties": {
+                  "cidr": {"type": "string"},
+                  "dns": {
+                    "type": "object",
+                    "properties": {
+                      "servers": {"type": "array", "items": {"type": "string"}},
+                      "cache": {"type": "object", "properties": {"ttl": {"type": "integer"}}}
+                    }
+                  }
+                }
+              },
+              "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+              "limits": {"type": "object", "additionalProperties": {"type": "integer"}},
+              "nodePools": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "properties": {"name": {"type": "string"}, "replicas": {"type": "integer"}}
+                }
+              },
+              "ports": {"type": "array", "items": {"type": "integer"}}
+            }
+          }
+        ]
+      },
+      "Source": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {"name": {"type": "string"}},
+        "oneOf": [
+          {"$ref": "#/components/schemas/GitSource"},
+          {"$ref": "#/components/schemas/ImageSource"}
+        ],
+        "discriminator": {
+          "propertyName": "kind",
+          "mapping": {
+            "git": "#/components/schemas/GitSource",
+            "image": "#/components/schemas/ImageSource"
+          }
+        }
+      },
+      "GitSource": {
+        "type": "object",
+        "properties": {
+          "kind": {"type": "string"},
+          "url": {"type": "string"},
+          "branch": {"type": "string"}
+        }
+      },
+      "ImageSource": {
+        "type": "object",
+        "properties": {
+          "kind": {"type": "string"},
+          "image": {"type": "string"},
+          "pullSecret": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
+
+// bodyOperation loads bodySpec and returns one of its operations.
+func bodyOperation(t *testing.T, operationID string) *openapi.Operation {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(bodySpec))
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Invalid spec: %v", err)
+	}
+	for _, path := range doc.Paths.InMatchingOrder() {
+		for _, op := range doc.Paths.Value(path).Operations() {
+			if op.OperationID == operationID {
+				return &openapi.Operation{OperationID: operationID, Operation: op}
+			}
+		}
+	}
+	t.Fatalf("operation %s not found", operationID)
+	return nil
+}
+
+// bodyCommand returns a command with the flags of an operation.
+func bodyCommand(t *testing.T, fb *FlagBuilder, operationID string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{Use: "create", Short: "Create it", RunE: func(*cobra.Command, []string) error { return nil }}
+	if err := fb.AddOperationFlags(cmd, bodyOperation(t, operationID)); err != nil {
+		t.Fatalf("AddOperationFlags() error = %v", err)
+	}
+	return cmd
+}
+
+// buildBody sets flags and builds the request body, returning it as JSON.
+func buildBody(t *testing.T, cmd *cobra.Command, args []string) (string, error) {
+	t.Helper()
+
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	body, err := BuildRequestBody(cmd)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), nil
+}
+
+func TestRequestBodyFlags_Nested(t *testing.T) {
+	cmd := bodyCommand(t, NewFlagBuilder(nil), "createCluster")
+
+	for _, name := range []string{"name", "network.cidr", "network.dns.servers", "network.dns.cache", "labels", "limits", "nodepools", "ports"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("Expected flag --%s", name)
+		}
+	}
+	if cmd.Flags().Lookup("id") != nil {
+		t.Error("Expected no flag for a read-only property")
+	}
+	if cmd.Flags().Lookup("network.dns.cache.ttl") != nil {
+		t.Error("Expected objects beyond the body depth to take JSON")
+	}
+
+	// Required only if every enclosing object is required
+	for name, want := range map[string]bool{"name": true, "network.cidr": true, "network.dns.servers": false} {
+		_, required := cmd.Flags().Lookup(name).Annotations[cobra.BashCompOneRequiredFlag]
+		if required != want {
+			t.Errorf("--%s required = %v, want %v", name, required, want)
+		}
+	}
+
+	got, err := buildBody(t, cmd, []string{
+		"--name", "prod",
+		"--network.cidr", "10.0.0.0/16",
+		"--network.dns.servers", "1.1.1.1", "--network.dns.servers", "8.8.8.8",
+		"--network.dns.cache", `{"ttl": 30}`,
+		"--labels", "env=prod", "--labels", "team=core",
+		"--limits", "cpu=4",
+		"--nodepools", "name=a,replicas=3",
+		"--nodepools", `{"name": "b", "replicas": 1}`,
+		"--ports", "80", "--ports", "443",
+	})
+	if err != nil {
+		t.Fatalf("BuildRequestBody() error = %v", err)
+	}
+	want := `{"labels":{"env":"prod","team":"core"},"limits":{"cpu":4},"name":"prod",` +
+		`"network":{"cidr":"10.0.0.0/16","dns":{"cache":{"ttl":30},"servers":["1.1.1.1","8.8.8.8"]}},` +
+		`"nodePools":[{"name":"a","replicas":3},{"name":"b","replicas":1}],"ports":[80,443]}`
+	if got != want {
+		t.Errorf("BuildRequestBody() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRequestBodyFlags_BodyDepth(t *testing.T) {
+	fb := NewFlagBuilder(&openapi.CLIConfig{Flags: &openapi.FlagSettings{BodyDepth: 1}})
+	cmd := bodyCommand(t, fb, "createCluster")
+
+	if cmd.Flags().Lookup("network.cidr") != nil {
+		t.Error("Expected no dotted flags with a body depth of 1")
+	}
+	got, err := buildBody(t, cmd, []string{"--name", "prod", "--network", `{"cidr": "10.0.0.0/16"}`})
+	if err != nil {
+		t.Fatalf("BuildRequestBody() error = %v", err)
+	}
+	if got != `{"name":"prod","network":{"cidr":"10.0.0.0/16"}}` {
+		t.Errorf("BuildRequestBody() = %s", got)
+	}
+}
+
+func TestRequestBodyFlags_InvalidValues(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--labels", "env"}, "--labels: expected key=value"},
+		{[]string{"--limits", "cpu=lots"}, `cpu: invalid integer "lots"`},
+		{[]string{"--nodepools", "replicas=x"}, `replicas: invalid integer "x"`},
+		{[]string{"--network.dns.cache", "{"}, "--network.dns.cache: invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			cmd := bodyCommand(t, NewFlagBuilder(nil), "createCluster")
+			_, err := buildBody(t, cmd, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("BuildRequestBody() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestBodyFlags_VariantSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "selected",
+			args: []string{"--name", "web", "--kind", "git", "--url", "https://example.com/web.git"},
+			want: `{"kind":"git","name":"web","url":"https://example.com/web.git"}`,
+		},
+		{
+			name: "inferred",
+			args: []string{"--name", "web", "--image", "nginx", "--pullsecret", "registry"},
+			want: `{"image":"nginx","kind":"image","name":"web","pullSecret":"registry"}`,
+		},
+		{
+			name:    "wrong variant",
+			args:    []string{"--name", "web", "--kind", "git", "--image", "nginx"},
+			wantErr: "--image does not apply to --kind git (only to image)",
+		},
+		{
+			name:    "mixed variants",
+			args:    []string{"--name", "web", "--url", "u", "--image", "nginx"},
+			wantErr: "choose one with --kind",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := bodyCommand(t, NewFlagBuilder(nil), "createSource")
+			got, err := buildBody(t, cmd, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("BuildRequestBody() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildRequestBody() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildRequestBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	cmd := bodyCommand(t, NewFlagBuilder(nil), "createSource")
+	if got := cmd.Flags().Lookup("kind").Annotations["enum"]; !reflect.DeepEqual(got, []string{"git", "image"}) {
+		t.Errorf("Expected --kind to accept the variants, got %v", got)
+	}
+	for _, want := range []string{"Variants (--kind):", "git    --branch, --url", "image  --image, --pullsecret"} {
+		if !strings.Contains(cmd.Long, want) {
+			t.Errorf("Expected help to contain %q, got:\n%s", want, cmd.Long)
+		}
+	}
+}
+
+func TestRequestBodyFlags_VariantSubcommands(t *testing.T) {
+	fb := NewFlagBuilder(&openapi.CLIConfig{Flags: &openapi.FlagSettings{Variants: VariantsSubcommands}})
+	cmd := bodyCommand(t, fb, "createSource")
+
+	if cmd.RunE != nil {
+		t.Error("Expected the operation command to only group its variants")
+	}
+	if !strings.Contains(cmd.Long, "create git") {
+		t.Errorf("Expected the variants to be listed, got:\n%s", cmd.Long)
+	}
+
+	var image *cobra.Command
+	for _, child := range cmd.Commands() {
+		if child.Name() == "image" {
+			image = child
+		}
+	}
+	if image == nil || image.RunE == nil {
+		t.Fatal("Expected a runnable image subcommand")
+	}
+	if image.Flags().Lookup("url") != nil || image.Flags().Lookup("kind") != nil {
+		t.Error("Expected only the image variant's flags")
+	}
+	if err := fb.AddOperationFlags(image, bodyOperation(t, "createSource")); err != nil {
+		t.Errorf("Expected variant subcommands to be skipped, got %v", err)
+	}
+
+	got, err := buildBody(t, image, []string{"--name", "web", "--image", "nginx"})
+	if err != nil {
+		t.Fatalf("BuildRequestBody() error = %v", err)
+	}
+	if got != `{"image":"nginx","kind":"image","name":"web"}` {
+		t.Errorf("BuildRequestBody() = %s", got)
+	}
+}
+
+func TestSplitPairs(t *testing.T) {
+	got := splitPairs(`name=a,tags=["x","y"],meta={"k":"a,b"},note="c,d"`)
+	want := []string{`name=a`, `tags=["x","y"]`, `meta={"k":"a,b"}`, `note="c,d"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitPairs() = %q, want %q", got, want)
+	}
+}
+
+func TestRequestBodyFlags_OpenAPI31(t *testing.T) {
+	spec := `openapi: 3.1.0
+info: {title: Places, version: 1.0.0}
+paths:
+  /places:
+    post:
+      operationId: createPlace
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [kind, name]
+              properties:
+                kind: {const: place}
+                name: {type: string}
+                note: {type: [string, "null"]}
+                rating: {type: [integer, "null"]}
+                point:
+                  type: array
+                  prefixItems: [{type: number}, {type: number}]
+                  items: {type: string}
+      responses:
+        201: {description: Created}
+`
+	parsed, err := openapi.NewParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	operations, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{Use: "create"}
+	if err := NewFlagBuilder(nil).AddOperationFlags(cmd, operations[0]); err != nil {
+		t.Fatalf("AddOperationFlags() error = %v", err)
+	}
+	if _, required := cmd.Flags().Lookup("kind").Annotations[cobra.BashCompOneRequiredFlag]; required {
+		t.Error("Expected the const flag not to be required")
+	}
+
+	got, err := buildBody(t, cmd, []string{
+		"--name", "home",
+		"--note", "null",
+		"--rating", "4",
+		"--point", "51.5", "--point", "-0.1", "--point", "london",
+	})
+	if err != nil {
+		t.Fatalf("BuildRequestBody() error = %v", err)
+	}
+	want := `{"kind":"place","name":"home","note":null,"point":[51.5,-0.1,"london"],"rating":4}`
+	if got != want {
+		t.Errorf("BuildRequestBody() =\n%s\nwant\n%s", got, want)
+	}
+}
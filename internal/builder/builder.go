@@ -77,6 +77,8 @@ type Builder struct {
 	spec       *openapi.ParsedSpec
 	config     *BuilderConfig
 	commandMap map[string]*cobra.Command
+	// mounted are the specs of a composed builder
+	mounted []*MountedSpec
 }
 
 // BuilderConfig configures command building behavior.
@@ -91,6 +93,10 @@ type BuilderConfig struct {
 	FlattenSingleOperations bool
 	// DefaultExecutor is the default command executor function
 	DefaultExecutor func(cmd *cobra.Command, args []string) error
+	// Tags keeps only the operations with one of these tags
+	Tags []string
+	// ExcludeTags drops the operations with one of these tags
+	ExcludeTags []string
 }
 
 // NewBuilder creates a new command builder.
@@ -117,13 +123,17 @@ func DefaultBuilderConfig() *BuilderConfig {
 
 // Build builds the complete command tree from the OpenAPI spec.
 func (b *Builder) Build() (*cobra.Command, error) {
+	if b.mounted != nil {
+		return b.buildComposed()
+	}
+
 	// Create root command
 	rootCmd := b.buildRootCommand()
 
 	// Get all operations from spec
-	operations, err := b.spec.GetOperations()
+	operations, err := b.Operations()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get operations: %w", err)
+		return nil, err
 	}
 
 	// Build command tree based on configuration
@@ -140,6 +150,40 @@ func (b *Builder) Build() (*cobra.Command, error) {
 	return rootCmd, nil
 }
 
+// Operations returns the operations of the spec the builder makes
+// commands for, after tag filtering.
+func (b *Builder) Operations() ([]*openapi.Operation, error) {
+	operations, err := b.spec.GetOperations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+	if len(b.config.Tags) == 0 && len(b.config.ExcludeTags) == 0 {
+		return operations, nil
+	}
+
+	filtered := make([]*openapi.Operation, 0, len(operations))
+	for _, op := range operations {
+		if len(b.config.Tags) > 0 && !hasAnyTag(op.Tags, b.config.Tags) {
+			continue
+		}
+		if hasAnyTag(op.Tags, b.config.ExcludeTags) {
+			continue
+		}
+		filtered = append(filtered, op)
+	}
+	return filtered, nil
+}
+
+// hasAnyTag reports whether any of tags is in want.
+func hasAnyTag(tags, want []string) bool {
+	for _, tag := range tags {
+		if containsString(want, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // buildRootCommand creates the root command from spec info.
 func (b *Builder) buildRootCommand() *cobra.Command {
 	info := b.spec.GetInfo()
@@ -0,0 +1,217 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// MountedSpec is one of several specs composed into a single CLI.
+type MountedSpec struct {
+	// Name identifies the spec in conflicts and unavailable commands
+	Name string
+	// Mount is the command group the spec's commands go under. Empty
+	// mounts them at the root.
+	Mount string
+	// Description is the short help of the mount command
+	Description string
+	// Spec is the loaded spec, or nil if it failed to load
+	Spec *openapi.ParsedSpec
+	// Err is why the spec failed to load
+	Err error
+	// Config configures the spec's commands, including their executor
+	// and tag filters
+	Config *BuilderConfig
+}
+
+// CompositionError reports commands and operationIds defined by more
+// than one spec.
+type CompositionError struct {
+	Conflicts []string
+}
+
+// Error lists the conflicts, one per line.
+func (e *CompositionError) Error() string {
+	return "specs conflict:\n  " + strings.Join(e.Conflicts, "\n  ")
+}
+
+// NewComposedBuilder creates a builder for a CLI composed of several
+// specs. Its Build adds the commands of each spec under the spec's mount,
+// annotated with the spec's name.
+//
+// A spec that failed to load gets a mount command reporting it as
+// unavailable. Group commands of the same name from different specs are
+// merged; any other command or operationId defined by two specs is a
+// conflict, and Build returns all conflicts together as a
+// *CompositionError.
+func NewComposedBuilder(specs []*MountedSpec, config *BuilderConfig) *Builder {
+	if config == nil {
+		config = DefaultBuilderConfig()
+	}
+	return &Builder{
+		config:     config,
+		mounted:    specs,
+		commandMap: make(map[string]*cobra.Command),
+	}
+}
+
+// buildComposed builds the command tree of a composed builder.
+func (b *Builder) buildComposed() (*cobra.Command, error) {
+	rootCmd := &cobra.Command{
+		Use:   b.config.RootName,
+		Short: b.config.RootDescription,
+	}
+	b.commandMap[""] = rootCmd
+
+	if err := compose(rootCmd, b.mounted); err != nil {
+		return nil, err
+	}
+	return rootCmd, nil
+}
+
+// compose adds the commands of the mounted specs to root.
+func compose(root *cobra.Command, specs []*MountedSpec) error {
+	var conflicts []string
+	operationSpecs := make(map[string]string)
+
+	for _, mounted := range specs {
+		parent := root
+		if mounted.Mount != "" {
+			var conflict string
+			parent, conflict = mountCommand(root, mounted)
+			if conflict != "" {
+				conflicts = append(conflicts, conflict)
+				continue
+			}
+		}
+
+		if mounted.Spec == nil {
+			if mounted.Mount != "" {
+				markUnavailable(parent, mounted)
+			}
+			continue
+		}
+
+		config := mounted.Config
+		if config == nil {
+			config = DefaultBuilderConfig()
+		}
+		b := NewBuilder(mounted.Spec, config)
+
+		operations, err := b.Operations()
+		if err != nil {
+			return fmt.Errorf("spec %s: %w", mounted.Name, err)
+		}
+		for _, op := range operations {
+			if other, ok := operationSpecs[op.OperationID]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("operationId %s is defined by specs %s and %s", op.OperationID, other, mounted.Name))
+				continue
+			}
+			operationSpecs[op.OperationID] = mounted.Name
+		}
+
+		tree, err := b.Build()
+		if err != nil {
+			return fmt.Errorf("failed to build commands for spec %s: %w", mounted.Name, err)
+		}
+		annotateSpec(tree, mounted.Name)
+
+		for _, cmd := range tree.Commands() {
+			tree.RemoveCommand(cmd)
+			conflicts = append(conflicts, mergeCommand(parent, cmd)...)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &CompositionError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// mountCommand returns the group command a spec is mounted under,
+// creating it if no other spec uses the mount. It returns a conflict
+// instead if the name is taken by an operation command.
+func mountCommand(root *cobra.Command, mounted *MountedSpec) (*cobra.Command, string) {
+	if existing := findSubcommand(root, mounted.Mount); existing != nil {
+		if isOperationCommand(existing) {
+			return nil, fmt.Sprintf("mount %q of spec %s is a command of spec %s", mounted.Mount, mounted.Name, existing.Annotations["spec"])
+		}
+		return existing, ""
+	}
+
+	short := mounted.Description
+	if short == "" && mounted.Spec != nil {
+		short = mounted.Spec.GetInfo().Title
+	}
+	if short == "" {
+		short = fmt.Sprintf("%s operations", mounted.Name)
+	}
+
+	cmd := &cobra.Command{
+		Use:         mounted.Mount,
+		Short:       short,
+		Annotations: map[string]string{"spec": mounted.Name},
+	}
+	root.AddCommand(cmd)
+	return cmd, ""
+}
+
+// markUnavailable makes the mount command of a spec that failed to load
+// report why when it, or a command under it, is run.
+func markUnavailable(cmd *cobra.Command, mounted *MountedSpec) {
+	cmd.Short += " (unavailable)"
+	cmd.Annotations["unavailable"] = mounted.Name
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.DisableFlagParsing = true
+	cmd.RunE = func(*cobra.Command, []string) error {
+		return fmt.Errorf("commands of %s are unavailable: %w", mounted.Name, mounted.Err)
+	}
+}
+
+// mergeCommand adds cmd to parent, merging it into a group command of
+// the same name. It returns the conflicts found.
+func mergeCommand(parent, cmd *cobra.Command) []string {
+	existing := findSubcommand(parent, cmd.Name())
+	if existing == nil {
+		for _, alias := range cmd.Aliases {
+			if other := findSubcommand(parent, alias); other != nil {
+				return []string{fmt.Sprintf("alias %q of command %q in spec %s is a command of spec %s",
+					alias, parent.CommandPath()+" "+cmd.Name(), cmd.Annotations["spec"], other.Annotations["spec"])}
+			}
+		}
+		parent.AddCommand(cmd)
+		return nil
+	}
+
+	if isOperationCommand(existing) || isOperationCommand(cmd) {
+		return []string{fmt.Sprintf("command %q is defined by specs %s and %s",
+			existing.CommandPath(), existing.Annotations["spec"], cmd.Annotations["spec"])}
+	}
+
+	var conflicts []string
+	for _, child := range cmd.Commands() {
+		cmd.RemoveCommand(child)
+		conflicts = append(conflicts, mergeCommand(existing, child)...)
+	}
+	return conflicts
+}
+
+// isOperationCommand reports whether a command runs an operation rather
+// than grouping other commands.
+func isOperationCommand(cmd *cobra.Command) bool {
+	_, ok := cmd.Annotations["operationID"]
+	return ok
+}
+
+// annotateSpec records the spec of a command and its subcommands.
+func annotateSpec(cmd *cobra.Command, name string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations["spec"] = name
+	for _, sub := range cmd.Commands() {
+		annotateSpec(sub, name)
+	}
+}
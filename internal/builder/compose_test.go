@@ -0,0 +1,104 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// composeSpec returns a spec with one operation per "tag:operationId".
+func composeSpec(t *testing.T, title string, operations ...string) *openapi.ParsedSpec {
+	t.Helper()
+
+	var paths []string
+	for i, operation := range operations {
+		tag, id, _ := strings.Cut(operation, ":")
+		paths = append(paths, fmt.Sprintf(`"/%s/%d": {"get": {"operationId": %q, "tags": [%q], "responses": {"200": {"description": "OK"}}}}`, tag, i, id, tag))
+	}
+	data := fmt.Sprintf(`{"openapi": "3.0.3", "info": {"title": %q, "version": "1.0.0"}, "paths": {%s}}`, title, strings.Join(paths, ","))
+
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(data))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	return spec
+}
+
+func TestComposedBuilder(t *testing.T) {
+	loadErr := errors.New("HTTP 503")
+
+	root, err := NewComposedBuilder([]*MountedSpec{
+		{Name: "accounts", Spec: composeSpec(t, "Accounts", "users:listUsers", "users:getUser")},
+		{Name: "directory", Spec: composeSpec(t, "Directory", "users:listGroups", "admin:purge"), Config: &BuilderConfig{GroupByTags: true, ExcludeTags: []string{"admin"}}},
+		{Name: "billing", Mount: "billing", Spec: composeSpec(t, "Billing API", "invoices:listInvoices", "internal:rebuild"), Config: &BuilderConfig{GroupByTags: true, Tags: []string{"invoices"}}},
+		{Name: "search", Mount: "search", Description: "Search everything", Err: loadErr},
+	}, &BuilderConfig{RootName: "platform"}).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	for _, path := range []string{"users list-users", "users get-user", "users list-groups", "billing invoices list-invoices"} {
+		cmd, _, err := root.Find(strings.Fields(path))
+		if err != nil || cmd.Name() != strings.Fields(path)[len(strings.Fields(path))-1] {
+			t.Errorf("Expected command %q, got %v", path, err)
+		}
+	}
+	listGroups, _, _ := root.Find([]string{"users", "list-groups"})
+	if listGroups.Annotations["spec"] != "directory" {
+		t.Errorf("Expected list-groups to belong to directory, got %q", listGroups.Annotations["spec"])
+	}
+
+	// Tag filters
+	if cmd, _, _ := root.Find([]string{"admin"}); cmd != root {
+		t.Error("Expected excluded tags to have no commands")
+	}
+	if cmd, _, _ := root.Find([]string{"billing", "internal"}); cmd.Name() != "billing" {
+		t.Error("Expected only the included tags to have commands")
+	}
+
+	billing, _, _ := root.Find([]string{"billing"})
+	if billing.Short != "Billing API" {
+		t.Errorf("Expected the mount to be described by the spec title, got %q", billing.Short)
+	}
+
+	// A spec that failed to load reports it from its mount
+	search, args, _ := root.Find([]string{"search", "query", "--term", "x"})
+	if search.Name() != "search" || search.Short != "Search everything (unavailable)" {
+		t.Fatalf("Expected the unavailable search mount, got %q %q", search.Name(), search.Short)
+	}
+	err = search.RunE(search, args)
+	if !errors.Is(err, loadErr) || err.Error() != "commands of search are unavailable: HTTP 503" {
+		t.Errorf("Expected an unavailable error, got %v", err)
+	}
+}
+
+func TestComposedBuilder_Conflicts(t *testing.T) {
+	b := NewComposedBuilder([]*MountedSpec{
+		{Name: "accounts", Spec: composeSpec(t, "Accounts", "users:getUser", "billing:getPlan")},
+		{Name: "legacy", Spec: composeSpec(t, "Legacy", "users:getUser")},
+		{Name: "billing", Mount: "billing", Spec: composeSpec(t, "Billing", "plans:listPlans")},
+	}, &BuilderConfig{RootName: "platform"})
+	_, err := b.Build()
+
+	var composeErr *CompositionError
+	if !errors.As(err, &composeErr) {
+		t.Fatalf("Expected a CompositionError, got %v", err)
+	}
+	want := []string{
+		"operationId getUser is defined by specs accounts and legacy",
+		`command "platform users get-user" is defined by specs accounts and legacy`,
+	}
+	if strings.Join(composeErr.Conflicts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Conflicts =\n%s\nwant\n%s", strings.Join(composeErr.Conflicts, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Groups of the same name merge
+	root, _ := b.GetCommandByPath("")
+	if cmd, _, _ := root.Find([]string{"billing", "plans", "list-plans"}); cmd.Name() != "list-plans" {
+		t.Error("Expected billing to merge the mount into the tag group")
+	}
+}
@@ -0,0 +1,327 @@
+package builder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CommandChangeKind is the kind of change to a command or its flags.
+type CommandChangeKind string
+
+const (
+	// CommandRemoved is a command whose operation was removed.
+	CommandRemoved CommandChangeKind = "command-removed"
+	// CommandRenamed is a command whose operation moved to another path.
+	CommandRenamed CommandChangeKind = "command-renamed"
+	// CommandAdded is a command for a new operation.
+	CommandAdded CommandChangeKind = "command-added"
+	// FlagRemoved is a flag that no longer exists.
+	FlagRemoved CommandChangeKind = "flag-removed"
+	// FlagRenamed is a flag whose name changed.
+	FlagRenamed CommandChangeKind = "flag-renamed"
+	// FlagRetyped is a flag whose value type changed.
+	FlagRetyped CommandChangeKind = "flag-retyped"
+	// FlagRequired is an existing flag that became required.
+	FlagRequired CommandChangeKind = "flag-required"
+	// RequiredFlagAdded is a new flag that is required.
+	RequiredFlagAdded CommandChangeKind = "required-flag-added"
+	// FlagAdded is a new optional flag.
+	FlagAdded CommandChangeKind = "flag-added"
+)
+
+// CommandChange is a change to the commands generated from a spec, as a
+// user of the CLI sees it.
+type CommandChange struct {
+	Kind CommandChangeKind `json:"kind"`
+	// Command is the path of the command below the root, as in the old
+	// spec unless the command was added
+	Command     string `json:"command"`
+	OperationID string `json:"operation_id"`
+	// Flag is the name of the flag, as in the old spec unless the flag
+	// was added
+	Flag string `json:"flag,omitempty"`
+	// Old and New are the command paths, flag names or flag types that
+	// changed
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+	// Breaking is set when scripts using the old CLI may stop working
+	Breaking bool `json:"breaking"`
+}
+
+// String describes the change in a sentence.
+func (c *CommandChange) String() string {
+	switch c.Kind {
+	case CommandRemoved:
+		return fmt.Sprintf("command %q was removed", c.Command)
+	case CommandRenamed:
+		return fmt.Sprintf("command %q was renamed to %q", c.Old, c.New)
+	case CommandAdded:
+		return fmt.Sprintf("command %q was added", c.Command)
+	case FlagRemoved:
+		return fmt.Sprintf("flag --%s of %q was removed", c.Flag, c.Command)
+	case FlagRenamed:
+		return fmt.Sprintf("flag --%s of %q was renamed to --%s", c.Old, c.Command, c.New)
+	case FlagRetyped:
+		return fmt.Sprintf("flag --%s of %q changed type from %s to %s", c.Flag, c.Command, c.Old, c.New)
+	case FlagRequired:
+		return fmt.Sprintf("flag --%s of %q is now required", c.Flag, c.Command)
+	case RequiredFlagAdded:
+		return fmt.Sprintf("required flag --%s was added to %q", c.Flag, c.Command)
+	case FlagAdded:
+		return fmt.Sprintf("flag --%s was added to %q", c.Flag, c.Command)
+	default:
+		return fmt.Sprintf("%s: %s", c.Kind, c.Command)
+	}
+}
+
+// HasBreakingChanges reports whether any of the changes is breaking.
+func HasBreakingChanges(changes []*CommandChange) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// commandInfo is an operation command and its flags, keyed by what they
+// are generated from.
+type commandInfo struct {
+	operationID string
+	path        string
+	flags       map[string]*flagInfo
+}
+
+// flagInfo is a flag of an operation command.
+type flagInfo struct {
+	name     string
+	kind     string
+	typ      string
+	required bool
+}
+
+// DiffCommands compares the commands the builder generates for two
+// versions of a spec.
+//
+// Commands are matched by operationId and flags by the parameter, body
+// property or x-cli-flags entry they are generated from, so a command
+// moved to another group is reported as renamed rather than removed. A
+// command that loses one flag and gains one of the same type and source
+// has had the flag renamed.
+func DiffCommands(oldSpec, newSpec *openapi.ParsedSpec, config *BuilderConfig) ([]*CommandChange, error) {
+	oldCommands, err := operationCommands(oldSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("old spec: %w", err)
+	}
+	newCommands, err := operationCommands(newSpec, config)
+	if err != nil {
+		return nil, fmt.Errorf("new spec: %w", err)
+	}
+
+	var changes []*CommandChange
+	for key, old := range oldCommands {
+		cur, ok := newCommands[key]
+		if !ok {
+			changes = append(changes, &CommandChange{Kind: CommandRemoved, Command: old.path, OperationID: old.operationID, Breaking: true})
+			continue
+		}
+		if old.path != cur.path {
+			changes = append(changes, &CommandChange{Kind: CommandRenamed, Command: old.path, OperationID: old.operationID, Old: old.path, New: cur.path, Breaking: true})
+		}
+		changes = append(changes, diffFlags(old, cur)...)
+	}
+	for key, cur := range newCommands {
+		if _, ok := oldCommands[key]; !ok {
+			changes = append(changes, &CommandChange{Kind: CommandAdded, Command: cur.path, OperationID: cur.operationID})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Breaking != changes[j].Breaking {
+			return changes[i].Breaking
+		}
+		if changes[i].Command != changes[j].Command {
+			return changes[i].Command < changes[j].Command
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Flag < changes[j].Flag
+	})
+	return changes, nil
+}
+
+// diffFlags compares the flags of a command in two versions of a spec.
+func diffFlags(old, cur *commandInfo) []*CommandChange {
+	var changes []*CommandChange
+	change := func(kind CommandChangeKind, flag, oldValue, newValue string, breaking bool) {
+		changes = append(changes, &CommandChange{
+			Kind:        kind,
+			Command:     old.path,
+			OperationID: old.operationID,
+			Flag:        flag,
+			Old:         oldValue,
+			New:         newValue,
+			Breaking:    breaking,
+		})
+	}
+
+	var removed, added []*flagInfo
+	for key, of := range old.flags {
+		nf, ok := cur.flags[key]
+		if !ok {
+			removed = append(removed, of)
+			continue
+		}
+		if of.name != nf.name {
+			change(FlagRenamed, of.name, of.name, nf.name, true)
+		}
+		if of.typ != nf.typ {
+			change(FlagRetyped, of.name, of.typ, nf.typ, true)
+		}
+		if !of.required && nf.required {
+			change(FlagRequired, of.name, "", "", true)
+		}
+	}
+	for key, nf := range cur.flags {
+		if _, ok := old.flags[key]; !ok {
+			added = append(added, nf)
+		}
+	}
+
+	for _, of := range removed {
+		if nf := renamedFlag(of, removed, added); nf != nil {
+			change(FlagRenamed, of.name, of.name, nf.name, true)
+			added = removeFlag(added, nf)
+			continue
+		}
+		change(FlagRemoved, of.name, "", "", true)
+	}
+	for _, nf := range added {
+		if nf.required {
+			change(RequiredFlagAdded, nf.name, "", "", true)
+		} else {
+			change(FlagAdded, nf.name, "", "", false)
+		}
+	}
+	return changes
+}
+
+// renamedFlag returns the added flag that replaces a removed one: the only
+// added flag of the same kind and type, when no other removed flag has
+// them too.
+func renamedFlag(of *flagInfo, removed, added []*flagInfo) *flagInfo {
+	same := func(f *flagInfo) bool { return f.kind == of.kind && f.typ == of.typ }
+
+	var match *flagInfo
+	for _, nf := range added {
+		if same(nf) {
+			if match != nil {
+				return nil
+			}
+			match = nf
+		}
+	}
+	for _, other := range removed {
+		if other != of && same(other) {
+			return nil
+		}
+	}
+	return match
+}
+
+// removeFlag returns flags without f.
+func removeFlag(flags []*flagInfo, f *flagInfo) []*flagInfo {
+	for i, other := range flags {
+		if other == f {
+			return append(flags[:i:i], flags[i+1:]...)
+		}
+	}
+	return flags
+}
+
+// operationCommands builds the commands of a spec with their flags and
+// returns them by operationId, and variant for body variant subcommands.
+func operationCommands(spec *openapi.ParsedSpec, config *BuilderConfig) (map[string]*commandInfo, error) {
+	if config == nil {
+		config = DefaultBuilderConfig()
+	}
+	cfg := *config
+	cfg.DefaultExecutor = nil
+
+	b := NewBuilder(spec, &cfg)
+	operations, err := b.Operations()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*openapi.Operation, len(operations))
+	for _, op := range operations {
+		byID[op.OperationID] = op
+	}
+
+	root, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	fb := NewFlagBuilder(spec.Extensions.Config)
+	commands := make(map[string]*commandInfo)
+	var walk func(cmd *cobra.Command, path []string) error
+	walk = func(cmd *cobra.Command, path []string) error {
+		if id, ok := cmd.Annotations["operationID"]; ok {
+			if op := byID[id]; op != nil && cmd.Annotations[variantAnnotation] == "" {
+				if err := fb.AddOperationFlags(cmd, op); err != nil {
+					return fmt.Errorf("failed to add flags for operation %s: %w", id, err)
+				}
+			}
+			key := id
+			if variant := cmd.Annotations[variantAnnotation]; variant != "" {
+				key += "/" + variant
+			}
+			commands[key] = &commandInfo{
+				operationID: id,
+				path:        strings.Join(path, " "),
+				flags:       commandFlags(cmd),
+			}
+		}
+		for _, sub := range cmd.Commands() {
+			if err := walk(sub, append(path[:len(path):len(path)], sub.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root, nil); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// commandFlags returns the local flags of a command keyed by what they
+// are generated from.
+func commandFlags(cmd *cobra.Command) map[string]*flagInfo {
+	flags := make(map[string]*flagInfo)
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		info := &flagInfo{
+			name:     f.Name,
+			typ:      f.Value.Type(),
+			required: len(f.Annotations[cobra.BashCompOneRequiredFlag]) > 0 && f.Annotations[cobra.BashCompOneRequiredFlag][0] == "true",
+		}
+
+		key := "flag:" + f.Name
+		if param, ok := cmd.Annotations["param:"+f.Name]; ok {
+			info.kind = "param:" + cmd.Annotations["param:"+f.Name+":in"]
+			key = info.kind + ":" + param
+		} else if path := f.Annotations[bodyPathAnnotation]; len(path) > 0 {
+			info.kind = "body:" + strings.Join(path[:len(path)-1], ".")
+			key = "body:" + strings.Join(path, ".")
+		}
+		flags[key] = info
+	})
+	return flags
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+const diffOldSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: owner, in: query, schema: {type: string}}
+      responses: {"200": {description: OK}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+                age: {type: integer}
+      responses: {"201": {description: Created}}
+  /pets/{id}:
+    delete:
+      operationId: deletePet
+      tags: [pets]
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {"204": {description: Deleted}}
+`
+
+const diffNewSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "2.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [animals]
+      parameters:
+        - {name: limit, in: query, schema: {type: string}}
+        - {name: ownerId, in: query, schema: {type: string}}
+      responses: {"200": {description: OK}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name, species]
+              properties:
+                name: {type: string}
+                age: {type: integer}
+                species: {type: string}
+                notes: {type: string}
+      responses: {"201": {description: Created}}
+`
+
+func TestDiffCommands(t *testing.T) {
+	parse := func(data string) *openapi.ParsedSpec {
+		spec, err := openapi.NewParser().Parse(context.Background(), []byte(data))
+		if err != nil {
+			t.Fatalf("Failed to parse spec: %v", err)
+		}
+		return spec
+	}
+
+	changes, err := DiffCommands(parse(diffOldSpec), parse(diffNewSpec), &BuilderConfig{GroupByTags: true})
+	if err != nil {
+		t.Fatalf("DiffCommands() error = %v", err)
+	}
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	want := []string{
+		`flag --name of "pets create-pet" is now required`,
+		`required flag --species was added to "pets create-pet"`,
+		`command "pets delete-pet" was removed`,
+		`command "pets list-pets" was renamed to "animals list-pets"`,
+		`flag --owner of "pets list-pets" was renamed to --ownerid`,
+		`flag --limit of "pets list-pets" changed type from int to string`,
+		`flag --notes was added to "pets create-pet"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DiffCommands() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !HasBreakingChanges(changes) {
+		t.Error("Expected breaking changes")
+	}
+
+	// A spec has no changes from itself
+	changes, err = DiffCommands(parse(diffOldSpec), parse(diffOldSpec), nil)
+	if err != nil || len(changes) != 0 || HasBreakingChanges(changes) {
+		t.Errorf("Expected no changes, got %v, %v", changes, err)
+	}
+}
@@ -13,6 +13,8 @@ import (
 // FlagBuilder builds flags for Cobra commands from OpenAPI parameters and schemas.
 type FlagBuilder struct {
 	globalFlags *GlobalFlags
+	bodyDepth   int
+	variants    string
 }
 
 // GlobalFlags contains global flags from config.
@@ -29,13 +31,38 @@ type GlobalFlags struct {
 
 // NewFlagBuilder creates a new flag builder.
 func NewFlagBuilder(config *openapi.CLIConfig) *FlagBuilder {
-	return &FlagBuilder{
+	fb := &FlagBuilder{
 		globalFlags: &GlobalFlags{},
+		bodyDepth:   DefaultBodyDepth,
+		variants:    VariantsSelector,
 	}
+	if config != nil && config.Flags != nil {
+		if config.Flags.BodyDepth > 0 {
+			fb.bodyDepth = config.Flags.BodyDepth
+		}
+		if config.Flags.Variants != "" {
+			fb.variants = config.Flags.Variants
+		}
+	}
+	return fb
 }
 
 // AddOperationFlags adds flags for an operation to a command.
 func (fb *FlagBuilder) AddOperationFlags(cmd *cobra.Command, op *openapi.Operation) error {
+	// Variant subcommands get their flags when they are created
+	if cmd.Annotations[variantAnnotation] != "" {
+		return nil
+	}
+
+	// Operations whose body variants are subcommands only group them
+	if op.Operation.RequestBody != nil && op.Operation.RequestBody.Value != nil {
+		if schema := requestBodySchema(op.Operation.RequestBody.Value); schema != nil &&
+			schema.Discriminator != nil && (len(schema.OneOf) > 0 || len(schema.AnyOf) > 0) &&
+			fb.variantMode(schema) == VariantsSubcommands {
+			return fb.addVariantCommands(cmd, op, schema)
+		}
+	}
+
 	// Add flags from parameters
 	if err := fb.addParameterFlags(cmd, op.Operation.Parameters); err != nil {
 		return fmt.Errorf("failed to add parameter flags: %w", err)
@@ -53,9 +80,30 @@ func (fb *FlagBuilder) AddOperationFlags(cmd *cobra.Command, op *openapi.Operati
 		return fmt.Errorf("failed to add custom flags: %w", err)
 	}
 
+	// Add --then to follow the links of the response
+	addLinkFlag(cmd, op)
+
 	return nil
 }
 
+// addLinkFlag adds the --then flag, which runs the operations linked
+// from the response, to commands of operations with response links.
+func addLinkFlag(cmd *cobra.Command, op *openapi.Operation) {
+	links := op.Links()
+	if len(links) == 0 || cmd.Flags().Lookup("then") != nil {
+		return
+	}
+
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		names = append(names, link.Name)
+	}
+	cmd.Flags().StringArray("then", nil, fmt.Sprintf("Run a linked operation with values from the response, repeatable to chain links (%s)", strings.Join(names, ", ")))
+	_ = cmd.RegisterFlagCompletionFunc("then", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
 // addParameterFlags adds flags from OpenAPI parameters.
 func (fb *FlagBuilder) addParameterFlags(cmd *cobra.Command, parameters openapi3.Parameters) error {
 	for _, paramRef := range parameters {
@@ -94,71 +142,6 @@ func (fb *FlagBuilder) addParameterFlags(cmd *cobra.Command, parameters openapi3
 	return nil
 }
 
-// addRequestBodyFlags adds flags from request body schema.
-func (fb *FlagBuilder) addRequestBodyFlags(cmd *cobra.Command, requestBody *openapi3.RequestBody, cliFlags []*openapi.CLIFlag) error {
-	// Get JSON content schema
-	content := requestBody.Content.Get("application/json")
-	if content == nil {
-		// No JSON content, skip
-		return nil
-	}
-
-	if content.Schema == nil || content.Schema.Value == nil {
-		return nil
-	}
-
-	schema := content.Schema.Value
-
-	// Build flag mapping from x-cli-flags
-	flagMap := make(map[string]*openapi.CLIFlag)
-	for _, cliFlag := range cliFlags {
-		if cliFlag.Source == "body" {
-			flagMap[cliFlag.Name] = cliFlag
-		}
-	}
-
-	// Add flags for each property
-	for propName, propSchema := range schema.Properties {
-		if propSchema.Value == nil {
-			continue
-		}
-
-		// Check if there's a custom CLI flag mapping
-		var flagName string
-		var description string
-		var required bool
-
-		if cliFlag, ok := flagMap[propName]; ok {
-			flagName = cliFlag.Flag
-			description = cliFlag.Description
-			required = cliFlag.Required
-		} else {
-			flagName = toFlagName(propName)
-			description = propSchema.Value.Description
-			// Check if required in schema
-			for _, req := range schema.Required {
-				if req == propName {
-					required = true
-					break
-				}
-			}
-		}
-
-		// Add flag
-		if err := fb.addFlagFromSchema(cmd, flagName, propSchema, description, required); err != nil {
-			return fmt.Errorf("failed to add flag for property %s: %w", propName, err)
-		}
-
-		// Store body field mapping in annotations
-		if cmd.Annotations == nil {
-			cmd.Annotations = make(map[string]string)
-		}
-		cmd.Annotations[fmt.Sprintf("body:%s", flagName)] = propName
-	}
-
-	return nil
-}
-
 // addCustomFlags adds custom flags from x-cli-flags that aren't from params/body.
 func (fb *FlagBuilder) addCustomFlags(cmd *cobra.Command, cliFlags []*openapi.CLIFlag) error {
 	for _, cliFlag := range cliFlags {
@@ -258,7 +241,13 @@ func (fb *FlagBuilder) addFlagFromSchema(cmd *cobra.Command, flagName string, sc
 	}
 
 	// Handle based on type
-	typeSlice := schema.Type.Slice()
+	// A "null" type alongside another does not change the flag
+	var typeSlice []string
+	for _, t := range schema.Type.Slice() {
+		if t != "null" {
+			typeSlice = append(typeSlice, t)
+		}
+	}
 	if len(typeSlice) == 0 {
 		// Default to string for unknown types
 		cmd.Flags().String(flagName, "", description)
@@ -341,13 +330,15 @@ func (fb *FlagBuilder) addFlagFromSchema(cmd *cobra.Command, flagName string, sc
 // AddGlobalFlags adds global flags to a command.
 func (fb *FlagBuilder) AddGlobalFlags(cmd *cobra.Command) {
 	// Output format
-	cmd.PersistentFlags().StringP("output", "o", "json", "Output format (json, yaml, table)")
+	cmd.PersistentFlags().StringP("output", "o", "json", "Output format (json, yaml, table, jsonl, csv)")
 
 	// Verbosity
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress hints and other non-essential output")
 
-	// Color
+	// Color and paging
 	cmd.PersistentFlags().Bool("no-color", false, "Disable colored output")
+	cmd.PersistentFlags().Bool("no-pager", false, "Write long output straight to the terminal instead of a pager")
 
 	// Config file
 	cmd.PersistentFlags().String("config", "", "Config file path")
@@ -363,6 +354,15 @@ func (fb *FlagBuilder) AddGlobalFlags(cmd *cobra.Command) {
 
 	// Interactive
 	cmd.PersistentFlags().BoolP("interactive", "i", false, "Enable interactive mode")
+
+	// Record/replay
+	cmd.PersistentFlags().String("record", "", "Record HTTP exchanges as fixtures in this directory")
+	cmd.PersistentFlags().String("replay", "", "Serve HTTP responses from fixtures in this directory")
+	cmd.PersistentFlags().String("replay-match", "method,path,query", "Request attributes matched during replay (method,path,query,body)")
+
+	// Contract validation
+	cmd.PersistentFlags().Bool("no-validate", false, "Send requests without checking them against the spec")
+	cmd.PersistentFlags().Bool("validate-response", false, "Fail when a response does not match the spec, showing the differences")
 }
 
 // GetFlagValue retrieves a flag value with type conversion.
@@ -414,27 +414,19 @@ func BuildRequestParams(cmd *cobra.Command) (map[string]interface{}, error) {
 	return params, nil
 }
 
-// BuildRequestBody builds request body from flags.
-func BuildRequestBody(cmd *cobra.Command) (map[string]interface{}, error) {
-	body := make(map[string]interface{})
-
-	// Iterate through all flags
-	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
-		if !flag.Changed {
-			return
+// ParamFlagName returns the name of the flag of the parameter called name
+// in location in, or "" if the parameter has no flag.
+func ParamFlagName(cmd *cobra.Command, name, in string) string {
+	for key, value := range cmd.Annotations {
+		flagName, ok := strings.CutPrefix(key, "param:")
+		if !ok || value != name || strings.Contains(flagName, ":") {
+			continue
 		}
-
-		// Get the body field mapping from annotations
-		bodyKey := fmt.Sprintf("body:%s", flag.Name)
-		if cmd.Annotations != nil {
-			if fieldName, ok := cmd.Annotations[bodyKey]; ok {
-				val, _ := GetFlagValue(cmd.Flags(), flag.Name)
-				body[fieldName] = val
-			}
+		if cmd.Annotations[key+":in"] == in {
+			return flagName
 		}
-	})
-
-	return body, nil
+	}
+	return ""
 }
 
 // toFlagName converts a parameter name to a flag name.
@@ -62,7 +62,7 @@ func TestFlagBuilder_AddGlobalFlags(t *testing.T) {
 	flagBuilder.AddGlobalFlags(cmd)
 
 	// Verify global flags exist
-	expectedFlags := []string{"output", "verbose", "no-color", "config", "profile", "dry-run", "debug", "interactive"}
+	expectedFlags := []string{"output", "verbose", "quiet", "no-color", "no-pager", "config", "profile", "dry-run", "debug", "interactive", "record", "replay", "replay-match"}
 	for _, flagName := range expectedFlags {
 		flag := cmd.PersistentFlags().Lookup(flagName)
 		if flag == nil {
@@ -777,3 +777,65 @@ func TestAddParameterFlags_SkipsHeaderAndPath(t *testing.T) {
 		t.Error("Expected query parameter to be added")
 	}
 }
+
+func TestParamFlagName(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	params := openapi3.Parameters{
+		{Value: &openapi3.Parameter{Name: "page_size", In: "query", Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}}}},
+		{Value: &openapi3.Parameter{Name: "id", In: "path", Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}}}},
+	}
+	if err := NewFlagBuilder(nil).addParameterFlags(cmd, params); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ParamFlagName(cmd, "page_size", "query"); got != "page-size" {
+		t.Errorf("ParamFlagName(page_size) = %q, want page-size", got)
+	}
+	if got := ParamFlagName(cmd, "page_size", "header"); got != "" {
+		t.Errorf("Expected no flag in another location, got %q", got)
+	}
+	if got := ParamFlagName(cmd, "id", "path"); got != "" {
+		t.Errorf("Expected no flag for a path parameter, got %q", got)
+	}
+}
+
+func TestFlagBuilder_AddLinkFlag(t *testing.T) {
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(`
+openapi: 3.0.3
+info: {title: Test, version: "1.0"}
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      responses:
+        "201":
+          description: Created
+          links:
+            getCluster: {operationId: getCluster, parameters: {id: $response.body#/id}}
+  /clusters/{id}:
+    get:
+      operationId: getCluster
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: OK}
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	operations, _ := spec.GetOperations()
+
+	for _, op := range operations {
+		cmd := &cobra.Command{Use: op.OperationID}
+		if err := NewFlagBuilder(nil).AddOperationFlags(cmd, op); err != nil {
+			t.Fatal(err)
+		}
+		then := cmd.Flags().Lookup("then")
+		if (then != nil) != (op.OperationID == "createCluster") {
+			t.Errorf("%s: unexpected --then flag %v", op.OperationID, then)
+		}
+		if then != nil && then.Value.Type() != "stringArray" {
+			t.Errorf("Expected --then to be repeatable, got %s", then.Value.Type())
+		}
+	}
+}
@@ -0,0 +1,691 @@
+package builder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// LintSeverity is how serious a lint finding is.
+type LintSeverity string
+
+const (
+	// LintError is a finding that breaks or hides commands.
+	LintError LintSeverity = "error"
+	// LintWarning is a finding that makes the CLI harder to use.
+	LintWarning LintSeverity = "warning"
+	// LintInfo is a finding worth knowing about.
+	LintInfo LintSeverity = "info"
+	// LintOff disables a rule.
+	LintOff LintSeverity = "off"
+)
+
+// ParseLintSeverity parses a severity name.
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	switch severity := LintSeverity(strings.ToLower(s)); severity {
+	case LintError, LintWarning, LintInfo, LintOff:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q: must be error, warning, info or off", s)
+	}
+}
+
+const (
+	// LintIgnoreExtension lists the rules not to report for the spec,
+	// operation, parameter or schema it is on. It is a rule ID, a list of
+	// them, or true for every rule.
+	LintIgnoreExtension = "x-cli-lint-ignore"
+	// LintConfigExtension sets rule severities for a spec:
+	//
+	//	x-cli-lint:
+	//	  rules:
+	//	    enum-description: off
+	LintConfigExtension = "x-cli-lint"
+)
+
+// LintRule is a check of how a spec will look as a CLI.
+type LintRule struct {
+	ID          string
+	Description string
+	// Severity is the severity of the rule's findings by default
+	Severity LintSeverity
+	check    func(run *lintRun)
+}
+
+// LintRules returns the rules the linter runs, in order.
+func LintRules() []*LintRule {
+	return []*LintRule{
+		{
+			ID:          "operation-id-missing",
+			Description: "Operations need an operationId to name their command",
+			Severity:    LintError,
+			check:       checkOperationIDMissing,
+		},
+		{
+			ID:          "operation-id-unstable",
+			Description: "operationIds generated from the method and path rename commands when the path changes",
+			Severity:    LintWarning,
+			check:       checkOperationIDUnstable,
+		},
+		{
+			ID:          "command-collision",
+			Description: "Two operations must not produce the same command",
+			Severity:    LintError,
+			check:       checkCommandCollision,
+		},
+		{
+			ID:          "global-flag-collision",
+			Description: "Operation flags must not shadow global flags",
+			Severity:    LintError,
+			check:       checkGlobalFlagCollision,
+		},
+		{
+			ID:          "flag-name-collision",
+			Description: "Parameters and body properties of an operation must not become the same flag",
+			Severity:    LintError,
+			check:       checkFlagNameCollision,
+		},
+		{
+			ID:          "enum-description",
+			Description: "Enums need a description to explain their values in help",
+			Severity:    LintWarning,
+			check:       checkEnumDescription,
+		},
+		{
+			ID:          "destructive-confirmation",
+			Description: "DELETE operations should ask for confirmation with x-cli-confirmation",
+			Severity:    LintWarning,
+			check:       checkDestructiveConfirmation,
+		},
+		{
+			ID:          "async-terminal-states",
+			Description: "Async operations need terminal states to stop polling",
+			Severity:    LintError,
+			check:       checkAsyncTerminalStates,
+		},
+	}
+}
+
+// LintFinding is a problem the linter found in a spec.
+type LintFinding struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+	// Pointer is the JSON pointer of the spec object the finding is about
+	Pointer     string `json:"pointer"`
+	Command     string `json:"command,omitempty"`
+	OperationID string `json:"operation_id,omitempty"`
+}
+
+// String describes the finding on one line.
+func (f *LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", f.Pointer, f.Severity, f.Message, f.Rule)
+}
+
+// HasLintFindings reports whether any finding is at least as severe as
+// severity.
+func HasLintFindings(findings []*LintFinding, severity LintSeverity) bool {
+	for _, finding := range findings {
+		if severityRank(finding.Severity) >= severityRank(severity) {
+			return true
+		}
+	}
+	return false
+}
+
+// severityRank orders severities from off to error.
+func severityRank(severity LintSeverity) int {
+	switch severity {
+	case LintError:
+		return 3
+	case LintWarning:
+		return 2
+	case LintInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Linter checks how a spec will look as a CLI: command and flag names,
+// help and the safety of destructive and async operations.
+type Linter struct {
+	// Config is the builder configuration commands are generated with
+	Config *BuilderConfig
+	// Severities overrides rule severities by rule ID, over the spec's
+	// x-cli-lint extension. LintOff disables a rule.
+	Severities map[string]LintSeverity
+}
+
+// NewLinter creates a linter for commands built with config.
+func NewLinter(config *BuilderConfig) *Linter {
+	if config == nil {
+		config = DefaultBuilderConfig()
+	}
+	return &Linter{
+		Config:     config,
+		Severities: make(map[string]LintSeverity),
+	}
+}
+
+// Lint runs the rules against a spec and returns the findings, ordered by
+// where they are in the spec.
+func (l *Linter) Lint(spec *openapi.ParsedSpec) ([]*LintFinding, error) {
+	rules := LintRules()
+	severities, err := l.severities(spec, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := newLintRun(spec, l.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		run.rule = rule
+		run.severity = severities[rule.ID]
+		if run.severity == LintOff {
+			continue
+		}
+		rule.check(run)
+	}
+
+	sort.SliceStable(run.findings, func(i, j int) bool {
+		a, b := run.findings[i], run.findings[j]
+		if a.Pointer != b.Pointer {
+			return a.Pointer < b.Pointer
+		}
+		return a.Rule < b.Rule
+	})
+	return run.findings, nil
+}
+
+// severities returns the severity of each rule: its default, then the
+// spec's x-cli-lint, then the linter's Severities.
+func (l *Linter) severities(spec *openapi.ParsedSpec, rules []*LintRule) (map[string]LintSeverity, error) {
+	severities := make(map[string]LintSeverity, len(rules))
+	for _, rule := range rules {
+		severities[rule.ID] = rule.Severity
+	}
+
+	set := func(id string, severity LintSeverity) error {
+		if _, ok := severities[id]; !ok {
+			return fmt.Errorf("unknown lint rule %q", id)
+		}
+		severities[id] = severity
+		return nil
+	}
+
+	if config, ok := spec.Spec.Extensions[LintConfigExtension].(map[string]interface{}); ok {
+		ruleConfig, _ := config["rules"].(map[string]interface{})
+		for id, value := range ruleConfig {
+			name, _ := value.(string)
+			severity, err := ParseLintSeverity(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s rule %s: %w", LintConfigExtension, id, err)
+			}
+			if err := set(id, severity); err != nil {
+				return nil, fmt.Errorf("%s: %w", LintConfigExtension, err)
+			}
+		}
+	}
+
+	for id, severity := range l.Severities {
+		if err := set(id, severity); err != nil {
+			return nil, err
+		}
+	}
+	return severities, nil
+}
+
+// lintRun is the state of linting one spec.
+type lintRun struct {
+	spec       *openapi.ParsedSpec
+	config     *BuilderConfig
+	operations []*openapi.Operation
+	// commands are the command paths of operations, by method and path
+	commands map[string]string
+	root     *cobra.Command
+
+	rule     *LintRule
+	severity LintSeverity
+	findings []*LintFinding
+	seen     map[string]bool
+}
+
+// newLintRun builds the commands of a spec for the rules to check.
+func newLintRun(spec *openapi.ParsedSpec, config *BuilderConfig) (*lintRun, error) {
+	cfg := *config
+	cfg.DefaultExecutor = nil
+
+	b := NewBuilder(spec, &cfg)
+	operations, err := b.Operations()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Path != operations[j].Path {
+			return operations[i].Path < operations[j].Path
+		}
+		return operations[i].Method < operations[j].Method
+	})
+
+	root, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &lintRun{
+		spec:       spec,
+		config:     &cfg,
+		operations: operations,
+		commands:   make(map[string]string),
+		root:       root,
+		seen:       make(map[string]bool),
+	}
+	walkCommands(root, nil, func(cmd *cobra.Command, path []string) {
+		if _, ok := cmd.Annotations["operationID"]; ok {
+			run.commands[operationKey(cmd.Annotations["method"], cmd.Annotations["path"])] = strings.Join(path, " ")
+		}
+	})
+	return run, nil
+}
+
+// walkCommands calls fn for cmd and each command below it, with their
+// paths below cmd.
+func walkCommands(cmd *cobra.Command, path []string, fn func(cmd *cobra.Command, path []string)) {
+	fn(cmd, path)
+	for _, sub := range cmd.Commands() {
+		walkCommands(sub, append(path[:len(path):len(path)], sub.Name()), fn)
+	}
+}
+
+// operationKey identifies an operation by its method and path.
+func operationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// report adds a finding of the current rule unless the spec, op or one of
+// scopes ignores it. A rule reports each pointer once.
+func (r *lintRun) report(op *openapi.Operation, pointer, message string, scopes ...map[string]interface{}) {
+	if lintIgnores(r.spec.Spec.Extensions, r.rule.ID) {
+		return
+	}
+	if op != nil && lintIgnores(op.Operation.Extensions, r.rule.ID) {
+		return
+	}
+	for _, scope := range scopes {
+		if lintIgnores(scope, r.rule.ID) {
+			return
+		}
+	}
+
+	key := r.rule.ID + " " + pointer
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+
+	finding := &LintFinding{
+		Rule:     r.rule.ID,
+		Severity: r.severity,
+		Message:  message,
+		Pointer:  pointer,
+	}
+	if op != nil {
+		finding.Command = r.commands[operationKey(op.Method, op.Path)]
+		finding.OperationID = op.OperationID
+	}
+	r.findings = append(r.findings, finding)
+}
+
+// lintIgnores reports whether the x-cli-lint-ignore extension in
+// extensions ignores the rule.
+func lintIgnores(extensions map[string]interface{}, rule string) bool {
+	switch ignore := extensions[LintIgnoreExtension].(type) {
+	case bool:
+		return ignore
+	case string:
+		return ignore == rule
+	case []interface{}:
+		for _, id := range ignore {
+			if id == rule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// operationPointer returns the JSON pointer of an operation.
+func operationPointer(op *openapi.Operation) string {
+	return "/paths/" + escapeJSONPointer(op.Path) + "/" + strings.ToLower(op.Method)
+}
+
+// escapeJSONPointer escapes a JSON pointer token.
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// describeOperation names an operation in messages.
+func describeOperation(op *openapi.Operation) string {
+	if op.OperationID != "" {
+		return op.OperationID
+	}
+	return operationKey(op.Method, op.Path)
+}
+
+// checkOperationIDMissing reports operations without an operationId.
+func checkOperationIDMissing(run *lintRun) {
+	for _, op := range run.operations {
+		if op.OperationID == "" {
+			run.report(op, operationPointer(op), fmt.Sprintf("%s has no operationId, so its command is named after the method", operationKey(op.Method, op.Path)))
+		}
+	}
+}
+
+// generatedSuffix matches the numeric suffixes generators add to make
+// operationIds unique.
+var generatedSuffix = regexp.MustCompile(`[_-]\d+$`)
+
+// checkOperationIDUnstable reports operationIds that look generated from
+// the method and path.
+func checkOperationIDUnstable(run *lintRun) {
+	for _, op := range run.operations {
+		id := op.OperationID
+		var reason string
+		switch {
+		case id == "":
+			continue
+		case strings.ContainsAny(id, "/{} "):
+			reason = "contains the path"
+		case strings.Contains(id, "__"):
+			reason = "looks generated from the method and path"
+		case generatedSuffix.MatchString(id):
+			reason = "ends in a number added to make it unique"
+		default:
+			continue
+		}
+		run.report(op, operationPointer(op)+"/operationId", fmt.Sprintf("operationId %q %s; its command is renamed when the path changes", id, reason))
+	}
+}
+
+// checkCommandCollision reports operations whose commands have the same
+// name or alias as another command in the same group, which hides one of
+// them.
+func checkCommandCollision(run *lintRun) {
+	byKey := make(map[string]*openapi.Operation, len(run.operations))
+	for _, op := range run.operations {
+		byKey[operationKey(op.Method, op.Path)] = op
+	}
+
+	walkCommands(run.root, nil, func(parent *cobra.Command, path []string) {
+		names := make(map[string][]*cobra.Command)
+		for _, cmd := range parent.Commands() {
+			names[cmd.Name()] = append(names[cmd.Name()], cmd)
+			for _, alias := range cmd.Aliases {
+				if alias == cmd.Name() {
+					continue
+				}
+				names[alias] = append(names[alias], cmd)
+			}
+		}
+
+		for name, cmds := range names {
+			if len(cmds) < 2 {
+				continue
+			}
+			command := strings.Join(append(path[:len(path):len(path)], name), " ")
+			for _, cmd := range cmds {
+				op := byKey[operationKey(cmd.Annotations["method"], cmd.Annotations["path"])]
+				if op == nil {
+					continue
+				}
+				var others []string
+				for _, other := range cmds {
+					if other == cmd {
+						continue
+					}
+					if otherOp := byKey[operationKey(other.Annotations["method"], other.Annotations["path"])]; otherOp != nil {
+						others = append(others, describeOperation(otherOp))
+					} else {
+						others = append(others, fmt.Sprintf("the %s command group", other.Name()))
+					}
+				}
+				sort.Strings(others)
+				run.report(op, operationPointer(op), fmt.Sprintf("command %q of %s is also generated for %s", command, describeOperation(op), strings.Join(others, ", ")))
+			}
+		}
+	})
+}
+
+// checkGlobalFlagCollision reports operation flags with the name or
+// shorthand of a global flag, which they shadow.
+func checkGlobalFlagCollision(run *lintRun) {
+	fb := NewFlagBuilder(run.spec.Extensions.Config)
+
+	global := &cobra.Command{Use: "global"}
+	fb.AddGlobalFlags(global)
+	globalFlags := global.PersistentFlags()
+
+	for _, op := range run.operations {
+		for _, f := range lintFlags(fb, op) {
+			if globalFlags.Lookup(f.name) != nil {
+				run.report(op, f.pointer, fmt.Sprintf("flag --%s of %s shadows the global flag --%s", f.name, describeOperation(op), f.name), f.scope)
+			} else if g := globalFlags.ShorthandLookup(f.shorthand); f.shorthand != "" && g != nil {
+				run.report(op, f.pointer, fmt.Sprintf("flag -%s (--%s) of %s clashes with the global flag -%s (--%s)", f.shorthand, f.name, describeOperation(op), g.Shorthand, g.Name), f.scope)
+			}
+		}
+	}
+}
+
+// lintFlag is a flag of an operation and where it is generated from.
+type lintFlag struct {
+	name      string
+	shorthand string
+	pointer   string
+	scope     map[string]interface{}
+}
+
+// lintFlags returns the flags of an operation. When they cannot be built,
+// as when two parameters become the same flag, the parameter flags are
+// returned.
+func lintFlags(fb *FlagBuilder, op *openapi.Operation) []*lintFlag {
+	var flags []*lintFlag
+
+	cmd := &cobra.Command{Use: "lint"}
+	if err := addLintFlags(fb, cmd, op); err != nil {
+		for i, paramRef := range op.Operation.Parameters {
+			if param := paramRef.Value; param != nil && param.In != "path" && param.In != "header" {
+				flags = append(flags, &lintFlag{
+					name:    toFlagName(param.Name),
+					pointer: fmt.Sprintf("%s/parameters/%d", operationPointer(op), i),
+					scope:   param.Extensions,
+				})
+			}
+		}
+		return flags
+	}
+
+	walkCommands(cmd, nil, func(cmd *cobra.Command, _ []string) {
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			pointer, scope := flagPointer(cmd, op, f)
+			flags = append(flags, &lintFlag{name: f.Name, shorthand: f.Shorthand, pointer: pointer, scope: scope})
+		})
+	})
+	return flags
+}
+
+// addLintFlags adds the flags of an operation to cmd, returning pflag's
+// panics on duplicate flags as errors.
+func addLintFlags(fb *FlagBuilder, cmd *cobra.Command, op *openapi.Operation) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return fb.AddOperationFlags(cmd, op)
+}
+
+// flagPointer returns the JSON pointer of what a flag is generated from,
+// with the extensions of a parameter it is generated from.
+func flagPointer(cmd *cobra.Command, op *openapi.Operation, f *pflag.Flag) (string, map[string]interface{}) {
+	if name, ok := cmd.Annotations["param:"+f.Name]; ok {
+		for i, paramRef := range op.Operation.Parameters {
+			if paramRef.Value != nil && paramRef.Value.Name == name {
+				return fmt.Sprintf("%s/parameters/%d", operationPointer(op), i), paramRef.Value.Extensions
+			}
+		}
+	}
+	if len(f.Annotations[bodyPathAnnotation]) > 0 {
+		return operationPointer(op) + "/requestBody", nil
+	}
+	return operationPointer(op), nil
+}
+
+// checkFlagNameCollision reports parameters and top-level body
+// properties of an operation that become the same flag, which fails to
+// build the command.
+func checkFlagNameCollision(run *lintRun) {
+	for _, op := range run.operations {
+		sources := make(map[string]string)
+		for i, paramRef := range op.Operation.Parameters {
+			param := paramRef.Value
+			if param == nil || param.In == "path" || param.In == "header" {
+				continue
+			}
+			flagName := toFlagName(param.Name)
+			source := fmt.Sprintf("parameter %q", param.Name)
+			if other, ok := sources[flagName]; ok {
+				run.report(op, fmt.Sprintf("%s/parameters/%d", operationPointer(op), i),
+					fmt.Sprintf("%s and %s of %s both become flag --%s", other, source, describeOperation(op), flagName), param.Extensions)
+				continue
+			}
+			sources[flagName] = source
+		}
+
+		if op.Operation.RequestBody == nil || op.Operation.RequestBody.Value == nil {
+			continue
+		}
+		schema := requestBodySchema(op.Operation.RequestBody.Value)
+		if schema == nil {
+			continue
+		}
+		schema = flattenSchema(schema)
+		for _, name := range sortedProperties(schema) {
+			prop := schema.Properties[name].Value
+			if prop == nil || prop.ReadOnly {
+				continue
+			}
+			flagName := toFlagName(name)
+			source := fmt.Sprintf("body property %q", name)
+			if other, ok := sources[flagName]; ok {
+				run.report(op, operationPointer(op)+"/requestBody",
+					fmt.Sprintf("%s and %s of %s both become flag --%s", other, source, describeOperation(op), flagName), prop.Extensions)
+				continue
+			}
+			sources[flagName] = source
+		}
+	}
+}
+
+// checkEnumDescription reports parameters and body properties with an
+// enum but no description explaining its values.
+func checkEnumDescription(run *lintRun) {
+	for _, op := range run.operations {
+		for i, paramRef := range op.Operation.Parameters {
+			param := paramRef.Value
+			if param == nil || param.Schema == nil || param.Schema.Value == nil {
+				continue
+			}
+			schema := param.Schema.Value
+			if len(schema.Enum) > 0 && param.Description == "" && schema.Description == "" {
+				run.report(op, fmt.Sprintf("%s/parameters/%d", operationPointer(op), i),
+					fmt.Sprintf("parameter %q of %s has enum values but no description", param.Name, describeOperation(op)), param.Extensions, schema.Extensions)
+			}
+		}
+
+		if op.Operation.RequestBody == nil || op.Operation.RequestBody.Value == nil {
+			continue
+		}
+		for mediaType, content := range op.Operation.RequestBody.Value.Content {
+			if content.Schema == nil {
+				continue
+			}
+			pointer := operationPointer(op) + "/requestBody/content/" + escapeJSONPointer(mediaType) + "/schema"
+			checkSchemaEnums(run, op, content.Schema, pointer, make(map[*openapi3.Schema]bool))
+		}
+	}
+}
+
+// checkSchemaEnums reports the properties below a schema with an enum but
+// no description. Properties of referenced schemas are reported at the
+// referenced schema.
+func checkSchemaEnums(run *lintRun, op *openapi.Operation, schemaRef *openapi3.SchemaRef, pointer string, visited map[*openapi3.Schema]bool) {
+	schema := schemaRef.Value
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+	if strings.HasPrefix(schemaRef.Ref, "#/") {
+		pointer = strings.TrimPrefix(schemaRef.Ref, "#")
+	}
+
+	for _, name := range sortedProperties(schema) {
+		propRef := schema.Properties[name]
+		prop := propRef.Value
+		if prop == nil {
+			continue
+		}
+		propPointer := pointer + "/properties/" + escapeJSONPointer(name)
+		if len(prop.Enum) > 0 && prop.Description == "" {
+			if strings.HasPrefix(propRef.Ref, "#/") {
+				propPointer = strings.TrimPrefix(propRef.Ref, "#")
+			}
+			run.report(op, propPointer, fmt.Sprintf("body property %q of %s has enum values but no description", name, describeOperation(op)), schema.Extensions, prop.Extensions)
+		}
+		checkSchemaEnums(run, op, propRef, propPointer, visited)
+	}
+	if schema.Items != nil {
+		checkSchemaEnums(run, op, schema.Items, pointer+"/items", visited)
+	}
+	for i, ref := range schema.AllOf {
+		checkSchemaEnums(run, op, ref, fmt.Sprintf("%s/allOf/%d", pointer, i), visited)
+	}
+	for i, ref := range schema.OneOf {
+		checkSchemaEnums(run, op, ref, fmt.Sprintf("%s/oneOf/%d", pointer, i), visited)
+	}
+	for i, ref := range schema.AnyOf {
+		checkSchemaEnums(run, op, ref, fmt.Sprintf("%s/anyOf/%d", pointer, i), visited)
+	}
+}
+
+// checkDestructiveConfirmation reports DELETE operations that run without
+// asking for confirmation.
+func checkDestructiveConfirmation(run *lintRun) {
+	for _, op := range run.operations {
+		if !strings.EqualFold(op.Method, "DELETE") {
+			continue
+		}
+		if op.CLIConfirmation == nil || !op.CLIConfirmation.Enabled {
+			run.report(op, operationPointer(op), fmt.Sprintf("%s deletes without asking for confirmation; add x-cli-confirmation", describeOperation(op)))
+		}
+	}
+}
+
+// checkAsyncTerminalStates reports async operations that cannot tell when
+// to stop polling.
+func checkAsyncTerminalStates(run *lintRun) {
+	for _, op := range run.operations {
+		if async := op.CLIAsync; async != nil && async.Enabled && len(async.TerminalStates) == 0 {
+			run.report(op, operationPointer(op)+"/x-cli-async", fmt.Sprintf("%s is async but has no terminal-states, so it polls until it times out", describeOperation(op)))
+		}
+	}
+}
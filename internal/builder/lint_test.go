@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+const lintSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1.0"}
+x-cli-lint:
+  rules:
+    operation-id-unstable: info
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - {name: output, in: query, schema: {type: string}}
+        - {name: sort_by, in: query, schema: {type: string}}
+        - {name: sort-by, in: query, schema: {type: string}}
+        - {name: status, in: query, schema: {type: string, enum: [available, sold]}}
+        - name: kind
+          in: query
+          description: Kind of pet
+          schema: {type: string, enum: [cat, dog]}
+      responses: {"200": {description: OK}}
+    post:
+      operationId: create_pet_pets__post
+      tags: [pets]
+      x-cli-async:
+        enabled: true
+        status-endpoint: /pets/{id}
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Pet"}
+      responses: {"201": {description: Created}}
+  /animals:
+    get:
+      operationId: listPets_1
+      tags: [pets]
+      x-cli-command: list-pets
+      responses: {"200": {description: OK}}
+  /pets/{id}:
+    delete:
+      tags: [pets]
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {"204": {description: Deleted}}
+    put:
+      operationId: replacePet
+      tags: [pets]
+      x-cli-lint-ignore: [enum-description]
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: mode, in: query, schema: {type: string, enum: [full, partial]}}
+      responses: {"200": {description: OK}}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+        size:
+          type: string
+          enum: [small, large]
+          x-cli-lint-ignore: enum-description
+        color: {type: string, enum: [black, white]}
+`
+
+func TestLinter_Lint(t *testing.T) {
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(lintSpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	linter := NewLinter(nil)
+	linter.Severities["destructive-confirmation"] = LintError
+	findings, err := linter.Lint(spec)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+
+	var got []string
+	for _, finding := range findings {
+		got = append(got, finding.String())
+	}
+	want := []string{
+		`/components/schemas/Pet/properties/color: warning: body property "color" of create_pet_pets__post has enum values but no description [enum-description]`,
+		`/paths/~1animals/get: error: command "pets list-pets" of listPets_1 is also generated for listPets [command-collision]`,
+		`/paths/~1animals/get/operationId: info: operationId "listPets_1" ends in a number added to make it unique; its command is renamed when the path changes [operation-id-unstable]`,
+		`/paths/~1pets/get: error: command "pets list-pets" of listPets is also generated for listPets_1 [command-collision]`,
+		`/paths/~1pets/get/parameters/0: error: flag --output of listPets shadows the global flag --output [global-flag-collision]`,
+		`/paths/~1pets/get/parameters/2: error: parameter "sort_by" and parameter "sort-by" of listPets both become flag --sort-by [flag-name-collision]`,
+		`/paths/~1pets/get/parameters/3: warning: parameter "status" of listPets has enum values but no description [enum-description]`,
+		`/paths/~1pets/post/operationId: info: operationId "create_pet_pets__post" looks generated from the method and path; its command is renamed when the path changes [operation-id-unstable]`,
+		`/paths/~1pets/post/x-cli-async: error: create_pet_pets__post is async but has no terminal-states, so it polls until it times out [async-terminal-states]`,
+		`/paths/~1pets~1{id}/delete: error: DELETE /pets/{id} deletes without asking for confirmation; add x-cli-confirmation [destructive-confirmation]`,
+		`/paths/~1pets~1{id}/delete: error: DELETE /pets/{id} has no operationId, so its command is named after the method [operation-id-missing]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !HasLintFindings(findings, LintError) {
+		t.Error("Expected error findings")
+	}
+
+	// Unknown rules are rejected
+	linter.Severities["no-such-rule"] = LintOff
+	if _, err := linter.Lint(spec); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,279 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+// PluginGroupID is the help group plugin commands are listed under.
+const PluginGroupID = "plugins"
+
+// PluginRunFunc runs a plugin command. Values holds the declared flags by
+// name; it is nil for pass-through commands.
+type PluginRunFunc func(cmd *cobra.Command, args []string, values map[string]interface{}) error
+
+// PluginCommand is a command contributed by a plugin or by x-cli-plugin.
+type PluginCommand struct {
+	// Plugin is the name of the plugin providing the command.
+	Plugin string
+	// Command describes the command, its parent and its flags.
+	Command cli.PluginCommand
+	// PassThrough hands all arguments, including flags, to Run unparsed.
+	PassThrough bool
+	// Run runs the command.
+	Run PluginRunFunc
+}
+
+// CommandConflict reports a plugin command, alias or flag that could not be
+// mounted because the name is already taken.
+type CommandConflict struct {
+	Plugin string
+	Path   string
+	Reason string
+}
+
+// String returns a human-readable description of the conflict.
+func (c *CommandConflict) String() string {
+	return fmt.Sprintf("plugin %s: %s: %s", c.Plugin, c.Path, c.Reason)
+}
+
+// MountPluginCommands adds plugin commands to the command tree built by
+// Build. Missing parent commands are created. Commands whose name is taken
+// by an existing command are skipped, and aliases or flags that clash are
+// dropped; each of these is returned as a conflict.
+func (b *Builder) MountPluginCommands(commands []*PluginCommand) ([]*CommandConflict, error) {
+	rootCmd, ok := b.commandMap[""]
+	if !ok {
+		return nil, fmt.Errorf("command tree has not been built")
+	}
+
+	var conflicts []*CommandConflict
+	for _, pc := range commands {
+		conflicts = append(conflicts, b.mountPluginCommand(rootCmd, pc)...)
+	}
+
+	return conflicts, nil
+}
+
+// mountPluginCommand mounts a single plugin command.
+func (b *Builder) mountPluginCommand(rootCmd *cobra.Command, pc *PluginCommand) []*CommandConflict {
+	parentCmd := b.getOrCreatePluginParent(rootCmd, pc)
+	path := strings.TrimSpace(commandPath(parentCmd, rootCmd) + " " + pc.Command.Name)
+
+	conflict := func(reason string, args ...interface{}) *CommandConflict {
+		return &CommandConflict{Plugin: pc.Plugin, Path: path, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if existing := findSubcommand(parentCmd, pc.Command.Name); existing != nil {
+		return []*CommandConflict{conflict("name is already used by %s", describeCommand(existing))}
+	}
+
+	var conflicts []*CommandConflict
+
+	cmd := &cobra.Command{
+		Use:   strings.TrimSpace(pc.Command.Name + " " + pc.Command.Args),
+		Short: pc.Command.Description,
+		Annotations: map[string]string{
+			"plugin":        pc.Plugin,
+			"pluginCommand": pc.Command.Name,
+		},
+		DisableFlagParsing: pc.PassThrough,
+	}
+
+	for _, alias := range pc.Command.Aliases {
+		if existing := findSubcommand(parentCmd, alias); existing != nil {
+			conflicts = append(conflicts, conflict("alias %q is already used by %s", alias, describeCommand(existing)))
+			continue
+		}
+		cmd.Aliases = append(cmd.Aliases, alias)
+	}
+
+	if !pc.PassThrough {
+		for _, flag := range pc.Command.Flags {
+			if reason := flagConflict(rootCmd, parentCmd, flag); reason != "" {
+				conflicts = append(conflicts, conflict("flag --%s %s", flag.Name, reason))
+				continue
+			}
+			addPluginFlag(cmd, flag)
+		}
+	}
+
+	run := pc.Run
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if pc.PassThrough {
+			return run(cmd, args, nil)
+		}
+		if err := ValidateEnumFlags(cmd); err != nil {
+			return err
+		}
+		return run(cmd, args, pluginFlagValues(cmd, pc.Command.Flags))
+	}
+
+	if parentCmd == rootCmd {
+		addToPluginGroup(rootCmd, cmd)
+	}
+	parentCmd.AddCommand(cmd)
+	return conflicts
+}
+
+// getOrCreatePluginParent resolves the parent path of a plugin command,
+// creating group commands for missing segments.
+func (b *Builder) getOrCreatePluginParent(rootCmd *cobra.Command, pc *PluginCommand) *cobra.Command {
+	currentCmd := rootCmd
+	for _, segment := range strings.Fields(pc.Command.Parent) {
+		if existing := findSubcommand(currentCmd, segment); existing != nil {
+			currentCmd = existing
+			continue
+		}
+
+		groupCmd := &cobra.Command{
+			Use:         segment,
+			Short:       fmt.Sprintf("%s commands", segment),
+			Annotations: map[string]string{"plugin": pc.Plugin},
+		}
+		if currentCmd == rootCmd {
+			addToPluginGroup(rootCmd, groupCmd)
+		}
+		currentCmd.AddCommand(groupCmd)
+		currentCmd = groupCmd
+	}
+
+	return currentCmd
+}
+
+// addToPluginGroup lists a top-level command under "Plugin Commands" in the
+// root help.
+func addToPluginGroup(rootCmd, cmd *cobra.Command) {
+	if !rootCmd.ContainsGroup(PluginGroupID) {
+		rootCmd.AddGroup(&cobra.Group{ID: PluginGroupID, Title: "Plugin Commands:"})
+	}
+	cmd.GroupID = PluginGroupID
+}
+
+// addPluginFlag adds a flag declared in a plugin manifest.
+func addPluginFlag(cmd *cobra.Command, flag cli.PluginFlag) {
+	flags := cmd.Flags()
+
+	switch flag.Type {
+	case "int":
+		defaultVal := 0
+		switch v := flag.Default.(type) {
+		case int:
+			defaultVal = v
+		case float64:
+			defaultVal = int(v)
+		}
+		flags.IntP(flag.Name, flag.Shorthand, defaultVal, flag.Description)
+
+	case "float":
+		defaultVal := 0.0
+		switch v := flag.Default.(type) {
+		case int:
+			defaultVal = float64(v)
+		case float64:
+			defaultVal = v
+		}
+		flags.Float64P(flag.Name, flag.Shorthand, defaultVal, flag.Description)
+
+	case "bool":
+		defaultVal, _ := flag.Default.(bool)
+		flags.BoolP(flag.Name, flag.Shorthand, defaultVal, flag.Description)
+
+	case "stringArray":
+		var defaultVal []string
+		if values, ok := flag.Default.([]interface{}); ok {
+			for _, v := range values {
+				defaultVal = append(defaultVal, fmt.Sprint(v))
+			}
+		}
+		flags.StringArrayP(flag.Name, flag.Shorthand, defaultVal, flag.Description)
+
+	default:
+		defaultVal := ""
+		if flag.Default != nil {
+			defaultVal = fmt.Sprint(flag.Default)
+		}
+		flags.StringP(flag.Name, flag.Shorthand, defaultVal, flag.Description)
+	}
+
+	if flag.Required {
+		_ = cmd.MarkFlagRequired(flag.Name)
+	}
+
+	if len(flag.Enum) > 0 {
+		_ = flags.SetAnnotation(flag.Name, "enum", flag.Enum)
+		enum := flag.Enum
+		_ = cmd.RegisterFlagCompletionFunc(flag.Name, func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return enum, cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+}
+
+// pluginFlagValues collects the values of the declared flags.
+func pluginFlagValues(cmd *cobra.Command, flags []cli.PluginFlag) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, flag := range flags {
+		if cmd.Flags().Lookup(flag.Name) == nil {
+			continue
+		}
+		if val, err := GetFlagValue(cmd.Flags(), flag.Name); err == nil {
+			values[flag.Name] = val
+		}
+	}
+	return values
+}
+
+// flagConflict reports why a plugin flag cannot be added, or "" if it can.
+func flagConflict(rootCmd, parentCmd *cobra.Command, flag cli.PluginFlag) string {
+	for cmd := parentCmd; cmd != nil; cmd = cmd.Parent() {
+		if cmd.PersistentFlags().Lookup(flag.Name) != nil {
+			return fmt.Sprintf("is already defined by %s", describeCommand(cmd))
+		}
+		if flag.Shorthand != "" && cmd.PersistentFlags().ShorthandLookup(flag.Shorthand) != nil {
+			return fmt.Sprintf("shorthand -%s is already defined by %s", flag.Shorthand, describeCommand(cmd))
+		}
+		if cmd == rootCmd {
+			break
+		}
+	}
+	if flag.Name == "help" || flag.Shorthand == "h" {
+		return "is reserved for help"
+	}
+	return ""
+}
+
+// findSubcommand finds the subcommand of cmd with the given name or alias.
+func findSubcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// commandPath returns the path of cmd below rootCmd.
+func commandPath(cmd, rootCmd *cobra.Command) string {
+	var segments []string
+	for ; cmd != nil && cmd != rootCmd; cmd = cmd.Parent() {
+		segments = append([]string{cmd.Name()}, segments...)
+	}
+	return strings.Join(segments, " ")
+}
+
+// describeCommand names the origin of a command for conflict messages.
+func describeCommand(cmd *cobra.Command) string {
+	if plugin, ok := cmd.Annotations["plugin"]; ok {
+		return fmt.Sprintf("plugin %s", plugin)
+	}
+	if operationID, ok := cmd.Annotations["operationID"]; ok {
+		return fmt.Sprintf("operation %s", operationID)
+	}
+	if !cmd.HasParent() {
+		return "the root command"
+	}
+	return fmt.Sprintf("command %q", cmd.CommandPath())
+}
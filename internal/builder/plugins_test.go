@@ -0,0 +1,216 @@
+package builder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+const pluginTestSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Test API", "version": "1.0.0"},
+	"paths": {
+		"/users": {
+			"get": {
+				"operationId": "listUsers",
+				"tags": ["users"],
+				"x-cli-aliases": ["ls"],
+				"responses": {"200": {"description": "OK"}}
+			}
+		}
+	}
+}`
+
+func buildPluginTestTree(t *testing.T) (*Builder, *cobra.Command) {
+	t.Helper()
+
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(pluginTestSpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	b := NewBuilder(spec, &BuilderConfig{RootName: "test-cli", GroupByTags: true})
+	rootCmd, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to build command tree: %v", err)
+	}
+	rootCmd.PersistentFlags().StringP("output", "o", "json", "Output format")
+
+	return b, rootCmd
+}
+
+func TestBuilder_MountPluginCommands(t *testing.T) {
+	b, rootCmd := buildPluginTestTree(t)
+
+	var gotArgs []string
+	var gotValues map[string]interface{}
+	run := func(cmd *cobra.Command, args []string, values map[string]interface{}) error {
+		gotArgs = args
+		gotValues = values
+		return nil
+	}
+
+	conflicts, err := b.MountPluginCommands([]*PluginCommand{
+		{
+			Plugin: "exporter",
+			Command: cli.PluginCommand{
+				Name:        "export",
+				Description: "Export users",
+				Parent:      "users",
+				Args:        "<file>",
+				Flags: []cli.PluginFlag{
+					{Name: "format", Shorthand: "f", Default: "csv", Enum: []string{"csv", "json"}},
+					{Name: "limit", Type: "int", Default: 10},
+					{Name: "all", Type: "bool"},
+				},
+			},
+			Run: run,
+		},
+		{
+			Plugin:  "k8s",
+			Command: cli.PluginCommand{Name: "deploy", Parent: "tools k8s"},
+			Run:     run,
+		},
+	})
+	if err != nil {
+		t.Fatalf("MountPluginCommands() error = %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+
+	rootCmd.SetArgs([]string{"users", "export", "out.csv", "--limit", "5", "-f", "json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != "out.csv" {
+		t.Errorf("Expected args [out.csv], got %v", gotArgs)
+	}
+	if gotValues["limit"] != 5 || gotValues["format"] != "json" || gotValues["all"] != false {
+		t.Errorf("Unexpected flag values: %v", gotValues)
+	}
+
+	exportCmd, _, err := rootCmd.Find([]string{"users", "export"})
+	if err != nil || exportCmd.Annotations["plugin"] != "exporter" {
+		t.Errorf("Expected export command from plugin exporter, got %v (%v)", exportCmd, err)
+	}
+
+	toolsCmd, _, err := rootCmd.Find([]string{"tools"})
+	if err != nil || toolsCmd.GroupID != PluginGroupID {
+		t.Errorf("Expected tools group in plugin help group, got %v (%v)", toolsCmd, err)
+	}
+	if _, _, err := rootCmd.Find([]string{"tools", "k8s", "deploy"}); err != nil {
+		t.Errorf("Expected nested plugin command: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"users", "export", "out", "--format", "xml"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "not in allowed values") {
+		t.Errorf("Expected enum validation error, got %v", err)
+	}
+
+	completions, _ := exportCmd.GetFlagCompletionFunc("format")
+	if completions == nil {
+		t.Fatal("Expected completion for enum flag")
+	}
+	values, _ := completions(exportCmd, nil, "")
+	if len(values) != 2 {
+		t.Errorf("Expected 2 completions, got %v", values)
+	}
+}
+
+func TestBuilder_MountPluginCommands_Conflicts(t *testing.T) {
+	b, rootCmd := buildPluginTestTree(t)
+
+	run := func(*cobra.Command, []string, map[string]interface{}) error { return nil }
+	conflicts, err := b.MountPluginCommands([]*PluginCommand{
+		{Plugin: "dup", Command: cli.PluginCommand{Name: "users"}, Run: run},
+		{Plugin: "dup", Command: cli.PluginCommand{Name: "list-users", Parent: "users"}, Run: run},
+		{Plugin: "alias", Command: cli.PluginCommand{Name: "search", Parent: "users", Aliases: []string{"ls", "s"}}, Run: run},
+		{
+			Plugin: "flags",
+			Command: cli.PluginCommand{
+				Name: "report",
+				Flags: []cli.PluginFlag{
+					{Name: "output"},
+					{Name: "out", Shorthand: "o"},
+					{Name: "verbose"},
+				},
+			},
+			Run: run,
+		},
+		{Plugin: "second", Command: cli.PluginCommand{Name: "report"}, Run: run},
+	})
+	if err != nil {
+		t.Fatalf("MountPluginCommands() error = %v", err)
+	}
+
+	var reasons []string
+	for _, c := range conflicts {
+		reasons = append(reasons, c.String())
+	}
+	expected := []string{
+		"plugin dup: users: name is already used by command",
+		"plugin dup: users list-users: name is already used by operation listUsers",
+		`plugin alias: users search: alias "ls" is already used by operation listUsers`,
+		"plugin flags: report: flag --output is already defined by the root command",
+		"plugin flags: report: flag --out shorthand -o is already defined by the root command",
+		"plugin second: report: name is already used by plugin flags",
+	}
+	if len(reasons) != len(expected) {
+		t.Fatalf("Expected %d conflicts, got %d: %v", len(expected), len(reasons), reasons)
+	}
+	for i, want := range expected {
+		if !strings.HasPrefix(reasons[i], want) {
+			t.Errorf("Conflict %d = %q, want prefix %q", i, reasons[i], want)
+		}
+	}
+
+	searchCmd, _, err := rootCmd.Find([]string{"users", "s"})
+	if err != nil || searchCmd.Name() != "search" {
+		t.Errorf("Expected search to keep its free alias, got %v (%v)", searchCmd, err)
+	}
+	reportCmd, _, _ := rootCmd.Find([]string{"report"})
+	if reportCmd.Flags().Lookup("verbose") == nil || reportCmd.LocalFlags().Lookup("out") != nil {
+		t.Error("Expected only the non-conflicting flag on report")
+	}
+}
+
+func TestBuilder_MountPluginCommands_PassThrough(t *testing.T) {
+	b, rootCmd := buildPluginTestTree(t)
+
+	var gotArgs []string
+	_, err := b.MountPluginCommands([]*PluginCommand{{
+		Plugin:      "aws",
+		Command:     cli.PluginCommand{Name: "s3"},
+		PassThrough: true,
+		Run: func(cmd *cobra.Command, args []string, values map[string]interface{}) error {
+			gotArgs = args
+			if values != nil {
+				t.Errorf("Expected no values for pass-through command, got %v", values)
+			}
+			return nil
+		},
+	}})
+	if err != nil {
+		t.Fatalf("MountPluginCommands() error = %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"s3", "ls", "--recursive", "-o", "text"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.Join(gotArgs, " ") != "ls --recursive -o text" {
+		t.Errorf("Expected raw args, got %v", gotArgs)
+	}
+}
+
+func TestBuilder_MountPluginCommands_NotBuilt(t *testing.T) {
+	b := NewBuilder(nil, nil)
+	if _, err := b.MountPluginCommands(nil); err == nil {
+		t.Error("Expected error before Build()")
+	}
+}
@@ -0,0 +1,188 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/cache"
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/state"
+)
+
+// recentCommandLimit is how many history entries count as recently run
+// commands when warning about spec changes.
+const recentCommandLimit = 100
+
+// specDrift is a spec that changed when it was refreshed.
+type specDrift struct {
+	specURL string
+	oldSpec *openapi.ParsedSpec
+	newSpec *openapi.ParsedSpec
+}
+
+// driftRecorder collects the specs that changed while loading, so they
+// can be reported once the CLI is set up.
+type driftRecorder struct {
+	mu     sync.Mutex
+	drifts []specDrift
+}
+
+// record is an openapi.Loader OnChange callback.
+func (r *driftRecorder) record(_ context.Context, specURL string, oldSpec, newSpec *openapi.ParsedSpec, _ []*openapi.DetectedChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drifts = append(r.drifts, specDrift{specURL: specURL, oldSpec: oldSpec, newSpec: newSpec})
+}
+
+// newSpecLoader creates a loader that caches remote specs and records
+// the ones that changed since they were cached. With signing configured,
+// it refuses unsigned specs and warns when it falls back to the last
+// verified copy.
+func (rt *Runtime) newSpecLoader(runtimeConfig *RuntimeConfig) (*openapi.Loader, error) {
+	var specCache openapi.SpecCache
+	if c, err := cache.NewSpecCache(runtimeConfig.CLIName); err == nil {
+		specCache = &cache.LoaderCache{Cache: c}
+	}
+	loader := openapi.NewLoader(specCache)
+	loader.OnChange = rt.drift.record
+
+	if runtimeConfig.Signing != nil {
+		verifier, err := openapi.NewSpecVerifier(runtimeConfig.Signing.PublicKeys)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec signing keys: %w", err)
+		}
+		loader.Verifier = verifier
+
+		w := runtimeConfig.Stderr
+		if w == nil {
+			w = os.Stderr
+		}
+		loader.OnRejected = func(_ context.Context, _ string, err error) {
+			_, _ = fmt.Fprintf(w, "Warning: %v; using the last verified copy\n", err)
+		}
+	}
+	return loader, nil
+}
+
+// warnSpecDrift tells the user about breaking changes to commands they
+// ran recently, in specs that changed since they were cached.
+func (rt *Runtime) warnSpecDrift(runtimeConfig *RuntimeConfig) {
+	if len(rt.drift.drifts) == 0 {
+		return
+	}
+
+	history, err := state.NewHistory(runtimeConfig.CLIName, 0)
+	if err != nil {
+		return
+	}
+	recent := recentCommands(history, runtimeConfig.CLIName)
+	if len(recent) == 0 {
+		return
+	}
+
+	w := runtimeConfig.Stderr
+	if w == nil {
+		w = os.Stderr
+	}
+
+	specs := make(map[string]cli.APISpec, len(runtimeConfig.Specs))
+	for _, spec := range runtimeConfig.Specs {
+		specs[spec.OpenAPIURL] = spec
+	}
+
+	for _, drift := range rt.drift.drifts {
+		spec := specs[drift.specURL]
+		changes, err := builder.DiffCommands(drift.oldSpec, drift.newSpec, &builder.BuilderConfig{
+			GroupByTags:             true,
+			FlattenSingleOperations: true,
+			Tags:                    spec.Tags,
+			ExcludeTags:             spec.ExcludeTags,
+		})
+		if err != nil {
+			continue
+		}
+
+		var affected []*builder.CommandChange
+		for _, change := range changes {
+			if !change.Breaking {
+				continue
+			}
+			change = mountChange(change, spec.Mount)
+			if ranRecently(recent, change.Command) {
+				affected = append(affected, change)
+			}
+		}
+		writeDriftNotice(w, drift.newSpec, affected)
+	}
+}
+
+// writeDriftNotice writes the changes to recently run commands of a spec.
+func writeDriftNotice(w io.Writer, spec *openapi.ParsedSpec, changes []*builder.CommandChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	info := spec.GetInfo()
+	_, _ = fmt.Fprintf(w, "Notice: %s changed to version %s, affecting commands you ran recently:\n", info.Title, info.Version)
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(w, "  - %s\n", change)
+	}
+}
+
+// mountChange returns the change with its command paths under mount.
+func mountChange(change *builder.CommandChange, mount string) *builder.CommandChange {
+	if mount == "" {
+		return change
+	}
+
+	mounted := *change
+	mounted.Command = strings.TrimSpace(mount + " " + change.Command)
+	if change.Kind == builder.CommandRenamed {
+		mounted.Old = strings.TrimSpace(mount + " " + change.Old)
+		mounted.New = strings.TrimSpace(mount + " " + change.New)
+	}
+	return &mounted
+}
+
+// recentCommands returns the words of the recently run commands, without
+// the CLI name.
+func recentCommands(history *state.History, cliName string) [][]string {
+	var commands [][]string
+	for _, entry := range history.GetRecent(recentCommandLimit) {
+		words := strings.Fields(entry.Command)
+		if len(words) > 0 && words[0] == cliName {
+			words = words[1:]
+		}
+		if len(words) > 0 {
+			commands = append(commands, words)
+		}
+	}
+	return commands
+}
+
+// ranRecently reports whether any recent command runs the command path.
+func ranRecently(recent [][]string, path string) bool {
+	want := strings.Fields(path)
+	for _, words := range recent {
+		if len(words) < len(want) {
+			continue
+		}
+		match := true
+		for i, word := range want {
+			if words[i] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
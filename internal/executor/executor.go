@@ -13,7 +13,8 @@
 //  4. Execute HTTP request
 //  5. Handle response (sync, async, workflow)
 //  6. Format and display output
-//  7. Update state and history
+//  7. Follow response links (--then) or suggest them as next steps
+//  8. Update state and history
 //
 // # Features
 //
@@ -23,7 +24,12 @@
 //   - Request body construction from flags
 //   - Async operation polling with progress display
 //   - Multi-step workflow execution
-//   - Response formatting (JSON, YAML, table, etc.)
+//   - Next-step hints and --then chaining from OpenAPI response links
+//   - Plugin hooks before requests, after responses, on errors and
+//     before output
+//   - Response formatting (JSON, YAML, table, etc.), streamed for large
+//     lists with x-cli-output stream settings
+//   - Paging of long output on a terminal, unless --no-pager is set
 //   - Error handling with helpful messages
 //
 // # Example Usage
@@ -70,11 +76,13 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/CliForge/cliforge/internal/builder"
 	"github.com/CliForge/cliforge/pkg/auth"
+	"github.com/CliForge/cliforge/pkg/cli/interactive"
 	"github.com/CliForge/cliforge/pkg/openapi"
 	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/CliForge/cliforge/pkg/progress"
@@ -89,9 +97,11 @@ type Executor struct {
 	httpClient    *http.Client
 	baseURL       string
 	authManager   *auth.Manager
+	authName      string
 	outputManager *output.Manager
 	stateManager  *state.Manager
 	progressMgr   *progress.Manager
+	hookRunner    HookRunner
 }
 
 // ExecutorConfig configures the executor.
@@ -102,6 +112,11 @@ type ExecutorConfig struct {
 	OutputManager *output.Manager
 	StateManager  *state.Manager
 	ProgressMgr   *progress.Manager
+	// Hooks provides the plugin hooks run around every API call.
+	Hooks HookRunner
+	// AuthName is the authenticator of AuthManager to use. Empty uses
+	// its default.
+	AuthName string
 }
 
 // NewExecutor creates a new command executor.
@@ -123,9 +138,11 @@ func NewExecutor(spec *openapi.ParsedSpec, config *ExecutorConfig) (*Executor, e
 		httpClient:    httpClient,
 		baseURL:       config.BaseURL,
 		authManager:   config.AuthManager,
+		authName:      config.AuthName,
 		outputManager: config.OutputManager,
 		stateManager:  config.StateManager,
 		progressMgr:   config.ProgressMgr,
+		hookRunner:    config.Hooks,
 	}, nil
 }
 
@@ -157,6 +174,26 @@ func (e *Executor) Execute(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("operation %s not found in spec", operationID)
 	}
 
+	// Select the HTTP client, honoring --record and --replay
+	client, err := e.clientForCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Run plugin hooks around every request, including workflow steps
+	hooks := e.hooksForCommand(cmd)
+	if hooks != nil {
+		hooked := *client
+		hooked.Transport = hooks.transport(client.Transport)
+		client = &hooked
+		ctx = withOperationID(ctx, operationID)
+	}
+
+	// The client and hooks belong to this command only; the executor is
+	// shared with later commands
+	ctx = withCommandRun(ctx, &commandRun{client: client, hooks: hooks})
+	cmd.SetContext(ctx)
+
 	// Check if operation uses workflow
 	if operation.CLIWorkflow != nil {
 		return e.executeWorkflow(ctx, cmd, operation)
@@ -166,8 +203,51 @@ func (e *Executor) Execute(cmd *cobra.Command, args []string) error {
 	return e.executeHTTPOperation(ctx, cmd, operation, args)
 }
 
+// commandRunKey is the context key for the commandRun of a command.
+type commandRunKey struct{}
+
+// commandRun holds the HTTP client and hook chain of one command
+// invocation, including the links it follows with --then.
+type commandRun struct {
+	client *http.Client
+	hooks  *hookChain
+}
+
+// withCommandRun attaches run to ctx.
+func withCommandRun(ctx context.Context, run *commandRun) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, commandRunKey{}, run)
+}
+
+// commandRunFrom returns the commandRun of ctx, or nil outside a command.
+func commandRunFrom(ctx context.Context) *commandRun {
+	if ctx == nil {
+		return nil
+	}
+	run, _ := ctx.Value(commandRunKey{}).(*commandRun)
+	return run
+}
+
+// client returns the HTTP client of the command running in ctx, falling
+// back to the executor's client.
+func (e *Executor) client(ctx context.Context) *http.Client {
+	if run := commandRunFrom(ctx); run != nil && run.client != nil {
+		return run.client
+	}
+	return e.httpClient
+}
+
 // executeHTTPOperation executes a single HTTP operation.
 func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command, op *openapi.Operation, args []string) error {
+	// Check the links chained with --then before running anything
+	if then, _ := cmd.Flags().GetStringArray("then"); len(then) > 0 {
+		if err := e.checkLinkChain(op, then); err != nil {
+			return err
+		}
+	}
+
 	// Execute preflight checks if defined
 	if len(op.CLIPreflight) > 0 {
 		if _, err := e.executePreflightChecks(ctx, op.CLIPreflight); err != nil {
@@ -216,7 +296,7 @@ func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command,
 		_ = prog.Update("Sending request...")
 	}
 
-	resp, err := e.httpClient.Do(req)
+	resp, err := e.client(ctx).Do(req)
 	if err != nil {
 		if prog != nil {
 			_ = prog.Failure("Request failed")
@@ -225,6 +305,14 @@ func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command,
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	// Stream large list responses to the output as they arrive
+	if e.streamsOutput(ctx, cmd, op, resp) {
+		if prog != nil {
+			_ = prog.Success("Request completed")
+		}
+		return e.formatOutputStream(cmd, resp, op)
+	}
+
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -234,6 +322,15 @@ func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command,
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// Check the response against the spec when asked to
+	var contractErr error
+	if validate, _ := cmd.Flags().GetBool("validate-response"); validate {
+		if violations := validateResponse(op, resp, body); len(violations) > 0 {
+			writeContractDiff(cmd.ErrOrStderr(), op, resp.StatusCode, violations)
+			contractErr = &ResponseValidationError{OperationID: op.OperationID, StatusCode: resp.StatusCode, Violations: len(violations)}
+		}
+	}
+
 	// Check for errors
 	if resp.StatusCode >= 400 {
 		if prog != nil {
@@ -244,7 +341,13 @@ func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command,
 
 	// Handle async operations
 	if op.CLIAsync != nil && op.CLIAsync.Enabled {
-		return e.handleAsyncOperation(ctx, resp, body, op, prog)
+		if err := e.handleAsyncOperation(ctx, resp, body, op, prog); err != nil {
+			return err
+		}
+		if contractErr != nil {
+			return contractErr
+		}
+		return e.followLinks(ctx, cmd, op, args, req, resp, body)
 	}
 
 	// Success
@@ -253,7 +356,15 @@ func (e *Executor) executeHTTPOperation(ctx context.Context, cmd *cobra.Command,
 	}
 
 	// Format and output response
-	return e.formatOutput(cmd, resp, body, op)
+	if err := e.formatOutput(cmd, resp, body, op); err != nil {
+		return err
+	}
+	if contractErr != nil {
+		return contractErr
+	}
+
+	// Suggest or run the operations linked from the response
+	return e.followLinks(ctx, cmd, op, args, req, resp, body)
 }
 
 // buildRequest builds an HTTP request from command flags and operation.
@@ -266,8 +377,9 @@ func (e *Executor) buildRequest(ctx context.Context, cmd *cobra.Command, op *ope
 
 	// Build request body
 	var bodyReader io.Reader
+	var bodyData map[string]interface{}
 	if op.Operation.RequestBody != nil {
-		bodyData, err := builder.BuildRequestBody(cmd)
+		bodyData, err = builder.BuildRequestBody(cmd)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build request body: %w", err)
 		}
@@ -281,6 +393,11 @@ func (e *Executor) buildRequest(ctx context.Context, cmd *cobra.Command, op *ope
 		}
 	}
 
+	// Check the request against the spec before sending it
+	if err := e.validateRequest(cmd, op, args, bodyData); err != nil {
+		return nil, err
+	}
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, op.Method, reqURL, bodyReader)
 	if err != nil {
@@ -323,31 +440,17 @@ func (e *Executor) buildURL(cmd *cobra.Command, op *openapi.Operation, args []st
 
 	// Build path with path parameters
 	path := op.Path
-	pathParams := extractPathParams(op.Path)
-
-	// Get parameter values from flags or args
-	params, _ := builder.BuildRequestParams(cmd)
-
-	// Replace path parameters
-	argIndex := 0
-	for _, paramName := range pathParams {
-		var paramValue string
-
-		// Try to get from flags first
-		if val, ok := params[paramName]; ok {
-			paramValue = fmt.Sprintf("%v", val)
-		} else if argIndex < len(args) {
-			// Use positional argument
-			paramValue = args[argIndex]
-			argIndex++
-		} else {
-			return "", fmt.Errorf("missing value for path parameter: %s", paramName)
-		}
-
-		// Replace in path
+	pathValues, err := pathParamValues(cmd, op, args)
+	if err != nil {
+		return "", err
+	}
+	for paramName, paramValue := range pathValues {
 		path = strings.ReplaceAll(path, fmt.Sprintf("{%s}", paramName), url.PathEscape(paramValue))
 	}
 
+	// Get parameter values from flags
+	params, _ := builder.BuildRequestParams(cmd)
+
 	// Build full URL
 	fullURL := strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
 
@@ -372,16 +475,38 @@ func (e *Executor) buildURL(cmd *cobra.Command, op *openapi.Operation, args []st
 	return parsedURL.String(), nil
 }
 
+// pathParamValues returns the values of the path parameters of an
+// operation, from flags or else positional arguments.
+func pathParamValues(cmd *cobra.Command, op *openapi.Operation, args []string) (map[string]string, error) {
+	params, _ := builder.BuildRequestParams(cmd)
+
+	values := make(map[string]string)
+	argIndex := 0
+	for _, paramName := range extractPathParams(op.Path) {
+		// Try to get from flags first
+		if val, ok := params[paramName]; ok {
+			values[paramName] = fmt.Sprintf("%v", val)
+		} else if argIndex < len(args) {
+			// Use positional argument
+			values[paramName] = args[argIndex]
+			argIndex++
+		} else {
+			return nil, fmt.Errorf("missing value for path parameter: %s", paramName)
+		}
+	}
+	return values, nil
+}
+
 // applyAuth applies authentication to the request.
 func (e *Executor) applyAuth(ctx context.Context, req *http.Request) error {
 	// Get token from auth manager
-	token, err := e.authManager.GetToken(ctx, "")
+	token, err := e.authManager.GetToken(ctx, e.authName)
 	if err != nil {
 		return err
 	}
 
 	// Get authenticator to apply headers
-	authenticator, err := e.authManager.GetAuthenticator("")
+	authenticator, err := e.authManager.GetAuthenticator(e.authName)
 	if err != nil {
 		return err
 	}
@@ -503,7 +628,7 @@ func (e *Executor) pollStatus(ctx context.Context, statusEndpoint string, initia
 		return nil, err
 	}
 
-	resp, err := e.httpClient.Do(req)
+	resp, err := e.client(ctx).Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -533,6 +658,12 @@ func (e *Executor) formatOutput(cmd *cobra.Command, resp *http.Response, body []
 		}
 	}
 
+	// Let plugins enrich or redact the data
+	data, err := e.runOutputHooks(cmd.Context(), op.OperationID, data)
+	if err != nil {
+		return err
+	}
+
 	// Get output format from flags
 	outputFormat, _ := cmd.Flags().GetString("output")
 
@@ -541,7 +672,12 @@ func (e *Executor) formatOutput(cmd *cobra.Command, resp *http.Response, body []
 		// Apply output configuration from operation
 		formatConfig := e.outputManager.ApplyOutputRules(op.CLIOutput)
 
-		return e.outputManager.FormatWithConfig(cmd.OutOrStdout(), data, outputFormat, formatConfig)
+		out := e.pagedOutput(cmd, op)
+		err := e.outputManager.FormatWithConfig(out, data, outputFormat, formatConfig)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		return err
 	}
 
 	// Fallback to simple JSON output
@@ -551,6 +687,79 @@ func (e *Executor) formatOutput(cmd *cobra.Command, resp *http.Response, body []
 	return nil
 }
 
+// pagedOutput returns the writer the output of op goes to: stdout through
+// the output manager's pager, unless paging is off for the command.
+func (e *Executor) pagedOutput(cmd *cobra.Command, op *openapi.Operation) io.WriteCloser {
+	var pager *output.Pager
+	if e.outputManager != nil && pagesOutput(cmd, op) {
+		pager = e.outputManager.GetPager()
+	}
+	return pager.Start(cmd.OutOrStdout())
+}
+
+// pagesOutput reports whether the output of op may be paged. Watched
+// operations keep writing as events arrive, and --no-pager, --quiet and
+// --interactive leave the terminal to the command.
+func pagesOutput(cmd *cobra.Command, op *openapi.Operation) bool {
+	if op.CLIWatch != nil && op.CLIWatch.Enabled {
+		return false
+	}
+	for _, name := range []string{"no-pager", "quiet", "interactive"} {
+		if set, _ := cmd.Flags().GetBool(name); set {
+			return false
+		}
+	}
+	return true
+}
+
+// streamsOutput reports whether a response is formatted while it is read,
+// for operations whose x-cli-output enables streaming. Responses that
+// something else needs whole, like output hooks, response validation or
+// links followed with --then, are read first.
+func (e *Executor) streamsOutput(ctx context.Context, cmd *cobra.Command, op *openapi.Operation, resp *http.Response) bool {
+	if op.CLIOutput == nil || op.CLIOutput.Stream == nil || e.outputManager == nil || hooksFrom(ctx) != nil {
+		return false
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || (op.CLIAsync != nil && op.CLIAsync.Enabled) {
+		return false
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "json") {
+		return false
+	}
+	if validate, _ := cmd.Flags().GetBool("validate-response"); validate {
+		return false
+	}
+	then, _ := cmd.Flags().GetStringArray("then")
+	return len(then) == 0
+}
+
+// formatOutputStream formats the items of a response as they are read.
+func (e *Executor) formatOutputStream(cmd *cobra.Command, resp *http.Response, op *openapi.Operation) error {
+	outputFormat, _ := cmd.Flags().GetString("output")
+	formatConfig := e.outputManager.ApplyOutputRules(op.CLIOutput)
+
+	var pager *output.Pager
+	if pagesOutput(cmd, op) {
+		pager = e.outputManager.GetPager()
+	}
+	out := pager.StartStream(cmd.OutOrStdout())
+	err := e.outputManager.FormatStream(out, resp.Body, outputFormat, formatConfig)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// workflowClient returns the HTTP client of workflow steps, which adds the
+// credentials of the executor's authenticator to requests to its API.
+func (e *Executor) workflowClient(ctx context.Context) *http.Client {
+	client := e.client(ctx)
+	if e.authManager == nil || e.baseURL == "" {
+		return client
+	}
+	return auth.NewClient(client, e.authManager, map[string]string{e.baseURL: e.authName})
+}
+
 // executeWorkflow executes a workflow operation.
 func (e *Executor) executeWorkflow(ctx context.Context, cmd *cobra.Command, op *openapi.Operation) error {
 	// Convert CLI workflow to workflow engine format
@@ -559,18 +768,38 @@ func (e *Executor) executeWorkflow(ctx context.Context, cmd *cobra.Command, op *
 		return fmt.Errorf("failed to convert workflow: %w", err)
 	}
 
-	// Create workflow executor
-	workflowExec, err := workflow.NewExecutor(wf, e.httpClient, nil)
+	// Create workflow executor; its steps call the API with the user's
+	// credentials
+	workflowExec, err := workflow.NewExecutor(wf, e.workflowClient(ctx), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create workflow executor: %w", err)
 	}
 
-	// Start workflow progress
-	var prog progress.Progress
+	// Prompt steps ask on the terminal unless --yes answers them
+	workflowExec.SetPrompter(interactive.NewPrompter(&interactive.PrompterConfig{
+		Input:              cmd.InOrStdin(),
+		Output:             cmd.OutOrStdout(),
+		DisableInteractive: !isTerminal(os.Stdin),
+	}))
+	if yes, err := cmd.Flags().GetBool("yes"); err == nil {
+		workflowExec.SetAssumeYes(yes)
+	}
+
+	// Keep warnings out of formatted output
+	workflowExec.SetWarningOutput(cmd.ErrOrStderr())
+
+	// Report compensations if the workflow rolls back
 	if e.progressMgr != nil {
-		prog, _ = e.progressMgr.StartProgress("Executing workflow", len(wf.Steps))
-		if prog != nil {
-			defer func() { _ = prog.Stop() }()
+		workflowExec.SetRollbackObserver(progress.NewRollbackReporter(e.progressMgr))
+	} else {
+		workflowExec.SetRollbackObserver(workflow.NewTextRollbackObserver(cmd.ErrOrStderr()))
+	}
+
+	// Show workflow progress, driven by the same events as the event log
+	if e.progressMgr != nil {
+		integration := progress.NewWorkflowIntegration(e.progressMgr)
+		if err := integration.OnWorkflowStart(wf); err == nil {
+			workflowExec.AddEventListener(integration)
 		}
 	}
 
@@ -578,21 +807,29 @@ func (e *Executor) executeWorkflow(ctx context.Context, cmd *cobra.Command, op *
 	execCtx := workflow.NewExecutionContext(nil)
 	state, err := workflowExec.Execute(execCtx)
 	if err != nil {
-		if prog != nil {
-			_ = prog.Failure("Workflow failed")
+		if state != nil {
+			if state.Rollback != nil && len(state.Rollback.Incomplete()) > 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Some compensations did not succeed. Retry them with: %s workflow rollback %s\n", cmd.Root().Name(), state.WorkflowID)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "See what happened with: %s workflow timeline %s\n", cmd.Root().Name(), state.WorkflowID)
 		}
 		return fmt.Errorf("workflow execution failed: %w", err)
 	}
 
-	// Success
-	if prog != nil {
-		_ = prog.Success("Workflow completed")
-	}
-
 	// Format output
 	if e.outputManager != nil {
+		data, err := e.runOutputHooks(ctx, op.OperationID, state)
+		if err != nil {
+			return err
+		}
 		outputFormat, _ := cmd.Flags().GetString("output")
-		return e.outputManager.Format(cmd.OutOrStdout(), state, outputFormat)
+
+		out := e.pagedOutput(cmd, op)
+		err = e.outputManager.Format(out, data, outputFormat)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		return err
 	}
 
 	return nil
@@ -600,30 +837,16 @@ func (e *Executor) executeWorkflow(ctx context.Context, cmd *cobra.Command, op *
 
 // convertToWorkflow converts CLI workflow to workflow engine format.
 func (e *Executor) convertToWorkflow(cliWorkflow *openapi.CLIWorkflow) (*workflow.Workflow, error) {
-	wf := &workflow.Workflow{
-		Steps: make([]*workflow.Step, 0, len(cliWorkflow.Steps)),
-	}
-
-	for _, cliStep := range cliWorkflow.Steps {
-		step := &workflow.Step{
-			ID:        cliStep.ID,
-			Type:      workflow.StepTypeAPICall,
-			Condition: cliStep.Condition,
-		}
-
-		if cliStep.Request != nil {
-			step.APICall = &workflow.APICallStep{
-				Method:   cliStep.Request.Method,
-				Endpoint: cliStep.Request.URL,
-				Headers:  cliStep.Request.Headers,
-				Body:     cliStep.Request.Body,
-			}
-		}
+	return workflow.FromCLIWorkflow(cliWorkflow)
+}
 
-		wf.Steps = append(wf.Steps, step)
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
-
-	return wf, nil
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 // extractPathParams extracts path parameter names from a path template.
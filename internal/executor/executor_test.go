@@ -336,10 +336,13 @@ func TestExecutor_BuildRequest(t *testing.T) {
 			setupCmd: func() *cobra.Command {
 				cmd := &cobra.Command{Use: "create"}
 				cmd.Annotations = map[string]string{
-					"body:name": "name",
+					"body:username": "username",
+					"body:email":    "email",
 				}
-				cmd.Flags().String("name", "", "Name")
-				_ = cmd.Flags().Set("name", "test-user")
+				cmd.Flags().String("username", "", "Username")
+				cmd.Flags().String("email", "", "Email")
+				_ = cmd.Flags().Set("username", "test-user")
+				_ = cmd.Flags().Set("email", "test@example.com")
 				return cmd
 			},
 			wantMethod:      "POST",
@@ -744,6 +747,50 @@ func TestExecutor_FormatOutput(t *testing.T) {
 	}
 }
 
+func TestPagesOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []string
+		op    *openapi.Operation
+		want  bool
+	}{
+		{name: "paged", op: &openapi.Operation{}, want: true},
+		{name: "no pager", flags: []string{"--no-pager"}, op: &openapi.Operation{}},
+		{name: "quiet", flags: []string{"--quiet"}, op: &openapi.Operation{}},
+		{name: "interactive", flags: []string{"--interactive"}, op: &openapi.Operation{}},
+		{name: "watched", op: &openapi.Operation{CLIWatch: &openapi.CLIWatch{Enabled: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			for _, name := range []string{"no-pager", "quiet", "interactive"} {
+				cmd.Flags().Bool(name, false, "")
+			}
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			if got := pagesOutput(cmd, tt.op); got != tt.want {
+				t.Errorf("pagesOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Output that is not a terminal is written straight out
+	executor := &Executor{outputManager: output.NewManager()}
+	executor.outputManager.SetPager(output.NewPager())
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", "json", "Output format")
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	if err := executor.formatOutput(cmd, &http.Response{StatusCode: 200}, []byte(`{"id": 1}`), &openapi.Operation{}); err != nil {
+		t.Fatalf("formatOutput() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"id": 1`) {
+		t.Errorf("Expected the output unpaged, got %q", buf.String())
+	}
+}
+
 func TestExecutor_ExecuteHTTPOperation(t *testing.T) {
 	callCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -798,6 +845,46 @@ func TestExecutor_ExecuteHTTPOperation(t *testing.T) {
 	}
 }
 
+func TestExecutor_ExecuteHTTPOperationStreamsOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"total": 2, "users": [{"id": 1}, {"id": 2}]}`))
+	}))
+	defer server.Close()
+
+	spec, err := openapi.NewParser().ParseFile(context.Background(), "../../examples/openapi/swagger2-example.json")
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	executor, err := NewExecutor(spec, &ExecutorConfig{BaseURL: server.URL, OutputManager: output.NewManager()})
+	if err != nil {
+		t.Fatalf("Failed to create executor: %v", err)
+	}
+
+	operations, _ := spec.GetOperations()
+	var listOp *openapi.Operation
+	for _, op := range operations {
+		if op.OperationID == "listUsers" {
+			listOp = op
+			break
+		}
+	}
+	streamed := *listOp
+	streamed.CLIOutput = &openapi.CLIOutput{Stream: &openapi.OutputStream{ItemsPath: "users"}}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{Use: "list"}
+	cmd.Flags().String("output", "jsonl", "Output format")
+	cmd.SetOut(&out)
+
+	if err := executor.executeHTTPOperation(context.Background(), cmd, &streamed, nil); err != nil {
+		t.Fatalf("executeHTTPOperation() error = %v", err)
+	}
+	if out.String() != "{\"id\":1}\n{\"id\":2}\n" {
+		t.Errorf("Expected the streamed items, got %q", out.String())
+	}
+}
+
 func TestExecutor_ExecuteHTTPOperationWithError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,411 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+// HookRunner provides the plugin hooks run at the executor's hook points.
+// *plugin.Registry implements it.
+type HookRunner interface {
+	Hooks(point plugin.HookPoint) []plugin.RegisteredHook
+	RunHook(ctx context.Context, hook plugin.RegisteredHook, hc *plugin.HookContext) (*plugin.HookContext, error)
+}
+
+// hookChain runs the hooks of a point in order, tracing their changes when
+// trace is set.
+type hookChain struct {
+	runner HookRunner
+	trace  io.Writer
+}
+
+// hooksForCommand returns the hook chain for a command invocation, tracing
+// to stderr with --debug. It is nil when no plugin declares hooks.
+func (e *Executor) hooksForCommand(cmd *cobra.Command) *hookChain {
+	if e.hookRunner == nil {
+		return nil
+	}
+
+	registered := false
+	for _, point := range plugin.HookPoints {
+		if len(e.hookRunner.Hooks(point)) > 0 {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return nil
+	}
+
+	chain := &hookChain{runner: e.hookRunner}
+	if debug, _ := cmd.Flags().GetBool("debug"); debug {
+		chain.trace = cmd.ErrOrStderr()
+	}
+	return chain
+}
+
+// hooksFrom returns the hook chain of the command running in ctx, or nil.
+func hooksFrom(ctx context.Context) *hookChain {
+	if run := commandRunFrom(ctx); run != nil {
+		return run.hooks
+	}
+	return nil
+}
+
+// run passes hc through every matching hook of its point.
+func (h *hookChain) run(ctx context.Context, hc *plugin.HookContext) (*plugin.HookContext, error) {
+	if h == nil {
+		return hc, nil
+	}
+
+	for _, hook := range h.runner.Hooks(hc.Point) {
+		if !hook.Matches(hc.OperationID) {
+			continue
+		}
+
+		// Built-in hooks may change the context in place
+		var before *plugin.HookContext
+		if h.trace != nil {
+			before = snapshotHookContext(hc)
+		}
+
+		result, err := h.runner.RunHook(ctx, hook, hc)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.trace != nil {
+			changes := describeHookChanges(before, result)
+			if len(changes) == 0 {
+				changes = []string{"no changes"}
+			}
+			for _, change := range changes {
+				_, _ = fmt.Fprintf(h.trace, "[hook] %s %s: %s\n", hook.Plugin, hc.Point, change)
+			}
+		}
+		hc = result
+	}
+
+	return hc, nil
+}
+
+// runOutputHooks passes data through the before-output hooks.
+func (e *Executor) runOutputHooks(ctx context.Context, operationID string, data interface{}) (interface{}, error) {
+	hooks := hooksFrom(ctx)
+	if hooks == nil {
+		return data, nil
+	}
+
+	hc, err := hooks.run(ctx, &plugin.HookContext{
+		Point:       plugin.HookBeforeOutput,
+		OperationID: operationID,
+		Output:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hc.Output, nil
+}
+
+// transport wraps base so every request goes through the request, response
+// and error hooks.
+func (h *hookChain) transport(base http.RoundTripper) http.RoundTripper {
+	if h == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &hookTransport{base: base, hooks: h}
+}
+
+// hookTransport is an http.RoundTripper running plugin hooks around a
+// request. Because the workflow engine shares the executor's client, hooks
+// apply to workflow api-call steps too.
+type hookTransport struct {
+	base  http.RoundTripper
+	hooks *hookChain
+}
+
+// operationIDKey is the context key for the operation a request belongs to.
+type operationIDKey struct{}
+
+// withOperationID records the operation a request belongs to so hooks can
+// be limited to it.
+func withOperationID(ctx context.Context, operationID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, operationIDKey{}, operationID)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	operationID, _ := ctx.Value(operationIDKey{}).(string)
+
+	model, err := requestModel(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := t.hooks.run(ctx, &plugin.HookContext{
+		Point:       plugin.HookBeforeRequest,
+		OperationID: operationID,
+		Request:     model,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if hc.Request == nil {
+		return nil, fmt.Errorf("before-request hooks removed the request")
+	}
+	model = hc.Request
+
+	if req, err = applyRequestModel(req, model); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		hc, hookErr := t.hooks.run(ctx, &plugin.HookContext{
+			Point:       plugin.HookOnError,
+			OperationID: operationID,
+			Request:     model,
+			Error:       err.Error(),
+		})
+		if hookErr != nil {
+			return nil, hookErr
+		}
+		if hc.Response == nil {
+			return nil, err
+		}
+		// A hook recovered with a response
+		return responseFromModel(req, hc.Response), nil
+	}
+
+	respModel, err := responseModel(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []plugin.HookPoint{plugin.HookAfterResponse}
+	if respModel.StatusCode >= 400 {
+		points = append(points, plugin.HookOnError)
+	}
+	for _, point := range points {
+		hc, err := t.hooks.run(ctx, &plugin.HookContext{
+			Point:       point,
+			OperationID: operationID,
+			Request:     model,
+			Response:    respModel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if hc.Response == nil {
+			return nil, fmt.Errorf("%s hooks removed the response", point)
+		}
+		respModel = hc.Response
+	}
+
+	return responseFromModel(req, respModel), nil
+}
+
+// requestModel converts a request to its hook model. The body is read and
+// replaced so the request can still be sent.
+func requestModel(req *http.Request) (*plugin.HookRequest, error) {
+	model := &plugin.HookRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: cloneHeader(req.Header),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		model.Body = string(body)
+	}
+
+	return model, nil
+}
+
+// applyRequestModel returns a copy of req with the model's method, URL,
+// headers and body.
+func applyRequestModel(req *http.Request, model *plugin.HookRequest) (*http.Request, error) {
+	var body io.Reader
+	if model.Body != "" {
+		body = strings.NewReader(model.Body)
+	}
+
+	out, err := http.NewRequestWithContext(req.Context(), model.Method, model.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request from hooks: %w", err)
+	}
+	out.Header = http.Header(cloneHeader(model.Header))
+	if out.Header == nil {
+		out.Header = make(http.Header)
+	}
+	return out, nil
+}
+
+// responseModel converts a response to its hook model, consuming the body.
+func responseModel(resp *http.Response) (*plugin.HookResponse, error) {
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &plugin.HookResponse{
+		StatusCode: resp.StatusCode,
+		Header:     cloneHeader(resp.Header),
+		Body:       string(body),
+	}, nil
+}
+
+// responseFromModel builds the response returned to the client.
+func responseFromModel(req *http.Request, model *plugin.HookResponse) *http.Response {
+	header := http.Header(cloneHeader(model.Header))
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", model.StatusCode, http.StatusText(model.StatusCode)),
+		StatusCode:    model.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(model.Body)),
+		ContentLength: int64(len(model.Body)),
+		Request:       req,
+	}
+}
+
+// cloneHeader copies a header into the model's map type.
+func cloneHeader(header map[string][]string) map[string][]string {
+	if header == nil {
+		return nil
+	}
+	clone := make(map[string][]string, len(header))
+	for key, values := range header {
+		clone[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// snapshotHookContext deep-copies a hook context.
+func snapshotHookContext(hc *plugin.HookContext) *plugin.HookContext {
+	encoded, err := json.Marshal(hc)
+	if err != nil {
+		return hc
+	}
+	snapshot := &plugin.HookContext{}
+	if err := json.Unmarshal(encoded, snapshot); err != nil {
+		return hc
+	}
+	return snapshot
+}
+
+// describeHookChanges lists what a hook changed, for --debug.
+func describeHookChanges(before, after *plugin.HookContext) []string {
+	var changes []string
+
+	if before.Request != nil && after.Request != nil {
+		if before.Request.Method != after.Request.Method {
+			changes = append(changes, fmt.Sprintf("method %s -> %s", before.Request.Method, after.Request.Method))
+		}
+		if before.Request.URL != after.Request.URL {
+			changes = append(changes, fmt.Sprintf("url %s -> %s", before.Request.URL, after.Request.URL))
+		}
+		changes = append(changes, describeHeaderChanges("request header", before.Request.Header, after.Request.Header)...)
+		if before.Request.Body != after.Request.Body {
+			changes = append(changes, fmt.Sprintf("request body changed (%d -> %d bytes)", len(before.Request.Body), len(after.Request.Body)))
+		}
+	}
+
+	switch {
+	case before.Response == nil && after.Response != nil:
+		changes = append(changes, fmt.Sprintf("supplied response with status %d", after.Response.StatusCode))
+	case before.Response != nil && after.Response != nil:
+		if before.Response.StatusCode != after.Response.StatusCode {
+			changes = append(changes, fmt.Sprintf("status %d -> %d", before.Response.StatusCode, after.Response.StatusCode))
+		}
+		changes = append(changes, describeHeaderChanges("response header", before.Response.Header, after.Response.Header)...)
+		if before.Response.Body != after.Response.Body {
+			changes = append(changes, fmt.Sprintf("response body changed (%d -> %d bytes)", len(before.Response.Body), len(after.Response.Body)))
+		}
+	}
+
+	if !reflect.DeepEqual(normalizeOutput(before.Output), normalizeOutput(after.Output)) {
+		changes = append(changes, "output changed")
+	}
+
+	return changes
+}
+
+// describeHeaderChanges lists added, changed and removed headers. Values
+// are not shown since they often carry credentials.
+func describeHeaderChanges(kind string, before, after map[string][]string) []string {
+	before, after = cloneHeader(before), cloneHeader(after)
+
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	beforeHeader, afterHeader := http.Header(before), http.Header(after)
+	var changes []string
+	for _, key := range sorted {
+		oldValues, hadOld := beforeHeader[key]
+		newValues, hasNew := afterHeader[key]
+		switch {
+		case !hadOld && hasNew:
+			changes = append(changes, fmt.Sprintf("added %s %s", kind, key))
+		case hadOld && !hasNew:
+			changes = append(changes, fmt.Sprintf("removed %s %s", kind, key))
+		case !reflect.DeepEqual(oldValues, newValues):
+			changes = append(changes, fmt.Sprintf("changed %s %s", kind, key))
+		}
+	}
+	return changes
+}
+
+// normalizeOutput round-trips output through JSON so typed and decoded
+// values compare equal.
+func normalizeOutput(output interface{}) interface{} {
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return output
+	}
+	var normalized interface{}
+	_ = json.Unmarshal(encoded, &normalized)
+	return normalized
+}
@@ -0,0 +1,265 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/auth"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/output"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"github.com/CliForge/cliforge/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+// fakeHook is a hook implemented by a Go function.
+type fakeHook struct {
+	plugin.RegisteredHook
+	fn func(hc *plugin.HookContext) *plugin.HookContext
+}
+
+// fakeHookRunner runs fakeHooks in the order they were added.
+type fakeHookRunner struct {
+	hooks []fakeHook
+}
+
+func (r *fakeHookRunner) add(pluginName string, point plugin.HookPoint, fn func(hc *plugin.HookContext) *plugin.HookContext) {
+	r.hooks = append(r.hooks, fakeHook{
+		RegisteredHook: plugin.RegisteredHook{Plugin: pluginName, HookSpec: plugin.HookSpec{Point: point}},
+		fn:             fn,
+	})
+}
+
+func (r *fakeHookRunner) Hooks(point plugin.HookPoint) []plugin.RegisteredHook {
+	var hooks []plugin.RegisteredHook
+	for _, hook := range r.hooks {
+		if hook.Point == point {
+			hooks = append(hooks, hook.RegisteredHook)
+		}
+	}
+	return hooks
+}
+
+func (r *fakeHookRunner) RunHook(ctx context.Context, hook plugin.RegisteredHook, hc *plugin.HookContext) (*plugin.HookContext, error) {
+	for _, h := range r.hooks {
+		if h.RegisteredHook.Plugin == hook.Plugin && h.Point == hook.Point {
+			return h.fn(hc), nil
+		}
+	}
+	return nil, errors.New("unknown hook")
+}
+
+func newHookTestExecutor(t *testing.T, serverURL string, runner HookRunner) *Executor {
+	t.Helper()
+
+	spec, err := openapi.NewParser().ParseFile(context.Background(), "../../examples/openapi/swagger2-example.json")
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	authMgr := auth.NewManager("test")
+	_ = authMgr.RegisterAuthenticator("default", &auth.NoneAuth{})
+
+	executor, err := NewExecutor(spec, &ExecutorConfig{
+		BaseURL:       serverURL,
+		OutputManager: output.NewManager(),
+		AuthManager:   authMgr,
+		Hooks:         runner,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create executor: %v", err)
+	}
+	return executor
+}
+
+func newHookTestCommand(operationID string, stdout, stderr *bytes.Buffer) *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Annotations = map[string]string{"operationID": operationID}
+	cmd.Flags().String("output", "json", "Output format")
+	cmd.Flags().Bool("debug", false, "Enable debug mode")
+	return cmd
+}
+
+func TestExecutor_Hooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": "1", "email": "a@example.com"}]`))
+	}))
+	defer server.Close()
+
+	runner := &fakeHookRunner{}
+	runner.add("tenant", plugin.HookBeforeRequest, func(hc *plugin.HookContext) *plugin.HookContext {
+		hc.Request.Header["X-Tenant"] = []string{"acme"}
+		return hc
+	})
+	runner.add("enrich", plugin.HookAfterResponse, func(hc *plugin.HookContext) *plugin.HookContext {
+		hc.Response.Body = strings.Replace(hc.Response.Body, `"id": "1"`, `"id": "1", "source": "api"`, 1)
+		return hc
+	})
+	runner.add("redact", plugin.HookBeforeOutput, func(hc *plugin.HookContext) *plugin.HookContext {
+		for _, item := range hc.Output.([]interface{}) {
+			item.(map[string]interface{})["email"] = "***"
+		}
+		return hc
+	})
+
+	executor := newHookTestExecutor(t, server.URL, runner)
+
+	var stdout, stderr bytes.Buffer
+	cmd := newHookTestCommand("listUsers", &stdout, &stderr)
+	_ = cmd.Flags().Set("debug", "true")
+	if err := executor.Execute(cmd, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, `"source": "api"`) || !strings.Contains(out, `"email": "***"`) || strings.Contains(out, "a@example.com") {
+		t.Errorf("Hooks not applied to output: %s", out)
+	}
+
+	trace := stderr.String()
+	for _, want := range []string{
+		"[hook] tenant before-request: added request header X-Tenant",
+		"[hook] enrich after-response: response body changed",
+		"[hook] redact before-output: output changed",
+	} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("Expected trace %q, got:\n%s", want, trace)
+		}
+	}
+}
+
+func TestExecutor_Hooks_OnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"fault": {"text": "quota exceeded"}}`))
+	}))
+	defer server.Close()
+
+	runner := &fakeHookRunner{}
+	runner.add("vendor", plugin.HookOnError, func(hc *plugin.HookContext) *plugin.HookContext {
+		if strings.Contains(hc.Response.Body, "quota exceeded") {
+			hc.Response.Body = `{"message": "Quota exceeded, try again tomorrow"}`
+		}
+		return hc
+	})
+
+	executor := newHookTestExecutor(t, server.URL, runner)
+
+	var stdout, stderr bytes.Buffer
+	err := executor.Execute(newHookTestCommand("listUsers", &stdout, &stderr), nil)
+	if err == nil || err.Error() != "HTTP 500: Quota exceeded, try again tomorrow" {
+		t.Errorf("Expected translated error, got %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no trace without --debug, got %s", stderr.String())
+	}
+}
+
+func TestHookTransport_TransportError(t *testing.T) {
+	failing := &mockTransport{roundTripFunc: func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}}
+
+	runner := &fakeHookRunner{}
+	runner.add("offline", plugin.HookOnError, func(hc *plugin.HookContext) *plugin.HookContext {
+		if hc.Error == "connection refused" {
+			hc.Response = &plugin.HookResponse{StatusCode: 200, Body: `{"cached": true}`}
+		}
+		return hc
+	})
+
+	client := &http.Client{Transport: (&hookChain{runner: runner}).transport(failing)}
+	resp, err := client.Get("https://api.example.com/users")
+	if err != nil {
+		t.Fatalf("Expected a hook to recover, got %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != 200 {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	client = &http.Client{Transport: (&hookChain{runner: &fakeHookRunner{}}).transport(failing)}
+	if _, err := client.Get("https://api.example.com/users"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Expected transport error, got %v", err)
+	}
+}
+
+func TestHookTransport_WorkflowAPICall(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	runner := &fakeHookRunner{}
+	runner.add("signer", plugin.HookBeforeRequest, func(hc *plugin.HookContext) *plugin.HookContext {
+		hc.Request.Header["X-Signature"] = []string{"sig:" + hc.Request.Method + ":" + hc.Request.Body}
+		return hc
+	})
+
+	client := &http.Client{Transport: (&hookChain{runner: runner}).transport(server.Client().Transport)}
+	wf := &workflow.Workflow{Steps: []*workflow.Step{{
+		ID:      "create",
+		Type:    workflow.StepTypeAPICall,
+		APICall: &workflow.APICallStep{Method: "POST", Endpoint: server.URL + "/items", Body: map[string]interface{}{"a": 1}},
+	}}}
+
+	workflowExec, err := workflow.NewExecutor(wf, client, nil)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	workflowExec.SetStateManager(workflow.NewStateManagerWithDir(t.TempDir()))
+	if _, err := workflowExec.Execute(workflow.NewExecutionContext(nil)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if signature != `sig:POST:{"a":1}` {
+		t.Errorf("Expected signed workflow request, got %q", signature)
+	}
+}
+
+func TestDescribeHookChanges(t *testing.T) {
+	before := &plugin.HookContext{
+		Request: &plugin.HookRequest{
+			Method: "GET",
+			URL:    "https://api.example.com/a",
+			Header: map[string][]string{"Accept": {"application/json"}, "X-Old": {"1"}},
+		},
+	}
+	after := &plugin.HookContext{
+		Request: &plugin.HookRequest{
+			Method: "GET",
+			URL:    "https://api.example.com/b",
+			Header: map[string][]string{"accept": {"text/plain"}, "x-new": {"secret"}},
+		},
+	}
+
+	changes := describeHookChanges(before, after)
+	expected := []string{
+		"url https://api.example.com/a -> https://api.example.com/b",
+		"changed request header Accept",
+		"added request header X-New",
+		"removed request header X-Old",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("describeHookChanges() = %v, want %v", changes, expected)
+	}
+	if len(describeHookChanges(before, before)) != 0 {
+		t.Error("Expected no changes for identical contexts")
+	}
+}
@@ -0,0 +1,406 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// linkBinding is how a linked operation's command is run: its positional
+// arguments and flags, bound from a response.
+type linkBinding struct {
+	link  *openapi.OperationLink
+	cmd   *cobra.Command
+	op    *openapi.Operation
+	args  []string
+	flags [][2]string
+	// unresolved lists the parameters whose expressions had no value
+	unresolved []string
+}
+
+// checkLinkChain checks that the links named by --then exist, each on
+// the operation the previous one links to, before anything is run.
+func (e *Executor) checkLinkChain(op *openapi.Operation, names []string) error {
+	for _, name := range names {
+		link := op.Link(name)
+		if link == nil {
+			return fmt.Errorf("operation %s has no link %q%s", op.OperationID, name, availableLinks(op))
+		}
+		target, err := e.spec.LinkedOperation(link)
+		if err != nil {
+			return err
+		}
+		op = target
+	}
+	return nil
+}
+
+// availableLinks lists the links of an operation for error messages.
+func availableLinks(op *openapi.Operation) string {
+	links := op.Links()
+	if len(links) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(links))
+	for _, link := range links {
+		names = append(names, link.Name)
+	}
+	return fmt.Sprintf(" (available: %s)", strings.Join(names, ", "))
+}
+
+// followLinks runs the operations chained by --then after a successful
+// response, or prints the links of the response as next steps.
+func (e *Executor) followLinks(ctx context.Context, cmd *cobra.Command, op *openapi.Operation, args []string, req *http.Request, resp *http.Response, body []byte) error {
+	then, _ := cmd.Flags().GetStringArray("then")
+	if len(then) == 0 && !showHints(cmd) {
+		return nil
+	}
+
+	exprCtx := expressionContext(cmd, op, args, req, resp, body)
+	if len(then) == 0 {
+		writeLinkHints(cmd.ErrOrStderr(), e.linkBindings(cmd, op, exprCtx))
+		return nil
+	}
+
+	link := op.Link(then[0])
+	if link == nil {
+		return fmt.Errorf("operation %s has no link %q%s", op.OperationID, then[0], availableLinks(op))
+	}
+	binding, err := e.bindLink(cmd, link, exprCtx)
+	if err != nil {
+		return err
+	}
+	if len(binding.unresolved) > 0 {
+		return fmt.Errorf("link %s: no value in the response for %s", link.Name, strings.Join(binding.unresolved, ", "))
+	}
+	return e.runLink(ctx, binding, then[1:])
+}
+
+// showHints reports whether next steps are printed: only to a terminal,
+// and not with --quiet.
+func showHints(cmd *cobra.Command) bool {
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet {
+		return false
+	}
+	out, ok := cmd.OutOrStdout().(*os.File)
+	return ok && isTerminal(out)
+}
+
+// expressionContext collects what the runtime expressions of links are
+// evaluated against.
+func expressionContext(cmd *cobra.Command, op *openapi.Operation, args []string, req *http.Request, resp *http.Response, body []byte) *openapi.ExpressionContext {
+	pathParams, _ := pathParamValues(cmd, op, args)
+	exprCtx := &openapi.ExpressionContext{
+		URL:            req.URL.String(),
+		Method:         req.Method,
+		StatusCode:     resp.StatusCode,
+		PathParams:     pathParams,
+		Query:          req.URL.Query(),
+		RequestHeader:  req.Header,
+		ResponseHeader: resp.Header,
+		ResponseBody:   body,
+	}
+	if req.GetBody != nil {
+		if reqBody, err := req.GetBody(); err == nil {
+			exprCtx.RequestBody, _ = io.ReadAll(reqBody)
+		}
+	}
+	return exprCtx
+}
+
+// linkBindings binds the links of an operation whose commands exist,
+// leaving out those that cannot be bound.
+func (e *Executor) linkBindings(cmd *cobra.Command, op *openapi.Operation, exprCtx *openapi.ExpressionContext) []*linkBinding {
+	var bindings []*linkBinding
+	for _, link := range op.Links() {
+		if binding, err := e.bindLink(cmd, link, exprCtx); err == nil {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings
+}
+
+// bindLink finds the command of a linked operation and binds the link's
+// parameters and request body to its arguments and flags.
+func (e *Executor) bindLink(cmd *cobra.Command, link *openapi.OperationLink, exprCtx *openapi.ExpressionContext) (*linkBinding, error) {
+	target, err := e.spec.LinkedOperation(link)
+	if err != nil {
+		return nil, err
+	}
+	targetCmd := linkedCommand(cmd, target.OperationID)
+	if targetCmd == nil {
+		return nil, fmt.Errorf("link %s: no command runs operation %s", link.Name, target.OperationID)
+	}
+
+	binding := &linkBinding{link: link, cmd: targetCmd, op: target}
+	pathValues := make(map[string]string)
+
+	names := make([]string, 0, len(link.Parameters))
+	for name := range link.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, key := range names {
+		in, name := "", key
+		if location, paramName, ok := strings.Cut(key, "."); ok && isParamLocation(location) {
+			in, name = location, paramName
+		}
+		param := findParameter(target, name, in)
+		if param == nil {
+			return nil, fmt.Errorf("link %s: operation %s has no parameter %s", link.Name, target.OperationID, key)
+		}
+
+		placeholder := "<" + param.Name + ">"
+		value := placeholder
+		if evaluated, err := openapi.EvaluateExpression(link.Parameters[key], exprCtx); err == nil {
+			value = scalarString(evaluated)
+		} else {
+			binding.unresolved = append(binding.unresolved, param.Name)
+		}
+
+		if param.In == "path" {
+			pathValues[param.Name] = value
+			continue
+		}
+		flagName := builder.ParamFlagName(targetCmd, param.Name, param.In)
+		if flagName == "" {
+			return nil, fmt.Errorf("link %s: %s parameter %s of %s has no flag", link.Name, param.In, param.Name, target.OperationID)
+		}
+		binding.flags = append(binding.flags, [2]string{flagName, value})
+	}
+
+	// Path parameters are positional arguments, in the order of the path
+	for _, name := range extractPathParams(target.Path) {
+		value, ok := pathValues[name]
+		if !ok {
+			value = "<" + name + ">"
+		}
+		binding.args = append(binding.args, value)
+	}
+
+	if link.RequestBody != nil {
+		body, err := openapi.EvaluateExpression(link.RequestBody, exprCtx)
+		if err != nil {
+			binding.unresolved = append(binding.unresolved, "the request body")
+			return binding, nil
+		}
+		object, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("link %s: the request body is not an object", link.Name)
+		}
+		if err := bindBodyFlags(binding, object, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return binding, nil
+}
+
+// bindBodyFlags binds the fields of a request body to the flags setting
+// them, descending into objects without a flag of their own.
+func bindBodyFlags(binding *linkBinding, object map[string]interface{}, path []string) error {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := append(append([]string(nil), path...), key)
+		value := object[key]
+
+		if flagName := builder.BodyFieldFlag(binding.cmd, fieldPath); flagName != "" {
+			if items, ok := value.([]interface{}); ok {
+				for _, item := range items {
+					binding.flags = append(binding.flags, [2]string{flagName, scalarString(item)})
+				}
+				continue
+			}
+			binding.flags = append(binding.flags, [2]string{flagName, scalarString(value)})
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if err := bindBodyFlags(binding, nested, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+		return fmt.Errorf("link %s: no flag sets the body field %s of %s", binding.link.Name, strings.Join(fieldPath, "."), binding.op.OperationID)
+	}
+	return nil
+}
+
+// runLink runs the command of a bound link, which follows the rest of
+// the chain in turn.
+func (e *Executor) runLink(ctx context.Context, binding *linkBinding, then []string) error {
+	cmd := binding.cmd
+	flags := cmd.Flags()
+
+	// Repeated flags, like array body fields, replace any earlier values
+	set := make(map[string][]string)
+	var order []string
+	for _, flag := range binding.flags {
+		if _, ok := set[flag[0]]; !ok {
+			order = append(order, flag[0])
+		}
+		set[flag[0]] = append(set[flag[0]], flag[1])
+	}
+	if len(then) > 0 {
+		order = append(order, "then")
+		set["then"] = then
+	}
+	for _, name := range order {
+		if err := setFlag(flags, name, set[name]); err != nil {
+			return fmt.Errorf("link %s: %w", binding.link.Name, err)
+		}
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return fmt.Errorf("link %s: %w", binding.link.Name, err)
+	}
+
+	cmd.SetContext(ctx)
+	if binding.op.CLIWorkflow != nil {
+		return e.executeWorkflow(ctx, cmd, binding.op)
+	}
+	return e.executeHTTPOperation(ctx, cmd, binding.op, binding.args)
+}
+
+// setFlag sets a flag to values, replacing the values of list flags.
+func setFlag(flags *pflag.FlagSet, name string, values []string) error {
+	flag := flags.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("flag --%s not found", name)
+	}
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		if err := slice.Replace(values); err != nil {
+			return fmt.Errorf("invalid value for --%s: %w", name, err)
+		}
+		flag.Changed = true
+		return nil
+	}
+	for _, value := range values {
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value for --%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// writeLinkHints prints the commands of bound links as next steps.
+func writeLinkHints(w io.Writer, bindings []*linkBinding) {
+	if len(bindings) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "\nNext steps:")
+	for _, binding := range bindings {
+		description := binding.link.Description
+		if description == "" {
+			description = binding.cmd.Short
+		}
+		line := "  " + binding.commandLine()
+		if description != "" {
+			line += "  # " + description
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
+
+// commandLine returns the command line running a bound link.
+func (b *linkBinding) commandLine() string {
+	parts := []string{b.cmd.CommandPath()}
+	for _, arg := range b.args {
+		parts = append(parts, shellQuote(arg))
+	}
+	for _, flag := range b.flags {
+		parts = append(parts, "--"+flag[0], shellQuote(flag[1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// linkedCommand finds the command running an operation, among the
+// commands of the same spec as cmd.
+func linkedCommand(cmd *cobra.Command, operationID string) *cobra.Command {
+	spec := cmd.Annotations["spec"]
+	var find func(c *cobra.Command) *cobra.Command
+	find = func(c *cobra.Command) *cobra.Command {
+		if c.Annotations["operationID"] == operationID && c.Annotations["spec"] == spec && c.Runnable() {
+			return c
+		}
+		for _, sub := range c.Commands() {
+			if found := find(sub); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(cmd.Root())
+}
+
+// findParameter finds a parameter of an operation by name, and location
+// when in is set.
+func findParameter(op *openapi.Operation, name, in string) *openapi3.Parameter {
+	for _, ref := range op.Operation.Parameters {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		if ref.Value.Name == name && (in == "" || ref.Value.In == in) {
+			return ref.Value
+		}
+	}
+	return nil
+}
+
+// isParamLocation reports whether s is the location of a parameter.
+func isParamLocation(s string) bool {
+	switch s {
+	case "path", "query", "header", "cookie":
+		return true
+	}
+	return false
+}
+
+// scalarString formats a value bound to an argument or flag.
+func scalarString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for a POSIX shell when it needs it. Placeholders of
+// unresolved values are left as they are.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) || isPlaceholder(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isPlaceholder reports whether s stands for an unresolved value.
+func isPlaceholder(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">") && !strings.ContainsAny(s[1:len(s)-1], "<> '")
+}
@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+const linksSpec = `
+openapi: 3.0.3
+info: {title: Clusters, version: "1.0"}
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      summary: Create a cluster
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+                region: {type: string}
+      responses:
+        "201":
+          description: Created
+          links:
+            getCluster:
+              operationId: getCluster
+              description: Show the cluster
+              parameters:
+                cluster_id: $response.body#/id
+            listNodes:
+              operationId: listNodes
+              parameters:
+                path.cluster_id: $response.body#/id
+                query.region: $request.body#/region
+            renameCluster:
+              operationId: renameCluster
+              parameters:
+                cluster_id: $response.body#/id
+              requestBody:
+                name: "{$response.body#/name} copy"
+  /clusters/{cluster_id}:
+    get:
+      operationId: getCluster
+      summary: Get a cluster
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          links:
+            listNodes:
+              operationId: listNodes
+              parameters:
+                cluster_id: $request.path.cluster_id
+                region: $response.body#/region
+    patch:
+      operationId: renameCluster
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+      responses:
+        "200": {description: OK}
+  /clusters/{cluster_id}/nodes:
+    get:
+      operationId: listNodes
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+        - {name: region, in: query, schema: {type: string}}
+      responses:
+        "200": {description: OK}
+`
+
+// newLinksTest returns an executor for linksSpec sending to serverURL,
+// and a command tree with a command per operation.
+func newLinksTest(t *testing.T, serverURL string) (*Executor, map[string]*cobra.Command) {
+	t.Helper()
+
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(linksSpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	executor, err := NewExecutor(spec, &ExecutorConfig{BaseURL: serverURL})
+	if err != nil {
+		t.Fatalf("Failed to create executor: %v", err)
+	}
+	operations, err := spec.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "mycli"}
+	fb := builder.NewFlagBuilder(nil)
+	fb.AddGlobalFlags(root)
+	commands := make(map[string]*cobra.Command)
+	for _, op := range operations {
+		cmd := &cobra.Command{
+			Use:         op.OperationID,
+			Short:       op.Summary,
+			Annotations: map[string]string{"operationID": op.OperationID},
+			RunE:        func(*cobra.Command, []string) error { return nil },
+		}
+		if err := fb.AddOperationFlags(cmd, op); err != nil {
+			t.Fatal(err)
+		}
+		root.AddCommand(cmd)
+		commands[op.OperationID] = cmd
+	}
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	return executor, commands
+}
+
+func findOperation(t *testing.T, e *Executor, operationID string) *openapi.Operation {
+	t.Helper()
+	operations, _ := e.spec.GetOperations()
+	for _, op := range operations {
+		if op.OperationID == operationID {
+			return op
+		}
+	}
+	t.Fatalf("operation %s not found", operationID)
+	return nil
+}
+
+func TestExecutor_FollowLinks(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "c1", "name": "prod"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id": "c1", "region": "eu"}`))
+		}
+	}))
+	defer server.Close()
+
+	executor, commands := newLinksTest(t, server.URL)
+	create := commands["createCluster"]
+	if create.Flags().Lookup("then") == nil || commands["listNodes"].Flags().Lookup("then") != nil {
+		t.Fatal("Expected --then only on commands of operations with links")
+	}
+
+	// Each link runs the operation it links to with the previous response
+	_ = create.Flags().Set("name", "prod")
+	_ = create.Flags().Set("then", "getCluster")
+	_ = create.Flags().Set("then", "listNodes")
+	create.SetContext(context.Background())
+	if err := executor.executeHTTPOperation(context.Background(), create, findOperation(t, executor, "createCluster"), nil); err != nil {
+		t.Fatalf("executeHTTPOperation() error = %v", err)
+	}
+	want := []string{"POST /clusters", "GET /clusters/c1", "GET /clusters/c1/nodes?region=eu"}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Unknown links fail before anything is sent
+	requests = nil
+	_ = create.Flags().Set("then", "deleteCluster")
+	err := executor.executeHTTPOperation(context.Background(), create, findOperation(t, executor, "createCluster"), nil)
+	if err == nil || !strings.Contains(err.Error(), `operation listNodes has no link "deleteCluster"`) || len(requests) != 0 {
+		t.Errorf("Expected the chain to be checked first, got %v after %v", err, requests)
+	}
+}
+
+func TestExecutor_LinkHints(t *testing.T) {
+	executor, commands := newLinksTest(t, "http://localhost")
+	op := findOperation(t, executor, "createCluster")
+	exprCtx := &openapi.ExpressionContext{
+		RequestBody:  []byte(`{"name": "prod"}`),
+		ResponseBody: []byte(`{"id": "c 1", "name": "prod"}`),
+	}
+
+	var out bytes.Buffer
+	writeLinkHints(&out, executor.linkBindings(commands["createCluster"], op, exprCtx))
+	want := `
+Next steps:
+  mycli getCluster 'c 1'  # Show the cluster
+  mycli listNodes 'c 1' --region <region>
+  mycli renameCluster 'c 1' --name 'prod copy'
+`
+	if out.String() != want {
+		t.Errorf("hints =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Hints are only printed to a terminal, and not with --quiet
+	var stderr bytes.Buffer
+	cmd := commands["createCluster"]
+	cmd.SetErr(&stderr)
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/clusters", nil)
+	resp := &http.Response{StatusCode: http.StatusCreated, Header: http.Header{}}
+	if err := executor.followLinks(context.Background(), cmd, op, nil, req, resp, exprCtx.ResponseBody); err != nil || stderr.Len() != 0 {
+		t.Errorf("Expected no hints when not writing to a terminal, got %q (%v)", stderr.String(), err)
+	}
+}
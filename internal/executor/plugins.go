@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+// mountPluginCommands adds the commands declared by x-cli-plugin and by the
+// manifests of installed plugins to the command tree. Spec commands are
+// mounted first, then plugins in name order; naming conflicts are reported
+// to w.
+func (rt *Runtime) mountPluginCommands(w io.Writer) error {
+	if err := rt.pluginRegistry.DiscoverPlugins(); err != nil {
+		_, _ = fmt.Fprintf(w, "Warning: failed to load plugins: %v\n", err)
+	}
+
+	var commands []*builder.PluginCommand
+	for _, specPlugin := range rt.spec.Extensions.Plugins {
+		commands = append(commands, rt.specPluginCommand(specPlugin))
+	}
+
+	names := rt.pluginRegistry.List()
+	sort.Strings(names)
+	for _, name := range names {
+		manifest, err := rt.pluginRegistry.GetManifest(name)
+		if err != nil {
+			return err
+		}
+		for _, command := range manifest.Commands {
+			commands = append(commands, &builder.PluginCommand{
+				Plugin:  name,
+				Command: command,
+				Run:     rt.runPluginCommand(name, command.Name),
+			})
+		}
+	}
+
+	conflicts, err := rt.commandBuilder.MountPluginCommands(commands)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		_, _ = fmt.Fprintf(w, "Warning: %s\n", conflict)
+	}
+
+	return nil
+}
+
+// specPluginCommand converts an x-cli-plugin entry to a pass-through
+// command.
+func (rt *Runtime) specPluginCommand(specPlugin *openapi.CLIPlugin) *builder.PluginCommand {
+	segments := strings.Fields(specPlugin.Command)
+	command := cli.PluginCommand{
+		Name:        segments[len(segments)-1],
+		Description: specPlugin.Description,
+		Aliases:     specPlugin.Aliases,
+		Parent:      strings.Join(segments[:len(segments)-1], " "),
+	}
+
+	pluginName := specPlugin.Name
+	if pluginName == "" {
+		pluginName = specPlugin.Executable
+	}
+
+	pc := &builder.PluginCommand{
+		Plugin:      pluginName,
+		Command:     command,
+		PassThrough: true,
+	}
+
+	if specPlugin.Executable != "" {
+		pc.Run = func(cmd *cobra.Command, args []string, _ map[string]interface{}) error {
+			return runExecutable(cmd, specPlugin.Executable, append(append([]string{}, specPlugin.Args...), args...))
+		}
+	} else {
+		run := rt.runPluginCommand(specPlugin.Name, command.Name)
+		pc.Run = func(cmd *cobra.Command, args []string, values map[string]interface{}) error {
+			return run(cmd, append(append([]string{}, specPlugin.Args...), args...), values)
+		}
+	}
+
+	return pc
+}
+
+// runPluginCommand returns the run function of a command executed by a
+// registered plugin.
+func (rt *Runtime) runPluginCommand(pluginName, commandName string) builder.PluginRunFunc {
+	return func(cmd *cobra.Command, args []string, values map[string]interface{}) error {
+		input := &plugin.PluginInput{
+			Command: commandName,
+			Args:    args,
+			Data:    values,
+		}
+
+		result, err := rt.pluginRegistry.Execute(cmd.Context(), pluginName, input)
+		if err != nil {
+			return err
+		}
+
+		return rt.renderPluginOutput(cmd, pluginName, result)
+	}
+}
+
+// renderPluginOutput writes the output of a plugin command. Structured data
+// goes through the output manager; plain stdout is copied as is.
+func (rt *Runtime) renderPluginOutput(cmd *cobra.Command, pluginName string, result *plugin.PluginOutput) error {
+	if result.Stderr != "" {
+		_, _ = io.WriteString(cmd.ErrOrStderr(), result.Stderr)
+	}
+
+	if result.Data != nil {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		if err := rt.outputManager.Format(cmd.OutOrStdout(), result.Data, outputFormat); err != nil {
+			return fmt.Errorf("failed to format plugin output: %w", err)
+		}
+	} else if result.Stdout != "" {
+		_, _ = io.WriteString(cmd.OutOrStdout(), result.Stdout)
+	}
+
+	if !result.Success() {
+		if result.Error != "" {
+			return fmt.Errorf("plugin %s: %s", pluginName, result.Error)
+		}
+		return fmt.Errorf("plugin %s exited with code %d", pluginName, result.ExitCode)
+	}
+
+	return nil
+}
+
+// runExecutable runs an external program declared by x-cli-plugin with the
+// command's standard streams.
+func runExecutable(cmd *cobra.Command, executable string, args []string) error {
+	if _, err := exec.LookPath(executable); err != nil {
+		return fmt.Errorf("%s is not installed or not in PATH: %w", executable, err)
+	}
+
+	c := exec.CommandContext(cmd.Context(), executable, args...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s exited with code %d", executable, exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run %s: %w", executable, err)
+	}
+
+	return nil
+}
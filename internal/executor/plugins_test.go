@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+const pluginTestSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Test API", "version": "1.0.0"},
+	"paths": {},
+	"x-cli-plugin": [
+		{"name": "say", "command": "tools say", "executable": "echo", "args": ["said:"]}
+	]
+}`
+
+const pluginTestManifest = `name: greeter
+version: 1.0.0
+type: binary
+executable: greeter.sh
+permissions: []
+commands:
+  - name: greet
+    description: Greet someone
+    flags:
+      - name: name
+        required: true
+  - name: tools
+`
+
+const pluginTestScript = `#!/bin/sh
+cat > /dev/null
+echo '{"jsonrpc":"2.0","id":"1","result":{"exit_code":0,"data":{"greeting":"hello"}}}'
+`
+
+func TestRuntime_PluginCommands(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	pluginDir := filepath.Join(configHome, "plugintest", "plugins", "greeter")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin-manifest.yaml"), []byte(pluginTestManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "greeter.sh"), []byte(pluginTestScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(pluginTestSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	rt, err := NewRuntime(context.Background(), &RuntimeConfig{
+		CLIName:  "plugintest",
+		SpecPath: specPath,
+		Stderr:   &stderr,
+	})
+	if err != nil {
+		t.Fatalf("NewRuntime() error = %v", err)
+	}
+	defer func() { _ = rt.pluginRegistry.Close() }()
+
+	if !strings.Contains(stderr.String(), "plugin greeter: tools: name is already used by plugin say") {
+		t.Errorf("Expected conflict warning, got %q", stderr.String())
+	}
+
+	if cmd, _, err := rt.rootCmd.Find([]string{"plugin", "install"}); err != nil || cmd.Name() != "install" {
+		t.Errorf("Expected the plugin management command, got %v", err)
+	}
+
+	var stdout bytes.Buffer
+	rt.rootCmd.SetOut(&stdout)
+	rt.rootCmd.SetErr(&stdout)
+	rt.rootCmd.SetArgs([]string{"greet"})
+	if err := rt.rootCmd.Execute(); err == nil {
+		t.Error("Expected error for missing required flag")
+	}
+
+	stdout.Reset()
+	rt.rootCmd.SetArgs([]string{"greet", "--name", "bob", "-o", "json"})
+	if err := rt.rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"greeting": "hello"`) {
+		t.Errorf("Expected formatted plugin data, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	rt.rootCmd.SetArgs([]string{"tools", "say", "hi", "--loud"})
+	if err := rt.rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.TrimSpace(stdout.String()) != "said: hi --loud" {
+		t.Errorf("Expected executable output, got %q", stdout.String())
+	}
+}
@@ -153,7 +153,7 @@ func (e *Executor) executePreflightCheck(ctx context.Context, check *openapi.CLI
 	}
 
 	// Execute request
-	resp, err := e.httpClient.Do(req)
+	resp, err := e.client(ctx).Do(req)
 	if err != nil {
 		result.Error = fmt.Errorf("request failed: %w", err)
 		return result
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/CliForge/cliforge/pkg/replay"
+	"github.com/spf13/cobra"
+)
+
+// clientForCommand returns the HTTP client for a command invocation. With
+// --record the base client's transport is wrapped in a fixture recorder;
+// with --replay requests are served from fixtures and never reach the network.
+func (e *Executor) clientForCommand(cmd *cobra.Command) (*http.Client, error) {
+	base := e.httpClient
+
+	recordDir, _ := cmd.Flags().GetString("record")
+	replayDir, _ := cmd.Flags().GetString("replay")
+
+	if recordDir == "" && replayDir == "" {
+		return base, nil
+	}
+	if recordDir != "" && replayDir != "" {
+		return nil, fmt.Errorf("--record and --replay are mutually exclusive")
+	}
+
+	client := *base
+
+	if recordDir != "" {
+		recorder, err := replay.NewRecorder(recordDir, base.Transport, nil)
+		if err != nil {
+			return nil, err
+		}
+		client.Transport = recorder
+		return &client, nil
+	}
+
+	matchFlag, _ := cmd.Flags().GetString("replay-match")
+	match, err := replay.ParseMatchConfig(matchFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --replay-match: %w", err)
+	}
+
+	player, err := replay.NewPlayer(replayDir, match, nil)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = player
+	return &client, nil
+}
@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newReplayTestCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("record", "", "")
+	cmd.Flags().String("replay", "", "")
+	cmd.Flags().String("replay-match", "method,path,query", "")
+	return cmd
+}
+
+func TestExecutor_ClientForCommand(t *testing.T) {
+	base := newMockHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"id":"u-1"}`)),
+		}, nil
+	})
+	e := &Executor{httpClient: base}
+
+	// No flags: base client
+	client, err := e.clientForCommand(newReplayTestCommand())
+	if err != nil {
+		t.Fatalf("clientForCommand() error = %v", err)
+	}
+	if client != base {
+		t.Error("expected base client without --record or --replay")
+	}
+
+	// Record
+	dir := t.TempDir()
+	cmd := newReplayTestCommand()
+	_ = cmd.Flags().Set("record", dir)
+	client, err = e.clientForCommand(cmd)
+	if err != nil {
+		t.Fatalf("clientForCommand(--record) error = %v", err)
+	}
+	resp, err := client.Get("https://api.example.com/users/u-1")
+	if err != nil {
+		t.Fatalf("record request error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 fixture, got %d", len(files))
+	}
+
+	// Replay
+	cmd = newReplayTestCommand()
+	_ = cmd.Flags().Set("replay", dir)
+	client, err = e.clientForCommand(cmd)
+	if err != nil {
+		t.Fatalf("clientForCommand(--replay) error = %v", err)
+	}
+	resp, err = client.Get("https://api.example.com/users/u-1")
+	if err != nil {
+		t.Fatalf("replay request error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "u-1") {
+		t.Errorf("unexpected replay body %s", body)
+	}
+
+	// Both flags
+	cmd = newReplayTestCommand()
+	_ = cmd.Flags().Set("record", dir)
+	_ = cmd.Flags().Set("replay", dir)
+	if _, err := e.clientForCommand(cmd); err == nil {
+		t.Error("expected error when --record and --replay are combined")
+	}
+
+	// Invalid match
+	cmd = newReplayTestCommand()
+	_ = cmd.Flags().Set("replay", dir)
+	_ = cmd.Flags().Set("replay-match", "method,cookies")
+	if _, err := e.clientForCommand(cmd); err == nil {
+		t.Error("expected error for invalid --replay-match")
+	}
+
+	// Missing fixture directory
+	cmd = newReplayTestCommand()
+	_ = cmd.Flags().Set("replay", filepath.Join(dir, "missing"))
+	if _, err := e.clientForCommand(cmd); err == nil {
+		t.Error("expected error for missing fixture directory")
+	}
+}
+
+func TestExecutor_Execute_RecordIsPerCommand(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": "1"}]`))
+	}))
+	defer server.Close()
+
+	executor := newHookTestExecutor(t, server.URL, nil)
+	base := executor.httpClient
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	cmd := newHookTestCommand("listUsers", &stdout, &stderr)
+	cmd.Flags().String("record", "", "")
+	cmd.Flags().String("replay", "", "")
+	_ = cmd.Flags().Set("record", dir)
+	if err := executor.Execute(cmd, nil); err != nil {
+		t.Fatalf("Execute(--record) error = %v", err)
+	}
+	if executor.httpClient != base {
+		t.Error("--record replaced the executor's shared client")
+	}
+
+	// A later command without --record must not record
+	cmd = newHookTestCommand("listUsers", &stdout, &stderr)
+	cmd.Flags().String("record", "", "")
+	cmd.Flags().String("replay", "", "")
+	if err := executor.Execute(cmd, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if hits != 2 {
+		t.Errorf("expected 2 requests to the server, got %d", hits)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Errorf("expected 1 fixture from the recorded command, got %d", len(files))
+	}
+}
@@ -0,0 +1,292 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/spf13/cobra"
+)
+
+// RequestValidationError reports the parts of a request that do not match
+// the operation's schemas, by the flag or argument that set them.
+type RequestValidationError struct {
+	OperationID string
+	Fields      []FieldError
+}
+
+// FieldError is a value that does not match its schema.
+type FieldError struct {
+	// Field is the flag, argument or body location of the value, such
+	// as "--network.cidr" or "<cluster-id>".
+	Field   string
+	Message string
+}
+
+// Error lists the invalid fields, one per line.
+func (e *RequestValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid request for %s:", e.OperationID)
+	for _, field := range e.Fields {
+		fmt.Fprintf(&b, "\n  %s: %s", field.Field, field.Message)
+	}
+	return b.String()
+}
+
+// schemaViolation is a value that failed schema validation.
+type schemaViolation struct {
+	path    []string
+	reason  string
+	value   interface{}
+	missing bool
+}
+
+// validationOptions are the schema checks run on requests and responses.
+func validationOptions(extra ...openapi3.SchemaValidationOption) []openapi3.SchemaValidationOption {
+	return append([]openapi3.SchemaValidationOption{openapi3.MultiErrors(), openapi3.EnableFormatValidation()}, extra...)
+}
+
+// schemaViolations flattens a schema validation error into violations.
+func schemaViolations(err error) []schemaViolation {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var violations []schemaViolation
+		for _, e := range multi {
+			violations = append(violations, schemaViolations(e)...)
+		}
+		return violations
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return []schemaViolation{{reason: err.Error()}}
+	}
+	reason := schemaErr.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("does not match the schema's %s", schemaErr.SchemaField)
+	}
+	return []schemaViolation{{
+		path:    schemaErr.JSONPointer(),
+		reason:  reason,
+		value:   schemaErr.Value,
+		missing: schemaErr.SchemaField == "required",
+	}}
+}
+
+// validateRequest checks the parameters and body of a request against
+// the operation's schemas before it is sent.
+func (e *Executor) validateRequest(cmd *cobra.Command, op *openapi.Operation, args []string, body map[string]interface{}) error {
+	if skip, _ := cmd.Flags().GetBool("no-validate"); skip {
+		return nil
+	}
+
+	var fields []FieldError
+
+	params, _ := builder.BuildRequestParams(cmd)
+	pathArgs := make(map[string]string)
+	argIndex := 0
+	for _, name := range extractPathParams(op.Path) {
+		if _, ok := params[name]; !ok && argIndex < len(args) {
+			pathArgs[name] = args[argIndex]
+			argIndex++
+		}
+	}
+
+	for _, paramRef := range op.Operation.Parameters {
+		param := paramRef.Value
+		if param == nil || param.Schema == nil || param.Schema.Value == nil {
+			continue
+		}
+
+		var value interface{}
+		field := "--" + paramFlagName(cmd, param.Name)
+		if v, ok := params[param.Name]; ok {
+			value = v
+		} else if arg, ok := pathArgs[param.Name]; ok && param.In == "path" {
+			value = coerceParam(arg, param.Schema.Value)
+			field = "<" + toFlagName(param.Name) + ">"
+		} else {
+			continue
+		}
+
+		if err := param.Schema.Value.VisitJSON(value, validationOptions()...); err != nil {
+			for _, v := range schemaViolations(err) {
+				fields = append(fields, FieldError{Field: field, Message: v.reason})
+			}
+		}
+	}
+
+	if schema := requestBodySchema(op); schema != nil && len(body) > 0 {
+		// Round trip through JSON so values have the types the server sees
+		var value interface{}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("failed to unmarshal request body: %w", err)
+		}
+
+		if err := schema.VisitJSON(value, validationOptions(openapi3.VisitAsRequest())...); err != nil {
+			for _, v := range schemaViolations(err) {
+				field := "body /" + strings.Join(v.path, "/")
+				message := v.reason
+				if name := builder.BodyFlagName(cmd, v.path); name != "" {
+					field = "--" + name
+					// A missing value with its own flag needs that flag
+					if v.missing && len(bodyFlagPath(cmd, name)) == len(v.path) {
+						message = "is required"
+					}
+				}
+				fields = append(fields, FieldError{Field: field, Message: message})
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &RequestValidationError{OperationID: op.OperationID, Fields: fields}
+}
+
+// requestBodySchema returns the JSON schema of an operation's request
+// body, or nil.
+func requestBodySchema(op *openapi.Operation) *openapi3.Schema {
+	if op.Operation.RequestBody == nil || op.Operation.RequestBody.Value == nil {
+		return nil
+	}
+	content := op.Operation.RequestBody.Value.Content.Get("application/json")
+	if content == nil || content.Schema == nil {
+		return nil
+	}
+	return content.Schema.Value
+}
+
+// bodyFlagPath returns the body path of a flag.
+func bodyFlagPath(cmd *cobra.Command, name string) []string {
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag.Annotations["body-path"]
+	}
+	return nil
+}
+
+// paramFlagName returns the flag of a parameter.
+func paramFlagName(cmd *cobra.Command, name string) string {
+	for key, value := range cmd.Annotations {
+		if value == name && strings.HasPrefix(key, "param:") && !strings.HasSuffix(key, ":in") {
+			return strings.TrimPrefix(key, "param:")
+		}
+	}
+	return toFlagName(name)
+}
+
+// coerceParam converts a positional argument to its schema's type, so
+// "42" is checked as a number. Values that do not convert stay strings
+// and fail the type check.
+func coerceParam(s string, schema *openapi3.Schema) interface{} {
+	switch {
+	case schema.Type.Includes("integer"), schema.Type.Includes("number"):
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case schema.Type.Includes("boolean"):
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return s
+}
+
+// validateResponse checks a response against the operation's declared
+// responses, returning the contract violations found.
+func validateResponse(op *openapi.Operation, resp *http.Response, body []byte) []schemaViolation {
+	if op.Operation.Responses == nil {
+		return nil
+	}
+	responseRef := op.Operation.Responses.Status(resp.StatusCode)
+	if responseRef == nil {
+		responseRef = op.Operation.Responses.Default()
+	}
+	if responseRef == nil || responseRef.Value == nil {
+		return []schemaViolation{{reason: fmt.Sprintf("status %d is not declared", resp.StatusCode)}}
+	}
+
+	mediaType := "application/json"
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		if parsed, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = parsed
+		}
+	}
+
+	content := responseRef.Value.Content.Get(mediaType)
+	if content == nil {
+		if len(responseRef.Value.Content) > 0 && len(body) > 0 {
+			return []schemaViolation{{reason: fmt.Sprintf("content type %s is not declared for status %d", mediaType, resp.StatusCode)}}
+		}
+		return nil
+	}
+	if content.Schema == nil || content.Schema.Value == nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []schemaViolation{{reason: "body is not valid JSON", value: string(body)}}
+	}
+
+	if err := content.Schema.Value.VisitJSON(value, validationOptions(openapi3.VisitAsResponse())...); err != nil {
+		return schemaViolations(err)
+	}
+	return nil
+}
+
+// writeContractDiff writes response contract violations as a diff of
+// what the spec expects against what the server sent.
+func writeContractDiff(w io.Writer, op *openapi.Operation, status int, violations []schemaViolation) {
+	_, _ = fmt.Fprintf(w, "Response does not match the spec (%s %s, HTTP %d):\n", op.Method, op.Path, status)
+	for _, v := range violations {
+		_, _ = fmt.Fprintf(w, "  /%s\n", strings.Join(v.path, "/"))
+		_, _ = fmt.Fprintf(w, "    - spec:   %s\n", v.reason)
+		if v.missing {
+			_, _ = fmt.Fprintln(w, "    + actual: (missing)")
+		} else if v.value != nil {
+			_, _ = fmt.Fprintf(w, "    + actual: %s\n", formatActual(v.value))
+		}
+	}
+}
+
+// formatActual returns a value as compact JSON, shortened to fit a line.
+func formatActual(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	const maxLen = 80
+	if len(data) > maxLen {
+		return string(data[:maxLen-3]) + "..."
+	}
+	return string(data)
+}
+
+// ResponseValidationError reports that a response does not match the
+// spec.
+type ResponseValidationError struct {
+	OperationID string
+	StatusCode  int
+	Violations  int
+}
+
+// Error summarises the violations, which are written out separately.
+func (e *ResponseValidationError) Error() string {
+	return fmt.Sprintf("response of %s (HTTP %d) does not match the spec: %d violation(s)", e.OperationID, e.StatusCode, e.Violations)
+}
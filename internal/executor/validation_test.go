@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+const validationSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Clusters", "version": "1.0.0"},
+  "paths": {
+    "/projects/{projectId}/clusters": {
+      "post": {
+        "operationId": "createCluster",
+        "parameters": [
+          {"name": "projectId", "in": "path", "required": true, "schema": {"type": "integer"}},
+          {"name": "replicas", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 5}}
+        ],
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "required": ["name"],
+                "properties": {
+                  "name": {"type": "string", "pattern": "^[a-z][a-z0-9-]*$"},
+                  "expires": {"type": "string", "format": "date"},
+                  "network": {
+                    "type": "object",
+                    "required": ["cidr"],
+                    "properties": {
+                      "cidr": {"type": "string"},
+                      "mtu": {"type": "integer", "minimum": 576}
+                    }
+                  }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "Created",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "required": ["id", "state"],
+                  "properties": {
+                    "id": {"type": "string"},
+                    "state": {"type": "string", "enum": ["pending", "ready"]}
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// newValidationTest returns an executor for validationSpec sending to
+// serverURL, and a command for createCluster writing to stderr.
+func newValidationTest(t *testing.T, serverURL string, stderr *bytes.Buffer) (*Executor, *cobra.Command) {
+	t.Helper()
+
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(validationSpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	executor, err := NewExecutor(spec, &ExecutorConfig{BaseURL: serverURL})
+	if err != nil {
+		t.Fatalf("Failed to create executor: %v", err)
+	}
+
+	operations, err := spec.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{
+		Use:         "create",
+		Annotations: map[string]string{"operationID": "createCluster"},
+	}
+	fb := builder.NewFlagBuilder(nil)
+	fb.AddGlobalFlags(cmd)
+	if err := fb.AddOperationFlags(cmd, operations[0]); err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(stderr)
+	cmd.SetContext(context.Background())
+	return executor, cmd
+}
+
+func TestExecutor_ValidateRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "c1", "state": "pending"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		args      []string
+		flags     []string
+		wantError []string
+	}{
+		{
+			name:  "valid",
+			args:  []string{"42"},
+			flags: []string{"--name", "prod", "--network.cidr", "10.0.0.0/16", "--replicas", "3"},
+		},
+		{
+			name:  "invalid values",
+			args:  []string{"abc"},
+			flags: []string{"--name", "Prod", "--expires", "tomorrow", "--replicas", "9", "--network.cidr", "10.0.0.0/16", "--network.mtu", "100"},
+			wantError: []string{
+				"invalid request for createCluster:",
+				`--expires: string doesn't match the format "date"`,
+				`--name: string doesn't match the regular expression "^[a-z][a-z0-9-]*$"`,
+				"--network.mtu: number must be at least 576",
+				"--replicas: number must be at most 5",
+				"<projectid>: value must be an integer",
+			},
+		},
+		{
+			name:      "missing nested property",
+			args:      []string{"42"},
+			flags:     []string{"--name", "prod", "--network.mtu", "1500"},
+			wantError: []string{"--network.cidr: is required"},
+		},
+		{
+			name:  "skipped",
+			args:  []string{"42"},
+			flags: []string{"--name", "Prod", "--no-validate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			executor, cmd := newValidationTest(t, server.URL, &bytes.Buffer{})
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			err := executor.Execute(cmd, tt.args)
+			if len(tt.wantError) == 0 {
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if requests != 1 {
+					t.Errorf("Expected the request to be sent, got %d requests", requests)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected a validation error")
+			}
+			for _, want := range tt.wantError {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got:\n%v", want, err)
+				}
+			}
+			if requests != 0 {
+				t.Error("Expected an invalid request not to be sent")
+			}
+		})
+	}
+}
+
+func TestExecutor_ValidateResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 7, "state": "deleted"}`))
+	}))
+	defer server.Close()
+
+	flags := []string{"--name", "prod"}
+
+	// Responses are not checked by default
+	executor, cmd := newValidationTest(t, server.URL, &bytes.Buffer{})
+	_ = cmd.ParseFlags(flags)
+	if err := executor.Execute(cmd, []string{"42"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var stderr bytes.Buffer
+	executor, cmd = newValidationTest(t, server.URL, &stderr)
+	_ = cmd.ParseFlags(append(flags, "--validate-response"))
+	err := executor.Execute(cmd, []string{"42"})
+	if err == nil || err.Error() != "response of createCluster (HTTP 201) does not match the spec: 2 violation(s)" {
+		t.Errorf("Expected a contract error, got %v", err)
+	}
+
+	for _, want := range []string{
+		"Response does not match the spec (POST /projects/{projectId}/clusters, HTTP 201):",
+		"  /id\n    - spec:   value must be a string\n    + actual: 7\n",
+		"  /state\n    - spec:   value is not one of the allowed values [\"pending\",\"ready\"]\n    + actual: \"deleted\"\n",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, stderr.String())
+		}
+	}
+}
+
+func TestValidateResponse_UndeclaredStatus(t *testing.T) {
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(validationSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operations, _ := spec.GetOperations()
+
+	resp := &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}}
+	violations := validateResponse(operations[0], resp, []byte(`{}`))
+	if len(violations) != 1 || violations[0].reason != "status 202 is not declared" {
+		t.Errorf("Expected an undeclared status violation, got %+v", violations)
+	}
+}
@@ -3,17 +3,25 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/CliForge/cliforge/internal/builder"
 	"github.com/CliForge/cliforge/pkg/auth"
 	"github.com/CliForge/cliforge/pkg/auth/storage"
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/cli/builtin"
+	"github.com/CliForge/cliforge/pkg/config"
 	"github.com/CliForge/cliforge/pkg/openapi"
 	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/CliForge/cliforge/pkg/plugin"
 	"github.com/CliForge/cliforge/pkg/progress"
+	"github.com/CliForge/cliforge/pkg/secrets"
 	"github.com/CliForge/cliforge/pkg/state"
+	"github.com/adrg/xdg"
 	"github.com/spf13/cobra"
 )
 
@@ -37,6 +45,12 @@ type Runtime struct {
 	// Executor
 	executor *Executor
 
+	// Specs composed into the CLI, by name
+	composed map[string]*composedSpec
+
+	// Specs that changed since they were cached
+	drift driftRecorder
+
 	// HTTP client
 	httpClient *http.Client
 }
@@ -47,47 +61,106 @@ type RuntimeConfig struct {
 	SpecPath   string
 	ConfigPath string
 	BaseURL    string
+	// Stderr receives startup warnings such as plugin command conflicts.
+	// Defaults to os.Stderr.
+	Stderr io.Writer
+	// Plugins holds the publishers trusted by the plugin command and the
+	// plugin policy.
+	Plugins *cli.Plugins
+	// Specs composes several specs into the CLI in place of SpecPath.
+	// Each gets its own executor, and one that fails to load leaves the
+	// others usable.
+	Specs []cli.APISpec
+	// Auth is the authentication of composed specs without their own.
+	Auth *cli.AuthBehavior
+	// Signing, when set, refuses specs not signed with one of its keys.
+	Signing *cli.SpecSigning
+	// Output holds the output defaults of the CLI config. Long output on
+	// a terminal is paged unless it turns paging off.
+	Output *cli.DefaultsOutput
+}
+
+// composedSpec is a spec composed into the CLI with others.
+type composedSpec struct {
+	spec        *openapi.ParsedSpec
+	err         error
+	flagBuilder *builder.FlagBuilder
 }
 
 // NewRuntime creates a new runtime instance.
 func NewRuntime(ctx context.Context, runtimeConfig *RuntimeConfig) (*Runtime, error) {
 	rt := &Runtime{}
 
-	// Parse OpenAPI spec
-	parser := openapi.NewParser()
-	spec, err := parser.ParseFile(ctx, runtimeConfig.SpecPath)
+	loader, err := rt.newSpecLoader(runtimeConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		return nil, err
 	}
-	rt.spec = spec
 
-	// Override CLI name from spec extensions
-	if spec.Extensions.Config != nil {
-		if spec.Extensions.Config.Name != "" {
-			runtimeConfig.CLIName = spec.Extensions.Config.Name
+	if len(runtimeConfig.Specs) > 0 {
+		// Composed specs are configured by the CLI config, not by the
+		// x-cli-config of any one spec
+		rt.spec = &openapi.ParsedSpec{Extensions: &openapi.SpecExtensions{}}
+		rt.loadSpecs(ctx, loader, runtimeConfig)
+	} else {
+		// Load OpenAPI spec
+		spec, err := loader.Load(ctx, runtimeConfig.SpecPath, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		}
+		rt.spec = spec
+
+		// Override CLI name from spec extensions
+		if spec.Extensions.Config != nil {
+			if spec.Extensions.Config.Name != "" {
+				runtimeConfig.CLIName = spec.Extensions.Config.Name
+			}
 		}
 	}
 
 	// Initialize managers
-	if err := rt.initializeManagers(runtimeConfig.CLIName); err != nil {
+	if err := rt.initializeManagers(runtimeConfig); err != nil {
 		return nil, fmt.Errorf("failed to initialize managers: %w", err)
 	}
 
-	// Create HTTP client with authentication
+	// Create HTTP client
 	if err := rt.createHTTPClient(); err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 
+	// Session plugins call back into the host with the CLI's HTTP client
+	if err := rt.initializePluginHost(runtimeConfig); err != nil {
+		return nil, fmt.Errorf("failed to initialize plugin host: %w", err)
+	}
+
 	// Build command tree
 	if err := rt.buildCommandTree(runtimeConfig); err != nil {
 		return nil, fmt.Errorf("failed to build command tree: %w", err)
 	}
 
+	rt.warnSpecDrift(runtimeConfig)
+
 	return rt, nil
 }
 
+// loadSpecs loads the composed specs concurrently, each cached with its
+// own TTL.
+func (rt *Runtime) loadSpecs(ctx context.Context, loader *openapi.Loader, runtimeConfig *RuntimeConfig) {
+	sources := make([]openapi.SpecSource, len(runtimeConfig.Specs))
+	for i, spec := range runtimeConfig.Specs {
+		ttl, _ := time.ParseDuration(spec.CacheTTL)
+		sources[i] = openapi.SpecSource{Name: spec.Name, Location: spec.OpenAPIURL, CacheTTL: ttl}
+	}
+
+	rt.composed = make(map[string]*composedSpec)
+	for _, result := range loader.LoadAll(ctx, sources, nil) {
+		rt.composed[result.Source.Name] = &composedSpec{spec: result.Spec, err: result.Err}
+	}
+}
+
 // initializeManagers initializes all manager components.
-func (rt *Runtime) initializeManagers(cliName string) error {
+func (rt *Runtime) initializeManagers(runtimeConfig *RuntimeConfig) error {
+	cliName, plugins := runtimeConfig.CLIName, runtimeConfig.Plugins
+
 	// State manager
 	var err error
 	rt.stateManager, err = state.NewManager(cliName)
@@ -100,6 +173,9 @@ func (rt *Runtime) initializeManagers(cliName string) error {
 	if rt.spec.Extensions.Config != nil && rt.spec.Extensions.Config.Output != nil {
 		rt.outputManager.SetDefaultFormat(rt.spec.Extensions.Config.Output.DefaultFormat)
 	}
+	if config.PagingEnabled(runtimeConfig.Output) {
+		rt.outputManager.SetPager(output.NewPager())
+	}
 
 	// Progress manager
 	progressConfig := progress.DefaultConfig()
@@ -107,13 +183,25 @@ func (rt *Runtime) initializeManagers(cliName string) error {
 
 	// Auth manager
 	rt.authManager = auth.NewManager(cliName)
-	if err := rt.initializeAuth(); err != nil {
+	if err := rt.initializeAuth(runtimeConfig); err != nil {
 		return fmt.Errorf("failed to initialize auth: %w", err)
 	}
 
-	// Plugin registry - create with basic permissions
-	permManager, _ := plugin.NewPermissionManager("", nil)
-	rt.pluginRegistry = plugin.NewRegistry(cliName, permManager)
+	// Plugin registry - permissions are approved interactively on first
+	// use, within the organisation's plugin policy
+	permManager, err := plugin.NewPermissionManager(filepath.Join(xdg.ConfigHome, cliName), &plugin.DefaultApprover{})
+	if err != nil {
+		return fmt.Errorf("failed to create plugin permission manager: %w", err)
+	}
+	policy, err := plugin.NewPolicy(plugins)
+	if err != nil {
+		return fmt.Errorf("invalid plugin policy: %w", err)
+	}
+	permManager.SetPolicy(policy)
+	rt.pluginRegistry = plugin.NewRegistry(filepath.Join(xdg.ConfigHome, cliName, "plugins"), permManager)
+	wasmConfig := plugin.DefaultWASMConfig()
+	wasmConfig.CacheDir = filepath.Join(xdg.CacheHome, cliName, "wasm")
+	rt.pluginRegistry.SetWASMConfig(wasmConfig)
 	if err := rt.initializePlugins(); err != nil {
 		return fmt.Errorf("failed to initialize plugins: %w", err)
 	}
@@ -122,9 +210,16 @@ func (rt *Runtime) initializeManagers(cliName string) error {
 }
 
 // initializeAuth initializes authentication from spec and config.
-func (rt *Runtime) initializeAuth() error {
-	// Check for auth configuration in spec extensions
-	if rt.spec.Extensions.Config != nil && rt.spec.Extensions.Config.Auth != nil {
+func (rt *Runtime) initializeAuth(runtimeConfig *RuntimeConfig) error {
+	// Check for auth configuration in the CLI config, then in spec
+	// extensions
+	if runtimeConfig.Auth != nil {
+		if err := rt.authManager.CreateFromConfig(map[string]*auth.Config{
+			"default": auth.ConfigFromBehavior(runtimeConfig.Auth),
+		}); err != nil {
+			return err
+		}
+	} else if rt.spec.Extensions.Config != nil && rt.spec.Extensions.Config.Auth != nil {
 		authSettings := rt.spec.Extensions.Config.Auth
 
 		// Create auth config based on type
@@ -154,6 +249,18 @@ func (rt *Runtime) initializeAuth() error {
 		}
 	}
 
+	// Composed specs with their own auth get an authenticator named
+	// after the spec; the rest use the default
+	specAuth := make(map[string]*auth.Config)
+	for _, spec := range runtimeConfig.Specs {
+		if spec.Auth != nil {
+			specAuth[spec.Name] = auth.ConfigFromBehavior(spec.Auth)
+		}
+	}
+	if err := rt.authManager.CreateFromConfig(specAuth); err != nil {
+		return err
+	}
+
 	// Register memory storage as fallback
 	rt.authManager.RegisterStorage("memory", storage.NewMemoryStorage())
 
@@ -167,17 +274,52 @@ func (rt *Runtime) initializePlugins() error {
 	return nil
 }
 
-// createHTTPClient creates an authenticated HTTP client.
-func (rt *Runtime) createHTTPClient() error {
-	// Create base client with timeout
-	baseClient := &http.Client{
-		Timeout: 30 * time.Second,
+// initializePluginHost sets the services session plugins can call. Their
+// HTTP requests to the CLI's APIs are authenticated like its commands.
+func (rt *Runtime) initializePluginHost(runtimeConfig *RuntimeConfig) error {
+	cliName := runtimeConfig.CLIName
+	detector, err := secrets.NewDetector(secrets.DefaultSecretsBehavior())
+	if err != nil {
+		return err
 	}
 
-	// For now, use base client
-	// TODO: Wrap with authenticated client when needed
-	rt.httpClient = baseClient
+	rt.pluginRegistry.SetHostServices(&plugin.HostServices{
+		HTTPClient: rt.httpClient,
+		Auth:       rt.authManager,
+		APIs:       apis(runtimeConfig),
+		Secrets:    &plugin.KeyringSecretStore{Service: cliName + "-plugins"},
+		State:      plugin.NewFileStateStore(filepath.Join(xdg.DataHome, cliName, "plugin-state")),
+		Progress:   rt.progressManager,
+		Detector:   detector,
+	})
+	return nil
+}
 
+// apis maps the base URL of each API of the CLI to the authenticator its
+// requests use; the default has an empty name.
+func apis(runtimeConfig *RuntimeConfig) map[string]string {
+	apis := make(map[string]string)
+	if runtimeConfig.BaseURL != "" {
+		apis[runtimeConfig.BaseURL] = ""
+	}
+	for _, spec := range runtimeConfig.Specs {
+		if spec.BaseURL == "" {
+			continue
+		}
+		apis[spec.BaseURL] = ""
+		if spec.Auth != nil {
+			apis[spec.BaseURL] = spec.Name
+		}
+	}
+	return apis
+}
+
+// createHTTPClient creates the HTTP client. Credentials are added per
+// request, by the executor and the plugin host, for the CLI's APIs only.
+func (rt *Runtime) createHTTPClient() error {
+	rt.httpClient = &http.Client{
+		Timeout: 30 * time.Second,
+	}
 	return nil
 }
 
@@ -200,9 +342,6 @@ func (rt *Runtime) buildCommandTree(runtimeConfig *RuntimeConfig) error {
 		}
 	}
 
-	// Create command builder
-	rt.commandBuilder = builder.NewBuilder(rt.spec, builderConfig)
-
 	// Create executor
 	execConfig := &ExecutorConfig{
 		BaseURL:       runtimeConfig.BaseURL,
@@ -211,6 +350,7 @@ func (rt *Runtime) buildCommandTree(runtimeConfig *RuntimeConfig) error {
 		OutputManager: rt.outputManager,
 		StateManager:  rt.stateManager,
 		ProgressMgr:   rt.progressManager,
+		Hooks:         rt.pluginRegistry,
 	}
 
 	var err error
@@ -222,6 +362,22 @@ func (rt *Runtime) buildCommandTree(runtimeConfig *RuntimeConfig) error {
 	// Set executor as default
 	builderConfig.DefaultExecutor = rt.executor.Execute
 
+	stderr := runtimeConfig.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	// Create command builder
+	if len(rt.composed) > 0 {
+		mounted, err := rt.mountSpecs(runtimeConfig, execConfig, stderr)
+		if err != nil {
+			return err
+		}
+		rt.commandBuilder = builder.NewComposedBuilder(mounted, builderConfig)
+	} else {
+		rt.commandBuilder = builder.NewBuilder(rt.spec, builderConfig)
+	}
+
 	// Build command tree
 	rootCmd, err := rt.commandBuilder.Build()
 	if err != nil {
@@ -234,22 +390,150 @@ func (rt *Runtime) buildCommandTree(runtimeConfig *RuntimeConfig) error {
 
 	// Add global flags
 	rt.flagBuilder.AddGlobalFlags(rootCmd)
+	builtin.PageHelp(rootCmd, rt.outputManager.GetPager())
 
 	// Add operation-specific flags to all operation commands
 	if err := rt.addOperationFlags(rootCmd); err != nil {
 		return err
 	}
 
+	rt.addPluginCommand(runtimeConfig)
+	rt.addWorkflowCommand(runtimeConfig)
+
+	// Mount plugin commands after the global flags so clashes are detected
+	if err := rt.mountPluginCommands(stderr); err != nil {
+		return fmt.Errorf("failed to mount plugin commands: %w", err)
+	}
+
 	return nil
 }
 
+// mountSpecs prepares the composed specs for the command builder, each
+// with an executor for its base URL and auth. Specs that failed to load
+// are reported to w.
+func (rt *Runtime) mountSpecs(runtimeConfig *RuntimeConfig, execConfig *ExecutorConfig, w io.Writer) ([]*builder.MountedSpec, error) {
+	mounted := make([]*builder.MountedSpec, 0, len(runtimeConfig.Specs))
+	for _, specConfig := range runtimeConfig.Specs {
+		composed := rt.composed[specConfig.Name]
+		m := &builder.MountedSpec{
+			Name:        specConfig.Name,
+			Mount:       specConfig.Mount,
+			Description: specConfig.Description,
+			Spec:        composed.spec,
+			Err:         composed.err,
+		}
+		mounted = append(mounted, m)
+
+		if composed.err != nil {
+			_, _ = fmt.Fprintf(w, "Warning: %v; its commands are unavailable\n", composed.err)
+			continue
+		}
+
+		config := *execConfig
+		if specConfig.BaseURL != "" {
+			config.BaseURL = specConfig.BaseURL
+		}
+		if specConfig.Auth != nil {
+			config.AuthName = specConfig.Name
+		}
+		executor, err := NewExecutor(composed.spec, &config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create executor for spec %s: %w", specConfig.Name, err)
+		}
+		composed.flagBuilder = builder.NewFlagBuilder(composed.spec.Extensions.Config)
+
+		m.Config = &builder.BuilderConfig{
+			GroupByTags:             true,
+			FlattenSingleOperations: true,
+			DefaultExecutor:         executor.Execute,
+			Tags:                    specConfig.Tags,
+			ExcludeTags:             specConfig.ExcludeTags,
+		}
+	}
+	return mounted, nil
+}
+
+// addPluginCommand adds the plugin management command unless the spec
+// already defines a command with that name.
+func (rt *Runtime) addPluginCommand(runtimeConfig *RuntimeConfig) {
+	for _, cmd := range rt.rootCmd.Commands() {
+		if cmd.Name() == "plugin" {
+			return
+		}
+	}
+
+	rt.rootCmd.AddCommand(builtin.NewPluginCommand(&builtin.PluginOptions{
+		CLIName: runtimeConfig.CLIName,
+		Config:  runtimeConfig.Plugins,
+		Output:  os.Stdout,
+	}))
+}
+
+// addWorkflowCommand adds the workflow command, which retries the failed
+// compensations of a workflow and shows its timeline, when the CLI has
+// workflow operations and the spec does not define a command with that
+// name. Compensations are retried with the user's credentials.
+func (rt *Runtime) addWorkflowCommand(runtimeConfig *RuntimeConfig) {
+	for _, cmd := range rt.rootCmd.Commands() {
+		if cmd.Name() == "workflow" {
+			return
+		}
+	}
+
+	// Composed CLIs have no spec of their own
+	var spec *openapi.ParsedSpec
+	specs := make([]*openapi.ParsedSpec, 0, len(rt.composed)+1)
+	if len(rt.composed) == 0 {
+		spec = rt.spec
+		specs = append(specs, spec)
+	}
+	for _, composed := range rt.composed {
+		specs = append(specs, composed.spec)
+	}
+	if !hasWorkflows(specs) {
+		return
+	}
+
+	rt.rootCmd.AddCommand(builtin.NewWorkflowCommand(&builtin.WorkflowOptions{
+		Spec:       spec,
+		HTTPClient: auth.NewClient(rt.httpClient, rt.authManager, apis(runtimeConfig)),
+		Output:     os.Stdout,
+	}))
+}
+
+// hasWorkflows reports whether any of specs has an x-cli-workflow
+// operation.
+func hasWorkflows(specs []*openapi.ParsedSpec) bool {
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		operations, err := spec.GetOperations()
+		if err != nil {
+			continue
+		}
+		for _, op := range operations {
+			if op.CLIWorkflow != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // addOperationFlags adds operation-specific flags to commands.
 func (rt *Runtime) addOperationFlags(cmd *cobra.Command) error {
 	// Check if this command has an operation
 	if cmd.Annotations != nil {
 		if operationID, ok := cmd.Annotations["operationID"]; ok {
+			// Composed commands take the flags of their own spec
+			spec, flagBuilder := rt.spec, rt.flagBuilder
+			if composed, ok := rt.composed[cmd.Annotations["spec"]]; ok {
+				spec, flagBuilder = composed.spec, composed.flagBuilder
+			}
+
 			// Find the operation
-			operations, err := rt.spec.GetOperations()
+			operations, err := spec.GetOperations()
 			if err != nil {
 				return err
 			}
@@ -257,7 +541,7 @@ func (rt *Runtime) addOperationFlags(cmd *cobra.Command) error {
 			for _, op := range operations {
 				if op.OperationID == operationID {
 					// Add flags for this operation
-					if err := rt.flagBuilder.AddOperationFlags(cmd, op); err != nil {
+					if err := flagBuilder.AddOperationFlags(cmd, op); err != nil {
 						return fmt.Errorf("failed to add flags for operation %s: %w", operationID, err)
 					}
 					break
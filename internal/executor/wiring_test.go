@@ -1,8 +1,19 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/state"
+	"github.com/adrg/xdg"
 )
 
 func TestNewRuntime(t *testing.T) {
@@ -360,6 +371,114 @@ func TestRuntime_AddOperationFlags(t *testing.T) {
 	}
 }
 
+const composedAccountsSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Accounts", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "get": {
+        "operationId": "listUsers",
+        "tags": ["users"],
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/admin/purge": {
+      "post": {"operationId": "purge", "tags": ["admin"], "responses": {"204": {"description": "Purged"}}}
+    }
+  }
+}`
+
+const composedBillingSpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Billing", "version": "1.0.0"},
+  "paths": {
+    "/invoices": {
+      "get": {"operationId": "listInvoices", "tags": ["invoices"], "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+func TestRuntime_ComposedSpecs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	t.Setenv("BILLING_KEY", "secret")
+
+	var requests []string
+	server := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, name+" "+r.URL.String()+" key="+r.Header.Get("X-Billing-Key"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[]`))
+		}))
+	}
+	accounts, billing := server("accounts"), server("billing")
+	defer accounts.Close()
+	defer billing.Close()
+
+	dir := t.TempDir()
+	for name, spec := range map[string]string{"accounts.json": composedAccountsSpec, "billing.json": composedBillingSpec} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(spec), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stderr bytes.Buffer
+	rt, err := NewRuntime(context.Background(), &RuntimeConfig{
+		CLIName: "platform",
+		BaseURL: accounts.URL,
+		Stderr:  &stderr,
+		Specs: []cli.APISpec{
+			{Name: "accounts", OpenAPIURL: filepath.Join(dir, "accounts.json"), ExcludeTags: []string{"admin"}},
+			{
+				Name:       "billing",
+				OpenAPIURL: filepath.Join(dir, "billing.json"),
+				Mount:      "billing",
+				BaseURL:    billing.URL,
+				Auth:       &cli.AuthBehavior{Type: "api_key", APIKey: &cli.APIKeyAuth{Header: "X-Billing-Key", EnvVar: "BILLING_KEY"}},
+			},
+			{Name: "search", OpenAPIURL: filepath.Join(dir, "missing.json"), Mount: "search"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuntime() error = %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "Warning: failed to load spec search:") {
+		t.Errorf("Expected a warning for the missing spec, got %q", stderr.String())
+	}
+
+	run := func(args ...string) error {
+		rt.rootCmd.SetOut(&bytes.Buffer{})
+		rt.rootCmd.SetErr(&bytes.Buffer{})
+		rt.rootCmd.SetArgs(args)
+		return rt.rootCmd.Execute()
+	}
+
+	if err := run("users", "list-users", "--limit", "5"); err != nil {
+		t.Fatalf("users list-users: %v", err)
+	}
+	if err := run("billing", "invoices", "list-invoices"); err != nil {
+		t.Fatalf("billing invoices list-invoices: %v", err)
+	}
+	want := []string{"accounts /users?limit=5 key=", "billing /invoices key=secret"}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+
+	if cmd, _, _ := rt.rootCmd.Find([]string{"admin"}); cmd != rt.rootCmd {
+		t.Error("Expected excluded tags to have no commands")
+	}
+
+	err = run("search", "query")
+	if err == nil || !strings.Contains(err.Error(), "commands of search are unavailable") {
+		t.Errorf("Expected the search commands to be unavailable, got %v", err)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && s != substr && len(s) >= len(substr) &&
@@ -374,3 +493,108 @@ func hasSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRuntime_SpecDrift(t *testing.T) {
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, t.TempDir())
+	}
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	history, err := state.NewHistory("platform", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := history.RecordCommand("platform users list-users --limit 5", 0, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	version, limitType := "1.0.0", "integer"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + version + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = fmt.Fprintf(w, `{"openapi": "3.0.3", "info": {"title": "Accounts", "version": %q}, "paths": {
+			"/users": {"get": {"operationId": "listUsers", "tags": ["users"], "parameters": [{"name": "limit", "in": "query", "schema": {"type": %q}}], "responses": {"200": {"description": "OK"}}}},
+			"/groups": {"get": {"operationId": "listGroups", "tags": ["users"], "parameters": [{"name": "limit", "in": "query", "schema": {"type": %q}}], "responses": {"200": {"description": "OK"}}}}
+		}}`, version, limitType, limitType)
+	}))
+	defer server.Close()
+
+	newRuntime := func() string {
+		var stderr bytes.Buffer
+		if _, err := NewRuntime(context.Background(), &RuntimeConfig{CLIName: "platform", SpecPath: server.URL, Stderr: &stderr}); err != nil {
+			t.Fatalf("NewRuntime() error = %v", err)
+		}
+		return stderr.String()
+	}
+
+	if out := newRuntime(); out != "" {
+		t.Errorf("Expected no notice for a new spec, got %q", out)
+	}
+
+	version, limitType = "2.0.0", "string"
+	want := "Notice: Accounts changed to version 2.0.0, affecting commands you ran recently:\n" +
+		"  - flag --limit of \"users list-users\" changed type from int to string\n"
+	if out := newRuntime(); out != want {
+		t.Errorf("notice = %q, want %q", out, want)
+	}
+
+	if out := newRuntime(); out != "" {
+		t.Errorf("Expected the notice once, got %q", out)
+	}
+}
+
+// workflowSpec has a single x-cli-workflow operation.
+const workflowSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Deploy API", "version": "1.0.0"},
+  "paths": {
+    "/deployments": {
+      "post": {
+        "operationId": "deploy",
+        "responses": {"201": {"description": "Created"}},
+        "x-cli-workflow": {
+          "steps": [{"id": "create", "request": {"method": "POST", "url": "/deployments"}}]
+        }
+      }
+    }
+  }
+}`
+
+func TestRuntime_WorkflowCommand(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "workflow.json")
+	if err := os.WriteFile(spec, []byte(workflowSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{spec: spec, want: true},
+		{spec: "../../examples/openapi/swagger2-example.json", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.spec), func(t *testing.T) {
+			runtime, err := NewRuntime(context.Background(), &RuntimeConfig{
+				CLIName:  "testcli",
+				SpecPath: tt.spec,
+				BaseURL:  "https://api.example.com",
+			})
+			if err != nil {
+				t.Fatalf("Failed to create runtime: %v", err)
+			}
+
+			cmd, _, err := runtime.rootCmd.Find([]string{"workflow", "rollback"})
+			found := err == nil && cmd.Name() == "rollback"
+			if found != tt.want {
+				t.Errorf("Expected workflow rollback to be mounted: %v, got %v", tt.want, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSynthesisDepth bounds the nesting of synthesised data, which also
+// stops recursive schemas.
+const maxSynthesisDepth = 8
+
+// selectResponse finds the response of operation with the given status
+// code, or its lowest 2xx response when code is 0.
+func selectResponse(operation *openapi3.Operation, code int) (int, *openapi3.Response, error) {
+	var responses map[string]*openapi3.ResponseRef
+	if operation.Responses != nil {
+		responses = operation.Responses.Map()
+	}
+
+	if code != 0 {
+		for _, key := range []string{strconv.Itoa(code), fmt.Sprintf("%dXX", code/100), "default"} {
+			if ref := responses[key]; ref != nil && ref.Value != nil {
+				return code, ref.Value, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("the operation has no %d response", code)
+	}
+
+	best, bestKey := 0, ""
+	for key, ref := range responses {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		status, err := strconv.Atoi(key)
+		if err != nil {
+			switch key {
+			case "2XX", "2xx", "default":
+				status = 200
+			default:
+				continue
+			}
+		}
+		if bestKey == "" || betterResponse(status, key, best, bestKey) {
+			best, bestKey = status, key
+		}
+	}
+	if bestKey == "" {
+		return 200, nil, nil
+	}
+	return best, responses[bestKey].Value, nil
+}
+
+// betterResponse reports whether a response is a better default than the
+// best so far: success responses first, then the lowest code.
+func betterResponse(status int, key string, best int, bestKey string) bool {
+	if successRank(status) != successRank(best) {
+		return successRank(status) < successRank(best)
+	}
+	if status != best {
+		return status < best
+	}
+	return key < bestKey
+}
+
+// successRank orders 2xx responses before others.
+func successRank(status int) int {
+	if status >= 200 && status < 300 {
+		return 0
+	}
+	return 1
+}
+
+// selectMedia picks the JSON content of a response, else its first.
+func selectMedia(resp *openapi3.Response) (string, *openapi3.MediaType) {
+	if resp == nil || len(resp.Content) == 0 {
+		return "", nil
+	}
+	if media := resp.Content["application/json"]; media != nil {
+		return "application/json", media
+	}
+
+	types := make([]string, 0, len(resp.Content))
+	for contentType := range resp.Content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if isJSON(contentType) {
+			return contentType, resp.Content[contentType]
+		}
+	}
+	return types[0], resp.Content[types[0]]
+}
+
+// exampleResponse builds a response of operation from its examples, as
+// chosen by prefer.
+func exampleResponse(operation *openapi3.Operation, prefer *preference) (*response, error) {
+	status, resp, err := selectResponse(operation, prefer.code)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType, media := selectMedia(resp)
+	if media == nil {
+		return &response{status: status}, nil
+	}
+
+	body, err := mediaExample(media, prefer)
+	if err != nil {
+		return nil, err
+	}
+	return &response{status: status, contentType: contentType, body: body, hasBody: body != nil}, nil
+}
+
+// mediaExample returns a copy of the example of a media type chosen by
+// prefer, or data synthesised from its schema.
+func mediaExample(media *openapi3.MediaType, prefer *preference) (interface{}, error) {
+	if prefer.example != "" && !prefer.dynamic {
+		ref := media.Examples[prefer.example]
+		if ref == nil || ref.Value == nil {
+			return nil, fmt.Errorf("the response has no example named %q", prefer.example)
+		}
+		return clone(ref.Value.Value), nil
+	}
+
+	if !prefer.dynamic {
+		if media.Example != nil {
+			return clone(media.Example), nil
+		}
+		names := make([]string, 0, len(media.Examples))
+		for name, ref := range media.Examples {
+			if ref != nil && ref.Value != nil && ref.Value.Value != nil {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return clone(media.Examples[names[0]].Value.Value), nil
+		}
+	}
+
+	if media.Schema == nil {
+		return nil, nil
+	}
+	return clone(synthesize(media.Schema.Value, !prefer.dynamic, 0)), nil
+}
+
+// synthesize makes up data matching schema. With useExamples, the
+// examples and defaults of the schema and its properties are used.
+func synthesize(schema *openapi3.Schema, useExamples bool, depth int) interface{} {
+	if schema == nil || depth > maxSynthesisDepth {
+		return nil
+	}
+	if useExamples {
+		if schema.Example != nil {
+			return schema.Example
+		}
+		if schema.Default != nil {
+			return schema.Default
+		}
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, ref := range schema.AllOf {
+			if ref == nil {
+				continue
+			}
+			if object, ok := synthesize(ref.Value, useExamples, depth+1).(map[string]interface{}); ok {
+				for key, value := range object {
+					merged[key] = value
+				}
+			}
+		}
+		if len(schema.Properties) > 0 {
+			for key, value := range synthesizeObject(schema, useExamples, depth) {
+				merged[key] = value
+			}
+		}
+		return merged
+	}
+	for _, refs := range [][]*openapi3.SchemaRef{schema.OneOf, schema.AnyOf} {
+		if len(refs) > 0 && refs[0] != nil {
+			return synthesize(refs[0].Value, useExamples, depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		return synthesizeObject(schema, useExamples, depth)
+	case "array":
+		count := 1
+		if schema.MinItems > 1 {
+			count = int(schema.MinItems)
+		}
+		items := make([]interface{}, 0, count)
+		if schema.Items != nil {
+			for i := 0; i < count; i++ {
+				if item := synthesize(schema.Items.Value, useExamples, depth+1); item != nil {
+					items = append(items, item)
+				}
+			}
+		}
+		return items
+	case "string":
+		return synthesizeString(schema)
+	case "integer":
+		if schema.Min != nil {
+			return int64(*schema.Min)
+		}
+		return 1
+	case "number":
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 1.0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// synthesizeObject makes up the properties of an object schema, leaving
+// out write-only ones.
+func synthesizeObject(schema *openapi3.Schema, useExamples bool, depth int) map[string]interface{} {
+	object := make(map[string]interface{}, len(schema.Properties))
+	for name, ref := range schema.Properties {
+		if ref == nil || ref.Value == nil || ref.Value.WriteOnly {
+			continue
+		}
+		if value := synthesize(ref.Value, useExamples, depth+1); value != nil {
+			object[name] = value
+		}
+	}
+	return object
+}
+
+// synthesizeString makes up a string in the format of schema.
+func synthesizeString(schema *openapi3.Schema) string {
+	var value string
+	switch schema.Format {
+	case "date-time":
+		value = "2024-01-01T00:00:00Z"
+	case "date":
+		value = "2024-01-01"
+	case "time":
+		value = "00:00:00"
+	case "uuid":
+		value = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		value = "user@example.com"
+	case "uri", "url":
+		value = "https://example.com"
+	case "hostname":
+		value = "example.com"
+	case "ipv4":
+		value = "192.0.2.1"
+	case "ipv6":
+		value = "2001:db8::1"
+	case "byte":
+		value = "c3RyaW5n"
+	default:
+		value = "string"
+	}
+	if minLength := int(schema.MinLength); len(value) < minLength {
+		value += strings.Repeat("x", minLength-len(value))
+	}
+	return value
+}
+
+// schemaType returns the type of schema, guessing object for schemas with
+// properties.
+func schemaType(schema *openapi3.Schema) string {
+	if schema.Type != nil {
+		for _, t := range schema.Type.Slice() {
+			if t != "null" {
+				return t
+			}
+		}
+	}
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+	if schema.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+// clone deep copies JSON data, so examples of the spec are never changed.
+func clone(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return value
+	}
+	return copied
+}
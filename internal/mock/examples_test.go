@@ -0,0 +1,83 @@
+package mock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+func TestSynthesize(t *testing.T) {
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(`
+openapi: 3.0.3
+info: {title: Synth, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    Node:
+      allOf:
+        - $ref: "#/components/schemas/Base"
+        - type: object
+          properties:
+            children:
+              type: array
+              items: {$ref: "#/components/schemas/Node"}
+            kind:
+              oneOf:
+                - {type: string, enum: [leaf, branch]}
+                - {type: integer}
+            password: {type: string, writeOnly: true}
+    Base:
+      type: object
+      properties:
+        id: {type: string, format: uuid}
+        size: {type: integer, minimum: 3, example: 7}
+        ratio: {type: number}
+        active: {type: boolean, default: false}
+        code: {type: string, minLength: 8}
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	schema := spec.Spec.Components.Schemas["Node"].Value
+
+	got, ok := clone(synthesize(schema, true, 0)).(map[string]interface{})
+	if !ok {
+		t.Fatalf("synthesize() = %v, want an object", got)
+	}
+	want := map[string]interface{}{
+		"id":     "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"size":   7.0,
+		"ratio":  1.0,
+		"active": false,
+		"code":   "stringxx",
+		"kind":   "leaf",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("synthesize()[%q] = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := got["password"]; ok {
+		t.Error("Expected write-only properties to be left out")
+	}
+
+	// Recursion stops at the maximum depth
+	levels := 0
+	for node := got; node != nil; levels++ {
+		children, _ := node["children"].([]interface{})
+		node = nil
+		if len(children) > 0 {
+			node, _ = children[0].(map[string]interface{})
+		}
+	}
+	if levels < 2 || levels > maxSynthesisDepth {
+		t.Errorf("Expected 2 to %d levels of children, got %d", maxSynthesisDepth, levels)
+	}
+
+	// Without examples, minimums and types are used
+	got, _ = clone(synthesize(schema, false, 0)).(map[string]interface{})
+	if got["size"] != 3.0 || got["active"] != true {
+		t.Errorf("synthesize() without examples = %v", got)
+	}
+}
@@ -0,0 +1,493 @@
+// Package mock serves an OpenAPI spec as a local mock API.
+//
+// The mock server answers every operation of a spec from its examples,
+// or from data synthesised from its schemas, so generated CLIs can be
+// demoed and integration-tested without a backend.
+//
+// # Responses
+//
+// An operation answers with its lowest 2xx response. The body is the
+// named example chosen by the request, else the media type's example,
+// else its first named example, else data synthesised from the schema.
+// Clients choose with the Prefer header:
+//
+//	Prefer: code=404              # respond with the 404 response
+//	Prefer: example=soldOut       # use the named example
+//	Prefer: dynamic=true          # synthesise from the schema
+//	Prefer: terminal=error        # end an async operation in this state
+//
+// # Resources
+//
+// A path ending in a path parameter whose parent path is also in the spec
+// is an item of a collection, like /pets/{petId} of /pets. Collections
+// keep their items: POST creates, GET lists and reads, PUT replaces,
+// PATCH merges and DELETE removes them. Collections start with the items
+// of their list response example.
+//
+// # Async Operations
+//
+// Operations with x-cli-async report a pending status, and reach their
+// first terminal state after Options.AsyncSteps reads of their status
+// endpoint, which defaults to the created or changed resource.
+//
+// # Watch Endpoints
+//
+// Operations with an x-cli-watch of type sse or websocket stream the
+// watched resource from their watch endpoint every Options.WatchInterval,
+// until its async operation reaches a terminal state or the client
+// disconnects.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// Options configures a mock Server.
+type Options struct {
+	// AsyncSteps is how many status reads an async operation stays
+	// pending for. Defaults to 3.
+	AsyncSteps int
+	// WatchInterval is the time between watch events. Defaults to 1s.
+	WatchInterval time.Duration
+	// Log receives a line per request. Nil disables logging.
+	Log io.Writer
+	// SpecPath is the path SpecData is served at, so CLIs can load the
+	// spec from the mock. Empty serves no spec.
+	SpecPath string
+	SpecData []byte
+}
+
+// Server is an http.Handler serving a spec as a mock API.
+type Server struct {
+	spec    *openapi.ParsedSpec
+	opts    Options
+	routes  []*route
+	watches []*watchRoute
+	// basePaths are the paths of the spec's servers, which requests may
+	// be prefixed with
+	basePaths []string
+	// terminal holds the terminal states of every async operation, which
+	// are never used as pending states
+	terminal map[string]bool
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	jobs        map[string]*asyncJob
+}
+
+// route is a path of the spec with its operations.
+type route struct {
+	template string
+	pattern  *regexp.Regexp
+	params   []string
+	ops      map[string]*openapi.Operation
+	kind     routeKind
+	// idParam is the path parameter identifying an item
+	idParam string
+	// parent is the collection route of an item route
+	parent *route
+	// item is the item route of a collection route
+	item *route
+}
+
+type routeKind int
+
+const (
+	plainRoute routeKind = iota
+	collectionRoute
+	itemRoute
+)
+
+// NewServer creates a mock server for spec.
+func NewServer(spec *openapi.ParsedSpec, opts *Options) (*Server, error) {
+	if spec == nil || spec.Spec == nil || spec.Spec.Paths == nil {
+		return nil, fmt.Errorf("spec has no paths")
+	}
+
+	s := &Server{
+		spec:        spec,
+		terminal:    make(map[string]bool),
+		collections: make(map[string]*collection),
+		jobs:        make(map[string]*asyncJob),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.AsyncSteps <= 0 {
+		s.opts.AsyncSteps = 3
+	}
+	if s.opts.WatchInterval <= 0 {
+		s.opts.WatchInterval = time.Second
+	}
+
+	for _, server := range spec.Spec.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil || strings.Contains(u.Path, "{") {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+			s.basePaths = append(s.basePaths, base)
+		}
+	}
+
+	operations, err := spec.GetOperations()
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]map[string]*openapi.Operation)
+	for _, op := range operations {
+		if byPath[op.Path] == nil {
+			byPath[op.Path] = make(map[string]*openapi.Operation)
+		}
+		byPath[op.Path][op.Method] = op
+	}
+	// Hidden operations have no command, but are still part of the API
+	for path, item := range spec.Spec.Paths.Map() {
+		for method, operation := range item.Operations() {
+			if byPath[path] == nil {
+				byPath[path] = make(map[string]*openapi.Operation)
+			}
+			if byPath[path][method] == nil {
+				byPath[path][method] = &openapi.Operation{Method: method, Path: path, OperationID: operation.OperationID, Operation: operation}
+			}
+		}
+	}
+
+	byTemplate := make(map[string]*route)
+	for path, ops := range byPath {
+		rt := newRoute(path)
+		rt.ops = ops
+		s.routes = append(s.routes, rt)
+		byTemplate[strings.TrimSuffix(path, "/")] = rt
+
+		for _, op := range ops {
+			if op.CLIAsync != nil && op.CLIAsync.Enabled {
+				for _, state := range op.CLIAsync.TerminalStates {
+					s.terminal[state] = true
+				}
+			}
+			if op.CLIWatch != nil && op.CLIWatch.Enabled && (op.CLIWatch.Type == "sse" || op.CLIWatch.Type == "websocket") {
+				endpoint := op.CLIWatch.Endpoint
+				if endpoint == "" {
+					endpoint = op.Path
+				}
+				s.watches = append(s.watches, &watchRoute{route: newRoute(s.stripBase(endpoint)), op: op})
+			}
+		}
+	}
+
+	// An item's parent path must be in the spec to hold its collection
+	for _, rt := range s.routes {
+		parent, last := splitLast(strings.TrimSuffix(rt.template, "/"))
+		if len(last) < 3 || last[0] != '{' || last[len(last)-1] != '}' || strings.Count(last, "{") != 1 {
+			continue
+		}
+		if parentRoute := byTemplate[parent]; parentRoute != nil {
+			rt.kind = itemRoute
+			rt.idParam = last[1 : len(last)-1]
+			rt.parent = parentRoute
+			parentRoute.kind = collectionRoute
+			parentRoute.item = rt
+		}
+	}
+
+	// Literal paths win over parameters, like /pets/mine over /pets/{id}
+	sort.Slice(s.routes, func(i, j int) bool {
+		if len(s.routes[i].params) != len(s.routes[j].params) {
+			return len(s.routes[i].params) < len(s.routes[j].params)
+		}
+		return s.routes[i].template < s.routes[j].template
+	})
+	sort.Slice(s.watches, func(i, j int) bool {
+		return len(s.watches[i].route.params) < len(s.watches[j].route.params)
+	})
+
+	return s, nil
+}
+
+// paramPattern matches the path parameters of a path template.
+var paramPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// newRoute compiles a path template.
+func newRoute(template string) *route {
+	rt := &route{template: template}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, match := range paramPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:match[0]]))
+		pattern.WriteString("([^/]+)")
+		rt.params = append(rt.params, template[match[2]:match[3]])
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(strings.TrimSuffix(template[last:], "/")))
+	pattern.WriteString("/?$")
+	rt.pattern = regexp.MustCompile(pattern.String())
+
+	return rt
+}
+
+// match returns the path parameters of path, or nil if the route does not
+// match it.
+func (rt *route) match(path string) map[string]string {
+	values := rt.pattern.FindStringSubmatch(path)
+	if values == nil {
+		return nil
+	}
+	params := make(map[string]string, len(rt.params))
+	for i, name := range rt.params {
+		value, err := url.PathUnescape(values[i+1])
+		if err != nil {
+			value = values[i+1]
+		}
+		params[name] = value
+	}
+	return params
+}
+
+// splitLast splits a path into its parent and last segment.
+func splitLast(path string) (string, string) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+// stripBase removes the base path of the spec's servers from path.
+func (s *Server) stripBase(path string) string {
+	for _, base := range s.basePaths {
+		if path == base {
+			return "/"
+		}
+		if strings.HasPrefix(path, base+"/") {
+			return strings.TrimPrefix(path, base)
+		}
+	}
+	return path
+}
+
+// preference is what the Prefer header asks for.
+type preference struct {
+	code     int
+	example  string
+	dynamic  bool
+	terminal string
+}
+
+// parsePrefer reads the Prefer headers of r.
+func parsePrefer(r *http.Request) (*preference, error) {
+	prefer := &preference{}
+	for _, header := range r.Header.Values("Prefer") {
+		for _, token := range strings.FieldsFunc(header, func(c rune) bool { return c == ',' || c == ';' }) {
+			key, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			switch strings.TrimSpace(key) {
+			case "code":
+				code, err := strconv.Atoi(value)
+				if err != nil || code < 100 || code > 599 {
+					return nil, fmt.Errorf("invalid Prefer code %q", value)
+				}
+				prefer.code = code
+			case "example":
+				prefer.example = value
+			case "dynamic":
+				prefer.dynamic = value == "true"
+			case "terminal":
+				prefer.terminal = value
+			}
+		}
+	}
+	return prefer, nil
+}
+
+// ServeHTTP answers a request from the spec.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.SpecPath != "" && r.Method == http.MethodGet && r.URL.Path == s.opts.SpecPath {
+		contentType := "application/yaml"
+		if strings.HasSuffix(s.opts.SpecPath, ".json") {
+			contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", contentType)
+		s.logf("%s %s %d", r.Method, r.URL.Path, http.StatusOK)
+		_, _ = w.Write(s.opts.SpecData)
+		return
+	}
+
+	path := s.stripBase(r.URL.Path)
+
+	if r.Method == http.MethodGet && isStreamRequest(r) {
+		for _, watch := range s.watches {
+			if params := watch.route.match(path); params != nil {
+				s.serveWatch(w, r, watch, params)
+				return
+			}
+		}
+	}
+
+	var rt *route
+	var params map[string]string
+	for _, candidate := range s.routes {
+		if params = candidate.match(path); params != nil {
+			rt = candidate
+			break
+		}
+	}
+	if rt == nil {
+		// Watch endpoints outside the spec's paths
+		if r.Method == http.MethodGet {
+			for _, watch := range s.watches {
+				if params := watch.route.match(path); params != nil {
+					s.serveWatch(w, r, watch, params)
+					return
+				}
+			}
+		}
+		s.writeError(w, r, http.StatusNotFound, fmt.Sprintf("no path of the spec matches %s", path))
+		return
+	}
+
+	op := rt.ops[r.Method]
+	if op == nil {
+		var allowed []string
+		for method := range rt.ops {
+			allowed = append(allowed, method)
+		}
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		s.writeError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("%s has no %s operation", rt.template, r.Method))
+		return
+	}
+
+	prefer, err := parsePrefer(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var input interface{}
+	if r.Body != nil {
+		data, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("failed to read body: %v", err))
+			return
+		}
+		contentType := r.Header.Get("Content-Type")
+		if len(data) > 0 && (contentType == "" || strings.Contains(contentType, "json")) {
+			if err := json.Unmarshal(data, &input); err != nil {
+				s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+				return
+			}
+		}
+	}
+
+	req := &request{
+		route:   rt,
+		op:      op,
+		path:    strings.TrimSuffix(path, "/"),
+		urlPath: r.URL.Path,
+		params:  params,
+		prefer:  prefer,
+		input:   input,
+	}
+
+	s.mu.Lock()
+	resp, err := s.handle(req)
+	s.mu.Unlock()
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.write(w, r, resp)
+}
+
+// request is a request matched to an operation.
+type request struct {
+	route *route
+	op    *openapi.Operation
+	// path is the path without the base path of the spec's servers
+	path    string
+	urlPath string
+	params  map[string]string
+	prefer  *preference
+	input   interface{}
+}
+
+// response is a response to write.
+type response struct {
+	status      int
+	contentType string
+	body        interface{}
+	// hasBody is false for responses without content
+	hasBody bool
+	headers map[string]string
+}
+
+// write writes resp.
+func (s *Server) write(w http.ResponseWriter, r *http.Request, resp *response) {
+	for key, value := range resp.headers {
+		w.Header().Set(key, value)
+	}
+	s.logf("%s %s %d", r.Method, r.URL.Path, resp.status)
+
+	if !resp.hasBody {
+		w.WriteHeader(resp.status)
+		return
+	}
+
+	contentType := resp.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	if text, ok := resp.body.(string); ok && !isJSON(contentType) {
+		w.WriteHeader(resp.status)
+		_, _ = io.WriteString(w, text)
+		return
+	}
+	data, err := json.MarshalIndent(resp.body, "", "  ")
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+		return
+	}
+	w.WriteHeader(resp.status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// writeError writes an error the mock server found itself, as opposed to
+// an error response of the spec.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	s.write(w, r, &response{
+		status:  status,
+		body:    map[string]interface{}{"code": status, "message": message},
+		hasBody: true,
+	})
+}
+
+// logf writes a line to the log.
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.opts.Log != nil {
+		_, _ = fmt.Fprintf(s.opts.Log, "%s "+format+"\n", append([]interface{}{time.Now().Format("15:04:05")}, args...)...)
+	}
+}
+
+// isJSON reports whether a content type is JSON.
+func isJSON(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,295 @@
+package mock
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/gorilla/websocket"
+)
+
+const mockSpec = `
+openapi: 3.0.3
+info: {title: Clusters, version: "1.0"}
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /clusters:
+    get:
+      operationId: listClusters
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              example:
+                items:
+                  - {id: "c1", name: prod, state: ready}
+                total: 1
+    post:
+      operationId: createCluster
+      x-cli-async:
+        enabled: true
+        status-field: state
+        status-endpoint: /clusters/{id}
+        terminal-states: [ready, error]
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Cluster"}
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Cluster"}
+        "400":
+          description: Bad request
+          content:
+            application/json:
+              examples:
+                invalidName:
+                  value: {message: invalid name}
+  /clusters/{cluster_id}:
+    get:
+      operationId: getCluster
+      x-cli-watch:
+        enabled: true
+        type: sse
+        endpoint: /clusters/{cluster_id}/events
+        events: [state-change]
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Cluster"}
+        "404":
+          description: Not found
+          content:
+            application/json:
+              example: {message: cluster not found}
+    patch:
+      operationId: updateCluster
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Cluster"}
+    delete:
+      operationId: deleteCluster
+      x-cli-async:
+        enabled: true
+        status-field: state
+        terminal-states: [deleted]
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "202":
+          description: Accepted
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Cluster"}
+  /clusters/{cluster_id}/logs:
+    get:
+      operationId: getClusterLogs
+      x-cli-watch:
+        enabled: true
+        type: websocket
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  line: {type: string, example: started}
+components:
+  schemas:
+    Cluster:
+      type: object
+      properties:
+        id: {type: string}
+        name: {type: string}
+        state: {type: string, enum: [pending, installing, ready, error, deleted]}
+        created_at: {type: string, format: date-time, readOnly: true}
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	spec, err := openapi.NewParser().Parse(context.Background(), []byte(mockSpec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	server, err := NewServer(spec, &Options{AsyncSteps: 2, WatchInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// call sends a request and decodes its JSON response.
+func call(t *testing.T, ts *httptest.Server, method, path, body string, headers ...string) (int, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, _ := io.ReadAll(resp.Body)
+	var decoded map[string]interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s %s returned invalid JSON %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestServer_Resources(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Collections start with the items of the list example
+	status, body := call(t, ts, "GET", "/clusters", "")
+	if status != 200 || body["total"] != 1.0 {
+		t.Fatalf("GET /clusters = %d %v", status, body)
+	}
+
+	// Created items get a new ID and fields from the schema
+	status, body = call(t, ts, "POST", "/v1/clusters", `{"name": "dev"}`)
+	if status != 201 || body["id"] != "2" || body["name"] != "dev" || body["created_at"] != "2024-01-01T00:00:00Z" {
+		t.Fatalf("POST /clusters = %d %v", status, body)
+	}
+
+	// The async create is pending until its status was read twice
+	if _, body = call(t, ts, "GET", "/clusters/2", ""); body["state"] != "pending" {
+		t.Errorf("Expected state pending, got %v", body["state"])
+	}
+	if _, body = call(t, ts, "GET", "/clusters/2", ""); body["state"] != "ready" {
+		t.Errorf("Expected state ready, got %v", body["state"])
+	}
+
+	if status, body = call(t, ts, "PATCH", "/clusters/2", `{"name": "staging"}`); status != 200 || body["name"] != "staging" || body["state"] != "ready" {
+		t.Errorf("PATCH /clusters/2 = %d %v", status, body)
+	}
+	if _, body = call(t, ts, "GET", "/clusters", ""); body["total"] != 2.0 {
+		t.Errorf("Expected 2 clusters, got %v", body)
+	}
+
+	// Async deletes remove the item when they reach a terminal state
+	if status, body = call(t, ts, "DELETE", "/clusters/2", ""); status != 202 || body["state"] != "pending" {
+		t.Errorf("DELETE /clusters/2 = %d %v", status, body)
+	}
+	call(t, ts, "GET", "/clusters/2", "")
+	if _, body = call(t, ts, "GET", "/clusters/2", ""); body["state"] != "deleted" {
+		t.Errorf("Expected state deleted, got %v", body["state"])
+	}
+	if status, body = call(t, ts, "GET", "/clusters/2", ""); status != 404 || body["message"] != "cluster not found" {
+		t.Errorf("GET deleted cluster = %d %v", status, body)
+	}
+
+	if status, _ = call(t, ts, "PUT", "/clusters/1", "{}"); status != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for PUT, got %d", status)
+	}
+	if status, _ = call(t, ts, "GET", "/nodes", ""); status != 404 {
+		t.Errorf("Expected 404 for an unknown path, got %d", status)
+	}
+}
+
+func TestServer_Prefer(t *testing.T) {
+	ts := newTestServer(t)
+
+	status, body := call(t, ts, "POST", "/clusters", `{}`, "Prefer", "code=400, example=invalidName")
+	if status != 400 || body["message"] != "invalid name" {
+		t.Errorf("Prefer code=400 = %d %v", status, body)
+	}
+	if status, _ = call(t, ts, "POST", "/clusters", `{}`, "Prefer", "code=418"); status != 400 {
+		t.Errorf("Expected 400 for an undeclared code, got %d", status)
+	}
+
+	_, body = call(t, ts, "POST", "/clusters", `{"id": "broken"}`, "Prefer", "terminal=error")
+	call(t, ts, "GET", "/clusters/broken", "")
+	if _, body = call(t, ts, "GET", "/clusters/broken", ""); body["state"] != "error" {
+		t.Errorf("Expected state error, got %v", body["state"])
+	}
+	if status, _ = call(t, ts, "POST", "/clusters", `{"id": "broken"}`); status != 409 {
+		t.Errorf("Expected 409 for a duplicate ID, got %d", status)
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	ts := newTestServer(t)
+	call(t, ts, "POST", "/clusters", `{"id": "c2"}`)
+
+	// SSE ends when the async operation reaches a terminal state
+	req, _ := http.NewRequest("GET", ts.URL+"/clusters/c2/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "event:") || strings.HasPrefix(line, "data:") {
+			events = append(events, line)
+		}
+	}
+	want := []string{
+		"event: state-change", `data: {"created_at":"2024-01-01T00:00:00Z","id":"c2","name":"string","state":"pending"}`,
+		"event: state-change", `data: {"created_at":"2024-01-01T00:00:00Z","id":"c2","name":"string","state":"ready"}`,
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SSE events =\n%s\nwant\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+
+	// WebSockets stream the operation's example
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/clusters/c2/logs", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	var message struct {
+		Event string                 `json:"event"`
+		Data  map[string]interface{} `json:"data"`
+	}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if message.Event != "message" || message.Data["line"] != "started" {
+		t.Errorf("Unexpected message %+v", message)
+	}
+
+	// Plain requests to the watched path still get JSON
+	if status, body := call(t, ts, "GET", "/clusters/c2/logs", ""); status != 200 || body["line"] != "started" {
+		t.Errorf("GET /clusters/c2/logs = %d %v", status, body)
+	}
+}
@@ -0,0 +1,561 @@
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// collection holds the items of a collection, in creation order.
+type collection struct {
+	ids   []string
+	items map[string]map[string]interface{}
+}
+
+// put stores an item.
+func (c *collection) put(id string, item map[string]interface{}) {
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = item
+}
+
+// remove deletes an item.
+func (c *collection) remove(id string) {
+	delete(c.items, id)
+	for i, existing := range c.ids {
+		if existing == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// list returns copies of the items.
+func (c *collection) list() []interface{} {
+	items := make([]interface{}, 0, len(c.ids))
+	for _, id := range c.ids {
+		items = append(items, clone(c.items[id]))
+	}
+	return items
+}
+
+// asyncJob is an async operation in progress, found by the path of its
+// status endpoint.
+type asyncJob struct {
+	field    string
+	pending  string
+	terminal string
+	reads    int
+	// body answers status reads of paths without a stored item
+	body map[string]interface{}
+	// remove deletes the item at the status endpoint when the job ends
+	remove bool
+}
+
+// handle answers a request. The caller holds s.mu.
+func (s *Server) handle(req *request) (*response, error) {
+	if req.op.Method == http.MethodGet {
+		if body, ok := s.poll(req.path); ok {
+			resp, err := s.itemResponse(req, body)
+			if err != nil {
+				return nil, err
+			}
+			resp.hasBody = true
+			return resp, nil
+		}
+	}
+
+	// Error responses come straight from the spec
+	if req.prefer.code != 0 && successRank(req.prefer.code) != 0 {
+		return exampleResponse(req.op.Operation, req.prefer)
+	}
+
+	switch req.route.kind {
+	case collectionRoute:
+		switch req.op.Method {
+		case http.MethodGet:
+			return s.list(req)
+		case http.MethodPost:
+			return s.create(req)
+		}
+	case itemRoute:
+		switch req.op.Method {
+		case http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			return s.item(req)
+		}
+	}
+
+	resp, err := exampleResponse(req.op.Operation, req.prefer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.startJob(req, resp.bodyObject(), nil, req.path); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// collection returns the collection at path, filling a new one with the
+// items of the list response example of rt.
+func (s *Server) collection(rt *route, path string) *collection {
+	if c := s.collections[path]; c != nil {
+		return c
+	}
+
+	c := &collection{items: make(map[string]map[string]interface{})}
+	s.collections[path] = c
+
+	list := rt.ops[http.MethodGet]
+	if list == nil || rt.item == nil {
+		return c
+	}
+	resp, err := exampleResponse(list.Operation, &preference{})
+	if err != nil {
+		return c
+	}
+	var items []interface{}
+	switch body := resp.body.(type) {
+	case []interface{}:
+		items = body
+	case map[string]interface{}:
+		items, _ = body[arrayKey(body)].([]interface{})
+	}
+	for _, value := range items {
+		item, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, id, ok := itemID(item, rt.item.idParam); ok {
+			c.put(id, item)
+		}
+	}
+	return c
+}
+
+// list answers a list request with the items of the collection, in the
+// shape of the list response example.
+func (s *Server) list(req *request) (*response, error) {
+	resp, err := exampleResponse(req.op.Operation, req.prefer)
+	if err != nil || !resp.hasBody {
+		return resp, err
+	}
+
+	items := s.collection(req.route, req.path).list()
+	switch body := resp.body.(type) {
+	case []interface{}:
+		resp.body = items
+	case map[string]interface{}:
+		if key := arrayKey(body); key != "" {
+			body[key] = items
+			for _, countKey := range []string{"total", "count"} {
+				if _, ok := body[countKey].(float64); ok {
+					body[countKey] = float64(len(items))
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+// create adds the request body to the collection, filled in by the
+// response example and given a new ID unless it has one.
+func (s *Server) create(req *request) (*response, error) {
+	input, err := inputObject(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.route.item == nil {
+		return exampleResponse(req.op.Operation, req.prefer)
+	}
+	idParam := req.route.item.idParam
+	c := s.collection(req.route, req.path)
+
+	template, err := s.template(req)
+	if err != nil {
+		return nil, err
+	}
+	field, id, ok := itemID(input, idParam)
+	if !ok {
+		field, _, _ = itemID(template, idParam)
+		id = c.newID(template[field])
+		template[field] = typedID(template[field], id)
+	}
+	if _, exists := c.items[id]; exists {
+		return s.errorResponse(req, http.StatusConflict, fmt.Sprintf("%s %s already exists", req.path, id))
+	}
+
+	item := mergePatch(template, input)
+	c.put(id, item)
+
+	itemPath := req.path + "/" + url.PathEscape(id)
+	resp, err := s.itemResponse(req, item)
+	if err != nil {
+		return nil, err
+	}
+	resp.headers = map[string]string{"Location": strings.TrimSuffix(req.urlPath, "/") + "/" + url.PathEscape(id)}
+	if _, err := s.startJob(req, resp.bodyObject(), item, itemPath); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// item reads, replaces, merges into or deletes an item of a collection.
+func (s *Server) item(req *request) (*response, error) {
+	parent, _ := splitLast(req.path)
+	c := s.collection(req.route.parent, parent)
+	id := req.params[req.route.idParam]
+	item := c.items[id]
+
+	switch req.op.Method {
+	case http.MethodPut:
+		input, err := inputObject(req)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			if item, err = s.template(req); err != nil {
+				return nil, err
+			}
+		}
+		field, _, _ := itemID(item, req.route.idParam)
+		item = mergePatch(item, input)
+		item[field] = typedID(item[field], id)
+		c.put(id, item)
+	case http.MethodPatch:
+		input, err := inputObject(req)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return s.errorResponse(req, http.StatusNotFound, fmt.Sprintf("%s not found", req.path))
+		}
+		item = mergePatch(item, input)
+		c.put(id, item)
+	default:
+		if item == nil {
+			return s.errorResponse(req, http.StatusNotFound, fmt.Sprintf("%s not found", req.path))
+		}
+	}
+
+	resp, err := s.itemResponse(req, item)
+	if err != nil {
+		return nil, err
+	}
+	if req.op.Method == http.MethodGet {
+		return resp, nil
+	}
+	job, err := s.startJob(req, resp.bodyObject(), item, req.path)
+	if err != nil {
+		return nil, err
+	}
+	// Async deletes remove the item when they end
+	if req.op.Method == http.MethodDelete && (job == nil || !job.remove) {
+		c.remove(id)
+	}
+	return resp, nil
+}
+
+// itemResponse answers with a copy of item, using the status and media
+// type of the operation's response.
+func (s *Server) itemResponse(req *request, item map[string]interface{}) (*response, error) {
+	status, resp, err := selectResponse(req.op.Operation, req.prefer.code)
+	if err != nil {
+		return nil, err
+	}
+	contentType, media := selectMedia(resp)
+	if media == nil {
+		return &response{status: status}, nil
+	}
+	return &response{status: status, contentType: contentType, body: clone(item), hasBody: true}, nil
+}
+
+// bodyObject returns the body of resp if it is an object.
+func (resp *response) bodyObject() map[string]interface{} {
+	body, _ := resp.body.(map[string]interface{})
+	return body
+}
+
+// template returns the response example of the request as an object, to
+// fill in the fields of new items.
+func (s *Server) template(req *request) (map[string]interface{}, error) {
+	resp, err := exampleResponse(req.op.Operation, req.prefer)
+	if err != nil {
+		return nil, err
+	}
+	if body := resp.bodyObject(); body != nil {
+		return body, nil
+	}
+	return make(map[string]interface{}), nil
+}
+
+// errorResponse answers with the operation's response for status, or a
+// generic error when it has none.
+func (s *Server) errorResponse(req *request, status int, message string) (*response, error) {
+	if resp, err := exampleResponse(req.op.Operation, &preference{code: status}); err == nil {
+		return resp, nil
+	}
+	return &response{
+		status:  status,
+		body:    map[string]interface{}{"code": status, "message": message},
+		hasBody: true,
+	}, nil
+}
+
+// startJob starts the async operation of the request, if it has one,
+// setting its pending state in body and item. Its status endpoint
+// defaults to path, the path of item.
+func (s *Server) startJob(req *request, body, item map[string]interface{}, path string) (*asyncJob, error) {
+	async := req.op.CLIAsync
+	if async == nil || !async.Enabled || len(async.TerminalStates) == 0 {
+		return nil, nil
+	}
+
+	terminal := async.TerminalStates[0]
+	if req.prefer.terminal != "" {
+		if !contains(async.TerminalStates, req.prefer.terminal) {
+			return nil, fmt.Errorf("%q is not a terminal state of the operation", req.prefer.terminal)
+		}
+		terminal = req.prefer.terminal
+	}
+
+	itemPath := path
+	if async.StatusEndpoint != "" {
+		resolved, ok := expand(s.stripBase(async.StatusEndpoint), req.params, body)
+		if !ok {
+			s.logf("cannot resolve status endpoint %s of %s", async.StatusEndpoint, req.op.OperationID)
+			return nil, nil
+		}
+		path = resolved
+	}
+
+	field := async.StatusField
+	if field == "" {
+		field = "status"
+	}
+	job := &asyncJob{
+		field:    field,
+		pending:  s.pendingState(req, field, body),
+		terminal: terminal,
+		remove:   req.op.Method == http.MethodDelete && item != nil && path == itemPath,
+	}
+	if body != nil {
+		body[field] = job.pending
+		job.body, _ = clone(body).(map[string]interface{})
+	}
+	if item != nil {
+		item[field] = job.pending
+	}
+	s.jobs[path] = job
+	return job, nil
+}
+
+// pendingState picks the status of an async operation in progress: the
+// status of its response if that is not a terminal state, else the first
+// other value of the status field's enum, else "pending".
+func (s *Server) pendingState(req *request, field string, body map[string]interface{}) string {
+	if status, ok := body[field].(string); ok && status != "" && !s.terminal[status] {
+		return status
+	}
+
+	_, resp, err := selectResponse(req.op.Operation, 0)
+	if err == nil {
+		if _, media := selectMedia(resp); media != nil && media.Schema != nil && media.Schema.Value != nil {
+			if property := media.Schema.Value.Properties[field]; property != nil && property.Value != nil {
+				for _, value := range property.Value.Enum {
+					if state, ok := value.(string); ok && !s.terminal[state] {
+						return state
+					}
+				}
+			}
+		}
+	}
+	return "pending"
+}
+
+// poll reads the status of the async operation whose status endpoint is
+// path, advancing it. It returns the item or response at path with the
+// status set, and false if no operation is in progress there.
+func (s *Server) poll(path string) (map[string]interface{}, bool) {
+	job := s.jobs[path]
+	if job == nil {
+		return nil, false
+	}
+
+	job.reads++
+	status := job.pending
+	done := job.reads >= s.opts.AsyncSteps
+	if done {
+		status = job.terminal
+		delete(s.jobs, path)
+	}
+
+	if c, id, item := s.itemAt(path); item != nil {
+		item[job.field] = status
+		body := clone(item).(map[string]interface{})
+		if done && job.remove {
+			c.remove(id)
+		}
+		return body, true
+	}
+
+	body := job.body
+	if body == nil {
+		body = make(map[string]interface{})
+	}
+	body[job.field] = status
+	return clone(body).(map[string]interface{}), true
+}
+
+// itemAt finds the stored item at path.
+func (s *Server) itemAt(path string) (*collection, string, map[string]interface{}) {
+	for _, rt := range s.routes {
+		if rt.kind != itemRoute {
+			continue
+		}
+		params := rt.match(path)
+		if params == nil {
+			continue
+		}
+		parent, _ := splitLast(strings.TrimSuffix(path, "/"))
+		c := s.collection(rt.parent, parent)
+		id := params[rt.idParam]
+		if item := c.items[id]; item != nil {
+			return c, id, item
+		}
+	}
+	return nil, "", nil
+}
+
+// newID returns an unused ID shaped like sample: the next number for
+// numeric IDs, a UUID for UUIDs, else a counter.
+func (c *collection) newID(sample interface{}) string {
+	if _, ok := sample.(float64); ok {
+		highest := 0.0
+		for _, id := range c.ids {
+			if n, err := strconv.ParseFloat(id, 64); err == nil && n > highest {
+				highest = n
+			}
+		}
+		return strconv.FormatFloat(highest+1, 'f', -1, 64)
+	}
+
+	uuid := false
+	if s, ok := sample.(string); ok && len(s) == 36 && strings.Count(s, "-") == 4 {
+		uuid = true
+	}
+	for n := len(c.ids) + 1; ; n++ {
+		id := strconv.Itoa(n)
+		if uuid {
+			id = fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+		}
+		if _, exists := c.items[id]; !exists {
+			return id
+		}
+	}
+}
+
+// typedID returns id as a number when sample is one.
+func typedID(sample interface{}, id string) interface{} {
+	if _, ok := sample.(float64); ok {
+		if n, err := strconv.ParseFloat(id, 64); err == nil {
+			return n
+		}
+	}
+	return id
+}
+
+// itemID finds the ID of item: the field named after the path parameter,
+// else "id". The field defaults to "id" when item has neither.
+func itemID(item map[string]interface{}, idParam string) (string, string, bool) {
+	for _, field := range []string{idParam, "id"} {
+		switch value := item[field].(type) {
+		case string:
+			return field, value, true
+		case float64:
+			return field, strconv.FormatFloat(value, 'f', -1, 64), true
+		}
+	}
+	return "id", "", false
+}
+
+// arrayKey returns the first key of body holding an array.
+func arrayKey(body map[string]interface{}) string {
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := body[key].([]interface{}); ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// inputObject returns the request body, which must be an object if set.
+func inputObject(req *request) (map[string]interface{}, error) {
+	if req.input == nil {
+		return map[string]interface{}{}, nil
+	}
+	input, ok := req.input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object body")
+	}
+	return input, nil
+}
+
+// mergePatch applies patch to target as a JSON merge patch (RFC 7396).
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			targetObject, _ := target[key].(map[string]interface{})
+			target[key] = mergePatch(targetObject, patchObject)
+			continue
+		}
+		target[key] = clone(value)
+	}
+	return target
+}
+
+// expand fills the parameters of a path template from params, then from
+// the fields of body.
+func expand(template string, params map[string]string, body map[string]interface{}) (string, bool) {
+	ok := true
+	path := paramPattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+		if value, found := params[name]; found {
+			return url.PathEscape(value)
+		}
+		switch value := body[name].(type) {
+		case string:
+			return url.PathEscape(value)
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		ok = false
+		return match
+	})
+	return strings.TrimSuffix(path, "/"), ok
+}
+
+// contains reports whether values contains value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
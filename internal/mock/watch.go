@@ -0,0 +1,160 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/gorilla/websocket"
+)
+
+// watchRoute is the watch endpoint of an operation with x-cli-watch.
+type watchRoute struct {
+	route *route
+	op    *openapi.Operation
+}
+
+// isStreamRequest reports whether r asks for an event stream or a
+// WebSocket.
+func isStreamRequest(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// serveWatch streams the resource watched by an operation. The watched
+// resource is the operation's path, with the parameters of the watch
+// endpoint.
+func (s *Server) serveWatch(w http.ResponseWriter, r *http.Request, watch *watchRoute, params map[string]string) {
+	path, ok := expand(watch.op.Path, params, nil)
+	if !ok {
+		path = ""
+	}
+	events := watch.op.CLIWatch.Events
+	if len(events) == 0 {
+		events = []string{"message"}
+	}
+
+	// next returns the next event, and whether it is the last
+	next := func(i int) (string, []byte, bool) {
+		s.mu.Lock()
+		data, done := s.snapshot(path, watch.op)
+		s.mu.Unlock()
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			encoded = []byte("null")
+		}
+		return events[i%len(events)], encoded, done
+	}
+
+	s.logf("%s %s stream (%s)", r.Method, r.URL.Path, watch.op.CLIWatch.Type)
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWebSocket(w, r, next)
+		return
+	}
+	s.serveSSE(w, r, next)
+}
+
+// snapshot returns the watched resource at path: the progress of its
+// async operation, else the stored item, else the operation's example.
+// It reports whether an async operation reached its terminal state.
+// The caller holds s.mu.
+func (s *Server) snapshot(path string, op *openapi.Operation) (interface{}, bool) {
+	if path != "" {
+		if job := s.jobs[path]; job != nil {
+			reads := job.reads
+			body, _ := s.poll(path)
+			return body, reads+1 >= s.opts.AsyncSteps
+		}
+		if _, _, item := s.itemAt(path); item != nil {
+			return clone(item), false
+		}
+	}
+
+	resp, err := exampleResponse(op.Operation, &preference{})
+	if err != nil {
+		return nil, false
+	}
+	return resp.body, false
+}
+
+// serveSSE streams events as Server-Sent Events.
+func (s *Server) serveSSE(w http.ResponseWriter, r *http.Request, next func(int) (string, []byte, bool)) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(s.opts.WatchInterval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		event, data, done := next(i)
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", i+1, event, data); err != nil {
+			return
+		}
+		flusher.Flush()
+		if done {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveWebSocket streams events as WebSocket messages of the form
+// {"event": ..., "data": ...}.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request, next func(int) (string, []byte, bool)) {
+	upgrader := websocket.Upgrader{
+		// The mock serves local clients of any origin
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Reading notices the client closing the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.opts.WatchInterval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		event, data, done := next(i)
+		message, _ := json.Marshal(map[string]interface{}{"event": event, "data": json.RawMessage(data)})
+		if err := conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			return
+		}
+		if done {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "watch ended"))
+			return
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -14,11 +14,21 @@ import (
 // CommandBuilder builds Cobra commands from OpenAPI operations.
 type CommandBuilder struct {
 	runtime *Runtime
+	// baseURL is where requests are sent, the API base URL or that of a
+	// composed spec
+	baseURL string
+	// authName is the authenticator requests use, empty for none
+	authName string
 }
 
-// NewCommandBuilder creates a new CommandBuilder.
+// NewCommandBuilder creates a new CommandBuilder for the API base URL and
+// default auth.
 func NewCommandBuilder(rt *Runtime) *CommandBuilder {
-	return &CommandBuilder{runtime: rt}
+	cb := &CommandBuilder{runtime: rt, baseURL: rt.config.API.BaseURL}
+	if rt.config.Behaviors != nil && rt.config.Behaviors.Auth != nil {
+		cb.authName = "default"
+	}
+	return cb
 }
 
 // BuildCommand builds a Cobra command from an OpenAPI operation.
@@ -183,13 +193,13 @@ func (cb *CommandBuilder) executeOperation(ctx context.Context, op *openapi.Oper
 	}
 
 	// Add authentication
-	if cb.runtime.authManager != nil {
-		token, err := cb.runtime.authManager.GetToken(ctx, "")
+	if cb.authName != "" {
+		token, err := cb.runtime.authManager.GetToken(ctx, cb.authName)
 		if err != nil {
 			return fmt.Errorf("authentication failed: %w", err)
 		}
 		// Get authenticator and add headers
-		auth, err := cb.runtime.authManager.GetAuthenticator("")
+		auth, err := cb.runtime.authManager.GetAuthenticator(cb.authName)
 		if err != nil {
 			return fmt.Errorf("failed to get authenticator: %w", err)
 		}
@@ -214,7 +224,7 @@ func (cb *CommandBuilder) executeOperation(ctx context.Context, op *openapi.Oper
 // buildRequest builds an HTTP request from the operation and flags.
 func (cb *CommandBuilder) buildRequest(ctx context.Context, op *openapi.Operation, cmd *cobra.Command, args []string) (*http.Request, error) {
 	// Build URL
-	url := cb.runtime.config.API.BaseURL + op.Path
+	url := cb.baseURL + op.Path
 
 	// Replace path parameters
 	// TODO: Implement path parameter replacement from flags
@@ -0,0 +1,113 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/CliForge/cliforge/internal/builder"
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+// composedSpec is one of the specs composed into the CLI with api.specs.
+type composedSpec struct {
+	config cli.APISpec
+	spec   *openapi.ParsedSpec
+	err    error
+}
+
+// EncodeEmbeddedSpecs encodes the bundled JSON specs of a CLI composing
+// several, by spec name, as the embedded spec of NewRuntimeWithSpec.
+func EncodeEmbeddedSpecs(specs map[string][]byte) ([]byte, error) {
+	raw := make(map[string]json.RawMessage, len(specs))
+	for name, data := range specs {
+		raw[name] = data
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedded specs: %w", err)
+	}
+	return data, nil
+}
+
+// loadSpecs loads the composed specs concurrently, each cached with its
+// own TTL and falling back to its embedded spec. A spec that fails to
+// load leaves the others usable.
+func (rt *Runtime) loadSpecs(ctx context.Context, loader *openapi.Loader) error {
+	var embedded map[string]json.RawMessage
+	if rt.embeddedSpec != nil {
+		if err := json.Unmarshal(rt.embeddedSpec, &embedded); err != nil {
+			return fmt.Errorf("invalid embedded specs: %w", err)
+		}
+	}
+
+	sources := make([]openapi.SpecSource, len(rt.config.API.Specs))
+	for i, spec := range rt.config.API.Specs {
+		ttl, _ := time.ParseDuration(spec.CacheTTL)
+		sources[i] = openapi.SpecSource{Name: spec.Name, Location: spec.OpenAPIURL, CacheTTL: ttl}
+	}
+
+	rt.composed = make([]*composedSpec, len(sources))
+	for i, result := range loader.LoadAll(ctx, sources, nil) {
+		spec, err := result.Spec, result.Err
+		if data, ok := embedded[result.Source.Name]; ok && err != nil {
+			rt.warnEmbeddedSpec(err)
+			spec, err = loader.LoadFromData(ctx, data)
+			if err != nil {
+				err = fmt.Errorf("failed to load embedded spec %s: %w", result.Source.Name, err)
+			}
+		}
+		rt.composed[i] = &composedSpec{config: rt.config.API.Specs[i], spec: spec, err: err}
+	}
+	return nil
+}
+
+// buildComposedCommands builds the commands of each composed spec under
+// its mount, with the spec's base URL and auth. Specs that failed to load
+// are reported and left out.
+func (rt *Runtime) buildComposedCommands() error {
+	for _, composed := range rt.composed {
+		if composed.err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %v; its commands are unavailable\n", composed.err)
+			continue
+		}
+
+		operations, err := builder.NewBuilder(composed.spec, &builder.BuilderConfig{
+			Tags:        composed.config.Tags,
+			ExcludeTags: composed.config.ExcludeTags,
+		}).Operations()
+		if err != nil {
+			return fmt.Errorf("spec %s: %w", composed.config.Name, err)
+		}
+
+		parent := rt.rootCmd
+		if mount := composed.config.Mount; mount != "" {
+			short := composed.config.Description
+			if short == "" {
+				short = composed.spec.GetInfo().Title
+			}
+			parent = &cobra.Command{Use: mount, Short: short}
+			rt.rootCmd.AddCommand(parent)
+		}
+
+		cb := NewCommandBuilder(rt)
+		if composed.config.BaseURL != "" {
+			cb.baseURL = composed.config.BaseURL
+		}
+		if composed.config.Auth != nil {
+			cb.authName = composed.config.Name
+		}
+		for _, op := range operations {
+			cmd, err := cb.BuildCommand(op)
+			if err != nil {
+				return fmt.Errorf("failed to build command for %s in spec %s: %w", op.OperationID, composed.config.Name, err)
+			}
+			parent.AddCommand(cmd)
+		}
+	}
+	return nil
+}
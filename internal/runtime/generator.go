@@ -12,6 +12,8 @@ import (
 // Generator generates runtime code for CLIs.
 type Generator struct {
 	config *cli.Config
+	// EmbedSpec embeds spec_embedded.json as the fallback spec
+	EmbedSpec bool
 }
 
 // NewGenerator creates a new Generator.
@@ -28,6 +30,7 @@ func (g *Generator) GenerateMain(configData []byte) (string, error) {
 		"Version":     g.config.Metadata.Version,
 		"HasBranding": g.config.Branding != nil && g.config.Branding.ASCIIArt != "",
 		"ASCIIArt":    "",
+		"EmbedSpec":   g.EmbedSpec,
 	}
 
 	if g.config.Branding != nil && g.config.Branding.ASCIIArt != "" {
@@ -63,7 +66,10 @@ import (
 
 //go:embed config_embedded.yaml
 var embeddedConfig []byte
-
+{{if .EmbedSpec}}
+//go:embed spec_embedded.json
+var embeddedSpec []byte
+{{end}}
 var (
 	version = "{{.Version}}"
 	debug   = "false"
@@ -77,7 +83,7 @@ func main() {
 	{{end}}
 
 	// Initialize runtime
-	rt, err := runtime.NewRuntime(embeddedConfig, version, debug == "true")
+	{{if .EmbedSpec}}rt, err := runtime.NewRuntimeWithSpec(embeddedConfig, embeddedSpec, version, debug == "true"){{else}}rt, err := runtime.NewRuntime(embeddedConfig, version, debug == "true"){{end}}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize: %v\n", err)
 		os.Exit(1)
@@ -34,6 +34,14 @@
 //	    rt.Execute()
 //	}
 //
+// Binaries built with an embedded spec use NewRuntimeWithSpec, and fall
+// back to the embedded spec when the configured one cannot be loaded.
+//
+// CLIs composing several specs with api.specs load each on its own and
+// mount its commands under the spec's mount, with the spec's base URL and
+// auth. A spec that fails to load, with no embedded spec to fall back to,
+// leaves the others usable.
+//
 // # Subsystems
 //
 //   - AuthManager: Handles authentication flows and token storage
@@ -50,6 +58,7 @@
 //	--verbose, -v    Enable verbose output
 //	--debug          Enable debug mode
 //	--no-color       Disable colored output
+//	--no-pager       Write long output without a pager
 //	--config         Path to config file
 //	--profile        Named profile to use
 //
@@ -65,6 +74,7 @@
 //	cache        Manage cache
 //	update       Check for updates
 //	auth         Manage authentication
+//	plugin       Manage plugins
 //
 // The runtime package provides the complete scaffolding needed for
 // production-ready CLI applications generated from OpenAPI specs.
@@ -72,12 +82,15 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/CliForge/cliforge/pkg/auth"
 	"github.com/CliForge/cliforge/pkg/cache"
 	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/cli/builtin"
+	"github.com/CliForge/cliforge/pkg/config"
 	"github.com/CliForge/cliforge/pkg/openapi"
 	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/CliForge/cliforge/pkg/state"
@@ -95,10 +108,21 @@ type Runtime struct {
 	outputManager *output.Manager
 	stateManager  *state.Manager
 	specCache     *cache.SpecCache
+	spec          *openapi.ParsedSpec
+	composed      []*composedSpec
+	embeddedSpec  []byte
 }
 
 // NewRuntime creates a new Runtime instance from embedded configuration.
 func NewRuntime(embeddedConfig []byte, version string, debug bool) (*Runtime, error) {
+	return NewRuntimeWithSpec(embeddedConfig, nil, version, debug)
+}
+
+// NewRuntimeWithSpec creates a new Runtime instance from embedded
+// configuration and a bundled spec, used when the configured spec cannot
+// be loaded. CLIs composing several specs with api.specs embed them
+// encoded with EncodeEmbeddedSpecs.
+func NewRuntimeWithSpec(embeddedConfig, embeddedSpec []byte, version string, debug bool) (*Runtime, error) {
 	// Parse embedded configuration
 	var config cli.Config
 	if err := yaml.Unmarshal(embeddedConfig, &config); err != nil {
@@ -109,9 +133,10 @@ func NewRuntime(embeddedConfig []byte, version string, debug bool) (*Runtime, er
 	config.Metadata.Debug = debug
 
 	rt := &Runtime{
-		config:  &config,
-		version: version,
-		debug:   debug,
+		config:       &config,
+		version:      version,
+		debug:        debug,
+		embeddedSpec: embeddedSpec,
 	}
 
 	// Initialize all subsystems
@@ -131,8 +156,11 @@ func NewRuntime(embeddedConfig []byte, version string, debug bool) (*Runtime, er
 func (rt *Runtime) initializeSubsystems() error {
 	ctx := context.Background()
 
-	// Initialize output manager
+	// Initialize output manager, paging long output unless turned off
 	rt.outputManager = output.NewManager()
+	if rt.pagingEnabled() {
+		rt.outputManager.SetPager(output.NewPager())
+	}
 
 	// Initialize state manager
 	stateDir := fmt.Sprintf("%s/.%s/state", os.Getenv("HOME"), rt.config.Metadata.Name)
@@ -150,16 +178,27 @@ func (rt *Runtime) initializeSubsystems() error {
 		}
 	}
 
-	// Initialize auth manager
+	// Initialize auth manager, with an authenticator named after each
+	// composed spec with its own auth
+	authBehaviors := make(map[string]*cli.AuthBehavior)
 	if rt.config.Behaviors != nil && rt.config.Behaviors.Auth != nil {
+		authBehaviors["default"] = rt.config.Behaviors.Auth
+	}
+	for _, spec := range rt.config.API.Specs {
+		if spec.Auth != nil {
+			authBehaviors[spec.Name] = spec.Auth
+		}
+	}
+	if len(authBehaviors) > 0 {
 		rt.authManager = auth.NewManager(rt.config.Metadata.Name)
-		authConfig := convertAuthConfig(rt.config.Behaviors.Auth)
-		authenticator, err := createAuthenticator(authConfig)
+	}
+	for name, behavior := range authBehaviors {
+		authenticator, err := createAuthenticator(auth.ConfigFromBehavior(behavior))
 		if err != nil {
-			return fmt.Errorf("failed to create authenticator: %w", err)
+			return fmt.Errorf("failed to create authenticator %s: %w", name, err)
 		}
-		if err := rt.authManager.RegisterAuthenticator("default", authenticator); err != nil {
-			return fmt.Errorf("failed to register authenticator: %w", err)
+		if err := rt.authManager.RegisterAuthenticator(name, authenticator); err != nil {
+			return fmt.Errorf("failed to register authenticator %s: %w", name, err)
 		}
 	}
 
@@ -171,27 +210,49 @@ func (rt *Runtime) initializeSubsystems() error {
 	return nil
 }
 
-// loadOpenAPISpec loads the OpenAPI specification.
+// loadOpenAPISpec loads the OpenAPI specification, falling back to the
+// embedded spec.
 func (rt *Runtime) loadOpenAPISpec(ctx context.Context) error {
 	loader := openapi.NewLoader(nil)
+	if signing := rt.config.API.Signing; signing != nil {
+		verifier, err := openapi.NewSpecVerifier(signing.PublicKeys)
+		if err != nil {
+			return fmt.Errorf("invalid spec signing keys: %w", err)
+		}
+		loader.Verifier = verifier
+	}
+
+	// Composed specs are loaded on their own
+	if len(rt.config.API.Specs) > 0 {
+		return rt.loadSpecs(ctx, loader)
+	}
 
 	// Load spec from configured URL or file
-	_, err := loader.LoadFromURL(ctx, rt.config.API.OpenAPIURL, nil)
+	spec, err := loader.Load(ctx, rt.config.API.OpenAPIURL, nil)
+	if err != nil && rt.embeddedSpec != nil {
+		rt.warnEmbeddedSpec(err)
+		spec, err = loader.LoadFromData(ctx, rt.embeddedSpec)
+	}
 	if err != nil {
-		// Try as file path
-		_, err = loader.LoadFromFile(ctx, rt.config.API.OpenAPIURL)
-		if err != nil {
-			return fmt.Errorf("failed to load OpenAPI spec: %w", err)
-		}
+		return fmt.Errorf("failed to load OpenAPI spec: %w", err)
 	}
 
+	rt.spec = spec
 	return nil
 }
 
+// warnEmbeddedSpec reports falling back to an embedded spec because of
+// err, in debug mode or when a signature was refused.
+func (rt *Runtime) warnEmbeddedSpec(err error) {
+	// A refused signature is worth knowing about even without debug
+	refused := errors.Is(err, openapi.ErrSpecUnsigned) || errors.Is(err, openapi.ErrSignatureInvalid)
+	if rt.debug || refused {
+		fmt.Fprintf(os.Stderr, "Warning: using the embedded spec: %v\n", err)
+	}
+}
+
 // buildCommandTree builds the Cobra command tree from the OpenAPI spec.
 func (rt *Runtime) buildCommandTree() error {
-	ctx := context.Background()
-
 	// Create root command
 	rt.rootCmd = &cobra.Command{
 		Use:     rt.config.Metadata.Name,
@@ -206,18 +267,33 @@ func (rt *Runtime) buildCommandTree() error {
 
 	// Add global flags
 	rt.addGlobalFlags()
+	builtin.PageHelp(rt.rootCmd, rt.outputManager.GetPager())
 
 	// Add built-in commands
 	rt.addBuiltinCommands()
 
-	// Load spec and build API commands
-	if err := rt.buildAPICommands(ctx); err != nil {
+	// Build API commands from the loaded spec
+	if err := rt.buildAPICommands(); err != nil {
 		return fmt.Errorf("failed to build API commands: %w", err)
 	}
 
 	return nil
 }
 
+// pagingEnabled resolves whether long output is paged from the embedded
+// config merged with the user's preferences and environment, falling back
+// to the embedded config alone when they cannot be merged.
+func (rt *Runtime) pagingEnabled() bool {
+	merged := rt.config
+	if loaded, err := config.NewLoader(rt.config.Metadata.Name, nil, "").Merge(rt.config); err == nil {
+		merged = loaded.Final
+	}
+	if merged.Defaults == nil {
+		return config.PagingEnabled(nil)
+	}
+	return config.PagingEnabled(merged.Defaults.Output)
+}
+
 // addGlobalFlags adds global flags to the root command.
 func (rt *Runtime) addGlobalFlags() {
 	if rt.config.Behaviors == nil || rt.config.Behaviors.GlobalFlags == nil {
@@ -241,6 +317,10 @@ func (rt *Runtime) addGlobalFlags() {
 	if flags.NoColor != nil && flags.NoColor.Enabled {
 		rt.rootCmd.PersistentFlags().Bool("no-color", false, flags.NoColor.Description)
 	}
+
+	if flags.NoPager != nil && flags.NoPager.Enabled {
+		rt.rootCmd.PersistentFlags().Bool("no-pager", false, flags.NoPager.Description)
+	}
 }
 
 // addBuiltinCommands adds built-in commands to the CLI.
@@ -251,27 +331,27 @@ func (rt *Runtime) addBuiltinCommands() {
 
 	cmds := rt.config.Behaviors.BuiltinCommands
 
-	// Built-in commands - placeholder for future implementation
+	if cmds.Plugin != nil && cmds.Plugin.Enabled {
+		rt.rootCmd.AddCommand(builtin.NewPluginCommand(&builtin.PluginOptions{
+			CLIName: rt.config.Metadata.Name,
+			Config:  rt.config.Plugins,
+			Output:  os.Stdout,
+		}))
+	}
+
+	// Remaining built-in commands - placeholder for future implementation
 	// Version, help, completion, cache, update, context, history commands
 	// Will be implemented in future iterations
-	_ = cmds // Prevent unused variable warning
 }
 
 // buildAPICommands builds commands from the OpenAPI specification.
-func (rt *Runtime) buildAPICommands(ctx context.Context) error {
-	// Load OpenAPI spec
-	loader := openapi.NewLoader(nil)
-	spec, err := loader.LoadFromURL(ctx, rt.config.API.OpenAPIURL, nil)
-	if err != nil {
-		// Try as file path
-		spec, err = loader.LoadFromFile(ctx, rt.config.API.OpenAPIURL)
-		if err != nil {
-			return fmt.Errorf("failed to load spec: %w", err)
-		}
+func (rt *Runtime) buildAPICommands() error {
+	if rt.composed != nil {
+		return rt.buildComposedCommands()
 	}
 
 	// Get operations from spec
-	operations, err := spec.GetOperations()
+	operations, err := rt.spec.GetOperations()
 	if err != nil {
 		return fmt.Errorf("failed to get operations: %w", err)
 	}
@@ -300,47 +380,6 @@ func (rt *Runtime) Execute() error {
 	return rt.rootCmd.Execute()
 }
 
-// convertAuthConfig converts CLI auth config to auth package config.
-func convertAuthConfig(authBehavior *cli.AuthBehavior) *auth.Config {
-	cfg := &auth.Config{}
-
-	switch authBehavior.Type {
-	case "api_key":
-		cfg.Type = auth.AuthTypeAPIKey
-		if authBehavior.APIKey != nil {
-			cfg.APIKey = &auth.APIKeyConfig{
-				Name:     authBehavior.APIKey.Header,
-				Location: auth.APIKeyLocationHeader,
-				EnvVar:   authBehavior.APIKey.EnvVar,
-			}
-		}
-	case "oauth2":
-		cfg.Type = auth.AuthTypeOAuth2
-		if authBehavior.OAuth2 != nil {
-			cfg.OAuth2 = &auth.OAuth2Config{
-				ClientID:     authBehavior.OAuth2.ClientID,
-				ClientSecret: authBehavior.OAuth2.ClientSecret,
-				AuthURL:      authBehavior.OAuth2.AuthURL,
-				TokenURL:     authBehavior.OAuth2.TokenURL,
-				Scopes:       authBehavior.OAuth2.Scopes,
-				Flow:         auth.OAuth2FlowAuthorizationCode,
-			}
-		}
-	case "basic":
-		cfg.Type = auth.AuthTypeBasic
-		if authBehavior.Basic != nil {
-			cfg.Basic = &auth.BasicConfig{
-				EnvUsername: authBehavior.Basic.UsernameEnv,
-				EnvPassword: authBehavior.Basic.PasswordEnv,
-			}
-		}
-	default:
-		cfg.Type = auth.AuthTypeNone
-	}
-
-	return cfg
-}
-
 // createAuthenticator creates an authenticator from config.
 func createAuthenticator(config *auth.Config) (auth.Authenticator, error) {
 	switch config.Type {
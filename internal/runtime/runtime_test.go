@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+)
+
+const embeddedTestSpec = `{"openapi": "3.0.0", "info": {"title": "Embedded", "version": "1.0.0"}, "paths": {}}`
+
+const fetchedTestSpec = `{"openapi": "3.0.0", "info": {"title": "Fetched", "version": "2.0.0"}, "paths": {}}`
+
+func TestRuntime_LoadOpenAPISpec_EmbeddedFallback(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing := &cli.SpecSigning{PublicKeys: []string{base64.StdEncoding.EncodeToString(publicKey)}}
+
+	// Serves the spec with a signature no trusted key made
+	forged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Spec-Signature", base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize)))
+		_, _ = w.Write([]byte(fetchedTestSpec))
+	}))
+	defer forged.Close()
+
+	// A closed server's address refuses connections
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	tests := []struct {
+		name string
+		api  cli.API
+	}{
+		{
+			name: "unreachable URL",
+			api:  cli.API{OpenAPIURL: unreachable.URL + "/openapi.json"},
+		},
+		{
+			name: "invalid signature",
+			api:  cli.API{OpenAPIURL: forged.URL + "/openapi.json", Signing: signing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &Runtime{
+				config:       &cli.Config{API: tt.api},
+				embeddedSpec: []byte(embeddedTestSpec),
+			}
+			if err := rt.loadOpenAPISpec(context.Background()); err != nil {
+				t.Fatalf("loadOpenAPISpec() error = %v", err)
+			}
+			if title := rt.spec.GetInfo().Title; title != "Embedded" {
+				t.Errorf("Expected the embedded spec, got %q", title)
+			}
+		})
+	}
+
+	// Without an embedded spec the failure is reported
+	rt := &Runtime{config: &cli.Config{API: tests[1].api}}
+	if err := rt.loadOpenAPISpec(context.Background()); err == nil {
+		t.Error("Expected an error without an embedded spec")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/CliForge/cliforge/pkg/auth/storage"
+	"github.com/CliForge/cliforge/pkg/cli"
 )
 
 // Manager coordinates authentication providers and token storage.
@@ -310,3 +311,44 @@ func (m *Manager) ListStorages() []string {
 	}
 	return names
 }
+
+// ConfigFromBehavior converts the auth section of a CLI config.
+func ConfigFromBehavior(authBehavior *cli.AuthBehavior) *Config {
+	cfg := &Config{}
+
+	switch authBehavior.Type {
+	case "api_key":
+		cfg.Type = AuthTypeAPIKey
+		if authBehavior.APIKey != nil {
+			cfg.APIKey = &APIKeyConfig{
+				Name:     authBehavior.APIKey.Header,
+				Location: APIKeyLocationHeader,
+				EnvVar:   authBehavior.APIKey.EnvVar,
+			}
+		}
+	case "oauth2":
+		cfg.Type = AuthTypeOAuth2
+		if authBehavior.OAuth2 != nil {
+			cfg.OAuth2 = &OAuth2Config{
+				ClientID:     authBehavior.OAuth2.ClientID,
+				ClientSecret: authBehavior.OAuth2.ClientSecret,
+				AuthURL:      authBehavior.OAuth2.AuthURL,
+				TokenURL:     authBehavior.OAuth2.TokenURL,
+				Scopes:       authBehavior.OAuth2.Scopes,
+				Flow:         OAuth2FlowAuthorizationCode,
+			}
+		}
+	case "basic":
+		cfg.Type = AuthTypeBasic
+		if authBehavior.Basic != nil {
+			cfg.Basic = &BasicConfig{
+				EnvUsername: authBehavior.Basic.UsernameEnv,
+				EnvPassword: authBehavior.Basic.PasswordEnv,
+			}
+		}
+	default:
+		cfg.Type = AuthTypeNone
+	}
+
+	return cfg
+}
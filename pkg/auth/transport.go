@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Transport adds the credentials of a Manager's authenticators to requests
+// under the base URL of an API. Requests to other URLs, including
+// redirects away from an API, are sent without them.
+type Transport struct {
+	// Base sends the requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Manager provides the credentials.
+	Manager *Manager
+
+	// APIs maps the base URL of each API to the authenticator its
+	// requests use. An empty name uses the default authenticator.
+	APIs map[string]string
+}
+
+// NewClient returns a copy of client that authenticates requests to the
+// APIs, mapped as in Transport.APIs. A nil client starts from
+// http.DefaultClient.
+func NewClient(client *http.Client, manager *Manager, apis map[string]string) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	authenticated := *client
+	authenticated.Transport = &Transport{Base: client.Transport, Manager: manager, APIs: apis}
+	return &authenticated
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	authName, ok := t.API(req.URL)
+	if !ok || t.Manager == nil {
+		return base.RoundTrip(req)
+	}
+
+	token, err := t.Manager.GetToken(req.Context(), authName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply authentication: %w", err)
+	}
+	authenticator, err := t.Manager.GetAuthenticator(authName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply authentication: %w", err)
+	}
+
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	for key, value := range authenticator.GetHeaders(token) {
+		req.Header.Set(key, value)
+	}
+	return base.RoundTrip(req)
+}
+
+// API returns the authenticator of the API target is under, preferring the
+// longest base URL, and false if it is under none.
+func (t *Transport) API(target *url.URL) (authName string, ok bool) {
+	longest := -1
+	for baseURL, name := range t.APIs {
+		base, err := url.Parse(baseURL)
+		if err != nil || !underBaseURL(target, base) {
+			continue
+		}
+		if len(base.Path) > longest {
+			authName, ok, longest = name, true, len(base.Path)
+		}
+	}
+	return authName, ok
+}
+
+// underBaseURL reports whether target is base or beneath its path, on the
+// same scheme and host.
+func underBaseURL(target, base *url.URL) bool {
+	if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) {
+		return false
+	}
+	prefix := strings.TrimSuffix(base.Path, "/")
+	return target.Path == prefix || strings.HasPrefix(target.Path, prefix+"/")
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/away" {
+			http.Redirect(w, r, "/other", http.StatusFound)
+			return
+		}
+		_, _ = io.WriteString(w, r.Header.Get("X-API-Key")+r.Header.Get("X-Admin-Key"))
+	}))
+	defer server.Close()
+
+	manager := NewManager("test-cli")
+	for name, key := range map[string]string{"default": "user-key", "admin": "admin-key"} {
+		header := "X-API-Key"
+		if name == "admin" {
+			header = "X-Admin-Key"
+		}
+		auth, err := NewAPIKeyAuth(&APIKeyConfig{Key: key, Name: header, Location: APIKeyLocationHeader})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := manager.RegisterAuthenticator(name, auth); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := manager.SetDefault("default"); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(server.Client(), manager, map[string]string{
+		server.URL + "/api":       "",
+		server.URL + "/api/admin": "admin",
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api", want: "user-key"},
+		{path: "/api/users", want: "user-key"},
+		{path: "/api/admin/users", want: "admin-key"},
+		{path: "/apiary", want: ""},
+		{path: "/other", want: ""},
+		{path: "/api/away", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := client.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("Expected credentials %q, got %q", tt.want, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// LoaderCache adapts a SpecCache to openapi.SpecCache, so specs fetched
+// by an openapi.Loader are cached on disk.
+type LoaderCache struct {
+	Cache *SpecCache
+}
+
+// Get retrieves a cached spec, returning ErrCacheMiss if there is none.
+func (l *LoaderCache) Get(ctx context.Context, key string) (*openapi.CachedSpec, error) {
+	cached, err := l.Cache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi.CachedSpec{
+		Data:      cached.Data,
+		ETag:      cached.ETag,
+		FetchedAt: cached.FetchedAt,
+		URL:       cached.URL,
+		Signature: cached.Signature,
+	}, nil
+}
+
+// Set stores a spec in the cache.
+func (l *LoaderCache) Set(ctx context.Context, key string, spec *openapi.CachedSpec) error {
+	return l.Cache.Set(ctx, key, &CachedSpec{
+		Data:      spec.Data,
+		ETag:      spec.ETag,
+		FetchedAt: spec.FetchedAt,
+		URL:       spec.URL,
+		Signature: spec.Signature,
+	})
+}
+
+// Invalidate removes a cached spec.
+func (l *LoaderCache) Invalidate(ctx context.Context, key string) error {
+	return l.Cache.Invalidate(ctx, key)
+}
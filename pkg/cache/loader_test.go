@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+func TestLoaderCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Cached", "version": "1.0.0"}, "paths": {}}`))
+	}))
+	defer server.Close()
+
+	cache := &SpecCache{BaseDir: t.TempDir(), AppName: "test", DefaultTTL: 5 * time.Minute}
+	ctx := context.Background()
+
+	// A fresh loader finds the spec the first one cached on disk
+	for i := 0; i < 2; i++ {
+		loader := openapi.NewLoader(&LoaderCache{Cache: cache})
+		loader.Parser.DisableValidation = true
+		if _, err := loader.LoadFromURL(ctx, server.URL, &openapi.LoadOptions{SkipConditional: true}); err != nil {
+			t.Fatalf("LoadFromURL() error = %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	cached, err := cache.Get(ctx, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if cached.ETag != `"v1"` || cached.URL != server.URL {
+		t.Errorf("Expected the ETag and URL to be cached, got %+v", cached)
+	}
+}
@@ -76,6 +76,9 @@ type CachedSpec struct {
 	URL string `json:"url"`
 	// Version is the spec version
 	Version string `json:"version"`
+	// Signature is the verified signature of Data, so the cached copy can
+	// be verified again when read
+	Signature string `json:"signature,omitempty"`
 }
 
 // NewSpecCache creates a new XDG-compliant spec cache.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/CliForge/cliforge/pkg/cli"
 	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +21,9 @@ type ChangelogOptions struct {
 	BinaryChangelogFunc func() ([]ChangelogEntry, error)
 	APIChangelogFunc    func() ([]openapi.ChangelogEntry, error)
 	Output              io.Writer
+	// Pager pages long changelogs on a terminal. Nil writes them
+	// straight out.
+	Pager *output.Pager
 }
 
 // ChangelogEntry represents a single changelog entry.
@@ -60,7 +64,15 @@ Examples:
   changelog --since v2.0.0      # Show changes since version
   changelog --limit 5           # Show last 5 versions`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runChangelog(opts, binaryOnly, apiOnly, sinceVersion, limit, outputFormat)
+			out := pagedOutput(cmd, opts.Pager, opts.Output)
+			paged := *opts
+			paged.Output = out
+
+			err := runChangelog(&paged, binaryOnly, apiOnly, sinceVersion, limit, outputFormat)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			return err
 		},
 	}
 
@@ -23,6 +23,7 @@ type GlobalFlags struct {
 	Quiet   bool
 	Debug   bool
 	NoColor bool
+	NoPager bool
 
 	// HTTP client settings
 	Timeout time.Duration
@@ -127,6 +128,12 @@ func (fm *FlagManager) addOutputFlags(pf *pflag.FlagSet) {
 			pf.BoolVar(&fm.flags.NoColor, "no-color", false,
 				"Disable colored output")
 		}
+
+		// No pager flag
+		if fm.config.Behaviors.GlobalFlags.NoPager != nil && fm.config.Behaviors.GlobalFlags.NoPager.Enabled {
+			pf.BoolVar(&fm.flags.NoPager, "no-pager", false,
+				"Write long output straight to the terminal instead of a pager")
+		}
 	}
 }
 
@@ -274,6 +281,18 @@ func (fm *FlagManager) ApplyToConfig(config *cli.Config) {
 		config.Defaults.Output.Color = "never"
 	}
 
+	// Apply no-pager flag
+	if fm.flags.NoPager {
+		if config.Defaults == nil {
+			config.Defaults = &cli.Defaults{}
+		}
+		if config.Defaults.Output == nil {
+			config.Defaults.Output = &cli.DefaultsOutput{}
+		}
+		paging := false
+		config.Defaults.Output.Paging = &paging
+	}
+
 	// Apply HTTP settings
 	if fm.flags.Timeout > 0 {
 		if config.Defaults == nil {
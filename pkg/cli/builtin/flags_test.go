@@ -196,11 +196,15 @@ func TestFlagManager_ApplyToConfig(t *testing.T) {
 	// Set some flags
 	fm.flags.Output = "yaml"
 	fm.flags.NoColor = true
+	fm.flags.NoPager = true
 	fm.flags.Timeout = 60 * time.Second
 	fm.flags.Retry = 5
 	fm.flags.NoCache = true
 
-	targetConfig := &cli.Config{}
+	paging := true
+	targetConfig := &cli.Config{
+		Defaults: &cli.Defaults{Output: &cli.DefaultsOutput{Paging: &paging}},
+	}
 	fm.ApplyToConfig(targetConfig)
 
 	// Verify output format was applied
@@ -217,6 +221,11 @@ func TestFlagManager_ApplyToConfig(t *testing.T) {
 		t.Errorf("expected color 'never', got %q", targetConfig.Defaults.Output.Color)
 	}
 
+	// Verify no-pager was applied
+	if paging := targetConfig.Defaults.Output.Paging; paging == nil || *paging {
+		t.Error("expected paging to be disabled")
+	}
+
 	// Verify HTTP settings
 	if targetConfig.Defaults.HTTP == nil {
 		t.Fatal("expected defaults.http to be set")
@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +14,8 @@ type HelpOptions struct {
 	ShowExamples bool
 	ShowAliases  bool
 	Output       io.Writer
+	// Pager pages long help on a terminal. Nil writes it straight out.
+	Pager *output.Pager
 }
 
 // NewHelpCommand creates a new help command.
@@ -27,19 +30,37 @@ Simply type ` + rootCmd.Name() + ` help [path to command] for full details.`,
 			return getCommandNames(rootCmd, toComplete), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// If no args, show root help
-			if len(args) == 0 {
-				return rootCmd.Help()
+			// Find the command, the root without args
+			targetCmd := rootCmd
+			if len(args) > 0 {
+				var err error
+				targetCmd, _, err = rootCmd.Find(args)
+				if err != nil {
+					return fmt.Errorf("unknown command %q: %w", strings.Join(args, " "), err)
+				}
 			}
 
-			// Find the command
-			targetCmd, _, err := rootCmd.Find(args)
-			if err != nil {
-				return fmt.Errorf("unknown command %q: %w", strings.Join(args, " "), err)
+			// The help and the sections added to it are paged together
+			w := opts.Output
+			if w == nil {
+				w = cmd.OutOrStdout()
 			}
-
-			// Show help for the target command
-			return showCustomHelp(targetCmd, opts)
+			out := pagedOutput(cmd, opts.Pager, w)
+			paged := *opts
+			paged.Output = out
+
+			var err error
+			withOutput(targetCmd, out, func() {
+				if len(args) == 0 {
+					err = targetCmd.Help()
+				} else {
+					err = showCustomHelp(targetCmd, &paged)
+				}
+			})
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			return err
 		},
 	}
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/CliForge/cliforge/pkg/output"
 	"github.com/spf13/cobra"
 )
 
@@ -269,3 +270,25 @@ func TestHelpCommand_UnknownCommand(t *testing.T) {
 		t.Logf("Help returned: %v", err)
 	}
 }
+
+func TestPageHelp(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "testcli", Short: "A test CLI"}
+	rootCmd.PersistentFlags().Bool("no-pager", false, "")
+	subCmd := &cobra.Command{Use: "subcommand", Short: "A test subcommand", Run: func(*cobra.Command, []string) {}}
+	rootCmd.AddCommand(subCmd)
+	PageHelp(rootCmd, output.NewPager())
+
+	// Help written somewhere other than a terminal is not paged
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"subcommand", "--help", "--no-pager"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "A test subcommand") {
+		t.Errorf("expected subcommand help, got: %s", out.String())
+	}
+	if subCmd.OutOrStdout() != &out {
+		t.Error("expected the output of the subcommand to be restored")
+	}
+}
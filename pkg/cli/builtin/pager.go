@@ -0,0 +1,40 @@
+package builtin
+
+import (
+	"io"
+
+	"github.com/CliForge/cliforge/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// PageHelp pages the help of root and its subcommands, for --help as well
+// as the help command.
+func PageHelp(root *cobra.Command, pager *output.Pager) {
+	help := root.HelpFunc()
+	root.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		out := pagedOutput(cmd, pager, cmd.OutOrStdout())
+		withOutput(cmd, out, func() {
+			help(cmd, args)
+		})
+		_ = out.Close()
+	})
+}
+
+// pagedOutput returns the writer long output of cmd goes to: w through
+// the pager, unless --no-pager or --quiet turn paging off.
+func pagedOutput(cmd *cobra.Command, pager *output.Pager, w io.Writer) io.WriteCloser {
+	for _, name := range []string{"no-pager", "quiet"} {
+		if flag := cmd.Flag(name); flag != nil && flag.Value.String() == "true" {
+			pager = nil
+		}
+	}
+	return pager.Start(w)
+}
+
+// withOutput runs f with the output of cmd written to w.
+func withOutput(cmd *cobra.Command, w io.Writer, f func()) {
+	previous := cmd.OutOrStdout()
+	cmd.SetOut(w)
+	defer cmd.SetOut(previous)
+	f()
+}
@@ -0,0 +1,691 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+)
+
+// PluginOptions configures the plugin command behavior.
+type PluginOptions struct {
+	CLIName string
+
+	// Config is the plugins section of the embedded configuration,
+	// holding the trusted publisher keys and the plugin policy.
+	Config *cli.Plugins
+
+	// Approver asks the user to approve plugin permissions. Defaults to
+	// prompting on stderr.
+	Approver plugin.PermissionApprover
+
+	Output io.Writer
+}
+
+// PluginDetails is the JSON output of plugin info.
+type PluginDetails struct {
+	*plugin.InstallRecord
+	Manifest *plugin.PluginManifest `json:"manifest"`
+}
+
+// NewPluginCommand creates a new plugin command group.
+func NewPluginCommand(opts *PluginOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage plugins",
+		Long: `Install and manage plugins.
+
+Plugins are installed from a directory, a .tar.gz or .zip archive, or
+the URL of an archive. Archives are checked against a SHA-256 checksum
+and the signatures of trusted publishers when available, and plugin
+permissions are approved at install time. Versions are kept side by
+side so an upgrade can be rolled back.
+
+Available subcommands:
+  install  - Install a plugin
+  list     - List installed plugins
+  info     - Show plugin details
+  upgrade  - Upgrade a plugin or switch versions
+  remove   - Remove a plugin
+  verify   - Check installed plugins for modifications
+  test     - Run a plugin through the conformance suite
+  audit    - Show permission approvals, denials and revocations`,
+	}
+
+	cmd.AddCommand(newPluginInstallCommand(opts))
+	cmd.AddCommand(newPluginListCommand(opts))
+	cmd.AddCommand(newPluginInfoCommand(opts))
+	cmd.AddCommand(newPluginUpgradeCommand(opts))
+	cmd.AddCommand(newPluginRemoveCommand(opts))
+	cmd.AddCommand(newPluginVerifyCommand(opts))
+	cmd.AddCommand(newPluginTestCommand(opts))
+	cmd.AddCommand(newPluginAuditCommand(opts))
+
+	return cmd
+}
+
+// newPluginInstallCommand creates the plugin install subcommand.
+func newPluginInstallCommand(opts *PluginOptions) *cobra.Command {
+	var installOpts plugin.InstallOptions
+
+	cmd := &cobra.Command{
+		Use:   "install <path|archive|url>",
+		Short: "Install a plugin",
+		Long: `Install a plugin from a directory, a .tar.gz, .tgz or .zip archive, or
+the http(s) URL of an archive.
+
+The archive's SHA-256 digest is checked against --sha256, or against
+<archive>.sha256 if it exists. Its Ed25519 signature is read from
+--signature, or <archive>.sig if it exists, and checked against the
+trusted publishers.
+
+Examples:
+  plugin install ./greeter
+  plugin install greeter-1.0.0.tar.gz --sha256 9f86d0...
+  plugin install https://plugins.example.com/greeter-1.0.0.zip`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := newPluginRegistry(opts)
+			if err != nil {
+				return err
+			}
+
+			result, err := registry.Install(cmd.Context(), args[0], installOpts)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(opts.Output, "✓ Installed %s %s (%s)\n", result.Manifest.Name, result.Manifest.Version, describeSigner(result.Version))
+			return nil
+		},
+	}
+
+	addInstallFlags(cmd, &installOpts)
+	cmd.Flags().BoolVar(&installOpts.Force, "force", false, "Reinstall an installed plugin")
+
+	return cmd
+}
+
+// newPluginListCommand creates the plugin list subcommand.
+func newPluginListCommand(opts *PluginOptions) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List installed plugins",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginList(opts, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table|json)")
+
+	return cmd
+}
+
+// newPluginInfoCommand creates the plugin info subcommand.
+func newPluginInfoCommand(opts *PluginOptions) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "info <name>",
+		Short: "Show plugin details",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginInfo(opts, args[0], outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// newPluginUpgradeCommand creates the plugin upgrade subcommand.
+func newPluginUpgradeCommand(opts *PluginOptions) *cobra.Command {
+	var installOpts plugin.InstallOptions
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "upgrade <name> [path|archive|url]",
+		Short: "Upgrade a plugin or switch versions",
+		Long: `Install a newer version of a plugin and make it active. Without a
+source, the plugin is upgraded from where it was installed from.
+
+Permissions the new version requests beyond those already approved
+must be approved. The previous version is kept; use --version to
+switch back to it.
+
+Examples:
+  plugin upgrade greeter greeter-1.1.0.tar.gz
+  plugin upgrade greeter
+  plugin upgrade greeter --version 1.0.0`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := newPluginRegistry(opts)
+			if err != nil {
+				return err
+			}
+			name := args[0]
+
+			if version != "" {
+				if len(args) > 1 {
+					return fmt.Errorf("--version cannot be used with a source")
+				}
+				if err := registry.Activate(name, version); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(opts.Output, "✓ Activated %s %s\n", name, version)
+				return nil
+			}
+
+			var source string
+			if len(args) > 1 {
+				source = args[1]
+			}
+			result, err := registry.Upgrade(cmd.Context(), name, source, installOpts)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(opts.Output, "✓ Upgraded %s %s -> %s (%s)\n", name, result.Previous, result.Manifest.Version, describeSigner(result.Version))
+			return nil
+		},
+	}
+
+	addInstallFlags(cmd, &installOpts)
+	cmd.Flags().StringVar(&version, "version", "", "Activate an installed version")
+	cmd.Flags().BoolVar(&installOpts.Force, "force", false, "Allow reinstalls and downgrades")
+
+	return cmd
+}
+
+// newPluginRemoveCommand creates the plugin remove subcommand.
+func newPluginRemoveCommand(opts *PluginOptions) *cobra.Command {
+	var version string
+
+	cmd := &cobra.Command{
+		Use:     "remove <name>",
+		Short:   "Remove a plugin",
+		Aliases: []string{"rm", "uninstall"},
+		Long: `Remove a plugin and revoke its permissions, or remove a single
+inactive version with --version.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := newPluginRegistry(opts)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			if err := registry.Uninstall(name, version); err != nil {
+				return err
+			}
+
+			if version != "" {
+				_, _ = fmt.Fprintf(opts.Output, "✓ Removed %s %s\n", name, version)
+			} else {
+				_, _ = fmt.Fprintf(opts.Output, "✓ Removed %s\n", name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "Remove only this version")
+
+	return cmd
+}
+
+// newPluginVerifyCommand creates the plugin verify subcommand.
+func newPluginVerifyCommand(opts *PluginOptions) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "verify [name...]",
+		Short: "Check installed plugins for modifications",
+		Long: `Check the files of installed plugins against the SHA-256 digests
+recorded at install time. Without names, every installed plugin is
+checked.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginVerify(opts, args, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// newPluginTestCommand creates the plugin test subcommand.
+func newPluginTestCommand(opts *PluginOptions) *cobra.Command {
+	var outputFormat string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test <path|name>",
+		Short: "Run a plugin through the conformance suite",
+		Long: `Run a plugin directory or an installed plugin through the conformance
+suite. It checks the manifest and permission declarations, the session
+handshake and shutdown, that an unknown command is answered in time with
+a structured error, and the results of declared hooks.
+
+The plugin runs in the same sandbox as an installed plugin. Declared
+commands are not run.
+
+Examples:
+  plugin test ./my-plugin
+  plugin test my-plugin --timeout 30s`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginTest(cmd.Context(), opts, args[0], timeout, outputFormat)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Time the plugin gets to answer each request")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// newPluginAuditCommand creates the plugin audit subcommand.
+func newPluginAuditCommand(opts *PluginOptions) *cobra.Command {
+	var outputFormat, action string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "audit [name]",
+		Short: "Show permission approvals, denials and revocations",
+		Long: `Show the audit log of plugin permission decisions, oldest first. Each
+entry records whether the user or the plugin policy made the decision.
+
+Examples:
+  plugin audit
+  plugin audit greeter --action deny
+  plugin audit --limit 20 -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return runPluginAudit(opts, name, plugin.AuditAction(action), limit, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVar(&action, "action", "", "Only show entries with this action (approve|deny|revoke)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Only show the most recent entries")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format (table|json)")
+
+	return cmd
+}
+
+// addInstallFlags adds the verification flags of install and upgrade.
+func addInstallFlags(cmd *cobra.Command, installOpts *plugin.InstallOptions) {
+	cmd.Flags().StringVar(&installOpts.SHA256, "sha256", "", "Expected SHA-256 digest of the archive")
+	cmd.Flags().StringVar(&installOpts.Signature, "signature", "", "Path or URL of the archive's signature")
+}
+
+// newPluginRegistry creates the registry of the CLI's installed plugins.
+func newPluginRegistry(opts *PluginOptions) (*plugin.Registry, error) {
+	approver := opts.Approver
+	if approver == nil {
+		approver = &plugin.DefaultApprover{}
+	}
+
+	configDir := filepath.Join(xdg.ConfigHome, opts.CLIName)
+	permManager, err := plugin.NewPermissionManager(configDir, approver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plugin permission manager: %w", err)
+	}
+
+	policy, err := plugin.NewTrustPolicy(opts.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin configuration: %w", err)
+	}
+	pluginPolicy, err := plugin.NewPolicy(opts.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin policy: %w", err)
+	}
+	permManager.SetPolicy(pluginPolicy)
+
+	registry := plugin.NewRegistry(filepath.Join(configDir, "plugins"), permManager)
+	registry.SetTrustPolicy(policy)
+	return registry, nil
+}
+
+// runPluginList lists installed plugins.
+func runPluginList(opts *PluginOptions, outputFormat string) error {
+	registry, err := newPluginRegistry(opts)
+	if err != nil {
+		return err
+	}
+
+	records, err := registry.InstalledPlugins()
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		if records == nil {
+			records = []*plugin.InstallRecord{}
+		}
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+
+	if len(records) == 0 {
+		_, _ = fmt.Fprintln(opts.Output, "No plugins installed")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(opts.Output, "%-20s %-12s %-16s %s\n", "NAME", "VERSION", "PUBLISHER", "INSTALLED VERSIONS")
+	_, _ = fmt.Fprintln(opts.Output, strings.Repeat("-", 80))
+
+	for _, record := range records {
+		publisher := "-"
+		if active := record.ActiveVersion(); active != nil && active.Publisher != "" {
+			publisher = active.Publisher
+		}
+
+		versions := make([]string, 0, len(record.Versions))
+		for _, v := range record.Versions {
+			versions = append(versions, v.Version)
+		}
+
+		_, _ = fmt.Fprintf(opts.Output, "%-20s %-12s %-16s %s\n", record.Name, record.Active, publisher, strings.Join(versions, ", "))
+	}
+
+	return nil
+}
+
+// runPluginInfo shows the details of an installed plugin.
+func runPluginInfo(opts *PluginOptions, name, outputFormat string) error {
+	registry, err := newPluginRegistry(opts)
+	if err != nil {
+		return err
+	}
+
+	record, err := registry.InstalledPlugin(name)
+	if err != nil {
+		return err
+	}
+	manifest, err := registry.InstalledManifest(name, record.Active)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(&PluginDetails{InstallRecord: record, Manifest: manifest})
+	}
+
+	w := opts.Output
+	_, _ = fmt.Fprintf(w, "Name: %s\n", manifest.Name)
+	if manifest.Description != "" {
+		_, _ = fmt.Fprintf(w, "Description: %s\n", manifest.Description)
+	}
+	if manifest.Author != "" {
+		_, _ = fmt.Fprintf(w, "Author: %s\n", manifest.Author)
+	}
+	_, _ = fmt.Fprintf(w, "Type: %s\n", manifest.Type)
+	_, _ = fmt.Fprintf(w, "Active version: %s\n", record.Active)
+
+	if active := record.ActiveVersion(); active != nil {
+		_, _ = fmt.Fprintf(w, "Source: %s\n", active.Source)
+		if active.SHA256 != "" {
+			_, _ = fmt.Fprintf(w, "SHA-256: %s\n", active.SHA256)
+		}
+		_, _ = fmt.Fprintf(w, "Signature: %s\n", describeSigner(active))
+		_, _ = fmt.Fprintf(w, "Installed: %s\n", active.InstalledAt.Format(time.RFC3339))
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Versions:")
+	for _, v := range record.Versions {
+		marker := " "
+		if v.Version == record.Active {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s\n", marker, v.Version)
+	}
+
+	if len(manifest.Permissions) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "Permissions:")
+		for _, perm := range manifest.Permissions {
+			_, _ = fmt.Fprintf(w, "  • %s", perm.String())
+			if perm.Description != "" {
+				_, _ = fmt.Fprintf(w, " - %s", perm.Description)
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	if len(manifest.Commands) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "Commands:")
+		for _, command := range manifest.Commands {
+			path := strings.TrimSpace(command.Parent + " " + command.Name)
+			_, _ = fmt.Fprintf(w, "  %-20s %s\n", path, command.Description)
+		}
+	}
+
+	if len(manifest.Hooks) > 0 {
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "Hooks:")
+		for _, hook := range manifest.Hooks {
+			_, _ = fmt.Fprintf(w, "  %s", hook.Point)
+			if len(hook.Operations) > 0 {
+				_, _ = fmt.Fprintf(w, " (%s)", strings.Join(hook.Operations, ", "))
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
+
+// runPluginVerify verifies installed plugins and fails if any were
+// modified.
+func runPluginVerify(opts *PluginOptions, names []string, outputFormat string) error {
+	registry, err := newPluginRegistry(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		records, err := registry.InstalledPlugins()
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			names = append(names, record.Name)
+		}
+	}
+
+	results := []*plugin.VerifyResult{}
+	for _, name := range names {
+		verified, err := registry.VerifyPlugin(name)
+		if err != nil {
+			return err
+		}
+		results = append(results, verified...)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.OK() {
+			failed++
+		}
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		if len(results) == 0 {
+			_, _ = fmt.Fprintln(opts.Output, "No plugins installed")
+		}
+		for _, result := range results {
+			if result.OK() {
+				_, _ = fmt.Fprintf(opts.Output, "✓ %s %s\n", result.Name, result.Version)
+				continue
+			}
+			_, _ = fmt.Fprintf(opts.Output, "✗ %s %s\n", result.Name, result.Version)
+			for _, file := range result.Modified {
+				_, _ = fmt.Fprintf(opts.Output, "    modified: %s\n", file)
+			}
+			for _, file := range result.Missing {
+				_, _ = fmt.Fprintf(opts.Output, "    missing:  %s\n", file)
+			}
+			for _, file := range result.Added {
+				_, _ = fmt.Fprintf(opts.Output, "    added:    %s\n", file)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d plugin version(s) failed verification", failed)
+	}
+	return nil
+}
+
+// runPluginTest runs a plugin through the conformance suite.
+func runPluginTest(ctx context.Context, opts *PluginOptions, target string, timeout time.Duration, outputFormat string) error {
+	dir := target
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		registry, err := newPluginRegistry(opts)
+		if err != nil {
+			return err
+		}
+		record, err := registry.InstalledPlugin(target)
+		if err != nil {
+			return fmt.Errorf("%s is neither a plugin directory nor an installed plugin: %w", target, err)
+		}
+		dir = filepath.Join(xdg.ConfigHome, opts.CLIName, "plugins", record.Name, record.Active)
+	}
+
+	sandbox := plugin.DefaultSandboxConfig()
+	sandbox.Warnings = opts.Output
+	report := plugin.RunConformance(ctx, dir, plugin.ConformanceOptions{Timeout: timeout, Sandbox: sandbox})
+
+	counts := make(map[plugin.ConformanceStatus]int)
+	for _, check := range report.Checks {
+		counts[check.Status]++
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		name := report.Plugin
+		if name == "" {
+			name = "plugin"
+		}
+		_, _ = fmt.Fprintf(opts.Output, "Testing %s %s (%s)\n\n", name, report.Version, dir)
+		for _, check := range report.Checks {
+			_, _ = fmt.Fprintf(opts.Output, "%s %-20s %s\n", conformanceSymbols[check.Status], check.Name, check.Message)
+		}
+		_, _ = fmt.Fprintf(opts.Output, "\n%d passed, %d warning(s), %d failed, %d skipped\n",
+			counts[plugin.ConformancePass], counts[plugin.ConformanceWarn], counts[plugin.ConformanceFail], counts[plugin.ConformanceSkip])
+	}
+
+	if !report.Passed() {
+		return fmt.Errorf("plugin failed %d conformance check(s)", counts[plugin.ConformanceFail])
+	}
+	return nil
+}
+
+// runPluginAudit shows the audit log.
+func runPluginAudit(opts *PluginOptions, name string, action plugin.AuditAction, limit int, outputFormat string) error {
+	switch action {
+	case "", plugin.AuditApprove, plugin.AuditDeny, plugin.AuditRevoke:
+	default:
+		return fmt.Errorf("invalid action: %s (expected approve, deny or revoke)", action)
+	}
+
+	log := plugin.NewAuditLog(filepath.Join(xdg.ConfigHome, opts.CLIName, plugin.AuditFile))
+	all, err := log.Entries()
+	if err != nil {
+		return err
+	}
+
+	entries := []plugin.AuditEntry{}
+	for _, entry := range all {
+		if (name == "" || entry.Plugin == name) && (action == "" || entry.Action == action) {
+			entries = append(entries, entry)
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(opts.Output, "No audit entries")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(opts.Output, "%-20s %-8s %-7s %-24s %s\n", "TIME", "ACTION", "ACTOR", "PLUGIN", "DETAILS")
+	_, _ = fmt.Fprintln(opts.Output, strings.Repeat("-", 80))
+
+	for _, entry := range entries {
+		target := entry.Plugin
+		if entry.Version != "" {
+			target += " " + entry.Version
+		}
+
+		details := strings.Join(entry.Permissions, ", ")
+		if entry.Reason != "" {
+			if details != "" {
+				details += " "
+			}
+			details += "(" + entry.Reason + ")"
+		}
+
+		_, _ = fmt.Fprintf(opts.Output, "%-20s %-8s %-7s %-24s %s\n",
+			entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Action, entry.Actor, target, details)
+	}
+
+	return nil
+}
+
+// conformanceSymbols mark conformance check results in text output.
+var conformanceSymbols = map[plugin.ConformanceStatus]string{
+	plugin.ConformancePass: "✓",
+	plugin.ConformanceWarn: "!",
+	plugin.ConformanceFail: "✗",
+	plugin.ConformanceSkip: "-",
+}
+
+// describeSigner describes who signed an installed version.
+func describeSigner(version *plugin.InstalledVersion) string {
+	if version.Publisher == "" {
+		return "unsigned"
+	}
+	return "signed by " + version.Publisher
+}
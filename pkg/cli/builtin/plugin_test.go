@@ -0,0 +1,274 @@
+package builtin
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"github.com/adrg/xdg"
+)
+
+func writePluginTestSource(t *testing.T, version string, permissions string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "greeter-"+version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := `name: greeter
+version: ` + version + `
+type: binary
+description: Greets people
+executable: greeter.sh
+permissions:
+` + permissions + `
+commands:
+  - name: greet
+    description: Greet someone
+`
+	if err := os.WriteFile(filepath.Join(dir, plugin.ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "greeter.sh"), []byte("#!/bin/sh\necho hello\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func runPluginTestCommand(t *testing.T, opts *PluginOptions, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	opts.Output = &out
+
+	cmd := NewPluginCommand(opts)
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPluginCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	opts := &PluginOptions{CLIName: "plugintest", Approver: &plugin.AutoApprover{}}
+
+	out, err := runPluginTestCommand(t, opts, "list")
+	if err != nil || !strings.Contains(out, "No plugins installed") {
+		t.Errorf("Expected empty list, got %q (%v)", out, err)
+	}
+
+	v1 := writePluginTestSource(t, "1.0.0", "  - type: read:env\n    resource: HOME")
+	out, err = runPluginTestCommand(t, opts, "install", v1)
+	if err != nil {
+		t.Fatalf("install error = %v", err)
+	}
+	if !strings.Contains(out, "✓ Installed greeter 1.0.0 (unsigned)") {
+		t.Errorf("Unexpected install output: %q", out)
+	}
+
+	v2 := writePluginTestSource(t, "1.1.0", "  - type: read:env\n    resource: HOME")
+	out, err = runPluginTestCommand(t, opts, "upgrade", "greeter", v2)
+	if err != nil {
+		t.Fatalf("upgrade error = %v", err)
+	}
+	if !strings.Contains(out, "✓ Upgraded greeter 1.0.0 -> 1.1.0") {
+		t.Errorf("Unexpected upgrade output: %q", out)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "list")
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	if !strings.Contains(out, "greeter") || !strings.Contains(out, "1.0.0, 1.1.0") {
+		t.Errorf("Unexpected list output: %q", out)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "info", "greeter")
+	if err != nil {
+		t.Fatalf("info error = %v", err)
+	}
+	for _, want := range []string{"Active version: 1.1.0", "read:env:HOME", "greet", "* 1.1.0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected info to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, err = runPluginTestCommand(t, opts, "info", "greeter", "-o", "json")
+	if err != nil {
+		t.Fatalf("info error = %v", err)
+	}
+	var details map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &details); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if details["active"] != "1.1.0" || details["manifest"].(map[string]interface{})["name"] != "greeter" {
+		t.Errorf("Unexpected info JSON: %s", out)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "upgrade", "greeter", "--version", "1.0.0")
+	if err != nil || !strings.Contains(out, "✓ Activated greeter 1.0.0") {
+		t.Errorf("Expected rollback, got %q (%v)", out, err)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "verify")
+	if err != nil || !strings.Contains(out, "✓ greeter 1.0.0") || !strings.Contains(out, "✓ greeter 1.1.0") {
+		t.Errorf("Expected clean verification, got %q (%v)", out, err)
+	}
+
+	script := filepath.Join(xdg.ConfigHome, "plugintest", "plugins", "greeter", "1.1.0", "greeter.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho pwned\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runPluginTestCommand(t, opts, "verify", "greeter")
+	if err == nil || !strings.Contains(out, "✗ greeter 1.1.0") || !strings.Contains(out, "modified: greeter.sh") {
+		t.Errorf("Expected verification failure, got %q (%v)", out, err)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "remove", "greeter", "--version", "1.1.0")
+	if err != nil || !strings.Contains(out, "✓ Removed greeter 1.1.0") {
+		t.Errorf("Expected version removal, got %q (%v)", out, err)
+	}
+
+	out, err = runPluginTestCommand(t, opts, "remove", "greeter")
+	if err != nil || !strings.Contains(out, "✓ Removed greeter") {
+		t.Errorf("Expected removal, got %q (%v)", out, err)
+	}
+	if _, err := runPluginTestCommand(t, opts, "info", "greeter"); err == nil {
+		t.Error("Expected info to fail after removal")
+	}
+}
+
+func TestPluginCommand_RequireSignature(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &PluginOptions{
+		CLIName:  "plugintest",
+		Approver: &plugin.AutoApprover{},
+		Config: &cli.Plugins{
+			Publishers:       []cli.PluginPublisher{{Name: "acme", PublicKey: base64.StdEncoding.EncodeToString(publicKey)}},
+			RequireSignature: true,
+		},
+	}
+
+	source := writePluginTestSource(t, "1.0.0", "  []")
+	if _, err := runPluginTestCommand(t, opts, "install", source); err == nil || !strings.Contains(err.Error(), "signed by a trusted publisher") {
+		t.Errorf("Expected unsigned install to be refused, got %v", err)
+	}
+
+	opts.Config.Publishers[0].PublicKey = "not-a-key"
+	if _, err := runPluginTestCommand(t, opts, "list"); err == nil || !strings.Contains(err.Error(), "publisher 'acme'") {
+		t.Errorf("Expected invalid key error, got %v", err)
+	}
+}
+
+func TestPluginCommand_Test(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	opts := &PluginOptions{CLIName: "plugintest", Approver: &plugin.AutoApprover{}}
+	source := writePluginTestSource(t, "1.0.0", "  - type: read:env\n    resource: HOME\n    description: Find the config")
+
+	// greeter.sh answers every command with plain output, so an unknown
+	// command looks like a success.
+	out, err := runPluginTestCommand(t, opts, "test", source)
+	if err == nil || !strings.Contains(err.Error(), "failed 1 conformance check") {
+		t.Errorf("Expected conformance failure, got %v", err)
+	}
+	for _, want := range []string{"Testing greeter 1.0.0", "✓ manifest", "✓ permissions", "✗ errors", "1 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if _, err := runPluginTestCommand(t, opts, "install", source); err != nil {
+		t.Fatalf("install error = %v", err)
+	}
+	out, _ = runPluginTestCommand(t, opts, "test", "greeter", "-o", "json")
+	// The failure is reported after the JSON document
+	var report plugin.ConformanceReport
+	if err := json.NewDecoder(strings.NewReader(out)).Decode(&report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if report.Plugin != "greeter" || len(report.Checks) == 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if _, err := runPluginTestCommand(t, opts, "test", "missing"); err == nil || !strings.Contains(err.Error(), "neither a plugin directory nor an installed plugin") {
+		t.Errorf("Expected unknown plugin error, got %v", err)
+	}
+}
+
+func TestPluginCommand_Audit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	opts := &PluginOptions{
+		CLIName:  "plugintest",
+		Approver: &plugin.AutoApprover{},
+		Config: &cli.Plugins{Policy: &cli.PluginPolicy{
+			Deny:               []cli.PluginRule{{Name: "greeter", Versions: "<1.0.0"}},
+			BlockedPermissions: []string{"execute:*"},
+		}},
+	}
+
+	if _, err := runPluginTestCommand(t, opts, "install", writePluginTestSource(t, "0.9.0", "  - type: read:env\n    resource: HOME")); err == nil ||
+		!strings.Contains(err.Error(), "denied by policy") {
+		t.Errorf("Expected policy denial, got %v", err)
+	}
+	if _, err := runPluginTestCommand(t, opts, "install", writePluginTestSource(t, "1.0.0", "  - type: execute\n    resource: git")); err == nil ||
+		!strings.Contains(err.Error(), "blocked by policy: execute:git") {
+		t.Errorf("Expected blocked permission, got %v", err)
+	}
+	if _, err := runPluginTestCommand(t, opts, "install", writePluginTestSource(t, "1.0.0", "  - type: read:env\n    resource: HOME")); err != nil {
+		t.Fatalf("install error = %v", err)
+	}
+	if _, err := runPluginTestCommand(t, opts, "remove", "greeter"); err != nil {
+		t.Fatalf("remove error = %v", err)
+	}
+
+	out, err := runPluginTestCommand(t, opts, "audit")
+	if err != nil {
+		t.Fatalf("audit error = %v", err)
+	}
+	for _, want := range []string{"deny     policy  greeter 0.9.0", "execute:git (permissions blocked by policy", "approve  user    greeter 1.0.0", "read:env:HOME", "revoke   user"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, err = runPluginTestCommand(t, opts, "audit", "greeter", "--action", "deny", "--limit", "1", "-o", "json")
+	if err != nil {
+		t.Fatalf("audit error = %v", err)
+	}
+	var entries []plugin.AuditEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if len(entries) != 1 || entries[0].Version != "1.0.0" || entries[0].Actor != plugin.AuditActorPolicy {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+
+	if _, err := runPluginTestCommand(t, opts, "audit", "--action", "grant"); err == nil {
+		t.Error("Expected invalid action error")
+	}
+}
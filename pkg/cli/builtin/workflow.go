@@ -0,0 +1,292 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/workflow"
+	"github.com/spf13/cobra"
+)
+
+// WorkflowOptions configures the workflow command behavior.
+type WorkflowOptions struct {
+	// Spec is used to resolve x-cli-workflow operations by operationId.
+	Spec *openapi.ParsedSpec
+	// HTTPClient is used by compensations re-run with workflow rollback.
+	HTTPClient *http.Client
+	// State loads and saves workflow execution state; defaults to the
+	// standard state directory.
+	State  *workflow.StateManager
+	Output io.Writer
+}
+
+// NewWorkflowCommand creates a new workflow command.
+func NewWorkflowCommand(opts *WorkflowOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "Test and inspect workflows",
+		Long: `Test and inspect multi-step workflows.
+
+Examples:
+  workflow test workflows.test.yaml    # Run workflow tests against fixtures
+  workflow rollback workflow-1700000000  # Retry failed compensations
+  workflow timeline workflow-1700000000  # Show when each step ran`,
+	}
+
+	cmd.AddCommand(newWorkflowTestCommand(opts))
+	cmd.AddCommand(newWorkflowRollbackCommand(opts))
+	cmd.AddCommand(newWorkflowTimelineCommand(opts))
+
+	return cmd
+}
+
+// newWorkflowTestCommand creates the workflow test subcommand.
+func newWorkflowTestCommand(opts *WorkflowOptions) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "test <suite-file>...",
+		Short: "Run workflow tests against recorded fixtures",
+		Long: `Run workflows against recorded HTTP fixtures and check step outputs.
+
+Each suite file lists test cases. A case names an x-cli-workflow operation
+(or defines a workflow inline), the flags to run it with, a fixture
+directory recorded with --record, and the expected step outputs.
+No network access is needed.
+
+Example suite:
+  fixtures: ./fixtures/create-cluster
+  match: [method, path, query]
+  cases:
+    - name: creates a cluster
+      operation: createCluster
+      flags:
+        name: demo
+      expect:
+        status: completed
+        steps:
+          create-cluster:
+            status_code: 201
+            response.id: c-1`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowTest(opts, args, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// workflowTestReport is the JSON form of a test run.
+type workflowTestReport struct {
+	Suite    string   `json:"suite"`
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures,omitempty"`
+	Duration string   `json:"duration"`
+}
+
+// runWorkflowTest runs each suite file and reports the results.
+func runWorkflowTest(opts *WorkflowOptions, files []string, outputFormat string) error {
+	var reports []workflowTestReport
+	failed := 0
+
+	for _, file := range files {
+		suite, err := workflow.LoadTestSuite(file)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range suite.Run(opts.resolveWorkflow) {
+			if !result.Passed {
+				failed++
+			}
+			reports = append(reports, workflowTestReport{
+				Suite:    file,
+				Name:     result.Name,
+				Passed:   result.Passed,
+				Failures: result.Failures,
+				Duration: result.Duration.Round(time.Millisecond).String(),
+			})
+		}
+	}
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, report := range reports {
+			status := "PASS"
+			if !report.Passed {
+				status = "FAIL"
+			}
+			_, _ = fmt.Fprintf(opts.Output, "%s  %s (%s)\n", status, report.Name, report.Duration)
+			for _, failure := range report.Failures {
+				_, _ = fmt.Fprintf(opts.Output, "      %s\n", failure)
+			}
+		}
+		_, _ = fmt.Fprintf(opts.Output, "\n%d passed, %d failed\n", len(reports)-failed, failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d workflow test(s) failed", failed)
+	}
+	return nil
+}
+
+// newWorkflowRollbackCommand creates the workflow rollback subcommand.
+func newWorkflowRollbackCommand(opts *WorkflowOptions) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "rollback <workflow-id>",
+		Short: "Retry the failed compensations of a workflow",
+		Long: `Re-run the compensations that failed or were skipped when a workflow
+rolled back. The rollback report saved with the workflow state records each
+compensation and the context it needs, so they can be retried after the
+original command has exited. Compensations whose context held secrets are
+saved with the secrets masked and are not retried.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowRollback(opts, args[0], outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+
+	return cmd
+}
+
+// runWorkflowRollback retries the incomplete compensations of a saved
+// workflow execution and saves the updated report.
+func runWorkflowRollback(opts *WorkflowOptions, workflowID, outputFormat string) error {
+	stateManager := opts.State
+	if stateManager == nil {
+		stateManager = workflow.NewStateManager()
+	}
+
+	state, err := stateManager.LoadState(workflowID)
+	if err != nil {
+		return err
+	}
+
+	if state.Rollback == nil || len(state.Rollback.Incomplete()) == 0 {
+		if outputFormat == "json" {
+			return writeRollbackReport(opts.Output, state.Rollback)
+		}
+		_, _ = fmt.Fprintf(opts.Output, "Nothing to roll back for %s\n", workflowID)
+		return nil
+	}
+
+	rollback := workflow.NewRollbackManager()
+	if outputFormat != "json" {
+		rollback.SetObserver(workflow.NewTextRollbackObserver(opts.Output))
+	}
+
+	retryErr := rollback.RetryFailed(state.Rollback, workflow.NewStepExecutor(opts.HTTPClient, nil))
+	if retryErr == nil {
+		state.Status = workflow.ExecutionStatusRolledBack
+	}
+
+	if err := stateManager.SaveState(state); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if outputFormat == "json" {
+		if err := writeRollbackReport(opts.Output, state.Rollback); err != nil {
+			return err
+		}
+	}
+
+	return retryErr
+}
+
+// writeRollbackReport writes a rollback report as indented JSON.
+func writeRollbackReport(w io.Writer, report *workflow.RollbackReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// newWorkflowTimelineCommand creates the workflow timeline subcommand.
+func newWorkflowTimelineCommand(opts *WorkflowOptions) *cobra.Command {
+	var outputFormat string
+	var width int
+
+	cmd := &cobra.Command{
+		Use:   "timeline <workflow-id>",
+		Short: "Show when each step of a workflow ran",
+		Long: `Render the event log of a workflow execution as a text chart.
+
+Each top-level step is shown on its own row, grouped by execution level,
+with a bar spanning the time it ran, its duration and its retry count.
+Compensations run during rollback are listed below the steps.
+
+Bars: = succeeded, x failed, - skipped, > still running, < rolled back`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkflowTimeline(opts, args[0], outputFormat, width)
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text|json)")
+	cmd.Flags().IntVar(&width, "width", 40, "Width of the chart in columns")
+
+	return cmd
+}
+
+// runWorkflowTimeline loads a workflow's event log and renders it.
+func runWorkflowTimeline(opts *WorkflowOptions, workflowID, outputFormat string, width int) error {
+	stateManager := opts.State
+	if stateManager == nil {
+		stateManager = workflow.NewStateManager()
+	}
+
+	events, err := stateManager.LoadEvents(workflowID)
+	if err != nil {
+		return err
+	}
+
+	timeline := workflow.BuildTimeline(events)
+
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(opts.Output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(timeline)
+	}
+
+	return timeline.Render(opts.Output, width)
+}
+
+// resolveWorkflow finds the x-cli-workflow for an operationId in the spec.
+func (opts *WorkflowOptions) resolveWorkflow(operationID string) (*workflow.Workflow, error) {
+	if opts.Spec == nil {
+		return nil, fmt.Errorf("no spec loaded to resolve operation %s", operationID)
+	}
+
+	operations, err := opts.Spec.GetOperations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operations: %w", err)
+	}
+
+	for _, op := range operations {
+		if op.OperationID != operationID {
+			continue
+		}
+		if op.CLIWorkflow == nil {
+			return nil, fmt.Errorf("operation %s has no x-cli-workflow", operationID)
+		}
+		return workflow.FromCLIWorkflow(op.CLIWorkflow)
+	}
+
+	return nil, fmt.Errorf("operation %s not found in spec", operationID)
+}
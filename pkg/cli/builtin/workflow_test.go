@@ -0,0 +1,301 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/workflow"
+)
+
+func writeWorkflowTestSuite(t *testing.T, expectedID string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "fixtures"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fixture := `{
+  "request": {"method": "GET", "path": "/users/u-1"},
+  "response": {"status": 200, "body": {"id": "u-1"}}
+}`
+	if err := os.WriteFile(filepath.Join(dir, "fixtures", "0001-get-users-u-1.json"), []byte(fixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	suite := `fixtures: fixtures
+cases:
+  - name: reads a user
+    workflow:
+      steps:
+        - id: get-user
+          type: api-call
+          api-call:
+            method: GET
+            endpoint: https://api.example.com/users/u-1
+    expect:
+      steps:
+        get-user:
+          response.id: ` + expectedID + "\n"
+	path := filepath.Join(dir, "suite.yaml")
+	if err := os.WriteFile(path, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewWorkflowCommand(t *testing.T) {
+	cmd := NewWorkflowCommand(&WorkflowOptions{Output: &bytes.Buffer{}})
+
+	if cmd.Use != "workflow" {
+		t.Errorf("expected Use 'workflow', got %q", cmd.Use)
+	}
+
+	found := false
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == "test" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected test subcommand")
+	}
+}
+
+func TestWorkflowTest_Pass(t *testing.T) {
+	output := &bytes.Buffer{}
+	opts := &WorkflowOptions{Output: output}
+
+	if err := runWorkflowTest(opts, []string{writeWorkflowTestSuite(t, "u-1")}, "text"); err != nil {
+		t.Fatalf("runWorkflowTest() error = %v", err)
+	}
+
+	result := output.String()
+	if !strings.Contains(result, "PASS  reads a user") {
+		t.Errorf("expected PASS line, got: %s", result)
+	}
+	if !strings.Contains(result, "1 passed, 0 failed") {
+		t.Errorf("expected summary, got: %s", result)
+	}
+}
+
+func TestWorkflowTest_Fail(t *testing.T) {
+	output := &bytes.Buffer{}
+	opts := &WorkflowOptions{Output: output}
+
+	err := runWorkflowTest(opts, []string{writeWorkflowTestSuite(t, "u-2")}, "json")
+	if err == nil {
+		t.Fatal("expected error for failing test")
+	}
+
+	result := output.String()
+	if !strings.Contains(result, `"passed": false`) {
+		t.Errorf("expected failed JSON report, got: %s", result)
+	}
+	if !strings.Contains(result, "got u-1, want u-2") {
+		t.Errorf("expected failure detail, got: %s", result)
+	}
+}
+
+func TestWorkflowOptions_ResolveWorkflowWithoutSpec(t *testing.T) {
+	opts := &WorkflowOptions{}
+	if _, err := opts.resolveWorkflow("createCluster"); err == nil {
+		t.Error("expected error without a spec")
+	}
+}
+
+func TestWorkflowRollbackCommand(t *testing.T) {
+	stateManager := workflow.NewStateManagerWithDir(t.TempDir())
+	state := &workflow.ExecutionState{
+		WorkflowID: "workflow-1",
+		Status:     workflow.ExecutionStatusFailed,
+		Rollback: &workflow.RollbackReport{
+			Compensations: []*workflow.CompensationRecord{
+				{ID: "create[1]", StepID: "create", Iteration: 1, Status: workflow.CompensationSucceeded},
+				{ID: "create[2]", StepID: "create", Iteration: 2, Status: workflow.CompensationFailed,
+					Action: &workflow.Step{ID: "delete", Type: workflow.StepTypeNoop}, ContextRef: "root"},
+			},
+			Contexts: map[string]*workflow.ContextSnapshot{"root": {}},
+		},
+	}
+	if err := stateManager.SaveState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := NewWorkflowCommand(&WorkflowOptions{State: stateManager, Output: &out})
+	cmd.SetArgs([]string{"rollback", "workflow-1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Successfully rolled back step create[2]") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "create[1]") {
+		t.Errorf("succeeded compensations should not be re-run:\n%s", out.String())
+	}
+
+	saved, err := stateManager.LoadState("workflow-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != workflow.ExecutionStatusRolledBack {
+		t.Errorf("Status = %s, want %s", saved.Status, workflow.ExecutionStatusRolledBack)
+	}
+
+	out.Reset()
+	cmd = NewWorkflowCommand(&WorkflowOptions{State: stateManager, Output: &out})
+	cmd.SetArgs([]string{"rollback", "workflow-1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("second Execute() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Nothing to roll back") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+const rollbackSpec = `
+openapi: 3.0.0
+info:
+  title: Clusters
+  version: 1.0.0
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      responses:
+        "201":
+          description: Created
+      x-cli-workflow:
+        settings:
+          rollback-retry:
+            max-attempts: 1
+        steps:
+          - id: create
+            request:
+              method: POST
+              url: SERVER/clusters
+            rollback:
+              request:
+                method: DELETE
+                url: "SERVER/clusters/{steps.create.response.id}"
+          - id: attach
+            depends-on: [create]
+            required: true
+            request:
+              method: POST
+              url: SERVER/attach
+`
+
+func TestWorkflowRollbackCommand_SpecWorkflow(t *testing.T) {
+	var mu sync.Mutex
+	var deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/clusters":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "c-1"}`))
+		case r.Method == http.MethodDelete:
+			deletes = append(deletes, r.URL.Path)
+			// The first compensation fails
+			if len(deletes) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	parsed, err := openapi.NewParser().Parse(context.Background(), []byte(strings.ReplaceAll(rollbackSpec, "SERVER", server.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &WorkflowOptions{Spec: parsed, HTTPClient: server.Client()}
+	wf, err := opts.resolveWorkflow("createCluster")
+	if err != nil {
+		t.Fatalf("resolveWorkflow() error = %v", err)
+	}
+
+	stateManager := workflow.NewStateManagerWithDir(t.TempDir())
+	executor, err := workflow.NewExecutor(wf, server.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor.SetStateManager(stateManager)
+
+	state, err := executor.Execute(workflow.NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected the workflow to fail")
+	}
+	if state.Rollback == nil || len(state.Rollback.Incomplete()) != 1 {
+		t.Fatalf("expected one incomplete compensation, got %+v", state.Rollback)
+	}
+
+	var out bytes.Buffer
+	opts.State = stateManager
+	opts.Output = &out
+	cmd := NewWorkflowCommand(opts)
+	cmd.SetArgs([]string{"rollback", state.WorkflowID})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out.String())
+	}
+
+	if want := []string{"/clusters/c-1", "/clusters/c-1"}; strings.Join(deletes, " ") != strings.Join(want, " ") {
+		t.Errorf("DELETE requests = %v, want %v", deletes, want)
+	}
+	saved, err := stateManager.LoadState(state.WorkflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != workflow.ExecutionStatusRolledBack {
+		t.Errorf("Status = %s, want %s", saved.Status, workflow.ExecutionStatusRolledBack)
+	}
+}
+
+func TestWorkflowTimelineCommand(t *testing.T) {
+	dir := t.TempDir()
+	stateManager := workflow.NewStateManagerWithDir(dir)
+
+	log := `{"time":"2026-01-01T12:00:00Z","type":"step-scheduled","workflow-id":"workflow-1","step-id":"create"}
+{"time":"2026-01-01T12:00:00Z","type":"step-started","workflow-id":"workflow-1","step-id":"create","attempt":1}
+{"time":"2026-01-01T12:00:02Z","type":"step-succeeded","workflow-id":"workflow-1","step-id":"create","attempt":1}
+{"time":"2026-01-01T12:00:02Z","type":"workflow-completed","workflow-id":"workflow-1"}
+`
+	if err := os.WriteFile(stateManager.GetEventLogPath("workflow-1"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := NewWorkflowCommand(&WorkflowOptions{State: stateManager, Output: &out})
+	cmd.SetArgs([]string{"timeline", "workflow-1", "--width", "10"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Workflow workflow-1  completed  2s") {
+		t.Errorf("missing header:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "0      create  |==========|  2s") {
+		t.Errorf("missing step row:\n%s", out.String())
+	}
+
+	cmd = NewWorkflowCommand(&WorkflowOptions{State: stateManager, Output: &out})
+	cmd.SetArgs([]string{"timeline", "workflow-missing"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for missing event log")
+	}
+}
@@ -62,6 +62,7 @@ type Config struct {
 	Updates   *Updates   `yaml:"updates,omitempty" json:"updates,omitempty"`
 	Behaviors *Behaviors `yaml:"behaviors,omitempty" json:"behaviors,omitempty"`
 	Features  *Features  `yaml:"features,omitempty" json:"features,omitempty"`
+	Plugins   *Plugins   `yaml:"plugins,omitempty" json:"plugins,omitempty"`
 }
 
 // Metadata contains identifying information about the CLI tool.
@@ -127,6 +128,43 @@ type API struct {
 	DefaultHeaders map[string]string `yaml:"default_headers,omitempty" json:"default_headers,omitempty"`
 	UserAgent      string            `yaml:"user_agent,omitempty" json:"user_agent,omitempty"`
 	TelemetryURL   string            `yaml:"telemetry_url,omitempty" json:"telemetry_url,omitempty"`
+	// Specs composes several specs into one CLI in place of OpenAPIURL.
+	Specs []APISpec `yaml:"specs,omitempty" json:"specs,omitempty"`
+	// Signing requires the specs loaded at runtime to be signed.
+	Signing *SpecSigning `yaml:"signing,omitempty" json:"signing,omitempty"`
+}
+
+// SpecSigning lists the keys specs loaded at runtime must be signed with.
+// Signatures are base64 encoded detached Ed25519 signatures, sent in the
+// X-Spec-Signature response header or served next to the spec with ".sig"
+// appended to its URL or path.
+type SpecSigning struct {
+	// PublicKeys are Ed25519 keys, PEM or base64. Several keys allow
+	// rotating them.
+	PublicKeys []string `yaml:"public_keys" json:"public_keys"`
+}
+
+// APISpec is one of several specs composed into a single CLI. Each spec
+// is loaded and cached on its own, so one that fails to load leaves the
+// others usable.
+type APISpec struct {
+	// Name identifies the spec in errors and messages.
+	Name       string `yaml:"name" json:"name"`
+	OpenAPIURL string `yaml:"openapi_url" json:"openapi_url"`
+	// Mount is the command group the spec's commands go under, such as
+	// "billing". Empty mounts them at the root.
+	Mount       string `yaml:"mount,omitempty" json:"mount,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// BaseURL defaults to the API base_url.
+	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+	// Auth defaults to behaviors.auth.
+	Auth *AuthBehavior `yaml:"auth,omitempty" json:"auth,omitempty"`
+	// CacheTTL is how long the fetched spec is cached (duration string).
+	CacheTTL string `yaml:"cache_ttl,omitempty" json:"cache_ttl,omitempty"`
+	// Tags keeps only the operations with one of these tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// ExcludeTags drops the operations with one of these tags.
+	ExcludeTags []string `yaml:"exclude_tags,omitempty" json:"exclude_tags,omitempty"`
 }
 
 // Environment represents a multi-environment configuration.
@@ -166,8 +204,8 @@ type DefaultsPagination struct {
 type DefaultsOutput struct {
 	Format      string `yaml:"format,omitempty" json:"format,omitempty"` // json, yaml, table, csv
 	PrettyPrint bool   `yaml:"pretty_print,omitempty" json:"pretty_print,omitempty"`
-	Color       string `yaml:"color,omitempty" json:"color,omitempty"` // auto, always, never
-	Paging      bool   `yaml:"paging,omitempty" json:"paging,omitempty"`
+	Color       string `yaml:"color,omitempty" json:"color,omitempty"`   // auto, always, never
+	Paging      *bool  `yaml:"paging,omitempty" json:"paging,omitempty"` // unset pages long output
 }
 
 // DefaultsDeprecations contains deprecation warning defaults.
@@ -189,6 +227,45 @@ type Updates struct {
 	PublicKey     string `yaml:"public_key,omitempty" json:"public_key,omitempty"`         // PEM format
 }
 
+// Plugins defines how plugins can be installed.
+type Plugins struct {
+	Publishers       []PluginPublisher `yaml:"publishers,omitempty" json:"publishers,omitempty"`
+	RequireSignature bool              `yaml:"require_signature,omitempty" json:"require_signature,omitempty"`
+	Policy           *PluginPolicy     `yaml:"policy,omitempty" json:"policy,omitempty"`
+}
+
+// PluginPolicy restricts which plugins users can run and which
+// permissions they can approve.
+type PluginPolicy struct {
+	// Allow lists the plugins that may run. When empty, any plugin not
+	// denied may run.
+	Allow []PluginRule `yaml:"allow,omitempty" json:"allow,omitempty"`
+	// Deny lists plugins that may never run. It takes precedence over Allow.
+	Deny []PluginRule `yaml:"deny,omitempty" json:"deny,omitempty"`
+	// BlockedPermissions are permission patterns such as "execute:*" or
+	// "credential" that are never granted.
+	BlockedPermissions []string `yaml:"blocked_permissions,omitempty" json:"blocked_permissions,omitempty"`
+	// PreApproved lists plugins whose permissions are granted without
+	// asking the user.
+	PreApproved []PluginRule `yaml:"pre_approved,omitempty" json:"pre_approved,omitempty"`
+	// ReapproveOnUpgrade asks for approval again when a plugin's version
+	// changes.
+	ReapproveOnUpgrade bool `yaml:"reapprove_on_upgrade,omitempty" json:"reapprove_on_upgrade,omitempty"`
+}
+
+// PluginRule matches plugins. Empty fields match anything.
+type PluginRule struct {
+	Name      string `yaml:"name,omitempty" json:"name,omitempty"`           // supports * wildcards
+	Publisher string `yaml:"publisher,omitempty" json:"publisher,omitempty"` // trusted publisher that signed the plugin
+	Versions  string `yaml:"versions,omitempty" json:"versions,omitempty"`   // e.g. ">=1.2.0 <2.0.0"
+}
+
+// PluginPublisher is a publisher whose signed plugin archives are trusted.
+type PluginPublisher struct {
+	Name      string `yaml:"name" json:"name"`
+	PublicKey string `yaml:"public_key" json:"public_key"` // Ed25519, PEM or base64
+}
+
 // Behaviors defines locked runtime behaviors.
 type Behaviors struct {
 	Auth            *AuthBehavior       `yaml:"auth,omitempty" json:"auth,omitempty"`
@@ -299,6 +376,7 @@ type BuiltinCommands struct {
 	Deprecations *DeprecationsCommand `yaml:"deprecations,omitempty" json:"deprecations,omitempty"`
 	Cache        *CacheCommand        `yaml:"cache,omitempty" json:"cache,omitempty"`
 	Auth         *AuthCommand         `yaml:"auth,omitempty" json:"auth,omitempty"`
+	Plugin       *BuiltinCommand      `yaml:"plugin,omitempty" json:"plugin,omitempty"`
 }
 
 // BuiltinCommand defines a basic built-in command.
@@ -379,6 +457,7 @@ type GlobalFlags struct {
 	Quiet   *GlobalFlag  `yaml:"quiet,omitempty" json:"quiet,omitempty"`
 	Debug   *GlobalFlag  `yaml:"debug,omitempty" json:"debug,omitempty"`
 	NoColor *GlobalFlag  `yaml:"no_color,omitempty" json:"no_color,omitempty"`
+	NoPager *GlobalFlag  `yaml:"no_pager,omitempty" json:"no_pager,omitempty"`
 	Timeout *GlobalFlag  `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	Retry   *GlobalFlag  `yaml:"retry,omitempty" json:"retry,omitempty"`
 	NoCache *GlobalFlag  `yaml:"no_cache,omitempty" json:"no_cache,omitempty"`
@@ -470,7 +549,7 @@ type PreferencesOutput struct {
 	Format      string `yaml:"format,omitempty" json:"format,omitempty"`
 	Color       string `yaml:"color,omitempty" json:"color,omitempty"`
 	PrettyPrint bool   `yaml:"pretty_print,omitempty" json:"pretty_print,omitempty"`
-	Paging      bool   `yaml:"paging,omitempty" json:"paging,omitempty"`
+	Paging      *bool  `yaml:"paging,omitempty" json:"paging,omitempty"` // unset keeps the CLI default
 }
 
 // PreferencesDeprecations contains deprecation preferences.
@@ -589,9 +668,23 @@ type PluginManifest struct {
 
 // PluginCommand represents a command provided by a plugin.
 type PluginCommand struct {
+	Name        string       `yaml:"name" json:"name"`
+	Description string       `yaml:"description,omitempty" json:"description,omitempty"`
+	Aliases     []string     `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Parent      string       `yaml:"parent,omitempty" json:"parent,omitempty"` // space-separated path, empty for top level
+	Args        string       `yaml:"args,omitempty" json:"args,omitempty"`     // usage of positional args, e.g. "<cluster-id>"
+	Flags       []PluginFlag `yaml:"flags,omitempty" json:"flags,omitempty"`
+}
+
+// PluginFlag represents a flag of a plugin command.
+type PluginFlag struct {
 	Name        string   `yaml:"name" json:"name"`
+	Shorthand   string   `yaml:"shorthand,omitempty" json:"shorthand,omitempty"`
+	Type        string   `yaml:"type,omitempty" json:"type,omitempty"` // string, int, float, bool, stringArray
 	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
-	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Default     any      `yaml:"default,omitempty" json:"default,omitempty"`
+	Required    bool     `yaml:"required,omitempty" json:"required,omitempty"`
+	Enum        []string `yaml:"enum,omitempty" json:"enum,omitempty"`
 }
 
 // ConfigPriority represents the priority of configuration sources.
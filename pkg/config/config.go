@@ -92,6 +92,12 @@ func (l *Loader) LoadConfig() (*cli.LoadedConfig, error) {
 		return nil, fmt.Errorf("failed to load embedded config: %w", err)
 	}
 
+	return l.Merge(embeddedConfig)
+}
+
+// Merge merges an embedded configuration that was already parsed with the
+// user configuration and environment variables, as LoadConfig does.
+func (l *Loader) Merge(embeddedConfig *cli.Config) (*cli.LoadedConfig, error) {
 	// Load user configuration
 	userConfig, err := l.loadUserConfig()
 	if err != nil {
@@ -272,6 +278,16 @@ func (l *Loader) setConfigValue(config *cli.Config, path, value string) error {
 			config.Defaults.Output.Color = "never"
 		}
 
+	case strings.HasPrefix(path, "defaults.output.paging"):
+		if config.Defaults == nil {
+			config.Defaults = &cli.Defaults{}
+		}
+		if config.Defaults.Output == nil {
+			config.Defaults.Output = &cli.DefaultsOutput{}
+		}
+		paging := value == "1" || value == "true"
+		config.Defaults.Output.Paging = &paging
+
 	case strings.HasPrefix(path, "defaults.caching.enabled"):
 		if config.Defaults == nil {
 			config.Defaults = &cli.Defaults{}
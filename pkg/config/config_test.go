@@ -461,6 +461,16 @@ func TestSetConfigValue(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "turn paging off",
+			path:  "defaults.output.paging",
+			value: "false",
+			verify: func(t *testing.T, config *cli.Config) {
+				if PagingEnabled(config.Defaults.Output) {
+					t.Error("expected paging to be off")
+				}
+			},
+		},
 		{
 			name:      "invalid path",
 			path:      "invalid.unknown.path",
@@ -41,6 +41,25 @@ func (l *Loader) applyDebugOverrides(config *cli.Config, override *cli.Config) (
 		overrides["api.openapi_url"] = override.API.OpenAPIURL
 	}
 
+	// Override composed specs by name, e.g. to point one service at a
+	// local build
+	for _, spec := range override.API.Specs {
+		for i := range config.API.Specs {
+			current := &config.API.Specs[i]
+			if current.Name != spec.Name {
+				continue
+			}
+			if spec.OpenAPIURL != "" && spec.OpenAPIURL != current.OpenAPIURL {
+				current.OpenAPIURL = spec.OpenAPIURL
+				overrides[fmt.Sprintf("api.specs.%s.openapi_url", spec.Name)] = spec.OpenAPIURL
+			}
+			if spec.BaseURL != "" && spec.BaseURL != current.BaseURL {
+				current.BaseURL = spec.BaseURL
+				overrides[fmt.Sprintf("api.specs.%s.base_url", spec.Name)] = spec.BaseURL
+			}
+		}
+	}
+
 	// Override metadata
 	if override.Metadata.Name != "" && override.Metadata.Name != config.Metadata.Name {
 		config.Metadata.Name = override.Metadata.Name
@@ -132,7 +151,10 @@ func (l *Loader) applyUserPreferences(config *cli.Config, prefs *cli.UserPrefere
 			config.Defaults.Output.Color = prefs.Output.Color
 		}
 		config.Defaults.Output.PrettyPrint = prefs.Output.PrettyPrint
-		config.Defaults.Output.Paging = prefs.Output.Paging
+		if prefs.Output.Paging != nil {
+			paging := *prefs.Output.Paging
+			config.Defaults.Output.Paging = &paging
+		}
 	}
 
 	// Apply deprecation preferences
@@ -169,6 +191,7 @@ func copyConfig(src *cli.Config) *cli.Config {
 		Metadata: src.Metadata,
 		API:      src.API,
 	}
+	dst.API.Specs = append([]cli.APISpec(nil), src.API.Specs...)
 
 	// Copy branding
 	if src.Branding != nil {
@@ -219,6 +242,10 @@ func copyConfig(src *cli.Config) *cli.Config {
 		}
 		if src.Defaults.Output != nil {
 			output := *src.Defaults.Output
+			if src.Defaults.Output.Paging != nil {
+				paging := *src.Defaults.Output.Paging
+				output.Paging = &paging
+			}
 			dst.Defaults.Output = &output
 		}
 		if src.Defaults.Deprecations != nil {
@@ -307,6 +334,12 @@ func copyConfig(src *cli.Config) *cli.Config {
 	return dst
 }
 
+// PagingEnabled reports whether long terminal output is paged with the
+// output defaults of a merged config. Paging is on unless turned off.
+func PagingEnabled(output *cli.DefaultsOutput) bool {
+	return output == nil || output.Paging == nil || *output.Paging
+}
+
 // MergeDefaults applies built-in defaults to a config for any missing values.
 func MergeDefaults(config *cli.Config) error {
 	if config == nil {
@@ -345,7 +378,6 @@ func MergeDefaults(config *cli.Config) error {
 			Format:      "json",
 			PrettyPrint: true,
 			Color:       "auto",
-			Paging:      true,
 		}
 	} else {
 		if config.Defaults.Output.Format == "" {
@@ -355,6 +387,10 @@ func MergeDefaults(config *cli.Config) error {
 			config.Defaults.Output.Color = "auto"
 		}
 	}
+	if config.Defaults.Output.Paging == nil {
+		paging := PagingEnabled(config.Defaults.Output)
+		config.Defaults.Output.Paging = &paging
+	}
 
 	if config.Defaults.Deprecations == nil {
 		config.Defaults.Deprecations = &cli.DefaultsDeprecations{
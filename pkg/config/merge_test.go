@@ -30,7 +30,7 @@ func TestMergeDefaults(t *testing.T) {
 						Format:      "json",
 						PrettyPrint: true,
 						Color:       "auto",
-						Paging:      true,
+						Paging:      boolPtr(true),
 					},
 					Deprecations: &cli.DefaultsDeprecations{
 						AlwaysShow:  false,
@@ -66,7 +66,7 @@ func TestMergeDefaults(t *testing.T) {
 						Format:      "json",
 						PrettyPrint: true,
 						Color:       "auto",
-						Paging:      true,
+						Paging:      boolPtr(true),
 					},
 					Deprecations: &cli.DefaultsDeprecations{
 						AlwaysShow:  false,
@@ -273,6 +273,28 @@ func TestApplyDebugOverrides(t *testing.T) {
 				"behaviors.auth.type": "none",
 			},
 		},
+		{
+			name: "override spec by name",
+			config: &cli.Config{
+				API: cli.API{
+					Specs: []cli.APISpec{
+						{Name: "accounts", OpenAPIURL: "https://accounts.example.com/openapi.yaml"},
+						{Name: "billing", OpenAPIURL: "https://billing.example.com/openapi.yaml"},
+					},
+				},
+			},
+			override: &cli.Config{
+				API: cli.API{
+					Specs: []cli.APISpec{
+						{Name: "billing", OpenAPIURL: "./billing.yaml", BaseURL: "http://localhost:8080"},
+					},
+				},
+			},
+			expectedChanges: map[string]interface{}{
+				"api.specs.billing.openapi_url": "./billing.yaml",
+				"api.specs.billing.base_url":    "http://localhost:8080",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1045,7 +1067,7 @@ func TestApplyUserPreferences_InitializeNilDefaults(t *testing.T) {
 					Format:      "yaml",
 					Color:       "never",
 					PrettyPrint: false,
-					Paging:      false,
+					Paging:      boolPtr(false),
 				},
 			},
 			checkFunc: func(t *testing.T, cfg *cli.Config) {
@@ -1061,7 +1083,7 @@ func TestApplyUserPreferences_InitializeNilDefaults(t *testing.T) {
 				if cfg.Defaults.Output.PrettyPrint != false {
 					t.Error("Output.PrettyPrint should be false")
 				}
-				if cfg.Defaults.Output.Paging != false {
+				if config := cfg.Defaults.Output; config.Paging == nil || *config.Paging {
 					t.Error("Output.Paging should be false")
 				}
 			},
@@ -1117,3 +1139,59 @@ func TestApplyUserPreferences_InitializeNilDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestPagingEnabled(t *testing.T) {
+	loader := &Loader{}
+
+	tests := []struct {
+		name        string
+		output      *cli.DefaultsOutput
+		preferences *cli.PreferencesOutput
+		want        bool
+	}{
+		{name: "no output defaults", want: true},
+		{name: "output defaults without paging", output: &cli.DefaultsOutput{Format: "yaml"}, want: true},
+		{name: "paging turned off", output: &cli.DefaultsOutput{Paging: boolPtr(false)}, want: false},
+		{
+			name:        "preferences without paging keep the CLI setting",
+			output:      &cli.DefaultsOutput{Paging: boolPtr(false)},
+			preferences: &cli.PreferencesOutput{Format: "yaml"},
+			want:        false,
+		},
+		{
+			name:        "preferences turn paging off",
+			preferences: &cli.PreferencesOutput{Paging: boolPtr(false)},
+			want:        false,
+		},
+		{
+			name:        "preferences turn paging on",
+			output:      &cli.DefaultsOutput{Paging: boolPtr(false)},
+			preferences: &cli.PreferencesOutput{Paging: boolPtr(true)},
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &cli.Config{Defaults: &cli.Defaults{Output: tt.output}}
+			if tt.preferences != nil {
+				cfg = loader.applyUserPreferences(cfg, &cli.UserPreferences{Output: tt.preferences})
+			}
+			if got := PagingEnabled(cfg.Defaults.Output); got != tt.want {
+				t.Errorf("PagingEnabled() = %v, want %v", got, tt.want)
+			}
+
+			// The built-in defaults agree
+			if err := MergeDefaults(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := *cfg.Defaults.Output.Paging; got != tt.want {
+				t.Errorf("Paging after MergeDefaults = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/CliForge/cliforge/pkg/plugin"
 )
 
 // ValidationError represents a configuration validation error.
@@ -71,6 +73,11 @@ func (v *Validator) Validate(config *cli.Config) error {
 		v.validateUpdates(config.Updates)
 	}
 
+	// Validate plugins
+	if config.Plugins != nil {
+		v.validatePlugins(config.Plugins)
+	}
+
 	if len(v.errors) > 0 {
 		return v.errors
 	}
@@ -129,20 +136,32 @@ func (v *Validator) validateMetadata(m *cli.Metadata) {
 
 // validateAPI validates the API section.
 func (v *Validator) validateAPI(a *cli.API) {
-	// OpenAPI URL is required
-	if a.OpenAPIURL == "" {
+	// OpenAPI URL is required unless specs are composed
+	if len(a.Specs) > 0 {
+		if a.OpenAPIURL != "" {
+			v.addError("api.openapi_url", "openapi_url cannot be used with specs")
+		}
+	} else if a.OpenAPIURL == "" {
 		v.addError("api.openapi_url", "openapi_url is required")
 	} else if !v.isValidURLOrFilePath(a.OpenAPIURL) {
 		v.addError("api.openapi_url", "openapi_url must be a valid URL or file path")
 	}
 
-	// Base URL is required
+	// Base URL is required unless every spec has its own
 	if a.BaseURL == "" {
-		v.addError("api.base_url", "base_url is required")
+		if len(a.Specs) == 0 {
+			v.addError("api.base_url", "base_url is required")
+		}
 	} else if !v.isValidURL(a.BaseURL) {
 		v.addError("api.base_url", "base_url must be a valid URL")
 	}
 
+	v.validateSpecs(a)
+
+	if a.Signing != nil {
+		v.validateSigning(a.Signing)
+	}
+
 	// Validate telemetry URL if present
 	if a.TelemetryURL != "" && !v.isValidURL(a.TelemetryURL) {
 		v.addError("api.telemetry_url", "telemetry_url must be a valid URL")
@@ -177,6 +196,77 @@ func (v *Validator) validateAPI(a *cli.API) {
 	}
 }
 
+// validateSpecs validates the specs composed into the CLI.
+func (v *Validator) validateSpecs(a *cli.API) {
+	names := make(map[string]bool)
+	for i, spec := range a.Specs {
+		field := fmt.Sprintf("api.specs[%d]", i)
+		if spec.Name == "" {
+			v.addError(field+".name", "name is required")
+		} else if names[spec.Name] {
+			v.addError(field+".name", fmt.Sprintf("duplicate spec: %s", spec.Name))
+		}
+		names[spec.Name] = true
+
+		if spec.OpenAPIURL == "" {
+			v.addError(field+".openapi_url", "openapi_url is required")
+		} else if !v.isValidURLOrFilePath(spec.OpenAPIURL) {
+			v.addError(field+".openapi_url", "openapi_url must be a valid URL or file path")
+		}
+
+		if spec.BaseURL == "" {
+			if a.BaseURL == "" {
+				v.addError(field+".base_url", "base_url is required when api.base_url is not set")
+			}
+		} else if !v.isValidURL(spec.BaseURL) {
+			v.addError(field+".base_url", "base_url must be a valid URL")
+		}
+
+		if spec.Mount != "" && !isValidMount(spec.Mount) {
+			v.addError(field+".mount", "mount must be lowercase letters, digits and hyphens")
+		}
+
+		if spec.CacheTTL != "" && !v.isValidDuration(spec.CacheTTL) {
+			v.addError(field+".cache_ttl", "cache_ttl must be a valid duration")
+		}
+
+		for _, tag := range spec.Tags {
+			if contains(spec.ExcludeTags, tag) {
+				v.addError(field+".tags", fmt.Sprintf("tag %s is both included and excluded", tag))
+			}
+		}
+
+		if spec.Auth != nil {
+			v.validateAuth(field+".auth", spec.Auth)
+		}
+	}
+}
+
+// validateSigning validates the keys specs are verified with.
+func (v *Validator) validateSigning(s *cli.SpecSigning) {
+	if len(s.PublicKeys) == 0 {
+		v.addError("api.signing.public_keys", "at least one public key is required")
+	}
+	for i, key := range s.PublicKeys {
+		if _, err := openapi.ParsePublicKey(key); err != nil {
+			v.addError(fmt.Sprintf("api.signing.public_keys[%d]", i), err.Error())
+		}
+	}
+}
+
+// isValidMount reports whether a mount is a usable command name.
+func isValidMount(mount string) bool {
+	for i, r := range mount {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case r == '-' && i > 0 && i < len(mount)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // validateDefaults validates the defaults section.
 func (v *Validator) validateDefaults(d *cli.Defaults) {
 	// Validate HTTP defaults
@@ -231,54 +321,7 @@ func (v *Validator) validateDefaults(d *cli.Defaults) {
 func (v *Validator) validateBehaviors(b *cli.Behaviors) {
 	// Validate auth
 	if b.Auth != nil {
-		validAuthTypes := []string{"none", "api_key", "oauth2", "basic"}
-		if b.Auth.Type != "" && !contains(validAuthTypes, b.Auth.Type) {
-			v.addError("behaviors.auth.type", "type must be one of: none, api_key, oauth2, basic")
-		}
-
-		// Validate auth type-specific fields
-		switch b.Auth.Type {
-		case "api_key":
-			if b.Auth.APIKey == nil {
-				v.addError("behaviors.auth.api_key", "api_key configuration is required when type is api_key")
-			} else {
-				if b.Auth.APIKey.Header == "" {
-					v.addError("behaviors.auth.api_key.header", "header is required")
-				}
-				if b.Auth.APIKey.EnvVar == "" {
-					v.addError("behaviors.auth.api_key.env_var", "env_var is required")
-				}
-			}
-		case "oauth2":
-			if b.Auth.OAuth2 == nil {
-				v.addError("behaviors.auth.oauth2", "oauth2 configuration is required when type is oauth2")
-			} else {
-				if b.Auth.OAuth2.ClientID == "" {
-					v.addError("behaviors.auth.oauth2.client_id", "client_id is required")
-				}
-				if b.Auth.OAuth2.AuthURL == "" {
-					v.addError("behaviors.auth.oauth2.auth_url", "auth_url is required")
-				} else if !v.isValidURL(b.Auth.OAuth2.AuthURL) {
-					v.addError("behaviors.auth.oauth2.auth_url", "auth_url must be a valid URL")
-				}
-				if b.Auth.OAuth2.TokenURL == "" {
-					v.addError("behaviors.auth.oauth2.token_url", "token_url is required")
-				} else if !v.isValidURL(b.Auth.OAuth2.TokenURL) {
-					v.addError("behaviors.auth.oauth2.token_url", "token_url must be a valid URL")
-				}
-			}
-		case "basic":
-			if b.Auth.Basic == nil {
-				v.addError("behaviors.auth.basic", "basic configuration is required when type is basic")
-			} else {
-				if b.Auth.Basic.UsernameEnv == "" {
-					v.addError("behaviors.auth.basic.username_env", "username_env is required")
-				}
-				if b.Auth.Basic.PasswordEnv == "" {
-					v.addError("behaviors.auth.basic.password_env", "password_env is required")
-				}
-			}
-		}
+		v.validateAuth("behaviors.auth", b.Auth)
 	}
 
 	// Validate caching
@@ -326,6 +369,58 @@ func (v *Validator) validateBehaviors(b *cli.Behaviors) {
 	}
 }
 
+// validateAuth validates an auth section.
+func (v *Validator) validateAuth(field string, a *cli.AuthBehavior) {
+	validAuthTypes := []string{"none", "api_key", "oauth2", "basic"}
+	if a.Type != "" && !contains(validAuthTypes, a.Type) {
+		v.addError(field+".type", "type must be one of: none, api_key, oauth2, basic")
+	}
+
+	// Validate auth type-specific fields
+	switch a.Type {
+	case "api_key":
+		if a.APIKey == nil {
+			v.addError(field+".api_key", "api_key configuration is required when type is api_key")
+		} else {
+			if a.APIKey.Header == "" {
+				v.addError(field+".api_key.header", "header is required")
+			}
+			if a.APIKey.EnvVar == "" {
+				v.addError(field+".api_key.env_var", "env_var is required")
+			}
+		}
+	case "oauth2":
+		if a.OAuth2 == nil {
+			v.addError(field+".oauth2", "oauth2 configuration is required when type is oauth2")
+		} else {
+			if a.OAuth2.ClientID == "" {
+				v.addError(field+".oauth2.client_id", "client_id is required")
+			}
+			if a.OAuth2.AuthURL == "" {
+				v.addError(field+".oauth2.auth_url", "auth_url is required")
+			} else if !v.isValidURL(a.OAuth2.AuthURL) {
+				v.addError(field+".oauth2.auth_url", "auth_url must be a valid URL")
+			}
+			if a.OAuth2.TokenURL == "" {
+				v.addError(field+".oauth2.token_url", "token_url is required")
+			} else if !v.isValidURL(a.OAuth2.TokenURL) {
+				v.addError(field+".oauth2.token_url", "token_url must be a valid URL")
+			}
+		}
+	case "basic":
+		if a.Basic == nil {
+			v.addError(field+".basic", "basic configuration is required when type is basic")
+		} else {
+			if a.Basic.UsernameEnv == "" {
+				v.addError(field+".basic.username_env", "username_env is required")
+			}
+			if a.Basic.PasswordEnv == "" {
+				v.addError(field+".basic.password_env", "password_env is required")
+			}
+		}
+	}
+}
+
 // validateUpdates validates the updates section.
 func (v *Validator) validateUpdates(u *cli.Updates) {
 	if u.Enabled {
@@ -341,6 +436,39 @@ func (v *Validator) validateUpdates(u *cli.Updates) {
 	}
 }
 
+// validatePlugins validates the plugins section.
+func (v *Validator) validatePlugins(p *cli.Plugins) {
+	names := make(map[string]bool)
+	for i, publisher := range p.Publishers {
+		field := fmt.Sprintf("plugins.publishers[%d]", i)
+		if publisher.Name == "" {
+			v.addError(field+".name", "name is required")
+		} else if names[publisher.Name] {
+			v.addError(field+".name", fmt.Sprintf("duplicate publisher: %s", publisher.Name))
+		}
+		names[publisher.Name] = true
+
+		if publisher.PublicKey == "" {
+			v.addError(field+".public_key", "public_key is required")
+		}
+	}
+
+	if p.RequireSignature && len(p.Publishers) == 0 {
+		v.addError("plugins.require_signature", "require_signature needs at least one publisher")
+	}
+
+	if p.Policy != nil {
+		if _, err := plugin.NewPolicy(p); err != nil {
+			v.addError("plugins.policy", err.Error())
+		}
+		for i, rule := range p.Policy.PreApproved {
+			if rule.Publisher == "" {
+				v.addError(fmt.Sprintf("plugins.policy.pre_approved[%d].publisher", i), "pre-approved plugins must name the publisher that signs them")
+			}
+		}
+	}
+}
+
 // ValidateUserPreferences validates user preferences for overridable settings.
 func (v *Validator) ValidateUserPreferences(prefs *cli.UserPreferences) error {
 	v.errors = make(ValidationErrors, 0)
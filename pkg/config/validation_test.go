@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/CliForge/cliforge/pkg/cli"
@@ -163,6 +164,57 @@ func TestValidator_ValidateAPI(t *testing.T) {
 			wantError: true,
 			errorMsg:  "api.environments",
 		},
+		{
+			name: "valid specs",
+			api: cli.API{
+				Specs: []cli.APISpec{
+					{Name: "accounts", OpenAPIURL: "https://accounts.example.com/openapi.yaml", BaseURL: "https://accounts.example.com"},
+					{Name: "billing", OpenAPIURL: "./billing.yaml", BaseURL: "https://billing.example.com", Mount: "billing", CacheTTL: "1h", Tags: []string{"invoices"}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "specs with openapi_url",
+			api: cli.API{
+				OpenAPIURL: "https://api.example.com/openapi.yaml",
+				BaseURL:    "https://api.example.com",
+				Specs:      []cli.APISpec{{Name: "accounts", OpenAPIURL: "./accounts.yaml"}},
+			},
+			wantError: true,
+			errorMsg:  "api.openapi_url",
+		},
+		{
+			name: "spec without base_url",
+			api: cli.API{
+				Specs: []cli.APISpec{{Name: "accounts", OpenAPIURL: "./accounts.yaml"}},
+			},
+			wantError: true,
+			errorMsg:  "api.specs[0].base_url",
+		},
+		{
+			name: "duplicate spec",
+			api: cli.API{
+				BaseURL: "https://api.example.com",
+				Specs: []cli.APISpec{
+					{Name: "accounts", OpenAPIURL: "./accounts.yaml"},
+					{Name: "accounts", OpenAPIURL: "./accounts-v2.yaml"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "api.specs[1].name",
+		},
+		{
+			name: "invalid spec settings",
+			api: cli.API{
+				BaseURL: "https://api.example.com",
+				Specs: []cli.APISpec{
+					{Name: "accounts", OpenAPIURL: "./accounts.yaml", Mount: "Accounts", CacheTTL: "soon"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "api.specs[0].mount",
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,6 +222,18 @@ func TestValidator_ValidateAPI(t *testing.T) {
 			v := NewValidator()
 			v.validateAPI(&tt.api)
 
+			if tt.wantError {
+				found := false
+				for _, e := range v.errors {
+					if strings.Contains(e.Error(), tt.errorMsg) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected error for %s, got %v", tt.errorMsg, v.errors)
+				}
+			}
+
 			if tt.wantError && len(v.errors) == 0 {
 				t.Error("expected validation error but got none")
 			}
@@ -451,6 +515,100 @@ func TestValidator_ValidateUpdates(t *testing.T) {
 	}
 }
 
+func TestValidator_ValidatePlugins(t *testing.T) {
+	tests := []struct {
+		name      string
+		plugins   *cli.Plugins
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "valid publishers",
+			plugins: &cli.Plugins{
+				Publishers:       []cli.PluginPublisher{{Name: "acme", PublicKey: "MCowBQYDK2VwAyEA"}},
+				RequireSignature: true,
+			},
+			wantError: false,
+		},
+		{
+			name: "missing publisher key",
+			plugins: &cli.Plugins{
+				Publishers: []cli.PluginPublisher{{Name: "acme"}},
+			},
+			wantError: true,
+			errorMsg:  "plugins.publishers[0].public_key",
+		},
+		{
+			name: "duplicate publisher",
+			plugins: &cli.Plugins{
+				Publishers: []cli.PluginPublisher{
+					{Name: "acme", PublicKey: "a"},
+					{Name: "acme", PublicKey: "b"},
+				},
+			},
+			wantError: true,
+			errorMsg:  "plugins.publishers[1].name",
+		},
+		{
+			name:      "signatures required without publishers",
+			plugins:   &cli.Plugins{RequireSignature: true},
+			wantError: true,
+			errorMsg:  "plugins.require_signature",
+		},
+		{
+			name: "valid policy",
+			plugins: &cli.Plugins{Policy: &cli.PluginPolicy{
+				Allow:              []cli.PluginRule{{Name: "deploy", Versions: ">=1.0.0 <2.0.0"}},
+				BlockedPermissions: []string{"execute:*"},
+				PreApproved:        []cli.PluginRule{{Name: "deploy", Publisher: "acme"}},
+			}},
+			wantError: false,
+		},
+		{
+			name: "invalid policy version range",
+			plugins: &cli.Plugins{Policy: &cli.PluginPolicy{
+				Deny: []cli.PluginRule{{Name: "deploy", Versions: "<two"}},
+			}},
+			wantError: true,
+			errorMsg:  "plugins.policy",
+		},
+		{
+			name: "pre-approval without publisher",
+			plugins: &cli.Plugins{Policy: &cli.PluginPolicy{
+				PreApproved: []cli.PluginRule{{Name: "deploy"}},
+			}},
+			wantError: true,
+			errorMsg:  "plugins.policy.pre_approved[0].publisher",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator()
+			v.validatePlugins(tt.plugins)
+
+			if tt.wantError && len(v.errors) == 0 {
+				t.Error("expected validation error but got none")
+			}
+			if !tt.wantError && len(v.errors) > 0 {
+				t.Errorf("unexpected validation errors: %v", v.errors)
+			}
+			if tt.wantError && len(v.errors) > 0 {
+				found := false
+				for _, err := range v.errors {
+					if err.Field == tt.errorMsg {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("expected error field %s, got errors: %v", tt.errorMsg, v.errors)
+				}
+			}
+		})
+	}
+}
+
 func TestValidator_ValidateUserPreferences(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,610 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundler resolves the external $refs of a spec split across files and
+// URLs into one self-contained document.
+//
+// Referenced schemas, parameters, responses and other reusable objects
+// become components of the bundled spec, once per referenced location, and
+// identical objects from different locations share one component. Objects
+// that cannot be components, such as path items, are inlined. Extensions,
+// x-cli-* included, are kept as they are.
+type Bundler struct {
+	// HTTPClient fetches remote documents
+	HTTPClient *http.Client
+	// CacheDir stores fetched remote documents, which are then read from
+	// it instead of the network. Empty disables the cache.
+	CacheDir string
+	// Refresh fetches remote documents even when they are cached. A
+	// cached copy is still used if fetching fails.
+	Refresh bool
+}
+
+// NewBundler creates a new Bundler instance.
+func NewBundler() *Bundler {
+	return &Bundler{
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Bundle loads the spec at location, a file path or URL, and returns it
+// with every external $ref resolved.
+func (b *Bundler) Bundle(ctx context.Context, location string) (map[string]interface{}, error) {
+	base, err := documentURL(location)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &bundleState{
+		bundler: b,
+		ctx:     ctx,
+		root:    base.String(),
+		docs:    make(map[string]map[string]interface{}),
+		refs:    make(map[string]string),
+		names:   make(map[string]map[string]bool),
+		hashes:  make(map[string]string),
+		inlined: make(map[string]bool),
+	}
+	root, err := s.document(base)
+	if err != nil {
+		return nil, err
+	}
+	s.swagger2 = root["swagger"] != nil
+
+	bundled, err := s.walk(root, base, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.bundled = bundled.(map[string]interface{})
+	s.indexComponents()
+
+	// Adding a component can find more
+	for len(s.pending) > 0 {
+		c := s.pending[0]
+		s.pending = s.pending[1:]
+		if err := s.addComponent(c); err != nil {
+			return nil, err
+		}
+	}
+	return s.bundled, nil
+}
+
+// BundleFile bundles the spec at location and returns it encoded as YAML,
+// or as JSON when asJSON is set.
+func (b *Bundler) BundleFile(ctx context.Context, location string, asJSON bool) ([]byte, error) {
+	doc, err := b.Bundle(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	if asJSON {
+		return json.MarshalIndent(doc, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode bundled spec: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// bundleState is the state of one Bundle call.
+type bundleState struct {
+	bundler  *Bundler
+	ctx      context.Context
+	root     string
+	swagger2 bool
+	bundled  map[string]interface{}
+
+	// docs are the loaded documents by URL
+	docs map[string]map[string]interface{}
+	// refs maps resolved external refs to the local refs replacing them
+	refs map[string]string
+	// names are the component names taken, by kind
+	names map[string]map[string]bool
+	// hashes maps the content of components to their local refs
+	hashes map[string]string
+	// inlined are the refs being inlined, to detect cycles
+	inlined map[string]bool
+	// pending are the components found but not yet added
+	pending []*pendingComponent
+}
+
+// pendingComponent is a referenced object to be added as a component.
+type pendingComponent struct {
+	kind  string
+	name  string
+	ref   string
+	value interface{}
+	base  *url.URL
+}
+
+// walk returns a copy of node with its external refs replaced. base is
+// the URL of the document node is from, and at the path of node in it.
+func (s *bundleState) walk(node interface{}, base *url.URL, at []string) (interface{}, error) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			return s.resolveRef(v, ref, base, at)
+		}
+		// Keys are walked in order so components are named the same way
+		// every time
+		out := make(map[string]interface{}, len(v))
+		for _, key := range sortedKeys(v) {
+			walked, err := s.walk(v[key], base, append(at[:len(at):len(at)], key))
+			if err != nil {
+				return nil, err
+			}
+			out[key] = walked
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			walked, err := s.walk(value, base, append(at[:len(at):len(at)], fmt.Sprint(i)))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = walked
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// resolveRef returns the replacement of a $ref object.
+func (s *bundleState) resolveRef(node map[string]interface{}, ref string, base *url.URL, at []string) (interface{}, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	target := base.ResolveReference(parsed)
+	doc := *target
+	doc.Fragment = ""
+
+	// Refs within the root document stay local
+	if doc.String() == s.root {
+		out := make(map[string]interface{}, len(node))
+		for key, value := range node {
+			out[key] = value
+		}
+		out["$ref"] = "#" + target.Fragment
+		return out, nil
+	}
+
+	key := target.String()
+	if local, ok := s.refs[key]; ok {
+		return map[string]interface{}{"$ref": local}, nil
+	}
+
+	document, err := s.document(&doc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := resolvePointer(document, target.Fragment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve $ref %q: %w", key, err)
+	}
+
+	kind := componentKind(target.Fragment, at)
+	if kind == "" {
+		// Not a component: inline it
+		if s.inlined[key] {
+			return nil, fmt.Errorf("circular $ref %q cannot be inlined", key)
+		}
+		s.inlined[key] = true
+		defer delete(s.inlined, key)
+		return s.walk(value, &doc, at)
+	}
+
+	c := &pendingComponent{kind: kind, value: value, base: &doc}
+	c.name = s.componentName(kind, componentBaseName(target))
+	c.ref = s.componentRef(kind, c.name)
+	s.refs[key] = c.ref
+	s.pending = append(s.pending, c)
+	return map[string]interface{}{"$ref": c.ref}, nil
+}
+
+// addComponent walks a pending component and adds it to the bundled
+// spec, or points its refs at an identical component already added.
+func (s *bundleState) addComponent(c *pendingComponent) error {
+	walked, err := s.walk(c.value, c.base, []string{"components", c.kind, c.name})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(walked)
+	if err != nil {
+		return fmt.Errorf("failed to encode component %s: %w", c.name, err)
+	}
+	sum := sha256.Sum256(append([]byte(c.kind+"\x00"), data...))
+	hash := hex.EncodeToString(sum[:])
+	if existing, ok := s.hashes[hash]; ok && !s.refersTo(walked, c.ref) {
+		delete(s.names[c.kind], c.name)
+		for key, ref := range s.refs {
+			if ref == c.ref {
+				s.refs[key] = existing
+			}
+		}
+		s.redirect(s.bundled, c.ref, existing)
+		return nil
+	}
+	s.hashes[hash] = c.ref
+
+	s.components(c.kind)[c.name] = walked
+	return nil
+}
+
+// indexComponents records the content of the root document's components,
+// so identical referenced objects reuse them.
+func (s *bundleState) indexComponents() {
+	for _, kind := range []string{"schemas", "parameters", "responses", "requestBodies", "headers", "examples", "links", "callbacks", "securitySchemes"} {
+		for name, value := range s.componentsIn(s.bundled, kind) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				continue
+			}
+			sum := sha256.Sum256(append([]byte(kind+"\x00"), data...))
+			s.hashes[hex.EncodeToString(sum[:])] = s.componentRef(kind, name)
+		}
+	}
+}
+
+// refersTo reports whether node contains a $ref to ref, as recursive
+// components do.
+func (s *bundleState) refersTo(node interface{}, ref string) bool {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if v["$ref"] == ref {
+			return true
+		}
+		for _, value := range v {
+			if s.refersTo(value, ref) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if s.refersTo(value, ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redirect replaces $refs to from with $refs to to.
+func (s *bundleState) redirect(node interface{}, from, to string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if v["$ref"] == from {
+			v["$ref"] = to
+		}
+		for _, value := range v {
+			s.redirect(value, from, to)
+		}
+	case []interface{}:
+		for _, value := range v {
+			s.redirect(value, from, to)
+		}
+	}
+}
+
+// components returns the components of a kind in the bundled spec,
+// creating them if needed.
+func (s *bundleState) components(kind string) map[string]interface{} {
+	parent := s.bundled
+	for _, key := range s.componentPath(kind) {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			parent[key] = child
+		}
+		parent = child
+	}
+	return parent
+}
+
+// componentPath returns where components of a kind are in the spec:
+// under components, or at the top of a Swagger 2.0 spec.
+func (s *bundleState) componentPath(kind string) []string {
+	if s.swagger2 {
+		if kind == "schemas" {
+			return []string{"definitions"}
+		}
+		return []string{kind}
+	}
+	return []string{"components", kind}
+}
+
+// componentRef returns the local $ref of a component.
+func (s *bundleState) componentRef(kind, name string) string {
+	return "#/" + strings.Join(s.componentPath(kind), "/") + "/" + escapePointer(name)
+}
+
+// componentName takes a name for a component of a kind that no other
+// component has, based on name.
+func (s *bundleState) componentName(kind, name string) string {
+	taken, ok := s.names[kind]
+	if !ok {
+		taken = make(map[string]bool)
+		for existing := range s.componentsIn(s.docs[s.root], kind) {
+			taken[existing] = true
+		}
+		s.names[kind] = taken
+	}
+
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// componentsIn returns the components of a kind in a document.
+func (s *bundleState) componentsIn(doc map[string]interface{}, kind string) map[string]interface{} {
+	var parent interface{} = doc
+	for _, key := range s.componentPath(kind) {
+		m, _ := parent.(map[string]interface{})
+		parent = m[key]
+	}
+	components, _ := parent.(map[string]interface{})
+	return components
+}
+
+// document returns the document at a URL, loading it once.
+func (s *bundleState) document(u *url.URL) (map[string]interface{}, error) {
+	key := u.String()
+	if doc, ok := s.docs[key]; ok {
+		return doc, nil
+	}
+
+	data, err := s.bundler.read(s.ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", displayURL(u), err)
+	}
+	s.docs[key] = doc
+	return doc, nil
+}
+
+// read reads a document from a file or URL.
+func (b *Bundler) read(ctx context.Context, u *url.URL) ([]byte, error) {
+	switch u.Scheme {
+	case "file":
+		data, err := os.ReadFile(u.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return data, nil
+	case "http", "https":
+		return b.fetch(ctx, u.String())
+	default:
+		return nil, fmt.Errorf("unsupported $ref scheme: %s", u.Scheme)
+	}
+}
+
+// fetch fetches a remote document through the cache directory.
+func (b *Bundler) fetch(ctx context.Context, docURL string) ([]byte, error) {
+	var cachePath string
+	if b.CacheDir != "" {
+		sum := sha256.Sum256([]byte(docURL))
+		cachePath = filepath.Join(b.CacheDir, hex.EncodeToString(sum[:]))
+		if !b.Refresh {
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	data, err := b.download(ctx, docURL)
+	if err != nil {
+		if cachePath != "" {
+			if cached, cacheErr := os.ReadFile(cachePath); cacheErr == nil {
+				return cached, nil
+			}
+		}
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(b.CacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		if err := os.WriteFile(cachePath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to cache %s: %w", docURL, err)
+		}
+	}
+	return data, nil
+}
+
+// download fetches a remote document.
+func (b *Bundler) download(ctx context.Context, docURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml, application/x-yaml, text/yaml")
+	req.Header.Set("User-Agent", "CliForge/0.9.0 OpenAPI Bundler")
+
+	client := b.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", docURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", docURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", docURL, err)
+	}
+	return data, nil
+}
+
+// documentURL returns the URL of a spec location, a URL or file path.
+func documentURL(location string) (*url.URL, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %w", err)
+		}
+		return u, nil
+	}
+
+	abs, err := filepath.Abs(strings.TrimPrefix(location, "file://"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}, nil
+}
+
+// displayURL returns a URL as the user gave it: file URLs as paths.
+func displayURL(u *url.URL) string {
+	if u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	return u.String()
+}
+
+// resolvePointer returns the value at a JSON pointer in a document.
+func resolvePointer(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" || pointer == "/" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	current := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+			current = next
+		case []interface{}:
+			var i int
+			if _, err := fmt.Sscanf(token, "%d", &i); err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("%q not found", token)
+		}
+	}
+	return current, nil
+}
+
+// componentKinds maps the keys whose values are maps of reusable objects
+// to the kind of component the objects are.
+var componentKinds = map[string]string{
+	"schemas":         "schemas",
+	"definitions":     "schemas",
+	"$defs":           "schemas",
+	"properties":      "schemas",
+	"parameters":      "parameters",
+	"responses":       "responses",
+	"requestBodies":   "requestBodies",
+	"headers":         "headers",
+	"examples":        "examples",
+	"links":           "links",
+	"callbacks":       "callbacks",
+	"securitySchemes": "securitySchemes",
+}
+
+// componentKind returns the kind of component a referenced object is,
+// from its location in the referenced document or, failing that, where
+// the $ref is. It returns "" for objects that are inlined.
+func componentKind(fragment string, at []string) string {
+	tokens := strings.Split(strings.TrimPrefix(fragment, "/"), "/")
+	if len(tokens) == 3 && tokens[0] == "components" {
+		return componentKinds[tokens[1]]
+	}
+	if len(tokens) == 2 && (tokens[0] == "definitions" || tokens[0] == "$defs") {
+		return "schemas"
+	}
+
+	if len(at) == 0 {
+		return ""
+	}
+	last := at[len(at)-1]
+	switch last {
+	case "schema", "items", "not", "additionalProperties", "contains", "propertyNames":
+		return "schemas"
+	case "requestBody":
+		return "requestBodies"
+	}
+	if len(at) < 2 {
+		return ""
+	}
+	switch parent := at[len(at)-2]; parent {
+	case "allOf", "oneOf", "anyOf", "prefixItems", "patternProperties":
+		return "schemas"
+	default:
+		// Inside paths, the keys of a path item are methods, not
+		// components
+		if len(at) == 2 && at[0] == "paths" {
+			return ""
+		}
+		return componentKinds[parent]
+	}
+}
+
+// componentNamePattern matches the characters not allowed in component
+// names.
+var componentNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// componentBaseName returns the name a referenced object is given as a
+// component: the last token of its JSON pointer, or its file name.
+func componentBaseName(target *url.URL) string {
+	name := ""
+	if target.Fragment != "" {
+		tokens := strings.Split(target.Fragment, "/")
+		name = tokens[len(tokens)-1]
+	}
+	if name == "" {
+		name = path.Base(target.Path)
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	name = componentNamePattern.ReplaceAllString(name, "_")
+	if name == "" || name == "_" {
+		name = "Component"
+	}
+	return name
+}
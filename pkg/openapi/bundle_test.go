@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// externalRefs returns the $refs of a document that are not local.
+func externalRefs(node interface{}) []string {
+	var refs []string
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+			refs = append(refs, ref)
+		}
+		for _, value := range v {
+			refs = append(refs, externalRefs(value)...)
+		}
+	case []interface{}:
+		for _, value := range v {
+			refs = append(refs, externalRefs(value)...)
+		}
+	}
+	return refs
+}
+
+func TestBundler_Bundle(t *testing.T) {
+	ctx := context.Background()
+
+	data, err := NewBundler().BundleFile(ctx, "testdata/bundle/openapi.yaml", false)
+	if err != nil {
+		t.Fatalf("BundleFile() error = %v", err)
+	}
+
+	doc, err := decodeDocument(data)
+	if err != nil {
+		t.Fatalf("Failed to decode bundled spec: %v", err)
+	}
+	if refs := externalRefs(doc); len(refs) > 0 {
+		t.Errorf("Expected no external refs, got %v", refs)
+	}
+
+	spec, err := NewParser().Parse(ctx, data)
+	if err != nil {
+		t.Fatalf("Bundled spec does not parse: %v\n%s", err, data)
+	}
+
+	// Shared schemas are components, identical ones once
+	var schemas []string
+	for name := range spec.Spec.Components.Schemas {
+		schemas = append(schemas, name)
+	}
+	if len(schemas) != 2 || spec.Spec.Components.Schemas["pet"] == nil || spec.Spec.Components.Schemas["Error"] == nil {
+		t.Errorf("Expected the pet and Error schemas, got %v", schemas)
+	}
+	if spec.Spec.Components.Parameters["PetId"] == nil {
+		t.Error("Expected the PetId parameter component")
+	}
+
+	// Recursive schemas refer to their component
+	pet := spec.Spec.Components.Schemas["pet"].Value
+	if pet.Properties["parent"].Ref != "#/components/schemas/pet" {
+		t.Errorf("Expected parent to refer to pet, got %q", pet.Properties["parent"].Ref)
+	}
+
+	// Extensions are kept, including in inlined path items
+	operations, err := spec.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases := map[string][]string{}
+	for _, op := range operations {
+		aliases[op.OperationID] = op.CLIAliases
+	}
+	if len(operations) != 2 || strings.Join(aliases["listPets"], ",") != "ls" {
+		t.Errorf("Expected listPets with its aliases, got %v", aliases)
+	}
+	if spec.Extensions.Config == nil || spec.Extensions.Config.Name != "petstore" {
+		t.Error("Expected x-cli-config to be kept")
+	}
+}
+
+func TestBundler_RemoteRefs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"Owner": {"type": "object", "properties": {"name": {"type": "string"}}}}`))
+	}))
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "openapi.yaml")
+	spec := `openapi: 3.0.3
+info: {title: Owners, version: 1.0.0}
+paths:
+  /owners:
+    get:
+      operationId: listOwners
+      responses:
+        "200":
+          description: Owners
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "` + server.URL + `/schemas.json#/Owner"
+`
+	if err := os.WriteFile(root, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundler := NewBundler()
+	bundler.CacheDir = filepath.Join(dir, "cache")
+	if _, err := bundler.Bundle(context.Background(), root); err != nil {
+		t.Fatalf("Bundle() error = %v", err)
+	}
+
+	// Cached documents are read offline
+	server.Close()
+	doc, err := bundler.Bundle(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Bundle() offline error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	if components["Owner"] == nil {
+		t.Errorf("Expected the Owner schema, got %v", components)
+	}
+
+	// Without the cache, the unreachable server is an error
+	if _, err := NewBundler().Bundle(context.Background(), root); err == nil {
+		t.Error("Expected an error for an unreachable $ref")
+	}
+}
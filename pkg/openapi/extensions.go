@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -17,6 +18,8 @@ type SpecExtensions struct {
 	Changelog []ChangelogEntry
 	// Deprecations
 	Deprecations []*DeprecationInfo
+	// Plugin commands from the root-level x-cli-plugin
+	Plugins []*CLIPlugin
 }
 
 // CLIConfig represents the x-cli-config global extension.
@@ -29,6 +32,7 @@ type CLIConfig struct {
 	Output      *OutputSettings        `json:"output"`
 	Features    *FeatureSettings       `json:"features"`
 	Cache       *CacheSettings         `json:"cache"`
+	Flags       *FlagSettings          `json:"flags"`
 	Raw         map[string]interface{} `json:"-"` // Raw extension data
 }
 
@@ -66,6 +70,12 @@ type FeatureSettings struct {
 	Telemetry       bool `json:"telemetry"`
 }
 
+// FlagSettings controls how request body schemas become flags.
+type FlagSettings struct {
+	BodyDepth int    `json:"body-depth"` // levels of nested objects with dotted flags
+	Variants  string `json:"variants"`   // oneOf handling: selector, subcommands
+}
+
 // CacheSettings contains cache configuration.
 type CacheSettings struct {
 	Enabled  bool   `json:"enabled"`
@@ -183,11 +193,23 @@ type BackoffConfig struct {
 
 // CLIOutput represents the x-cli-output extension.
 type CLIOutput struct {
-	Format         string       `json:"format"`
-	SuccessMessage string       `json:"success-message"`
-	ErrorMessage   string       `json:"error-message"`
-	WatchStatus    bool         `json:"watch-status"`
-	Table          *TableConfig `json:"table"`
+	Format         string        `json:"format"`
+	SuccessMessage string        `json:"success-message"`
+	ErrorMessage   string        `json:"error-message"`
+	WatchStatus    bool          `json:"watch-status"`
+	Table          *TableConfig  `json:"table"`
+	Stream         *OutputStream `json:"stream"`
+}
+
+// OutputStream enables streaming output for large list responses: the
+// items are formatted as they are received instead of after the whole
+// response was read.
+type OutputStream struct {
+	// ItemsPath is the dotted path of the items array in the response,
+	// like "data.items". Empty means the response is the array.
+	ItemsPath string `json:"items-path"`
+	// SampleSize is how many items table output sizes its columns by.
+	SampleSize int `json:"sample-size"`
 }
 
 // TableConfig defines table output configuration.
@@ -205,17 +227,130 @@ type TableColumn struct {
 
 // CLIWorkflow represents the x-cli-workflow extension.
 type CLIWorkflow struct {
-	Steps  []*WorkflowStep `json:"steps"`
-	Output *WorkflowOutput `json:"output"`
+	Steps    []*WorkflowStep   `json:"steps"`
+	Output   *WorkflowOutput   `json:"output"`
+	Settings *WorkflowSettings `json:"settings"`
+
+	// Outputs maps output names to expressions returned to call steps.
+	Outputs map[string]string `json:"outputs"`
+
+	// Workflows holds the named workflows call steps can run.
+	Workflows map[string]*CLIWorkflow `json:"workflows"`
+}
+
+// WorkflowSettings defines workflow-level execution settings.
+type WorkflowSettings struct {
+	ParallelExecution bool               `json:"parallel-execution"`
+	FailFast          bool               `json:"fail-fast"`
+	Timeout           int                `json:"timeout"` // seconds
+	DryRunSupported   bool               `json:"dry-run-supported"`
+	MaxConcurrency    int                `json:"max-concurrency"`
+	RateLimit         *WorkflowRateLimit `json:"rate-limit"`
+
+	// RollbackRetry is the retry policy of rollback actions without
+	// their own.
+	RollbackRetry *WorkflowRetry `json:"rollback-retry"`
+}
+
+// WorkflowRateLimit defines the per-host rate limit of a workflow.
+type WorkflowRateLimit struct {
+	RequestsPerSecond float64 `json:"requests-per-second"`
+	Burst             int     `json:"burst"`
 }
 
 // WorkflowStep represents a single workflow step.
 type WorkflowStep struct {
-	ID        string           `json:"id"`
-	Request   *WorkflowRequest `json:"request"`
-	Condition string           `json:"condition"`
-	ForEach   string           `json:"foreach"`
-	As        string           `json:"as"`
+	ID          string            `json:"id"`
+	Type        string            `json:"type"` // api-call (default), plugin, loop, parallel, prompt, set, assert, call
+	Description string            `json:"description"`
+	DependsOn   []string          `json:"depends-on"`
+	Required    bool              `json:"required"`
+	Output      map[string]string `json:"output"`
+	Request     *WorkflowRequest  `json:"request"`
+	Condition   string            `json:"condition"`
+
+	// ForEach and As are the collection a loop step iterates over and the
+	// variable holding the current item.
+	ForEach string `json:"foreach"`
+	As      string `json:"as"`
+
+	// Steps are the steps a loop step runs for each item, or a parallel
+	// step runs concurrently. Parallel runs loop iterations concurrently,
+	// and MaxConcurrency limits the iterations or steps in flight.
+	Steps          []*WorkflowStep `json:"steps"`
+	Parallel       bool            `json:"parallel"`
+	MaxConcurrency int             `json:"max-concurrency"`
+
+	Plugin *WorkflowPlugin `json:"plugin"`
+	Prompt *WorkflowPrompt `json:"prompt"`
+	Set    *WorkflowSet    `json:"set"`
+	Assert *WorkflowAssert `json:"assert"`
+	Call   *WorkflowCall   `json:"call"`
+
+	// Retry is the retry policy of the step.
+	Retry *WorkflowRetry `json:"retry"`
+
+	// Rollback is the action undoing the step when the workflow fails.
+	Rollback *WorkflowStep `json:"rollback"`
+}
+
+// WorkflowRetry defines how a workflow step or rollback action is retried.
+type WorkflowRetry struct {
+	MaxAttempts     int                   `json:"max-attempts"`
+	Backoff         *WorkflowBackoff      `json:"backoff"`
+	RetryableErrors []*WorkflowErrorMatch `json:"retryable-errors"`
+}
+
+// WorkflowBackoff defines the delay between retries.
+type WorkflowBackoff struct {
+	Type            string  `json:"type"`             // fixed, linear, exponential
+	InitialInterval int     `json:"initial-interval"` // seconds
+	Multiplier      float64 `json:"multiplier"`
+	MaxInterval     int     `json:"max-interval"` // seconds
+}
+
+// WorkflowErrorMatch defines an error that is retried. An HTTP status of
+// 5xx is read as 500 and matches every server error.
+type WorkflowErrorMatch struct {
+	HTTPStatus *int   `json:"http-status"`
+	ErrorType  string `json:"error-type"`
+}
+
+// WorkflowPlugin defines a plugin step running a plugin command.
+type WorkflowPlugin struct {
+	Plugin  string                 `json:"plugin"`
+	Command string                 `json:"command"`
+	Input   map[string]interface{} `json:"input"`
+}
+
+// WorkflowPrompt defines a prompt step asking the user for input or
+// approval.
+type WorkflowPrompt struct {
+	Type       string      `json:"type"` // text, password, select, confirm, number, approval
+	Message    string      `json:"message"`
+	Variable   string      `json:"variable"`
+	Default    interface{} `json:"default"`
+	Options    []string    `json:"options"`
+	Validation string      `json:"validation"`
+	Required   bool        `json:"required"`
+}
+
+// WorkflowSet defines a set step assigning expressions to variables.
+type WorkflowSet struct {
+	Variables map[string]string `json:"variables"`
+}
+
+// WorkflowAssert defines an assert step failing when its condition is
+// false.
+type WorkflowAssert struct {
+	Condition string `json:"condition"`
+	Message   string `json:"message"`
+}
+
+// WorkflowCall defines a call step running a named workflow.
+type WorkflowCall struct {
+	Workflow string                 `json:"workflow"`
+	Inputs   map[string]interface{} `json:"inputs"`
 }
 
 // WorkflowRequest defines an HTTP request in a workflow.
@@ -251,13 +386,19 @@ type CLISecret struct {
 	EnvVar    string `json:"env-var"`
 }
 
-// CLIPlugin represents the x-cli-plugin extension.
+// CLIPlugin represents a command declared by the root-level x-cli-plugin
+// extension.
+//
+// Command is the space-separated path of the command in the CLI (defaults
+// to Name). The command runs Executable with Args followed by the user's
+// arguments; without an Executable it runs the installed plugin called Name.
 type CLIPlugin struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Command     string   `json:"command"`
 	Executable  string   `json:"executable"`
 	Args        []string `json:"args"`
+	Aliases     []string `json:"aliases"`
 }
 
 // CLIFileInput represents the x-cli-file-input extension.
@@ -337,6 +478,15 @@ func ParseSpecExtensions(spec *openapi3.T) (*SpecExtensions, error) {
 		extensions.Config = config
 	}
 
+	// Parse x-cli-plugin
+	if pluginData, ok := spec.Extensions["x-cli-plugin"]; ok {
+		plugins, err := parseCLIPlugins(pluginData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse x-cli-plugin: %w", err)
+		}
+		extensions.Plugins = plugins
+	}
+
 	// Parse x-cli-changelog
 	if changelogData, ok := spec.Info.Extensions["x-cli-changelog"]; ok {
 		changelog, err := parseChangelog(changelogData)
@@ -365,6 +515,69 @@ func ParseSpecExtensions(spec *openapi3.T) (*SpecExtensions, error) {
 	return extensions, nil
 }
 
+// parseCLIPlugins parses the root-level x-cli-plugin extension, which is a
+// single command or a list of commands.
+func parseCLIPlugins(data interface{}) ([]*CLIPlugin, error) {
+	var items []interface{}
+	switch v := data.(type) {
+	case []interface{}:
+		items = v
+	case map[string]interface{}:
+		items = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("x-cli-plugin must be an object or a list of objects")
+	}
+
+	plugins := make([]*CLIPlugin, 0, len(items))
+	for i, item := range items {
+		pluginMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("plugin %d must be an object", i)
+		}
+
+		plugin := CLIPlugin{}
+		if name, ok := pluginMap["name"].(string); ok {
+			plugin.Name = name
+		}
+		if desc, ok := pluginMap["description"].(string); ok {
+			plugin.Description = desc
+		}
+		if command, ok := pluginMap["command"].(string); ok {
+			plugin.Command = command
+		}
+		if executable, ok := pluginMap["executable"].(string); ok {
+			plugin.Executable = executable
+		}
+		if args, ok := pluginMap["args"].([]interface{}); ok {
+			for _, arg := range args {
+				if str, ok := arg.(string); ok {
+					plugin.Args = append(plugin.Args, str)
+				}
+			}
+		}
+		if aliases, ok := pluginMap["aliases"].([]interface{}); ok {
+			for _, alias := range aliases {
+				if str, ok := alias.(string); ok {
+					plugin.Aliases = append(plugin.Aliases, str)
+				}
+			}
+		}
+
+		if plugin.Command == "" {
+			plugin.Command = plugin.Name
+		}
+		if len(strings.Fields(plugin.Command)) == 0 {
+			return nil, fmt.Errorf("plugin %d: name or command is required", i)
+		}
+		if plugin.Executable == "" && plugin.Name == "" {
+			return nil, fmt.Errorf("plugin %d: executable or name is required", i)
+		}
+		plugins = append(plugins, &plugin)
+	}
+
+	return plugins, nil
+}
+
 // parseCLIConfig parses the x-cli-config extension.
 func parseCLIConfig(data interface{}) (*CLIConfig, error) {
 	configMap, ok := data.(map[string]interface{})
@@ -465,6 +678,17 @@ func parseCLIConfig(data interface{}) (*CLIConfig, error) {
 		}
 	}
 
+	// Parse flags
+	if flagsData, ok := configMap["flags"].(map[string]interface{}); ok {
+		config.Flags = &FlagSettings{}
+		if depth, ok := flagsData["body-depth"].(float64); ok {
+			config.Flags.BodyDepth = int(depth)
+		}
+		if variants, ok := flagsData["variants"].(string); ok {
+			config.Flags.Variants = variants
+		}
+	}
+
 	return config, nil
 }
 
@@ -856,65 +1080,83 @@ func parseCLIOutput(data map[string]interface{}) (*CLIOutput, error) {
 		}
 	}
 
+	if stream, ok := data["stream"].(map[string]interface{}); ok {
+		output.Stream = &OutputStream{}
+		if itemsPath, ok := stream["items-path"].(string); ok {
+			output.Stream.ItemsPath = itemsPath
+		}
+		if sampleSize, ok := stream["sample-size"].(float64); ok {
+			output.Stream.SampleSize = int(sampleSize)
+		}
+	} else if stream, ok := data["stream"].(bool); ok && stream {
+		output.Stream = &OutputStream{}
+	}
+
 	return output, nil
 }
 
-// parseCLIWorkflow parses the x-cli-workflow extension.
-func parseCLIWorkflow(data map[string]interface{}) (*CLIWorkflow, error) {
-	workflow := &CLIWorkflow{}
+// parseCLIWatch parses the x-cli-watch extension.
+func parseCLIWatch(data map[string]interface{}) (*CLIWatch, error) {
+	watch := &CLIWatch{}
 
-	if steps, ok := data["steps"].([]interface{}); ok {
-		for _, stepData := range steps {
-			stepMap, ok := stepData.(map[string]interface{})
-			if !ok {
-				continue
-			}
+	if enabled, ok := data["enabled"].(bool); ok {
+		watch.Enabled = enabled
+	}
+	if watchType, ok := data["type"].(string); ok {
+		watch.Type = watchType
+	}
+	if endpoint, ok := data["endpoint"].(string); ok {
+		watch.Endpoint = endpoint
+	}
 
-			step := &WorkflowStep{}
-			if id, ok := stepMap["id"].(string); ok {
-				step.ID = id
-			}
-			if condition, ok := stepMap["condition"].(string); ok {
-				step.Condition = condition
-			}
-			if forEach, ok := stepMap["foreach"].(string); ok {
-				step.ForEach = forEach
-			}
-			if as, ok := stepMap["as"].(string); ok {
-				step.As = as
+	if events, ok := data["events"].([]interface{}); ok {
+		for _, event := range events {
+			if str, ok := event.(string); ok {
+				watch.Events = append(watch.Events, str)
 			}
+		}
+	}
 
-			if request, ok := stepMap["request"].(map[string]interface{}); ok {
-				step.Request = &WorkflowRequest{}
-				if method, ok := request["method"].(string); ok {
-					step.Request.Method = method
-				}
-				if url, ok := request["url"].(string); ok {
-					step.Request.URL = url
+	if exitOn, ok := data["exit-on"].([]interface{}); ok {
+		for _, condData := range exitOn {
+			if condMap, ok := condData.(map[string]interface{}); ok {
+				cond := &ExitCondition{}
+				if event, ok := condMap["event"].(string); ok {
+					cond.Event = event
 				}
-				if headers, ok := request["headers"].(map[string]interface{}); ok {
-					step.Request.Headers = make(map[string]string)
-					for k, v := range headers {
-						if str, ok := v.(string); ok {
-							step.Request.Headers[k] = str
-						}
-					}
+				if condition, ok := condMap["condition"].(string); ok {
+					cond.Condition = condition
 				}
-				if body, ok := request["body"].(map[string]interface{}); ok {
-					step.Request.Body = body
-				}
-				if query, ok := request["query"].(map[string]interface{}); ok {
-					step.Request.Query = make(map[string]string)
-					for k, v := range query {
-						if str, ok := v.(string); ok {
-							step.Request.Query[k] = str
-						}
-					}
+				if message, ok := condMap["message"].(string); ok {
+					cond.Message = message
 				}
+				watch.ExitConditions = append(watch.ExitConditions, cond)
 			}
+		}
+	}
 
-			workflow.Steps = append(workflow.Steps, step)
+	if reconnect, ok := data["reconnect"].(map[string]interface{}); ok {
+		watch.Reconnect = &ReconnectConfig{}
+		if enabled, ok := reconnect["enabled"].(bool); ok {
+			watch.Reconnect.Enabled = enabled
 		}
+		if maxAttempts, ok := reconnect["max-attempts"].(float64); ok {
+			watch.Reconnect.MaxAttempts = int(maxAttempts)
+		}
+		if interval, ok := reconnect["interval"].(float64); ok {
+			watch.Reconnect.IntervalSecs = int(interval)
+		}
+	}
+
+	return watch, nil
+}
+
+// parseCLIWorkflow parses the x-cli-workflow extension.
+func parseCLIWorkflow(data map[string]interface{}) (*CLIWorkflow, error) {
+	workflow := &CLIWorkflow{}
+
+	if steps, ok := data["steps"].([]interface{}); ok {
+		workflow.Steps = parseWorkflowSteps(steps)
 	}
 
 	if output, ok := data["output"].(map[string]interface{}); ok {
@@ -927,9 +1169,300 @@ func parseCLIWorkflow(data map[string]interface{}) (*CLIWorkflow, error) {
 		}
 	}
 
+	if settings, ok := data["settings"].(map[string]interface{}); ok {
+		workflow.Settings = parseWorkflowSettings(settings)
+	}
+
+	if outputs, ok := data["outputs"].(map[string]interface{}); ok {
+		workflow.Outputs = parseStringMap(outputs)
+	}
+
+	if workflows, ok := data["workflows"].(map[string]interface{}); ok {
+		workflow.Workflows = make(map[string]*CLIWorkflow, len(workflows))
+		for name, workflowData := range workflows {
+			workflowMap, ok := workflowData.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("workflow %s must be an object", name)
+			}
+			named, err := parseCLIWorkflow(workflowMap)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s: %w", name, err)
+			}
+			workflow.Workflows[name] = named
+		}
+	}
+
 	return workflow, nil
 }
 
+// parseWorkflowSteps parses a list of x-cli-workflow steps.
+func parseWorkflowSteps(steps []interface{}) []*WorkflowStep {
+	var parsed []*WorkflowStep
+	for _, stepData := range steps {
+		stepMap, ok := stepData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parsed = append(parsed, parseWorkflowStep(stepMap))
+	}
+	return parsed
+}
+
+// parseWorkflowStep parses a step of the x-cli-workflow extension.
+func parseWorkflowStep(stepMap map[string]interface{}) *WorkflowStep {
+	step := &WorkflowStep{}
+	if id, ok := stepMap["id"].(string); ok {
+		step.ID = id
+	}
+	if stepType, ok := stepMap["type"].(string); ok {
+		step.Type = stepType
+	}
+	if description, ok := stepMap["description"].(string); ok {
+		step.Description = description
+	}
+	if dependsOn, ok := stepMap["depends-on"].([]interface{}); ok {
+		for _, dep := range dependsOn {
+			if str, ok := dep.(string); ok {
+				step.DependsOn = append(step.DependsOn, str)
+			}
+		}
+	}
+	if required, ok := stepMap["required"].(bool); ok {
+		step.Required = required
+	}
+	if output, ok := stepMap["output"].(map[string]interface{}); ok {
+		step.Output = parseStringMap(output)
+	}
+	if condition, ok := stepMap["condition"].(string); ok {
+		step.Condition = condition
+	}
+	// A loop's collection and item variable, also spelled as in the
+	// workflow engine
+	if forEach, ok := stepMap["foreach"].(string); ok {
+		step.ForEach = forEach
+	} else if collection, ok := stepMap["collection"].(string); ok {
+		step.ForEach = collection
+	}
+	if as, ok := stepMap["as"].(string); ok {
+		step.As = as
+	} else if iterator, ok := stepMap["iterator"].(string); ok {
+		step.As = iterator
+	}
+	if steps, ok := stepMap["steps"].([]interface{}); ok {
+		step.Steps = parseWorkflowSteps(steps)
+	}
+	if parallel, ok := stepMap["parallel"].(bool); ok {
+		step.Parallel = parallel
+	}
+	if maxConcurrency, ok := stepMap["max-concurrency"].(float64); ok {
+		step.MaxConcurrency = int(maxConcurrency)
+	}
+
+	if request, ok := stepMap["request"].(map[string]interface{}); ok {
+		step.Request = parseWorkflowRequest(request, "url")
+	} else if apiCall, ok := stepMap["api-call"].(map[string]interface{}); ok {
+		// The workflow engine's own spelling of a request
+		step.Request = parseWorkflowRequest(apiCall, "endpoint")
+	}
+
+	switch plugin := stepMap["plugin"].(type) {
+	case map[string]interface{}:
+		step.Plugin = parseWorkflowPlugin(plugin)
+	case string:
+		// The plugin name, with its command and input beside it
+		step.Plugin = parseWorkflowPlugin(stepMap)
+	}
+
+	if prompt, ok := stepMap["prompt"].(map[string]interface{}); ok {
+		step.Prompt = &WorkflowPrompt{}
+		if promptType, ok := prompt["type"].(string); ok {
+			step.Prompt.Type = promptType
+		}
+		if message, ok := prompt["message"].(string); ok {
+			step.Prompt.Message = message
+		}
+		if variable, ok := prompt["variable"].(string); ok {
+			step.Prompt.Variable = variable
+		}
+		step.Prompt.Default = prompt["default"]
+		if options, ok := prompt["options"].([]interface{}); ok {
+			for _, option := range options {
+				step.Prompt.Options = append(step.Prompt.Options, fmt.Sprint(option))
+			}
+		}
+		if validation, ok := prompt["validation"].(string); ok {
+			step.Prompt.Validation = validation
+		}
+		if required, ok := prompt["required"].(bool); ok {
+			step.Prompt.Required = required
+		}
+	}
+
+	if set, ok := stepMap["set"].(map[string]interface{}); ok {
+		step.Set = &WorkflowSet{}
+		if variables, ok := set["variables"].(map[string]interface{}); ok {
+			step.Set.Variables = parseStringMap(variables)
+		}
+	}
+
+	if assert, ok := stepMap["assert"].(map[string]interface{}); ok {
+		step.Assert = &WorkflowAssert{}
+		if condition, ok := assert["condition"].(string); ok {
+			step.Assert.Condition = condition
+		}
+		if message, ok := assert["message"].(string); ok {
+			step.Assert.Message = message
+		}
+	}
+
+	if call, ok := stepMap["call"].(map[string]interface{}); ok {
+		step.Call = &WorkflowCall{}
+		if name, ok := call["workflow"].(string); ok {
+			step.Call.Workflow = name
+		}
+		if inputs, ok := call["inputs"].(map[string]interface{}); ok {
+			step.Call.Inputs = inputs
+		}
+	}
+
+	if retry, ok := stepMap["retry"].(map[string]interface{}); ok {
+		step.Retry = parseWorkflowRetry(retry)
+	}
+	if rollback, ok := stepMap["rollback"].(map[string]interface{}); ok {
+		step.Rollback = parseWorkflowStep(rollback)
+	}
+
+	return step
+}
+
+// parseWorkflowPlugin parses the plugin, command and input of a plugin step.
+func parseWorkflowPlugin(data map[string]interface{}) *WorkflowPlugin {
+	plugin := &WorkflowPlugin{}
+	if name, ok := data["plugin"].(string); ok {
+		plugin.Plugin = name
+	}
+	if command, ok := data["command"].(string); ok {
+		plugin.Command = command
+	}
+	if input, ok := data["input"].(map[string]interface{}); ok {
+		plugin.Input = input
+	}
+	return plugin
+}
+
+// parseWorkflowRetry parses the retry policy of a workflow step.
+func parseWorkflowRetry(data map[string]interface{}) *WorkflowRetry {
+	retry := &WorkflowRetry{}
+	if maxAttempts, ok := data["max-attempts"].(float64); ok {
+		retry.MaxAttempts = int(maxAttempts)
+	}
+	if backoff, ok := data["backoff"].(map[string]interface{}); ok {
+		retry.Backoff = &WorkflowBackoff{}
+		if backoffType, ok := backoff["type"].(string); ok {
+			retry.Backoff.Type = backoffType
+		}
+		if initial, ok := backoff["initial-interval"].(float64); ok {
+			retry.Backoff.InitialInterval = int(initial)
+		}
+		if multiplier, ok := backoff["multiplier"].(float64); ok {
+			retry.Backoff.Multiplier = multiplier
+		}
+		if maxInterval, ok := backoff["max-interval"].(float64); ok {
+			retry.Backoff.MaxInterval = int(maxInterval)
+		}
+	}
+	if errors, ok := data["retryable-errors"].([]interface{}); ok {
+		for _, errorData := range errors {
+			errorMap, ok := errorData.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			match := &WorkflowErrorMatch{}
+			switch status := errorMap["http-status"].(type) {
+			case float64:
+				code := int(status)
+				match.HTTPStatus = &code
+			case string:
+				if status == "5xx" {
+					code := 500
+					match.HTTPStatus = &code
+				}
+			}
+			if errorType, ok := errorMap["error-type"].(string); ok {
+				match.ErrorType = errorType
+			}
+			retry.RetryableErrors = append(retry.RetryableErrors, match)
+		}
+	}
+	return retry
+}
+
+// parseWorkflowRequest parses the request of a workflow step, with its URL
+// under urlKey.
+func parseWorkflowRequest(request map[string]interface{}, urlKey string) *WorkflowRequest {
+	parsed := &WorkflowRequest{}
+	if method, ok := request["method"].(string); ok {
+		parsed.Method = method
+	}
+	if url, ok := request[urlKey].(string); ok {
+		parsed.URL = url
+	}
+	if headers, ok := request["headers"].(map[string]interface{}); ok {
+		parsed.Headers = parseStringMap(headers)
+	}
+	if body, ok := request["body"].(map[string]interface{}); ok {
+		parsed.Body = body
+	}
+	if query, ok := request["query"].(map[string]interface{}); ok {
+		parsed.Query = parseStringMap(query)
+	}
+	return parsed
+}
+
+// parseWorkflowSettings parses the settings of the x-cli-workflow extension.
+func parseWorkflowSettings(data map[string]interface{}) *WorkflowSettings {
+	settings := &WorkflowSettings{}
+	if parallel, ok := data["parallel-execution"].(bool); ok {
+		settings.ParallelExecution = parallel
+	}
+	if failFast, ok := data["fail-fast"].(bool); ok {
+		settings.FailFast = failFast
+	}
+	if timeout, ok := data["timeout"].(float64); ok {
+		settings.Timeout = int(timeout)
+	}
+	if dryRun, ok := data["dry-run-supported"].(bool); ok {
+		settings.DryRunSupported = dryRun
+	}
+	if maxConcurrency, ok := data["max-concurrency"].(float64); ok {
+		settings.MaxConcurrency = int(maxConcurrency)
+	}
+	if rateLimit, ok := data["rate-limit"].(map[string]interface{}); ok {
+		settings.RateLimit = &WorkflowRateLimit{}
+		if rps, ok := rateLimit["requests-per-second"].(float64); ok {
+			settings.RateLimit.RequestsPerSecond = rps
+		}
+		if burst, ok := rateLimit["burst"].(float64); ok {
+			settings.RateLimit.Burst = int(burst)
+		}
+	}
+	if rollbackRetry, ok := data["rollback-retry"].(map[string]interface{}); ok {
+		settings.RollbackRetry = parseWorkflowRetry(rollbackRetry)
+	}
+	return settings
+}
+
+// parseStringMap keeps the string values of data.
+func parseStringMap(data map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		if str, ok := v.(string); ok {
+			result[k] = str
+		}
+	}
+	return result
+}
+
 // Deprecation represents a deprecated API endpoint or parameter.
 type Deprecation struct {
 	OperationID string    `json:"operation_id"`
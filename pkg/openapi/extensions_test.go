@@ -203,6 +203,72 @@ func TestParseCLIFlags(t *testing.T) {
 	}
 }
 
+func TestParseCLIPlugins(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {
+			"title": "Test API",
+			"version": "1.0.0"
+		},
+		"paths": {},
+		"x-cli-plugin": [
+			{
+				"name": "backup",
+				"command": "storage backup",
+				"description": "Back up storage",
+				"executable": "aws",
+				"args": ["s3", "sync"],
+				"aliases": ["bk"]
+			},
+			{
+				"name": "lint"
+			}
+		]
+	}`
+
+	parser := NewParser()
+	ctx := context.Background()
+
+	parsed, err := parser.Parse(ctx, []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	plugins := parsed.Extensions.Plugins
+	if len(plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %d", len(plugins))
+	}
+
+	backup := plugins[0]
+	if backup.Command != "storage backup" {
+		t.Errorf("expected command 'storage backup', got '%s'", backup.Command)
+	}
+	if backup.Executable != "aws" {
+		t.Errorf("expected executable 'aws', got '%s'", backup.Executable)
+	}
+	if len(backup.Args) != 2 || backup.Args[1] != "sync" {
+		t.Errorf("expected args [s3 sync], got %v", backup.Args)
+	}
+	if len(backup.Aliases) != 1 || backup.Aliases[0] != "bk" {
+		t.Errorf("expected alias 'bk', got %v", backup.Aliases)
+	}
+
+	if plugins[1].Command != "lint" {
+		t.Errorf("expected command to default to name, got '%s'", plugins[1].Command)
+	}
+
+	invalid := []interface{}{
+		"backup",
+		map[string]interface{}{"description": "no name"},
+		map[string]interface{}{"command": "tools run"},
+	}
+	for _, data := range invalid {
+		if _, err := parseCLIPlugins(data); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
+
 func TestParseCLIInteractive(t *testing.T) {
 	spec := `{
 		"openapi": "3.0.0",
@@ -381,6 +447,68 @@ func TestParseCLIAsync(t *testing.T) {
 	}
 }
 
+func TestParseCLIWatch(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {
+			"title": "Test API",
+			"version": "1.0.0"
+		},
+		"paths": {
+			"/pets/{id}": {
+				"get": {
+					"operationId": "getPet",
+					"parameters": [
+						{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}
+					],
+					"responses": {
+						"200": {
+							"description": "OK"
+						}
+					},
+					"x-cli-watch": {
+						"enabled": true,
+						"type": "sse",
+						"endpoint": "/pets/{id}/events",
+						"events": ["status-change", "alert"],
+						"exit-on": [
+							{"event": "status-change", "condition": "data.status == 'adopted'", "message": "Adopted"}
+						],
+						"reconnect": {"enabled": true, "max-attempts": 5, "interval": 2}
+					}
+				}
+			}
+		}
+	}`
+
+	parsed, err := NewParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	operations, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatalf("failed to get operations: %v", err)
+	}
+
+	watch := operations[0].CLIWatch
+	if watch == nil {
+		t.Fatal("x-cli-watch not parsed")
+	}
+	if !watch.Enabled || watch.Type != "sse" || watch.Endpoint != "/pets/{id}/events" {
+		t.Errorf("unexpected watch %+v", watch)
+	}
+	if len(watch.Events) != 2 {
+		t.Errorf("expected 2 events, got %d", len(watch.Events))
+	}
+	if len(watch.ExitConditions) != 1 || watch.ExitConditions[0].Message != "Adopted" {
+		t.Errorf("unexpected exit conditions %+v", watch.ExitConditions)
+	}
+	if watch.Reconnect == nil || watch.Reconnect.MaxAttempts != 5 || watch.Reconnect.IntervalSecs != 2 {
+		t.Errorf("unexpected reconnect %+v", watch.Reconnect)
+	}
+}
+
 func TestParseCLIWorkflow(t *testing.T) {
 	spec := `{
 		"openapi": "3.0.0",
@@ -647,6 +775,10 @@ func TestParseCLIOutput(t *testing.T) {
 									"width": 30
 								}
 							]
+						},
+						"stream": {
+							"items-path": "data.users",
+							"sample-size": 50
 						}
 					}
 				}
@@ -696,6 +828,13 @@ func TestParseCLIOutput(t *testing.T) {
 	if col.Width != 10 {
 		t.Errorf("expected width 10, got %d", col.Width)
 	}
+
+	if op.CLIOutput.Stream == nil {
+		t.Fatal("stream config not parsed")
+	}
+	if op.CLIOutput.Stream.ItemsPath != "data.users" || op.CLIOutput.Stream.SampleSize != 50 {
+		t.Errorf("unexpected stream config: %+v", op.CLIOutput.Stream)
+	}
 }
 
 func TestParseChangelog(t *testing.T) {
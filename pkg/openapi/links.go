@@ -0,0 +1,276 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OperationLink is a link of a success response of an operation,
+// describing how the response feeds the parameters of another operation.
+type OperationLink struct {
+	// Name is the name of the link in the response.
+	Name string
+	// OperationID or OperationRef identifies the linked operation.
+	OperationID  string
+	OperationRef string
+	// Parameters maps parameter names, optionally qualified by their
+	// location like "path.id", to runtime expressions or constants.
+	Parameters map[string]interface{}
+	// RequestBody is a runtime expression or constant for the body.
+	RequestBody interface{}
+	Description string
+}
+
+// Links returns the links of the operation's success responses, sorted by
+// name. A name used by several responses is taken from the lowest status.
+func (op *Operation) Links() []*OperationLink {
+	if op.Operation == nil || op.Operation.Responses == nil {
+		return nil
+	}
+
+	var statuses []string
+	for status := range op.Operation.Responses.Map() {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Strings(statuses)
+
+	seen := make(map[string]bool)
+	var links []*OperationLink
+	for _, status := range statuses {
+		ref := op.Operation.Responses.Value(status)
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		for name, linkRef := range ref.Value.Links {
+			if seen[name] || linkRef == nil || linkRef.Value == nil {
+				continue
+			}
+			seen[name] = true
+			links = append(links, &OperationLink{
+				Name:         name,
+				OperationID:  linkRef.Value.OperationID,
+				OperationRef: linkRef.Value.OperationRef,
+				Parameters:   linkRef.Value.Parameters,
+				RequestBody:  linkRef.Value.RequestBody,
+				Description:  linkRef.Value.Description,
+			})
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].Name < links[j].Name })
+	return links
+}
+
+// Link returns the link of the operation's success responses called name.
+func (op *Operation) Link(name string) *OperationLink {
+	for _, link := range op.Links() {
+		if link.Name == name {
+			return link
+		}
+	}
+	return nil
+}
+
+// LinkedOperation finds the operation a link points to. Only local
+// operationRefs, like "#/paths/~1pets~1{id}/get", are supported.
+func (ps *ParsedSpec) LinkedOperation(link *OperationLink) (*Operation, error) {
+	operations, err := ps.GetOperations()
+	if err != nil {
+		return nil, err
+	}
+
+	if link.OperationID != "" {
+		for _, op := range operations {
+			if op.OperationID == link.OperationID {
+				return op, nil
+			}
+		}
+		return nil, fmt.Errorf("link %s: operation %s not found", link.Name, link.OperationID)
+	}
+
+	if link.OperationRef != "" {
+		pointer, ok := strings.CutPrefix(link.OperationRef, "#/paths/")
+		if !ok {
+			return nil, fmt.Errorf("link %s: unsupported operationRef %s", link.Name, link.OperationRef)
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return nil, fmt.Errorf("link %s: invalid operationRef %s", link.Name, link.OperationRef)
+		}
+		path := strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[:i])
+		method := strings.ToUpper(pointer[i+1:])
+		for _, op := range operations {
+			if op.Path == path && op.Method == method {
+				return op, nil
+			}
+		}
+		return nil, fmt.Errorf("link %s: operation %s not found", link.Name, link.OperationRef)
+	}
+
+	return nil, fmt.Errorf("link %s has no operationId or operationRef", link.Name)
+}
+
+// ExpressionContext is the request and response OpenAPI runtime
+// expressions are evaluated against.
+type ExpressionContext struct {
+	URL            string
+	Method         string
+	StatusCode     int
+	PathParams     map[string]string
+	Query          url.Values
+	RequestHeader  http.Header
+	RequestBody    []byte
+	ResponseHeader http.Header
+	ResponseBody   []byte
+}
+
+// EvaluateExpression evaluates the value of a link parameter or request
+// body: a runtime expression like "$response.body#/id", a string
+// embedding expressions like "{$request.path.id}-copy", or a constant.
+// Objects and arrays are evaluated element by element.
+func EvaluateExpression(value interface{}, ctx *ExpressionContext) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "$") {
+			return ctx.evaluate(v)
+		}
+		if !strings.Contains(v, "{$") {
+			return v, nil
+		}
+		var sb strings.Builder
+		rest := v
+		for {
+			start := strings.Index(rest, "{$")
+			if start < 0 {
+				sb.WriteString(rest)
+				break
+			}
+			end := strings.Index(rest[start:], "}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated expression in %q", v)
+			}
+			sb.WriteString(rest[:start])
+			evaluated, err := ctx.evaluate(rest[start+1 : start+end])
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(expressionString(evaluated))
+			rest = rest[start+end+1:]
+		}
+		return sb.String(), nil
+	case map[string]interface{}:
+		evaluated := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result, err := EvaluateExpression(item, ctx)
+			if err != nil {
+				return nil, err
+			}
+			evaluated[key] = result
+		}
+		return evaluated, nil
+	case []interface{}:
+		evaluated := make([]interface{}, len(v))
+		for i, item := range v {
+			result, err := EvaluateExpression(item, ctx)
+			if err != nil {
+				return nil, err
+			}
+			evaluated[i] = result
+		}
+		return evaluated, nil
+	default:
+		return value, nil
+	}
+}
+
+// evaluate evaluates a single runtime expression.
+func (ctx *ExpressionContext) evaluate(expr string) (interface{}, error) {
+	switch expr {
+	case "$url":
+		return ctx.URL, nil
+	case "$method":
+		return ctx.Method, nil
+	case "$statusCode":
+		return ctx.StatusCode, nil
+	}
+
+	source, rest, ok := strings.Cut(strings.TrimPrefix(expr, "$"), ".")
+	if !ok || (source != "request" && source != "response") {
+		return nil, fmt.Errorf("unsupported expression %s", expr)
+	}
+
+	if pointer, ok := strings.CutPrefix(rest, "body"); ok {
+		data := ctx.ResponseBody
+		if source == "request" {
+			data = ctx.RequestBody
+		}
+		var body interface{}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				return nil, fmt.Errorf("%s: body is not JSON", expr)
+			}
+		}
+		value, err := resolvePointer(body, strings.TrimPrefix(pointer, "#"))
+		if err != nil || value == nil {
+			return nil, fmt.Errorf("%s: not found in the %s body", expr, source)
+		}
+		return value, nil
+	}
+
+	location, name, ok := strings.Cut(rest, ".")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("unsupported expression %s", expr)
+	}
+	var value string
+	var found bool
+	switch {
+	case location == "header" && source == "request":
+		value, found = headerValue(ctx.RequestHeader, name)
+	case location == "header" && source == "response":
+		value, found = headerValue(ctx.ResponseHeader, name)
+	case location == "path" && source == "request":
+		value, found = ctx.PathParams[name]
+	case location == "query" && source == "request":
+		if values, ok := ctx.Query[name]; ok && len(values) > 0 {
+			value, found = values[0], true
+		}
+	default:
+		return nil, fmt.Errorf("unsupported expression %s", expr)
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: not found", expr)
+	}
+	return value, nil
+}
+
+// headerValue returns the value of a header, if it is set.
+func headerValue(header http.Header, name string) (string, bool) {
+	values := header.Values(name)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// expressionString formats an evaluated value embedded in a string.
+func expressionString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const linksSpec = `
+openapi: 3.0.3
+info: {title: Clusters, version: "1.0"}
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      responses:
+        "201":
+          description: Created
+          links:
+            getCluster:
+              operationId: getCluster
+              description: Show the new cluster
+              parameters:
+                cluster_id: $response.body#/id
+            listNodes:
+              operationRef: "#/paths/~1clusters~1{cluster_id}~1nodes/get"
+              parameters:
+                path.cluster_id: $response.body#/id
+        "400":
+          description: Bad request
+          links:
+            retry:
+              operationId: createCluster
+  /clusters/{cluster_id}:
+    get:
+      operationId: getCluster
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: OK}
+  /clusters/{cluster_id}/nodes:
+    get:
+      operationId: listNodes
+      parameters:
+        - {name: cluster_id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200": {description: OK}
+`
+
+func TestOperationLinks(t *testing.T) {
+	parsed, err := NewParser().Parse(context.Background(), []byte(linksSpec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	ops, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var create *Operation
+	for _, op := range ops {
+		if op.OperationID == "createCluster" {
+			create = op
+		}
+	}
+
+	links := create.Links()
+	if len(links) != 2 || links[0].Name != "getCluster" || links[1].Name != "listNodes" {
+		t.Fatalf("expected the links of success responses, got %+v", links)
+	}
+	if links[0].Description != "Show the new cluster" || links[0].Parameters["cluster_id"] != "$response.body#/id" {
+		t.Errorf("unexpected link %+v", links[0])
+	}
+	if create.Link("retry") != nil {
+		t.Error("expected links of error responses to be left out")
+	}
+
+	for _, link := range links {
+		target, err := parsed.LinkedOperation(link)
+		if err != nil {
+			t.Fatalf("LinkedOperation(%s) error = %v", link.Name, err)
+		}
+		if target.OperationID != link.Name {
+			t.Errorf("LinkedOperation(%s) = %s", link.Name, target.OperationID)
+		}
+	}
+
+	if _, err := parsed.LinkedOperation(&OperationLink{Name: "bad", OperationID: "missing"}); err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+	if _, err := parsed.LinkedOperation(&OperationLink{Name: "bad", OperationRef: "other.yaml#/paths/~1x/get"}); err == nil {
+		t.Error("expected an error for a remote operationRef")
+	}
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	ctx := &ExpressionContext{
+		URL:            "https://api.example.com/clusters?region=eu",
+		Method:         "POST",
+		StatusCode:     201,
+		PathParams:     map[string]string{"org": "acme"},
+		Query:          url.Values{"region": {"eu"}},
+		RequestHeader:  http.Header{"X-Request-Id": {"r1"}},
+		RequestBody:    []byte(`{"name": "prod"}`),
+		ResponseHeader: http.Header{"Location": {"/clusters/123"}},
+		ResponseBody:   []byte(`{"id": 123, "nodes": [{"name": "n1"}], "a/b": true}`),
+	}
+
+	tests := []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{"$url", ctx.URL},
+		{"$method", "POST"},
+		{"$statusCode", 201},
+		{"$request.path.org", "acme"},
+		{"$request.query.region", "eu"},
+		{"$request.header.x-request-id", "r1"},
+		{"$request.body#/name", "prod"},
+		{"$response.header.Location", "/clusters/123"},
+		{"$response.body#/id", 123.0},
+		{"$response.body#/nodes/0/name", "n1"},
+		{"$response.body#/a~1b", true},
+		{"cluster-{$response.body#/id}-{$request.path.org}", "cluster-123-acme"},
+		{"constant", "constant"},
+		{42, 42},
+		{map[string]interface{}{"cluster": "$response.body#/id"}, map[string]interface{}{"cluster": 123.0}},
+	}
+	for _, tt := range tests {
+		got, err := EvaluateExpression(tt.value, ctx)
+		if err != nil {
+			t.Errorf("EvaluateExpression(%v) error = %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("EvaluateExpression(%v) = %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"$response.body#/missing", "$request.path.missing", "$response.query.x", "$inputs.x", "{$url"} {
+		if _, err := EvaluateExpression(expr, ctx); err == nil {
+			t.Errorf("EvaluateExpression(%q) expected an error", expr)
+		}
+	}
+}
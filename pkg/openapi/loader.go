@@ -1,12 +1,16 @@
 package openapi
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +24,15 @@ type Loader struct {
 	HTTPClient *http.Client
 	// CacheTTL is the cache time-to-live (default: 5 minutes)
 	CacheTTL time.Duration
+	// OnChange is called when a spec fetched from a URL differs from the
+	// cached copy it replaces, with both versions and the changes between
+	// them
+	OnChange func(ctx context.Context, specURL string, oldSpec, newSpec *ParsedSpec, changes []*DetectedChange)
+	// Verifier, when set, refuses specs not signed by one of its keys
+	Verifier *SpecVerifier
+	// OnRejected is called when a fetched spec is refused for its
+	// signature and the last verified copy from the cache is used instead
+	OnRejected func(ctx context.Context, specURL string, err error)
 }
 
 // SpecCache defines the interface for caching loaded specs.
@@ -38,6 +51,8 @@ type CachedSpec struct {
 	ETag      string
 	FetchedAt time.Time
 	URL       string
+	// Signature is the base64 encoded signature of Data, when verified
+	Signature string
 }
 
 // NewLoader creates a new Loader instance.
@@ -53,6 +68,11 @@ func NewLoader(cache SpecCache) *Loader {
 }
 
 // LoadFromURL loads an OpenAPI spec from a URL with caching support.
+//
+// With a Verifier, the spec must be signed: fetched specs with a missing
+// or invalid signature are refused, and cached copies are verified again
+// when read. A refused spec falls back to a verified cached copy, if
+// there is one, reporting the refusal to OnRejected.
 func (l *Loader) LoadFromURL(ctx context.Context, specURL string, options *LoadOptions) (*ParsedSpec, error) {
 	if options == nil {
 		options = &LoadOptions{}
@@ -64,112 +84,158 @@ func (l *Loader) LoadFromURL(ctx context.Context, specURL string, options *LoadO
 	}
 
 	var data []byte
-	var etag string
 	var err error
+	// previous is the cached copy replaced by freshly fetched data
+	var previous []byte
 
 	// Try to load from cache if not forcing refresh
 	if !options.ForceRefresh && l.Cache != nil {
-		cached, err := l.Cache.Get(ctx, specURL)
-		if err == nil && cached != nil {
+		cached := l.cachedSpec(ctx, specURL)
+		if cached != nil {
 			// Check if cache is still valid
 			if time.Since(cached.FetchedAt) < l.CacheTTL {
 				// Use cached data
 				data = cached.Data
-				etag = cached.ETag
 
 				// If we have an ETag, try conditional request
-				if etag != "" && !options.SkipConditional {
-					freshData, newETag, notModified, err := l.fetchWithETag(ctx, specURL, etag)
-					if err == nil {
-						if notModified {
-							// Server says not modified, use cached data
-							data = cached.Data
-						} else {
-							// Server returned new data
-							data = freshData
-							etag = newETag
-							// Update cache
-							if l.Cache != nil {
-								_ = l.Cache.Set(ctx, specURL, &CachedSpec{
-									Data:      data,
-									ETag:      etag,
-									FetchedAt: time.Now(),
-									URL:       specURL,
-								})
-							}
-						}
+				if cached.ETag != "" && !options.SkipConditional {
+					fresh, notModified, err := l.fetchWithETag(ctx, specURL, cached.ETag)
+					if err == nil && !notModified {
+						// Server returned new data
+						previous = cached.Data
+						data = fresh.Data
+						l.store(ctx, specURL, fresh)
+					} else if err != nil {
+						l.reject(ctx, specURL, err)
 					}
 					// If conditional request failed, fall through to use cached data
 				}
 			} else {
 				// Cache expired, fetch fresh
-				data, etag, err = l.fetchSpec(ctx, specURL)
+				fresh, err := l.fetchSpec(ctx, specURL)
 				if err != nil {
 					// If fetch fails, use stale cache if available
-					if cached.Data != nil {
-						data = cached.Data
-						// Use stale cache data, continue without error
-					} else {
+					if cached.Data == nil {
 						return nil, fmt.Errorf("failed to fetch spec and no cache available: %w", err)
 					}
+					data = cached.Data
+					l.reject(ctx, specURL, err)
 				} else {
+					previous = cached.Data
+					data = fresh.Data
 					// Update cache with fresh data
-					if l.Cache != nil {
-						_ = l.Cache.Set(ctx, specURL, &CachedSpec{
-							Data:      data,
-							ETag:      etag,
-							FetchedAt: time.Now(),
-							URL:       specURL,
-						})
-					}
+					l.store(ctx, specURL, fresh)
 				}
 			}
 		} else {
 			// No cache, fetch fresh
-			data, etag, err = l.fetchSpec(ctx, specURL)
+			fresh, err := l.fetchSpec(ctx, specURL)
 			if err != nil {
 				return nil, fmt.Errorf("failed to fetch spec: %w", err)
 			}
+			data = fresh.Data
 
 			// Store in cache
-			if l.Cache != nil {
-				_ = l.Cache.Set(ctx, specURL, &CachedSpec{
-					Data:      data,
-					ETag:      etag,
-					FetchedAt: time.Now(),
-					URL:       specURL,
-				})
-			}
+			l.store(ctx, specURL, fresh)
 		}
 	} else {
 		// Force refresh or no cache
-		data, etag, err = l.fetchSpec(ctx, specURL)
+		fresh, err := l.fetchSpec(ctx, specURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch spec: %w", err)
 		}
+		data = fresh.Data
 
 		// Store in cache
 		if l.Cache != nil {
-			_ = l.Cache.Set(ctx, specURL, &CachedSpec{
-				Data:      data,
-				ETag:      etag,
-				FetchedAt: time.Now(),
-				URL:       specURL,
-			})
+			if cached, err := l.Cache.Get(ctx, specURL); err == nil && cached != nil {
+				previous = cached.Data
+			}
+			l.store(ctx, specURL, fresh)
 		}
 	}
 
 	// Parse the spec
-	return l.Parser.Parse(ctx, data)
+	spec, err := l.Parser.Parse(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != nil && !bytes.Equal(previous, data) {
+		l.notifyChange(ctx, specURL, previous, spec)
+	}
+	return spec, nil
+}
+
+// cachedSpec returns the cached copy of a spec, or nil if there is none
+// or, with a Verifier, its signature does not verify.
+func (l *Loader) cachedSpec(ctx context.Context, specURL string) *CachedSpec {
+	cached, err := l.Cache.Get(ctx, specURL)
+	if err != nil || cached == nil {
+		return nil
+	}
+	if l.Verifier != nil {
+		if err := l.Verifier.Verify(cached.Data, cached.Signature); err != nil {
+			return nil
+		}
+	}
+	return cached
 }
 
-// LoadFromFile loads an OpenAPI spec from a file.
+// store caches a fetched spec.
+func (l *Loader) store(ctx context.Context, specURL string, fetched *CachedSpec) {
+	if l.Cache == nil {
+		return
+	}
+	fetched.FetchedAt = time.Now()
+	fetched.URL = specURL
+	_ = l.Cache.Set(ctx, specURL, fetched)
+}
+
+// reject reports a fetched spec whose signature failed to verify, when
+// the cached copy is used in its place.
+func (l *Loader) reject(ctx context.Context, specURL string, err error) {
+	if l.OnRejected != nil && (errors.Is(err, ErrSpecUnsigned) || errors.Is(err, ErrSignatureInvalid)) {
+		l.OnRejected(ctx, specURL, err)
+	}
+}
+
+// notifyChange calls OnChange with the changes from the previously cached
+// copy of a spec. A cached copy that no longer parses is ignored.
+func (l *Loader) notifyChange(ctx context.Context, specURL string, previous []byte, spec *ParsedSpec) {
+	if l.OnChange == nil {
+		return
+	}
+
+	oldSpec, err := l.Parser.Parse(ctx, previous)
+	if err != nil {
+		return
+	}
+	changes, err := NewChangeDetector().DetectChanges(oldSpec, spec)
+	if err != nil {
+		return
+	}
+	l.OnChange(ctx, specURL, oldSpec, spec, changes)
+}
+
+// LoadFromFile loads an OpenAPI spec from a file. With a Verifier, its
+// signature is read from the file with SignatureSuffix appended.
 func (l *Loader) LoadFromFile(ctx context.Context, path string) (*ParsedSpec, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	if l.Verifier != nil {
+		signature, err := os.ReadFile(path + SignatureSuffix)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read signature: %w", err)
+		}
+		if err := l.Verifier.Verify(data, string(signature)); err != nil {
+			return nil, signatureError(path, path+SignatureSuffix, err)
+		}
+	}
+
 	return l.Parser.Parse(ctx, data)
 }
 
@@ -201,6 +267,61 @@ func (l *Loader) InvalidateCache(ctx context.Context, specURL string) error {
 	return l.Cache.Invalidate(ctx, specURL)
 }
 
+// Load loads an OpenAPI spec from an http(s) URL, with caching, or from a
+// file path.
+func (l *Loader) Load(ctx context.Context, location string, options *LoadOptions) (*ParsedSpec, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return l.LoadFromURL(ctx, location, options)
+	}
+	return l.LoadFromFile(ctx, location)
+}
+
+// SpecSource is one of several specs loaded together with LoadAll.
+type SpecSource struct {
+	// Name identifies the spec in results and errors
+	Name string
+	// Location is a URL or file path
+	Location string
+	// CacheTTL overrides the loader's cache TTL for this spec
+	CacheTTL time.Duration
+}
+
+// LoadResult is the outcome of loading one SpecSource. Exactly one of
+// Spec and Err is set.
+type LoadResult struct {
+	Source SpecSource
+	Spec   *ParsedSpec
+	Err    error
+}
+
+// LoadAll loads specs concurrently, returning a result per source in the
+// order given. A spec that fails to load does not stop the others.
+func (l *Loader) LoadAll(ctx context.Context, sources []SpecSource, options *LoadOptions) []LoadResult {
+	results := make([]LoadResult, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source SpecSource) {
+			defer wg.Done()
+
+			loader := *l
+			if source.CacheTTL > 0 {
+				loader.CacheTTL = source.CacheTTL
+			}
+
+			spec, err := loader.Load(ctx, source.Location, options)
+			if err != nil {
+				err = fmt.Errorf("failed to load spec %s: %w", source.Name, err)
+			}
+			results[i] = LoadResult{Source: source, Spec: spec, Err: err}
+		}(i, source)
+	}
+	wg.Wait()
+
+	return results
+}
+
 // LoadOptions controls how specs are loaded.
 type LoadOptions struct {
 	// ForceRefresh bypasses cache and fetches fresh spec
@@ -211,11 +332,12 @@ type LoadOptions struct {
 	Headers map[string]string
 }
 
-// fetchSpec fetches a spec from a URL.
-func (l *Loader) fetchSpec(ctx context.Context, specURL string) ([]byte, string, error) {
+// fetchSpec fetches a spec from a URL, with its signature when there is a
+// Verifier.
+func (l *Loader) fetchSpec(ctx context.Context, specURL string) (*CachedSpec, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json, application/yaml, application/x-yaml, text/yaml")
@@ -223,28 +345,27 @@ func (l *Loader) fetchSpec(ctx context.Context, specURL string) ([]byte, string,
 
 	resp, err := l.HTTPClient.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to fetch spec: %w", err)
+		return nil, fmt.Errorf("failed to fetch spec: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	etag := resp.Header.Get("ETag")
-	return data, etag, nil
+	return l.verified(ctx, specURL, resp, data)
 }
 
 // fetchWithETag performs a conditional GET request using ETag.
-func (l *Loader) fetchWithETag(ctx context.Context, specURL, etag string) ([]byte, string, bool, error) {
+func (l *Loader) fetchWithETag(ctx context.Context, specURL, etag string) (*CachedSpec, bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL, nil)
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json, application/yaml, application/x-yaml, text/yaml")
@@ -253,26 +374,103 @@ func (l *Loader) fetchWithETag(ctx context.Context, specURL, etag string) ([]byt
 
 	resp, err := l.HTTPClient.Do(req)
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to fetch spec: %w", err)
+		return nil, false, fmt.Errorf("failed to fetch spec: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode == http.StatusNotModified {
 		// Content not modified, use cached version
-		return nil, etag, true, nil
+		return nil, true, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", false, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, false, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to read response: %w", err)
+		return nil, false, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	fetched, err := l.verified(ctx, specURL, resp, data)
+	return fetched, false, err
+}
+
+// verified returns a fetched spec, checking its signature when there is a
+// Verifier. The signature is taken from the SignatureHeader of resp, or
+// else fetched from the spec URL with SignatureSuffix appended.
+func (l *Loader) verified(ctx context.Context, specURL string, resp *http.Response, data []byte) (*CachedSpec, error) {
+	fetched := &CachedSpec{Data: data, ETag: resp.Header.Get("ETag")}
+	if l.Verifier == nil {
+		return fetched, nil
+	}
+
+	source := SignatureHeader + " header"
+	signature := resp.Header.Get(SignatureHeader)
+	if signature == "" {
+		signatureURL, err := signatureURL(specURL)
+		if err != nil {
+			return nil, err
+		}
+		source = signatureURL
+		if signature, err = l.fetchSignature(ctx, signatureURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.Verifier.Verify(data, signature); err != nil {
+		return nil, signatureError(specURL, source, err)
+	}
+	fetched.Signature = strings.TrimSpace(signature)
+	return fetched, nil
+}
+
+// fetchSignature fetches a detached signature, returning an empty
+// signature if there is none.
+func (l *Loader) fetchSignature(ctx context.Context, signatureURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signatureURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("User-Agent", "CliForge/0.9.0 OpenAPI Loader")
 
-	newETag := resp.Header.Get("ETag")
-	return data, newETag, false, nil
+	resp, err := l.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch spec signature: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch spec signature: HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	signature, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", fmt.Errorf("failed to read spec signature: %w", err)
+	}
+	return string(signature), nil
+}
+
+// signatureURL returns the URL of the detached signature of a spec.
+func signatureURL(specURL string) (string, error) {
+	u, err := url.Parse(specURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	u.Path += SignatureSuffix
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// signatureError explains why the signature of a spec was refused.
+func signatureError(location, source string, err error) error {
+	if errors.Is(err, ErrSpecUnsigned) {
+		return fmt.Errorf("refusing spec %s: %w (no signature in %s)", location, err, source)
+	}
+	return fmt.Errorf("refusing spec %s: %w (signature from %s)", location, err, source)
 }
 
 // SetCacheTTL sets the cache time-to-live duration.
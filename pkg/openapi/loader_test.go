@@ -6,12 +6,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 // mockCache implements SpecCache for testing
 type mockCache struct {
+	mu   sync.Mutex
 	data map[string]*CachedSpec
 }
 
@@ -22,6 +24,8 @@ func newMockCache() *mockCache {
 }
 
 func (m *mockCache) Get(_ context.Context, key string) (*CachedSpec, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if spec, ok := m.data[key]; ok {
 		return spec, nil
 	}
@@ -29,11 +33,15 @@ func (m *mockCache) Get(_ context.Context, key string) (*CachedSpec, error) {
 }
 
 func (m *mockCache) Set(_ context.Context, key string, spec *CachedSpec) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.data[key] = spec
 	return nil
 }
 
 func (m *mockCache) Invalidate(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	delete(m.data, key)
 	return nil
 }
@@ -438,3 +446,112 @@ func TestLoader_LoadFromFile_Integration(t *testing.T) {
 		t.Errorf("expected title 'Temp Test', got '%s'", info.Title)
 	}
 }
+
+func TestLoader_LoadAll(t *testing.T) {
+	specData := func(title string) string {
+		return `{"openapi": "3.0.0", "info": {"title": "` + title + `", "version": "1.0.0"}, "paths": {}}`
+	}
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(specData(strings.TrimPrefix(r.URL.Path, "/"))))
+	}))
+	defer server.Close()
+
+	file := t.TempDir() + "/local.json"
+	if err := os.WriteFile(file, []byte(specData("local")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both cached a minute ago; only billing's TTL has expired
+	cache := newMockCache()
+	for _, name := range []string{"accounts", "billing"} {
+		_ = cache.Set(context.Background(), server.URL+"/"+name, &CachedSpec{
+			Data:      []byte(specData(name + "-cached")),
+			FetchedAt: time.Now().Add(-time.Minute),
+		})
+	}
+
+	results := NewLoader(cache).LoadAll(context.Background(), []SpecSource{
+		{Name: "accounts", Location: server.URL + "/accounts"},
+		{Name: "billing", Location: server.URL + "/billing", CacheTTL: 30 * time.Second},
+		{Name: "broken", Location: server.URL + "/broken"},
+		{Name: "local", Location: file},
+	}, nil)
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for i, want := range []string{"accounts-cached", "billing", "", "local"} {
+		result := results[i]
+		if want == "" {
+			if result.Err == nil || !strings.Contains(result.Err.Error(), "failed to load spec broken") {
+				t.Errorf("expected %s to fail, got %v", result.Source.Name, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("failed to load %s: %v", result.Source.Name, result.Err)
+			continue
+		}
+		if title := result.Spec.GetInfo().Title; title != want {
+			t.Errorf("%s: expected title %s, got %s", result.Source.Name, want, title)
+		}
+	}
+	if requests["/accounts"] != 0 || requests["/billing"] != 1 {
+		t.Errorf("expected only the expired spec to be fetched, got %v", requests)
+	}
+}
+
+func TestLoader_OnChange(t *testing.T) {
+	version := "1.0.0"
+	paths := `"/users": {"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + version + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Users", "version": "` + version + `"}, "paths": {` + paths + `}}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	loader := NewLoader(newMockCache())
+	loader.OnChange = func(_ context.Context, specURL string, oldSpec, newSpec *ParsedSpec, changes []*DetectedChange) {
+		calls = append(calls, oldSpec.GetInfo().Version+" -> "+newSpec.GetInfo().Version)
+		if specURL != server.URL || !IsBreaking(changes) {
+			t.Errorf("OnChange(%s) changes = %v, want the removed path", specURL, changes)
+		}
+	}
+	ctx := context.Background()
+
+	load := func() {
+		t.Helper()
+		if _, err := loader.LoadFromURL(ctx, server.URL, nil); err != nil {
+			t.Fatalf("LoadFromURL() error = %v", err)
+		}
+	}
+
+	// Nothing cached, then nothing changed
+	load()
+	load()
+	if len(calls) != 0 {
+		t.Fatalf("Expected no changes, got %v", calls)
+	}
+
+	version, paths = "2.0.0", ""
+	load()
+	if strings.Join(calls, ",") != "1.0.0 -> 2.0.0" {
+		t.Errorf("Expected one change, got %v", calls)
+	}
+}
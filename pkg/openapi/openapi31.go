@@ -0,0 +1,533 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPI31Fields are OpenAPI 3.1 and JSON Schema 2020-12 fields the 3.0
+// model has no field for. They are kept as extensions of the object they
+// appear in, where SchemaConst and SchemaPrefixItems read them.
+var openAPI31Fields = []string{
+	"$anchor", "$comment", "$dynamicAnchor", "$dynamicRef", "$id", "$schema", "$vocabulary",
+	"const", "contains", "contentEncoding", "contentMediaType", "contentSchema",
+	"dependentRequired", "dependentSchemas", "else", "examples", "identifier", "if",
+	"jsonSchemaDialect", "maxContains", "minContains", "patternProperties", "prefixItems",
+	"propertyNames", "summary", "then", "unevaluatedItems", "unevaluatedProperties",
+}
+
+// webhooksCallback is the component callback webhooks are loaded through,
+// so their references are resolved like those of paths.
+const webhooksCallback = "cliforge.webhooks"
+
+// decodeDocument decodes a JSON or YAML document into plain JSON values.
+func decodeDocument(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	doc, ok := toJSONValue(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("document is not an object")
+	}
+	return doc, nil
+}
+
+// toJSONValue converts decoded YAML to the values encoding/json produces.
+// YAML allows non-string keys, such as unquoted status codes.
+func toJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = toJSONValue(value)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprintf("%v", key)] = toJSONValue(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = toJSONValue(value)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return v
+}
+
+// toJSON decodes a JSON or YAML document and encodes it as JSON.
+func toJSON(data []byte) ([]byte, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// openAPI31Normalizer rewrites an OpenAPI 3.1 document into the shape of
+// the 3.0 model, keeping what the model cannot hold as extensions:
+//
+//   - type arrays with "null" become nullable
+//   - const becomes a single-value enum and keeps its const extension
+//   - numeric exclusiveMinimum and exclusiveMaximum become the 3.0 form
+//   - items: false after prefixItems becomes maxItems
+//   - boolean schemas become {} and {"not": {}}
+//   - $defs are moved to components/schemas and references to them updated
+//   - components/pathItems references are inlined
+//   - webhooks are loaded as a component callback
+type openAPI31Normalizer struct {
+	doc      map[string]interface{}
+	warnings []ValidationWarning
+}
+
+// normalizeOpenAPI31 rewrites doc in place and returns warnings about
+// constructs that are not valid in 3.1.
+func normalizeOpenAPI31(doc map[string]interface{}) ([]ValidationWarning, error) {
+	n := &openAPI31Normalizer{doc: doc}
+
+	components, _ := doc["components"].(map[string]interface{})
+	if components == nil {
+		components = make(map[string]interface{})
+		doc["components"] = components
+	}
+
+	if err := n.inlinePathItems(components); err != nil {
+		return nil, err
+	}
+	n.hoistDefs(components)
+
+	if webhooks, ok := doc["webhooks"].(map[string]interface{}); ok {
+		callbacks, _ := components["callbacks"].(map[string]interface{})
+		if callbacks == nil {
+			callbacks = make(map[string]interface{})
+			components["callbacks"] = callbacks
+		}
+		callbacks[webhooksCallback] = webhooks
+		delete(doc, "webhooks")
+	}
+	if _, ok := doc["paths"]; !ok {
+		doc["paths"] = map[string]interface{}{}
+	}
+
+	n.walkDocument(doc, "")
+	return n.warnings, nil
+}
+
+// inlinePathItems replaces references to components/pathItems with the
+// path items, which the 3.0 model cannot reference.
+func (n *openAPI31Normalizer) inlinePathItems(components map[string]interface{}) error {
+	pathItems, _ := components["pathItems"].(map[string]interface{})
+	delete(components, "pathItems")
+	if pathItems == nil {
+		return nil
+	}
+
+	var resolve func(item interface{}, seen map[string]bool) (interface{}, error)
+	resolve = func(item interface{}, seen map[string]bool) (interface{}, error) {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return item, nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/components/pathItems/") {
+			return item, nil
+		}
+		name := strings.TrimPrefix(ref, "#/components/pathItems/")
+		if seen[name] {
+			return nil, fmt.Errorf("path item %s references itself", name)
+		}
+		target, ok := pathItems[name]
+		if !ok {
+			return nil, fmt.Errorf("path item %s not found", ref)
+		}
+		seen[name] = true
+		resolved, err := resolve(deepCopy(target), seen)
+		if err != nil {
+			return nil, err
+		}
+		// Fields next to the reference override the path item's
+		if resolvedMap, ok := resolved.(map[string]interface{}); ok {
+			for key, value := range m {
+				if key != "$ref" {
+					resolvedMap[key] = value
+				}
+			}
+		}
+		return resolved, nil
+	}
+
+	containers := []map[string]interface{}{}
+	for _, key := range []string{"paths", "webhooks"} {
+		if m, ok := n.doc[key].(map[string]interface{}); ok {
+			containers = append(containers, m)
+		}
+	}
+	for _, container := range containers {
+		for key, item := range container {
+			resolved, err := resolve(item, map[string]bool{})
+			if err != nil {
+				return err
+			}
+			container[key] = resolved
+		}
+	}
+	return nil
+}
+
+// hoistDefs moves every $defs entry to components/schemas, named after
+// the schema it was defined in, and points references at the new names.
+func (n *openAPI31Normalizer) hoistDefs(components map[string]interface{}) {
+	schemas, _ := components["schemas"].(map[string]interface{})
+	if schemas == nil {
+		schemas = make(map[string]interface{})
+	}
+
+	moved := make(map[string]string)
+	var hoist func(v interface{}, pointer, base string)
+	hoist = func(v interface{}, pointer, base string) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if defs, ok := v["$defs"].(map[string]interface{}); ok {
+				delete(v, "$defs")
+				for _, name := range sortedKeys(defs) {
+					newName := uniqueName(schemas, base+"."+name)
+					schemas[newName] = defs[name]
+					moved[pointer+"/$defs/"+escapePointer(name)] = "#/components/schemas/" + newName
+					hoist(defs[name], pointer+"/$defs/"+escapePointer(name), newName)
+				}
+			}
+			for _, key := range sortedKeys(v) {
+				if isDataKey(key) {
+					continue
+				}
+				childBase := base
+				if pointer == "#/components/schemas" {
+					childBase = key
+				}
+				hoist(v[key], pointer+"/"+escapePointer(key), childBase)
+			}
+		case []interface{}:
+			for i, item := range v {
+				hoist(item, fmt.Sprintf("%s/%d", pointer, i), base)
+			}
+		}
+	}
+	hoist(n.doc, "#", "Inline")
+
+	if len(moved) == 0 {
+		return
+	}
+	components["schemas"] = schemas
+
+	// Longest pointers first, so nested definitions win
+	pointers := make([]string, 0, len(moved))
+	for pointer := range moved {
+		pointers = append(pointers, pointer)
+	}
+	sort.Slice(pointers, func(i, j int) bool { return len(pointers[i]) > len(pointers[j]) })
+
+	var rewrite func(v interface{})
+	rewrite = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				for _, pointer := range pointers {
+					if ref == pointer || strings.HasPrefix(ref, pointer+"/") {
+						v["$ref"] = moved[pointer] + strings.TrimPrefix(ref, pointer)
+						break
+					}
+				}
+			}
+			for _, value := range v {
+				rewrite(value)
+			}
+		case []interface{}:
+			for _, item := range v {
+				rewrite(item)
+			}
+		}
+	}
+	rewrite(n.doc)
+}
+
+// walkDocument finds the schemas of the document and normalizes them.
+func (n *openAPI31Normalizer) walkDocument(v interface{}, pointer string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if isDataKey(key) {
+				continue
+			}
+			childPointer := pointer + "/" + escapePointer(key)
+			switch {
+			case key == "schema":
+				v[key] = n.normalizeSchema(v[key], childPointer)
+			case key == "schemas" && pointer == "/components":
+				if schemas, ok := v[key].(map[string]interface{}); ok {
+					for name, schema := range schemas {
+						schemas[name] = n.normalizeSchema(schema, childPointer+"/"+escapePointer(name))
+					}
+				}
+			default:
+				n.walkDocument(v[key], childPointer)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			n.walkDocument(item, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	}
+}
+
+// Keywords whose values are schemas, maps of schemas and lists of schemas.
+var (
+	subschemaKeywords = []string{
+		"additionalProperties", "contains", "contentSchema", "else", "if", "items", "not",
+		"propertyNames", "then", "unevaluatedItems", "unevaluatedProperties",
+	}
+	subschemaMapKeywords  = []string{"dependentSchemas", "patternProperties", "properties"}
+	subschemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// normalizeSchema normalizes a schema and its subschemas, returning the
+// schema to use in its place.
+func (n *openAPI31Normalizer) normalizeSchema(v interface{}, pointer string) interface{} {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"not": map[string]interface{}{}}
+	case map[string]interface{}:
+		n.normalizeKeywords(v, pointer)
+		for _, key := range subschemaKeywords {
+			if sub, ok := v[key]; ok {
+				if _, isBool := sub.(bool); isBool && (key == "additionalProperties" || key == "items") {
+					continue
+				}
+				v[key] = n.normalizeSchema(sub, pointer+"/"+key)
+			}
+		}
+		for _, key := range subschemaMapKeywords {
+			if m, ok := v[key].(map[string]interface{}); ok {
+				for name, sub := range m {
+					m[name] = n.normalizeSchema(sub, pointer+"/"+key+"/"+escapePointer(name))
+				}
+			}
+		}
+		for _, key := range subschemaListKeywords {
+			if list, ok := v[key].([]interface{}); ok {
+				for i, sub := range list {
+					list[i] = n.normalizeSchema(sub, fmt.Sprintf("%s/%s/%d", pointer, key, i))
+				}
+			}
+		}
+		return v
+	}
+	return v
+}
+
+// normalizeKeywords rewrites the keywords of one schema.
+func (n *openAPI31Normalizer) normalizeKeywords(s map[string]interface{}, pointer string) {
+	if _, ok := s["nullable"]; ok {
+		n.warn(pointer, "nullable", `nullable was removed in OpenAPI 3.1; add "null" to type instead`)
+	}
+
+	switch t := s["type"].(type) {
+	case string:
+		if t == "null" {
+			delete(s, "type")
+			s["nullable"] = true
+		}
+	case []interface{}:
+		var types []interface{}
+		for _, item := range t {
+			if item == "null" {
+				s["nullable"] = true
+				continue
+			}
+			types = append(types, item)
+		}
+		switch len(types) {
+		case 0:
+			delete(s, "type")
+		case 1:
+			s["type"] = types[0]
+		default:
+			s["type"] = types
+		}
+	}
+
+	if value, ok := s["const"]; ok {
+		if _, hasEnum := s["enum"]; !hasEnum {
+			s["enum"] = []interface{}{value}
+		}
+	}
+
+	for keyword, bound := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		switch value := s[keyword].(type) {
+		case float64:
+			s[bound] = value
+			s[keyword] = true
+		case bool:
+			n.warn(pointer, keyword, fmt.Sprintf("%s must be a number in OpenAPI 3.1", keyword))
+		}
+	}
+
+	if examples, ok := s["examples"].([]interface{}); ok && len(examples) > 0 {
+		if _, hasExample := s["example"]; !hasExample {
+			s["example"] = examples[0]
+		}
+	}
+
+	if items, ok := s["items"].(bool); ok {
+		delete(s, "items")
+		if prefixItems, ok := s["prefixItems"].([]interface{}); ok && !items {
+			if _, hasMax := s["maxItems"]; !hasMax {
+				s["maxItems"] = float64(len(prefixItems))
+			}
+		}
+	}
+	// Arrays may leave items out in 3.1
+	if _, ok := s["items"]; !ok && (s["type"] == "array" || containsValue(s["type"], "array")) {
+		s["items"] = map[string]interface{}{}
+	}
+}
+
+// containsValue reports whether v is a list containing value.
+func containsValue(v interface{}, value interface{}) bool {
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// warn records a warning about a schema keyword.
+func (n *openAPI31Normalizer) warn(pointer, field, message string) {
+	n.warnings = append(n.warnings, ValidationWarning{Path: "#" + pointer, Field: field, Message: message})
+}
+
+// isDataKey reports whether a key holds example or extension data rather
+// than part of the document's structure.
+func isDataKey(key string) bool {
+	return key == "example" || key == "examples" || strings.HasPrefix(key, "x-")
+}
+
+// webhooksFromComponents removes the webhooks loaded as a component
+// callback and returns them.
+func webhooksFromComponents(spec *openapi3.T) map[string]*openapi3.PathItem {
+	if spec.Components == nil {
+		return nil
+	}
+	ref, ok := spec.Components.Callbacks[webhooksCallback]
+	if !ok {
+		return nil
+	}
+	delete(spec.Components.Callbacks, webhooksCallback)
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	return ref.Value.Map()
+}
+
+// deepCopy copies a decoded JSON value.
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = deepCopy(value)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, value := range v {
+			list[i] = deepCopy(value)
+		}
+		return list
+	}
+	return v
+}
+
+// uniqueName returns name, or name with a number appended if it is taken.
+func uniqueName(taken map[string]interface{}, name string) string {
+	if _, exists := taken[name]; !exists {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s%d", name, i)
+		if _, exists := taken[candidate]; !exists {
+			return candidate
+		}
+	}
+}
+
+// escapePointer escapes a JSON pointer token.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SchemaNullable reports whether a schema allows null, through nullable
+// or a "null" type.
+func SchemaNullable(schema *openapi3.Schema) bool {
+	return schema.Nullable || schema.Type.Includes("null")
+}
+
+// SchemaConst returns the const value of a schema.
+func SchemaConst(schema *openapi3.Schema) (interface{}, bool) {
+	value, ok := schema.Extensions["const"]
+	return value, ok
+}
+
+// SchemaPrefixItems returns the schemas of the leading items of a tuple
+// array, or nil. References in them are not resolved.
+func SchemaPrefixItems(schema *openapi3.Schema) []*openapi3.Schema {
+	raw, ok := schema.Extensions["prefixItems"]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var items []*openapi3.Schema
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil
+	}
+	return items
+}
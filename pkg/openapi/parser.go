@@ -57,6 +57,15 @@
 //	// Returns OpenAPI 3.0 ParsedSpec
 //	fmt.Println(spec.OriginalVersion) // "2.0"
 //
+// # OpenAPI 3.1
+//
+// OpenAPI 3.1 specs are normalized into the 3.0 model before loading:
+// "null" in a type array sets Nullable, numeric exclusiveMinimum and
+// exclusiveMaximum become their 3.0 forms, $defs move to
+// components/schemas and webhooks are returned in ParsedSpec.Webhooks.
+// Keywords without a 3.0 equivalent stay on the schema and are read with
+// SchemaConst and SchemaPrefixItems.
+//
 // # Validation
 //
 // By default, specs are validated during parsing. Disable with:
@@ -109,6 +118,13 @@ type ParsedSpec struct {
 	OriginalVersion SpecVersion
 	// Extensions contains all parsed x-cli-* extensions
 	Extensions *SpecExtensions
+	// Webhooks are the webhooks of an OpenAPI 3.1 spec. They describe
+	// requests the API sends, so no commands are generated for them.
+	Webhooks map[string]*openapi3.PathItem
+
+	// warnings are found while reading an OpenAPI 3.1 spec, and reported
+	// by the Validator.
+	warnings []ValidationWarning
 }
 
 // SpecVersion indicates the OpenAPI specification version.
@@ -133,6 +149,8 @@ func (p *Parser) Parse(ctx context.Context, data []byte) (*ParsedSpec, error) {
 	}
 
 	var spec *openapi3.T
+	var webhooks map[string]*openapi3.PathItem
+	var warnings []ValidationWarning
 
 	switch version {
 	case SpecVersionSwagger2:
@@ -140,11 +158,16 @@ func (p *Parser) Parse(ctx context.Context, data []byte) (*ParsedSpec, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse Swagger 2.0 spec: %w", err)
 		}
-	case SpecVersionOpenAPI3, SpecVersionOpenAPI31:
+	case SpecVersionOpenAPI3:
 		spec, err = p.parseOpenAPI3(ctx, data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse OpenAPI 3.x spec: %w", err)
 		}
+	case SpecVersionOpenAPI31:
+		spec, webhooks, warnings, err = p.parseOpenAPI31(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI 3.1 spec: %w", err)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported spec version: %s", version)
 	}
@@ -159,6 +182,8 @@ func (p *Parser) Parse(ctx context.Context, data []byte) (*ParsedSpec, error) {
 		Spec:            spec,
 		OriginalVersion: version,
 		Extensions:      extensions,
+		Webhooks:        webhooks,
+		warnings:        warnings,
 	}, nil
 }
 
@@ -180,17 +205,20 @@ func (p *Parser) ParseReader(ctx context.Context, r io.Reader) (*ParsedSpec, err
 	return p.Parse(ctx, data)
 }
 
-// detectVersion detects the OpenAPI specification version.
+// detectVersion detects the OpenAPI specification version of a JSON or
+// YAML document.
 func (p *Parser) detectVersion(data []byte) (SpecVersion, error) {
-	// Quick detection using basic JSON parsing
-	var versionCheck struct {
-		Swagger string `json:"swagger"`
-		OpenAPI string `json:"openapi"`
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse spec: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &versionCheck); err != nil {
-		return "", fmt.Errorf("failed to parse spec: %w", err)
+	var versionCheck struct {
+		Swagger string
+		OpenAPI string
 	}
+	versionCheck.Swagger, _ = doc["swagger"].(string)
+	versionCheck.OpenAPI, _ = doc["openapi"].(string)
 
 	if versionCheck.Swagger != "" {
 		if strings.HasPrefix(versionCheck.Swagger, "2.") {
@@ -212,8 +240,14 @@ func (p *Parser) detectVersion(data []byte) (SpecVersion, error) {
 	return "", fmt.Errorf("could not determine spec version (missing 'swagger' or 'openapi' field)")
 }
 
-// parseSwagger2 parses a Swagger 2.0 spec and converts it to OpenAPI 3.0.
+// parseSwagger2 parses a JSON or YAML Swagger 2.0 spec and converts it to
+// OpenAPI 3.0.
 func (p *Parser) parseSwagger2(ctx context.Context, data []byte) (*openapi3.T, error) {
+	data, err := toJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode Swagger 2.0: %w", err)
+	}
+
 	var spec2 openapi2.T
 	if err := json.Unmarshal(data, &spec2); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal Swagger 2.0: %w", err)
@@ -255,6 +289,50 @@ func (p *Parser) parseOpenAPI3(ctx context.Context, data []byte) (*openapi3.T, e
 	return spec, nil
 }
 
+// parseOpenAPI31 parses an OpenAPI 3.1 specification. The document is
+// normalized into the 3.0 model first, keeping JSON Schema 2020-12
+// keywords the model has no field for as schema extensions.
+func (p *Parser) parseOpenAPI31(ctx context.Context, data []byte) (*openapi3.T, map[string]*openapi3.PathItem, []ValidationWarning, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode OpenAPI 3.1: %w", err)
+	}
+	warnings, err := normalizeOpenAPI31(doc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode OpenAPI 3.1: %w", err)
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = p.AllowRemoteRefs
+
+	spec, err := loader.LoadFromData(normalized)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load OpenAPI 3.1: %w", err)
+	}
+
+	// Validate if enabled, webhooks included
+	if !p.DisableValidation {
+		if err := spec.Validate(ctx, specValidationOptions(SpecVersionOpenAPI31)...); err != nil {
+			return nil, nil, nil, fmt.Errorf("spec validation failed: %w", err)
+		}
+	}
+
+	return spec, webhooksFromComponents(spec), warnings, nil
+}
+
+// specValidationOptions returns the options to validate a spec of a
+// version with.
+func specValidationOptions(version SpecVersion) []openapi3.ValidationOption {
+	if version == SpecVersionOpenAPI31 {
+		return []openapi3.ValidationOption{openapi3.AllowExtraSiblingFields(openAPI31Fields...)}
+	}
+	return nil
+}
+
 // GetInfo returns basic information about the API from the spec.
 func (ps *ParsedSpec) GetInfo() *SpecInfo {
 	info := &SpecInfo{
@@ -342,6 +420,7 @@ type Operation struct {
 	CLIAsync        *CLIAsync
 	CLIOutput       *CLIOutput
 	CLIWorkflow     *CLIWorkflow
+	CLIWatch        *CLIWatch
 	CLIParentRes    string
 }
 
@@ -429,5 +508,14 @@ func parseOperationExtensions(operation *openapi3.Operation, op *Operation) erro
 		op.CLIWorkflow = parsed
 	}
 
+	// x-cli-watch
+	if watch, ok := operation.Extensions["x-cli-watch"].(map[string]interface{}); ok {
+		parsed, err := parseCLIWatch(watch)
+		if err != nil {
+			return fmt.Errorf("failed to parse x-cli-watch: %w", err)
+		}
+		op.CLIWatch = parsed
+	}
+
 	return nil
 }
@@ -37,6 +37,16 @@ func TestParser_DetectVersion(t *testing.T) {
 			}`,
 			expected: SpecVersionSwagger2,
 		},
+		{
+			name:     "OpenAPI 3.1 YAML",
+			spec:     "openapi: 3.1.0\ninfo:\n  title: Test\n  version: 1.0.0\n",
+			expected: SpecVersionOpenAPI31,
+		},
+		{
+			name:     "Swagger 2.0 YAML",
+			spec:     "swagger: '2.0'\ninfo:\n  title: Test\n  version: 1.0.0\n",
+			expected: SpecVersionSwagger2,
+		},
 		{
 			name: "Invalid spec",
 			spec: `{
@@ -168,6 +178,124 @@ func TestParser_ParseSwagger2(t *testing.T) {
 	}
 }
 
+func TestParser_ParseSwagger2YAML(t *testing.T) {
+	spec := `swagger: "2.0"
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        200:
+          description: Success
+`
+
+	parsed, err := NewParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	if parsed.OriginalVersion != SpecVersionSwagger2 {
+		t.Errorf("expected version Swagger2, got %s", parsed.OriginalVersion)
+	}
+	if op := parsed.Spec.Paths.Value("/users").Get; op == nil || op.Responses.Status(200) == nil {
+		t.Error("expected listUsers with a 200 response")
+	}
+}
+
+func TestParser_ParseOpenAPI31(t *testing.T) {
+	parsed, err := NewParser().ParseFile(context.Background(), "testdata/openapi31.yaml")
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	if parsed.OriginalVersion != SpecVersionOpenAPI31 {
+		t.Errorf("expected version 3.1, got %s", parsed.OriginalVersion)
+	}
+
+	// Path items from components are inlined
+	operations, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operations) != 1 || operations[0].OperationID != "createPet" {
+		t.Fatalf("expected only createPet, got %d operations", len(operations))
+	}
+
+	// Webhooks are kept apart from paths
+	webhook := parsed.Webhooks["petAdopted"]
+	if webhook == nil || webhook.Post == nil || webhook.Post.RequestBody.Value.Content.Get("application/json").Schema.Value == nil {
+		t.Errorf("expected the petAdopted webhook with a resolved schema, got %+v", parsed.Webhooks)
+	}
+	if _, ok := parsed.Spec.Components.Callbacks[webhooksCallback]; ok {
+		t.Error("expected webhooks to be removed from the callbacks")
+	}
+
+	pet := parsed.Spec.Components.Schemas["Pet"].Value
+	props := pet.Properties
+
+	if nickname := props["nickname"].Value; !nickname.Type.Is("string") || !SchemaNullable(nickname) {
+		t.Errorf("expected nullable string nickname, got %v nullable=%v", nickname.Type, nickname.Nullable)
+	}
+
+	age := props["age"].Value
+	if !age.ExclusiveMin || age.Min == nil || *age.Min != 0 {
+		t.Errorf("expected exclusive minimum 0, got %v %v", age.ExclusiveMin, age.Min)
+	}
+
+	if value, ok := SchemaConst(props["kind"].Value); !ok || value != "dog" || len(props["kind"].Value.Enum) != 1 {
+		t.Errorf("expected const dog, got %v %v", value, props["kind"].Value.Enum)
+	}
+
+	location := props["location"].Value
+	if tuple := SchemaPrefixItems(location); len(tuple) != 2 || !tuple[0].Type.Is("number") {
+		t.Errorf("expected a tuple of two numbers, got %v", tuple)
+	}
+	if location.MaxItems == nil || *location.MaxItems != 2 {
+		t.Errorf("expected items: false to limit the tuple to 2 items, got %v", location.MaxItems)
+	}
+
+	// $defs move to components
+	tag := props["tag"]
+	if tag.Ref != "#/components/schemas/Pet.Tag" || tag.Value == nil || tag.Value.Example != "friendly" {
+		t.Errorf("expected tag to reference the hoisted Pet.Tag, got %q %+v", tag.Ref, tag.Value)
+	}
+
+	if props["extra"].Value == nil {
+		t.Error("expected the true schema to become an empty schema")
+	}
+}
+
+func TestParser_ParseOpenAPI31_Warnings(t *testing.T) {
+	spec := `openapi: 3.1.0
+info: {title: Test, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Old:
+      type: integer
+      nullable: true
+      minimum: 1
+      exclusiveMinimum: true
+`
+	parsed, err := NewParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	var messages []string
+	for _, w := range parsed.warnings {
+		messages = append(messages, w.String())
+	}
+	want := []string{
+		"#/components/schemas/Old.nullable: nullable was removed in OpenAPI 3.1; add \"null\" to type instead",
+		"#/components/schemas/Old.exclusiveMinimum: exclusiveMinimum must be a number in OpenAPI 3.1",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings = %q, want %q", messages, want)
+	}
+}
+
 func TestParser_HiddenOperations(t *testing.T) {
 	spec := `{
 		"openapi": "3.0.0",
@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SignatureHeader is the response header a spec's signature may be
+	// sent in.
+	SignatureHeader = "X-Spec-Signature"
+	// SignatureSuffix is appended to the URL or path of a spec to find its
+	// signature when the response has no SignatureHeader.
+	SignatureSuffix = ".sig"
+)
+
+var (
+	// ErrSpecUnsigned is returned for a spec without a signature when
+	// signatures are required.
+	ErrSpecUnsigned = errors.New("spec is not signed")
+	// ErrSignatureInvalid is returned for a spec whose signature was not
+	// made by a trusted key.
+	ErrSignatureInvalid = errors.New("spec signature does not match any trusted key")
+)
+
+// SpecVerifier checks the detached Ed25519 signatures of specs against
+// trusted public keys. Signatures are base64 encoded and cover the spec
+// bytes exactly as served.
+type SpecVerifier struct {
+	// Keys are the trusted public keys. Several keys allow rotating them.
+	Keys []ed25519.PublicKey
+}
+
+// NewSpecVerifier creates a verifier trusting public keys, each PEM
+// encoded or the base64 encoded raw key.
+func NewSpecVerifier(publicKeys []string) (*SpecVerifier, error) {
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("no public keys to verify specs with")
+	}
+
+	v := &SpecVerifier{}
+	for i, publicKey := range publicKeys {
+		key, err := ParsePublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d: %w", i+1, err)
+		}
+		v.Keys = append(v.Keys, key)
+	}
+	return v, nil
+}
+
+// Verify checks that signature is a signature of data by a trusted key.
+// An empty signature returns ErrSpecUnsigned.
+func (v *SpecVerifier) Verify(data []byte, signature string) error {
+	signature = strings.TrimSpace(signature)
+	if signature == "" {
+		return ErrSpecUnsigned
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(decoded) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: expected a base64 encoded Ed25519 signature", ErrSignatureInvalid)
+	}
+	for _, key := range v.Keys {
+		if ed25519.Verify(key, data, decoded) {
+			return nil
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+// SignSpec signs spec data, returning the base64 encoded signature a
+// SpecVerifier checks.
+func SignSpec(data []byte, key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// ParsePublicKey parses an Ed25519 public key, either PEM encoded or the
+// base64 encoded raw key.
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	key = strings.TrimSpace(key)
+
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		edKey, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an Ed25519 key")
+		}
+		return edKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ParsePrivateKey parses an Ed25519 private key, either PEM encoded
+// PKCS #8 or the base64 encoded seed or raw key.
+func ParsePrivateKey(key string) (ed25519.PrivateKey, error) {
+	key = strings.TrimSpace(key)
+
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		edKey, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an Ed25519 key")
+		}
+		return edKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("invalid private key: expected %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
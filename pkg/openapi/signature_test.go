@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpecVerifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	verifier, err := NewSpecVerifier([]string{base64.StdEncoding.EncodeToString(otherKey), pemKey})
+	if err != nil {
+		t.Fatalf("NewSpecVerifier() error = %v", err)
+	}
+
+	data := []byte("openapi: 3.0.0")
+	signature := SignSpec(data, privateKey)
+	if err := verifier.Verify(data, signature+"\n"); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := verifier.Verify([]byte("openapi: 3.0.1"), signature); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() of changed data error = %v, want ErrSignatureInvalid", err)
+	}
+	if err := verifier.Verify(data, "not a signature"); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() of garbage error = %v, want ErrSignatureInvalid", err)
+	}
+	if err := verifier.Verify(data, ""); !errors.Is(err, ErrSpecUnsigned) {
+		t.Errorf("Verify() without signature error = %v, want ErrSpecUnsigned", err)
+	}
+
+	seed := base64.StdEncoding.EncodeToString(privateKey.Seed())
+	parsed, err := ParsePrivateKey(seed)
+	if err != nil || !parsed.Equal(privateKey) {
+		t.Errorf("ParsePrivateKey() = %v, %v", parsed, err)
+	}
+
+	if _, err := NewSpecVerifier(nil); err == nil {
+		t.Error("Expected an error without keys")
+	}
+}
+
+func TestLoader_Verifier(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &SpecVerifier{Keys: []ed25519.PublicKey{publicKey}}
+
+	specData := func(version string) []byte {
+		return []byte(`{"openapi": "3.0.0", "info": {"title": "Signed", "version": "` + version + `"}, "paths": {}}`)
+	}
+	v1, v2 := specData("1.0.0"), specData("2.0.0")
+
+	// served is what the server returns; header and sibling are where
+	// its signature is
+	served, header, sibling := v1, "", SignSpec(v1, privateKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.json":
+			if header != "" {
+				w.Header().Set(SignatureHeader, header)
+			}
+			_, _ = w.Write(served)
+		case "/openapi.json.sig":
+			if sibling == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(sibling))
+		}
+	}))
+	defer server.Close()
+	specURL := server.URL + "/openapi.json"
+
+	cache := newMockCache()
+	var rejected []error
+	loader := NewLoader(cache)
+	loader.Verifier = verifier
+	loader.OnRejected = func(_ context.Context, _ string, err error) {
+		rejected = append(rejected, err)
+	}
+	version := func() string {
+		t.Helper()
+		spec, err := loader.LoadFromURL(context.Background(), specURL, &LoadOptions{ForceRefresh: true})
+		if err != nil {
+			t.Fatalf("LoadFromURL() error = %v", err)
+		}
+		return spec.GetInfo().Version
+	}
+
+	// Signed by a sibling file
+	if got := version(); got != "1.0.0" {
+		t.Errorf("Expected version 1.0.0, got %s", got)
+	}
+
+	// Signed in a header
+	served, header, sibling = v2, SignSpec(v2, privateKey), ""
+	if got := version(); got != "2.0.0" {
+		t.Errorf("Expected version 2.0.0, got %s", got)
+	}
+
+	// Unsigned and invalid specs are refused
+	served, header = v1, ""
+	if _, err := loader.LoadFromURL(context.Background(), specURL, &LoadOptions{ForceRefresh: true}); !errors.Is(err, ErrSpecUnsigned) {
+		t.Errorf("Expected ErrSpecUnsigned, got %v", err)
+	}
+	header = SignSpec(v2, privateKey)
+	if _, err := loader.LoadFromURL(context.Background(), specURL, &LoadOptions{ForceRefresh: true}); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+	}
+
+	// An expired cache falls back to the last verified copy
+	cached, _ := cache.Get(context.Background(), specURL)
+	cached.FetchedAt = time.Now().Add(-time.Hour)
+	_ = cache.Set(context.Background(), specURL, cached)
+	spec, err := loader.LoadFromURL(context.Background(), specURL, nil)
+	if err != nil {
+		t.Fatalf("LoadFromURL() error = %v", err)
+	}
+	if got := spec.GetInfo().Version; got != "2.0.0" || len(rejected) != 1 || !errors.Is(rejected[0], ErrSignatureInvalid) {
+		t.Errorf("Expected the verified 2.0.0 and a rejection, got %s and %v", got, rejected)
+	}
+
+	// A tampered cached copy is not used
+	cached, _ = cache.Get(context.Background(), specURL)
+	cached.Data, cached.FetchedAt = v1, time.Now()
+	_ = cache.Set(context.Background(), specURL, cached)
+	if _, err := loader.LoadFromURL(context.Background(), specURL, nil); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected the tampered cache to be refetched and refused, got %v", err)
+	}
+
+	// Files are signed by a sibling file
+	path := filepath.Join(t.TempDir(), "openapi.json")
+	if err := os.WriteFile(path, v1, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.LoadFromFile(context.Background(), path); !errors.Is(err, ErrSpecUnsigned) {
+		t.Errorf("Expected ErrSpecUnsigned for an unsigned file, got %v", err)
+	}
+	if err := os.WriteFile(path+SignatureSuffix, []byte(SignSpec(v1, privateKey)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.LoadFromFile(context.Background(), path); err != nil {
+		t.Errorf("LoadFromFile() error = %v", err)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 // Validator validates OpenAPI specs and CLI extensions.
@@ -51,7 +54,7 @@ func (v *Validator) Validate(ctx context.Context, spec *ParsedSpec) (*Validation
 	}
 
 	// Validate basic OpenAPI structure
-	if err := spec.Spec.Validate(ctx); err != nil {
+	if err := spec.Spec.Validate(ctx, specValidationOptions(spec.OriginalVersion)...); err != nil {
 		result.Valid = false
 		result.Errors = append(result.Errors, ValidationError{
 			Path:    "spec",
@@ -80,6 +83,9 @@ func (v *Validator) Validate(ctx context.Context, spec *ParsedSpec) (*Validation
 
 	v.validateOperations(operations, result)
 
+	// Check JSON Schema constructs against the spec's version
+	v.validateSchemas(spec, operations, result)
+
 	// Check for duplicate command names
 	v.checkDuplicateCommands(operations, result)
 
@@ -376,55 +382,296 @@ func (v *Validator) validateOperations(operations []*Operation, result *Validati
 
 		// Validate x-cli-workflow
 		if workflow := op.CLIWorkflow; workflow != nil {
-			for i, step := range workflow.Steps {
-				if step.ID == "" {
-					result.Errors = append(result.Errors, ValidationError{
-						Path:    path,
-						Field:   fmt.Sprintf("x-cli-workflow.steps[%d].id", i),
-						Message: "Workflow step ID is required",
-					})
-					result.Valid = false
-				}
-				if step.Request == nil {
+			v.validateWorkflow(path, "x-cli-workflow", workflow, workflow.Workflows, result)
+		}
+	}
+}
+
+// validateWorkflow validates the steps of an x-cli-workflow, or of one of
+// its named workflows, under field. named are the workflows call steps may
+// run.
+func (v *Validator) validateWorkflow(path, field string, workflow *CLIWorkflow, named map[string]*CLIWorkflow, result *ValidationResult) {
+	fail := func(field, message string) {
+		result.Errors = append(result.Errors, ValidationError{Path: path, Field: field, Message: message})
+		result.Valid = false
+	}
+
+	for i, step := range workflow.Steps {
+		stepField := fmt.Sprintf("%s.steps[%d]", field, i)
+		if step.ID == "" {
+			fail(stepField+".id", "Workflow step ID is required")
+		}
+		v.validateWorkflowStep(stepField, step, named, fail)
+	}
+
+	// Named workflows can also call the workflows they declare themselves
+	for name, sub := range workflow.Workflows {
+		visible := make(map[string]*CLIWorkflow, len(named)+len(sub.Workflows))
+		for n, w := range named {
+			visible[n] = w
+		}
+		for n, w := range sub.Workflows {
+			visible[n] = w
+		}
+		v.validateWorkflow(path, fmt.Sprintf("%s.workflows.%s", field, name), sub, visible, result)
+	}
+}
+
+// validateWorkflowStep checks the fields a step of its type needs, and its
+// rollback action.
+func (v *Validator) validateWorkflowStep(stepField string, step *WorkflowStep, named map[string]*CLIWorkflow, fail func(field, message string)) {
+	switch step.Type {
+	case "", "api-call":
+		if step.Request == nil {
+			fail(stepField+".request", "Workflow step request is required")
+			break
+		}
+		if step.Request.Method == "" {
+			fail(stepField+".request.method", "Request method is required")
+		}
+		if step.Request.URL == "" {
+			fail(stepField+".request.url", "Request URL is required")
+		}
+	case "plugin":
+		if step.Plugin == nil || step.Plugin.Plugin == "" {
+			fail(stepField+".plugin", "Plugin name is required")
+		} else if step.Plugin.Command == "" {
+			fail(stepField+".command", "Plugin command is required")
+		}
+	case "loop":
+		if step.ForEach == "" {
+			fail(stepField+".foreach", "Loop collection is required")
+		}
+		v.validateNestedSteps(stepField, step, named, fail)
+	case "parallel":
+		v.validateNestedSteps(stepField, step, named, fail)
+	case "prompt":
+		if step.Prompt == nil || step.Prompt.Message == "" {
+			fail(stepField+".prompt.message", "Prompt message is required")
+		}
+	case "set":
+		if step.Set == nil || len(step.Set.Variables) == 0 {
+			fail(stepField+".set.variables", "Set step variables are required")
+		}
+	case "assert":
+		if step.Assert == nil || step.Assert.Condition == "" {
+			fail(stepField+".assert.condition", "Assert condition is required")
+		}
+	case "call":
+		if step.Call == nil || step.Call.Workflow == "" {
+			fail(stepField+".call.workflow", "Called workflow name is required")
+		} else if _, ok := named[step.Call.Workflow]; !ok {
+			fail(stepField+".call.workflow", fmt.Sprintf("Unknown workflow: %s", step.Call.Workflow))
+		}
+	default:
+		fail(stepField+".type", fmt.Sprintf("Invalid workflow step type: %s", step.Type))
+	}
+
+	// Validate foreach/as pairing
+	if (step.ForEach != "" && step.As == "") || (step.ForEach == "" && step.As != "") {
+		fail(stepField, "foreach and as must be used together")
+	}
+
+	if step.Rollback != nil {
+		switch step.Type {
+		case "prompt", "set", "assert":
+			fail(stepField+".rollback", fmt.Sprintf("A %s step has no side effects to roll back", step.Type))
+		}
+		v.validateWorkflowStep(stepField+".rollback", step.Rollback, named, fail)
+	}
+}
+
+// validateNestedSteps checks the steps a loop or parallel step runs.
+func (v *Validator) validateNestedSteps(stepField string, step *WorkflowStep, named map[string]*CLIWorkflow, fail func(field, message string)) {
+	if len(step.Steps) == 0 {
+		fail(stepField+".steps", fmt.Sprintf("A %s step needs steps to run", step.Type))
+	}
+	for i, nested := range step.Steps {
+		nestedField := fmt.Sprintf("%s.steps[%d]", stepField, i)
+		if nested.ID == "" {
+			fail(nestedField+".id", "Workflow step ID is required")
+		}
+		v.validateWorkflowStep(nestedField, nested, named, fail)
+	}
+}
+
+// openAPI31Keywords are schema keywords that need OpenAPI 3.1.
+var openAPI31Keywords = []string{"$defs", "const", "prefixItems"}
+
+// validateSchemas checks the schemas of the spec for constructs its
+// OpenAPI version does not support, and for 3.1 constructs the generated
+// flags cannot fully represent.
+func (v *Validator) validateSchemas(spec *ParsedSpec, operations []*Operation, result *ValidationResult) {
+	is31 := spec.OriginalVersion == SpecVersionOpenAPI31
+	result.Warnings = append(result.Warnings, spec.warnings...)
+
+	if _, ok := spec.Spec.Extensions["webhooks"]; ok && !is31 {
+		result.Errors = append(result.Errors, ValidationError{
+			Path:    "webhooks",
+			Message: "webhooks require OpenAPI 3.1",
+		})
+		result.Valid = false
+	}
+
+	walkSpecSchemas(spec, operations, func(path string, schema *openapi3.Schema) {
+		types := schema.Type.Slice()
+
+		if !is31 {
+			if len(types) > 1 || schema.Type.Includes("null") {
+				result.Errors = append(result.Errors, ValidationError{
+					Path:    path,
+					Field:   "type",
+					Message: "type arrays and the null type require OpenAPI 3.1; use nullable",
+				})
+				result.Valid = false
+			}
+			for _, keyword := range openAPI31Keywords {
+				if _, ok := schema.Extensions[keyword]; ok {
 					result.Errors = append(result.Errors, ValidationError{
 						Path:    path,
-						Field:   fmt.Sprintf("x-cli-workflow.steps[%d].request", i),
-						Message: "Workflow step request is required",
+						Field:   keyword,
+						Message: fmt.Sprintf("%s requires OpenAPI 3.1", keyword),
 					})
 					result.Valid = false
-				} else {
-					if step.Request.Method == "" {
-						result.Errors = append(result.Errors, ValidationError{
-							Path:    path,
-							Field:   fmt.Sprintf("x-cli-workflow.steps[%d].request.method", i),
-							Message: "Request method is required",
-						})
-						result.Valid = false
-					}
-					if step.Request.URL == "" {
-						result.Errors = append(result.Errors, ValidationError{
-							Path:    path,
-							Field:   fmt.Sprintf("x-cli-workflow.steps[%d].request.url", i),
-							Message: "Request URL is required",
-						})
-						result.Valid = false
-					}
 				}
+			}
+			return
+		}
 
-				// Validate foreach/as pairing
-				if (step.ForEach != "" && step.As == "") || (step.ForEach == "" && step.As != "") {
-					result.Errors = append(result.Errors, ValidationError{
-						Path:    path,
-						Field:   fmt.Sprintf("x-cli-workflow.steps[%d]", i),
-						Message: "foreach and as must be used together",
-					})
-					result.Valid = false
+		if value, ok := SchemaConst(schema); ok && len(types) > 0 && !typePermits(types, value) {
+			result.Errors = append(result.Errors, ValidationError{
+				Path:    path,
+				Field:   "const",
+				Message: fmt.Sprintf("const value %v is not of type %s", value, strings.Join(types, " or ")),
+			})
+			result.Valid = false
+		}
+
+		if len(types) > 1 {
+			result.Warnings = append(result.Warnings, ValidationWarning{
+				Path:    path,
+				Field:   "type",
+				Message: fmt.Sprintf("flags only accept the first type, %s", types[0]),
+			})
+		}
+
+		for i, item := range SchemaPrefixItems(schema) {
+			if _, isRef := item.Extensions["$ref"]; isRef {
+				result.Warnings = append(result.Warnings, ValidationWarning{
+					Path:    path,
+					Field:   fmt.Sprintf("prefixItems[%d]", i),
+					Message: "references in prefixItems are not resolved; the value is sent as a string",
+				})
+			}
+		}
+	})
+}
+
+// typePermits reports whether a JSON value is of one of the types.
+func typePermits(types []string, value interface{}) bool {
+	for _, t := range types {
+		switch value := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && value == float64(int64(value))) {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkSpecSchemas calls fn for each schema of the spec's components and
+// operations, and for their subschemas, once each.
+func walkSpecSchemas(spec *ParsedSpec, operations []*Operation, fn func(path string, schema *openapi3.Schema)) {
+	seen := make(map[*openapi3.Schema]bool)
+
+	var walk func(path string, ref *openapi3.SchemaRef)
+	walk = func(path string, ref *openapi3.SchemaRef) {
+		if ref == nil || ref.Value == nil || seen[ref.Value] {
+			return
+		}
+		schema := ref.Value
+		seen[schema] = true
+		fn(path, schema)
+
+		for _, name := range sortedSchemaNames(schema.Properties) {
+			walk(path+".properties."+name, schema.Properties[name])
+		}
+		walk(path+".items", schema.Items)
+		walk(path+".additionalProperties", schema.AdditionalProperties.Schema)
+		walk(path+".not", schema.Not)
+		for i, sub := range schema.AllOf {
+			walk(fmt.Sprintf("%s.allOf[%d]", path, i), sub)
+		}
+		for i, sub := range schema.OneOf {
+			walk(fmt.Sprintf("%s.oneOf[%d]", path, i), sub)
+		}
+		for i, sub := range schema.AnyOf {
+			walk(fmt.Sprintf("%s.anyOf[%d]", path, i), sub)
+		}
+	}
+
+	if spec.Spec.Components != nil {
+		for _, name := range sortedSchemaNames(spec.Spec.Components.Schemas) {
+			walk("components.schemas."+name, spec.Spec.Components.Schemas[name])
+		}
+	}
+
+	for _, op := range operations {
+		path := fmt.Sprintf("%s %s", op.Method, op.Path)
+		for _, param := range op.Operation.Parameters {
+			if param.Value != nil {
+				walk(path+" parameter "+param.Value.Name, param.Value.Schema)
+			}
+		}
+		if body := op.Operation.RequestBody; body != nil && body.Value != nil {
+			for mediaType, content := range body.Value.Content {
+				walk(path+" requestBody "+mediaType, content.Schema)
+			}
+		}
+		if op.Operation.Responses != nil {
+			for status, response := range op.Operation.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				for mediaType, content := range response.Value.Content {
+					walk(path+" response "+status+" "+mediaType, content.Schema)
 				}
 			}
 		}
 	}
 }
 
+// sortedSchemaNames returns the names of schemas in order.
+func sortedSchemaNames(schemas openapi3.Schemas) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // checkDuplicateCommands checks for duplicate CLI command names.
 func (v *Validator) checkDuplicateCommands(operations []*Operation, result *ValidationResult) {
 	commandMap := make(map[string][]string)
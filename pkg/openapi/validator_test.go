@@ -703,6 +703,79 @@ func TestValidator_Validate_WorkflowMissingStepID(t *testing.T) {
 	}
 }
 
+func TestValidator_Validate_WorkflowStepTypes(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {
+			"/test": {
+				"post": {
+					"operationId": "test",
+					"responses": {"200": {"description": "OK"}},
+					"x-cli-workflow": {
+						"workflows": {
+							"cleanup": {
+								"steps": [{"id": "noop", "type": "set", "set": {"variables": {"done": "true"}}}]
+							}
+						},
+						"steps": [
+							{"id": "ask", "type": "prompt", "prompt": {"type": "approval", "message": "Proceed?"}},
+							{"id": "check", "type": "assert", "assert": {"condition": "flags.force"}},
+							{"id": "cleanup", "type": "call", "call": {"workflow": "cleanup"}},
+							{"id": "missing", "type": "call", "call": {"workflow": "unknown"}},
+							{"id": "empty", "type": "set"},
+							{"id": "bogus", "type": "bogus"},
+							{"id": "roles", "type": "plugin", "plugin": "aws-cli", "command": "create-role",
+								"rollback": {"type": "plugin", "plugin": {"plugin": "aws-cli"}}},
+							{"id": "undo-check", "type": "assert", "assert": {"condition": "true"},
+								"rollback": {"request": {"method": "DELETE", "url": "/things"}}},
+							{"id": "pools", "type": "loop", "collection": "flags.pools", "iterator": "pool", "parallel": true,
+								"steps": [{"id": "pool", "request": {"method": "POST", "url": "/pools"}}]},
+							{"id": "setup", "type": "parallel", "steps": [{"request": {"method": "POST", "url": "/vpcs"}}]},
+							{"id": "nothing", "type": "loop", "foreach": "flags.pools", "as": "pool"}
+						]
+					}
+				}
+			}
+		}
+	}`
+
+	parser := NewParser()
+	ctx := context.Background()
+	parsed, err := parser.Parse(ctx, []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	validator := NewValidator()
+	result, err := validator.Validate(ctx, parsed)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, e := range result.Errors {
+		fields[e.Field] = true
+	}
+	want := []string{
+		"x-cli-workflow.steps[3].call.workflow",
+		"x-cli-workflow.steps[4].set.variables",
+		"x-cli-workflow.steps[5].type",
+		"x-cli-workflow.steps[6].rollback.command",
+		"x-cli-workflow.steps[7].rollback",
+		"x-cli-workflow.steps[9].steps[0].id",
+		"x-cli-workflow.steps[10].steps",
+	}
+	for _, field := range want {
+		if !fields[field] {
+			t.Errorf("expected an error for %s, got %v", field, result.Errors)
+		}
+	}
+	if len(result.Errors) != len(want) {
+		t.Errorf("expected only %d errors, got %v", len(want), result.Errors)
+	}
+}
+
 func TestValidator_Validate_DuplicateCommands(t *testing.T) {
 	spec := `{
 		"openapi": "3.0.0",
@@ -816,3 +889,78 @@ func TestIsValidHexColor(t *testing.T) {
 		})
 	}
 }
+
+func TestValidator_Validate_OpenAPI31Schemas(t *testing.T) {
+	spec := `openapi: 3.1.0
+info: {title: Test, version: 1.0.0}
+paths: {}
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        kind: {type: integer, const: widget}
+        size: {type: [integer, string]}
+`
+
+	ctx := context.Background()
+	parsed, err := NewParser().Parse(ctx, []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	result, err := NewValidator().Validate(ctx, parsed)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Message != "const value widget is not of type integer" {
+		t.Errorf("expected a const type error, got %v", result.Errors)
+	}
+
+	found := false
+	for _, w := range result.Warnings {
+		if w.Message == "flags only accept the first type, integer" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a type array warning, got %v", result.Warnings)
+	}
+}
+
+func TestValidator_Validate_OpenAPI31KeywordsIn30(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.3",
+		"info": {"title": "Test", "version": "1.0.0"},
+		"paths": {},
+		"components": {
+			"schemas": {
+				"Item": {"type": "string", "const": "widget"}
+			}
+		}
+	}`
+
+	ctx := context.Background()
+	parser := NewParser()
+	parser.DisableValidation = true
+	parsed, err := parser.Parse(ctx, []byte(spec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+
+	result, err := NewValidator().Validate(ctx, parsed)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	found := false
+	for _, e := range result.Errors {
+		if e.Field == "const" && e.Message == "const requires OpenAPI 3.1" {
+			found = true
+		}
+	}
+	if result.Valid || !found {
+		t.Errorf("expected a const error, got %v", result.Errors)
+	}
+}
@@ -0,0 +1,175 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// CSVFormatter formats lists as CSV, with a header row of the columns.
+type CSVFormatter struct {
+	table *TableFormatter
+}
+
+// NewCSVFormatter creates a new CSV formatter.
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{
+		table: NewTableFormatter(),
+	}
+}
+
+// Name returns the formatter name.
+func (f *CSVFormatter) Name() string {
+	return "csv"
+}
+
+// Supports returns true if the formatter can handle the given data type.
+// CSV formatter supports the same data as tables.
+func (f *CSVFormatter) Supports(data interface{}) bool {
+	return f.table.Supports(data)
+}
+
+// Format formats the data as CSV and writes it to the writer. Lists get a
+// row per item, other data a single row.
+func (f *CSVFormatter) Format(w io.Writer, data interface{}, config *FormatConfig) error {
+	if config == nil {
+		config = NewFormatConfig()
+	}
+
+	// Structs are read through their JSON form, like responses
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return fmt.Errorf("failed to decode data: %w", err)
+	}
+	items, ok := decoded.([]interface{})
+	if !ok {
+		items = []interface{}{decoded}
+	}
+
+	cw := csv.NewWriter(w)
+	var columns []*openapi.TableColumn
+	for i, item := range items {
+		if i == 0 {
+			columns = f.columns(config, item)
+			if err := f.writeHeader(cw, columns, config); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(f.row(item, columns, config)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatStream writes a row per item as it is read. The columns come from
+// the output config or the first item.
+func (f *CSVFormatter) FormatStream(w io.Writer, items *ItemStream, config *FormatConfig) error {
+	if config == nil {
+		config = NewFormatConfig()
+	}
+
+	cw := csv.NewWriter(w)
+	var columns []*openapi.TableColumn
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if columns == nil {
+			columns = f.columns(config, item)
+			if err := f.writeHeader(cw, columns, config); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(f.row(item, columns, config)); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// columns returns the configured table columns, else the sorted keys of
+// the first item.
+func (f *CSVFormatter) columns(config *FormatConfig, first interface{}) []*openapi.TableColumn {
+	if config.OutputConfig != nil && config.OutputConfig.Table != nil && len(config.OutputConfig.Table.Columns) > 0 {
+		return config.OutputConfig.Table.Columns
+	}
+
+	object, ok := first.(map[string]interface{})
+	if !ok {
+		return []*openapi.TableColumn{{Header: "value"}}
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	columns := make([]*openapi.TableColumn, len(keys))
+	for i, key := range keys {
+		columns[i] = &openapi.TableColumn{Field: key, Header: key}
+	}
+	return columns
+}
+
+// writeHeader writes the header row when headers are shown.
+func (f *CSVFormatter) writeHeader(cw *csv.Writer, columns []*openapi.TableColumn, config *FormatConfig) error {
+	if !config.ShowHeaders {
+		return nil
+	}
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+		if headers[i] == "" {
+			headers[i] = col.Field
+		}
+	}
+	return cw.Write(headers)
+}
+
+// row returns the cells of an item.
+func (f *CSVFormatter) row(item interface{}, columns []*openapi.TableColumn, config *FormatConfig) []string {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = f.table.transformValue(csvValue(streamField(item, col.Field)), col.Transform, config)
+	}
+	return row
+}
+
+// csvValue formats a value as a cell, with nested values as JSON.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+func TestCSVFormatterFormat(t *testing.T) {
+	formatter := NewCSVFormatter()
+	if formatter.Name() != "csv" {
+		t.Errorf("Expected name 'csv', got '%s'", formatter.Name())
+	}
+
+	type user struct {
+		ID    int               `json:"id"`
+		Name  string            `json:"name"`
+		Roles []string          `json:"roles"`
+		Meta  map[string]string `json:"meta,omitempty"`
+	}
+
+	tests := []struct {
+		name   string
+		data   interface{}
+		config *FormatConfig
+		want   string
+	}{
+		{
+			name:   "structs",
+			data:   []user{{ID: 1, Name: "Ann, Jr.", Roles: []string{"admin"}}, {ID: 2, Name: "Bob"}},
+			config: NewFormatConfig(),
+			want:   "id,name,roles\n1,\"Ann, Jr.\",\"[\"\"admin\"\"]\"\n2,Bob,\n",
+		},
+		{
+			name:   "single map without headers",
+			data:   map[string]interface{}{"b": true, "a": 1.5},
+			config: &FormatConfig{},
+			want:   "1.5,true\n",
+		},
+		{
+			name: "configured columns",
+			data: []map[string]interface{}{{"id": "u1", "name": "ann"}},
+			config: NewFormatConfig().WithOutputConfig(&openapi.CLIOutput{Table: &openapi.TableConfig{Columns: []*openapi.TableColumn{
+				{Field: "name", Header: "Name", Transform: "uppercase"},
+				{Field: "missing"},
+			}}}),
+			want: "Name,missing\nANN,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, tt.data, tt.config); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Format() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
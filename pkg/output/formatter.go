@@ -6,7 +6,10 @@
 //
 // # Key Features
 //
-//   - Multiple output formats (JSON, YAML, table, CSV, template)
+//   - Multiple output formats (JSON, YAML, table, CSV, JSON lines, template)
+//   - Streaming of large lists item by item via StreamFormatter
+//   - Paging of long terminal output through $PAGER, less or a built-in
+//     pager
 //   - Pretty-printing and colored output support
 //   - Data transformation via JQ-like expressions
 //   - Field selection and column configuration
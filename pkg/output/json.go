@@ -1,6 +1,7 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -86,6 +87,75 @@ func (f *JSONFormatter) Format(w io.Writer, data interface{}, config *FormatConf
 	return nil
 }
 
+// FormatStream writes the items of a list as a JSON array, one item at a
+// time. The output is the same as formatting the whole list.
+func (f *JSONFormatter) FormatStream(w io.Writer, items *ItemStream, config *FormatConfig) error {
+	if config == nil {
+		config = NewFormatConfig()
+	}
+	if !items.IsList() {
+		item, err := items.Next()
+		if err != nil {
+			return err
+		}
+		return f.Format(w, item, config)
+	}
+
+	pretty := config.Pretty && !config.Compact
+	var buf bytes.Buffer
+	count := 0
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Each item is written with the separator before it
+		buf.Reset()
+		switch {
+		case count == 0 && pretty:
+			buf.WriteString("[\n" + f.indent)
+		case count == 0:
+			buf.WriteString("[")
+		case pretty:
+			buf.WriteString(",\n" + f.indent)
+		default:
+			buf.WriteString(",")
+		}
+
+		var data []byte
+		if pretty {
+			data, err = json.MarshalIndent(item, f.indent, f.indent)
+		} else {
+			data, err = json.Marshal(item)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		buf.Write(data)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		count++
+	}
+
+	end := "]"
+	switch {
+	case count == 0:
+		end = "[]"
+	case pretty:
+		end = "\n]"
+	}
+	if !config.Compact {
+		end += "\n"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
+
 // SetIndent sets the indentation string for pretty printing.
 func (f *JSONFormatter) SetIndent(indent string) *JSONFormatter {
 	f.indent = indent
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSONLinesFormatter formats output as JSON lines: one compact JSON
+// document per item of a list, suitable for line-oriented tools.
+type JSONLinesFormatter struct{}
+
+// NewJSONLinesFormatter creates a new JSON lines formatter.
+func NewJSONLinesFormatter() *JSONLinesFormatter {
+	return &JSONLinesFormatter{}
+}
+
+// Name returns the formatter name.
+func (f *JSONLinesFormatter) Name() string {
+	return "jsonl"
+}
+
+// Supports returns true if the formatter can handle the given data type.
+// JSON lines formatter can handle any data type.
+func (f *JSONLinesFormatter) Supports(data interface{}) bool {
+	return true
+}
+
+// Format writes each item of a slice or array on its own line. Other
+// data is written as a single line.
+func (f *JSONLinesFormatter) Format(w io.Writer, data interface{}, _ *FormatConfig) error {
+	v := reflect.ValueOf(data)
+	if data == nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return f.writeLine(w, data)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := f.writeLine(w, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatStream writes each item on its own line as it is read.
+func (f *JSONLinesFormatter) FormatStream(w io.Writer, items *ItemStream, _ *FormatConfig) error {
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f.writeLine(w, item); err != nil {
+			return err
+		}
+	}
+}
+
+// writeLine writes a value as a line of compact JSON.
+func (f *JSONLinesFormatter) writeLine(w io.Writer, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONLinesFormatterFormat(t *testing.T) {
+	formatter := NewJSONLinesFormatter()
+	if formatter.Name() != "jsonl" {
+		t.Errorf("Expected name 'jsonl', got '%s'", formatter.Name())
+	}
+
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"slice", []map[string]int{{"id": 1}, {"id": 2}}, "{\"id\":1}\n{\"id\":2}\n"},
+		{"map", map[string]string{"id": "a"}, "{\"id\":\"a\"}\n"},
+		{"empty slice", []string{}, ""},
+		{"nil", nil, "null\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, tt.data, nil); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Format() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
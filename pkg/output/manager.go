@@ -15,6 +15,7 @@ type Manager struct {
 	defaultFormat  string
 	config         *FormatConfig
 	templateEngine *TemplateEngine
+	pager          *Pager
 }
 
 // NewManager creates a new output manager with default formatters.
@@ -30,6 +31,8 @@ func NewManager() *Manager {
 	m.RegisterFormatter(NewJSONFormatter())
 	m.RegisterFormatter(NewYAMLFormatter())
 	m.RegisterFormatter(NewTableFormatter())
+	m.RegisterFormatter(NewJSONLinesFormatter())
+	m.RegisterFormatter(NewCSVFormatter())
 
 	return m
 }
@@ -63,6 +66,17 @@ func (m *Manager) GetConfig() *FormatConfig {
 	return m.config
 }
 
+// SetPager sets the pager that the Print methods page long output
+// through. A nil pager turns paging off, as it is by default.
+func (m *Manager) SetPager(pager *Pager) {
+	m.pager = pager
+}
+
+// GetPager returns the pager, or nil when paging is off.
+func (m *Manager) GetPager() *Pager {
+	return m.pager
+}
+
 // Format formats data using the specified format.
 func (m *Manager) Format(w io.Writer, data interface{}, format string) error {
 	if format == "" {
@@ -101,6 +115,48 @@ func (m *Manager) FormatWithConfig(w io.Writer, data interface{}, format string,
 	return formatter.Format(w, data, config)
 }
 
+// FormatStream formats a JSON document read from r. With stream settings
+// in the output config, the items at their items path are formatted as
+// they are decoded by formatters that are StreamFormatters; others get
+// the collected items. Only the items are output, not the rest of the
+// document.
+func (m *Manager) FormatStream(w io.Writer, r io.Reader, format string, config *FormatConfig) error {
+	if format == "" {
+		format = m.defaultFormat
+	}
+	if config == nil {
+		config = m.config
+	}
+
+	formatter, err := m.GetFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	itemsPath := ""
+	if config.OutputConfig != nil && config.OutputConfig.Stream != nil {
+		itemsPath = config.OutputConfig.Stream.ItemsPath
+	}
+	items, err := NewItemStream(r, itemsPath)
+	if err != nil {
+		return err
+	}
+
+	// Sorting needs all the items
+	if streamer, ok := formatter.(StreamFormatter); ok && items.IsList() && config.SortBy == "" {
+		return streamer.FormatStream(w, items, config)
+	}
+
+	data, err := items.Collect()
+	if err != nil {
+		return err
+	}
+	if !formatter.Supports(data) {
+		return fmt.Errorf("formatter '%s' does not support data type %T", format, data)
+	}
+	return formatter.Format(w, data, config)
+}
+
 // FormatResult formats a Result object.
 func (m *Manager) FormatResult(w io.Writer, result *Result, format string) error {
 	if format == "" {
@@ -265,12 +321,16 @@ func (m *Manager) ValidateFormat(format string, data interface{}) error {
 
 // Print is a convenience method to format and print to stdout.
 func (m *Manager) Print(data interface{}, format string) error {
-	return m.Format(os.Stdout, data, format)
+	return m.page(func(w io.Writer) error {
+		return m.Format(w, data, format)
+	})
 }
 
 // PrintResult is a convenience method to format and print a Result to stdout.
 func (m *Manager) PrintResult(result *Result, format string) error {
-	return m.FormatResult(os.Stdout, result, format)
+	return m.page(func(w io.Writer) error {
+		return m.FormatResult(w, result, format)
+	})
 }
 
 // PrintError is a convenience method to format and print an error to stderr.
@@ -280,7 +340,19 @@ func (m *Manager) PrintError(err error, format string) error {
 
 // PrintSuccess is a convenience method to format and print a success message to stdout.
 func (m *Manager) PrintSuccess(message string, data interface{}, format string) error {
-	return m.FormatSuccess(os.Stdout, message, data, format)
+	return m.page(func(w io.Writer) error {
+		return m.FormatSuccess(w, message, data, format)
+	})
+}
+
+// page runs print with stdout through the pager.
+func (m *Manager) page(print func(w io.Writer) error) error {
+	w := m.pager.Start(os.Stdout)
+	err := print(w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 // RenderMessage renders a message template with the given data.
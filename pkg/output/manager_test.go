@@ -269,8 +269,9 @@ func TestManagerIsFormatSupported(t *testing.T) {
 		{"json", true},
 		{"yaml", true},
 		{"table", true},
+		{"jsonl", true},
+		{"csv", true},
 		{"xml", false},
-		{"csv", false},
 	}
 
 	for _, tt := range tests {
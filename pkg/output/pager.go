@@ -0,0 +1,212 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is the pager used when $PAGER is not set. -F quits when
+// the output fits on one screen, -R passes colours through and -X leaves
+// the output on the screen after quitting.
+const defaultPager = "less -FRX"
+
+// Pager pages long output written to a terminal through $PAGER, less by
+// default, or a built-in pager with scrolling and search when neither is
+// installed. Output that is not written to a terminal is never paged.
+type Pager struct {
+	// Command is the pager command line. Empty uses $PAGER, then less.
+	// An empty $PAGER or cat turns paging off.
+	Command string
+}
+
+// NewPager creates a new pager using $PAGER.
+func NewPager() *Pager {
+	return &Pager{}
+}
+
+// Start returns a writer that pages what is written to it when w is a
+// terminal, and writes straight to w otherwise. Closing the writer waits
+// for the pager to quit. A nil Pager writes straight to w.
+func (p *Pager) Start(w io.Writer) io.WriteCloser {
+	return p.start(w, false)
+}
+
+// StartStream is Start for output written as it is produced, like a
+// streamed list. A pager program shows it as it arrives; the built-in
+// pager, which needs all the output, holds only the first screen and then
+// writes straight through.
+func (p *Pager) StartStream(w io.Writer) io.WriteCloser {
+	return p.start(w, true)
+}
+
+// start starts the pager, for streamed output when stream is set.
+func (p *Pager) start(w io.Writer, stream bool) io.WriteCloser {
+	out, ok := w.(*os.File)
+	if p == nil || !ok || !term.IsTerminal(int(out.Fd())) {
+		return nopWriteCloser{w}
+	}
+
+	args, ok := p.command()
+	if !ok {
+		return nopWriteCloser{w}
+	}
+	if path, err := exec.LookPath(args[0]); err == nil {
+		if pager, err := startProcessPager(path, args[1:], out); err == nil {
+			return pager
+		}
+	}
+
+	// The built-in pager reads keys from the terminal
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nopWriteCloser{w}
+	}
+	return &builtinPager{
+		out:    out,
+		in:     os.Stdin,
+		stream: stream,
+		size: func() (int, int, error) {
+			return term.GetSize(int(out.Fd()))
+		},
+	}
+}
+
+// command returns the pager command line, and false when paging is
+// turned off.
+func (p *Pager) command() ([]string, bool) {
+	line := p.Command
+	if line == "" {
+		var set bool
+		if line, set = os.LookupEnv("PAGER"); !set {
+			line = defaultPager
+		}
+	}
+	args := strings.Fields(line)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil, false
+	}
+	return args, true
+}
+
+// processPager writes output to the standard input of a pager program.
+type processPager struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	sigs  chan os.Signal
+	quit  bool
+}
+
+// startProcessPager starts a pager program writing to out.
+func startProcessPager(path string, args []string, out *os.File) (*processPager, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// Interrupts are the pager's to handle, so the terminal is not
+	// handed back while it is still running
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	return &processPager{cmd: cmd, stdin: stdin, sigs: sigs}, nil
+}
+
+// Write writes to the pager. Output after the pager quits is discarded.
+func (p *processPager) Write(b []byte) (int, error) {
+	if p.quit {
+		return len(b), nil
+	}
+	if _, err := p.stdin.Write(b); err != nil {
+		p.quit = true
+	}
+	return len(b), nil
+}
+
+// Close ends the output and waits for the pager to quit.
+func (p *processPager) Close() error {
+	defer signal.Stop(p.sigs)
+	_ = p.stdin.Close()
+
+	// How the pager exits is not the command's concern
+	var exitErr *exec.ExitError
+	if err := p.cmd.Wait(); err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+	return nil
+}
+
+// builtinPager is the pager used when no pager program is installed. It
+// keeps the output until it is closed, then shows it a screen at a time.
+// Streamed output is kept only until it fills the screen, and is written
+// straight through from then on.
+type builtinPager struct {
+	out, in *os.File
+	size    func() (width, height int, err error)
+	stream  bool
+	through bool
+	buf     strings.Builder
+}
+
+// Write keeps output to page when the pager is closed.
+func (p *builtinPager) Write(b []byte) (int, error) {
+	if p.through {
+		return p.out.Write(b)
+	}
+	p.buf.Write(b)
+	if !p.stream {
+		return len(b), nil
+	}
+
+	view := newPagerView(p.buf.String(), p.size)
+	if err := view.layout(); err == nil && view.fits() {
+		return len(b), nil
+	}
+	p.through = true
+	_, err := io.WriteString(p.out, p.buf.String())
+	p.buf.Reset()
+	return len(b), err
+}
+
+// Close pages the output, or writes it straight out when it fits on the
+// screen.
+func (p *builtinPager) Close() error {
+	if p.through {
+		return nil
+	}
+	view := newPagerView(p.buf.String(), p.size)
+	if err := view.layout(); err != nil || view.fits() {
+		_, err := io.WriteString(p.out, p.buf.String())
+		return err
+	}
+
+	state, err := term.MakeRaw(int(p.in.Fd()))
+	if err != nil {
+		_, err := io.WriteString(p.out, p.buf.String())
+		return err
+	}
+	defer func() { _ = term.Restore(int(p.in.Fd()), state) }()
+	return view.run(p.in, p.out)
+}
+
+// nopWriteCloser writes straight to its writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPagerStart(t *testing.T) {
+	// Output that is not a terminal is written straight out
+	var out bytes.Buffer
+	for _, pager := range []*Pager{nil, NewPager()} {
+		out.Reset()
+		w := pager.Start(&out)
+		_, _ = w.Write([]byte("hello\n"))
+		if err := w.Close(); err != nil || out.String() != "hello\n" {
+			t.Errorf("Start() wrote %q, %v", out.String(), err)
+		}
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		env     *string
+		want    string
+		paged   bool
+	}{
+		{name: "default", want: defaultPager, paged: true},
+		{name: "PAGER", env: ptr("more -s"), want: "more -s", paged: true},
+		{name: "empty PAGER", env: ptr("")},
+		{name: "cat", env: ptr("cat")},
+		{name: "command over PAGER", command: "most", env: ptr("more"), want: "most", paged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGER", "")
+			if tt.env == nil {
+				_ = os.Unsetenv("PAGER")
+			} else {
+				t.Setenv("PAGER", *tt.env)
+			}
+
+			args, paged := (&Pager{Command: tt.command}).command()
+			if paged != tt.paged || strings.Join(args, " ") != tt.want {
+				t.Errorf("command() = %q, %v, want %q, %v", args, paged, tt.want, tt.paged)
+			}
+		})
+	}
+}
+
+func TestProcessPager(t *testing.T) {
+	for _, name := range []string{"cat", "true"} {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			t.Skipf("%s is not installed", name)
+		}
+
+		out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pager, err := startProcessPager(path, nil, out)
+		if err != nil {
+			t.Fatalf("startProcessPager() error = %v", err)
+		}
+
+		// Output after the pager quits is discarded
+		text := strings.Repeat("line of output\n", 10000)
+		if n, err := pager.Write([]byte(text)); err != nil || n != len(text) {
+			t.Errorf("%s: Write() = %d, %v", name, n, err)
+		}
+		if err := pager.Close(); err != nil {
+			t.Errorf("%s: Close() error = %v", name, err)
+		}
+		_ = out.Close()
+
+		data, _ := os.ReadFile(out.Name())
+		if name == "cat" && string(data) != text {
+			t.Errorf("Expected the pager to get all the output, got %d bytes", len(data))
+		}
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestBuiltinPager_Stream(t *testing.T) {
+	out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = out.Close() }()
+	written := func() string {
+		data, _ := os.ReadFile(out.Name())
+		return string(data)
+	}
+
+	pager := &builtinPager{
+		out:    out,
+		in:     os.Stdin,
+		stream: true,
+		size: func() (int, int, error) {
+			return 80, 5, nil
+		},
+	}
+
+	// Output is held while it fits on the screen
+	_, _ = pager.Write([]byte("one\ntwo\n"))
+	if got := written(); got != "" {
+		t.Errorf("Expected output to be held until the screen is full, got %q", got)
+	}
+
+	// Then everything is written straight through
+	text := "one\ntwo\n"
+	for i := 0; i < 5; i++ {
+		line := strings.Repeat("x", i+1) + "\n"
+		text += line
+		_, _ = pager.Write([]byte(line))
+	}
+	if got := written(); got != text {
+		t.Errorf("Expected output past the first screen to be written through, got %q", got)
+	}
+	_, _ = pager.Write([]byte("last\n"))
+	if err := pager.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if got := written(); got != text+"last\n" {
+		t.Errorf("Expected all the output, got %q", got)
+	}
+}
@@ -0,0 +1,345 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// pagerHelp is shown by the built-in pager for h and ?.
+const pagerHelp = "j/k: line  space/b: page  d/u: half page  g/G: top/end  /: search  n/N: next/previous  q: quit"
+
+// pagerView is the screen of the built-in pager: the output wrapped to
+// the terminal width, shown a screen at a time with a prompt line below.
+type pagerView struct {
+	lines []string
+	size  func() (width, height int, err error)
+
+	rows    []string
+	width   int
+	height  int
+	top     int
+	pattern string
+	match   int
+	message string
+}
+
+// newPagerView creates a view of text, sized by size.
+func newPagerView(text string, size func() (int, int, error)) *pagerView {
+	return &pagerView{
+		lines: strings.Split(strings.TrimSuffix(text, "\n"), "\n"),
+		size:  size,
+		match: -1,
+	}
+}
+
+// layout wraps the lines to the terminal width when it changes, keeping
+// the screen within the rows.
+func (v *pagerView) layout() error {
+	width, height, err := v.size()
+	if err != nil {
+		return err
+	}
+	if width != v.width {
+		v.rows = v.rows[:0]
+		for _, line := range v.lines {
+			v.rows = append(v.rows, wrapLine(line, width)...)
+		}
+		v.width = width
+		v.match = -1
+	}
+	v.height = max(height-1, 1)
+	v.scroll(0)
+	return nil
+}
+
+// fits reports whether the rows fit on one screen.
+func (v *pagerView) fits() bool {
+	return len(v.rows) <= v.height
+}
+
+// scroll moves the screen down n rows, or up when n is negative.
+func (v *pagerView) scroll(n int) {
+	v.top = max(min(v.top+n, len(v.rows)-v.height), 0)
+}
+
+// run shows the view, handling keys read from keys until it is quit.
+func (v *pagerView) run(keys io.Reader, w io.Writer) error {
+	in := bufio.NewReader(keys)
+	for {
+		if err := v.layout(); err != nil {
+			return err
+		}
+		if err := v.draw(w); err != nil {
+			return err
+		}
+		v.message = ""
+
+		key, err := readKey(in)
+		if err == io.EOF || (err == nil && !v.handle(key, in, w)) {
+			// Leave the screen as it is, less the prompt
+			_, err := io.WriteString(w, "\r\x1b[K")
+			return err
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle acts on a key, reporting false when it quits the pager.
+func (v *pagerView) handle(key string, in *bufio.Reader, w io.Writer) bool {
+	switch key {
+	case "q", "Q", "\x03":
+		return false
+	case "j", "e", "\r", "\n", "\x0e", "\x1b[B":
+		v.scroll(1)
+	case "k", "y", "\x10", "\x1b[A":
+		v.scroll(-1)
+	case " ", "f", "\x06", "\x1b[6~":
+		v.scroll(v.height)
+	case "b", "\x02", "\x1b[5~":
+		v.scroll(-v.height)
+	case "d", "\x04":
+		v.scroll(v.height / 2)
+	case "u", "\x15":
+		v.scroll(-v.height / 2)
+	case "g", "<", "\x1b[H", "\x1b[1~":
+		v.top = 0
+	case "G", ">", "\x1b[F", "\x1b[4~":
+		v.scroll(len(v.rows))
+	case "/":
+		if pattern, ok := readPattern(in, w); ok {
+			if pattern != "" {
+				v.pattern = pattern
+			}
+			v.match = -1
+			v.search(1)
+		}
+		return true
+	case "n":
+		v.search(1)
+		return true
+	case "N":
+		v.search(-1)
+		return true
+	case "h", "?":
+		v.message = pagerHelp
+	}
+	v.match = -1
+	return true
+}
+
+// search moves the screen to the next row matching the pattern, searching
+// down when dir is 1 and up when it is -1. Searches start from the last
+// match, or the top of the screen.
+func (v *pagerView) search(dir int) {
+	if v.pattern == "" {
+		v.message = "No previous search pattern"
+		return
+	}
+
+	start := v.top
+	if v.match >= 0 {
+		start = v.match + dir
+	} else if dir < 0 {
+		start = v.top - 1
+	}
+	for i := start; i >= 0 && i < len(v.rows); i += dir {
+		if matchRow(v.rows[i], v.pattern) {
+			v.match = i
+			v.top = i
+			v.scroll(0)
+			return
+		}
+	}
+	v.message = "Pattern not found: " + v.pattern
+}
+
+// draw writes the screen: the rows from the top, then the prompt.
+func (v *pagerView) draw(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	end := min(v.top+v.height, len(v.rows))
+	for _, row := range v.rows[v.top:end] {
+		b.WriteString(row)
+		b.WriteString("\x1b[0m\r\n")
+	}
+	for i := end - v.top; i < v.height; i++ {
+		b.WriteString("~\r\n")
+	}
+	b.WriteString("\x1b[7m" + v.prompt() + "\x1b[0m")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// prompt returns the prompt line: a message, or how far through the
+// output the screen is.
+func (v *pagerView) prompt() string {
+	switch {
+	case v.message != "":
+		return v.message
+	case v.top+v.height >= len(v.rows):
+		return "(END)"
+	default:
+		return fmt.Sprintf("%d%%", (v.top+v.height)*100/len(v.rows))
+	}
+}
+
+// readKey reads a key press: a character, or the escape sequence of a
+// key like an arrow.
+func readKey(in *bufio.Reader) (string, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	if r != '\x1b' || in.Buffered() == 0 {
+		return string(r), nil
+	}
+
+	seq := []byte{'\x1b'}
+	for in.Buffered() > 0 {
+		c, err := in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, c)
+		if len(seq) > 2 && c >= 0x40 && c <= 0x7e {
+			break
+		}
+	}
+	return string(seq), nil
+}
+
+// readPattern reads a search pattern on the prompt line, reporting false
+// when it is cancelled.
+func readPattern(in *bufio.Reader, w io.Writer) (string, bool) {
+	var pattern []rune
+	for {
+		if _, err := io.WriteString(w, "\r\x1b[K/"+string(pattern)); err != nil {
+			return "", false
+		}
+		key, err := readKey(in)
+		if err != nil {
+			return "", false
+		}
+		switch key {
+		case "\r", "\n":
+			return string(pattern), true
+		case "\x1b", "\x03":
+			return "", false
+		case "\x7f", "\b":
+			if len(pattern) == 0 {
+				return "", false
+			}
+			pattern = pattern[:len(pattern)-1]
+		default:
+			if r, _ := utf8.DecodeRuneInString(key); utf8.RuneCountInString(key) == 1 && unicode.IsPrint(r) {
+				pattern = append(pattern, r)
+			}
+		}
+	}
+}
+
+// matchRow reports whether the text of a row contains the pattern,
+// ignoring case unless the pattern has capitals.
+func matchRow(row, pattern string) bool {
+	text := stripEscapes(row)
+	if strings.ToLower(pattern) == pattern {
+		text = strings.ToLower(text)
+	}
+	return strings.Contains(text, pattern)
+}
+
+// wrapLine splits a line into rows of at most width columns, with tabs
+// expanded. Escape sequences take no columns, and the colours in effect
+// at the end of a row are set again on the next.
+func wrapLine(line string, width int) []string {
+	var rows []string
+	var row strings.Builder
+	var colours string
+	cols := 0
+
+	put := func(r rune, w int) {
+		if cols+w > width && cols > 0 {
+			rows = append(rows, row.String())
+			row.Reset()
+			row.WriteString(colours)
+			cols = 0
+		}
+		row.WriteRune(r)
+		cols += w
+	}
+
+	for i := 0; i < len(line); {
+		if seq := escapeAt(line, i); seq != "" {
+			row.WriteString(seq)
+			if seq == "\x1b[0m" || seq == "\x1b[m" {
+				colours = ""
+			} else if strings.HasSuffix(seq, "m") {
+				colours += seq
+			}
+			i += len(seq)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		if r == '\t' {
+			for n := 8 - cols%8; n > 0; n-- {
+				put(' ', 1)
+			}
+			continue
+		}
+		put(r, runewidth.RuneWidth(r))
+	}
+	return append(rows, row.String())
+}
+
+// escapeAt returns the escape sequence starting at s[i], if any: a CSI
+// sequence like a colour, or an OSC sequence like a hyperlink.
+func escapeAt(s string, i int) string {
+	if s[i] != '\x1b' || i+1 >= len(s) {
+		return ""
+	}
+	switch s[i+1] {
+	case '[':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return s[i : j+1]
+			}
+		}
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return s[i : j+1]
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return s[i : j+2]
+			}
+		}
+	}
+	return ""
+}
+
+// stripEscapes returns s without its escape sequences.
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if seq := escapeAt(s, i); seq != "" {
+			i += len(seq)
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{name: "short", line: "abc", width: 5, want: []string{"abc"}},
+		{name: "empty", line: "", width: 5, want: []string{""}},
+		{name: "wrapped", line: "abcdefg", width: 3, want: []string{"abc", "def", "g"}},
+		{name: "tabs", line: "a\tb", width: 20, want: []string{"a       b"}},
+		{name: "wide runes", line: "日本語", width: 4, want: []string{"日本", "語"}},
+		{
+			name:  "colours carry over",
+			line:  "\x1b[31mabcd\x1b[0mef",
+			width: 3,
+			want:  []string{"\x1b[31mabc", "\x1b[31md\x1b[0mef"},
+		},
+		{
+			name:  "hyperlinks take no columns",
+			line:  "\x1b]8;;https://example.com\x1b\\ab\x1b]8;;\a",
+			width: 2,
+			want:  []string{"\x1b]8;;https://example.com\x1b\\ab\x1b]8;;\a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapLine(tt.line, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRow(t *testing.T) {
+	if !matchRow("\x1b[1mName\x1b[0m: alpha", "name: alpha") {
+		t.Error("Expected lowercase patterns to ignore case and colours")
+	}
+	if matchRow("name: alpha", "Name") {
+		t.Error("Expected patterns with capitals to match case")
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("j\x1b[Bé\x1b[6~"))
+	var keys []string
+	for {
+		key, err := readKey(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	if want := []string{"j", "\x1b[B", "é", "\x1b[6~"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("readKey() read %q, want %q", keys, want)
+	}
+}
+
+func TestPagerView(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 50; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	text := strings.Join(lines, "\n") + "\n"
+	size := func() (int, int, error) { return 80, 11, nil }
+
+	tests := []struct {
+		name    string
+		keys    string
+		top     int
+		message string
+	}{
+		{name: "line down", keys: "jjj", top: 3},
+		{name: "line up", keys: "jjk", top: 1},
+		{name: "page down", keys: " ", top: 10},
+		{name: "page up", keys: "  b", top: 10},
+		{name: "half page", keys: "d", top: 5},
+		{name: "end", keys: "G", top: 40},
+		{name: "top", keys: "Gg", top: 0},
+		{name: "arrows", keys: "\x1b[B\x1b[B\x1b[A", top: 1},
+		{name: "search", keys: "/line 3\r", top: 2},
+		{name: "next match", keys: "/line 3\rn", top: 29},
+		{name: "previous match", keys: "/line 3\rnN", top: 2},
+		{name: "search edited", keys: "/line 4x\x7f\r", top: 3},
+		{name: "search cancelled", keys: "/line 3\x1b", top: 0},
+		{name: "not found", keys: "/missing\r", top: 0, message: "Pattern not found: missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := newPagerView(text, size)
+			var screen strings.Builder
+			if err := view.run(strings.NewReader(tt.keys), &screen); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if view.top != tt.top {
+				t.Errorf("Expected the screen at row %d, got %d", tt.top, view.top)
+			}
+			if tt.message != "" && !strings.Contains(screen.String(), tt.message) {
+				t.Errorf("Expected the prompt %q", tt.message)
+			}
+		})
+	}
+
+	// The screen shows rows from the top, then the prompt
+	view := newPagerView(text, size)
+	var screen strings.Builder
+	if err := view.run(strings.NewReader("q"), &screen); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(screen.String(), "line 10\x1b[0m\r\n\x1b[7m20%") || strings.Contains(screen.String(), "line 11") {
+		t.Errorf("Unexpected screen %q", screen.String())
+	}
+
+	// Output that fits is not paged
+	view = newPagerView("one\ntwo\n", size)
+	if err := view.layout(); err != nil || !view.fits() {
+		t.Error("Expected two lines to fit on the screen")
+	}
+}
@@ -0,0 +1,228 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// defaultSampleSize is how many items streamed tables size their columns
+// by when the output config does not say.
+const defaultSampleSize = 100
+
+// StreamFormatter is implemented by formatters that write the items of a
+// list as they are decoded, so large responses are never held in memory
+// whole.
+type StreamFormatter interface {
+	Formatter
+
+	// FormatStream formats the items read from items and writes them to
+	// the provided writer as they arrive.
+	FormatStream(w io.Writer, items *ItemStream, config *FormatConfig) error
+}
+
+// ItemStream reads the items of a JSON array one at a time, with a
+// token-level decoder positioned on the array at an items path of the
+// document. When the value at the path is not an array, the stream has
+// that value as its only item.
+type ItemStream struct {
+	dec    *json.Decoder
+	list   bool
+	single interface{}
+	done   bool
+}
+
+// NewItemStream positions a decoder of r on the value at itemsPath, a
+// dotted path of object keys like "data.items". An empty path is the
+// whole document.
+func NewItemStream(r io.Reader, itemsPath string) (*ItemStream, error) {
+	dec := json.NewDecoder(r)
+
+	var segments []string
+	if path := strings.Trim(itemsPath, "."); path != "" {
+		segments = strings.Split(path, ".")
+	}
+	for _, segment := range segments {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if tok != json.Delim('{') {
+			return nil, fmt.Errorf("no %s in the response", itemsPath)
+		}
+		found := false
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read response: %w", err)
+			}
+			if key == segment {
+				found = true
+				break
+			}
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no %s in the response", itemsPath)
+		}
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if tok == json.Delim('[') {
+		return &ItemStream{dec: dec, list: true}, nil
+	}
+
+	// Other values are read whole
+	value, err := decodeRest(dec, tok)
+	if err != nil {
+		return nil, err
+	}
+	return &ItemStream{dec: dec, single: value}, nil
+}
+
+// IsList reports whether the value at the items path is an array.
+func (s *ItemStream) IsList() bool {
+	return s.list
+}
+
+// Next returns the next item, or io.EOF after the last one.
+func (s *ItemStream) Next() (interface{}, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	if !s.list {
+		s.done = true
+		return s.single, nil
+	}
+
+	if !s.dec.More() {
+		s.done = true
+		if _, err := s.dec.Token(); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil, io.EOF
+	}
+
+	var item interface{}
+	if err := s.dec.Decode(&item); err != nil {
+		return nil, fmt.Errorf("failed to decode item: %w", err)
+	}
+	return item, nil
+}
+
+// Collect reads the remaining items into a slice, or returns the value
+// of a stream that is not a list.
+func (s *ItemStream) Collect() (interface{}, error) {
+	if !s.list {
+		return s.Next()
+	}
+
+	items := make([]interface{}, 0)
+	for {
+		item, err := s.Next()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// skipValue skips the next value of dec, token by token.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// decodeRest decodes the value starting with tok, which was already read.
+func decodeRest(dec *json.Decoder, tok json.Token) (interface{}, error) {
+	if tok != json.Delim('{') {
+		return tok, nil
+	}
+
+	object := make(map[string]interface{})
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		object[fmt.Sprint(key)] = value
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return object, nil
+}
+
+// streamColumns returns the columns of streamed items: the configured
+// table columns, else the sorted keys of the first item.
+func streamColumns(config *FormatConfig, first interface{}) []*openapi.TableColumn {
+	if config.OutputConfig != nil && config.OutputConfig.Table != nil && len(config.OutputConfig.Table.Columns) > 0 {
+		return config.OutputConfig.Table.Columns
+	}
+
+	object, ok := first.(map[string]interface{})
+	if !ok {
+		return []*openapi.TableColumn{{Header: "VALUE"}}
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	columns := make([]*openapi.TableColumn, len(keys))
+	for i, key := range keys {
+		columns[i] = &openapi.TableColumn{Field: key, Header: strings.ToUpper(key)}
+	}
+	return columns
+}
+
+// streamField returns the value of a column of a streamed item. Columns
+// without a field are the item itself.
+func streamField(item interface{}, field string) interface{} {
+	if field == "" {
+		return item
+	}
+	if object, ok := item.(map[string]interface{}); ok {
+		return object[field]
+	}
+	return nil
+}
+
+// streamSampleSize returns how many items size streamed tables.
+func streamSampleSize(config *FormatConfig) int {
+	if config.OutputConfig != nil && config.OutputConfig.Stream != nil && config.OutputConfig.Stream.SampleSize > 0 {
+		return config.OutputConfig.Stream.SampleSize
+	}
+	return defaultSampleSize
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+const streamDocument = `{
+	"meta": {"skipped": [1, {"deep": [true]}], "note": "x"},
+	"data": {"total": 3, "items": [
+		{"id": 1, "name": "alpha", "tags": ["a", "b"]},
+		{"id": 2, "name": "beta", "labels": {"env": "prod"}},
+		{"id": 3, "name": "a much longer name"}
+	]}
+}`
+
+func TestItemStream(t *testing.T) {
+	items, err := NewItemStream(strings.NewReader(streamDocument), "data.items")
+	if err != nil {
+		t.Fatalf("NewItemStream() error = %v", err)
+	}
+	if !items.IsList() {
+		t.Fatal("Expected the items to be a list")
+	}
+
+	var names []string
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "alpha,beta,a much longer name" {
+		t.Errorf("Unexpected items %v", names)
+	}
+
+	// Values other than lists are the only item
+	items, err = NewItemStream(strings.NewReader(streamDocument), "data")
+	if err != nil {
+		t.Fatalf("NewItemStream() error = %v", err)
+	}
+	data, err := items.Collect()
+	if err != nil || items.IsList() || data.(map[string]interface{})["total"] != 3.0 {
+		t.Errorf("Collect() = %v, %v", data, err)
+	}
+
+	for _, path := range []string{"data.missing", "meta.note.x"} {
+		if _, err := NewItemStream(strings.NewReader(streamDocument), path); err == nil {
+			t.Errorf("Expected an error for the items path %s", path)
+		}
+	}
+}
+
+// TestStreamFormatters checks that streamed output is the same as
+// formatting the whole list.
+func TestStreamFormatters(t *testing.T) {
+	var document struct {
+		Data struct {
+			Items []interface{} `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(streamDocument), &document); err != nil {
+		t.Fatal(err)
+	}
+	list := document.Data.Items
+
+	configs := map[string]*FormatConfig{
+		"pretty":  NewFormatConfig(),
+		"compact": NewFormatConfig().WithCompact(true),
+		"plain":   NewFormatConfig().WithPretty(false),
+	}
+	formatters := []StreamFormatter{NewJSONFormatter(), NewYAMLFormatter(), NewJSONLinesFormatter(), NewCSVFormatter()}
+	for _, formatter := range formatters {
+		for name, config := range configs {
+			var want, got bytes.Buffer
+			if err := formatter.Format(&want, list, config); err != nil {
+				t.Fatalf("%s Format() error = %v", formatter.Name(), err)
+			}
+			items, _ := NewItemStream(strings.NewReader(streamDocument), "data.items")
+			if err := formatter.FormatStream(&got, items, config); err != nil {
+				t.Fatalf("%s FormatStream() error = %v", formatter.Name(), err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("%s (%s) streamed\n%s\nwant\n%s", formatter.Name(), name, got.String(), want.String())
+			}
+		}
+	}
+
+	// Empty lists
+	for _, formatter := range []StreamFormatter{NewJSONFormatter(), NewYAMLFormatter()} {
+		var want, got bytes.Buffer
+		_ = formatter.Format(&want, []interface{}{}, NewFormatConfig())
+		items, _ := NewItemStream(strings.NewReader(`[]`), "")
+		if err := formatter.FormatStream(&got, items, NewFormatConfig()); err != nil || got.String() != want.String() {
+			t.Errorf("%s streamed empty list %q, want %q (%v)", formatter.Name(), got.String(), want.String(), err)
+		}
+	}
+}
+
+func TestTableFormatterFormatStream(t *testing.T) {
+	config := NewFormatConfig().WithColors(false).WithOutputConfig(&openapi.CLIOutput{
+		Table: &openapi.TableConfig{Columns: []*openapi.TableColumn{
+			{Field: "id", Header: "ID"},
+			{Field: "name", Header: "NAME", Transform: "uppercase"},
+		}},
+		Stream: &openapi.OutputStream{ItemsPath: "data.items", SampleSize: 2},
+	})
+
+	var out bytes.Buffer
+	items, _ := NewItemStream(strings.NewReader(streamDocument), "data.items")
+	if err := NewTableFormatter().FormatStream(&out, items, config); err != nil {
+		t.Fatalf("FormatStream() error = %v", err)
+	}
+
+	// Widths come from the first two items, so the third is truncated
+	want := "ID | NAME\n" +
+		"1  | ALPHA\n" +
+		"2  | BETA\n" +
+		"3  | A ...\n"
+	if out.String() != want {
+		t.Errorf("FormatStream() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	items, _ = NewItemStream(strings.NewReader(`[]`), "")
+	if err := NewTableFormatter().FormatStream(&out, items, config); err != nil || out.String() != "No results found\n" {
+		t.Errorf("FormatStream() of no items = %q, %v", out.String(), err)
+	}
+}
+
+func TestManagerFormatStream(t *testing.T) {
+	manager := NewManager()
+	config := NewFormatConfig().WithOutputConfig(&openapi.CLIOutput{
+		Stream: &openapi.OutputStream{ItemsPath: "data.items"},
+	})
+
+	var out bytes.Buffer
+	if err := manager.FormatStream(&out, strings.NewReader(streamDocument), "jsonl", config); err != nil {
+		t.Fatalf("FormatStream() error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 || lines[0] != `{"id":1,"name":"alpha","tags":["a","b"]}` {
+		t.Errorf("Unexpected JSON lines:\n%s", out.String())
+	}
+
+	// Sorted tables collect the items first
+	out.Reset()
+	config.WithColors(false).WithSorting("NAME", true)
+	if err := manager.FormatStream(&out, strings.NewReader(streamDocument), "table", config); err != nil {
+		t.Fatalf("FormatStream() error = %v", err)
+	}
+	if strings.Index(out.String(), "a much longer name") > strings.Index(out.String(), "beta") {
+		t.Errorf("Expected sorted rows, got\n%s", out.String())
+	}
+
+	if err := manager.FormatStream(&out, strings.NewReader(streamDocument), "xml", config); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
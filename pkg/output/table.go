@@ -6,8 +6,10 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/CliForge/cliforge/pkg/openapi"
+	"github.com/mattn/go-runewidth"
 	"github.com/pterm/pterm"
 )
 
@@ -130,6 +132,138 @@ func (f *TableFormatter) Format(w io.Writer, data interface{}, config *FormatCon
 	return err
 }
 
+// FormatStream writes the items of a list as a table, a row at a time.
+// Column widths are fixed from a sample window of the first items; longer
+// values of later items are truncated.
+func (f *TableFormatter) FormatStream(w io.Writer, items *ItemStream, config *FormatConfig) error {
+	if config == nil {
+		config = NewFormatConfig()
+	}
+	if !items.IsList() {
+		item, err := items.Next()
+		if err != nil {
+			return err
+		}
+		return f.Format(w, item, config)
+	}
+
+	// Read the sample window
+	var sample []interface{}
+	for len(sample) < streamSampleSize(config) {
+		item, err := items.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sample = append(sample, item)
+	}
+	if len(sample) == 0 {
+		return f.FormatEmpty(w, "", config)
+	}
+
+	columns := streamColumns(config, sample[0])
+	widths := make([]int, len(columns))
+	if config.ShowHeaders {
+		for i, col := range columns {
+			widths[i] = cellWidth(columnHeader(col))
+		}
+	}
+	for _, item := range sample {
+		for i, cell := range f.streamRow(item, columns, config) {
+			widths[i] = max(widths[i], cellWidth(cell))
+		}
+	}
+	for i, col := range columns {
+		if col.Width > 0 {
+			widths[i] = min(widths[i], col.Width)
+		}
+	}
+
+	if config.ShowHeaders {
+		headers := make([]string, len(columns))
+		for i, col := range columns {
+			headers[i] = columnHeader(col)
+		}
+		line := renderTableLine(headers, widths)
+		if config.Colors {
+			line = pterm.NewStyle(pterm.FgLightCyan, pterm.Bold).Sprint(line)
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	writeRow := func(item interface{}) error {
+		_, err := io.WriteString(w, renderTableLine(f.streamRow(item, columns, config), widths)+"\n")
+		return err
+	}
+	for _, item := range sample {
+		if err := writeRow(item); err != nil {
+			return err
+		}
+	}
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeRow(item); err != nil {
+			return err
+		}
+	}
+}
+
+// streamRow returns the cells of a streamed item.
+func (f *TableFormatter) streamRow(item interface{}, columns []*openapi.TableColumn, config *FormatConfig) []string {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = f.transformValue(streamField(item, col.Field), col.Transform, config)
+	}
+	return row
+}
+
+// columnHeader returns the header of a column, defaulting to its field.
+func columnHeader(col *openapi.TableColumn) string {
+	if col.Header != "" {
+		return col.Header
+	}
+	return col.Field
+}
+
+// renderTableLine pads or truncates cells to the column widths and joins
+// them with the separator of pterm's default table.
+func renderTableLine(cells []string, widths []int) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		width := cellWidth(cell)
+		if width > widths[i] {
+			if width == len(cell) && widths[i] > 3 {
+				cell = cell[:widths[i]-3] + "..."
+			} else {
+				cell = runewidth.Truncate(cell, widths[i], "...")
+			}
+			width = cellWidth(cell)
+		}
+		padded[i] = cell + strings.Repeat(" ", max(widths[i]-width, 0))
+	}
+	return strings.TrimRight(strings.Join(padded, " | "), " ")
+}
+
+// cellWidth returns the display width of a cell, quickly for ASCII.
+func cellWidth(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return runewidth.StringWidth(s)
+		}
+	}
+	return len(s)
+}
+
 // formatSlice formats a slice or array as a table.
 func (f *TableFormatter) formatSlice(v reflect.Value, config *FormatConfig) ([][]string, error) {
 	if v.Len() == 0 {
@@ -1,8 +1,10 @@
 package output
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -48,6 +50,60 @@ func (f *YAMLFormatter) Format(w io.Writer, data interface{}, _ *FormatConfig) e
 	return nil
 }
 
+// FormatStream writes the items of a list as a YAML sequence, one item
+// at a time.
+func (f *YAMLFormatter) FormatStream(w io.Writer, items *ItemStream, config *FormatConfig) error {
+	if !items.IsList() {
+		item, err := items.Next()
+		if err != nil {
+			return err
+		}
+		return f.Format(w, item, config)
+	}
+
+	var buf, entry bytes.Buffer
+	count := 0
+	for {
+		item, err := items.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		buf.Reset()
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(item); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		_ = encoder.Close()
+
+		// Indent the item as an entry of the sequence
+		entry.Reset()
+		for i, line := range strings.SplitAfter(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			if i == 0 {
+				entry.WriteString("- ")
+			} else if line != "\n" {
+				entry.WriteString("  ")
+			}
+			entry.WriteString(line)
+		}
+		entry.WriteString("\n")
+		if _, err := w.Write(entry.Bytes()); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if count == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	return nil
+}
+
 // FormatResult formats a Result object as YAML.
 func (f *YAMLFormatter) FormatResult(w io.Writer, result *Result, config *FormatConfig) error {
 	if config == nil {
@@ -0,0 +1,150 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditFile is the name of the audit log in the CLI's config directory.
+const AuditFile = "plugin-audit.log"
+
+// AuditAction is what happened to a plugin's permissions.
+type AuditAction string
+
+const (
+	// AuditApprove records permissions being granted.
+	AuditApprove AuditAction = "approve"
+
+	// AuditDeny records a plugin being refused permissions.
+	AuditDeny AuditAction = "deny"
+
+	// AuditRevoke records permissions being withdrawn.
+	AuditRevoke AuditAction = "revoke"
+)
+
+// AuditActor is who made a decision.
+type AuditActor string
+
+const (
+	// AuditActorUser is the user answering an approval prompt or removing
+	// a plugin.
+	AuditActorUser AuditActor = "user"
+
+	// AuditActorPolicy is the organisation's plugin policy.
+	AuditActorPolicy AuditActor = "policy"
+)
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time        time.Time   `json:"time"`
+	Action      AuditAction `json:"action"`
+	Actor       AuditActor  `json:"actor"`
+	Plugin      string      `json:"plugin"`
+	Version     string      `json:"version,omitempty"`
+	Publisher   string      `json:"publisher,omitempty"`
+	Permissions []string    `json:"permissions,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// AuditLog is an append-only log of permission decisions, stored as one
+// JSON object per line.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog creates an audit log stored at path. The file is created on
+// the first entry.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Path returns the path of the log file.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Record appends an entry, setting its time if it is zero.
+func (l *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// recordOrWarn records an entry, warning on stderr if it cannot be
+// written. A permission decision is not undone because it could not be
+// logged.
+func (l *AuditLog) recordOrWarn(entry AuditEntry) {
+	if err := l.Record(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// Entries returns the logged entries, oldest first. A missing log has no
+// entries.
+func (l *AuditLog) Entries() ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid audit log entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// permissionStrings returns the string form of permissions.
+func permissionStrings(permissions []Permission) []string {
+	strs := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		strs = append(strs, perm.String())
+	}
+	return strs
+}
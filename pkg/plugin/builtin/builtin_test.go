@@ -610,6 +610,7 @@ func TestExecPlugin_InvalidWorkingDirectory(t *testing.T) {
 }
 
 func TestExecPlugin_WithSandbox(t *testing.T) {
+	t.Setenv("CLIFORGE_TEST_UNSAFE", "1")
 	execPlugin := NewExecPlugin([]string{"env"}, true)
 
 	input := &plugin.PluginInput{
@@ -625,10 +626,12 @@ func TestExecPlugin_WithSandbox(t *testing.T) {
 		t.Errorf("ExitCode = %v, want 0", output.ExitCode)
 	}
 
-	// When sandbox is enabled, environment should be filtered
-	// Check that only safe env vars are present
-	if strings.Contains(output.Stdout, "PWD=") && !strings.Contains(output.Stdout, "PATH=") {
-		// This is expected - PATH might be filtered by sandbox
+	// When sandbox is enabled, only safe env vars are passed on
+	if !strings.Contains(output.Stdout, "PATH=") {
+		t.Errorf("Expected PATH in sandboxed environment, got:\n%s", output.Stdout)
+	}
+	if strings.Contains(output.Stdout, "CLIFORGE_TEST_UNSAFE=") {
+		t.Errorf("Unexpected variable in sandboxed environment:\n%s", output.Stdout)
 	}
 }
 
@@ -75,8 +75,8 @@ func (p *ExecPlugin) Execute(ctx context.Context, input *plugin.PluginInput) (*p
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// Set environment variables
-	if input.Env != nil {
+	// Set environment variables; a sandbox builds its own environment
+	if input.Env != nil && !p.sandbox {
 		env := os.Environ()
 		for k, v := range input.Env {
 			env = append(env, fmt.Sprintf("%s=%s", k, v))
@@ -93,15 +93,21 @@ func (p *ExecPlugin) Execute(ctx context.Context, input *plugin.PluginInput) (*p
 		cmd.Dir = input.WorkingDir
 	}
 
-	// Apply sandboxing if enabled
+	// Execute the command, sandboxed if enabled
+	var err error
 	if p.sandbox {
-		if err := applySandbox(cmd); err != nil {
-			return nil, fmt.Errorf("failed to apply sandbox: %w", err)
+		sandbox, sandboxErr := newExecSandbox(input)
+		if sandboxErr != nil {
+			return nil, fmt.Errorf("failed to apply sandbox: %w", sandboxErr)
 		}
+		var cleanup func()
+		if cleanup, err = sandbox.Start(cmd, input.Env); err == nil {
+			err = cmd.Wait()
+			cleanup()
+		}
+	} else {
+		err = cmd.Run()
 	}
-
-	// Execute the command
-	err := cmd.Run()
 	duration := time.Since(startTime)
 
 	// Determine exit code
@@ -180,29 +186,22 @@ func validateArgument(arg string) error {
 	return nil
 }
 
-// applySandbox applies sandboxing to a command (platform-specific).
-func applySandbox(cmd *exec.Cmd) error {
-	// Basic sandboxing: limit resource access
-	// More sophisticated sandboxing (chroot, namespaces, etc.) would be platform-specific
+// execSandboxEnv are the host environment variables sandboxed commands
+// see.
+var execSandboxEnv = []string{"PATH", "HOME", "USER", "LANG"}
 
-	// For now, we just ensure no dangerous environment variables are inherited
-	if cmd.Env == nil {
-		cmd.Env = []string{}
+// newExecSandbox returns the sandbox a command runs in: it may read its
+// working directory and the safe environment variables, and has no network
+// access.
+func newExecSandbox(input *plugin.PluginInput) (*plugin.Sandbox, error) {
+	manifest := plugin.PluginManifest{Name: "exec"}
+	for _, key := range execSandboxEnv {
+		manifest.Permissions = append(manifest.Permissions, plugin.Permission{Type: plugin.PermissionReadEnv, Resource: key})
 	}
-
-	// Filter out potentially dangerous env vars
-	safeEnv := make([]string, 0)
-	for _, envVar := range cmd.Env {
-		if strings.HasPrefix(envVar, "PATH=") ||
-			strings.HasPrefix(envVar, "HOME=") ||
-			strings.HasPrefix(envVar, "USER=") ||
-			strings.HasPrefix(envVar, "LANG=") {
-			safeEnv = append(safeEnv, envVar)
-		}
+	if input.WorkingDir != "" {
+		manifest.Permissions = append(manifest.Permissions, plugin.Permission{Type: plugin.PermissionReadFile, Resource: input.WorkingDir + "/*"})
 	}
-	cmd.Env = safeEnv
-
-	return nil
+	return plugin.NewSandbox(manifest, "", plugin.DefaultSandboxConfig())
 }
 
 // ExecuteWithShell executes a command through a shell (use with caution).
@@ -0,0 +1,306 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// conformanceProbeCommand is a command no plugin declares, used to check
+// how a plugin reports errors.
+const conformanceProbeCommand = "__conformance_unknown__"
+
+// ConformanceStatus is the outcome of a conformance check.
+type ConformanceStatus string
+
+const (
+	// ConformancePass means the plugin behaves as the protocol requires.
+	ConformancePass ConformanceStatus = "pass"
+
+	// ConformanceWarn means the plugin works but could behave better.
+	ConformanceWarn ConformanceStatus = "warn"
+
+	// ConformanceFail means the plugin will not work correctly.
+	ConformanceFail ConformanceStatus = "fail"
+
+	// ConformanceSkip means the check does not apply or could not run.
+	ConformanceSkip ConformanceStatus = "skip"
+)
+
+// ConformanceCheck is the result of one conformance check.
+type ConformanceCheck struct {
+	Name    string            `json:"name"`
+	Status  ConformanceStatus `json:"status"`
+	Message string            `json:"message,omitempty"`
+}
+
+// ConformanceReport is the result of running a plugin through the
+// conformance suite.
+type ConformanceReport struct {
+	Plugin  string             `json:"plugin,omitempty"`
+	Version string             `json:"version,omitempty"`
+	Dir     string             `json:"dir"`
+	Checks  []ConformanceCheck `json:"checks"`
+}
+
+// Passed reports whether no check failed.
+func (r *ConformanceReport) Passed() bool {
+	for _, check := range r.Checks {
+		if check.Status == ConformanceFail {
+			return false
+		}
+	}
+	return true
+}
+
+// add records a check result.
+func (r *ConformanceReport) add(name string, status ConformanceStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, ConformanceCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// ConformanceOptions configures RunConformance.
+type ConformanceOptions struct {
+	// Timeout bounds each request to the plugin. Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// Sandbox is the sandbox binary plugins run in, as they would when
+	// installed.
+	Sandbox SandboxConfig
+}
+
+// RunConformance runs the plugin in dir through the conformance suite. It
+// checks that the manifest is valid and its permissions are sensible,
+// that the plugin completes the session handshake and shuts down when
+// asked, answers an unknown command with a structured error in time, and
+// returns valid results from its hooks.
+//
+// Declared commands are not run, since they may have side effects.
+func RunConformance(ctx context.Context, dir string, opts ConformanceOptions) *ConformanceReport {
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	report := &ConformanceReport{Dir: dir}
+
+	manifest, ok := checkManifest(report, dir)
+	if !ok {
+		return report
+	}
+	checkPermissions(report, manifest)
+
+	registry := NewRegistry("", nil)
+	registry.SetSandboxConfig(opts.Sandbox)
+	if err := registry.loadExternalPlugin(dir); err != nil {
+		report.add("load", ConformanceFail, "%v", err)
+		return report
+	}
+	p, _ := registry.Get(manifest.Name)
+	if err := p.Validate(); err != nil {
+		report.add("load", ConformanceFail, "%v", err)
+		return report
+	}
+	report.add("load", ConformancePass, "%s plugin loaded", manifest.Type)
+
+	if manifest.Type == PluginTypeBinary && manifest.Protocol == PluginProtocolSession {
+		if !checkSession(ctx, report, registry, manifest, opts) {
+			return report
+		}
+	}
+
+	checkErrorHandling(ctx, report, registry, manifest, opts)
+	checkHooks(ctx, report, registry, manifest, opts)
+
+	start := time.Now()
+	_ = registry.Close()
+	if manifest.Protocol == PluginProtocolSession {
+		if elapsed := time.Since(start); elapsed >= sessionShutdownTimeout {
+			report.add("shutdown", ConformanceWarn, "plugin did not exit within %v of shutdown and was killed", sessionShutdownTimeout)
+		} else {
+			report.add("shutdown", ConformancePass, "plugin exited after shutdown")
+		}
+	}
+
+	return report
+}
+
+// checkManifest reads and validates the manifest in dir.
+func checkManifest(report *ConformanceReport, dir string) (*PluginManifest, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		report.add("manifest", ConformanceFail, "failed to read manifest: %v", err)
+		return nil, false
+	}
+
+	var manifest PluginManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		report.add("manifest", ConformanceFail, "failed to parse manifest: %v", err)
+		return nil, false
+	}
+	report.Plugin = manifest.Name
+	report.Version = manifest.Version
+
+	if err := (&Registry{}).validateManifest(&manifest); err != nil {
+		report.add("manifest", ConformanceFail, "%v", err)
+		return nil, false
+	}
+	if !pluginNamePattern.MatchString(manifest.Name) {
+		report.add("manifest", ConformanceFail, "name '%s' cannot be installed; use letters, digits, '.', '_' and '-'", manifest.Name)
+		return nil, false
+	}
+	if manifest.Description == "" {
+		report.add("manifest", ConformanceWarn, "description is empty; it is shown in help and approval prompts")
+		return &manifest, true
+	}
+
+	report.add("manifest", ConformancePass, "%d command(s), %d hook(s)", len(manifest.Commands), len(manifest.Hooks))
+	return &manifest, true
+}
+
+// checkPermissions checks that permissions are explained, usable by the
+// plugin type, and not broader than they need to be.
+func checkPermissions(report *ConformanceReport, manifest *PluginManifest) {
+	if len(manifest.Permissions) == 0 {
+		report.add("permissions", ConformancePass, "no permissions requested")
+		return
+	}
+
+	if manifest.Type == PluginTypeWASM {
+		if _, err := wasmCapabilitiesFor(manifest.Permissions); err != nil {
+			report.add("permissions", ConformanceFail, "%v", err)
+			return
+		}
+	}
+
+	var warnings []string
+	for _, perm := range manifest.Permissions {
+		switch perm.Type {
+		case PermissionReadFile, PermissionWriteFile:
+			if _, _, err := permissionPath(perm.Resource); err != nil {
+				report.add("permissions", ConformanceFail, "invalid permission %s: %v", perm.String(), err)
+				return
+			}
+			if perm.Resource == "/" || perm.Resource == "/*" || perm.Resource == "~/*" {
+				warnings = append(warnings, fmt.Sprintf("%s grants a whole filesystem tree", perm.String()))
+			}
+		case PermissionReadEnv, PermissionNetwork, PermissionExecute:
+			if perm.Resource == "" || perm.Resource == "*" {
+				warnings = append(warnings, fmt.Sprintf("%s is not limited to a resource", perm.String()))
+			}
+		}
+		if perm.Description == "" {
+			warnings = append(warnings, fmt.Sprintf("%s has no description to show when asking for approval", perm.String()))
+		}
+	}
+
+	if len(warnings) > 0 {
+		for _, warning := range warnings {
+			report.add("permissions", ConformanceWarn, "%s", warning)
+		}
+		return
+	}
+	report.add("permissions", ConformancePass, "%d permission(s) declared", len(manifest.Permissions))
+}
+
+// checkSession checks the session handshake. Later checks are skipped if
+// it fails.
+func checkSession(ctx context.Context, report *ConformanceReport, registry *Registry, manifest *PluginManifest, opts ConformanceOptions) bool {
+	p, _ := registry.Get(manifest.Name)
+	binary, ok := p.(*BinaryPlugin)
+	if !ok {
+		return true
+	}
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	session, err := StartSandboxedSession(handshakeCtx, *manifest, binary.execPath, nil, binary.sandbox)
+	if err != nil {
+		report.add("handshake", ConformanceFail, "%v", err)
+		return false
+	}
+	info := session.Info()
+	_ = session.Close()
+
+	if info.Name != "" && info.Name != manifest.Name {
+		report.add("handshake", ConformanceWarn, "plugin reported name '%s', manifest says '%s'", info.Name, manifest.Name)
+	} else {
+		report.add("handshake", ConformancePass, "protocol version %d", info.ProtocolVersion)
+	}
+	return true
+}
+
+// checkErrorHandling sends a command the plugin does not declare and
+// checks that it answers in time with a structured error.
+func checkErrorHandling(ctx context.Context, report *ConformanceReport, registry *Registry, manifest *PluginManifest, opts ConformanceOptions) {
+	probeCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := registry.Execute(probeCtx, manifest.Name, &PluginInput{Command: conformanceProbeCommand})
+	elapsed := time.Since(start)
+
+	if probeCtx.Err() == context.DeadlineExceeded {
+		report.add("timeout", ConformanceFail, "plugin did not answer within %v", opts.Timeout)
+		report.add("errors", ConformanceSkip, "no answer to check")
+		return
+	}
+	report.add("timeout", ConformancePass, "answered in %v", elapsed.Round(time.Millisecond))
+
+	var rpcErr *RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		if rpcErr.Data == nil || rpcErr.Data.Suggestion == "" {
+			report.add("errors", ConformanceWarn, "unknown command error has no suggestion: %s", rpcErr.Message)
+		} else {
+			report.add("errors", ConformancePass, "unknown command answered with a structured error")
+		}
+	case err != nil:
+		report.add("errors", ConformanceFail, "unknown command broke the plugin: %v", err)
+	case output.Success():
+		report.add("errors", ConformanceFail, "unknown command '%s' was accepted", conformanceProbeCommand)
+	case output.Error != "":
+		report.add("errors", ConformanceWarn, "errors are reported in the result, not as a JSON-RPC error; the host cannot show suggestions or retry")
+	default:
+		report.add("errors", ConformanceWarn, "plugin exited with code %d without a JSON-RPC error", output.ExitCode)
+	}
+}
+
+// conformanceHookContexts are the contexts hooks are probed with.
+var conformanceHookContexts = map[HookPoint]*HookContext{
+	HookBeforeRequest: {
+		Request: &HookRequest{Method: "GET", URL: "https://api.example.com/conformance"},
+	},
+	HookAfterResponse: {
+		Request:  &HookRequest{Method: "GET", URL: "https://api.example.com/conformance"},
+		Response: &HookResponse{StatusCode: 200, Body: `{"ok":true}`},
+	},
+	HookOnError: {
+		Request:  &HookRequest{Method: "GET", URL: "https://api.example.com/conformance"},
+		Response: &HookResponse{StatusCode: 500, Body: `{"error":"conformance"}`},
+	},
+	HookBeforeOutput: {
+		Output: map[string]interface{}{"ok": true},
+	},
+}
+
+// checkHooks runs each declared hook with a sample context.
+func checkHooks(ctx context.Context, report *ConformanceReport, registry *Registry, manifest *PluginManifest, opts ConformanceOptions) {
+	for _, spec := range manifest.Hooks {
+		name := "hook " + string(spec.Point)
+		hc := *conformanceHookContexts[spec.Point]
+		hc.Point = spec.Point
+		hc.OperationID = "conformance"
+
+		hookCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		_, err := registry.RunHook(hookCtx, RegisteredHook{Plugin: manifest.Name, HookSpec: spec}, &hc)
+		cancel()
+		if err != nil {
+			report.add(name, ConformanceFail, "%v", err)
+			continue
+		}
+		report.add(name, ConformancePass, "returned a valid hook context")
+	}
+}
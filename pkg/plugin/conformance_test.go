@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConformancePlugin writes a stdio plugin whose executable is script.
+func writeConformancePlugin(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifest := `name: probe
+version: 1.0.0
+type: binary
+description: Conformance probe
+executable: plugin.sh
+permissions:
+  - type: network
+    resource: "*"
+`
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plugin.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunConformance(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   map[string]ConformanceStatus
+	}{
+		{
+			name:   "structured errors",
+			script: "#!/bin/sh\ncat >/dev/null\necho '{\"jsonrpc\":\"2.0\",\"id\":\"1\",\"error\":{\"code\":-32601,\"message\":\"unknown command\",\"data\":{\"suggestion\":\"Try greet\"}}}'\n",
+			want: map[string]ConformanceStatus{
+				"manifest": ConformancePass, "load": ConformancePass, "timeout": ConformancePass, "errors": ConformancePass,
+			},
+		},
+		{
+			name:   "accepts anything",
+			script: "#!/bin/sh\ncat >/dev/null\necho '{\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"exit_code\":0}}'\n",
+			want:   map[string]ConformanceStatus{"errors": ConformanceFail},
+		},
+		{
+			name:   "errors in result",
+			script: "#!/bin/sh\ncat >/dev/null\necho '{\"jsonrpc\":\"2.0\",\"id\":\"1\",\"result\":{\"exit_code\":1,\"error\":\"no such command\"}}'\n",
+			want:   map[string]ConformanceStatus{"errors": ConformanceWarn},
+		},
+		{
+			name:   "hangs",
+			script: "#!/bin/sh\nexec sleep 10\n",
+			want:   map[string]ConformanceStatus{"timeout": ConformanceFail, "errors": ConformanceSkip},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConformancePlugin(t, tt.script)
+			config := DefaultSandboxConfig()
+			config.Warnings = &bytes.Buffer{}
+
+			report := RunConformance(context.Background(), dir, ConformanceOptions{Timeout: 500 * time.Millisecond, Sandbox: config})
+
+			got := make(map[string]ConformanceStatus)
+			for _, check := range report.Checks {
+				got[check.Name] = check.Status
+			}
+			for name, status := range tt.want {
+				if got[name] != status {
+					t.Errorf("%s = %q, want %q (checks: %+v)", name, got[name], status, report.Checks)
+				}
+			}
+			if got["permissions"] != ConformanceWarn {
+				t.Errorf("Expected a warning for the unrestricted, undescribed network permission, got %q", got["permissions"])
+			}
+		})
+	}
+}
+
+func TestRunConformance_InvalidManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte("name: probe\ntype: binary\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := RunConformance(context.Background(), dir, ConformanceOptions{})
+	if report.Passed() || len(report.Checks) != 1 || report.Checks[0].Message != "version is required" {
+		t.Errorf("Expected only a failed manifest check, got %+v", report.Checks)
+	}
+}
@@ -75,17 +75,16 @@ func (e *Executor) Execute(ctx context.Context, plugin Plugin, input *PluginInpu
 
 // ExecuteBinary executes an external binary plugin.
 func (e *Executor) ExecuteBinary(ctx context.Context, execPath string, input *PluginInput) (*PluginOutput, error) {
-	// Prepare the JSON-RPC request
-	request := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      "1",
-		"method":  "execute",
-		"params":  input,
-	}
+	return e.ExecuteSandboxed(ctx, nil, execPath, input)
+}
 
-	requestData, err := json.Marshal(request)
+// ExecuteSandboxed executes an external binary plugin inside sandbox. A nil
+// sandbox runs the plugin with the user's full privileges.
+func (e *Executor) ExecuteSandboxed(ctx context.Context, sandbox *Sandbox, execPath string, input *PluginInput) (*PluginOutput, error) {
+	// Prepare the JSON-RPC request
+	requestData, err := marshalExecuteRequest(input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
 	// Create command
@@ -97,8 +96,8 @@ func (e *Executor) ExecuteBinary(ctx context.Context, execPath string, input *Pl
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// Set environment variables
-	if input.Env != nil {
+	// Set environment variables; a sandbox builds its own environment
+	if input.Env != nil && sandbox == nil {
 		env := make([]string, 0, len(input.Env))
 		for k, v := range input.Env {
 			env = append(env, fmt.Sprintf("%s=%s", k, v))
@@ -115,7 +114,17 @@ func (e *Executor) ExecuteBinary(ctx context.Context, execPath string, input *Pl
 	startTime := time.Now()
 
 	// Execute the command
-	err = cmd.Run()
+	if sandbox == nil {
+		err = cmd.Run()
+	} else {
+		var cleanup func()
+		cleanup, err = sandbox.Start(cmd, input.Env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute binary: %w", err)
+		}
+		err = cmd.Wait()
+		cleanup()
+	}
 	duration := time.Since(startTime)
 
 	// Get exit code
@@ -128,30 +137,44 @@ func (e *Executor) ExecuteBinary(ctx context.Context, execPath string, input *Pl
 		}
 	}
 
+	return parseExecuteResponse(stdout.Bytes(), stderr.Bytes(), exitCode, duration)
+}
+
+// marshalExecuteRequest encodes input as the JSON-RPC "execute" request
+// external plugins read from stdin.
+func marshalExecuteRequest(input *PluginInput) ([]byte, error) {
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "1",
+		"method":  "execute",
+		"params":  input,
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return data, nil
+}
+
+// parseExecuteResponse builds plugin output from what an external plugin
+// wrote to stdout and stderr. Stdout that is not a JSON-RPC response is
+// returned as plain output; a JSON-RPC error is returned as an *RPCError.
+func parseExecuteResponse(stdoutData, stderrData []byte, exitCode int, duration time.Duration) (*PluginOutput, error) {
 	// Parse JSON-RPC response
 	var response struct {
-		JSONRPC string `json:"jsonrpc"`
-		ID      string `json:"id"`
-		Result  struct {
-			Stdout   string                 `json:"stdout"`
-			Stderr   string                 `json:"stderr"`
-			ExitCode int                    `json:"exit_code"`
-			Data     map[string]interface{} `json:"data"`
-			Error    string                 `json:"error"`
-		} `json:"result"`
-		Error *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
+		JSONRPC string        `json:"jsonrpc"`
+		ID      string        `json:"id"`
+		Result  executeResult `json:"result"`
+		Error   *RPCError     `json:"error"`
 	}
 
-	stdoutData := stdout.Bytes()
 	if len(stdoutData) > 0 {
 		if err := json.Unmarshal(stdoutData, &response); err != nil {
 			// If not valid JSON-RPC, treat stdout as plain output
 			return &PluginOutput{
-				Stdout:   stdout.String(),
-				Stderr:   stderr.String(),
+				Stdout:   string(stdoutData),
+				Stderr:   string(stderrData),
 				ExitCode: exitCode,
 				Duration: duration,
 			}, nil
@@ -159,34 +182,43 @@ func (e *Executor) ExecuteBinary(ctx context.Context, execPath string, input *Pl
 
 		// Check for JSON-RPC error
 		if response.Error != nil {
-			return &PluginOutput{
-				Stderr:   response.Error.Message,
-				ExitCode: response.Error.Code,
-				Error:    response.Error.Message,
-				Duration: duration,
-			}, nil
+			return nil, response.Error
 		}
 
 		// Return structured response
-		return &PluginOutput{
-			Stdout:   response.Result.Stdout,
-			Stderr:   response.Result.Stderr,
-			ExitCode: response.Result.ExitCode,
-			Data:     response.Result.Data,
-			Error:    response.Result.Error,
-			Duration: duration,
-		}, nil
+		return response.Result.output(duration), nil
 	}
 
 	// No stdout, return stderr and exit code
 	return &PluginOutput{
 		Stdout:   "",
-		Stderr:   stderr.String(),
+		Stderr:   string(stderrData),
 		ExitCode: exitCode,
 		Duration: duration,
 	}, nil
 }
 
+// executeResult is the result of an "execute" request.
+type executeResult struct {
+	Stdout   string                 `json:"stdout"`
+	Stderr   string                 `json:"stderr"`
+	ExitCode int                    `json:"exit_code"`
+	Data     map[string]interface{} `json:"data"`
+	Error    string                 `json:"error"`
+}
+
+// output converts the result to plugin output.
+func (r *executeResult) output(duration time.Duration) *PluginOutput {
+	return &PluginOutput{
+		Stdout:   r.Stdout,
+		Stderr:   r.Stderr,
+		ExitCode: r.ExitCode,
+		Data:     r.Data,
+		Error:    r.Error,
+		Duration: duration,
+	}
+}
+
 // ExecuteWithRetry executes a plugin with retry logic.
 func (e *Executor) ExecuteWithRetry(ctx context.Context, plugin Plugin, input *PluginInput, maxRetries int) (*PluginOutput, error) {
 	var lastErr error
@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// HookPoint identifies where in the execution of an API call a hook runs.
+type HookPoint string
+
+const (
+	// HookBeforeRequest runs before a request is sent and can change it,
+	// e.g. to add headers or sign it.
+	HookBeforeRequest HookPoint = "before-request"
+
+	// HookAfterResponse runs on every response before it is used.
+	HookAfterResponse HookPoint = "after-response"
+
+	// HookOnError runs when a request fails or the response status is 400
+	// or above. It can rewrite the response, e.g. to translate vendor
+	// error bodies, or recover from a transport error by supplying one.
+	HookOnError HookPoint = "on-error"
+
+	// HookBeforeOutput runs on the decoded data before it is formatted.
+	HookBeforeOutput HookPoint = "before-output"
+)
+
+// HookCommand is the PluginInput.Command a hook is executed with.
+const HookCommand = "hook"
+
+// HookPoints lists the supported hook points in execution order.
+var HookPoints = []HookPoint{HookBeforeRequest, HookAfterResponse, HookOnError, HookBeforeOutput}
+
+// isHookPoint reports whether point is a supported hook point.
+func isHookPoint(point HookPoint) bool {
+	for _, p := range HookPoints {
+		if p == point {
+			return true
+		}
+	}
+	return false
+}
+
+// HookSpec declares a hook in a plugin manifest.
+type HookSpec struct {
+	// Point is where the hook runs.
+	Point HookPoint `yaml:"point" json:"point"`
+
+	// Order sorts the hooks of a point; lower runs first. Hooks with the
+	// same order run in plugin name order.
+	Order int `yaml:"order,omitempty" json:"order,omitempty"`
+
+	// Operations limits the hook to operation IDs matching these glob
+	// patterns. Empty means every call.
+	Operations []string `yaml:"operations,omitempty" json:"operations,omitempty"`
+}
+
+// Matches reports whether the hook applies to an operation.
+func (h HookSpec) Matches(operationID string) bool {
+	if len(h.Operations) == 0 {
+		return true
+	}
+	for _, pattern := range h.Operations {
+		if ok, _ := path.Match(pattern, operationID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredHook is a hook declared by a registered plugin.
+type RegisteredHook struct {
+	Plugin string
+	HookSpec
+}
+
+// HookRequest is the serialisable model of an HTTP request.
+type HookRequest struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   string              `json:"body,omitempty"`
+}
+
+// HookResponse is the serialisable model of an HTTP response.
+type HookResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       string              `json:"body,omitempty"`
+}
+
+// HookContext is what a hook receives as PluginInput.Data and returns as
+// PluginOutput.Data. Returning no data leaves the context unchanged.
+type HookContext struct {
+	Point       HookPoint     `json:"point"`
+	OperationID string        `json:"operation_id,omitempty"`
+	Request     *HookRequest  `json:"request,omitempty"`
+	Response    *HookResponse `json:"response,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Output      interface{}   `json:"output,omitempty"`
+}
+
+// Hooks returns the hooks registered for a point in execution order.
+func (r *Registry) Hooks(point HookPoint) []RegisteredHook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var hooks []RegisteredHook
+	for name, manifest := range r.manifests {
+		for _, spec := range manifest.Hooks {
+			if spec.Point == point {
+				hooks = append(hooks, RegisteredHook{Plugin: name, HookSpec: spec})
+			}
+		}
+	}
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if hooks[i].Order != hooks[j].Order {
+			return hooks[i].Order < hooks[j].Order
+		}
+		return hooks[i].Plugin < hooks[j].Plugin
+	})
+	return hooks
+}
+
+// RunHook executes a hook and returns the context it produced.
+func (r *Registry) RunHook(ctx context.Context, hook RegisteredHook, hc *HookContext) (*HookContext, error) {
+	data, err := hookData(hc)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := r.Execute(ctx, hook.Plugin, &PluginInput{Command: HookCommand, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if !output.Success() {
+		msg := output.Error
+		if msg == "" {
+			msg = fmt.Sprintf("exit code %d", output.ExitCode)
+		}
+		return nil, NewPluginError(hook.Plugin, fmt.Sprintf("%s hook failed", hook.Point), fmt.Errorf("%s", msg))
+	}
+	if len(output.Data) == 0 {
+		return hc, nil
+	}
+
+	encoded, err := json.Marshal(output.Data)
+	if err != nil {
+		return nil, NewPluginError(hook.Plugin, "invalid hook result", err)
+	}
+	result := &HookContext{}
+	if err := json.Unmarshal(encoded, result); err != nil {
+		return nil, NewPluginError(hook.Plugin, "invalid hook result", err)
+	}
+	// Hooks cannot move themselves to another point
+	result.Point = hc.Point
+	result.OperationID = hc.OperationID
+	return result, nil
+}
+
+// hookData converts a hook context to plugin input data.
+func hookData(hc *HookContext) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(hc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook context: %w", err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, fmt.Errorf("failed to encode hook context: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newHookTestPlugin(name string, hooks []HookSpec, execute func(ctx context.Context, input *PluginInput) (*PluginOutput, error)) *MockPlugin {
+	return &MockPlugin{
+		name:        name,
+		executeFunc: execute,
+		info: &PluginInfo{
+			Manifest: PluginManifest{
+				Name:    name,
+				Version: "1.0.0",
+				Type:    PluginTypeBuiltin,
+				Hooks:   hooks,
+			},
+			Status: PluginStatusReady,
+		},
+	}
+}
+
+func TestRegistry_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	pm, err := NewPermissionManager(tmpDir, &AutoApprover{})
+	if err != nil {
+		t.Fatalf("NewPermissionManager() error = %v", err)
+	}
+	registry := NewRegistry(tmpDir, pm)
+
+	plugins := []*MockPlugin{
+		newHookTestPlugin("signer", []HookSpec{{Point: HookBeforeRequest, Order: 100}}, nil),
+		newHookTestPlugin("tenant", []HookSpec{{Point: HookBeforeRequest, Order: 10}, {Point: HookOnError}}, nil),
+		newHookTestPlugin("audit", []HookSpec{{Point: HookBeforeRequest, Order: 100}}, nil),
+	}
+	for _, p := range plugins {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	var order []string
+	for _, hook := range registry.Hooks(HookBeforeRequest) {
+		order = append(order, hook.Plugin)
+	}
+	if strings.Join(order, ",") != "tenant,audit,signer" {
+		t.Errorf("Hooks() order = %v, want tenant,audit,signer", order)
+	}
+	if len(registry.Hooks(HookAfterResponse)) != 0 {
+		t.Error("Expected no after-response hooks")
+	}
+}
+
+func TestRegistry_RunHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	pm, err := NewPermissionManager(tmpDir, &AutoApprover{})
+	if err != nil {
+		t.Fatalf("NewPermissionManager() error = %v", err)
+	}
+	registry := NewRegistry(tmpDir, pm)
+
+	spec := HookSpec{Point: HookBeforeRequest}
+	tenant := newHookTestPlugin("tenant", []HookSpec{spec}, func(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
+		if input.Command != HookCommand {
+			t.Errorf("Command = %q, want %q", input.Command, HookCommand)
+		}
+		request := input.Data["request"].(map[string]interface{})
+		request["header"] = map[string]interface{}{"X-Tenant": []interface{}{"acme"}}
+		input.Data["point"] = string(HookOnError)
+		return &PluginOutput{Data: input.Data}, nil
+	})
+	noop := newHookTestPlugin("noop", []HookSpec{spec}, nil)
+	failing := newHookTestPlugin("failing", []HookSpec{spec}, func(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
+		return &PluginOutput{ExitCode: 1, Error: "bad signature key"}, nil
+	})
+	for _, p := range []*MockPlugin{tenant, noop, failing} {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	hc := &HookContext{
+		Point:       HookBeforeRequest,
+		OperationID: "listUsers",
+		Request:     &HookRequest{Method: "GET", URL: "https://api.example.com/users"},
+	}
+
+	result, err := registry.RunHook(context.Background(), RegisteredHook{Plugin: "tenant", HookSpec: spec}, hc)
+	if err != nil {
+		t.Fatalf("RunHook() error = %v", err)
+	}
+	if got := result.Request.Header["X-Tenant"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("Expected X-Tenant header, got %v", result.Request.Header)
+	}
+	if result.Point != HookBeforeRequest || result.OperationID != "listUsers" {
+		t.Errorf("Hook changed point or operation: %s %s", result.Point, result.OperationID)
+	}
+
+	result, err = registry.RunHook(context.Background(), RegisteredHook{Plugin: "noop", HookSpec: spec}, hc)
+	if err != nil || result != hc {
+		t.Errorf("Expected unchanged context without data, got %v (%v)", result, err)
+	}
+
+	if _, err := registry.RunHook(context.Background(), RegisteredHook{Plugin: "failing", HookSpec: spec}, hc); err == nil || !strings.Contains(err.Error(), "bad signature key") {
+		t.Errorf("Expected hook failure, got %v", err)
+	}
+}
+
+func TestHookSpec_Matches(t *testing.T) {
+	spec := HookSpec{Point: HookBeforeRequest, Operations: []string{"list*", "getUser"}}
+
+	tests := map[string]bool{
+		"listUsers":  true,
+		"getUser":    true,
+		"deleteUser": false,
+		"":           false,
+	}
+	for operationID, want := range tests {
+		if got := spec.Matches(operationID); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", operationID, got, want)
+		}
+	}
+
+	if !(HookSpec{Point: HookBeforeRequest}).Matches("") {
+		t.Error("Expected a hook without operations to match every call")
+	}
+}
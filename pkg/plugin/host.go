@@ -0,0 +1,513 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/auth"
+	"github.com/CliForge/cliforge/pkg/progress"
+	"github.com/CliForge/cliforge/pkg/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+// maxHostResponseBody caps the body http.request returns to a plugin.
+const maxHostResponseBody = 32 * 1024 * 1024
+
+// HostServices back the host methods session plugins can call. Methods
+// whose service is nil answer with an error.
+//
+// Every method is gated by the plugin's permissions:
+//
+//	http.request  network:<host>       e.g. network:api.example.com
+//	secrets.get   credential[:<key>]   no resource grants every key
+//	state.get     read:state:<key>
+//	state.set     write:state:<key>
+type HostServices struct {
+	// HTTPClient sends http.request calls.
+	HTTPClient *http.Client
+
+	// Auth adds the user's credentials to http.request calls to the CLI's
+	// APIs, so plugins call them as the user without seeing the
+	// credentials. Calls to other URLs are sent without them.
+	Auth *auth.Manager
+
+	// APIs maps the base URL of each API to the authenticator of Auth its
+	// calls use. An empty name uses the default authenticator.
+	APIs map[string]string
+
+	// Secrets answers secrets.get.
+	Secrets SecretStore
+
+	// State backs state.get and state.set. Each plugin has its own keys.
+	State StateStore
+
+	// Progress renders progress notifications. Without it they are
+	// ignored.
+	Progress *progress.Manager
+
+	// Log receives log notifications. Defaults to os.Stderr.
+	Log io.Writer
+
+	// Detector masks secrets in log messages and in the headers and
+	// bodies of http.request responses.
+	Detector *secrets.Detector
+}
+
+// SecretStore looks up secrets for plugins.
+type SecretStore interface {
+	GetSecret(key string) (string, error)
+}
+
+// StateStore stores JSON values for plugins.
+type StateStore interface {
+	// Get returns the value of key, and false if it is not set.
+	Get(plugin, key string) (json.RawMessage, bool, error)
+
+	// Set stores the value of key.
+	Set(plugin, key string, value json.RawMessage) error
+}
+
+// hostConn serves host methods and notifications for one session.
+type hostConn struct {
+	plugin   string
+	granted  []Permission
+	services *HostServices
+
+	mu       sync.Mutex
+	revealed []string
+	progress progress.Progress
+	failed   bool
+}
+
+// newHostConn creates a connection for plugin with its granted
+// permissions. services may be nil.
+func newHostConn(plugin string, granted []Permission, services *HostServices) *hostConn {
+	if services == nil {
+		services = &HostServices{}
+	}
+	return &hostConn{plugin: plugin, granted: granted, services: services}
+}
+
+// call runs a host method.
+func (h *hostConn) call(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "http.request":
+		var req hostHTTPRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return h.httpRequest(ctx, &req)
+
+	case "secrets.get":
+		var req struct {
+			Key string `json:"key"`
+		}
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return h.secretsGet(req.Key)
+
+	case "state.get", "state.set":
+		var req struct {
+			Key   string          `json:"key"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		if method == "state.get" {
+			return h.stateGet(req.Key)
+		}
+		return h.stateSet(req.Key, req.Value)
+
+	default:
+		return nil, &RPCError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+	}
+}
+
+// hostHTTPRequest are the parameters of http.request.
+type hostHTTPRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// hostHTTPResponse is the result of http.request.
+type hostHTTPResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+func (h *hostConn) httpRequest(ctx context.Context, req *hostHTTPRequest) (interface{}, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || target.Host == "" {
+		return nil, &RPCError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid url %q", req.URL)}
+	}
+	if err := h.check(PermissionNetwork, target.Hostname()); err != nil {
+		return nil, err
+	}
+	if h.services.HTTPClient == nil {
+		return nil, fmt.Errorf("http.request is not available")
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(req.Body))
+	if err != nil {
+		return nil, &RPCError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	client := h.services.HTTPClient
+	if h.services.Auth != nil {
+		client = h.authenticatedClient(client)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHostResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	headers := map[string][]string(resp.Header)
+	text := string(body)
+	if h.services.Detector != nil {
+		headers = h.services.Detector.MaskHeaders(headers)
+		if text, err = h.services.Detector.MaskJSONString(text); err != nil {
+			return nil, fmt.Errorf("failed to mask response: %w", err)
+		}
+	}
+	return &hostHTTPResponse{Status: resp.StatusCode, Headers: headers, Body: text}, nil
+}
+
+// authenticatedClient returns a copy of client that adds the user's
+// credentials to requests to the CLI's APIs. Redirects away from an API
+// are returned rather than followed.
+func (h *hostConn) authenticatedClient(client *http.Client) *http.Client {
+	transport := &auth.Transport{Base: client.Transport, Manager: h.services.Auth, APIs: h.services.APIs}
+
+	authenticated := *client
+	authenticated.Transport = transport
+	authenticated.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if _, fromAPI := transport.API(via[0].URL); fromAPI {
+			if _, toAPI := transport.API(req.URL); !toAPI {
+				return http.ErrUseLastResponse
+			}
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &authenticated
+}
+
+func (h *hostConn) secretsGet(key string) (interface{}, error) {
+	if err := h.check(PermissionCredential, key); err != nil {
+		return nil, err
+	}
+	if h.services.Secrets == nil {
+		return nil, fmt.Errorf("secrets.get is not available")
+	}
+
+	value, err := h.services.Secrets.GetSecret(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the value out of anything the plugin logs.
+	h.mu.Lock()
+	h.revealed = append(h.revealed, value)
+	h.mu.Unlock()
+
+	return map[string]string{"value": value}, nil
+}
+
+func (h *hostConn) stateGet(key string) (interface{}, error) {
+	if err := h.check(PermissionReadState, key); err != nil {
+		return nil, err
+	}
+	if h.services.State == nil {
+		return nil, fmt.Errorf("state.get is not available")
+	}
+
+	value, found, err := h.services.State.Get(h.plugin, key)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"value": value, "found": found}, nil
+}
+
+func (h *hostConn) stateSet(key string, value json.RawMessage) (interface{}, error) {
+	if err := h.check(PermissionWriteState, key); err != nil {
+		return nil, err
+	}
+	if h.services.State == nil {
+		return nil, fmt.Errorf("state.set is not available")
+	}
+
+	if err := h.services.State.Set(h.plugin, key, value); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{}, nil
+}
+
+// check returns a permission-denied error unless a granted permission of
+// type permType covers resource.
+func (h *hostConn) check(permType PermissionType, resource string) error {
+	for _, perm := range h.granted {
+		if perm.Type != permType {
+			continue
+		}
+		if perm.Resource == "" || MatchPermission(perm.Resource, resource) {
+			return nil
+		}
+	}
+	return &RPCError{
+		Code:    rpcPermissionDenied,
+		Message: fmt.Sprintf("permission denied: %s is not granted", Permission{Type: permType, Resource: resource}),
+	}
+}
+
+// hostLog are the parameters of the log notification.
+type hostLog struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// hostProgress are the parameters of the progress notification.
+type hostProgress struct {
+	Message    string  `json:"message"`
+	Current    int     `json:"current,omitempty"`
+	Total      int     `json:"total,omitempty"`
+	Percentage float64 `json:"percentage,omitempty"`
+}
+
+// notify handles a notification. Malformed and unknown notifications are
+// ignored.
+func (h *hostConn) notify(method string, params json.RawMessage) {
+	switch method {
+	case "log":
+		var msg hostLog
+		if decodeParams(params, &msg) != nil {
+			return
+		}
+		h.log(&msg)
+
+	case "progress":
+		var msg hostProgress
+		if decodeParams(params, &msg) != nil {
+			return
+		}
+		h.updateProgress(&msg)
+	}
+}
+
+// log writes a log message, masking secrets.
+func (h *hostConn) log(msg *hostLog) {
+	w := h.services.Log
+	if w == nil {
+		w = os.Stderr
+	}
+
+	text := h.mask(msg.Message)
+	switch msg.Level {
+	case "", "info":
+		fmt.Fprintf(w, "[%s] %s\n", h.plugin, text)
+	default:
+		fmt.Fprintf(w, "[%s] %s: %s\n", h.plugin, msg.Level, text)
+	}
+}
+
+// mask hides secrets in text: values the plugin got from secrets.get and
+// anything the detector recognises.
+func (h *hostConn) mask(text string) string {
+	h.mu.Lock()
+	for _, value := range h.revealed {
+		if value != "" {
+			text = strings.ReplaceAll(text, value, "***")
+		}
+	}
+	h.mu.Unlock()
+
+	if h.services.Detector != nil {
+		text = h.services.Detector.MaskString(text)
+	}
+	return text
+}
+
+// updateProgress renders a progress notification, starting a progress
+// indicator if none is active.
+func (h *hostConn) updateProgress(msg *hostProgress) {
+	manager := h.services.Progress
+	if manager == nil {
+		return
+	}
+
+	data := &progress.Data{
+		Message:    h.mask(msg.Message),
+		Current:    msg.Current,
+		Total:      msg.Total,
+		Percentage: msg.Percentage,
+		Timestamp:  time.Now(),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if current := manager.GetCurrentProgress(); current == nil || !current.IsActive() {
+		started, err := manager.StartProgress(data.Message, msg.Total)
+		if err != nil {
+			return
+		}
+		h.progress = started
+	}
+	_ = manager.UpdateProgressWithData(data)
+}
+
+// fail marks the current execution as failed.
+func (h *hostConn) fail() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failed = true
+}
+
+// finish stops the progress indicator the plugin started, if any, and
+// resets the connection for the next execution.
+func (h *hostConn) finish() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.progress != nil {
+		if h.failed {
+			_ = h.progress.Failure("")
+		} else {
+			_ = h.progress.Success("")
+		}
+		if h.services.Progress != nil {
+			_ = h.services.Progress.StopProgress()
+		}
+	}
+	h.progress = nil
+	h.failed = false
+}
+
+// decodeParams decodes request parameters.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &RPCError{Code: rpcInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// KeyringSecretStore reads plugin secrets from the system keyring.
+type KeyringSecretStore struct {
+	// Service is the keyring service the secrets are stored under.
+	Service string
+}
+
+// GetSecret returns the secret stored under key.
+func (s *KeyringSecretStore) GetSecret(key string) (string, error) {
+	value, err := keyring.Get(s.Service, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// FileStateStore keeps each plugin's state in a JSON file in a directory.
+type FileStateStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStateStore creates a store that keeps files in dir.
+func NewFileStateStore(dir string) *FileStateStore {
+	return &FileStateStore{dir: dir}
+}
+
+// Get returns the value of key for plugin.
+func (s *FileStateStore) Get(plugin, key string) (json.RawMessage, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load(plugin)
+	if err != nil {
+		return nil, false, err
+	}
+	value, found := values[key]
+	return value, found, nil
+}
+
+// Set stores the value of key for plugin.
+func (s *FileStateStore) Set(plugin, key string, value json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load(plugin)
+	if err != nil {
+		return err
+	}
+	values[key] = value
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin state: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create plugin state directory: %w", err)
+	}
+	if err := os.WriteFile(s.path(plugin), data, 0600); err != nil {
+		return fmt.Errorf("failed to write plugin state: %w", err)
+	}
+	return nil
+}
+
+// load reads a plugin's state file.
+func (s *FileStateStore) load(plugin string) (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+
+	data, err := os.ReadFile(s.path(plugin))
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin state: %w", err)
+	}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin state: %w", err)
+	}
+	return values, nil
+}
+
+// path returns the state file of a plugin.
+func (s *FileStateStore) path(plugin string) string {
+	return filepath.Join(s.dir, filepath.Base(plugin)+".json")
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/auth"
+	"github.com/CliForge/cliforge/pkg/secrets"
+)
+
+func TestHostConn_HTTPRequestAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/away" {
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"received": %q, "password": "hunter2"}`, r.Header.Get("X-API-Key"))
+	}))
+	defer server.Close()
+
+	manager := auth.NewManager("test")
+	apiKey, err := auth.NewAPIKeyAuth(&auth.APIKeyConfig{Key: "k3y", Location: auth.APIKeyLocationHeader, Name: "X-API-Key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.RegisterAuthenticator("default", apiKey); err != nil {
+		t.Fatal(err)
+	}
+	detector, err := secrets.NewDetector(secrets.DefaultSecretsBehavior())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := newHostConn("test", []Permission{{Type: PermissionNetwork, Resource: "127.0.0.1"}}, &HostServices{
+		HTTPClient: server.Client(),
+		Auth:       manager,
+		APIs:       map[string]string{server.URL + "/api": ""},
+		Detector:   detector,
+	})
+
+	request := func(path string) *hostHTTPResponse {
+		t.Helper()
+		result, err := h.httpRequest(context.Background(), &hostHTTPRequest{URL: server.URL + path})
+		if err != nil {
+			t.Fatalf("httpRequest(%s) error = %v", path, err)
+		}
+		return result.(*hostHTTPResponse)
+	}
+	received := func(resp *hostHTTPResponse) string {
+		t.Helper()
+		var body map[string]string
+		if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+			t.Fatalf("invalid body %q: %v", resp.Body, err)
+		}
+		if body["password"] == "hunter2" {
+			t.Errorf("Expected the password in the body to be masked, got %q", resp.Body)
+		}
+		return body["received"]
+	}
+
+	// Only requests to the API get the credentials
+	if got := received(request("/api/users")); got != "k3y" {
+		t.Errorf("Expected the API key on API requests, got %q", got)
+	}
+	for _, path := range []string{"/other", "/apiary"} {
+		if got := received(request(path)); got != "" {
+			t.Errorf("Expected no API key for %s, got %q", path, got)
+		}
+	}
+
+	// Redirects away from the API are not followed with the credentials
+	if resp := request("/api/away"); resp.Status != http.StatusFound || !strings.HasPrefix(resp.Headers["Location"][0], "http://example.com") {
+		t.Errorf("Expected the redirect away from the API to be returned, got %d", resp.Status)
+	}
+}
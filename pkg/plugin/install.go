@@ -0,0 +1,1016 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/update"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest in a plugin directory.
+const ManifestFile = "plugin-manifest.yaml"
+
+// InstallFile records the versions of an installed plugin and which one is
+// active. Versions are kept side by side in directories named after them:
+//
+//	plugins/<name>/install.yaml
+//	plugins/<name>/1.0.0/plugin-manifest.yaml
+//	plugins/<name>/1.1.0/plugin-manifest.yaml
+const InstallFile = "install.yaml"
+
+// MaxArchiveSize is the largest plugin archive, checksum or signature that
+// is downloaded when installing from a URL: 100 MiB. Larger downloads fail
+// instead of being read into memory.
+const MaxArchiveSize = 100 << 20
+
+// DownloadTimeout bounds a whole download when installing from a URL,
+// including reading the body.
+const DownloadTimeout = 5 * time.Minute
+
+// downloadClient downloads plugin sources.
+var downloadClient = &http.Client{Timeout: DownloadTimeout}
+
+// maxDownloadSize is the download limit, MaxArchiveSize outside tests.
+var maxDownloadSize int64 = MaxArchiveSize
+
+// pluginNamePattern restricts plugin names to safe directory names.
+var pluginNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Publisher is a publisher whose signed plugin archives are trusted.
+type Publisher struct {
+	Name string
+	Key  ed25519.PublicKey
+}
+
+// TrustPolicy decides which plugin archives can be installed.
+type TrustPolicy struct {
+	// Publishers are the keys archive signatures are checked against.
+	// Without publishers, signatures are not checked.
+	Publishers []Publisher
+
+	// RequireSignature refuses plugins not signed by a publisher.
+	RequireSignature bool
+}
+
+// NewTrustPolicy creates a trust policy from the plugins section of the
+// embedded configuration. A nil config accepts unsigned plugins.
+func NewTrustPolicy(config *cli.Plugins) (TrustPolicy, error) {
+	var policy TrustPolicy
+	if config == nil {
+		return policy, nil
+	}
+
+	policy.RequireSignature = config.RequireSignature
+	for _, publisher := range config.Publishers {
+		key, err := ParsePublicKey(publisher.PublicKey)
+		if err != nil {
+			return TrustPolicy{}, fmt.Errorf("publisher '%s': %w", publisher.Name, err)
+		}
+		policy.Publishers = append(policy.Publishers, Publisher{Name: publisher.Name, Key: key})
+	}
+
+	if policy.RequireSignature && len(policy.Publishers) == 0 {
+		return TrustPolicy{}, fmt.Errorf("signatures are required but no publishers are configured")
+	}
+
+	return policy, nil
+}
+
+// ParsePublicKey parses an Ed25519 public key, either PEM encoded or the
+// base64 encoded raw key.
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	key = strings.TrimSpace(key)
+
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		edKey, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an Ed25519 key")
+		}
+		return edKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// verifySignature returns the publisher whose key made signature.
+func (p TrustPolicy) verifySignature(data, signature []byte) (string, error) {
+	for _, publisher := range p.Publishers {
+		if ed25519.Verify(publisher.Key, data, signature) {
+			return publisher.Name, nil
+		}
+	}
+	return "", fmt.Errorf("signature does not match any trusted publisher")
+}
+
+// InstallRecord is the content of an installed plugin's InstallFile.
+type InstallRecord struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Active is the version DiscoverPlugins loads.
+	Active string `yaml:"active" json:"active"`
+
+	// Versions lists the installed versions, oldest first.
+	Versions []*InstalledVersion `yaml:"versions" json:"versions"`
+}
+
+// InstalledVersion describes an installed version of a plugin.
+type InstalledVersion struct {
+	Version string `yaml:"version" json:"version"`
+
+	// Source is the path or URL the version was installed from.
+	Source string `yaml:"source" json:"source"`
+
+	// SHA256 is the digest of the archive. It is empty for plugins
+	// installed from a directory.
+	SHA256 string `yaml:"sha256,omitempty" json:"sha256,omitempty"`
+
+	// Publisher is the trusted publisher that signed the archive. It is
+	// empty for unsigned plugins.
+	Publisher string `yaml:"publisher,omitempty" json:"publisher,omitempty"`
+
+	InstalledAt time.Time `yaml:"installed_at" json:"installed_at"`
+
+	// Files maps the files of the version to their SHA-256 digests, so
+	// tampering can be detected by VerifyPlugin.
+	Files map[string]string `yaml:"files" json:"files"`
+}
+
+// Version returns an installed version, or nil.
+func (rec *InstallRecord) Version(version string) *InstalledVersion {
+	for _, v := range rec.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// ActiveVersion returns the active version, or nil.
+func (rec *InstallRecord) ActiveVersion() *InstalledVersion {
+	return rec.Version(rec.Active)
+}
+
+// setVersion adds or replaces a version, keeping versions sorted.
+func (rec *InstallRecord) setVersion(version *InstalledVersion) {
+	versions := []*InstalledVersion{version}
+	for _, v := range rec.Versions {
+		if v.Version != version.Version {
+			versions = append(versions, v)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	rec.Versions = versions
+}
+
+// removeVersion removes a version from the record.
+func (rec *InstallRecord) removeVersion(version string) {
+	versions := rec.Versions[:0]
+	for _, v := range rec.Versions {
+		if v.Version != version {
+			versions = append(versions, v)
+		}
+	}
+	rec.Versions = versions
+}
+
+// InstallOptions configures how a plugin is installed or upgraded.
+type InstallOptions struct {
+	// SHA256 is the expected digest of an archive. When empty, the digest
+	// in <source>.sha256 is checked if that file exists.
+	SHA256 string
+
+	// Signature is the path or URL of the archive's base64 encoded
+	// Ed25519 signature. When empty, <source>.sig is used if it exists.
+	Signature string
+
+	// Force reinstalls an installed version and allows downgrades.
+	Force bool
+}
+
+// InstallResult describes a newly installed version.
+type InstallResult struct {
+	Manifest *PluginManifest
+	Version  *InstalledVersion
+
+	// Previous is the version that was active before, if any.
+	Previous string
+}
+
+// VerifyResult reports whether the files of an installed version are
+// unchanged since it was installed.
+type VerifyResult struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Active   bool     `json:"active"`
+	Modified []string `json:"modified,omitempty"`
+	Missing  []string `json:"missing,omitempty"`
+	Added    []string `json:"added,omitempty"`
+}
+
+// OK reports whether the version passed verification.
+func (v *VerifyResult) OK() bool {
+	return len(v.Modified) == 0 && len(v.Missing) == 0 && len(v.Added) == 0
+}
+
+// SetTrustPolicy sets the policy archives are checked against on install.
+func (r *Registry) SetTrustPolicy(policy TrustPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trustPolicy = policy
+}
+
+// Install installs a plugin from a directory, a .tar.gz, .tgz or .zip
+// archive, or the http(s) URL of an archive, and makes it active. The
+// permissions the plugin requests are approved before anything is written.
+func (r *Registry) Install(ctx context.Context, source string, opts InstallOptions) (*InstallResult, error) {
+	return r.install(ctx, "", source, opts)
+}
+
+// Upgrade installs a newer version of an installed plugin and makes it
+// active, keeping the previous version. An empty source reinstalls from
+// the source of the active version. Only permissions the previous
+// versions were not granted are asked for.
+func (r *Registry) Upgrade(ctx context.Context, name, source string, opts InstallOptions) (*InstallResult, error) {
+	record, err := r.InstalledPlugin(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if source == "" {
+		active := record.ActiveVersion()
+		if active == nil {
+			return nil, fmt.Errorf("plugin '%s' has no active version to upgrade from", name)
+		}
+		source = active.Source
+	}
+
+	return r.install(ctx, name, source, opts)
+}
+
+// Activate makes an installed version active, e.g. to roll back an upgrade.
+func (r *Registry) Activate(name, version string) error {
+	record, err := r.InstalledPlugin(name)
+	if err != nil {
+		return err
+	}
+	if record.Version(version) == nil {
+		return fmt.Errorf("plugin '%s' version %s is not installed", name, version)
+	}
+
+	manifest, err := readManifest(filepath.Join(r.pluginDir, name, version))
+	if err != nil {
+		return err
+	}
+	if err := r.approvePermissions(manifest, record.Version(version).Publisher); err != nil {
+		return err
+	}
+
+	record.Active = version
+	return r.writeInstallRecord(record)
+}
+
+// Uninstall removes a version of an installed plugin, or the whole plugin
+// and its approved permissions when version is empty or the only one.
+func (r *Registry) Uninstall(name, version string) error {
+	record, err := r.InstalledPlugin(name)
+	if err != nil {
+		return err
+	}
+
+	if version != "" && len(record.Versions) > 1 {
+		if record.Version(version) == nil {
+			return fmt.Errorf("plugin '%s' version %s is not installed", name, version)
+		}
+		if version == record.Active {
+			return fmt.Errorf("version %s of plugin '%s' is active; activate another version first", version, name)
+		}
+		if err := os.RemoveAll(filepath.Join(r.pluginDir, name, version)); err != nil {
+			return fmt.Errorf("failed to remove plugin version: %w", err)
+		}
+		record.removeVersion(version)
+		return r.writeInstallRecord(record)
+	}
+
+	if version != "" && record.Version(version) == nil {
+		return fmt.Errorf("plugin '%s' version %s is not installed", name, version)
+	}
+	if err := os.RemoveAll(filepath.Join(r.pluginDir, name)); err != nil {
+		return fmt.Errorf("failed to remove plugin: %w", err)
+	}
+	if r.permissionManager != nil {
+		if err := r.permissionManager.RevokePermissions(name); err != nil {
+			return fmt.Errorf("failed to revoke permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// InstalledPlugins returns the records of all installed plugins sorted by
+// name. Plugins copied into the plugin directory by hand are not included.
+func (r *Registry) InstalledPlugins() ([]*InstallRecord, error) {
+	entries, err := os.ReadDir(r.pluginDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var records []*InstallRecord
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		record, err := readInstallRecord(filepath.Join(r.pluginDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, nil
+}
+
+// InstalledPlugin returns the record of an installed plugin.
+func (r *Registry) InstalledPlugin(name string) (*InstallRecord, error) {
+	if !pluginNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid plugin name: %s", name)
+	}
+
+	record, err := readInstallRecord(filepath.Join(r.pluginDir, name))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("plugin '%s' is not installed", name)
+	}
+	return record, nil
+}
+
+// InstalledManifest returns the manifest of an installed version.
+func (r *Registry) InstalledManifest(name, version string) (*PluginManifest, error) {
+	record, err := r.InstalledPlugin(name)
+	if err != nil {
+		return nil, err
+	}
+	if record.Version(version) == nil {
+		return nil, fmt.Errorf("plugin '%s' version %s is not installed", name, version)
+	}
+	return readManifest(filepath.Join(r.pluginDir, name, version))
+}
+
+// VerifyPlugin checks the files of every installed version of a plugin
+// against the digests recorded when it was installed.
+func (r *Registry) VerifyPlugin(name string) ([]*VerifyResult, error) {
+	record, err := r.InstalledPlugin(name)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*VerifyResult, 0, len(record.Versions))
+	for _, version := range record.Versions {
+		result := &VerifyResult{Name: name, Version: version.Version, Active: version.Version == record.Active}
+
+		dir := filepath.Join(r.pluginDir, name, version.Version)
+		files := map[string]string{}
+		if _, err := os.Stat(dir); err == nil {
+			if files, err = hashFiles(dir); err != nil {
+				return nil, err
+			}
+		}
+
+		for file, digest := range version.Files {
+			actual, ok := files[file]
+			switch {
+			case !ok:
+				result.Missing = append(result.Missing, file)
+			case actual != digest:
+				result.Modified = append(result.Modified, file)
+			}
+		}
+		for file := range files {
+			if _, ok := version.Files[file]; !ok {
+				result.Added = append(result.Added, file)
+			}
+		}
+		sort.Strings(result.Modified)
+		sort.Strings(result.Missing)
+		sort.Strings(result.Added)
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// install stages source, checks it against the trust policy and the
+// installed versions, approves its permissions and moves it in place. For
+// upgrades name is the installed plugin.
+func (r *Registry) install(ctx context.Context, name, source string, opts InstallOptions) (*InstallResult, error) {
+	if r.pluginDir == "" {
+		return nil, fmt.Errorf("no plugin directory configured")
+	}
+	if err := os.MkdirAll(r.pluginDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	// Stage inside the plugin directory so the final move is a rename
+	staging, err := os.MkdirTemp(r.pluginDir, ".install-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	staged, err := r.stagePlugin(ctx, source, opts, staging)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(staged.dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validateManifest(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := r.validateInstallName(manifest.Name); err != nil {
+		return nil, err
+	}
+	if _, err := update.ParseVersion(manifest.Version); err != nil || filepath.Base(manifest.Version) != manifest.Version {
+		return nil, fmt.Errorf("invalid plugin version: %s", manifest.Version)
+	}
+
+	pluginDir := filepath.Join(r.pluginDir, manifest.Name)
+	record, err := readInstallRecord(pluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		if record == nil {
+			if _, err := os.Stat(pluginDir); err == nil {
+				return nil, fmt.Errorf("%s exists but was not created by plugin install; remove it first", pluginDir)
+			}
+		} else if !opts.Force {
+			return nil, fmt.Errorf("plugin '%s' is already installed (version %s); use upgrade", manifest.Name, record.Active)
+		}
+	} else {
+		if manifest.Name != name {
+			return nil, fmt.Errorf("source contains plugin '%s', not '%s'", manifest.Name, name)
+		}
+		if !opts.Force {
+			if compareVersions(manifest.Version, record.Active) <= 0 {
+				return nil, fmt.Errorf("version %s is not newer than the active version %s", manifest.Version, record.Active)
+			}
+			if record.Version(manifest.Version) != nil {
+				return nil, fmt.Errorf("version %s is already installed; activate it instead", manifest.Version)
+			}
+		}
+	}
+
+	// Permissions are approved up front rather than on first use
+	if err := r.approvePermissions(manifest, staged.publisher); err != nil {
+		return nil, err
+	}
+
+	files, err := hashFiles(staged.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := filepath.Join(pluginDir, manifest.Version)
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, fmt.Errorf("failed to replace installed version: %w", err)
+	}
+	if err := os.Rename(staged.dir, dest); err != nil {
+		return nil, fmt.Errorf("failed to install plugin: %w", err)
+	}
+
+	version := &InstalledVersion{
+		Version:     manifest.Version,
+		Source:      source,
+		SHA256:      staged.sha256,
+		Publisher:   staged.publisher,
+		InstalledAt: time.Now(),
+		Files:       files,
+	}
+
+	result := &InstallResult{Manifest: manifest, Version: version}
+	if record == nil {
+		record = &InstallRecord{Name: manifest.Name}
+	} else {
+		result.Previous = record.Active
+	}
+	record.setVersion(version)
+	record.Active = manifest.Version
+
+	if err := r.writeInstallRecord(record); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateInstallName checks that a plugin can be installed under name.
+func (r *Registry) validateInstallName(name string) error {
+	if !pluginNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid plugin name: %s", name)
+	}
+	// Built-in plugins skip permission approval
+	if r.permissionManager != nil && r.permissionManager.isBuiltinPlugin(name) {
+		return fmt.Errorf("plugin name '%s' is reserved for a built-in plugin", name)
+	}
+	return nil
+}
+
+// approvePermissions checks a manifest against the plugin policy, asks for
+// the permissions that have not been approved yet and records the version
+// they were approved for.
+func (r *Registry) approvePermissions(manifest *PluginManifest, publisher string) error {
+	if r.permissionManager == nil {
+		return nil
+	}
+	id := PluginIdentity{Name: manifest.Name, Version: manifest.Version, Publisher: publisher}
+	if err := r.permissionManager.Authorize(id, manifest.Permissions); err != nil {
+		return NewPluginError(manifest.Name, "permission denied", err)
+	}
+	return r.permissionManager.GrantPermissions(manifest.Name, manifest.Permissions, manifest.Version)
+}
+
+// stagedPlugin is a plugin unpacked into the staging directory.
+type stagedPlugin struct {
+	dir       string
+	sha256    string
+	publisher string
+}
+
+// stagePlugin copies or unpacks source into staging after verifying its
+// checksum and signature.
+func (r *Registry) stagePlugin(ctx context.Context, source string, opts InstallOptions, staging string) (*stagedPlugin, error) {
+	r.mu.RLock()
+	policy := r.trustPolicy
+	r.mu.RUnlock()
+
+	dir := filepath.Join(staging, "plugin")
+
+	if !isRemoteSource(source) {
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin source: %w", err)
+		}
+		if info.IsDir() {
+			if opts.SHA256 != "" || opts.Signature != "" {
+				return nil, fmt.Errorf("checksums and signatures can only be verified for archives")
+			}
+			if policy.RequireSignature {
+				return nil, fmt.Errorf("plugins must be signed by a trusted publisher; install a signed archive")
+			}
+			if err := copyDir(source, dir); err != nil {
+				return nil, err
+			}
+			return &stagedPlugin{dir: dir}, nil
+		}
+	}
+
+	format := archiveFormat(source)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported plugin source %s: expected a directory or a .tar.gz, .tgz or .zip archive", source)
+	}
+
+	data, err := readSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	expected := opts.SHA256
+	if expected == "" {
+		checksum, err := readOptionalSource(ctx, source+".sha256")
+		if err != nil {
+			return nil, err
+		}
+		// sha256sum format: "<digest>  <file>"
+		if fields := strings.Fields(string(checksum)); len(fields) > 0 {
+			expected = fields[0]
+		}
+	}
+	if expected != "" && !strings.EqualFold(expected, digest) {
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", expected, digest)
+	}
+
+	var signature []byte
+	if opts.Signature != "" {
+		signature, err = readSource(ctx, opts.Signature)
+	} else {
+		signature, err = readOptionalSource(ctx, source+".sig")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var publisher string
+	switch {
+	case signature != nil && len(policy.Publishers) > 0:
+		decoded, err := decodeSignature(signature)
+		if err != nil {
+			return nil, err
+		}
+		if publisher, err = policy.verifySignature(data, decoded); err != nil {
+			return nil, err
+		}
+	case policy.RequireSignature:
+		return nil, fmt.Errorf("plugin archive is not signed; signatures from a trusted publisher are required")
+	}
+
+	if format == "zip" {
+		err = extractZip(data, dir)
+	} else {
+		err = extractTarGz(data, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", source, err)
+	}
+
+	root, err := findPluginRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &stagedPlugin{dir: root, sha256: digest, publisher: publisher}, nil
+}
+
+// writeInstallRecord saves the install file of a plugin.
+func (r *Registry) writeInstallRecord(record *InstallRecord) error {
+	data, err := yaml.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal install record: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.pluginDir, record.Name, InstallFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write install record: %w", err)
+	}
+	return nil
+}
+
+// readInstallRecord reads the install file in dir. It returns nil if the
+// directory was not created by Install.
+func readInstallRecord(dir string) (*InstallRecord, error) {
+	data, err := os.ReadFile(filepath.Join(dir, InstallFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read install record: %w", err)
+	}
+
+	var record InstallRecord
+	if err := yaml.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, InstallFile), err)
+	}
+	return &record, nil
+}
+
+// readManifest reads the manifest in a plugin directory.
+func readManifest(dir string) (*PluginManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest PluginManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// compareVersions compares two semantic versions. Versions that do not
+// parse compare as strings.
+func compareVersions(a, b string) int {
+	va, errA := update.ParseVersion(a)
+	vb, errB := update.ParseVersion(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return va.Compare(vb)
+}
+
+// isRemoteSource reports whether source is an http(s) URL.
+func isRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// archiveFormat returns "tar.gz" or "zip" for a supported archive name.
+func archiveFormat(source string) string {
+	name := strings.ToLower(source)
+	if isRemoteSource(name) {
+		name = strings.SplitN(strings.SplitN(name, "?", 2)[0], "#", 2)[0]
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	default:
+		return ""
+	}
+}
+
+// readSource reads a local file or downloads a URL.
+func readSource(ctx context.Context, source string) ([]byte, error) {
+	data, err := readOptionalSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s not found", source)
+	}
+	return data, nil
+}
+
+// readOptionalSource is like readSource but returns nil if the file or
+// URL does not exist.
+func readOptionalSource(ctx context.Context, source string) ([]byte, error) {
+	if !isRemoteSource(source) {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", source, err)
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", source, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", source, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", source, err)
+	}
+	if int64(len(data)) > maxDownloadSize {
+		return nil, fmt.Errorf("failed to download %s: larger than %d bytes", source, maxDownloadSize)
+	}
+	return data, nil
+}
+
+// decodeSignature decodes a base64 or raw Ed25519 signature.
+func decodeSignature(data []byte) ([]byte, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err == nil && len(decoded) == ed25519.SignatureSize {
+		return decoded, nil
+	}
+	if len(data) == ed25519.SignatureSize {
+		return data, nil
+	}
+	return nil, fmt.Errorf("invalid signature: expected a base64 encoded Ed25519 signature")
+}
+
+// extractTarGz unpacks a gzipped tarball into dest.
+func extractTarGz(data []byte, dest string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := archivePath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// Written by git archive, carries no files
+		default:
+			return fmt.Errorf("unsupported archive entry %s", header.Name)
+		}
+	}
+}
+
+// extractZip unpacks a zip archive into dest.
+func extractZip(data []byte, dest string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		target, err := archivePath(dest, file.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = writeFile(target, rc, mode)
+			_ = rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported archive entry %s", file.Name)
+		}
+	}
+	return nil
+}
+
+// archivePath resolves an archive entry inside dest, rejecting entries
+// that would escape it.
+func archivePath(dest, name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("archive entry %s is outside the plugin directory", name)
+	}
+	return filepath.Join(dest, filepath.FromSlash(clean)), nil
+}
+
+// writeFile writes a plugin file, keeping only its execute permission.
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if mode&0111 != 0 {
+		perm = 0755
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyDir copies a plugin directory.
+func copyDir(src, dest string) error {
+	return filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode().IsRegular():
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			return writeFile(target, f, info.Mode())
+		default:
+			return fmt.Errorf("unsupported file %s", file)
+		}
+	})
+}
+
+// findPluginRoot returns the directory holding the manifest: the archive
+// root or its only top-level directory.
+func findPluginRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "__MACOSX" {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if len(dirs) == 1 {
+		root := filepath.Join(dir, dirs[0])
+		if _, err := os.Stat(filepath.Join(root, ManifestFile)); err == nil {
+			return root, nil
+		}
+	}
+
+	return "", fmt.Errorf("no %s found in plugin source", ManifestFile)
+}
+
+// hashFiles returns the SHA-256 digests of the files in dir by slash
+// separated relative path.
+func hashFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		files[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash plugin files: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,422 @@
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingApprover approves permissions and records what it was asked.
+type recordingApprover struct {
+	requests [][]Permission
+	deny     bool
+}
+
+func (a *recordingApprover) RequestApproval(pluginName string, permissions []Permission) (bool, error) {
+	a.requests = append(a.requests, permissions)
+	return !a.deny, nil
+}
+
+func installTestManifest(version string, permissions ...string) string {
+	manifest := "name: greeter\nversion: " + version + "\ntype: binary\nexecutable: greeter.sh\npermissions:\n"
+	for _, perm := range permissions {
+		parts := strings.SplitN(perm, "=", 2)
+		manifest += "  - type: " + parts[0] + "\n    resource: " + parts[1] + "\n"
+	}
+	if len(permissions) == 0 {
+		manifest += "  []\n"
+	}
+	return manifest
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0755)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func greeterFiles(prefix, manifest string) map[string]string {
+	return map[string]string{
+		prefix + ManifestFile: manifest,
+		prefix + "greeter.sh": "#!/bin/sh\necho hello\n",
+	}
+}
+
+func newInstallTestRegistry(t *testing.T, approver PermissionApprover) (*Registry, string) {
+	t.Helper()
+
+	configDir := t.TempDir()
+	pm, err := NewPermissionManager(configDir, approver)
+	if err != nil {
+		t.Fatalf("NewPermissionManager() error = %v", err)
+	}
+	pluginDir := filepath.Join(configDir, "plugins")
+	return NewRegistry(pluginDir, pm), pluginDir
+}
+
+func TestRegistry_Install_SignedArchive(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approver := &recordingApprover{}
+	registry, pluginDir := newInstallTestRegistry(t, approver)
+	registry.SetTrustPolicy(TrustPolicy{
+		Publishers:       []Publisher{{Name: "acme", Key: publicKey}},
+		RequireSignature: true,
+	})
+
+	archive := filepath.Join(t.TempDir(), "greeter-1.0.0.tar.gz")
+	data := writeTarGz(t, archive, greeterFiles("greeter/", installTestManifest("1.0.0", "read:env=HOME")))
+	sum := sha256.Sum256(data)
+	if err := os.WriteFile(archive+".sha256", []byte(hex.EncodeToString(sum[:])+"  greeter-1.0.0.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+	if err := os.WriteFile(archive+".sig", []byte(signature), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := registry.Install(context.Background(), archive, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.Version.Publisher != "acme" || result.Version.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Unexpected install result: %+v", result.Version)
+	}
+	if len(approver.requests) != 1 || approver.requests[0][0].String() != "read:env:HOME" {
+		t.Errorf("Expected approval of read:env:HOME, got %v", approver.requests)
+	}
+
+	info, err := os.Stat(filepath.Join(pluginDir, "greeter", "1.0.0", "greeter.sh"))
+	if err != nil || info.Mode()&0111 == 0 {
+		t.Errorf("Expected executable greeter.sh, got %v (%v)", info, err)
+	}
+
+	if err := registry.DiscoverPlugins(); err != nil {
+		t.Fatalf("DiscoverPlugins() error = %v", err)
+	}
+	if manifest, err := registry.GetManifest("greeter"); err != nil || manifest.Version != "1.0.0" {
+		t.Errorf("Expected greeter 1.0.0 to be discovered, got %v (%v)", manifest, err)
+	}
+
+	if _, err := registry.Install(context.Background(), archive, InstallOptions{}); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("Expected already installed error, got %v", err)
+	}
+}
+
+func TestRegistry_Install_Rejected(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "greeter.tar.gz")
+	data := writeTarGz(t, archive, greeterFiles("", installTestManifest("1.0.0")))
+
+	forged := filepath.Join(dir, "forged.sig")
+	if err := os.WriteFile(forged, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, data))), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unsupported := filepath.Join(dir, "plugin.rar")
+	if err := os.WriteFile(unsupported, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	privileged := filepath.Join(dir, "privileged.zip")
+	writeZip(t, privileged, greeterFiles("", installTestManifest("1.0.0", "execute=aws")))
+
+	escaping := filepath.Join(dir, "escaping.zip")
+	writeZip(t, escaping, map[string]string{ManifestFile: installTestManifest("1.0.0"), "../evil.sh": "boom"})
+
+	tests := []struct {
+		name    string
+		policy  TrustPolicy
+		source  string
+		opts    InstallOptions
+		approve bool
+		wantErr string
+	}{
+		{"checksum mismatch", TrustPolicy{}, archive, InstallOptions{SHA256: strings.Repeat("0", 64)}, true, "checksum mismatch"},
+		{"forged signature", TrustPolicy{Publishers: []Publisher{{Name: "acme", Key: publicKey}}}, archive, InstallOptions{Signature: forged}, true, "does not match any trusted publisher"},
+		{"unsigned", TrustPolicy{Publishers: []Publisher{{Name: "acme", Key: publicKey}}, RequireSignature: true}, archive, InstallOptions{}, true, "not signed"},
+		{"path traversal", TrustPolicy{}, escaping, InstallOptions{}, true, "outside the plugin directory"},
+		{"unsupported source", TrustPolicy{}, unsupported, InstallOptions{}, true, "unsupported plugin source"},
+		{"permissions denied", TrustPolicy{}, privileged, InstallOptions{}, false, "permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, pluginDir := newInstallTestRegistry(t, &recordingApprover{deny: !tt.approve})
+			registry.SetTrustPolicy(tt.policy)
+
+			_, err := registry.Install(context.Background(), tt.source, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Install() error = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(pluginDir, "greeter")); !os.IsNotExist(err) {
+				t.Error("Expected nothing to be installed")
+			}
+			if entries, _ := os.ReadDir(pluginDir); len(entries) != 0 {
+				t.Errorf("Expected staging directory to be removed, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestRegistry_Upgrade(t *testing.T) {
+	approver := &recordingApprover{}
+	registry, pluginDir := newInstallTestRegistry(t, approver)
+	dir := t.TempDir()
+
+	v1 := filepath.Join(dir, "v1")
+	if err := os.MkdirAll(v1, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range greeterFiles("", installTestManifest("1.0.0", "read:env=HOME")) {
+		if err := os.WriteFile(filepath.Join(v1, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := registry.Install(context.Background(), v1, InstallOptions{}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	v2 := filepath.Join(dir, "greeter-1.1.0.zip")
+	writeZip(t, v2, greeterFiles("", installTestManifest("1.1.0", "read:env=HOME", "network=api.example.com")))
+
+	approver.requests = nil
+	result, err := registry.Upgrade(context.Background(), "greeter", v2, InstallOptions{})
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if result.Previous != "1.0.0" || result.Manifest.Version != "1.1.0" {
+		t.Errorf("Unexpected upgrade result: previous %s, version %s", result.Previous, result.Manifest.Version)
+	}
+	if len(approver.requests) != 1 || len(approver.requests[0]) != 1 || approver.requests[0][0].String() != "network:api.example.com" {
+		t.Errorf("Expected approval of only the new permission, got %v", approver.requests)
+	}
+
+	if _, err := registry.Upgrade(context.Background(), "greeter", v1, InstallOptions{}); err == nil || !strings.Contains(err.Error(), "not newer") {
+		t.Errorf("Expected downgrade to be refused, got %v", err)
+	}
+
+	record, err := registry.InstalledPlugin("greeter")
+	if err != nil {
+		t.Fatalf("InstalledPlugin() error = %v", err)
+	}
+	if record.Active != "1.1.0" || len(record.Versions) != 2 || record.Versions[0].Version != "1.0.0" {
+		t.Errorf("Unexpected install record: %+v", record)
+	}
+
+	// Roll back
+	if err := registry.Activate("greeter", "1.0.0"); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	if err := registry.DiscoverPlugins(); err != nil {
+		t.Fatalf("DiscoverPlugins() error = %v", err)
+	}
+	if manifest, err := registry.GetManifest("greeter"); err != nil || manifest.Version != "1.0.0" {
+		t.Errorf("Expected the active version to be discovered, got %v (%v)", manifest, err)
+	}
+
+	if err := registry.Uninstall("greeter", "1.0.0"); err == nil || !strings.Contains(err.Error(), "is active") {
+		t.Errorf("Expected removing the active version to fail, got %v", err)
+	}
+	if err := registry.Uninstall("greeter", "1.1.0"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pluginDir, "greeter", "1.1.0")); !os.IsNotExist(err) {
+		t.Error("Expected version 1.1.0 to be removed")
+	}
+
+	if err := registry.Uninstall("greeter", ""); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := registry.InstalledPlugin("greeter"); err == nil {
+		t.Error("Expected greeter to be uninstalled")
+	}
+	if _, approved := registry.permissionManager.GetApprovedPermissions("greeter"); approved {
+		t.Error("Expected permissions to be revoked")
+	}
+}
+
+func TestRegistry_Install_URL(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "greeter.tgz")
+	data := writeTarGz(t, archive, greeterFiles("", installTestManifest("2.0.0")))
+	sum := sha256.Sum256(data)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/greeter.tgz":
+			_, _ = w.Write(data)
+		case "/greeter.tgz.sha256":
+			_, _ = w.Write([]byte(hex.EncodeToString(sum[:])))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	registry, _ := newInstallTestRegistry(t, &AutoApprover{})
+	result, err := registry.Install(context.Background(), server.URL+"/greeter.tgz", InstallOptions{})
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.Version.Source != server.URL+"/greeter.tgz" || result.Version.Publisher != "" {
+		t.Errorf("Unexpected install result: %+v", result.Version)
+	}
+}
+
+func TestRegistry_Install_URLTooLarge(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "greeter.tgz")
+	data := writeTarGz(t, archive, greeterFiles("", installTestManifest("2.0.0")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	limit := maxDownloadSize
+	maxDownloadSize = int64(len(data)) - 1
+	defer func() { maxDownloadSize = limit }()
+
+	registry, _ := newInstallTestRegistry(t, &AutoApprover{})
+	_, err := registry.Install(context.Background(), server.URL+"/greeter.tgz", InstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Expected a size error, got %v", err)
+	}
+}
+
+func TestRegistry_VerifyPlugin(t *testing.T) {
+	registry, pluginDir := newInstallTestRegistry(t, &AutoApprover{})
+
+	archive := filepath.Join(t.TempDir(), "greeter.zip")
+	writeZip(t, archive, greeterFiles("", installTestManifest("1.0.0")))
+	if _, err := registry.Install(context.Background(), archive, InstallOptions{}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	results, err := registry.VerifyPlugin("greeter")
+	if err != nil {
+		t.Fatalf("VerifyPlugin() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].OK() || !results[0].Active {
+		t.Errorf("Expected a clean install to verify, got %+v", results)
+	}
+
+	versionDir := filepath.Join(pluginDir, "greeter", "1.0.0")
+	if err := os.WriteFile(filepath.Join(versionDir, "greeter.sh"), []byte("#!/bin/sh\ncurl evil\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, "extra"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err = registry.VerifyPlugin("greeter")
+	if err != nil {
+		t.Fatalf("VerifyPlugin() error = %v", err)
+	}
+	if results[0].OK() || strings.Join(results[0].Modified, ",") != "greeter.sh" || strings.Join(results[0].Added, ",") != "extra" {
+		t.Errorf("Expected tampering to be detected, got %+v", results[0])
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	for _, key := range []string{pemKey, base64.StdEncoding.EncodeToString(publicKey)} {
+		parsed, err := ParsePublicKey(key)
+		if err != nil {
+			t.Fatalf("ParsePublicKey() error = %v", err)
+		}
+		if !parsed.Equal(publicKey) {
+			t.Error("Parsed key does not match")
+		}
+	}
+
+	if _, err := ParsePublicKey("dG9vIHNob3J0"); err == nil {
+		t.Error("Expected error for a short key")
+	}
+}
@@ -17,6 +17,8 @@ type PermissionManager struct {
 	store     *PermissionStore
 	mu        sync.RWMutex
 	approver  PermissionApprover
+	policy    *Policy
+	audit     *AuditLog
 }
 
 // PermissionApprover is an interface for requesting user approval.
@@ -51,6 +53,8 @@ func NewPermissionManager(configDir string, approver PermissionApprover) (*Permi
 	pm := &PermissionManager{
 		configDir: configDir,
 		approver:  approver,
+		policy:    &Policy{},
+		audit:     NewAuditLog(filepath.Join(configDir, AuditFile)),
 		store: &PermissionStore{
 			Plugins: make(map[string]*ApprovedPlugin),
 		},
@@ -67,28 +71,82 @@ func NewPermissionManager(configDir string, approver PermissionApprover) (*Permi
 	return pm, nil
 }
 
+// SetPolicy sets the organisation's plugin policy.
+func (pm *PermissionManager) SetPolicy(policy *Policy) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if policy == nil {
+		policy = &Policy{}
+	}
+	pm.policy = policy
+}
+
+// AuditLog returns the log of approvals, denials and revocations.
+func (pm *PermissionManager) AuditLog() *AuditLog {
+	return pm.audit
+}
+
 // CheckPermissions verifies that all required permissions are approved.
 // If not approved, requests user approval.
 func (pm *PermissionManager) CheckPermissions(pluginName string, permissions []Permission) error {
+	return pm.Authorize(PluginIdentity{Name: pluginName}, permissions)
+}
+
+// Authorize checks a plugin against the policy and verifies that all
+// required permissions are approved. If not approved, the policy's
+// pre-approval or the user approves them. Every decision is recorded in
+// the audit log.
+func (pm *PermissionManager) Authorize(id PluginIdentity, permissions []Permission) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
 	// Built-in plugins are trusted by default
-	if pm.isBuiltinPlugin(pluginName) {
+	if pm.isBuiltinPlugin(id.Name) {
 		return nil
 	}
 
-	approved, exists := pm.store.Plugins[pluginName]
+	if err := pm.policy.CheckPlugin(id); err != nil {
+		pm.record(AuditEntry{Action: AuditDeny, Actor: AuditActorPolicy, Reason: err.Error()}, id, nil)
+		return err
+	}
+	if blocked := pm.policy.Blocked(permissions); len(blocked) > 0 {
+		err := fmt.Errorf("permissions blocked by policy: %s", strings.Join(permissionStrings(blocked), ", "))
+		pm.record(AuditEntry{Action: AuditDeny, Actor: AuditActorPolicy, Reason: err.Error()}, id, blocked)
+		return err
+	}
+
+	approved, exists := pm.store.Plugins[id.Name]
 	if !exists {
 		// No permissions approved yet, request approval
-		return pm.requestApproval(pluginName, permissions)
+		return pm.requestApproval(id, permissions, "")
+	}
+
+	// Approvals are tied to the version they were given for
+	if pm.policy.ReapproveOnUpgrade() && id.Version != "" && approved.Version != "" && approved.Version != id.Version {
+		reason := fmt.Sprintf("version changed from %s", approved.Version)
+		delete(pm.store.Plugins, id.Name)
+		if err := pm.save(); err != nil {
+			return err
+		}
+		pm.audit.recordOrWarn(AuditEntry{
+			Action:      AuditRevoke,
+			Actor:       AuditActorPolicy,
+			Plugin:      id.Name,
+			Version:     approved.Version,
+			Permissions: approved.ApprovedPermissions,
+			Reason:      reason,
+		})
+		if len(permissions) == 0 {
+			return pm.grantPermissionsLocked(id.Name, nil, id.Version)
+		}
+		return pm.requestApproval(id, permissions, reason)
 	}
 
 	// Check if all required permissions are approved
 	missing := pm.findMissingPermissions(permissions, approved.ApprovedPermissions)
 	if len(missing) > 0 {
 		// Request approval for missing permissions
-		return pm.requestApproval(pluginName, missing)
+		return pm.requestApproval(id, missing, "")
 	}
 
 	// Update last used time
@@ -105,7 +163,20 @@ func (pm *PermissionManager) CheckPermissions(pluginName string, permissions []P
 func (pm *PermissionManager) GrantPermissions(pluginName string, permissions []Permission, version string) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
-	return pm.grantPermissionsLocked(pluginName, permissions, version)
+
+	var added []Permission
+	if approved, exists := pm.store.Plugins[pluginName]; exists {
+		added = pm.findMissingPermissions(permissions, approved.ApprovedPermissions)
+	} else {
+		added = permissions
+	}
+	if err := pm.grantPermissionsLocked(pluginName, permissions, version); err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		pm.record(AuditEntry{Action: AuditApprove, Actor: AuditActorUser}, PluginIdentity{Name: pluginName, Version: version}, added)
+	}
+	return nil
 }
 
 // grantPermissionsLocked grants permissions without acquiring lock (caller must hold lock).
@@ -139,8 +210,23 @@ func (pm *PermissionManager) RevokePermissions(pluginName string) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	approved, exists := pm.store.Plugins[pluginName]
+	if !exists {
+		return nil
+	}
 	delete(pm.store.Plugins, pluginName)
-	return pm.save()
+	if err := pm.save(); err != nil {
+		return err
+	}
+
+	pm.audit.recordOrWarn(AuditEntry{
+		Action:      AuditRevoke,
+		Actor:       AuditActorUser,
+		Plugin:      pluginName,
+		Version:     approved.Version,
+		Permissions: approved.ApprovedPermissions,
+	})
+	return nil
 }
 
 // ListApprovedPlugins returns all plugins with approved permissions.
@@ -169,23 +255,55 @@ func (pm *PermissionManager) GetApprovedPermissions(pluginName string) ([]string
 	return approved.ApprovedPermissions, true
 }
 
-// requestApproval requests user approval for permissions (caller must hold lock).
-func (pm *PermissionManager) requestApproval(pluginName string, permissions []Permission) error {
+// requestApproval grants permissions the policy pre-approves, and asks
+// the user for the rest (caller must hold lock). reason explains why
+// approval is asked for again, if it is.
+func (pm *PermissionManager) requestApproval(id PluginIdentity, permissions []Permission, reason string) error {
+	// Nothing to ask for
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	if pm.policy.PreApproved(id) {
+		if err := pm.grantPermissionsLocked(id.Name, permissions, id.Version); err != nil {
+			return err
+		}
+		pm.record(AuditEntry{Action: AuditApprove, Actor: AuditActorPolicy, Reason: "pre-approved by policy"}, id, permissions)
+		return nil
+	}
+
 	if pm.approver == nil {
 		return fmt.Errorf("no permission approver configured")
 	}
 
-	approved, err := pm.approver.RequestApproval(pluginName, permissions)
+	approved, err := pm.approver.RequestApproval(id.Name, permissions)
 	if err != nil {
 		return fmt.Errorf("failed to request approval: %w", err)
 	}
 
 	if !approved {
+		pm.record(AuditEntry{Action: AuditDeny, Actor: AuditActorUser, Reason: reason}, id, permissions)
 		return fmt.Errorf("permission denied by user")
 	}
 
 	// Store approved permissions (use locked version since we already hold lock)
-	return pm.grantPermissionsLocked(pluginName, permissions, "")
+	if err := pm.grantPermissionsLocked(id.Name, permissions, id.Version); err != nil {
+		return err
+	}
+	pm.record(AuditEntry{Action: AuditApprove, Actor: AuditActorUser, Reason: reason}, id, permissions)
+	return nil
+}
+
+// record fills in the plugin and permissions of an entry and appends it
+// to the audit log.
+func (pm *PermissionManager) record(entry AuditEntry, id PluginIdentity, permissions []Permission) {
+	entry.Plugin = id.Name
+	entry.Version = id.Version
+	entry.Publisher = id.Publisher
+	if len(permissions) > 0 {
+		entry.Permissions = permissionStrings(permissions)
+	}
+	pm.audit.recordOrWarn(entry)
 }
 
 // findMissingPermissions returns permissions that are not approved.
@@ -290,6 +408,8 @@ func ValidatePermission(permStr string) error {
 		PermissionWriteFile,
 		PermissionNetwork,
 		PermissionCredential,
+		PermissionReadState,
+		PermissionWriteState,
 	}
 
 	// Check if permission string starts with a valid type
@@ -314,6 +434,8 @@ func ValidatePermission(permStr string) error {
 		PermissionReadFile,
 		PermissionWriteFile,
 		PermissionNetwork,
+		PermissionReadState,
+		PermissionWriteState,
 	}
 
 	needsResource := false
@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/CliForge/cliforge/pkg/cli"
 )
 
 // Plugin is the core interface that all plugins must implement.
@@ -45,10 +47,24 @@ const (
 	// PluginTypeBinary is an external executable plugin.
 	PluginTypeBinary PluginType = "binary"
 
-	// PluginTypeWASM is a WebAssembly plugin (future).
+	// PluginTypeWASM is a WebAssembly module run in an in-process sandbox.
 	PluginTypeWASM PluginType = "wasm"
 )
 
+// PluginProtocol is the protocol a binary plugin speaks.
+type PluginProtocol string
+
+const (
+	// PluginProtocolStdio starts a process for every request, writes the
+	// request to its stdin and reads the response once it exits.
+	PluginProtocolStdio PluginProtocol = "stdio"
+
+	// PluginProtocolSession keeps processes running and exchanges
+	// newline-delimited JSON-RPC messages with them. Plugins can serve
+	// many requests, report logs and progress, and call host methods.
+	PluginProtocolSession PluginProtocol = "session"
+)
+
 // PluginManifest describes a plugin's metadata and requirements.
 type PluginManifest struct {
 	// Name is the unique identifier for the plugin.
@@ -69,12 +85,28 @@ type PluginManifest struct {
 	// Executable is the path to the executable (for binary plugins).
 	Executable string `yaml:"executable,omitempty" json:"executable,omitempty"`
 
+	// Protocol is how the host talks to the executable (for binary
+	// plugins). Defaults to PluginProtocolStdio.
+	Protocol PluginProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+
+	// Module is the path to the .wasm file (for WASM plugins). Defaults to
+	// plugin.wasm next to the manifest.
+	Module string `yaml:"module,omitempty" json:"module,omitempty"`
+
 	// Entrypoint is the entry function name (for WASM plugins).
 	Entrypoint string `yaml:"entrypoint,omitempty" json:"entrypoint,omitempty"`
 
 	// Permissions lists required permissions.
 	Permissions []Permission `yaml:"permissions" json:"permissions"`
 
+	// Commands lists the commands the plugin adds to the CLI. They are
+	// executed with PluginInput.Command set to the command name.
+	Commands []cli.PluginCommand `yaml:"commands,omitempty" json:"commands,omitempty"`
+
+	// Hooks lists the points in API calls where the plugin runs. Hooks
+	// are executed with PluginInput.Command set to HookCommand.
+	Hooks []HookSpec `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+
 	// Metadata contains additional plugin-specific metadata.
 	Metadata map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
 }
@@ -115,6 +147,12 @@ const (
 
 	// PermissionCredential allows accessing stored credentials.
 	PermissionCredential PermissionType = "credential"
+
+	// PermissionReadState allows reading plugin state.
+	PermissionReadState PermissionType = "read:state"
+
+	// PermissionWriteState allows writing plugin state.
+	PermissionWriteState PermissionType = "write:state"
 )
 
 // String returns the full permission string (type:resource).
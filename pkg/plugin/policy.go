@@ -0,0 +1,247 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/update"
+)
+
+// PluginIdentity identifies the plugin a permission check is for. Version
+// and Publisher are empty when unknown.
+type PluginIdentity struct {
+	Name      string
+	Version   string
+	Publisher string
+}
+
+// String returns the name and, when known, the version of the plugin.
+func (id PluginIdentity) String() string {
+	if id.Version == "" {
+		return id.Name
+	}
+	return id.Name + " " + id.Version
+}
+
+// Policy is the organisation's plugin policy from the embedded
+// configuration. It decides which plugins may run, which permissions can
+// never be granted, and which plugins are approved without asking.
+type Policy struct {
+	allow              []policyRule
+	deny               []policyRule
+	blocked            []string
+	preApproved        []policyRule
+	reapproveOnUpgrade bool
+}
+
+// policyRule is a cli.PluginRule with its version range parsed.
+type policyRule struct {
+	rule     cli.PluginRule
+	versions VersionRange
+}
+
+// NewPolicy creates a policy from the plugins section of the embedded
+// configuration. Without a policy, everything is allowed.
+func NewPolicy(plugins *cli.Plugins) (*Policy, error) {
+	policy := &Policy{}
+	if plugins == nil || plugins.Policy == nil {
+		return policy, nil
+	}
+	config := plugins.Policy
+
+	var err error
+	if policy.allow, err = parsePolicyRules("allow", config.Allow); err != nil {
+		return nil, err
+	}
+	if policy.deny, err = parsePolicyRules("deny", config.Deny); err != nil {
+		return nil, err
+	}
+	if policy.preApproved, err = parsePolicyRules("pre_approved", config.PreApproved); err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range config.BlockedPermissions {
+		if err := ValidatePermission(pattern); err != nil && !isPermissionType(pattern) {
+			return nil, fmt.Errorf("invalid blocked permission '%s': %w", pattern, err)
+		}
+		policy.blocked = append(policy.blocked, pattern)
+	}
+	policy.reapproveOnUpgrade = config.ReapproveOnUpgrade
+
+	return policy, nil
+}
+
+// parsePolicyRules parses the version ranges of rules.
+func parsePolicyRules(section string, rules []cli.PluginRule) ([]policyRule, error) {
+	parsed := make([]policyRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" && rule.Publisher == "" && rule.Versions == "" {
+			return nil, fmt.Errorf("%s[%d]: rule matches every plugin; set name, publisher or versions", section, i)
+		}
+		versions, err := ParseVersionRange(rule.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", section, i, err)
+		}
+		parsed = append(parsed, policyRule{rule: rule, versions: versions})
+	}
+	return parsed, nil
+}
+
+// isPermissionType reports whether s is a permission type without a
+// resource, such as "credential".
+func isPermissionType(s string) bool {
+	switch PermissionType(s) {
+	case PermissionExecute, PermissionReadEnv, PermissionWriteEnv, PermissionReadFile, PermissionWriteFile,
+		PermissionNetwork, PermissionCredential, PermissionReadState, PermissionWriteState:
+		return true
+	}
+	return false
+}
+
+// matches reports whether the rule matches a plugin. A rule with a
+// publisher never matches unsigned plugins, and a rule with versions
+// never matches a plugin of unknown version.
+func (r policyRule) matches(id PluginIdentity) bool {
+	if r.rule.Name != "" && !MatchPermission(r.rule.Name, id.Name) {
+		return false
+	}
+	if r.rule.Publisher != "" && r.rule.Publisher != id.Publisher {
+		return false
+	}
+	if r.rule.Versions != "" && (id.Version == "" || !r.versions.Contains(id.Version)) {
+		return false
+	}
+	return true
+}
+
+// CheckPlugin returns an error if the policy does not let the plugin run.
+func (p *Policy) CheckPlugin(id PluginIdentity) error {
+	for _, rule := range p.deny {
+		if rule.matches(id) {
+			return fmt.Errorf("plugin %s is denied by policy", id)
+		}
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	for _, rule := range p.allow {
+		if rule.matches(id) {
+			return nil
+		}
+	}
+	return fmt.Errorf("plugin %s is not on the policy's allow list", id)
+}
+
+// Blocked returns the permissions the policy never grants.
+func (p *Policy) Blocked(permissions []Permission) []Permission {
+	var blocked []Permission
+	for _, perm := range permissions {
+		for _, pattern := range p.blocked {
+			if matchBlockedPermission(pattern, perm) {
+				blocked = append(blocked, perm)
+				break
+			}
+		}
+	}
+	return blocked
+}
+
+// matchBlockedPermission matches a permission against a blocked pattern.
+// A bare type such as "network" blocks the type whatever its resource.
+func matchBlockedPermission(pattern string, perm Permission) bool {
+	if pattern == string(perm.Type) {
+		return true
+	}
+	return MatchPermission(pattern, perm.String())
+}
+
+// PreApproved reports whether the policy grants the plugin's permissions
+// without asking.
+func (p *Policy) PreApproved(id PluginIdentity) bool {
+	for _, rule := range p.preApproved {
+		if rule.matches(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReapproveOnUpgrade reports whether approvals are tied to the version
+// they were given for.
+func (p *Policy) ReapproveOnUpgrade() bool {
+	return p.reapproveOnUpgrade
+}
+
+// VersionRange is a set of version constraints that must all hold, such
+// as ">=1.2.0 <2.0.0". A constraint without an operator matches the
+// version exactly. The empty range contains every version.
+type VersionRange []versionConstraint
+
+// versionConstraint is an operator and the version it compares against.
+type versionConstraint struct {
+	op      string
+	version *update.Version
+}
+
+// versionOperators are checked longest first so ">=" is not read as ">".
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ParseVersionRange parses a space or comma separated list of
+// constraints.
+func ParseVersionRange(s string) (VersionRange, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+
+	var vr VersionRange
+	for _, field := range fields {
+		op := "="
+		for _, candidate := range versionOperators {
+			if strings.HasPrefix(field, candidate) {
+				op = candidate
+				field = strings.TrimSpace(field[len(candidate):])
+				break
+			}
+		}
+		version, err := update.ParseVersion(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range '%s': %w", s, err)
+		}
+		vr = append(vr, versionConstraint{op: op, version: version})
+	}
+	return vr, nil
+}
+
+// Contains reports whether version satisfies every constraint. Versions
+// that do not parse are only in the empty range.
+func (vr VersionRange) Contains(version string) bool {
+	if len(vr) == 0 {
+		return true
+	}
+	v, err := update.ParseVersion(version)
+	if err != nil {
+		return false
+	}
+
+	for _, c := range vr {
+		cmp := v.Compare(c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "!=":
+			ok = cmp != 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+package plugin
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+)
+
+func TestVersionRange(t *testing.T) {
+	tests := []struct {
+		rangeStr string
+		version  string
+		want     bool
+	}{
+		{"", "1.0.0", true},
+		{"1.2.0", "1.2.0", true},
+		{"1.2.0", "1.2.1", false},
+		{">=1.2.0 <2.0.0", "1.9.9", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0, <2.0.0", "1.1.0", false},
+		{"!=1.3.0", "1.3.0", false},
+		{">1.0.0", "not-a-version", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rangeStr+" "+tt.version, func(t *testing.T) {
+			vr, err := ParseVersionRange(tt.rangeStr)
+			if err != nil {
+				t.Fatalf("ParseVersionRange() error = %v", err)
+			}
+			if got := vr.Contains(tt.version); got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseVersionRange(">=one"); err == nil {
+		t.Error("Expected an error for an invalid version")
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *cli.PluginPolicy
+		wantErr string
+	}{
+		{name: "no policy"},
+		{
+			name: "valid",
+			policy: &cli.PluginPolicy{
+				Allow:              []cli.PluginRule{{Publisher: "acme"}},
+				Deny:               []cli.PluginRule{{Name: "legacy-*", Versions: "<2.0.0"}},
+				BlockedPermissions: []string{"execute:*", "credential", "network"},
+			},
+		},
+		{
+			name:    "rule without fields",
+			policy:  &cli.PluginPolicy{Deny: []cli.PluginRule{{}}},
+			wantErr: "deny[0]: rule matches every plugin",
+		},
+		{
+			name:    "invalid range",
+			policy:  &cli.PluginPolicy{Allow: []cli.PluginRule{{Name: "x", Versions: ">=latest"}}},
+			wantErr: "allow[0]: invalid version range",
+		},
+		{
+			name:    "invalid permission",
+			policy:  &cli.PluginPolicy{BlockedPermissions: []string{"launch:*"}},
+			wantErr: "invalid blocked permission 'launch:*'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(&cli.Plugins{Policy: tt.policy})
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("NewPolicy() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_CheckPlugin(t *testing.T) {
+	policy, err := NewPolicy(&cli.Plugins{Policy: &cli.PluginPolicy{
+		Allow: []cli.PluginRule{{Publisher: "acme"}, {Name: "internal-*"}},
+		Deny:  []cli.PluginRule{{Name: "acme-legacy"}, {Publisher: "acme", Versions: "<1.0.0"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id      PluginIdentity
+		wantErr string
+	}{
+		{id: PluginIdentity{Name: "deploy", Version: "1.2.0", Publisher: "acme"}},
+		{id: PluginIdentity{Name: "internal-tools", Version: "0.1.0"}},
+		{id: PluginIdentity{Name: "acme-legacy", Version: "3.0.0", Publisher: "acme"}, wantErr: "denied by policy"},
+		{id: PluginIdentity{Name: "deploy", Version: "0.9.0", Publisher: "acme"}, wantErr: "denied by policy"},
+		{id: PluginIdentity{Name: "deploy", Version: "1.2.0"}, wantErr: "not on the policy's allow list"},
+		{id: PluginIdentity{Name: "deploy", Version: "1.2.0", Publisher: "other"}, wantErr: "not on the policy's allow list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String()+" "+tt.id.Publisher, func(t *testing.T) {
+			err := policy.CheckPlugin(tt.id)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CheckPlugin() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CheckPlugin() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_Blocked(t *testing.T) {
+	policy, err := NewPolicy(&cli.Plugins{Policy: &cli.PluginPolicy{
+		BlockedPermissions: []string{"execute:*", "credential", "network"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := policy.Blocked([]Permission{
+		{Type: PermissionExecute, Resource: "kubectl"},
+		{Type: PermissionCredential},
+		{Type: PermissionNetwork, Resource: "api.example.com"},
+		{Type: PermissionReadEnv, Resource: "HOME"},
+	})
+	if got := strings.Join(permissionStrings(blocked), ","); got != "execute:kubectl,credential,network:api.example.com" {
+		t.Errorf("Blocked() = %s", got)
+	}
+}
+
+// countingApprover answers approval requests and counts them.
+type countingApprover struct {
+	approve bool
+	calls   int
+}
+
+func (a *countingApprover) RequestApproval(pluginName string, permissions []Permission) (bool, error) {
+	a.calls++
+	return a.approve, nil
+}
+
+func TestPermissionManager_Policy(t *testing.T) {
+	dir := t.TempDir()
+	approver := &countingApprover{approve: true}
+	pm, err := NewPermissionManager(dir, approver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicy(&cli.Plugins{Policy: &cli.PluginPolicy{
+		Deny:               []cli.PluginRule{{Name: "banned"}},
+		BlockedPermissions: []string{"credential"},
+		PreApproved:        []cli.PluginRule{{Name: "deploy", Publisher: "acme"}},
+		ReapproveOnUpgrade: true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm.SetPolicy(policy)
+
+	readHome := []Permission{{Type: PermissionReadEnv, Resource: "HOME"}}
+
+	if err := pm.Authorize(PluginIdentity{Name: "banned", Version: "1.0.0"}, readHome); err == nil {
+		t.Error("Expected denied plugin to be refused")
+	}
+	if err := pm.Authorize(PluginIdentity{Name: "vault", Version: "1.0.0"}, []Permission{{Type: PermissionCredential}}); err == nil ||
+		!strings.Contains(err.Error(), "blocked by policy: credential") {
+		t.Errorf("Expected blocked permission error, got %v", err)
+	}
+
+	// Pre-approved plugins are never prompted for
+	if err := pm.Authorize(PluginIdentity{Name: "deploy", Version: "1.0.0", Publisher: "acme"}, readHome); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if approver.calls != 0 {
+		t.Errorf("Expected no prompt for a pre-approved plugin, got %d", approver.calls)
+	}
+
+	// An unsigned copy of a pre-approved plugin is asked for
+	if err := pm.Authorize(PluginIdentity{Name: "greeter", Version: "1.0.0"}, readHome); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if err := pm.Authorize(PluginIdentity{Name: "greeter", Version: "1.0.0"}, readHome); err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if approver.calls != 1 {
+		t.Errorf("Expected one prompt, got %d", approver.calls)
+	}
+
+	// A new version is approved again
+	approver.approve = false
+	if err := pm.Authorize(PluginIdentity{Name: "greeter", Version: "1.1.0"}, readHome); err == nil {
+		t.Error("Expected the new version to need approval")
+	}
+	if approver.calls != 2 {
+		t.Errorf("Expected a prompt for the new version, got %d", approver.calls)
+	}
+
+	// The declined upgrade leaves no approval on disk
+	reloaded, err := NewPermissionManager(dir, approver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, approved := reloaded.GetApprovedPermissions("greeter"); approved {
+		t.Error("Expected the old version's approval to be removed from disk")
+	}
+
+	if err := pm.RevokePermissions("deploy"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := NewAuditLog(filepath.Join(dir, AuditFile)).Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	want := []struct {
+		action AuditAction
+		actor  AuditActor
+		plugin string
+	}{
+		{AuditDeny, AuditActorPolicy, "banned"},
+		{AuditDeny, AuditActorPolicy, "vault"},
+		{AuditApprove, AuditActorPolicy, "deploy"},
+		{AuditApprove, AuditActorUser, "greeter"},
+		{AuditRevoke, AuditActorPolicy, "greeter"},
+		{AuditDeny, AuditActorUser, "greeter"},
+		{AuditRevoke, AuditActorUser, "deploy"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d audit entries, got %+v", len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i].Action != w.action || entries[i].Actor != w.actor || entries[i].Plugin != w.plugin {
+			t.Errorf("entry %d = %+v, want %s by %s for %s", i, entries[i], w.action, w.actor, w.plugin)
+		}
+	}
+	if entries[4].Reason != "version changed from 1.0.0" || entries[4].Version != "1.0.0" {
+		t.Errorf("Expected the old approval to be revoked, got %+v", entries[4])
+	}
+	if entries[5].Reason != "version changed from 1.0.0" {
+		t.Errorf("Expected the reason for re-approval, got %q", entries[5].Reason)
+	}
+	if entries[0].Time.IsZero() || entries[3].Version != "1.0.0" {
+		t.Errorf("Expected time and version to be recorded, got %+v", entries[3])
+	}
+}
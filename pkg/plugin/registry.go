@@ -2,11 +2,14 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
+	"github.com/CliForge/cliforge/pkg/cli"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,6 +20,13 @@ type Registry struct {
 	mu                sync.RWMutex
 	pluginDir         string
 	permissionManager *PermissionManager
+	wasmConfig        WASMConfig
+	sandboxConfig     SandboxConfig
+	hostServices      *HostServices
+	trustPolicy       TrustPolicy
+	// publishers maps installed plugins to the publisher that signed
+	// their active version.
+	publishers map[string]string
 }
 
 // NewRegistry creates a new plugin registry.
@@ -24,11 +34,54 @@ func NewRegistry(pluginDir string, permissionManager *PermissionManager) *Regist
 	return &Registry{
 		plugins:           make(map[string]Plugin),
 		manifests:         make(map[string]*PluginManifest),
+		publishers:        make(map[string]string),
 		pluginDir:         pluginDir,
 		permissionManager: permissionManager,
+		wasmConfig:        DefaultWASMConfig(),
+		sandboxConfig:     DefaultSandboxConfig(),
 	}
 }
 
+// SetHostServices sets the services session plugins discovered after the
+// call can use.
+func (r *Registry) SetHostServices(host *HostServices) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hostServices = host
+}
+
+// Close releases the resources of all plugins, such as the processes of
+// session plugins.
+func (r *Registry) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for name, plugin := range r.plugins {
+		if closer, ok := plugin.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("plugin '%s': %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetWASMConfig sets the limits of WASM plugins discovered after the call.
+func (r *Registry) SetWASMConfig(config WASMConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wasmConfig = config
+}
+
+// SetSandboxConfig sets the sandbox of binary plugins discovered after the
+// call.
+func (r *Registry) SetSandboxConfig(config SandboxConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sandboxConfig = config
+}
+
 // Register registers a plugin with the registry.
 // Built-in plugins should be registered at startup.
 func (r *Registry) Register(plugin Plugin) error {
@@ -129,9 +182,12 @@ func (r *Registry) Execute(ctx context.Context, name string, input *PluginInput)
 		return nil, err
 	}
 
-	// Check permissions
+	// Check the policy and permissions
 	if r.permissionManager != nil {
-		if err := r.permissionManager.CheckPermissions(name, manifest.Permissions); err != nil {
+		r.mu.RLock()
+		id := PluginIdentity{Name: name, Version: manifest.Version, Publisher: r.publishers[name]}
+		r.mu.RUnlock()
+		if err := r.permissionManager.Authorize(id, manifest.Permissions); err != nil {
 			return nil, NewPluginError(name, "permission denied", err)
 		}
 	}
@@ -139,6 +195,10 @@ func (r *Registry) Execute(ctx context.Context, name string, input *PluginInput)
 	// Execute the plugin
 	output, err := plugin.Execute(ctx, input)
 	if err != nil {
+		var pluginErr *PluginError
+		if errors.As(err, &pluginErr) {
+			return nil, err
+		}
 		return nil, NewPluginError(name, "execution failed", err)
 	}
 
@@ -163,13 +223,39 @@ func (r *Registry) DiscoverPlugins() error {
 			return err
 		}
 
-		// Skip directories
 		if info.IsDir() {
+			if path == r.pluginDir {
+				return nil
+			}
+			// Skip install staging directories
+			if strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			// Installed plugins keep versions side by side; only the
+			// active one is loaded
+			record, err := readInstallRecord(path)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				if record.Active != "" {
+					if err := r.loadExternalPlugin(filepath.Join(path, record.Active)); err != nil {
+						return err
+					}
+					if active := record.ActiveVersion(); active != nil && active.Publisher != "" {
+						r.mu.Lock()
+						r.publishers[record.Name] = active.Publisher
+						r.mu.Unlock()
+					}
+				}
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
 		// Look for manifest files
-		if filepath.Base(path) == "plugin-manifest.yaml" {
+		if filepath.Base(path) == ManifestFile {
 			return r.loadExternalPlugin(filepath.Dir(path))
 		}
 
@@ -179,7 +265,7 @@ func (r *Registry) DiscoverPlugins() error {
 
 // loadExternalPlugin loads an external plugin from a directory.
 func (r *Registry) loadExternalPlugin(dir string) error {
-	manifestPath := filepath.Join(dir, "plugin-manifest.yaml")
+	manifestPath := filepath.Join(dir, ManifestFile)
 
 	// Read manifest
 	data, err := os.ReadFile(manifestPath)
@@ -206,18 +292,46 @@ func (r *Registry) loadExternalPlugin(dir string) error {
 		if !filepath.IsAbs(execPath) {
 			execPath = filepath.Join(dir, execPath)
 		}
-		plugin = NewBinaryPlugin(manifest, execPath)
+		binary := NewBinaryPlugin(manifest, execPath)
+		r.mu.RLock()
+		binary.SetHostServices(r.hostServices)
+		sandboxConfig := r.sandboxConfig
+		r.mu.RUnlock()
+		if !sandboxConfig.Disabled {
+			sandbox, err := NewSandbox(manifest, dir, sandboxConfig)
+			if err != nil {
+				return fmt.Errorf("failed to create sandbox: %w", err)
+			}
+			binary.SetSandbox(sandbox)
+		}
+		plugin = binary
 
 	case PluginTypeWASM:
-		// WASM plugins are not yet implemented
-		return fmt.Errorf("WASM plugins are not yet supported")
+		// Resolve module path
+		modulePath := manifest.Module
+		if modulePath == "" {
+			modulePath = DefaultWASMModule
+		}
+		if !filepath.IsAbs(modulePath) {
+			modulePath = filepath.Join(dir, modulePath)
+		}
+		r.mu.RLock()
+		config := r.wasmConfig
+		r.mu.RUnlock()
+		plugin = NewWASMPlugin(manifest, modulePath, config)
 
 	default:
 		return fmt.Errorf("unsupported plugin type: %s", manifest.Type)
 	}
 
 	// Register the plugin
-	return r.Register(plugin)
+	if err := r.Register(plugin); err != nil {
+		if closer, ok := plugin.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+		return err
+	}
+	return nil
 }
 
 // validateManifest validates a plugin manifest.
@@ -241,12 +355,31 @@ func (r *Registry) validateManifest(manifest *PluginManifest) error {
 		}
 	}
 
+	// Validate commands
+	for _, command := range manifest.Commands {
+		if err := validateCommand(command); err != nil {
+			return fmt.Errorf("invalid command '%s': %w", command.Name, err)
+		}
+	}
+
+	// Validate hooks
+	for _, hook := range manifest.Hooks {
+		if !isHookPoint(hook.Point) {
+			return fmt.Errorf("unknown hook point: %s", hook.Point)
+		}
+	}
+
 	// Type-specific validation
 	switch manifest.Type {
 	case PluginTypeBinary:
 		if manifest.Executable == "" {
 			return fmt.Errorf("executable is required for binary plugins")
 		}
+		switch manifest.Protocol {
+		case "", PluginProtocolStdio, PluginProtocolSession:
+		default:
+			return fmt.Errorf("unknown protocol: %s", manifest.Protocol)
+		}
 
 	case PluginTypeWASM:
 		if manifest.Entrypoint == "" {
@@ -263,6 +396,29 @@ func (r *Registry) validateManifest(manifest *PluginManifest) error {
 	return nil
 }
 
+// validateCommand validates a command declared in a plugin manifest.
+func validateCommand(command cli.PluginCommand) error {
+	if command.Name == "" || strings.ContainsAny(command.Name, " \t") {
+		return fmt.Errorf("name must be a single word")
+	}
+
+	for _, flag := range command.Flags {
+		if flag.Name == "" {
+			return fmt.Errorf("flag name is required")
+		}
+		if len(flag.Shorthand) > 1 {
+			return fmt.Errorf("flag '%s': shorthand must be a single character", flag.Name)
+		}
+		switch flag.Type {
+		case "", "string", "int", "float", "bool", "stringArray":
+		default:
+			return fmt.Errorf("flag '%s': unknown type: %s", flag.Name, flag.Type)
+		}
+	}
+
+	return nil
+}
+
 // GetPluginInfo returns detailed information about a plugin.
 func (r *Registry) GetPluginInfo(name string) (*PluginInfo, error) {
 	plugin, err := r.Get(name)
@@ -320,6 +476,11 @@ func (r *Registry) ListPluginInfo() ([]*PluginInfo, error) {
 type BinaryPlugin struct {
 	manifest PluginManifest
 	execPath string
+	host     *HostServices
+	sandbox  *Sandbox
+
+	mu   sync.Mutex
+	pool *SessionPool
 }
 
 // NewBinaryPlugin creates a new binary plugin.
@@ -330,11 +491,52 @@ func NewBinaryPlugin(manifest PluginManifest, execPath string) *BinaryPlugin {
 	}
 }
 
-// Execute runs the binary plugin.
+// SetHostServices sets the services session plugins can call back into.
+// It must be called before the first execution.
+func (p *BinaryPlugin) SetHostServices(host *HostServices) {
+	p.host = host
+}
+
+// SetSandbox sets the sandbox the plugin's processes run in. Without one
+// they run with the user's full privileges. It must be called before the
+// first execution.
+func (p *BinaryPlugin) SetSandbox(sandbox *Sandbox) {
+	p.sandbox = sandbox
+}
+
+// Execute runs the binary plugin. Session plugins run on a pool of
+// long-lived processes; others start a process per request.
 func (p *BinaryPlugin) Execute(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
-	// Binary plugin execution is handled by the executor
-	// This is a placeholder that will be implemented in executor.go
-	return nil, fmt.Errorf("binary plugin execution not yet implemented")
+	if p.manifest.Protocol != PluginProtocolSession {
+		output, err := NewExecutor(0, 0).ExecuteSandboxed(ctx, p.sandbox, p.execPath, input)
+		if err != nil {
+			return nil, executeError(p.manifest.Name, err)
+		}
+		return output, nil
+	}
+
+	p.mu.Lock()
+	if p.pool == nil {
+		p.pool = NewSessionPool(p.manifest, p.execPath, p.host, DefaultSessionPoolSize)
+		p.pool.sandbox = p.sandbox
+	}
+	pool := p.pool
+	p.mu.Unlock()
+
+	return pool.Execute(ctx, input)
+}
+
+// Close shuts down the processes of a session plugin.
+func (p *BinaryPlugin) Close() error {
+	p.mu.Lock()
+	pool := p.pool
+	p.pool = nil
+	p.mu.Unlock()
+
+	if pool == nil {
+		return nil
+	}
+	return pool.Close()
 }
 
 // Validate checks if the binary plugin is valid.
@@ -0,0 +1,192 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SandboxConfig configures the operating system sandbox binary plugins
+// run in.
+type SandboxConfig struct {
+	// Disabled runs plugins with the user's full privileges.
+	Disabled bool
+
+	// CPUTime is the most CPU time a plugin process may use. Zero means
+	// no limit.
+	CPUTime time.Duration
+
+	// MaxMemory is the most memory in bytes a plugin process may allocate.
+	// Zero means no limit.
+	MaxMemory uint64
+
+	// Warnings receives a warning for each restriction the system cannot
+	// enforce. Defaults to os.Stderr.
+	Warnings io.Writer
+}
+
+// DefaultSandboxConfig returns the default sandbox configuration.
+func DefaultSandboxConfig() SandboxConfig {
+	return SandboxConfig{
+		CPUTime:   5 * time.Minute,
+		MaxMemory: 1 << 30,
+	}
+}
+
+// systemDirs are readable and executable in every sandbox, so plugins can
+// load shared libraries and run interpreters.
+var systemDirs = []string{"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64", "/etc"}
+
+// Sandbox restricts a plugin process to what its manifest's permissions
+// grant:
+//   - the filesystem is limited to system directories (read-only), the
+//     plugin's own directory (read-only), read:file and write:file
+//     resources, and a private temporary directory;
+//   - sockets other than Unix sockets are refused unless network is
+//     granted;
+//   - CPU time and memory are limited;
+//   - the environment holds only read:env variables, the variables the
+//     host passes in, and TMPDIR.
+//
+// On Linux the filesystem is restricted with Landlock and sockets with a
+// seccomp filter. Where the kernel lacks a feature the plugin still runs,
+// and a warning says which restriction is not enforced.
+type Sandbox struct {
+	name     string
+	execDirs []string
+	network  bool
+	env      []string
+	config   SandboxConfig
+
+	// readPaths and writePaths are the granted files and directories:
+	// single files are granted on their own, not the directory holding
+	// them. writeFiles are the single files among writePaths.
+	readPaths  []string
+	writePaths []string
+	writeFiles []string
+
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// NewSandbox creates the sandbox of a plugin installed in dir. dir may be
+// empty for plugins that are not installed.
+func NewSandbox(manifest PluginManifest, dir string, config SandboxConfig) (*Sandbox, error) {
+	if config.Warnings == nil {
+		config.Warnings = os.Stderr
+	}
+
+	s := &Sandbox{
+		name:   manifest.Name,
+		config: config,
+		warned: make(map[string]bool),
+	}
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve plugin directory: %w", err)
+		}
+		s.execDirs = append(s.execDirs, abs)
+	}
+
+	for _, perm := range manifest.Permissions {
+		switch perm.Type {
+		case PermissionReadFile, PermissionWriteFile:
+			path, isDir, err := permissionPath(perm.Resource)
+			if err != nil {
+				return nil, fmt.Errorf("invalid permission %s: %w", perm.String(), err)
+			}
+			if perm.Type == PermissionWriteFile {
+				s.writePaths = appendUnique(s.writePaths, path)
+				if !isDir {
+					s.writeFiles = appendUnique(s.writeFiles, path)
+				}
+			} else {
+				s.readPaths = appendUnique(s.readPaths, path)
+			}
+
+		case PermissionReadEnv:
+			s.env = appendUnique(s.env, perm.Resource)
+
+		case PermissionNetwork:
+			s.network = true
+
+		default:
+			// execute is covered by the system directories; write:env and
+			// credential are enforced by the host, not the kernel.
+		}
+	}
+
+	return s, nil
+}
+
+// Start starts cmd inside the sandbox. env holds variables the host passes
+// to the plugin in addition to the approved read:env ones. The returned
+// cleanup function removes the plugin's temporary directory and must be
+// called once the process has exited.
+func (s *Sandbox) Start(cmd *exec.Cmd, env map[string]string) (cleanup func(), err error) {
+	if s.config.Disabled {
+		cmd.Env = os.Environ()
+		for key, value := range env {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return func() {}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "cliforge-plugin-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(tmpDir) }
+
+	cmd.Env = s.environ(os.Environ(), env, tmpDir)
+	if err := s.start(cmd, tmpDir); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
+
+// environ returns the environment of the plugin: host variables matching a
+// read:env pattern, overridden by env, plus TMPDIR.
+func (s *Sandbox) environ(host []string, env map[string]string, tmpDir string) []string {
+	caps := &wasmCapabilities{env: s.env}
+	merged := make(map[string]string, len(env)+1)
+	for key, value := range env {
+		merged[key] = value
+	}
+	merged["TMPDIR"] = tmpDir
+
+	kvs := caps.environ(host, merged)
+	result := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		result = append(result, kv[0]+"="+kv[1])
+	}
+	return result
+}
+
+// warn prints a warning about an unenforced restriction, once per feature.
+func (s *Sandbox) warn(feature string, format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warned[feature] {
+		return
+	}
+	s.warned[feature] = true
+	_, _ = fmt.Fprintf(s.config.Warnings, "Warning: plugin '%s' sandbox: %s\n", s.name, fmt.Sprintf(format, args...))
+}
+
+// cpuSeconds returns the CPU time limit in whole seconds, rounded up.
+func (s *Sandbox) cpuSeconds() uint64 {
+	if s.config.CPUTime <= 0 {
+		return 0
+	}
+	return uint64((s.config.CPUTime + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,321 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Landlock rights granted by sandbox rules.
+const (
+	landlockRead    = unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+	landlockExecute = landlockRead | unix.LANDLOCK_ACCESS_FS_EXECUTE
+
+	// landlockFileRights are the rights that apply to files; rules on
+	// files rather than directories may only grant these.
+	landlockFileRights = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+)
+
+// sandboxDevices are device files every sandbox can use, with whether they
+// are writable.
+var sandboxDevices = map[string]bool{
+	"/dev/null":    true,
+	"/dev/zero":    false,
+	"/dev/random":  false,
+	"/dev/urandom": false,
+}
+
+// start starts cmd on a thread restricted by Landlock and seccomp, which
+// the child inherits, then applies resource limits to the child.
+func (s *Sandbox) start(cmd *exec.Cmd, tmpDir string) error {
+	errc := make(chan error, 1)
+	go func() {
+		// The restrictions only apply to this thread. It is never
+		// unlocked, so the runtime discards it when the goroutine exits
+		// instead of running other goroutines on it.
+		runtime.LockOSThread()
+		if err := s.restrictThread(cmd, tmpDir); err != nil {
+			errc <- err
+			return
+		}
+		errc <- cmd.Start()
+	}()
+	if err := <-errc; err != nil {
+		return err
+	}
+
+	if err := s.setRlimits(cmd.Process.Pid); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	return nil
+}
+
+// restrictThread applies the filesystem and network restrictions to the
+// calling thread.
+func (s *Sandbox) restrictThread(cmd *exec.Cmd, tmpDir string) error {
+	// Both Landlock and unprivileged seccomp filters require that the
+	// thread cannot gain privileges, for example through setuid binaries.
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+
+	if err := s.restrictFilesystem(cmd, tmpDir); err != nil {
+		return err
+	}
+	if !s.network {
+		if err := s.restrictNetwork(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restrictFilesystem limits filesystem access with a Landlock ruleset.
+func (s *Sandbox) restrictFilesystem(cmd *exec.Cmd, tmpDir string) error {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		s.warn("landlock", "Landlock is not available (%v); filesystem access is not restricted", errno)
+		return nil
+	}
+
+	handled := landlockHandledRights(int(abi))
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create Landlock ruleset: %w", errno)
+	}
+	ruleset := int(fd)
+	defer unix.Close(ruleset)
+
+	write := handled &^ unix.LANDLOCK_ACCESS_FS_EXECUTE
+	rules := make(map[string]uint64)
+	grant := func(path string, access uint64) {
+		rules[path] |= access
+	}
+
+	for _, dir := range systemDirs {
+		grant(dir, landlockExecute)
+	}
+	for _, dir := range s.execDirs {
+		grant(dir, landlockExecute)
+	}
+	if cmd.Path != "" && filepath.IsAbs(cmd.Path) {
+		grant(filepath.Dir(cmd.Path), landlockExecute)
+	}
+	for device, writable := range sandboxDevices {
+		if writable {
+			grant(device, landlockRead|unix.LANDLOCK_ACCESS_FS_WRITE_FILE)
+		} else {
+			grant(device, landlockRead)
+		}
+	}
+	// Rules on single files grant only that file, so a granted file
+	// that does not exist yet is created for the rule to apply to.
+	for _, path := range s.writeFiles {
+		if err := createGrantedFile(path); err != nil {
+			return err
+		}
+	}
+	for _, path := range s.readPaths {
+		grant(path, landlockRead)
+	}
+	for _, path := range s.writePaths {
+		grant(path, write)
+	}
+	grant(tmpDir, write)
+
+	for path, access := range rules {
+		if err := addLandlockRule(ruleset, path, access&handled); err != nil {
+			return err
+		}
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(ruleset), 0, 0); errno != 0 {
+		return fmt.Errorf("failed to enforce Landlock ruleset: %w", errno)
+	}
+	return nil
+}
+
+// landlockHandledRights returns every filesystem right the Landlock ABI
+// version can restrict. Rights the kernel does not know stay unrestricted.
+func landlockHandledRights(abi int) uint64 {
+	// Version 1 covers everything up to LANDLOCK_ACCESS_FS_MAKE_SYM.
+	rights := uint64(unix.LANDLOCK_ACCESS_FS_MAKE_SYM<<1 - 1)
+	if abi >= 2 {
+		rights |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		rights |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	return rights
+}
+
+// createGrantedFile creates an empty file at path unless it exists. Files
+// in directories that do not exist are left for the plugin to fail on.
+func createGrantedFile(path string) error {
+	fd, err := unix.Open(path, unix.O_CREAT|unix.O_EXCL|unix.O_WRONLY|unix.O_CLOEXEC, 0600)
+	if err != nil {
+		if errors.Is(err, unix.EEXIST) || errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return unix.Close(fd)
+}
+
+// addLandlockRule allows access beneath path, or to path alone when it is
+// a file. Paths that do not exist are skipped.
+func addLandlockRule(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.ENOTDIR) {
+			return nil
+		}
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var stat unix.Stat_t
+	if err := unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileRights
+	}
+
+	attr := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH,
+		uintptr(unsafe.Pointer(&attr)), 0, 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to add Landlock rule for %s: %w", path, errno)
+	}
+	return nil
+}
+
+// seccompData offsets of struct seccomp_data fields.
+const (
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArg0 = 16
+)
+
+// x32SyscallBit marks x32 system calls on amd64, which would otherwise
+// bypass a filter written for the 64-bit numbers.
+const x32SyscallBit = 0x40000000
+
+// Jump targets of seccompNetworkFilter, resolved when the filter is built.
+const (
+	bpfAllow = 0xff - iota
+	bpfDeny
+	bpfKill
+)
+
+// restrictNetwork installs a seccomp filter that refuses sockets other
+// than Unix sockets.
+func (s *Sandbox) restrictNetwork() error {
+	filter, ok := seccompNetworkFilter()
+	if !ok {
+		s.warn("seccomp", "seccomp filters are not supported on %s; network access is not restricted", runtime.GOARCH)
+		return nil
+	}
+
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+		if errors.Is(err, unix.EINVAL) {
+			s.warn("seccomp", "seccomp is not available; network access is not restricted")
+			return nil
+		}
+		return fmt.Errorf("failed to install seccomp filter: %w", err)
+	}
+	return nil
+}
+
+// seccompNetworkFilter returns a BPF program that makes socket(2) fail
+// with EACCES for every domain but AF_UNIX. io_uring is refused too, since
+// it can create sockets without calling socket(2). Processes of another
+// architecture are killed, as their system call numbers differ.
+func seccompNetworkFilter() ([]unix.SockFilter, bool) {
+	var arch uint32
+	switch runtime.GOARCH {
+	case "amd64":
+		arch = unix.AUDIT_ARCH_X86_64
+	case "arm64":
+		arch = unix.AUDIT_ARCH_AARCH64
+	default:
+		return nil, false
+	}
+
+	filter := []unix.SockFilter{
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArch),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, arch, 0, bpfKill),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataNr),
+	}
+	if runtime.GOARCH == "amd64" {
+		filter = append(filter, bpfJump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, x32SyscallBit, bpfDeny, 0))
+	}
+	filter = append(filter,
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, unix.SYS_IO_URING_SETUP, bpfDeny, 0),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, unix.SYS_SOCKET, 0, bpfAllow),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, seccompDataArg0),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, unix.AF_UNIX, bpfAllow, bpfDeny),
+	)
+
+	targets := map[uint8]int{}
+	targets[bpfAllow] = len(filter)
+	filter = append(filter, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ALLOW))
+	targets[bpfDeny] = len(filter)
+	filter = append(filter, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.EACCES)))
+	targets[bpfKill] = len(filter)
+	filter = append(filter, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS))
+
+	// Replace the placeholder targets with offsets from the next
+	// instruction.
+	for i := range filter {
+		if target, ok := targets[filter[i].Jt]; ok {
+			filter[i].Jt = uint8(target - i - 1)
+		}
+		if target, ok := targets[filter[i].Jf]; ok {
+			filter[i].Jf = uint8(target - i - 1)
+		}
+	}
+	return filter, true
+}
+
+// bpfStmt returns a BPF statement.
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+// bpfJump returns a BPF jump.
+func bpfJump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
+
+// setRlimits limits the CPU time and memory of the process. The limits
+// apply from shortly after the process starts, since they can only be set
+// per process.
+func (s *Sandbox) setRlimits(pid int) error {
+	if seconds := s.cpuSeconds(); seconds > 0 {
+		// The soft limit sends SIGXCPU, the hard limit a second later
+		// SIGKILL.
+		limit := unix.Rlimit{Cur: seconds, Max: seconds + 1}
+		if err := unix.Prlimit(pid, unix.RLIMIT_CPU, &limit, nil); err != nil {
+			return fmt.Errorf("failed to limit CPU time: %w", err)
+		}
+	}
+	if s.config.MaxMemory > 0 {
+		limit := unix.Rlimit{Cur: s.config.MaxMemory, Max: s.config.MaxMemory}
+		if err := unix.Prlimit(pid, unix.RLIMIT_DATA, &limit, nil); err != nil {
+			return fmt.Errorf("failed to limit memory: %w", err)
+		}
+	}
+	return nil
+}
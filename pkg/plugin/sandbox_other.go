@@ -0,0 +1,15 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// start starts cmd without OS restrictions, which are only implemented on
+// Linux. The environment is still limited to what the sandbox allows.
+func (s *Sandbox) start(cmd *exec.Cmd, tmpDir string) error {
+	s.warn("unsupported", "OS restrictions are not supported on %s; filesystem, network and resource limits are not enforced", runtime.GOOS)
+	return cmd.Start()
+}
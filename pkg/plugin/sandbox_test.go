@@ -0,0 +1,189 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// sandboxPluginEnv makes the test binary act as a plugin that reports what
+// the sandbox lets it do.
+const sandboxPluginEnv = "CLIFORGE_TEST_SANDBOX_PLUGIN"
+
+// runSandboxTestPlugin tries the operations a sandbox restricts and prints
+// one "name: result" line for each.
+func runSandboxTestPlugin() {
+	report := func(name string, err error) {
+		if err != nil {
+			fmt.Printf("%s: denied\n", name)
+		} else {
+			fmt.Printf("%s: ok\n", name)
+		}
+	}
+
+	_, err := os.ReadFile(os.Getenv("SANDBOX_ALLOWED"))
+	report("read-allowed", err)
+	_, err = os.ReadFile(os.Getenv("SANDBOX_SECRET"))
+	report("read-secret", err)
+	_, err = os.ReadFile(filepath.Join(filepath.Dir(os.Getenv("SANDBOX_SECRET")), "sibling.txt"))
+	report("read-sibling", err)
+	report("write-file", os.WriteFile(os.Getenv("SANDBOX_FILE"), []byte("x"), 0644))
+	report("write-tmp", os.WriteFile(filepath.Join(os.TempDir(), "out"), []byte("x"), 0644))
+	report("write-granted", os.WriteFile(filepath.Join(os.Getenv("SANDBOX_WRITABLE"), "out"), []byte("x"), 0644))
+	report("write-outside", os.WriteFile(filepath.Join(filepath.Dir(os.Getenv("SANDBOX_SECRET")), "out"), []byte("x"), 0644))
+
+	for name, domain := range map[string]int{"socket-inet": syscall.AF_INET, "socket-unix": syscall.AF_UNIX} {
+		fd, err := syscall.Socket(domain, syscall.SOCK_STREAM, 0)
+		if err == nil {
+			_ = syscall.Close(fd)
+		}
+		report(name, err)
+	}
+
+	fmt.Printf("env-approved: %s\n", os.Getenv("CLIFORGE_TEST_APPROVED"))
+	fmt.Printf("env-secret: %s\n", os.Getenv("CLIFORGE_TEST_SECRET"))
+}
+
+func TestSandbox_Restrictions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the sandbox only restricts processes on Linux")
+	}
+
+	allowedDir := t.TempDir()
+	allowed := filepath.Join(allowedDir, "allowed.txt")
+	secretDir := t.TempDir()
+	secret := filepath.Join(secretDir, "secret.txt")
+	writable := t.TempDir()
+	file := filepath.Join(t.TempDir(), "file.txt")
+	for _, path := range []string{allowed, secret, filepath.Join(secretDir, "sibling.txt")} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("CLIFORGE_TEST_APPROVED", "visible")
+	t.Setenv("CLIFORGE_TEST_SECRET", "hidden")
+
+	manifest := PluginManifest{
+		Name: "sandboxed",
+		Permissions: []Permission{
+			{Type: PermissionReadFile, Resource: allowedDir + "/*"},
+			{Type: PermissionWriteFile, Resource: writable + "/*"},
+			{Type: PermissionReadEnv, Resource: "CLIFORGE_TEST_APPROVED"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []Permission
+		want        []string
+		// landlock is wanted when Landlock is available
+		landlock []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"read-allowed: ok", "write-tmp: ok", "write-granted: ok",
+				"socket-unix: ok", "env-approved: visible", "env-secret: \n",
+			},
+			landlock: []string{"read-secret: denied", "read-sibling: denied", "write-outside: denied", "write-file: denied"},
+		},
+		{
+			name:        "network granted",
+			permissions: []Permission{{Type: PermissionNetwork, Resource: "*"}},
+			want:        []string{"socket-inet: ok"},
+			landlock:    []string{"read-secret: denied", "write-outside: denied"},
+		},
+		{
+			name: "single files granted",
+			permissions: []Permission{
+				{Type: PermissionReadFile, Resource: secret},
+				{Type: PermissionWriteFile, Resource: file},
+			},
+			want:     []string{"read-secret: ok", "write-file: ok"},
+			landlock: []string{"read-sibling: denied", "write-outside: denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := manifest
+			m.Permissions = append(append([]Permission{}, manifest.Permissions...), tt.permissions...)
+
+			var warnings bytes.Buffer
+			config := DefaultSandboxConfig()
+			config.Warnings = &warnings
+			sandbox, err := NewSandbox(m, "", config)
+			if err != nil {
+				t.Fatalf("NewSandbox() error = %v", err)
+			}
+
+			output, err := NewExecutor(0, 0).ExecuteSandboxed(context.Background(), sandbox, os.Args[0], &PluginInput{
+				Env: map[string]string{
+					sandboxPluginEnv:   "1",
+					"SANDBOX_ALLOWED":  allowed,
+					"SANDBOX_SECRET":   secret,
+					"SANDBOX_WRITABLE": writable,
+					"SANDBOX_FILE":     file,
+				},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteSandboxed() error = %v", err)
+			}
+			if output.ExitCode != 0 {
+				t.Fatalf("plugin exited with %d: %s", output.ExitCode, output.Stderr)
+			}
+
+			want := tt.want
+			if strings.Contains(warnings.String(), "Landlock is not available") {
+				t.Logf("skipping filesystem checks: %s", warnings.String())
+			} else {
+				want = append(want, tt.landlock...)
+			}
+			if !sandbox.network && !strings.Contains(warnings.String(), "network access is not restricted") {
+				want = append(want, "socket-inet: denied")
+			}
+			for _, line := range want {
+				if !strings.Contains(output.Stdout, line) {
+					t.Errorf("Expected output to contain %q, got:\n%s", line, output.Stdout)
+				}
+			}
+		})
+	}
+
+	// The host itself must stay unrestricted.
+	if _, err := os.ReadFile(secret); err != nil {
+		t.Errorf("Host lost access to %s: %v", secret, err)
+	}
+}
+
+func TestSandbox_Environ(t *testing.T) {
+	manifest := PluginManifest{
+		Name:        "env",
+		Permissions: []Permission{{Type: PermissionReadEnv, Resource: "APP_*"}},
+	}
+	sandbox, err := NewSandbox(manifest, "", DefaultSandboxConfig())
+	if err != nil {
+		t.Fatalf("NewSandbox() error = %v", err)
+	}
+
+	env := sandbox.environ(
+		[]string{"APP_TOKEN=abc", "HOME=/root", "APP_MODE=dev"},
+		map[string]string{"APP_MODE": "prod", "EXTRA": "1"},
+		"/tmp/plugin",
+	)
+	got := strings.Join(env, " ")
+	for _, want := range []string{"APP_TOKEN=abc", "APP_MODE=prod", "EXTRA=1", "TMPDIR=/tmp/plugin"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected environment to contain %q, got %q", want, got)
+		}
+	}
+	if strings.Contains(got, "HOME=") || strings.Contains(got, "APP_MODE=dev") {
+		t.Errorf("Unexpected variables in environment %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/CliForge/cliforge/pkg/plugin"
+)
+
+// ErrNoSession is returned by host calls made over the stdio protocol,
+// which has no channel back to the host.
+var ErrNoSession = errors.New("host methods require protocol: session")
+
+// Context is passed to handlers. It is cancelled when the execution's
+// timeout expires or the host shuts the plugin down.
+type Context struct {
+	context.Context
+
+	// Input is the execution's input.
+	Input *plugin.PluginInput
+
+	// Stdout and Stderr are returned to the host as the output's stdout
+	// and stderr.
+	Stdout bytes.Buffer
+	Stderr bytes.Buffer
+
+	conn *conn
+}
+
+// Args returns the positional arguments.
+func (c *Context) Args() []string {
+	return c.Input.Args
+}
+
+// Permissions returns the permissions the host granted. It is empty over
+// the stdio protocol.
+func (c *Context) Permissions() []string {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.permissions
+}
+
+// Log sends a log message to the host, which prints it with secrets
+// masked. Over the stdio protocol it is written to Stderr.
+func (c *Context) Log(level, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if c.conn == nil {
+		fmt.Fprintf(&c.Stderr, "%s: %s\n", level, message)
+		return
+	}
+	c.conn.notify("log", map[string]interface{}{"level": level, "message": message})
+}
+
+// Progress reports progress to the host, which renders it as a progress
+// bar. It is ignored over the stdio protocol.
+func (c *Context) Progress(message string, current, total int) {
+	if c.conn == nil {
+		return
+	}
+	c.conn.notify("progress", map[string]interface{}{"message": message, "current": current, "total": total})
+}
+
+// Track returns a tracker for a task of total steps and reports that none
+// is done yet.
+func (c *Context) Track(message string, total int) *Tracker {
+	t := &Tracker{c: c, message: message, total: total}
+	c.Progress(message, 0, total)
+	return t
+}
+
+// Call calls a host method such as "http.request" and decodes its result
+// into result. The host checks the call against the plugin's permissions.
+func (c *Context) Call(method string, params, result interface{}) error {
+	if c.conn == nil {
+		return ErrNoSession
+	}
+	return c.conn.call(c, method, params, result)
+}
+
+// Tracker reports the progress of a task with a known number of steps.
+// It is safe for concurrent use.
+type Tracker struct {
+	c       *Context
+	mu      sync.Mutex
+	message string
+	current int
+	total   int
+}
+
+// Increment marks one more step done.
+func (t *Tracker) Increment() {
+	t.Add(1)
+}
+
+// Add marks n more steps done.
+func (t *Tracker) Add(n int) {
+	t.mu.Lock()
+	t.current += n
+	message, current, total := t.message, t.current, t.total
+	t.mu.Unlock()
+	t.c.Progress(message, current, total)
+}
+
+// SetMessage changes the message shown with the progress.
+func (t *Tracker) SetMessage(message string) {
+	t.mu.Lock()
+	t.message = message
+	current, total := t.current, t.total
+	t.mu.Unlock()
+	t.c.Progress(message, current, total)
+}
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/CliForge/cliforge/pkg/plugin"
+)
+
+// JSON-RPC error codes a plugin answers with.
+const (
+	// CodeError is the code of errors returned by handlers.
+	CodeError = -32000
+
+	// CodeMethodNotFound is returned for unknown methods and commands.
+	CodeMethodNotFound = -32601
+
+	// CodeInvalidParams is returned when input cannot be decoded.
+	CodeInvalidParams = -32602
+
+	// CodeInternalError is returned when the plugin itself fails.
+	CodeInternalError = -32603
+)
+
+// Error is an error with the detail the host shows the user. The host
+// reports it as a plugin.PluginError, and retries it if it is recoverable.
+type Error struct {
+	// Code is the JSON-RPC error code. Defaults to CodeError.
+	Code int
+
+	// Message describes the error.
+	Message string
+
+	// Suggestion tells the user how to resolve the error.
+	Suggestion string
+
+	// Recoverable marks errors worth retrying.
+	Recoverable bool
+}
+
+// Errorf creates an error with a formatted message.
+func Errorf(format string, args ...interface{}) *Error {
+	return &Error{Code: CodeError, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithSuggestion adds a suggestion to the error.
+func (e *Error) WithSuggestion(suggestion string) *Error {
+	e.Suggestion = suggestion
+	return e
+}
+
+// AsRecoverable marks the error as recoverable.
+func (e *Error) AsRecoverable() *Error {
+	e.Recoverable = true
+	return e
+}
+
+// rpcError converts any error to the JSON-RPC error sent to the host.
+func rpcError(err error) *plugin.RPCError {
+	var sdkErr *Error
+	if !errors.As(err, &sdkErr) {
+		return &plugin.RPCError{Code: CodeError, Message: err.Error()}
+	}
+
+	rpcErr := &plugin.RPCError{Code: sdkErr.Code, Message: sdkErr.Message}
+	if rpcErr.Code == 0 {
+		rpcErr.Code = CodeError
+	}
+	if sdkErr.Suggestion != "" || sdkErr.Recoverable {
+		rpcErr.Data = &plugin.RPCErrorData{Suggestion: sdkErr.Suggestion, Recoverable: sdkErr.Recoverable}
+	}
+	return rpcErr
+}
@@ -0,0 +1,204 @@
+// Package sdk implements the plugin side of the CliForge plugin protocol,
+// so binary plugins can be written in Go without handling JSON-RPC.
+//
+// A plugin declares its manifest, registers typed command and hook
+// handlers, and calls Main:
+//
+//	func main() {
+//		p := sdk.New(plugin.PluginManifest{Name: "greeter", Version: "1.0.0"})
+//		sdk.Command(p, cli.PluginCommand{Name: "greet"}, func(c *sdk.Context, in GreetInput) (GreetOutput, error) {
+//			if in.Name == "" {
+//				return GreetOutput{}, sdk.Errorf("a name is required").WithSuggestion("Pass --name")
+//			}
+//			return GreetOutput{Message: "Hello, " + in.Name}, nil
+//		})
+//		p.Main()
+//	}
+//
+// The same binary serves both the stdio and the session protocol, and
+// prints its manifest when run as "greeter manifest".
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// Handler runs a command. The returned data becomes the output's Data.
+type Handler func(c *Context) (map[string]interface{}, error)
+
+// HookHandler runs a hook. Changes it makes to hc are returned to the
+// host.
+type HookHandler func(c *Context, hc *plugin.HookContext) error
+
+// Plugin is a plugin served by the SDK.
+type Plugin struct {
+	manifest plugin.PluginManifest
+	commands map[string]Handler
+	hooks    map[plugin.HookPoint]HookHandler
+}
+
+// New creates a plugin with the given manifest. Commands and hooks are
+// added to the manifest as handlers are registered. The type defaults to
+// binary and the executable to the plugin name.
+func New(manifest plugin.PluginManifest) *Plugin {
+	if manifest.Type == "" {
+		manifest.Type = plugin.PluginTypeBinary
+	}
+	if manifest.Executable == "" {
+		manifest.Executable = manifest.Name
+	}
+	if manifest.Permissions == nil {
+		manifest.Permissions = []plugin.Permission{}
+	}
+
+	return &Plugin{
+		manifest: manifest,
+		commands: make(map[string]Handler),
+		hooks:    make(map[plugin.HookPoint]HookHandler),
+	}
+}
+
+// Handle declares a command and the handler that runs it. Flag values
+// arrive in Context.Input.Data and positional arguments in Context.Args.
+func (p *Plugin) Handle(command cli.PluginCommand, handler Handler) {
+	if _, exists := p.commands[command.Name]; !exists {
+		p.manifest.Commands = append(p.manifest.Commands, command)
+	}
+	p.commands[command.Name] = handler
+}
+
+// Command declares a command with a typed handler. Flag values are decoded
+// into In by their JSON names; Out is returned as the output's data, under
+// "result" if it does not encode as a JSON object.
+func Command[In, Out any](p *Plugin, command cli.PluginCommand, handle func(c *Context, in In) (Out, error)) {
+	p.Handle(command, func(c *Context) (map[string]interface{}, error) {
+		var in In
+		if err := convert(c.Input.Data, &in); err != nil {
+			return nil, &Error{
+				Code:       CodeInvalidParams,
+				Message:    fmt.Sprintf("invalid input: %v", err),
+				Suggestion: fmt.Sprintf("Check the flags of '%s'", command.Name),
+			}
+		}
+
+		out, err := handle(c, in)
+		if err != nil {
+			return nil, err
+		}
+
+		var data map[string]interface{}
+		if err := convert(out, &data); err != nil {
+			var value interface{}
+			if err := convert(out, &value); err != nil {
+				return nil, fmt.Errorf("failed to encode output: %w", err)
+			}
+			data = map[string]interface{}{"result": value}
+		}
+		return data, nil
+	})
+}
+
+// Hook declares a hook and the handler that runs it.
+func (p *Plugin) Hook(spec plugin.HookSpec, handle HookHandler) {
+	if _, exists := p.hooks[spec.Point]; !exists {
+		p.manifest.Hooks = append(p.manifest.Hooks, spec)
+	}
+	p.hooks[spec.Point] = handle
+}
+
+// Manifest returns the plugin's manifest, including its commands and hooks.
+func (p *Plugin) Manifest() plugin.PluginManifest {
+	return p.manifest
+}
+
+// WriteManifest writes the manifest as YAML, ready to be saved as
+// plugin-manifest.yaml.
+func (p *Plugin) WriteManifest(w io.Writer) error {
+	data, err := yaml.Marshal(p.manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Main runs the plugin as a program. Run with the argument "manifest" it
+// prints the manifest; otherwise it serves the host on stdin and stdout.
+func (p *Plugin) Main() {
+	if len(os.Args) > 1 && os.Args[1] == "manifest" {
+		if err := p.WriteManifest(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := p.Serve(context.Background(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches an execution to its handler.
+func (p *Plugin) run(c *Context) (map[string]interface{}, error) {
+	if c.Input.Command == plugin.HookCommand {
+		return p.runHook(c)
+	}
+
+	handler, exists := p.commands[c.Input.Command]
+	if !exists {
+		names := make([]string, 0, len(p.commands))
+		for name := range p.commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &Error{
+			Code:       CodeMethodNotFound,
+			Message:    fmt.Sprintf("unknown command '%s'", c.Input.Command),
+			Suggestion: fmt.Sprintf("Available commands: %s", strings.Join(names, ", ")),
+		}
+	}
+	return handler(c)
+}
+
+// runHook decodes the hook context and runs the hook of its point. Points
+// without a handler leave the context unchanged.
+func (p *Plugin) runHook(c *Context) (map[string]interface{}, error) {
+	var hc plugin.HookContext
+	if err := convert(c.Input.Data, &hc); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid hook context: %v", err)}
+	}
+
+	handle, exists := p.hooks[hc.Point]
+	if !exists {
+		return nil, nil
+	}
+	if err := handle(c, &hc); err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := convert(&hc, &data); err != nil {
+		return nil, fmt.Errorf("failed to encode hook context: %w", err)
+	}
+	return data, nil
+}
+
+// convert copies from into to through JSON.
+func convert(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
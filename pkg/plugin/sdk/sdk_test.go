@@ -0,0 +1,295 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli"
+	"github.com/CliForge/cliforge/pkg/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// testPluginEnv makes the test binary act as the test plugin.
+const testPluginEnv = "CLIFORGE_TEST_SDK_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if protocol := os.Getenv(testPluginEnv); protocol != "" {
+		newTestPlugin(plugin.PluginProtocol(protocol)).Main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type greetInput struct {
+	Name  string `json:"name"`
+	Times int    `json:"times"`
+}
+
+type greetOutput struct {
+	Message string `json:"message"`
+}
+
+// newTestPlugin returns the plugin the tests run.
+func newTestPlugin(protocol plugin.PluginProtocol) *Plugin {
+	p := New(plugin.PluginManifest{
+		Name:        "greeter",
+		Version:     "1.2.0",
+		Description: "Greets people",
+		Protocol:    protocol,
+		Permissions: []plugin.Permission{
+			{Type: plugin.PermissionReadEnv, Resource: testPluginEnv, Description: "Select the test plugin"},
+		},
+	})
+
+	Command(p, cli.PluginCommand{Name: "greet", Flags: []cli.PluginFlag{{Name: "name"}, {Name: "times", Type: "int"}}},
+		func(c *Context, in greetInput) (greetOutput, error) {
+			if in.Name == "" {
+				return greetOutput{}, Errorf("a name is required").WithSuggestion("Pass --name")
+			}
+			c.Log("info", "greeting %s", in.Name)
+			return greetOutput{Message: strings.Repeat("Hello, "+in.Name+"! ", max(in.Times, 1))}, nil
+		})
+
+	Command(p, cli.PluginCommand{Name: "flaky"}, func(c *Context, in struct{}) (string, error) {
+		return "", Errorf("rate limited").WithSuggestion("Wait a minute").AsRecoverable()
+	})
+
+	Command(p, cli.PluginCommand{Name: "work"}, func(c *Context, in struct{}) (string, error) {
+		tracker := c.Track("working", 3)
+		for i := 0; i < 3; i++ {
+			tracker.Increment()
+		}
+		c.Stdout.WriteString("worked\n")
+		return "done", nil
+	})
+
+	Command(p, cli.PluginCommand{Name: "panic"}, func(c *Context, in struct{}) (string, error) {
+		panic("boom")
+	})
+
+	p.Hook(plugin.HookSpec{Point: plugin.HookBeforeRequest}, func(c *Context, hc *plugin.HookContext) error {
+		if hc.Request.Header == nil {
+			hc.Request.Header = map[string][]string{}
+		}
+		hc.Request.Header["X-Greeter"] = []string{"hello"}
+		return nil
+	})
+
+	return p
+}
+
+// serveOnce runs one stdio request through Serve and returns the response.
+func serveOnce(t *testing.T, p *Plugin, input *plugin.PluginInput) map[string]json.RawMessage {
+	t.Helper()
+	request, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": "1", "method": "execute", "params": input})
+
+	var out bytes.Buffer
+	if err := p.Serve(context.Background(), bytes.NewReader(request), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid response %q: %v", out.String(), err)
+	}
+	return response
+}
+
+func TestPlugin_Serve(t *testing.T) {
+	p := newTestPlugin(plugin.PluginProtocolStdio)
+
+	tests := []struct {
+		name       string
+		input      *plugin.PluginInput
+		wantResult string
+		wantError  *plugin.RPCError
+	}{
+		{
+			name:       "typed command",
+			input:      &plugin.PluginInput{Command: "greet", Data: map[string]interface{}{"name": "Ada", "times": 2}},
+			wantResult: `"message":"Hello, Ada! Hello, Ada! "`,
+		},
+		{
+			name:       "non-object output",
+			input:      &plugin.PluginInput{Command: "work"},
+			wantResult: `"data":{"result":"done"}`,
+		},
+		{
+			name:      "handler error",
+			input:     &plugin.PluginInput{Command: "greet"},
+			wantError: &plugin.RPCError{Code: CodeError, Message: "a name is required", Data: &plugin.RPCErrorData{Suggestion: "Pass --name"}},
+		},
+		{
+			name:      "invalid input",
+			input:     &plugin.PluginInput{Command: "greet", Data: map[string]interface{}{"times": "often"}},
+			wantError: &plugin.RPCError{Code: CodeInvalidParams},
+		},
+		{
+			name:      "unknown command",
+			input:     &plugin.PluginInput{Command: "wave"},
+			wantError: &plugin.RPCError{Code: CodeMethodNotFound, Message: "unknown command 'wave'"},
+		},
+		{
+			name:      "panic",
+			input:     &plugin.PluginInput{Command: "panic"},
+			wantError: &plugin.RPCError{Code: CodeInternalError, Message: "plugin panicked: boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := serveOnce(t, p, tt.input)
+
+			if tt.wantError == nil {
+				if response["error"] != nil {
+					t.Fatalf("Unexpected error %s", response["error"])
+				}
+				if !strings.Contains(string(response["result"]), tt.wantResult) {
+					t.Errorf("result = %s, want it to contain %s", response["result"], tt.wantResult)
+				}
+				return
+			}
+
+			var rpcErr plugin.RPCError
+			if err := json.Unmarshal(response["error"], &rpcErr); err != nil {
+				t.Fatalf("Expected an error, got %v", response)
+			}
+			if rpcErr.Code != tt.wantError.Code {
+				t.Errorf("code = %d, want %d", rpcErr.Code, tt.wantError.Code)
+			}
+			if tt.wantError.Message != "" && rpcErr.Message != tt.wantError.Message {
+				t.Errorf("message = %q, want %q", rpcErr.Message, tt.wantError.Message)
+			}
+			if tt.wantError.Data != nil && (rpcErr.Data == nil || *rpcErr.Data != *tt.wantError.Data) {
+				t.Errorf("data = %+v, want %+v", rpcErr.Data, tt.wantError.Data)
+			}
+		})
+	}
+}
+
+func TestPlugin_Manifest(t *testing.T) {
+	var out bytes.Buffer
+	if err := newTestPlugin(plugin.PluginProtocolSession).WriteManifest(&out); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+
+	var manifest plugin.PluginManifest
+	if err := yaml.Unmarshal(out.Bytes(), &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v\n%s", err, out.String())
+	}
+	if manifest.Type != plugin.PluginTypeBinary || manifest.Executable != "greeter" || manifest.Protocol != plugin.PluginProtocolSession {
+		t.Errorf("Unexpected defaults: %+v", manifest)
+	}
+	if len(manifest.Commands) != 4 || manifest.Commands[0].Name != "greet" || len(manifest.Commands[0].Flags) != 2 {
+		t.Errorf("Unexpected commands: %+v", manifest.Commands)
+	}
+	if len(manifest.Hooks) != 1 || manifest.Hooks[0].Point != plugin.HookBeforeRequest {
+		t.Errorf("Unexpected hooks: %+v", manifest.Hooks)
+	}
+}
+
+// writeTestPlugin writes the manifest of the test binary acting as the
+// test plugin and returns its directory.
+func writeTestPlugin(t *testing.T, protocol plugin.PluginProtocol) string {
+	t.Helper()
+	t.Setenv(testPluginEnv, string(protocol))
+
+	p := newTestPlugin(protocol)
+	p.manifest.Executable = os.Args[0]
+	if !filepath.IsAbs(os.Args[0]) {
+		abs, err := filepath.Abs(os.Args[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.manifest.Executable = abs
+	}
+
+	dir := t.TempDir()
+	var manifest bytes.Buffer
+	if err := p.WriteManifest(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, plugin.ManifestFile), manifest.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPlugin_Protocols(t *testing.T) {
+	for _, protocol := range []plugin.PluginProtocol{plugin.PluginProtocolStdio, plugin.PluginProtocolSession} {
+		t.Run(string(protocol), func(t *testing.T) {
+			dir := writeTestPlugin(t, protocol)
+
+			var log bytes.Buffer
+			registry := plugin.NewRegistry(filepath.Dir(dir), nil)
+			registry.SetHostServices(&plugin.HostServices{Log: &log})
+			if err := registry.DiscoverPlugins(); err != nil {
+				t.Fatalf("DiscoverPlugins() error = %v", err)
+			}
+			defer func() { _ = registry.Close() }()
+
+			output, err := registry.Execute(context.Background(), "greeter", &plugin.PluginInput{
+				Command: "greet",
+				Data:    map[string]interface{}{"name": "Ada"},
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if message, _ := output.GetString("message"); message != "Hello, Ada! " {
+				t.Errorf("message = %q", message)
+			}
+			if protocol == plugin.PluginProtocolSession && !strings.Contains(log.String(), "greeting Ada") {
+				t.Errorf("Expected log notification, got %q", log.String())
+			}
+			if protocol == plugin.PluginProtocolStdio && !strings.Contains(output.Stderr, "info: greeting Ada") {
+				t.Errorf("Expected log on stderr, got %q", output.Stderr)
+			}
+
+			_, err = registry.Execute(context.Background(), "greeter", &plugin.PluginInput{Command: "flaky"})
+			var pluginErr *plugin.PluginError
+			if !errors.As(err, &pluginErr) || !pluginErr.Recoverable || pluginErr.Suggestion != "Wait a minute" {
+				t.Errorf("Expected a recoverable PluginError with suggestion, got %#v", err)
+			}
+
+			hooks := registry.Hooks(plugin.HookBeforeRequest)
+			if len(hooks) != 1 {
+				t.Fatalf("Expected one hook, got %v", hooks)
+			}
+			hc, err := registry.RunHook(context.Background(), hooks[0], &plugin.HookContext{
+				Point:   plugin.HookBeforeRequest,
+				Request: &plugin.HookRequest{Method: "GET", URL: "https://api.example.com"},
+			})
+			if err != nil {
+				t.Fatalf("RunHook() error = %v", err)
+			}
+			if got := hc.Request.Header["X-Greeter"]; len(got) != 1 || got[0] != "hello" {
+				t.Errorf("Expected hook to add a header, got %v", hc.Request.Header)
+			}
+		})
+	}
+}
+
+func TestPlugin_Conformance(t *testing.T) {
+	for _, protocol := range []plugin.PluginProtocol{plugin.PluginProtocolStdio, plugin.PluginProtocolSession} {
+		t.Run(string(protocol), func(t *testing.T) {
+			dir := writeTestPlugin(t, protocol)
+
+			config := plugin.DefaultSandboxConfig()
+			config.Warnings = &bytes.Buffer{}
+			report := plugin.RunConformance(context.Background(), dir, plugin.ConformanceOptions{Sandbox: config})
+			if !report.Passed() {
+				t.Errorf("Expected SDK plugin to pass, got %+v", report.Checks)
+			}
+			for _, check := range report.Checks {
+				if check.Status == plugin.ConformanceWarn {
+					t.Errorf("Unexpected warning: %+v", check)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/CliForge/cliforge/pkg/plugin"
+)
+
+// message is a JSON-RPC 2.0 request, notification or response.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *plugin.RPCError `json:"error,omitempty"`
+}
+
+// executeResult is the result of an "execute" request.
+type executeResult struct {
+	Stdout   string                 `json:"stdout,omitempty"`
+	Stderr   string                 `json:"stderr,omitempty"`
+	ExitCode int                    `json:"exit_code"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// Serve answers the host's requests on in and out. It speaks whichever
+// protocol the host starts with: a single "execute" request for the stdio
+// protocol, or an "initialize" handshake for the session protocol, in
+// which case it serves until the host sends "shutdown" or closes in.
+func (p *Plugin) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	decoder := json.NewDecoder(in)
+	c := &conn{plugin: p, out: out, pending: make(map[string]chan *message)}
+
+	var first message
+	if err := decoder.Decode(&first); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	if first.Method != "initialize" {
+		c.execute(ctx, &first, false)
+		return nil
+	}
+	c.initialize(&first)
+
+	var executions sync.WaitGroup
+	defer executions.Wait()
+	for {
+		var msg message
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		switch {
+		case msg.Method == "execute":
+			executions.Add(1)
+			go func() {
+				defer executions.Done()
+				c.execute(ctx, &msg, true)
+			}()
+		case msg.Method == "shutdown":
+			cancel()
+			executions.Wait()
+			c.reply(msg.ID, struct{}{}, nil)
+			return nil
+		case msg.Method != "" && msg.ID != nil:
+			c.reply(msg.ID, nil, &plugin.RPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method '%s'", msg.Method)})
+		case msg.Method == "":
+			c.deliver(&msg)
+		}
+	}
+}
+
+// conn is the plugin's end of a connection to the host.
+type conn struct {
+	plugin      *Plugin
+	permissions []string
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[string]chan *message
+}
+
+// initialize answers the session handshake.
+func (c *conn) initialize(msg *message) {
+	var params struct {
+		ProtocolVersions []int    `json:"protocol_versions"`
+		Permissions      []string `json:"permissions"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		c.reply(msg.ID, nil, &plugin.RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid initialize params: %v", err)})
+		return
+	}
+	c.permissions = params.Permissions
+
+	version := 0
+	for _, v := range params.ProtocolVersions {
+		for _, supported := range plugin.SessionProtocolVersions {
+			if v == supported && v > version {
+				version = v
+			}
+		}
+	}
+	if version == 0 {
+		c.reply(msg.ID, nil, &plugin.RPCError{
+			Code:    CodeInvalidParams,
+			Message: fmt.Sprintf("no supported protocol version in %v (plugin supports %v)", params.ProtocolVersions, plugin.SessionProtocolVersions),
+		})
+		return
+	}
+
+	manifest := c.plugin.manifest
+	c.reply(msg.ID, &plugin.SessionInfo{ProtocolVersion: version, Name: manifest.Name, Version: manifest.Version}, nil)
+}
+
+// execute runs an "execute" request and sends its response. session
+// tells whether the context can talk back to the host.
+func (c *conn) execute(ctx context.Context, msg *message, session bool) {
+	if msg.Method != "execute" {
+		c.reply(msg.ID, nil, &plugin.RPCError{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method '%s'", msg.Method)})
+		return
+	}
+
+	var input plugin.PluginInput
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &input); err != nil {
+			c.reply(msg.ID, nil, &plugin.RPCError{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid execute params: %v", err)})
+			return
+		}
+	}
+
+	if input.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, input.Timeout)
+		defer cancel()
+	}
+
+	pc := &Context{Context: ctx, Input: &input}
+	if session {
+		pc.conn = c
+	}
+
+	data, err := c.safeRun(pc)
+	if err != nil {
+		c.reply(msg.ID, nil, rpcError(err))
+		return
+	}
+	c.reply(msg.ID, &executeResult{
+		Stdout: pc.Stdout.String(),
+		Stderr: pc.Stderr.String(),
+		Data:   data,
+	}, nil)
+}
+
+// safeRun runs the handler, turning a panic into an internal error so the
+// host gets an answer instead of a dead process.
+func (c *conn) safeRun(pc *Context) (data map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &Error{Code: CodeInternalError, Message: fmt.Sprintf("plugin panicked: %v", r)}
+		}
+	}()
+	return c.plugin.run(pc)
+}
+
+// call sends a request to the host and waits for its response.
+func (c *conn) call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	c.nextID++
+	id := "p" + strconv.Itoa(c.nextID)
+	reply := make(chan *message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	rawID, _ := json.Marshal(id)
+	msg := &message{JSONRPC: "2.0", ID: rawID, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			c.forget(id)
+			return fmt.Errorf("failed to marshal %s params: %w", method, err)
+		}
+		msg.Params = data
+	}
+	if err := c.write(msg); err != nil {
+		c.forget(id)
+		return err
+	}
+
+	select {
+	case response := <-reply:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result != nil && len(response.Result) > 0 {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		c.forget(id)
+		return ctx.Err()
+	}
+}
+
+// deliver hands a host response to the call waiting for it.
+func (c *conn) deliver(msg *message) {
+	var id string
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	reply, exists := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+
+	if exists {
+		reply <- msg
+	}
+}
+
+// forget drops a pending call.
+func (c *conn) forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// notify sends a notification, ignoring write errors: a host that went
+// away will not read the response either.
+func (c *conn) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	_ = c.write(&message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// reply sends the response to a request.
+func (c *conn) reply(id json.RawMessage, result interface{}, rpcErr *plugin.RPCError) {
+	msg := &message{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = &plugin.RPCError{Code: CodeInternalError, Message: fmt.Sprintf("failed to marshal result: %v", err)}
+		} else {
+			msg.Result = data
+		}
+	}
+	_ = c.write(msg)
+}
+
+// write sends a message on its own line.
+func (c *conn) write(msg *message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to host: %w", err)
+	}
+	return nil
+}
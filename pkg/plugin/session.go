@@ -0,0 +1,563 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SessionProtocolVersions are the session protocol versions the host
+// speaks, newest first. The plugin picks one during the handshake.
+var SessionProtocolVersions = []int{1}
+
+// DefaultSessionPoolSize is how many processes a session plugin runs at
+// most when its pool size is not set.
+const DefaultSessionPoolSize = 4
+
+// sessionShutdownTimeout is how long a plugin gets to exit after the host
+// asks it to shut down.
+const sessionShutdownTimeout = 2 * time.Second
+
+// JSON-RPC error codes used by the session protocol.
+const (
+	rpcMethodNotFound   = -32601
+	rpcInvalidParams    = -32602
+	rpcInternalError    = -32603
+	rpcPermissionDenied = -32001
+)
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response. Requests
+// and notifications have a method; only requests and responses have an ID.
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is a JSON-RPC error returned by a plugin or sent to one.
+type RPCError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *RPCErrorData `json:"data,omitempty"`
+}
+
+// RPCErrorData is the detail a plugin can attach to the error of an
+// "execute" request. The host reports such errors as a PluginError.
+type RPCErrorData struct {
+	// Suggestion tells the user how to resolve the error.
+	Suggestion string `json:"suggestion,omitempty"`
+
+	// Recoverable marks errors worth retrying, such as rate limits.
+	Recoverable bool `json:"recoverable,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// initializeParams are the parameters of the "initialize" handshake.
+type initializeParams struct {
+	// ProtocolVersions are the versions the host speaks.
+	ProtocolVersions []int `json:"protocol_versions"`
+
+	// Host identifies the host.
+	Host string `json:"host"`
+
+	// Permissions are the granted permissions, which decide what host
+	// methods the plugin may call.
+	Permissions []string `json:"permissions"`
+}
+
+// SessionInfo is the plugin's answer to the handshake.
+type SessionInfo struct {
+	// ProtocolVersion is the version the plugin chose.
+	ProtocolVersion int `json:"protocol_version"`
+
+	// Name and Version identify the plugin.
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// Session is a running session plugin process.
+//
+// The host and the plugin exchange newline-delimited JSON-RPC 2.0 messages
+// over the process's stdin and stdout. The host starts with an
+// "initialize" request, then sends "execute" requests one at a time and
+// finally a "shutdown" request. While it executes, the plugin can send
+// "log" and "progress" notifications and call host methods such as
+// "http.request"; see HostServices.
+type Session struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *tailBuffer
+	host    *hostConn
+	info    SessionInfo
+	ctx     context.Context
+	cancel  context.CancelFunc
+	exited  chan struct{}
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *rpcMessage
+	err     error
+}
+
+// StartSession starts the plugin executable and performs the handshake.
+// The manifest's permissions decide what host methods the plugin may call,
+// so they must already be approved.
+func StartSession(ctx context.Context, manifest PluginManifest, execPath string, host *HostServices) (*Session, error) {
+	return StartSandboxedSession(ctx, manifest, execPath, host, nil)
+}
+
+// StartSandboxedSession is StartSession with the process run inside
+// sandbox. A nil sandbox runs it with the user's full privileges.
+func StartSandboxedSession(ctx context.Context, manifest PluginManifest, execPath string, host *HostServices, sandbox *Sandbox) (*Session, error) {
+	sessionCtx, cancel := context.WithCancel(context.Background())
+
+	stdout, stdoutWriter := io.Pipe()
+	cmd := exec.CommandContext(sessionCtx, execPath)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stderr := &tailBuffer{limit: 4096}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderr
+
+	cleanup := func() {}
+	if sandbox == nil {
+		err = cmd.Start()
+	} else {
+		cleanup, err = sandbox.Start(cmd, nil)
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	s := &Session{
+		name:    manifest.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		host:    newHostConn(manifest.Name, manifest.Permissions, host),
+		ctx:     sessionCtx,
+		cancel:  cancel,
+		exited:  make(chan struct{}),
+		pending: make(map[int64]chan *rpcMessage),
+	}
+
+	go func() {
+		err := cmd.Wait()
+		cleanup()
+		if err == nil {
+			err = errors.New("plugin exited")
+		} else {
+			err = fmt.Errorf("plugin exited: %w", err)
+		}
+		_ = stdoutWriter.CloseWithError(err)
+		close(s.exited)
+	}()
+	go s.readLoop(stdout)
+
+	permissions := make([]string, 0, len(manifest.Permissions))
+	for _, perm := range manifest.Permissions {
+		permissions = append(permissions, perm.String())
+	}
+
+	err = s.call(ctx, "initialize", &initializeParams{
+		ProtocolVersions: SessionProtocolVersions,
+		Host:             "cliforge",
+		Permissions:      permissions,
+	}, &s.info)
+	if err != nil {
+		s.kill()
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+
+	supported := false
+	for _, version := range SessionProtocolVersions {
+		supported = supported || version == s.info.ProtocolVersion
+	}
+	if !supported {
+		s.kill()
+		return nil, fmt.Errorf("handshake failed: plugin chose unsupported protocol version %d (host supports %v)",
+			s.info.ProtocolVersion, SessionProtocolVersions)
+	}
+
+	return s, nil
+}
+
+// Info returns what the plugin reported in the handshake.
+func (s *Session) Info() SessionInfo {
+	return s.info
+}
+
+// Alive reports whether the session can still serve requests.
+func (s *Session) Alive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err == nil
+}
+
+// Execute sends an "execute" request. If ctx ends first the process is
+// stopped, since the plugin may still be working on the request.
+func (s *Session) Execute(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
+	defer s.host.finish()
+
+	startTime := time.Now()
+	var result executeResult
+	if err := s.call(ctx, "execute", input, &result); err != nil {
+		s.host.fail()
+		return nil, executeError(s.name, err)
+	}
+
+	output := result.output(time.Since(startTime))
+	if !output.Success() {
+		s.host.fail()
+	}
+	return output, nil
+}
+
+// executeError reports the JSON-RPC error of an "execute" request as a
+// PluginError carrying the plugin's suggestion and recoverable flag. Other
+// errors are returned as is.
+func executeError(name string, err error) error {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+
+	pluginErr := NewPluginError(name, "execution failed", rpcErr)
+	if rpcErr.Data != nil {
+		pluginErr.Suggestion = rpcErr.Data.Suggestion
+		pluginErr.Recoverable = rpcErr.Data.Recoverable
+	}
+	return pluginErr
+}
+
+// Close asks the plugin to shut down, and stops it if it does not exit in
+// time.
+func (s *Session) Close() error {
+	if s.Alive() {
+		ctx, cancel := context.WithTimeout(context.Background(), sessionShutdownTimeout)
+		_ = s.call(ctx, "shutdown", nil, nil)
+		cancel()
+	}
+	_ = s.stdin.Close()
+
+	select {
+	case <-s.exited:
+	case <-time.After(sessionShutdownTimeout):
+	}
+	s.kill()
+	return nil
+}
+
+// kill stops the process and fails pending requests.
+func (s *Session) kill() {
+	s.cancel()
+	<-s.exited
+	s.fail(errors.New("plugin stopped"))
+}
+
+// call sends a request and decodes its result into result.
+func (s *Session) call(ctx context.Context, method string, params, result interface{}) error {
+	s.mu.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return err
+	}
+	s.nextID++
+	id := s.nextID
+	reply := make(chan *rpcMessage, 1)
+	s.pending[id] = reply
+	s.mu.Unlock()
+
+	msg := &rpcMessage{JSONRPC: "2.0", ID: json.RawMessage(strconv.FormatInt(id, 10)), Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			s.forget(id)
+			return fmt.Errorf("failed to marshal %s params: %w", method, err)
+		}
+		msg.Params = data
+	}
+	if err := s.write(msg); err != nil {
+		s.forget(id)
+		return err
+	}
+
+	select {
+	case response, ok := <-reply:
+		if !ok {
+			return s.exitError()
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		if result != nil && len(response.Result) > 0 {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		s.forget(id)
+		s.kill()
+		return ctx.Err()
+	}
+}
+
+// forget drops a pending request.
+func (s *Session) forget(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+}
+
+// write sends a message on its own line.
+func (s *Session) write(msg *rpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := s.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to plugin: %w", err)
+	}
+	return nil
+}
+
+// readLoop dispatches messages from the plugin until its stdout closes.
+func (s *Session) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			s.fail(fmt.Errorf("invalid message from plugin: %w", err))
+			s.cancel()
+			return
+		}
+
+		switch {
+		case msg.Method != "" && msg.ID != nil:
+			go s.serve(&msg)
+		case msg.Method != "":
+			s.host.notify(msg.Method, msg.Params)
+		default:
+			s.deliver(&msg)
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	s.fail(err)
+}
+
+// deliver hands a response to the request waiting for it.
+func (s *Session) deliver(msg *rpcMessage) {
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	reply, exists := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+
+	if exists {
+		reply <- msg
+	}
+}
+
+// serve answers a host method call from the plugin.
+func (s *Session) serve(msg *rpcMessage) {
+	response := &rpcMessage{JSONRPC: "2.0", ID: msg.ID}
+
+	result, err := s.host.call(s.ctx, msg.Method, msg.Params)
+	if err != nil {
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &RPCError{Code: rpcInternalError, Message: err.Error()}
+		}
+		response.Error = rpcErr
+	} else if response.Result, err = json.Marshal(result); err != nil {
+		response.Error = &RPCError{Code: rpcInternalError, Message: err.Error()}
+	}
+
+	_ = s.write(response)
+}
+
+// fail ends the session with err and fails pending requests.
+func (s *Session) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+	for id, reply := range s.pending {
+		close(reply)
+		delete(s.pending, id)
+	}
+}
+
+// exitError describes why the session ended.
+func (s *Session) exitError() error {
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+
+	if stderr := s.stderr.String(); stderr != "" {
+		return fmt.Errorf("%w: %s", err, stderr)
+	}
+	return err
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// SessionPool runs a session plugin in up to size processes. Processes
+// stay running between requests, so plugins can keep warm state.
+type SessionPool struct {
+	manifest PluginManifest
+	execPath string
+	host     *HostServices
+	sandbox  *Sandbox
+	slots    chan struct{}
+
+	mu     sync.Mutex
+	idle   []*Session
+	closed bool
+}
+
+// NewSessionPool creates a pool for the plugin. Processes are started on
+// demand.
+func NewSessionPool(manifest PluginManifest, execPath string, host *HostServices, size int) *SessionPool {
+	if size <= 0 {
+		size = DefaultSessionPoolSize
+	}
+	return &SessionPool{
+		manifest: manifest,
+		execPath: execPath,
+		host:     host,
+		slots:    make(chan struct{}, size),
+	}
+}
+
+// Execute runs input on an idle process, starting one if none is idle,
+// and waits if size processes are busy.
+func (p *SessionPool) Execute(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	session, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := session.Execute(ctx, input)
+	p.release(session)
+	return output, err
+}
+
+// Close shuts down idle processes. Busy processes are shut down when their
+// request finishes.
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, session := range idle {
+		_ = session.Close()
+	}
+	return nil
+}
+
+// acquire returns an idle live session or starts a new one.
+func (p *SessionPool) acquire(ctx context.Context) (*Session, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("plugin '%s' is shut down", p.manifest.Name)
+	}
+	for len(p.idle) > 0 {
+		session := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if session.Alive() {
+			p.mu.Unlock()
+			return session, nil
+		}
+		_ = session.Close()
+	}
+	p.mu.Unlock()
+
+	return StartSandboxedSession(ctx, p.manifest, p.execPath, p.host, p.sandbox)
+}
+
+// release returns a session to the pool, or closes it if it died or the
+// pool is closed.
+func (p *SessionPool) release(session *Session) {
+	p.mu.Lock()
+	if session.Alive() && !p.closed {
+		p.idle = append(p.idle, session)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	_ = session.Close()
+}
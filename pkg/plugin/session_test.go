@@ -0,0 +1,459 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/progress"
+)
+
+// sessionPluginEnv makes the test binary act as a session plugin.
+const sessionPluginEnv = "CLIFORGE_TEST_SESSION_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(sessionPluginEnv) != "" {
+		runSessionTestPlugin()
+		os.Exit(0)
+	}
+	if os.Getenv(sandboxPluginEnv) != "" {
+		runSandboxTestPlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runSessionTestPlugin is a session plugin whose behaviour is chosen by
+// the command of each execute request.
+func runSessionTestPlugin() {
+	var (
+		writeMu sync.Mutex
+		mu      sync.Mutex
+		nextID  int
+		pending = make(map[string]chan map[string]json.RawMessage)
+		count   int
+	)
+
+	send := func(msg map[string]interface{}) {
+		msg["jsonrpc"] = "2.0"
+		data, _ := json.Marshal(msg)
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, _ = os.Stdout.Write(append(data, '\n'))
+	}
+	callHost := func(method string, params interface{}) map[string]json.RawMessage {
+		mu.Lock()
+		nextID++
+		id := fmt.Sprintf("p%d", nextID)
+		reply := make(chan map[string]json.RawMessage, 1)
+		pending[id] = reply
+		mu.Unlock()
+		send(map[string]interface{}{"id": id, "method": method, "params": params})
+		return <-reply
+	}
+
+	execute := func(id json.RawMessage, input *PluginInput) {
+		result := map[string]interface{}{"exit_code": 0}
+		data := map[string]interface{}{}
+		result["data"] = data
+
+		switch input.Command {
+		case "count":
+			mu.Lock()
+			count++
+			data["count"] = count
+			data["pid"] = os.Getpid()
+			mu.Unlock()
+		case "log":
+			send(map[string]interface{}{"method": "log", "params": map[string]string{"level": "warn", "message": input.Data["message"].(string)}})
+		case "progress":
+			for i := 1; i <= 3; i++ {
+				send(map[string]interface{}{"method": "progress", "params": map[string]interface{}{"message": "working", "current": i, "total": 3}})
+			}
+		case "call":
+			response := callHost(input.Data["method"].(string), input.Data["params"])
+			for key, value := range response {
+				var decoded interface{}
+				_ = json.Unmarshal(value, &decoded)
+				data[key] = decoded
+			}
+		case "fail":
+			result["exit_code"] = 1
+			result["error"] = "failed on purpose"
+		case "rate-limited":
+			send(map[string]interface{}{"id": id, "error": map[string]interface{}{
+				"code": -32000, "message": "rate limited",
+				"data": map[string]interface{}{"suggestion": "Wait a minute", "recoverable": true},
+			}})
+			return
+		case "crash":
+			os.Exit(3)
+		case "hang":
+			select {}
+		}
+		send(map[string]interface{}{"id": id, "result": result})
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			os.Exit(2)
+		}
+
+		var method string
+		_ = json.Unmarshal(msg["method"], &method)
+		switch method {
+		case "initialize":
+			version := 1
+			if v := os.Getenv("CLIFORGE_TEST_SESSION_VERSION"); v != "" {
+				version, _ = strconv.Atoi(v)
+			}
+			send(map[string]interface{}{"id": msg["id"], "result": map[string]interface{}{
+				"protocol_version": version, "name": "session-test", "version": "1.0.0",
+			}})
+		case "execute":
+			var input PluginInput
+			_ = json.Unmarshal(msg["params"], &input)
+			go execute(msg["id"], &input)
+		case "shutdown":
+			send(map[string]interface{}{"id": msg["id"], "result": map[string]interface{}{}})
+			return
+		case "":
+			var id string
+			_ = json.Unmarshal(msg["id"], &id)
+			mu.Lock()
+			reply := pending[id]
+			delete(pending, id)
+			mu.Unlock()
+			if reply != nil {
+				reply <- msg
+			}
+		}
+	}
+}
+
+// sessionTestManifest returns the manifest of the test plugin.
+func sessionTestManifest(t *testing.T, permissions ...Permission) (PluginManifest, string) {
+	t.Helper()
+	t.Setenv(sessionPluginEnv, "1")
+
+	execPath, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	return PluginManifest{
+		Name:        "session-test",
+		Version:     "1.0.0",
+		Type:        PluginTypeBinary,
+		Executable:  execPath,
+		Protocol:    PluginProtocolSession,
+		Permissions: permissions,
+	}, execPath
+}
+
+func TestSession_Handshake(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+
+	session, err := StartSession(context.Background(), manifest, execPath, nil)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	info := session.Info()
+	if info.ProtocolVersion != 1 || info.Name != "session-test" || info.Version != "1.0.0" {
+		t.Errorf("Info() = %+v", info)
+	}
+
+	t.Setenv("CLIFORGE_TEST_SESSION_VERSION", "99")
+	_, err = StartSession(context.Background(), manifest, execPath, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported protocol version 99") {
+		t.Errorf("StartSession() with unsupported version error = %v", err)
+	}
+}
+
+func TestSessionPool_ReusesProcesses(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+	pool := NewSessionPool(manifest, execPath, nil, 1)
+	defer func() { _ = pool.Close() }()
+
+	var pids []int
+	for i := 1; i <= 3; i++ {
+		output, err := pool.Execute(context.Background(), &PluginInput{Command: "count"})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if count, _ := output.GetInt("count"); count != i {
+			t.Errorf("count = %d, want %d; state was not kept between requests", count, i)
+		}
+		pid, _ := output.GetInt("pid")
+		pids = append(pids, pid)
+	}
+	if pids[0] != pids[1] || pids[1] != pids[2] {
+		t.Errorf("pids = %v, want one process", pids)
+	}
+
+	// A failed request does not end the session.
+	output, err := pool.Execute(context.Background(), &PluginInput{Command: "fail"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if output.Success() || output.Error != "failed on purpose" {
+		t.Errorf("output = %+v, want failure", output)
+	}
+
+	// A crashed process is replaced.
+	if _, err := pool.Execute(context.Background(), &PluginInput{Command: "crash"}); err == nil {
+		t.Error("Execute() of a crashing plugin succeeded")
+	}
+	output, err = pool.Execute(context.Background(), &PluginInput{Command: "count"})
+	if err != nil {
+		t.Fatalf("Execute() after crash error = %v", err)
+	}
+	if count, _ := output.GetInt("count"); count != 1 {
+		t.Errorf("count after crash = %d, want 1 from a new process", count)
+	}
+}
+
+func TestSessionPool_Concurrency(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+	pool := NewSessionPool(manifest, execPath, nil, 2)
+	defer func() { _ = pool.Close() }()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	pids := make(map[int]bool)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := pool.Execute(context.Background(), &PluginInput{Command: "count"})
+			if err != nil {
+				t.Errorf("Execute() error = %v", err)
+				return
+			}
+			pid, _ := output.GetInt("pid")
+			mu.Lock()
+			pids[pid] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(pids) > 2 {
+		t.Errorf("pool of 2 ran %d processes", len(pids))
+	}
+}
+
+func TestSession_Timeout(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+	pool := NewSessionPool(manifest, execPath, nil, 1)
+	defer func() { _ = pool.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := pool.Execute(ctx, &PluginInput{Command: "hang"})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Execute() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() took %v to stop", elapsed)
+	}
+
+	if _, err := pool.Execute(context.Background(), &PluginInput{Command: "count"}); err != nil {
+		t.Errorf("Execute() after timeout error = %v", err)
+	}
+}
+
+func TestSession_HostMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+		fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	manifest, execPath := sessionTestManifest(t,
+		Permission{Type: PermissionNetwork, Resource: "127.0.0.1"},
+		Permission{Type: PermissionCredential, Resource: "api-*"},
+		Permission{Type: PermissionReadState, Resource: "*"},
+		Permission{Type: PermissionWriteState, Resource: "cursor"},
+	)
+
+	var log strings.Builder
+	var logMu sync.Mutex
+	host := &HostServices{
+		HTTPClient: server.Client(),
+		Secrets:    secretStoreFunc(func(key string) (string, error) { return "s3cr3t-" + key, nil }),
+		State:      NewFileStateStore(t.TempDir()),
+		Log:        lockedWriter{&logMu, &log},
+	}
+
+	session, err := StartSession(context.Background(), manifest, execPath, host)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	call := func(method string, params map[string]interface{}) *PluginOutput {
+		t.Helper()
+		output, err := session.Execute(context.Background(), &PluginInput{
+			Command: "call",
+			Data:    map[string]interface{}{"method": method, "params": params},
+		})
+		if err != nil {
+			t.Fatalf("Execute(%s) error = %v", method, err)
+		}
+		return output
+	}
+	denied := func(output *PluginOutput) bool {
+		errMap, _ := output.GetMap("error")
+		code, _ := errMap["code"].(float64)
+		return int(code) == rpcPermissionDenied
+	}
+
+	output := call("http.request", map[string]interface{}{
+		"method": "POST", "url": server.URL + "/things", "headers": map[string]string{"X-Test": "yes"},
+	})
+	result, _ := output.GetMap("result")
+	if result["status"] != float64(200) || result["body"] != "POST /things" {
+		t.Errorf("http.request result = %v", output.Data)
+	}
+
+	output = call("http.request", map[string]interface{}{"url": "http://example.com/"})
+	if !denied(output) {
+		t.Errorf("http.request to an ungranted host = %v, want permission denied", output.Data)
+	}
+
+	output = call("secrets.get", map[string]interface{}{"key": "api-token"})
+	if result, _ := output.GetMap("result"); result["value"] != "s3cr3t-api-token" {
+		t.Errorf("secrets.get result = %v", output.Data)
+	}
+	if output := call("secrets.get", map[string]interface{}{"key": "db-password"}); !denied(output) {
+		t.Errorf("secrets.get of an ungranted key = %v, want permission denied", output.Data)
+	}
+
+	if output := call("state.set", map[string]interface{}{"key": "cursor", "value": 42}); output.Data["error"] != nil {
+		t.Errorf("state.set error = %v", output.Data["error"])
+	}
+	output = call("state.get", map[string]interface{}{"key": "cursor"})
+	if result, _ := output.GetMap("result"); result["value"] != float64(42) || result["found"] != true {
+		t.Errorf("state.get result = %v", output.Data)
+	}
+	if output := call("state.set", map[string]interface{}{"key": "other", "value": 1}); !denied(output) {
+		t.Errorf("state.set of an ungranted key = %v, want permission denied", output.Data)
+	}
+
+	if output := call("fs.delete", nil); output.Data["error"] == nil {
+		t.Errorf("unknown method = %v, want an error", output.Data)
+	}
+
+	// Log notifications are written with secrets masked.
+	if _, err := session.Execute(context.Background(), &PluginInput{
+		Command: "log",
+		Data:    map[string]interface{}{"message": "using s3cr3t-api-token"},
+	}); err != nil {
+		t.Fatalf("Execute(log) error = %v", err)
+	}
+	logMu.Lock()
+	got := log.String()
+	logMu.Unlock()
+	if got != "[session-test] warn: using ***\n" {
+		t.Errorf("log = %q", got)
+	}
+}
+
+func TestBinaryPlugin_Session(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+	p := NewBinaryPlugin(manifest, execPath)
+	defer func() { _ = p.Close() }()
+
+	for i := 1; i <= 2; i++ {
+		output, err := p.Execute(context.Background(), &PluginInput{Command: "count"})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if count, _ := output.GetInt("count"); count != i {
+			t.Errorf("count = %d, want %d", count, i)
+		}
+	}
+}
+
+func TestBinaryPlugin_StructuredError(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+	p := NewBinaryPlugin(manifest, execPath)
+	defer func() { _ = p.Close() }()
+
+	_, err := p.Execute(context.Background(), &PluginInput{Command: "rate-limited"})
+	var pluginErr *PluginError
+	if !errors.As(err, &pluginErr) {
+		t.Fatalf("Execute() error = %v, want a PluginError", err)
+	}
+	if pluginErr.Suggestion != "Wait a minute" || !pluginErr.Recoverable {
+		t.Errorf("PluginError = %+v, want suggestion and recoverable", pluginErr)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "rate limited" {
+		t.Errorf("Expected the RPC error as cause, got %v", err)
+	}
+}
+
+type secretStoreFunc func(key string) (string, error)
+
+func (f secretStoreFunc) GetSecret(key string) (string, error) {
+	return f(key)
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *strings.Builder
+}
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func TestSession_Progress(t *testing.T) {
+	manifest, execPath := sessionTestManifest(t)
+
+	var out strings.Builder
+	var outMu sync.Mutex
+	config := progress.DefaultConfig()
+	config.Type = progress.TypeBar
+	config.Writer = lockedWriter{&outMu, &out}
+	manager := progress.NewManager(config)
+
+	session, err := StartSession(context.Background(), manifest, execPath, &HostServices{Progress: manager})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	if _, err := session.Execute(context.Background(), &PluginInput{Command: "progress"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Notifications are processed in order, before the response.
+	if current := manager.GetCurrentProgress(); current != nil && current.IsActive() {
+		t.Error("progress started by the plugin is still active after execute")
+	}
+	outMu.Lock()
+	defer outMu.Unlock()
+	if !strings.Contains(out.String(), "working") {
+		t.Errorf("progress output = %q, want the plugin's message", out.String())
+	}
+}
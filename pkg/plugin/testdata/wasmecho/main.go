@@ -0,0 +1,55 @@
+// Command wasmecho is a WASM plugin used by the WASM runtime tests. Build
+// it with GOOS=wasip1 GOARCH=wasm.
+//
+// It reports the input it received, the environment it can see, the
+// contents of input.Files["read"], and whether it could write to
+// input.Files["write"].
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+type request struct {
+	Params struct {
+		Args  []string          `json:"args"`
+		Files map[string]string `json:"files"`
+		Data  map[string]any    `json:"data"`
+	} `json:"params"`
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(2)
+	}
+
+	data := map[string]any{
+		"args": os.Args[1:],
+		"env":  os.Environ(),
+		"echo": req.Params.Data,
+	}
+	if path, ok := req.Params.Files["read"]; ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			data["read_error"] = err.Error()
+		} else {
+			data["read"] = string(content)
+		}
+	}
+	if path, ok := req.Params.Files["write"]; ok {
+		if err := os.WriteFile(path, []byte("written"), 0o600); err != nil {
+			data["write_error"] = err.Error()
+		} else {
+			data["written"] = true
+		}
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      "1",
+		"result":  map[string]any{"exit_code": 0, "data": data},
+	})
+}
@@ -0,0 +1,425 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/sysfs"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// DefaultWASMMemoryLimitPages is the default memory limit of WASM plugins:
+// 4096 pages of 64 KiB, or 256 MiB.
+const DefaultWASMMemoryLimitPages = 4096
+
+// DefaultWASMModule is the module file loaded when a WASM plugin's manifest
+// does not name one.
+const DefaultWASMModule = "plugin.wasm"
+
+// WASMConfig limits the resources available to WASM plugins.
+type WASMConfig struct {
+	// MemoryLimitPages is the most 64 KiB pages a module's memory may
+	// grow to. Modules declaring more fail to load.
+	MemoryLimitPages uint32
+
+	// Timeout bounds executions whose context has no deadline. Executor
+	// gives every execution a deadline, so this only applies to plugins
+	// run directly.
+	Timeout time.Duration
+
+	// CacheDir keeps compiled modules between runs, typically the CLI's
+	// cache directory plus "wasm". Compiling a large module takes seconds,
+	// so leaving it empty makes every run pay that cost again.
+	CacheDir string
+}
+
+// DefaultWASMConfig returns the default WASM plugin limits.
+func DefaultWASMConfig() WASMConfig {
+	return WASMConfig{
+		MemoryLimitPages: DefaultWASMMemoryLimitPages,
+		Timeout:          30 * time.Second,
+	}
+}
+
+// WASMPlugin runs a WebAssembly module with a pure-Go runtime.
+//
+// The module is a WASI command: it reads the same JSON-RPC "execute"
+// request as a binary plugin from stdin and writes its response to stdout.
+// Each execution gets a fresh instance, so no state survives between calls.
+//
+// The module can only reach what the manifest's permissions grant:
+// read:file and write:file resources are mounted read-only and read-write,
+// read:env resources select which host environment variables it sees, and
+// it has no network access or ability to run processes.
+type WASMPlugin struct {
+	manifest   PluginManifest
+	modulePath string
+	config     WASMConfig
+
+	mu       sync.Mutex
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// NewWASMPlugin creates a new WASM plugin for the module at modulePath.
+func NewWASMPlugin(manifest PluginManifest, modulePath string, config WASMConfig) *WASMPlugin {
+	defaults := DefaultWASMConfig()
+	if config.MemoryLimitPages == 0 {
+		config.MemoryLimitPages = defaults.MemoryLimitPages
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	return &WASMPlugin{
+		manifest:   manifest,
+		modulePath: modulePath,
+		config:     config,
+	}
+}
+
+// Execute instantiates the module and runs its entrypoint with input.
+func (p *WASMPlugin) Execute(ctx context.Context, input *PluginInput) (*PluginOutput, error) {
+	runtime, compiled, err := p.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := wasmCapabilitiesFor(p.manifest.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+
+	request, err := marshalExecuteRequest(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	config := wazero.NewModuleConfig().
+		// An empty name lets several instances run at once.
+		WithName("").
+		WithArgs(append([]string{p.manifest.Name}, input.Args...)...).
+		WithStdin(bytes.NewReader(request)).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithSysNanosleep().
+		WithRandSource(rand.Reader).
+		WithFSConfig(caps.fsConfig()).
+		// Reactor modules export _initialize instead of _start; it is
+		// skipped when the module does not export it.
+		WithStartFunctions("_initialize", p.entrypoint())
+
+	for _, kv := range caps.environ(os.Environ(), input.Env) {
+		config = config.WithEnv(kv[0], kv[1])
+	}
+
+	startTime := time.Now()
+	mod, err := runtime.InstantiateModule(ctx, compiled, config)
+	duration := time.Since(startTime)
+	if mod != nil {
+		_ = mod.Close(context.Background())
+	}
+
+	exitCode := 0
+	if err != nil {
+		var exitErr *sys.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run module: %w", err)
+		}
+		switch exitErr.ExitCode() {
+		case sys.ExitCodeDeadlineExceeded, sys.ExitCodeContextCanceled:
+			return nil, fmt.Errorf("module stopped: %w", ctx.Err())
+		}
+		exitCode = int(exitErr.ExitCode())
+	}
+
+	output, err := parseExecuteResponse(stdout.Bytes(), stderr.Bytes(), exitCode, duration)
+	if err != nil {
+		return nil, executeError(p.manifest.Name, err)
+	}
+	return output, nil
+}
+
+// Validate loads the module and checks that it exports the entrypoint and
+// that every permission can be granted to it.
+func (p *WASMPlugin) Validate() error {
+	if _, err := wasmCapabilitiesFor(p.manifest.Permissions); err != nil {
+		return err
+	}
+
+	_, compiled, err := p.compile()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := compiled.ExportedFunctions()[p.entrypoint()]; !exists {
+		return fmt.Errorf("module %s does not export entrypoint %q", p.modulePath, p.entrypoint())
+	}
+
+	return nil
+}
+
+// Describe returns plugin information.
+func (p *WASMPlugin) Describe() *PluginInfo {
+	return &PluginInfo{
+		Manifest:     p.manifest,
+		Capabilities: []string{"execute", "sandboxed"},
+		Status:       PluginStatusReady,
+	}
+}
+
+// Close releases the runtime and compiled module.
+func (p *WASMPlugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.runtime == nil {
+		return nil
+	}
+	err := p.runtime.Close(context.Background())
+	p.runtime = nil
+	p.compiled = nil
+	return err
+}
+
+// entrypoint returns the function run on each execution.
+func (p *WASMPlugin) entrypoint() string {
+	if p.manifest.Entrypoint != "" {
+		return p.manifest.Entrypoint
+	}
+	return "_start"
+}
+
+// compile creates the runtime and compiles the module on first use.
+func (p *WASMPlugin) compile() (wazero.Runtime, wazero.CompiledModule, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.compiled != nil {
+		return p.runtime, p.compiled, nil
+	}
+
+	code, err := os.ReadFile(p.modulePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	ctx := context.Background()
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(p.config.MemoryLimitPages).
+		WithCloseOnContextDone(true)
+	if p.config.CacheDir != "" {
+		cache, err := wazero.NewCompilationCacheWithDir(p.config.CacheDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open compilation cache: %w", err)
+		}
+		runtimeConfig = runtimeConfig.WithCompilationCache(cache)
+	}
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, nil, fmt.Errorf("failed to set up WASI: %w", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, nil, fmt.Errorf("failed to compile module %s: %w", p.modulePath, err)
+	}
+
+	p.runtime = runtime
+	p.compiled = compiled
+	return runtime, compiled, nil
+}
+
+// wasmCapabilities is what a WASM module may reach, derived from its
+// manifest's permissions.
+type wasmCapabilities struct {
+	// mounts are the host directories holding what was granted.
+	mounts []*wasmMount
+
+	// env are patterns of host environment variables the module may read.
+	env []string
+}
+
+// wasmCapabilitiesFor maps permissions to capabilities. Permissions that
+// cannot be granted to a module are an error rather than silently ignored.
+func wasmCapabilitiesFor(permissions []Permission) (*wasmCapabilities, error) {
+	caps := &wasmCapabilities{}
+
+	for _, perm := range permissions {
+		switch perm.Type {
+		case PermissionReadFile, PermissionWriteFile:
+			path, isDir, err := permissionPath(perm.Resource)
+			if err != nil {
+				return nil, fmt.Errorf("invalid permission %s: %w", perm.String(), err)
+			}
+			access := accessRead
+			if perm.Type == PermissionWriteFile {
+				access = accessWrite
+			}
+			if isDir {
+				m := caps.mount(path)
+				m.access = max(m.access, access)
+			} else {
+				m := caps.mount(filepath.Dir(path))
+				m.files[filepath.Base(path)] = max(m.files[filepath.Base(path)], access)
+			}
+
+		case PermissionReadEnv:
+			caps.env = appendUnique(caps.env, perm.Resource)
+
+		case PermissionExecute, PermissionNetwork:
+			return nil, fmt.Errorf("permission %s cannot be granted to WASM plugins; use a binary plugin instead", perm.String())
+
+		default:
+			// write:env and credential give the module nothing it could
+			// reach from inside the sandbox.
+		}
+	}
+
+	return caps, nil
+}
+
+// mount returns the mount of a host directory, adding it if needed.
+func (c *wasmCapabilities) mount(dir string) *wasmMount {
+	for _, m := range c.mounts {
+		if m.dir == dir {
+			return m
+		}
+	}
+	m := &wasmMount{dir: dir, files: make(map[string]fileAccess)}
+	c.mounts = append(c.mounts, m)
+	return m
+}
+
+// fsConfig mounts the granted directories at the same path in the guest.
+// Directories holding single granted files only expose those files.
+func (c *wasmCapabilities) fsConfig() wazero.FSConfig {
+	config := wazero.NewFSConfig()
+	for _, m := range c.mounts {
+		guest := filepath.ToSlash(m.dir)
+		switch {
+		case len(m.files) > 0:
+			config = config.(sysfs.FSConfig).WithSysFSMount(&grantFS{FS: sysfs.DirFS(m.dir), mount: m}, guest)
+		case m.access == accessWrite:
+			config = config.WithDirMount(m.dir, guest)
+		default:
+			config = config.WithReadOnlyDirMount(m.dir, guest)
+		}
+	}
+	return config
+}
+
+// environ returns the environment of the module: host variables matching a
+// read:env pattern, overridden by those passed in the input.
+func (c *wasmCapabilities) environ(host []string, input map[string]string) [][2]string {
+	values := make(map[string]string)
+	var keys []string
+	set := func(key, value string) {
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+
+	for _, kv := range host {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		for _, pattern := range c.env {
+			if MatchPermission(pattern, key) {
+				set(key, value)
+				break
+			}
+		}
+	}
+	for key, value := range input {
+		set(key, value)
+	}
+
+	env := make([][2]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, [2]string{key, values[key]})
+	}
+	return env
+}
+
+// permissionPath returns the host path a file permission resource covers,
+// and whether it is a directory: the root of a glob like ~/.app/*, or a
+// single file or directory. Wildcards that do not follow a directory,
+// like * or ~/.app*, cannot be narrowed to one path and are an error.
+func permissionPath(resource string) (path string, isDir bool, err error) {
+	if resource == "" {
+		return "", false, fmt.Errorf("a path is required")
+	}
+
+	// Everything from the first wildcard on only narrows what the
+	// directory before it covers.
+	path = resource
+	if i := strings.Index(path, "*"); i >= 0 {
+		path = path[:i]
+		if !strings.HasSuffix(path, "/") && !strings.HasSuffix(path, string(filepath.Separator)) {
+			return "", false, fmt.Errorf("%s cannot be narrowed to a file or directory; wildcards must follow a directory", resource)
+		}
+		isDir = true
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !isDir {
+		info, err := os.Stat(path)
+		isDir = err == nil && info.IsDir()
+	}
+	return path, isDir, nil
+}
+
+// appendUnique appends s to list unless it is already there.
+func appendUnique(list []string, s string) []string {
+	if containsString(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// containsString checks if s is in list.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	experimentalsys "github.com/tetratelabs/wazero/experimental/sys"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// fileAccess is what a module may do with a mounted file or directory.
+type fileAccess int
+
+const (
+	accessNone fileAccess = iota
+	accessRead
+	accessWrite
+)
+
+// wasmMount is a host directory mounted at the same path in the guest.
+type wasmMount struct {
+	dir string
+
+	// access applies to everything in the directory, and files to single
+	// files directly in it that were granted on their own.
+	access fileAccess
+	files  map[string]fileAccess
+}
+
+// grantFS limits a mounted directory to what was granted: the files
+// granted on their own, and everything in the directory when the
+// directory itself is granted. Other entries do not exist for the module,
+// and read-only entries cannot be changed.
+type grantFS struct {
+	experimentalsys.FS
+	mount *wasmMount
+}
+
+// cleanMountPath returns a path relative to a mount that stays within it:
+// "." for the mount itself.
+func cleanMountPath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// access returns what the module may do with a cleaned path. The mount
+// itself is readable so it can be opened and listed.
+func (g *grantFS) access(p string) fileAccess {
+	switch {
+	case p == ".":
+		return max(g.mount.access, accessRead)
+	case strings.Contains(p, "/"):
+		return g.mount.access
+	default:
+		return max(g.mount.access, g.mount.files[p])
+	}
+}
+
+// check cleans p, returning the error for an operation on it that needs
+// want.
+func (g *grantFS) check(p string, want fileAccess) (string, experimentalsys.Errno) {
+	p = cleanMountPath(p)
+	switch access := g.access(p); {
+	case access >= want:
+		return p, 0
+	case access == accessNone:
+		return p, experimentalsys.ENOENT
+	default:
+		return p, experimentalsys.EROFS
+	}
+}
+
+// OpenFile implements the same method as documented on sys.FS
+func (g *grantFS) OpenFile(p string, flag experimentalsys.Oflag, perm fs.FileMode) (experimentalsys.File, experimentalsys.Errno) {
+	want := accessRead
+	if flag&(experimentalsys.O_WRONLY|experimentalsys.O_RDWR|experimentalsys.O_CREAT|experimentalsys.O_TRUNC|experimentalsys.O_APPEND) != 0 {
+		want = accessWrite
+	}
+	p, errno := g.check(p, want)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	f, errno := g.FS.OpenFile(p, flag, perm)
+	if errno != 0 {
+		return nil, errno
+	}
+	if p == "." && g.mount.access == accessNone {
+		return &grantDir{File: f, fs: g}, 0
+	}
+	return f, 0
+}
+
+// Lstat implements the same method as documented on sys.FS
+func (g *grantFS) Lstat(p string) (sys.Stat_t, experimentalsys.Errno) {
+	p, errno := g.check(p, accessRead)
+	if errno != 0 {
+		return sys.Stat_t{}, errno
+	}
+	return g.FS.Lstat(p)
+}
+
+// Stat implements the same method as documented on sys.FS
+func (g *grantFS) Stat(p string) (sys.Stat_t, experimentalsys.Errno) {
+	p, errno := g.check(p, accessRead)
+	if errno != 0 {
+		return sys.Stat_t{}, errno
+	}
+	return g.FS.Stat(p)
+}
+
+// Readlink implements the same method as documented on sys.FS
+func (g *grantFS) Readlink(p string) (string, experimentalsys.Errno) {
+	p, errno := g.check(p, accessRead)
+	if errno != 0 {
+		return "", errno
+	}
+	return g.FS.Readlink(p)
+}
+
+// Mkdir implements the same method as documented on sys.FS
+func (g *grantFS) Mkdir(p string, perm fs.FileMode) experimentalsys.Errno {
+	p, errno := g.check(p, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Mkdir(p, perm)
+}
+
+// Chmod implements the same method as documented on sys.FS
+func (g *grantFS) Chmod(p string, perm fs.FileMode) experimentalsys.Errno {
+	p, errno := g.check(p, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Chmod(p, perm)
+}
+
+// Rename implements the same method as documented on sys.FS
+func (g *grantFS) Rename(from, to string) experimentalsys.Errno {
+	from, errno := g.check(from, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	to, errno = g.check(to, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Rename(from, to)
+}
+
+// Rmdir implements the same method as documented on sys.FS
+func (g *grantFS) Rmdir(p string) experimentalsys.Errno {
+	p, errno := g.check(p, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Rmdir(p)
+}
+
+// Unlink implements the same method as documented on sys.FS
+func (g *grantFS) Unlink(p string) experimentalsys.Errno {
+	p, errno := g.check(p, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Unlink(p)
+}
+
+// Link implements the same method as documented on sys.FS. Links could
+// make a granted name reach a file that was not granted.
+func (g *grantFS) Link(_, _ string) experimentalsys.Errno {
+	return experimentalsys.EPERM
+}
+
+// Symlink implements the same method as documented on sys.FS. Links could
+// make a granted name reach a file that was not granted.
+func (g *grantFS) Symlink(_, _ string) experimentalsys.Errno {
+	return experimentalsys.EPERM
+}
+
+// Utimens implements the same method as documented on sys.FS
+func (g *grantFS) Utimens(p string, atim, mtim int64) experimentalsys.Errno {
+	p, errno := g.check(p, accessWrite)
+	if errno != 0 {
+		return errno
+	}
+	return g.FS.Utimens(p, atim, mtim)
+}
+
+// grantDir is a mounted directory that was not granted itself, listing
+// only the files granted in it.
+type grantDir struct {
+	experimentalsys.File
+	fs *grantFS
+}
+
+// Readdir implements the same method as documented on sys.File
+func (d *grantDir) Readdir(n int) ([]experimentalsys.Dirent, experimentalsys.Errno) {
+	var dirents []experimentalsys.Dirent
+	for {
+		want := n - len(dirents)
+		read, errno := d.File.Readdir(want)
+		if errno != 0 {
+			return nil, errno
+		}
+		for _, dirent := range read {
+			if d.fs.access(dirent.Name) != accessNone {
+				dirents = append(dirents, dirent)
+			}
+		}
+		// Keep reading until n are listed or the directory is exhausted
+		if n <= 0 || len(read) < want || len(dirents) == n {
+			return dirents, 0
+		}
+	}
+}
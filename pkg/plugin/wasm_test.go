@@ -0,0 +1,400 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wasmModule assembles a minimal module exporting _start. The function
+// loops forever if loop is set, and the module declares a memory of
+// memoryPages pages if it is non-zero.
+func wasmModule(loop bool, memoryPages byte) []byte {
+	module := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+		0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type: () -> ()
+		0x03, 0x02, 0x01, 0x00, // function 0 has type 0
+	}
+	if memoryPages > 0 {
+		module = append(module, 0x05, 0x03, 0x01, 0x00, memoryPages) // memory: min pages
+	}
+	module = append(module, 0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00) // export _start
+	if loop {
+		module = append(module, 0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b) // loop br 0 end
+	} else {
+		module = append(module, 0x0a, 0x04, 0x01, 0x02, 0x00, 0x0b)
+	}
+	return module
+}
+
+func writeWASMModule(t *testing.T, code []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.wasm")
+	if err := os.WriteFile(path, code, 0644); err != nil {
+		t.Fatalf("failed to write module: %v", err)
+	}
+	return path
+}
+
+// buildWASMEcho compiles testdata/wasmecho, skipping the test if the Go
+// toolchain cannot target wasip1.
+func buildWASMEcho(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping WASM build in short mode")
+	}
+
+	path := filepath.Join(t.TempDir(), "wasmecho.wasm")
+	cmd := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-o", path, "./testdata/wasmecho")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build wasip1 module: %v\n%s", err, out)
+	}
+	return path
+}
+
+// wasmTestCacheDir returns a compilation cache shared by test runs, so the
+// echo module is only compiled once per toolchain.
+func wasmTestCacheDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cliforge-test", "wasm")
+}
+
+func TestWASMPlugin_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       []byte
+		manifest   PluginManifest
+		wantErr    string
+		memoryPage uint32
+	}{
+		{
+			name:     "valid",
+			code:     wasmModule(false, 1),
+			manifest: PluginManifest{Name: "ok", Entrypoint: "_start"},
+		},
+		{
+			name:     "missing entrypoint",
+			code:     wasmModule(false, 0),
+			manifest: PluginManifest{Name: "missing", Entrypoint: "run"},
+			wantErr:  `does not export entrypoint "run"`,
+		},
+		{
+			name:       "memory over limit",
+			code:       wasmModule(false, 100),
+			manifest:   PluginManifest{Name: "greedy", Entrypoint: "_start"},
+			memoryPage: 10,
+			wantErr:    "over limit",
+		},
+		{
+			name:    "invalid module",
+			code:    []byte("not wasm"),
+			wantErr: "failed to compile module",
+		},
+		{
+			name: "network permission",
+			code: wasmModule(false, 0),
+			manifest: PluginManifest{Name: "net", Permissions: []Permission{
+				{Type: PermissionNetwork, Resource: "api.example.com"},
+			}},
+			wantErr: "cannot be granted to WASM plugins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewWASMPlugin(tt.manifest, writeWASMModule(t, tt.code), WASMConfig{MemoryLimitPages: tt.memoryPage})
+			defer func() { _ = p.Close() }()
+
+			err := p.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWASMPlugin_Timeout(t *testing.T) {
+	p := NewWASMPlugin(PluginManifest{Name: "spin", Entrypoint: "_start"}, writeWASMModule(t, wasmModule(true, 0)), WASMConfig{})
+	defer func() { _ = p.Close() }()
+
+	executor := NewExecutor(100*time.Millisecond, time.Second)
+	start := time.Now()
+	_, err := executor.Execute(context.Background(), p, &PluginInput{})
+	if err == nil {
+		t.Fatal("Execute() of an endless loop succeeded")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Execute() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Execute() took %v to stop", elapsed)
+	}
+}
+
+func TestWASMPlugin_Execute(t *testing.T) {
+	module := buildWASMEcho(t)
+
+	readDir := t.TempDir()
+	writeDir := t.TempDir()
+	hiddenDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(readDir, "input.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hiddenDir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("WASMTEST_ALLOWED", "yes")
+	t.Setenv("WASMTEST_DENIED", "no")
+
+	manifest := PluginManifest{
+		Name:       "echo",
+		Type:       PluginTypeWASM,
+		Entrypoint: "_start",
+		Permissions: []Permission{
+			{Type: PermissionReadFile, Resource: readDir + "/*"},
+			{Type: PermissionWriteFile, Resource: writeDir + "/*"},
+			{Type: PermissionReadEnv, Resource: "WASMTEST_ALLOWED"},
+		},
+	}
+	p := NewWASMPlugin(manifest, module, WASMConfig{CacheDir: wasmTestCacheDir(t)})
+	defer func() { _ = p.Close() }()
+
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	run := func(files map[string]string) *PluginOutput {
+		t.Helper()
+		output, err := p.Execute(context.Background(), &PluginInput{
+			Args:  []string{"--flag"},
+			Env:   map[string]string{"FROM_INPUT": "1"},
+			Data:  map[string]interface{}{"key": "value"},
+			Files: files,
+		})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !output.Success() {
+			t.Fatalf("Execute() failed: %+v", output)
+		}
+		return output
+	}
+
+	output := run(map[string]string{
+		"read":  filepath.Join(readDir, "input.txt"),
+		"write": filepath.Join(writeDir, "output.txt"),
+	})
+
+	if got, _ := output.GetString("read"); got != "hello" {
+		t.Errorf("read = %q, want hello (data: %v)", got, output.Data)
+	}
+	if written, _ := output.GetBool("written"); !written {
+		t.Errorf("write to granted directory failed: %v", output.Data["write_error"])
+	}
+	if content, err := os.ReadFile(filepath.Join(writeDir, "output.txt")); err != nil || string(content) != "written" {
+		t.Errorf("output.txt = %q, %v", content, err)
+	}
+	if echo, _ := output.GetMap("echo"); echo["key"] != "value" {
+		t.Errorf("echo = %v, want input data", echo)
+	}
+	if args, _ := output.Data["args"].([]interface{}); len(args) != 1 || args[0] != "--flag" {
+		t.Errorf("args = %v, want [--flag]", output.Data["args"])
+	}
+
+	env, _ := output.Data["env"].([]interface{})
+	envSet := make(map[string]bool)
+	for _, kv := range env {
+		envSet[kv.(string)] = true
+	}
+	if !envSet["WASMTEST_ALLOWED=yes"] || !envSet["FROM_INPUT=1"] {
+		t.Errorf("env = %v, want allowed and input variables", env)
+	}
+	if envSet["WASMTEST_DENIED=no"] {
+		t.Errorf("env = %v, includes a variable without read:env", env)
+	}
+
+	// Paths outside the grants are not reachable, and read-only grants
+	// cannot be written.
+	output = run(map[string]string{
+		"read":  filepath.Join(hiddenDir, "secret.txt"),
+		"write": filepath.Join(readDir, "output.txt"),
+	})
+	if _, ok := output.Data["read"]; ok {
+		t.Errorf("module read a file outside its grants")
+	}
+	if _, ok := output.Data["written"]; ok {
+		t.Errorf("module wrote to a read-only grant")
+	}
+	if _, err := os.Stat(filepath.Join(readDir, "output.txt")); !os.IsNotExist(err) {
+		t.Errorf("read-only directory was written: %v", err)
+	}
+}
+
+func TestWASMCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	caps, err := wasmCapabilitiesFor([]Permission{
+		{Type: PermissionReadFile, Resource: file},
+		{Type: PermissionReadFile, Resource: dir + "/sub/*"},
+		{Type: PermissionWriteFile, Resource: dir + "/*"},
+		{Type: PermissionReadEnv, Resource: "AWS_*"},
+		{Type: PermissionCredential},
+	})
+	if err != nil {
+		t.Fatalf("wasmCapabilitiesFor() error = %v", err)
+	}
+
+	if len(caps.mounts) != 2 {
+		t.Fatalf("mounts = %d, want 2", len(caps.mounts))
+	}
+	if m := caps.mounts[0]; m.dir != dir || m.access != accessWrite || m.files["config.json"] != accessRead {
+		t.Errorf("mounts[0] = %+v, want %s writable with config.json readable", m, dir)
+	}
+	if m := caps.mounts[1]; m.dir != filepath.Join(dir, "sub") || m.access != accessRead || len(m.files) != 0 {
+		t.Errorf("mounts[1] = %+v, want %s/sub readable", m, dir)
+	}
+
+	env := caps.environ(
+		[]string{"AWS_PROFILE=dev", "HOME=/root", "AWS_REGION=eu-west-1"},
+		map[string]string{"AWS_PROFILE": "prod"},
+	)
+	want := [][2]string{{"AWS_PROFILE", "prod"}, {"AWS_REGION", "eu-west-1"}}
+	if len(env) != len(want) {
+		t.Fatalf("environ() = %v, want %v", env, want)
+	}
+	for i := range want {
+		if env[i] != want[i] {
+			t.Errorf("environ()[%d] = %v, want %v", i, env[i], want[i])
+		}
+	}
+
+	if _, err := wasmCapabilitiesFor([]Permission{{Type: PermissionExecute, Resource: "aws"}}); err == nil {
+		t.Error("wasmCapabilitiesFor() granted execute")
+	}
+
+	// Wildcards that do not follow a directory cannot be narrowed
+	for _, resource := range []string{"*", "~/.aws*", dir + "/config*.json"} {
+		if _, err := wasmCapabilitiesFor([]Permission{{Type: PermissionReadFile, Resource: resource}}); err == nil {
+			t.Errorf("wasmCapabilitiesFor() granted read:file:%s", resource)
+		}
+	}
+}
+
+func TestWASMPlugin_FileGrants(t *testing.T) {
+	module := buildWASMEcho(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte("config"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "credentials"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Single files are granted without the directory holding them
+	manifest := PluginManifest{
+		Name:       "echo",
+		Type:       PluginTypeWASM,
+		Entrypoint: "_start",
+		Permissions: []Permission{
+			{Type: PermissionReadFile, Resource: filepath.Join(dir, "config")},
+			{Type: PermissionWriteFile, Resource: filepath.Join(dir, "output")},
+		},
+	}
+	p := NewWASMPlugin(manifest, module, WASMConfig{CacheDir: wasmTestCacheDir(t)})
+	defer func() { _ = p.Close() }()
+
+	run := func(files map[string]string) *PluginOutput {
+		t.Helper()
+		output, err := p.Execute(context.Background(), &PluginInput{Files: files})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !output.Success() {
+			t.Fatalf("Execute() failed: %+v", output)
+		}
+		return output
+	}
+
+	output := run(map[string]string{
+		"read":  filepath.Join(dir, "config"),
+		"write": filepath.Join(dir, "output"),
+	})
+	if got, _ := output.GetString("read"); got != "config" {
+		t.Errorf("read = %q, want config (data: %v)", got, output.Data)
+	}
+	if written, _ := output.GetBool("written"); !written {
+		t.Errorf("write to granted file failed: %v", output.Data["write_error"])
+	}
+
+	output = run(map[string]string{
+		"read":  filepath.Join(dir, "credentials"),
+		"write": filepath.Join(dir, "config"),
+	})
+	if _, ok := output.Data["read"]; ok {
+		t.Errorf("module read a file next to a granted one")
+	}
+	if _, ok := output.Data["written"]; ok {
+		t.Errorf("module wrote to a read-only file")
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "config")); string(content) != "config" {
+		t.Errorf("read-only file was written: %q", content)
+	}
+}
+
+func TestRegistry_LoadWASMPlugin(t *testing.T) {
+	pluginDir := t.TempDir()
+	dir := filepath.Join(pluginDir, "spin")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DefaultWASMModule), wasmModule(false, 1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "name: spin\nversion: 1.0.0\ntype: wasm\nentrypoint: _start\npermissions: []\n"
+	if err := os.WriteFile(filepath.Join(dir, "plugin-manifest.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry(pluginDir, nil)
+	if err := registry.DiscoverPlugins(); err != nil {
+		t.Fatalf("DiscoverPlugins() error = %v", err)
+	}
+
+	p, err := registry.Get("spin")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := p.(*WASMPlugin); !ok {
+		t.Fatalf("plugin is %T, want *WASMPlugin", p)
+	}
+
+	output, err := registry.Execute(context.Background(), "spin", &PluginInput{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !output.Success() {
+		t.Errorf("Execute() output = %+v, want success", output)
+	}
+}
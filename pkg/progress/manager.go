@@ -2,6 +2,7 @@ package progress
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -318,10 +319,10 @@ func NewWorkflowIntegration(manager *Manager) *WorkflowIntegration {
 
 // OnWorkflowStart is called when a workflow starts.
 func (w *WorkflowIntegration) OnWorkflowStart(wf *workflow.Workflow) error {
-	config := w.manager.config
+	config := *w.manager.config
 	config.Type = TypeSteps
 
-	multiStep := NewMultiStep(config)
+	multiStep := NewMultiStep(&config)
 
 	// Initialize steps
 	for _, step := range wf.Steps {
@@ -419,6 +420,148 @@ func (w *WorkflowIntegration) OnWorkflowComplete(success bool, message string) e
 	return multiStep.Failure(message)
 }
 
+var _ workflow.EventListener = (*WorkflowIntegration)(nil)
+
+// OnEvent updates the display from a workflow event. Register the
+// integration with workflow.Executor.AddEventListener after calling
+// OnWorkflowStart so the display follows the same events as the event log.
+// Events of called sub-workflows and of nested steps are ignored.
+func (w *WorkflowIntegration) OnEvent(event *workflow.Event) {
+	if event.ParentID != "" {
+		return
+	}
+
+	w.mu.RLock()
+	_, topLevel := w.stepMap[event.StepID]
+	w.mu.RUnlock()
+
+	switch event.Type {
+	case workflow.EventWorkflowCompleted:
+		_ = w.OnWorkflowComplete(true, "Workflow completed")
+		return
+	case workflow.EventWorkflowFailed:
+		_ = w.OnWorkflowComplete(false, "Workflow failed: "+event.Error)
+		return
+	}
+
+	if !topLevel {
+		return
+	}
+
+	switch event.Type {
+	case workflow.EventStepStarted:
+		_ = w.OnStepStart(event.StepID)
+	case workflow.EventStepRetried:
+		_ = w.OnStepRetry(event.StepID, event.Attempt)
+	case workflow.EventStepSucceeded:
+		_ = w.OnStepComplete(event.StepID)
+	case workflow.EventStepFailed:
+		_ = w.OnStepFail(event.StepID, errors.New(event.Error))
+	case workflow.EventStepSkipped:
+		_ = w.OnStepSkip(event.StepID)
+	}
+}
+
+// OnStepRetry is called before a step is attempted again.
+func (w *WorkflowIntegration) OnStepRetry(stepID string, attempt int) error {
+	w.mu.RLock()
+	multiStep := w.multiStep
+	w.mu.RUnlock()
+
+	if multiStep == nil {
+		return nil
+	}
+
+	return multiStep.UpdateStep(stepID, StepStatusRunning, fmt.Sprintf("Retrying (attempt %d)", attempt))
+}
+
+// RollbackReporter shows workflow compensations as a multi-step display.
+// It implements workflow.RollbackObserver.
+type RollbackReporter struct {
+	manager   *Manager
+	multiStep *MultiStep
+	mu        sync.Mutex
+}
+
+var _ workflow.RollbackObserver = (*RollbackReporter)(nil)
+
+// NewRollbackReporter creates a rollback reporter.
+func NewRollbackReporter(manager *Manager) *RollbackReporter {
+	return &RollbackReporter{manager: manager}
+}
+
+// OnRollbackStart is called with the compensations about to run.
+func (r *RollbackReporter) OnRollbackStart(records []*workflow.CompensationRecord) {
+	config := *r.manager.config
+	config.Type = TypeSteps
+
+	multiStep := NewMultiStep(&config)
+	for _, record := range records {
+		description := "Undo " + record.StepID
+		if record.Iteration > 0 {
+			description = fmt.Sprintf("Undo %s (iteration %d)", record.StepID, record.Iteration)
+		}
+		_ = multiStep.AddStep(&StepInfo{
+			ID:          record.ID,
+			Description: description,
+			Status:      StepStatusPending,
+		})
+	}
+
+	if err := multiStep.Start("Rolling back workflow..."); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.multiStep = multiStep
+	r.mu.Unlock()
+}
+
+// OnCompensationStart is called when a compensation starts.
+func (r *RollbackReporter) OnCompensationStart(record *workflow.CompensationRecord) {
+	if multiStep := r.current(); multiStep != nil {
+		_ = multiStep.UpdateStep(record.ID, StepStatusRunning, "")
+	}
+}
+
+// OnCompensationFinish is called when a compensation succeeds, fails or is
+// skipped.
+func (r *RollbackReporter) OnCompensationFinish(record *workflow.CompensationRecord) {
+	multiStep := r.current()
+	if multiStep == nil {
+		return
+	}
+
+	switch record.Status {
+	case workflow.CompensationSucceeded:
+		_ = multiStep.UpdateStep(record.ID, StepStatusCompleted, "")
+	case workflow.CompensationFailed:
+		_ = multiStep.UpdateStep(record.ID, StepStatusFailed, record.Error)
+	case workflow.CompensationSkipped:
+		_ = multiStep.UpdateStep(record.ID, StepStatusSkipped, "")
+	}
+}
+
+// OnRollbackComplete is called when all compensations have finished.
+func (r *RollbackReporter) OnRollbackComplete(report *workflow.RollbackReport) {
+	multiStep := r.current()
+	if multiStep == nil {
+		return
+	}
+
+	if incomplete := len(report.Incomplete()); incomplete > 0 {
+		_ = multiStep.Failure(fmt.Sprintf("Rollback incomplete: %d compensation(s) did not succeed", incomplete))
+		return
+	}
+	_ = multiStep.Success("Rollback completed")
+}
+
+func (r *RollbackReporter) current() *MultiStep {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.multiStep
+}
+
 // DefaultManager is the global default progress manager.
 var DefaultManager = NewManager(DefaultConfig())
 
@@ -1,6 +1,7 @@
 package progress
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -618,3 +619,66 @@ func TestManager_Concurrency(t *testing.T) {
 		t.Error("Manager should have no active progress after concurrent operations")
 	}
 }
+
+func TestRollbackReporter_Lifecycle(t *testing.T) {
+	manager := NewManager(&Config{
+		Type:    TypeSpinner,
+		Enabled: false,
+	})
+	reporter := NewRollbackReporter(manager)
+
+	records := []*workflow.CompensationRecord{
+		{ID: "create[2]", StepID: "create", Iteration: 2, Status: workflow.CompensationPending},
+		{ID: "create[1]", StepID: "create", Iteration: 1, Status: workflow.CompensationPending},
+	}
+
+	reporter.OnRollbackStart(records)
+	if reporter.multiStep == nil {
+		t.Fatal("MultiStep should be initialized")
+	}
+	if reporter.multiStep.config.Type != TypeSteps {
+		t.Errorf("Type = %s, want %s", reporter.multiStep.config.Type, TypeSteps)
+	}
+	if manager.config.Type != TypeSpinner {
+		t.Error("manager config should not be modified")
+	}
+
+	for _, record := range records {
+		reporter.OnCompensationStart(record)
+		record.Status = workflow.CompensationSucceeded
+		reporter.OnCompensationFinish(record)
+	}
+	reporter.OnRollbackComplete(&workflow.RollbackReport{Compensations: records})
+}
+
+func TestWorkflowIntegration_OnEvent(t *testing.T) {
+	var buf bytes.Buffer
+	manager := NewManager(&Config{Type: TypeSteps, Enabled: true, Writer: &buf})
+	integration := NewWorkflowIntegration(manager)
+
+	// Build the display without starting it so nothing is rendered.
+	integration.multiStep = NewMultiStep(manager.config)
+	for _, id := range []string{"create", "check"} {
+		info := &StepInfo{ID: id, Status: StepStatusPending}
+		integration.stepMap[id] = info
+		_ = integration.multiStep.AddStep(info)
+	}
+
+	events := []*workflow.Event{
+		{Type: workflow.EventStepStarted, StepID: "create"},
+		{Type: workflow.EventStepStarted, StepID: "nested"},
+		{Type: workflow.EventStepFailed, StepID: "create", ParentID: "call-step"},
+		{Type: workflow.EventStepSucceeded, StepID: "create"},
+		{Type: workflow.EventStepSkipped, StepID: "check"},
+	}
+	for _, event := range events {
+		integration.OnEvent(event)
+	}
+
+	if got := integration.stepMap["create"].Status; got != StepStatusCompleted {
+		t.Errorf("create status = %s, want %s", got, StepStatusCompleted)
+	}
+	if got := integration.stepMap["check"].Status; got != StepStatusSkipped {
+		t.Errorf("check status = %s, want %s", got, StepStatusSkipped)
+	}
+}
@@ -0,0 +1,174 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/CliForge/cliforge/pkg/secrets"
+)
+
+// Player is an http.RoundTripper that serves responses from fixtures.
+//
+// Each request is answered by the first unused fixture that matches it. When
+// every matching fixture has been used, the last one is served again, so a
+// polling loop recorded once keeps returning its final state.
+type Player struct {
+	fixtures []*Fixture
+	match    MatchConfig
+	detector *secrets.Detector
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// NewPlayer creates a player serving fixtures from dir. If detector is nil,
+// the default secret patterns are used when matching request bodies.
+func NewPlayer(dir string, match MatchConfig, detector *secrets.Detector) (*Player, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayerFromFixtures(fixtures, match, detector), nil
+}
+
+// NewPlayerFromFixtures creates a player serving the given fixtures.
+func NewPlayerFromFixtures(fixtures []*Fixture, match MatchConfig, detector *secrets.Detector) *Player {
+	if detector == nil {
+		detector = defaultDetector()
+	}
+
+	return &Player{
+		fixtures: fixtures,
+		match:    match,
+		detector: detector,
+		used:     make([]bool, len(fixtures)),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	fixture := p.find(req, body)
+	if fixture == nil {
+		return nil, fmt.Errorf("replay: no fixture matches %s %s", req.Method, req.URL.RequestURI())
+	}
+
+	resp := &http.Response{
+		StatusCode:    fixture.Response.Status,
+		Status:        fmt.Sprintf("%d %s", fixture.Response.Status, http.StatusText(fixture.Response.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Request:       req,
+		ContentLength: -1,
+	}
+	for key, values := range fixture.Response.Headers {
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+
+	respBody := rawBody(fixture.Response.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(respBody)))
+
+	return resp, nil
+}
+
+// find returns the fixture to serve for a request and marks it used.
+func (p *Player) find(req *http.Request, body []byte) *Fixture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lastMatch := -1
+	for i, fixture := range p.fixtures {
+		if !p.matches(fixture.Request, req, body) {
+			continue
+		}
+		if !p.used[i] {
+			p.used[i] = true
+			return fixture
+		}
+		lastMatch = i
+	}
+
+	if lastMatch >= 0 {
+		return p.fixtures[lastMatch]
+	}
+	return nil
+}
+
+// matches reports whether a recorded request matches a live one.
+func (p *Player) matches(recorded *FixtureRequest, req *http.Request, body []byte) bool {
+	if p.match.Method && recorded.Method != req.Method {
+		return false
+	}
+
+	if p.match.Path && recorded.Path != req.URL.Path {
+		return false
+	}
+
+	if p.match.Query {
+		recordedQuery := recorded.Query
+		if recordedQuery == nil {
+			recordedQuery = map[string][]string{}
+		}
+		liveQuery := p.detector.MaskQuery(req.URL.Query())
+		if !reflect.DeepEqual(recordedQuery, liveQuery) {
+			return false
+		}
+	}
+
+	if p.match.Body && !jsonEqual(recorded.Body, maskBody(p.detector, body)) {
+		return false
+	}
+
+	return true
+}
+
+// Unused returns the names of fixtures that were never served.
+func (p *Player) Unused() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var unused []string
+	for i, used := range p.used {
+		if !used {
+			unused = append(unused, p.fixtures[i].name)
+		}
+	}
+	return unused
+}
+
+// jsonEqual compares two JSON documents semantically.
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,128 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/CliForge/cliforge/pkg/secrets"
+)
+
+// Recorder is an http.RoundTripper that records every exchange to a fixture
+// directory.
+type Recorder struct {
+	dir      string
+	next     http.RoundTripper
+	detector *secrets.Detector
+
+	mu    sync.Mutex
+	count int
+}
+
+// NewRecorder creates a recorder writing fixtures to dir. If next is nil,
+// http.DefaultTransport is used. If detector is nil, the default secret
+// patterns are used for masking.
+func NewRecorder(dir string, next http.RoundTripper, detector *secrets.Detector) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if detector == nil {
+		detector = defaultDetector()
+	}
+
+	// Continue numbering after fixtures that are already present.
+	existing, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		dir:      dir,
+		next:     next,
+		detector: detector,
+		count:    len(existing),
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := &Fixture{
+		Request: &FixtureRequest{
+			Method:  req.Method,
+			Host:    req.URL.Host,
+			Path:    req.URL.Path,
+			Query:   r.detector.MaskQuery(req.URL.Query()),
+			Headers: r.detector.MaskHeaders(req.Header),
+			Body:    maskBody(r.detector, reqBody),
+		},
+		Response: &FixtureResponse{
+			Status:  resp.StatusCode,
+			Headers: r.detector.MaskHeaders(resp.Header),
+			Body:    maskBody(r.detector, respBody),
+		},
+	}
+
+	if err := r.write(req, fixture); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// write saves a fixture under the next sequence number.
+func (r *Recorder) write(req *http.Request, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count++
+	filename := filepath.Join(r.dir, fixtureFileName(r.count, req))
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+// Count returns the number of exchanges recorded so far.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
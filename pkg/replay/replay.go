@@ -0,0 +1,210 @@
+// Package replay records HTTP exchanges to fixture files and replays them.
+//
+// The replay package lets generated CLIs and workflows run without a network.
+// In record mode, a Recorder wraps the real transport and writes every
+// request/response pair to a fixture directory with secrets masked. In replay
+// mode, a Player serves those fixtures through a custom http.RoundTripper.
+//
+// # Features
+//
+//   - One JSON fixture file per exchange, numbered in recording order
+//   - Secrets masked in query parameters, headers and bodies using pkg/secrets
+//   - Configurable request matching on method, path, query and body
+//   - Repeated requests (such as polling) consume fixtures in order
+//
+// # Example Usage
+//
+//	// Record
+//	recorder, _ := replay.NewRecorder("./fixtures", http.DefaultTransport, nil)
+//	client := &http.Client{Transport: recorder}
+//
+//	// Replay
+//	player, _ := replay.NewPlayer("./fixtures", replay.DefaultMatchConfig(), nil)
+//	client := &http.Client{Transport: player}
+//
+// # Fixture Format
+//
+//	{
+//	  "request":  {"method": "POST", "path": "/api/clusters", "query": {}, "body": {...}},
+//	  "response": {"status": 201, "headers": {...}, "body": {...}}
+//	}
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CliForge/cliforge/pkg/secrets"
+)
+
+// Fixture is a recorded HTTP exchange.
+type Fixture struct {
+	Request  *FixtureRequest  `json:"request"`
+	Response *FixtureResponse `json:"response"`
+
+	// name is the fixture file name, used in error messages.
+	name string
+}
+
+// FixtureRequest is the recorded request half of an exchange.
+type FixtureRequest struct {
+	Method  string              `json:"method"`
+	Host    string              `json:"host,omitempty"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query,omitempty"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    json.RawMessage     `json:"body,omitempty"`
+}
+
+// FixtureResponse is the recorded response half of an exchange.
+type FixtureResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    json.RawMessage     `json:"body,omitempty"`
+}
+
+// MatchConfig selects which request attributes must match a fixture.
+type MatchConfig struct {
+	Method bool
+	Path   bool
+	Query  bool
+	Body   bool
+}
+
+// DefaultMatchConfig matches on method, path and query.
+func DefaultMatchConfig() MatchConfig {
+	return MatchConfig{Method: true, Path: true, Query: true}
+}
+
+// ParseMatchConfig parses a comma-separated list of attributes such as
+// "method,path,body". An empty string returns the default configuration.
+func ParseMatchConfig(value string) (MatchConfig, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultMatchConfig(), nil
+	}
+
+	var config MatchConfig
+	for _, part := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "method":
+			config.Method = true
+		case "path":
+			config.Path = true
+		case "query":
+			config.Query = true
+		case "body":
+			config.Body = true
+		case "":
+		default:
+			return MatchConfig{}, fmt.Errorf("unknown match attribute %q (valid: method, path, query, body)", part)
+		}
+	}
+
+	return config, nil
+}
+
+// defaultDetector returns a secret detector with the default patterns.
+func defaultDetector() *secrets.Detector {
+	detector, err := secrets.NewDetector(secrets.DefaultSecretsBehavior())
+	if err != nil {
+		// The default patterns are static and always compile.
+		panic(fmt.Sprintf("replay: invalid default secret patterns: %v", err))
+	}
+	return detector
+}
+
+// maskBody masks secrets in a body and returns it as JSON. Non-JSON bodies
+// are stored as JSON strings.
+func maskBody(detector *secrets.Detector, body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		masked, _ := json.Marshal(detector.MaskString(string(body)))
+		return masked
+	}
+
+	masked, err := json.Marshal(detector.MaskJSON(data))
+	if err != nil {
+		return nil
+	}
+	return masked
+}
+
+// rawBody returns the bytes to send for a recorded body. JSON strings are
+// unwrapped so that non-JSON bodies round-trip.
+func rawBody(body json.RawMessage) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(body, &str); err == nil {
+		return []byte(str)
+	}
+	return body
+}
+
+// LoadFixtures loads all fixture files from dir in recording order.
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	fixtures := make([]*Fixture, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", name, err)
+		}
+		if fixture.Request == nil || fixture.Response == nil {
+			return nil, fmt.Errorf("fixture %s must have a request and a response", name)
+		}
+
+		fixture.name = name
+		fixtures = append(fixtures, &fixture)
+	}
+
+	return fixtures, nil
+}
+
+// fixtureFileName returns the file name for the n-th recorded exchange.
+func fixtureFileName(n int, req *http.Request) string {
+	slug := strings.Trim(strings.ToLower(req.URL.Path), "/")
+	slug = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, slug)
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	if slug == "" {
+		slug = "root"
+	}
+
+	return fmt.Sprintf("%04d-%s-%s.json", n, strings.ToLower(req.Method), slug)
+}
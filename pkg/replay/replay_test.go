@@ -0,0 +1,192 @@
+package replay
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMatchConfig(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    MatchConfig
+		wantErr bool
+	}{
+		{"", DefaultMatchConfig(), false},
+		{"method,path", MatchConfig{Method: true, Path: true}, false},
+		{"METHOD, body", MatchConfig{Method: true, Body: true}, false},
+		{"method,headers", MatchConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMatchConfig(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMatchConfig(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMatchConfig(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"c-1","token":"s3cret-value"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"c-1","status":"ready"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	client := &http.Client{Transport: recorder}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/clusters", strings.NewReader(`{"name":"test"}`))
+	req.Header.Set("Authorization", "Bearer my-token")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("record POST error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "s3cret-value") {
+		t.Errorf("recorder must pass the live body through, got %s", body)
+	}
+
+	resp, err = client.Get(server.URL + "/api/clusters/c-1?verbose=true&api_key=k3y-in-query")
+	if err != nil {
+		t.Fatalf("record GET error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if recorder.Count() != 2 {
+		t.Fatalf("Count() = %d, want 2", recorder.Count())
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 fixture files, got %d", len(files))
+	}
+	first, _ := os.ReadFile(files[0])
+	if strings.Contains(string(first), "my-token") {
+		t.Error("Authorization header should be masked in fixture")
+	}
+	if strings.Contains(string(first), "s3cret-value") {
+		t.Error("token field should be masked in fixture")
+	}
+	if !strings.HasPrefix(filepath.Base(files[0]), "0001-post-api-clusters") {
+		t.Errorf("unexpected fixture name %s", filepath.Base(files[0]))
+	}
+	second, _ := os.ReadFile(files[1])
+	if strings.Contains(string(second), "k3y-in-query") {
+		t.Error("api_key query parameter should be masked in fixture")
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(file), mode)
+		}
+	}
+
+	player, err := NewPlayer(dir, DefaultMatchConfig(), nil)
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+	client = &http.Client{Transport: player}
+
+	resp, err = client.Get("http://offline.invalid/api/clusters/c-1?verbose=true&api_key=k3y-in-query")
+	if err != nil {
+		t.Fatalf("replay GET error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "ready") {
+		t.Errorf("unexpected replay response %d %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("X-Request-Id") != "abc" {
+		t.Errorf("expected recorded header, got %q", resp.Header.Get("X-Request-Id"))
+	}
+
+	if unused := player.Unused(); len(unused) != 1 {
+		t.Errorf("Unused() = %v, want one fixture", unused)
+	}
+
+	// Query does not match.
+	if _, err := client.Get("http://offline.invalid/api/clusters/c-1"); err == nil {
+		t.Error("expected error for unmatched query")
+	}
+}
+
+func TestPlayer_SequentialAndRepeat(t *testing.T) {
+	fixtures := []*Fixture{
+		{
+			Request:  &FixtureRequest{Method: "GET", Path: "/status"},
+			Response: &FixtureResponse{Status: 200, Body: []byte(`{"state":"pending"}`)},
+		},
+		{
+			Request:  &FixtureRequest{Method: "GET", Path: "/status"},
+			Response: &FixtureResponse{Status: 200, Body: []byte(`{"state":"ready"}`)},
+		},
+	}
+	player := NewPlayerFromFixtures(fixtures, DefaultMatchConfig(), nil)
+	client := &http.Client{Transport: player}
+
+	want := []string{"pending", "ready", "ready"}
+	for i, state := range want {
+		resp, err := client.Get("http://api.example.com/status")
+		if err != nil {
+			t.Fatalf("request %d error = %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if !strings.Contains(string(body), state) {
+			t.Errorf("request %d body = %s, want %s", i, body, state)
+		}
+	}
+}
+
+func TestPlayer_MatchBody(t *testing.T) {
+	fixtures := []*Fixture{
+		{
+			Request:  &FixtureRequest{Method: "POST", Path: "/items", Body: []byte(`{"name":"a"}`)},
+			Response: &FixtureResponse{Status: 201, Body: []byte(`{"id":"a"}`)},
+		},
+		{
+			Request:  &FixtureRequest{Method: "POST", Path: "/items", Body: []byte(`{"name":"b"}`)},
+			Response: &FixtureResponse{Status: 201, Body: []byte(`{"id":"b"}`)},
+		},
+	}
+	match := MatchConfig{Method: true, Path: true, Body: true}
+	client := &http.Client{Transport: NewPlayerFromFixtures(fixtures, match, nil)}
+
+	resp, err := client.Post("http://api.example.com/items", "application/json", strings.NewReader(`{ "name": "b" }`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), `"b"`) {
+		t.Errorf("body = %s, want fixture b", body)
+	}
+
+	if _, err := client.Post("http://api.example.com/items", "application/json", strings.NewReader(`{"name":"c"}`)); err == nil {
+		t.Error("expected error for unmatched body")
+	}
+}
@@ -278,6 +278,32 @@ func (d *Detector) MaskHeaders(headers map[string][]string) map[string][]string
 	return result
 }
 
+// MaskQuery masks secrets in URL query parameters: all values of
+// parameters whose names indicate a secret, and secret-looking values of
+// the others.
+func (d *Detector) MaskQuery(query map[string][]string) map[string][]string {
+	if !d.IsEnabled() {
+		return query
+	}
+
+	result := make(map[string][]string, len(query))
+
+	for key, values := range query {
+		secret := d.IsSecretField(key) || d.IsSecretHeader(key)
+		maskedValues := make([]string, len(values))
+		for i, v := range values {
+			if secret {
+				maskedValues[i] = MaskValue(v, d.config.Masking)
+			} else {
+				maskedValues[i] = d.MaskString(v)
+			}
+		}
+		result[key] = maskedValues
+	}
+
+	return result
+}
+
 // MaskJSONString masks secrets in a JSON string.
 func (d *Detector) MaskJSONString(jsonStr string) (string, error) {
 	if !d.IsEnabled() {
@@ -392,6 +392,37 @@ func TestDetector_MaskHeaders(t *testing.T) {
 	}
 }
 
+func TestDetector_MaskQuery(t *testing.T) {
+	detector, err := NewDetector(DefaultSecretsBehavior())
+	if err != nil {
+		t.Fatalf("Failed to create detector: %v", err)
+	}
+
+	query := map[string][]string{
+		"verbose":      {"true"},
+		"api_key":      {"my_api_key_67890"},
+		"access_token": {"first", "second"},
+	}
+
+	masked := detector.MaskQuery(query)
+
+	// Check that normal parameters are not masked
+	if masked["verbose"][0] != "true" {
+		t.Errorf("verbose should not be masked")
+	}
+
+	// Check that secret parameters are masked
+	if masked["api_key"][0] == "my_api_key_67890" {
+		t.Errorf("api_key should be masked")
+	}
+	if len(masked["access_token"]) != 2 || masked["access_token"][1] == "second" {
+		t.Errorf("every access_token value should be masked, got %v", masked["access_token"])
+	}
+	if query["api_key"][0] != "my_api_key_67890" {
+		t.Errorf("the query itself should not be modified")
+	}
+}
+
 func TestDetector_ShouldMaskInContext(t *testing.T) {
 	config := &cli.SecretsBehavior{
 		Enabled: true,
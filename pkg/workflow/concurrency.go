@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"sync"
+)
+
+// runBounded calls fn for every index in [0, n) using at most limit
+// concurrent goroutines. A limit of zero or less means unbounded. When stop
+// returns true, no further indexes are started; in-flight calls finish.
+func runBounded(n, limit int, stop func() bool, fn func(i int)) {
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	if n == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		if stop != nil && stop() {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(index)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// effectiveConcurrency returns the step-level limit if set, otherwise the
+// workflow-level default.
+func effectiveConcurrency(stepLimit, workflowLimit int) int {
+	if stepLimit > 0 {
+		return stepLimit
+	}
+	return workflowLimit
+}
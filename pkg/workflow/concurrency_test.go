@@ -0,0 +1,136 @@
+package workflow
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBounded_LimitsConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	runBounded(20, 3, nil, func(i int) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		active.Add(-1)
+
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+	})
+
+	if peak.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", peak.Load())
+	}
+	if len(seen) != 20 {
+		t.Errorf("expected 20 calls, got %d", len(seen))
+	}
+}
+
+func TestRunBounded_Stop(t *testing.T) {
+	var calls atomic.Int32
+	var stopped atomic.Bool
+
+	runBounded(10, 1, stopped.Load, func(i int) {
+		calls.Add(1)
+		if i == 2 {
+			stopped.Store(true)
+		}
+	})
+
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 calls before stop, got %d", calls.Load())
+	}
+}
+
+func TestEffectiveConcurrency(t *testing.T) {
+	if got := effectiveConcurrency(2, 5); got != 2 {
+		t.Errorf("expected step limit 2, got %d", got)
+	}
+	if got := effectiveConcurrency(0, 5); got != 5 {
+		t.Errorf("expected workflow limit 5, got %d", got)
+	}
+	if got := effectiveConcurrency(0, 0); got != 0 {
+		t.Errorf("expected unbounded, got %d", got)
+	}
+}
+
+func TestExecutor_ParallelLevel_StopsAfterRequiredFailure(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	// Only one step runs at a time, so the failure of "fail" must keep the
+	// steps queued after it from starting.
+	steps := []*Step{
+		createStep("one", server.URL, "1"),
+		{ID: "fail", Type: StepTypeAPICall, Required: true,
+			APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+		createStep("two", server.URL, "2"),
+		createStep("four", server.URL, "4"),
+	}
+	wf := &Workflow{
+		Settings: &Settings{ParallelExecution: true, MaxConcurrency: 1},
+		Steps:    steps,
+	}
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	state, err := executor.Execute(NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+	if state.Status != ExecutionStatusRolledBack {
+		t.Errorf("Status = %s, want %s", state.Status, ExecutionStatusRolledBack)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.deleted) != 1 || handler.deleted[0] != "1" {
+		t.Errorf("deleted = %v, want only the step that ran before the failure", handler.deleted)
+	}
+	if len(handler.created) != 0 {
+		t.Errorf("still created: %v", handler.created)
+	}
+}
+
+func TestStepExecutor_Parallel_StopsAfterFailure(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	// One branch runs at a time, so the failure of "fail" must keep the
+	// branches after it from starting.
+	wf := &Workflow{Steps: []*Step{{
+		ID:   "setup",
+		Type: StepTypeParallel,
+		Parallel: &ParallelStep{
+			MaxConcurrency: 1,
+			Steps: []*Step{
+				createStep("one", server.URL, "1"),
+				{ID: "fail", Type: StepTypeAPICall,
+					APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+				createStep("two", server.URL, "2"),
+				createStep("four", server.URL, "4"),
+			},
+		},
+	}}}
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	if _, err := executor.Execute(NewExecutionContext(nil)); err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(handler.deleted) != 1 || handler.deleted[0] != "1" {
+		t.Errorf("deleted = %v, want only the branch that ran before the failure", handler.deleted)
+	}
+	if len(handler.created) != 0 {
+		t.Errorf("still created: %v", handler.created)
+	}
+}
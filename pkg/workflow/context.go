@@ -121,3 +121,72 @@ func (c *ExecutionContext) Clone() *ExecutionContext {
 
 	return clone
 }
+
+// Fork creates an independent copy of the context for concurrent execution.
+// Unlike Clone, step results and rollback actions recorded in the fork are
+// not visible to the parent, so forks may run in parallel without sharing
+// mutable state. The fork starts with no rollback actions; use
+// AdoptRollbackActions to hand its actions back to the parent.
+func (c *ExecutionContext) Fork() *ExecutionContext {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	fork := &ExecutionContext{
+		Flags:           c.Flags,
+		Variables:       make(map[string]interface{}, len(c.Variables)),
+		StepResults:     make(map[string]*StepResult, len(c.StepResults)),
+		CompletedSteps:  append([]*StepResult(nil), c.CompletedSteps...),
+		RollbackActions: make([]*RollbackAction, 0),
+		HTTPClient:      c.HTTPClient,
+		PluginExecutor:  c.PluginExecutor,
+	}
+
+	for k, v := range c.Variables {
+		fork.Variables[k] = v
+	}
+	for k, v := range c.StepResults {
+		fork.StepResults[k] = v
+	}
+
+	return fork
+}
+
+// AdoptRollbackActions moves the rollback actions recorded in a fork into c.
+// Actions keep running in the fork they were recorded in, and are attributed
+// to parentID and iteration unless a nested step already set them.
+func (c *ExecutionContext) AdoptRollbackActions(fork *ExecutionContext, parentID string, iteration int) {
+	fork.mu.Lock()
+	actions := fork.RollbackActions
+	fork.RollbackActions = make([]*RollbackAction, 0)
+	fork.mu.Unlock()
+
+	for _, action := range actions {
+		if action.Context == nil {
+			action.Context = fork
+		}
+		if action.ParentID == "" {
+			action.ParentID = parentID
+		}
+		if action.Iteration == 0 {
+			action.Iteration = iteration
+		}
+		c.AddRollbackAction(action)
+	}
+}
+
+// recordRollback records the rollback action of a step that succeeded.
+// Steps skipped by their condition have nothing to undo.
+func (c *ExecutionContext) recordRollback(step *Step, result *StepResult) {
+	if step.Rollback == nil || result == nil || !result.Success {
+		return
+	}
+	if skipped, _ := result.Output["skipped"].(bool); skipped {
+		return
+	}
+
+	c.AddRollbackAction(&RollbackAction{
+		StepID: step.ID,
+		Action: step.Rollback,
+		Result: result,
+	})
+}
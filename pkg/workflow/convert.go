@@ -0,0 +1,180 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+// FromCLIWorkflow converts an x-cli-workflow definition from an OpenAPI
+// operation into a workflow the engine can execute, along with its
+// settings and named workflows. Steps without a type are api-call steps.
+func FromCLIWorkflow(cliWorkflow *openapi.CLIWorkflow) (*Workflow, error) {
+	if cliWorkflow == nil {
+		return nil, fmt.Errorf("workflow definition is required")
+	}
+
+	wf := &Workflow{
+		Outputs: cliWorkflow.Outputs,
+	}
+
+	if settings := cliWorkflow.Settings; settings != nil {
+		wf.Settings = &Settings{
+			ParallelExecution: settings.ParallelExecution,
+			FailFast:          settings.FailFast,
+			Timeout:           settings.Timeout,
+			DryRunSupported:   settings.DryRunSupported,
+			MaxConcurrency:    settings.MaxConcurrency,
+		}
+		if rateLimit := settings.RateLimit; rateLimit != nil {
+			wf.Settings.RateLimit = &RateLimitConfig{
+				RequestsPerSecond: rateLimit.RequestsPerSecond,
+				Burst:             rateLimit.Burst,
+			}
+		}
+		wf.Settings.RollbackRetry = fromWorkflowRetry(settings.RollbackRetry)
+	}
+
+	steps, err := fromWorkflowSteps(cliWorkflow.Steps)
+	if err != nil {
+		return nil, err
+	}
+	wf.Steps = steps
+
+	if len(cliWorkflow.Workflows) > 0 {
+		wf.Workflows = make(map[string]*Workflow, len(cliWorkflow.Workflows))
+		for name, named := range cliWorkflow.Workflows {
+			sub, err := FromCLIWorkflow(named)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %s: %w", name, err)
+			}
+			wf.Workflows[name] = sub
+		}
+	}
+
+	return wf, nil
+}
+
+// fromWorkflowSteps converts a list of x-cli-workflow steps.
+func fromWorkflowSteps(cliSteps []*openapi.WorkflowStep) ([]*Step, error) {
+	steps := make([]*Step, 0, len(cliSteps))
+	for _, cliStep := range cliSteps {
+		step, err := fromWorkflowStep(cliStep)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// fromWorkflowStep converts a step of an x-cli-workflow definition.
+func fromWorkflowStep(cliStep *openapi.WorkflowStep) (*Step, error) {
+	step := &Step{
+		ID:          cliStep.ID,
+		Type:        StepType(cliStep.Type),
+		Description: cliStep.Description,
+		DependsOn:   cliStep.DependsOn,
+		Condition:   cliStep.Condition,
+		Required:    cliStep.Required,
+		Output:      cliStep.Output,
+		Retry:       fromWorkflowRetry(cliStep.Retry),
+	}
+	if step.Type == "" {
+		step.Type = StepTypeAPICall
+	}
+
+	switch step.Type {
+	case StepTypeAPICall:
+		if cliStep.Request != nil {
+			step.APICall = &APICallStep{
+				Method:   cliStep.Request.Method,
+				Endpoint: cliStep.Request.URL,
+				Headers:  cliStep.Request.Headers,
+				Body:     cliStep.Request.Body,
+				Query:    cliStep.Request.Query,
+			}
+		}
+	case StepTypePlugin:
+		if plugin := cliStep.Plugin; plugin != nil {
+			step.Plugin = &PluginStep{Plugin: plugin.Plugin, Command: plugin.Command, Input: plugin.Input}
+		}
+	case StepTypeLoop:
+		steps, err := fromWorkflowSteps(cliStep.Steps)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", cliStep.ID, err)
+		}
+		step.Loop = &LoopStep{
+			Iterator:       cliStep.As,
+			Collection:     cliStep.ForEach,
+			Steps:          steps,
+			Parallel:       cliStep.Parallel,
+			MaxConcurrency: cliStep.MaxConcurrency,
+		}
+	case StepTypeParallel:
+		steps, err := fromWorkflowSteps(cliStep.Steps)
+		if err != nil {
+			return nil, fmt.Errorf("step %s: %w", cliStep.ID, err)
+		}
+		step.Parallel = &ParallelStep{Steps: steps, MaxConcurrency: cliStep.MaxConcurrency}
+	case StepTypePrompt:
+		if prompt := cliStep.Prompt; prompt != nil {
+			step.Prompt = &PromptStep{
+				Type:       prompt.Type,
+				Message:    prompt.Message,
+				Variable:   prompt.Variable,
+				Default:    prompt.Default,
+				Options:    prompt.Options,
+				Validation: prompt.Validation,
+				Required:   prompt.Required,
+			}
+		}
+	case StepTypeSet:
+		if cliStep.Set != nil {
+			step.Set = &SetStep{Variables: cliStep.Set.Variables}
+		}
+	case StepTypeAssert:
+		if assert := cliStep.Assert; assert != nil {
+			step.Assert = &AssertStep{Condition: assert.Condition, Message: assert.Message}
+		}
+	case StepTypeCall:
+		if call := cliStep.Call; call != nil {
+			step.Call = &CallStep{Workflow: call.Workflow, Inputs: call.Inputs}
+		}
+	default:
+		return nil, fmt.Errorf("step %s: unsupported step type in x-cli-workflow: %s", cliStep.ID, cliStep.Type)
+	}
+
+	if cliStep.Rollback != nil {
+		rollback, err := fromWorkflowStep(cliStep.Rollback)
+		if err != nil {
+			return nil, fmt.Errorf("step %s rollback: %w", cliStep.ID, err)
+		}
+		step.Rollback = rollback
+	}
+
+	return step, nil
+}
+
+// fromWorkflowRetry converts the retry policy of an x-cli-workflow step.
+func fromWorkflowRetry(cliRetry *openapi.WorkflowRetry) *RetryConfig {
+	if cliRetry == nil {
+		return nil
+	}
+	retry := &RetryConfig{MaxAttempts: cliRetry.MaxAttempts}
+	if backoff := cliRetry.Backoff; backoff != nil {
+		retry.Backoff = &BackoffConfig{
+			Type:            BackoffType(backoff.Type),
+			InitialInterval: backoff.InitialInterval,
+			Multiplier:      backoff.Multiplier,
+			MaxInterval:     backoff.MaxInterval,
+		}
+	}
+	for _, match := range cliRetry.RetryableErrors {
+		retry.RetryableErrors = append(retry.RetryableErrors, &ErrorMatch{
+			HTTPStatus: match.HTTPStatus,
+			ErrorType:  match.ErrorType,
+		})
+	}
+	return retry
+}
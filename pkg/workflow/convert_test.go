@@ -0,0 +1,242 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+const convertSpec = `
+openapi: 3.0.0
+info:
+  title: Clusters
+  version: 1.0.0
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      responses:
+        "201":
+          description: Created
+      x-cli-workflow:
+        settings:
+          parallel-execution: true
+          fail-fast: true
+          timeout: 600
+          max-concurrency: 4
+          rate-limit:
+            requests-per-second: 5
+            burst: 10
+          rollback-retry:
+            max-attempts: 3
+            backoff:
+              type: exponential
+              initial-interval: 2
+        workflows:
+          create-network:
+            steps:
+              - id: create
+                type: api-call
+                api-call:
+                  method: POST
+                  endpoint: /networks
+                  body:
+                    name: "{flags.name}"
+            outputs:
+              network_id: steps.create.response.id
+        steps:
+          - id: quota
+            request:
+              method: GET
+              url: /quota
+            retry:
+              max-attempts: 2
+              retryable-errors:
+                - http-status: 429
+                - http-status: 5xx
+          - id: roles
+            type: plugin
+            plugin: aws-cli
+            command: iam-create-role
+            input:
+              role-name: "{flags.name}-installer"
+            rollback:
+              type: plugin
+              plugin:
+                plugin: aws-cli
+                command: iam-delete-role
+                input:
+                  role-name: "{flags.name}-installer"
+          - id: approve
+            type: prompt
+            prompt:
+              type: select
+              message: Region
+              options: [us-east-1, eu-west-1]
+              default: us-east-1
+              variable: region
+          - id: compute
+            type: set
+            depends-on: [approve]
+            set:
+              variables:
+                node_count: flags.replicas * 3
+          - id: check
+            type: assert
+            depends-on: [quota, compute]
+            assert:
+              condition: steps.quota.response.available >= node_count
+              message: Not enough quota
+          - id: network
+            type: call
+            depends-on: [check]
+            required: true
+            call:
+              workflow: create-network
+              inputs:
+                name: "{flags.name}-net"
+          - id: pools
+            type: loop
+            depends-on: [network]
+            foreach: flags.pools
+            as: pool
+            parallel: true
+            max-concurrency: 5
+            steps:
+              - id: create-pool
+                request:
+                  method: POST
+                  url: /pools
+                  body:
+                    name: "{pool}"
+          - id: setup
+            type: parallel
+            max-concurrency: 2
+            steps:
+              - id: vpc
+                request:
+                  method: POST
+                  url: /vpcs
+              - id: dns
+                request:
+                  method: POST
+                  url: /dns
+`
+
+func TestFromCLIWorkflow_Spec(t *testing.T) {
+	ctx := context.Background()
+	parsed, err := openapi.NewParser().Parse(ctx, []byte(convertSpec))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	result, err := openapi.NewValidator().Validate(ctx, parsed)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("expected a valid spec, got errors: %v", result.Errors)
+	}
+	operations, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatalf("GetOperations() error = %v", err)
+	}
+
+	wf, err := FromCLIWorkflow(operations[0].CLIWorkflow)
+	if err != nil {
+		t.Fatalf("FromCLIWorkflow() error = %v", err)
+	}
+	if _, err := NewParser(wf).Parse(); err != nil {
+		t.Fatalf("converted workflow does not parse: %v", err)
+	}
+
+	settings := wf.Settings
+	if settings == nil || !settings.ParallelExecution || !settings.FailFast || settings.Timeout != 600 || settings.MaxConcurrency != 4 {
+		t.Errorf("Settings = %+v", settings)
+	} else if settings.RateLimit == nil || settings.RateLimit.RequestsPerSecond != 5 || settings.RateLimit.Burst != 10 {
+		t.Errorf("RateLimit = %+v", settings.RateLimit)
+	} else if retry := settings.RollbackRetry; retry == nil || retry.MaxAttempts != 3 || retry.Backoff == nil ||
+		retry.Backoff.Type != BackoffExponential || retry.Backoff.InitialInterval != 2 {
+		t.Errorf("RollbackRetry = %+v", settings.RollbackRetry)
+	}
+
+	steps := make(map[string]*Step)
+	for _, step := range wf.Steps {
+		steps[step.ID] = step
+	}
+
+	tests := []struct {
+		id       string
+		stepType StepType
+		check    func(step *Step) bool
+	}{
+		{"quota", StepTypeAPICall, func(s *Step) bool {
+			return s.APICall != nil && s.APICall.Method == "GET" && s.APICall.Endpoint == "/quota" &&
+				s.Retry != nil && s.Retry.MaxAttempts == 2 && len(s.Retry.RetryableErrors) == 2 &&
+				*s.Retry.RetryableErrors[0].HTTPStatus == 429 && *s.Retry.RetryableErrors[1].HTTPStatus == 500
+		}},
+		{"roles", StepTypePlugin, func(s *Step) bool {
+			return s.Plugin != nil && s.Plugin.Plugin == "aws-cli" && s.Plugin.Command == "iam-create-role" &&
+				s.Plugin.Input["role-name"] == "{flags.name}-installer" &&
+				s.Rollback != nil && s.Rollback.Type == StepTypePlugin && s.Rollback.Plugin != nil &&
+				s.Rollback.Plugin.Command == "iam-delete-role"
+		}},
+		{"approve", StepTypePrompt, func(s *Step) bool {
+			return s.Prompt != nil && s.Prompt.Type == "select" && s.Prompt.Variable == "region" &&
+				s.Prompt.Default == "us-east-1" && len(s.Prompt.Options) == 2
+		}},
+		{"compute", StepTypeSet, func(s *Step) bool {
+			return s.Set != nil && s.Set.Variables["node_count"] == "flags.replicas * 3" && len(s.DependsOn) == 1
+		}},
+		{"check", StepTypeAssert, func(s *Step) bool {
+			return s.Assert != nil && s.Assert.Message == "Not enough quota"
+		}},
+		{"network", StepTypeCall, func(s *Step) bool {
+			return s.Call != nil && s.Call.Workflow == "create-network" && s.Call.Inputs["name"] == "{flags.name}-net" && s.Required
+		}},
+		{"pools", StepTypeLoop, func(s *Step) bool {
+			return s.Loop != nil && s.Loop.Collection == "flags.pools" && s.Loop.Iterator == "pool" &&
+				s.Loop.Parallel && s.Loop.MaxConcurrency == 5 && len(s.Loop.Steps) == 1 &&
+				s.Loop.Steps[0].APICall != nil && s.Loop.Steps[0].APICall.Endpoint == "/pools"
+		}},
+		{"setup", StepTypeParallel, func(s *Step) bool {
+			return s.Parallel != nil && s.Parallel.MaxConcurrency == 2 && len(s.Parallel.Steps) == 2 &&
+				s.Parallel.Steps[0].ID == "vpc" && s.Parallel.Steps[1].Type == StepTypeAPICall
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			step := steps[tt.id]
+			if step == nil {
+				t.Fatalf("step %s not converted", tt.id)
+			}
+			if step.Type != tt.stepType {
+				t.Errorf("Type = %s, want %s", step.Type, tt.stepType)
+			}
+			if !tt.check(step) {
+				t.Errorf("unexpected step %+v", step)
+			}
+		})
+	}
+
+	named := wf.Workflows["create-network"]
+	if named == nil || len(named.Steps) != 1 {
+		t.Fatalf("Workflows = %+v, want create-network", wf.Workflows)
+	}
+	if create := named.Steps[0]; create.Type != StepTypeAPICall || create.APICall == nil || create.APICall.Endpoint != "/networks" {
+		t.Errorf("unexpected named workflow step %+v", create)
+	}
+	if named.Outputs["network_id"] != "steps.create.response.id" {
+		t.Errorf("Outputs = %v", named.Outputs)
+	}
+}
+
+func TestFromCLIWorkflow_UnsupportedType(t *testing.T) {
+	_, err := FromCLIWorkflow(&openapi.CLIWorkflow{
+		Steps: []*openapi.WorkflowStep{{ID: "branch", Type: "conditional"}},
+	})
+	if err == nil {
+		t.Error("expected an error for a step type x-cli-workflow cannot define")
+	}
+}
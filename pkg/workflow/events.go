@@ -0,0 +1,259 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened in a workflow execution.
+type EventType string
+
+const (
+	// EventWorkflowStarted is emitted when a workflow starts executing.
+	EventWorkflowStarted EventType = "workflow-started"
+	// EventWorkflowCompleted is emitted when every step has completed.
+	EventWorkflowCompleted EventType = "workflow-completed"
+	// EventWorkflowFailed is emitted when a workflow fails, before rollback.
+	EventWorkflowFailed EventType = "workflow-failed"
+	// EventStepScheduled is emitted when a top-level step's level starts.
+	EventStepScheduled EventType = "step-scheduled"
+	// EventStepStarted is emitted before a step's first attempt.
+	EventStepStarted EventType = "step-started"
+	// EventStepRetried is emitted before each further attempt of a step.
+	EventStepRetried EventType = "step-retried"
+	// EventStepSucceeded is emitted when a step succeeds.
+	EventStepSucceeded EventType = "step-succeeded"
+	// EventStepFailed is emitted when a step fails after all attempts.
+	EventStepFailed EventType = "step-failed"
+	// EventStepSkipped is emitted when a step's condition is not met.
+	EventStepSkipped EventType = "step-skipped"
+	// EventStepRolledBack is emitted when a step's compensation finishes,
+	// whether or not it succeeded.
+	EventStepRolledBack EventType = "step-rolled-back"
+	// EventWarning is emitted for problems that do not stop the workflow,
+	// such as failing to save state.
+	EventWarning EventType = "warning"
+)
+
+// Event is a single entry in a workflow's event stream.
+type Event struct {
+	Time       time.Time `json:"time"`
+	Type       EventType `json:"type"`
+	WorkflowID string    `json:"workflow-id,omitempty"`
+	StepID     string    `json:"step-id,omitempty"`
+
+	// ParentID is the call step that ran the sub-workflow emitting the
+	// event; empty for events of the top-level workflow.
+	ParentID string `json:"parent-id,omitempty"`
+
+	// Level is the execution level of a scheduled step.
+	Level int `json:"level,omitempty"`
+
+	// Attempt is the 1-based attempt number of a started or retried step,
+	// or the number of attempts of a compensation.
+	Attempt int `json:"attempt,omitempty"`
+
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// Status is the compensation status of a rolled-back step.
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// EventListener receives workflow events. OnEvent may be called from
+// several goroutines when steps run in parallel.
+type EventListener interface {
+	OnEvent(event *Event)
+}
+
+// EventListenerFunc adapts a function to an EventListener.
+type EventListenerFunc func(event *Event)
+
+// OnEvent calls f(event).
+func (f EventListenerFunc) OnEvent(event *Event) {
+	f(event)
+}
+
+// eventBus delivers events to listeners and to the JSON-lines event log.
+type eventBus struct {
+	mu         sync.Mutex
+	workflowID string
+	listeners  []EventListener
+	log        *os.File
+}
+
+// eventEmitter emits events on a bus on behalf of a workflow. Emitters of
+// called sub-workflows share their caller's bus.
+type eventEmitter struct {
+	bus      *eventBus
+	parentID string
+}
+
+// newEventEmitter creates an emitter with its own bus.
+func newEventEmitter() *eventEmitter {
+	return &eventEmitter{bus: &eventBus{}}
+}
+
+// nested returns an emitter for a sub-workflow run by the call step parentID.
+func (em *eventEmitter) nested(parentID string) *eventEmitter {
+	if em == nil {
+		return nil
+	}
+	if em.parentID != "" {
+		parentID = em.parentID
+	}
+	return &eventEmitter{bus: em.bus, parentID: parentID}
+}
+
+// addListener registers a listener.
+func (em *eventEmitter) addListener(listener EventListener) {
+	em.bus.mu.Lock()
+	defer em.bus.mu.Unlock()
+	em.bus.listeners = append(em.bus.listeners, listener)
+}
+
+// open starts a new event log at path for workflowID, replacing any log
+// already there.
+func (em *eventEmitter) open(workflowID, path string) error {
+	em.bus.mu.Lock()
+	defer em.bus.mu.Unlock()
+
+	em.bus.workflowID = workflowID
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create event log: %w", err)
+	}
+	em.bus.log = file
+	return nil
+}
+
+// close closes the event log.
+func (em *eventEmitter) close() error {
+	em.bus.mu.Lock()
+	defer em.bus.mu.Unlock()
+
+	if em.bus.log == nil {
+		return nil
+	}
+	err := em.bus.log.Close()
+	em.bus.log = nil
+	return err
+}
+
+// emit stamps an event and delivers it. A nil emitter discards events.
+func (em *eventEmitter) emit(event *Event) {
+	if em == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.ParentID == "" {
+		event.ParentID = em.parentID
+	}
+
+	// Listeners are called under the lock so they see events in the same
+	// order as the log.
+	em.bus.mu.Lock()
+	defer em.bus.mu.Unlock()
+
+	event.WorkflowID = em.bus.workflowID
+	// Each event is written straight to the file so the log survives a
+	// crash mid-workflow.
+	if em.bus.log != nil {
+		if data, err := json.Marshal(event); err == nil {
+			_, _ = em.bus.log.Write(append(data, '\n'))
+		}
+	}
+	for _, listener := range em.bus.listeners {
+		listener.OnEvent(event)
+	}
+}
+
+// warn emits a warning event.
+func (em *eventEmitter) warn(err error) {
+	em.emit(&Event{Type: EventWarning, Error: err.Error()})
+}
+
+// errorMessage returns the message of the first non-nil error.
+func errorMessage(errs ...error) string {
+	for _, err := range errs {
+		if err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// rollbackEvents emits a step-rolled-back event for every finished
+// compensation and forwards all notifications to next.
+type rollbackEvents struct {
+	events *eventEmitter
+	next   RollbackObserver
+}
+
+func (o *rollbackEvents) OnRollbackStart(records []*CompensationRecord) {
+	if o.next != nil {
+		o.next.OnRollbackStart(records)
+	}
+}
+
+func (o *rollbackEvents) OnCompensationStart(record *CompensationRecord) {
+	if o.next != nil {
+		o.next.OnCompensationStart(record)
+	}
+}
+
+func (o *rollbackEvents) OnCompensationFinish(record *CompensationRecord) {
+	o.events.emit(&Event{
+		Type:     EventStepRolledBack,
+		StepID:   record.ID,
+		Attempt:  record.Attempts,
+		Duration: record.Duration,
+		Status:   string(record.Status),
+		Error:    record.Error,
+	})
+	if o.next != nil {
+		o.next.OnCompensationFinish(record)
+	}
+}
+
+func (o *rollbackEvents) OnRollbackComplete(report *RollbackReport) {
+	if o.next != nil {
+		o.next.OnRollbackComplete(report)
+	}
+}
+
+// LoadEvents reads the event log of a workflow execution.
+func (sm *StateManager) LoadEvents(workflowID string) ([]*Event, error) {
+	file, err := os.Open(sm.GetEventLogPath(workflowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var events []*Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		events = append(events, &event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExecutor_EventLog(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		attempt := calls[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/flaky" && attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/fail":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	wf := &Workflow{Steps: []*Step{
+		{
+			ID:      "flaky",
+			Type:    StepTypeAPICall,
+			APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/flaky"},
+			Retry:   &RetryConfig{MaxAttempts: 2, Backoff: &BackoffConfig{Type: BackoffFixed}},
+			Rollback: &Step{
+				ID:      "undo-flaky",
+				Type:    StepTypeAPICall,
+				APICall: &APICallStep{Method: "DELETE", Endpoint: server.URL + "/flaky"},
+			},
+		},
+		{
+			ID:        "optional",
+			Type:      StepTypeNoop,
+			Condition: "false",
+		},
+		{
+			ID:        "fail",
+			Type:      StepTypeAPICall,
+			Required:  true,
+			DependsOn: []string{"flaky"},
+			APICall:   &APICallStep{Method: "POST", Endpoint: server.URL + "/fail"},
+		},
+	}}
+
+	executor, err := NewExecutor(wf, server.Client(), nil)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	stateManager := NewStateManagerWithDir(t.TempDir())
+	executor.SetStateManager(stateManager)
+	executor.SetRollbackObserver(&recordingObserver{})
+
+	var received []*Event
+	executor.AddEventListener(EventListenerFunc(func(event *Event) {
+		received = append(received, event)
+	}))
+
+	state, err := executor.Execute(NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+
+	logged, err := stateManager.LoadEvents(state.WorkflowID)
+	if err != nil {
+		t.Fatalf("LoadEvents() error = %v", err)
+	}
+	if len(logged) != len(received) {
+		t.Fatalf("log has %d events, listener received %d", len(logged), len(received))
+	}
+
+	var got []string
+	for i, event := range logged {
+		if event.Type != received[i].Type || event.StepID != received[i].StepID {
+			t.Errorf("event %d: log has %s %s, listener got %s %s", i, event.Type, event.StepID, received[i].Type, received[i].StepID)
+		}
+		if event.WorkflowID != state.WorkflowID {
+			t.Errorf("event %d: WorkflowID = %q, want %q", i, event.WorkflowID, state.WorkflowID)
+		}
+		got = append(got, string(event.Type)+" "+event.StepID)
+	}
+
+	want := []string{
+		"workflow-started ",
+		"step-scheduled flaky",
+		"step-scheduled optional",
+		"step-started flaky",
+		"step-retried flaky",
+		"step-succeeded flaky",
+		"step-skipped optional",
+		"step-scheduled fail",
+		"step-started fail",
+		"step-failed fail",
+		"workflow-failed ",
+		"step-rolled-back flaky",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExecutor_WarningOutput(t *testing.T) {
+	// A state directory under a regular file cannot be created
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	wf := &Workflow{Steps: []*Step{{ID: "noop", Type: StepTypeNoop}}}
+	executor, err := NewExecutor(wf, http.DefaultClient, nil)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	executor.SetStateManager(NewStateManagerWithDir(filepath.Join(file, "workflows")))
+
+	var warnings bytes.Buffer
+	executor.SetWarningOutput(&warnings)
+	var events int
+	executor.AddEventListener(EventListenerFunc(func(event *Event) {
+		if event.Type == EventWarning {
+			events++
+		}
+	}))
+
+	if _, err := executor.Execute(NewExecutionContext(nil)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(warnings.String(), "Warning: failed to save") {
+		t.Errorf("expected save warnings in the warning output, got %q", warnings.String())
+	}
+	if events == 0 {
+		t.Error("expected warning events")
+	}
+}
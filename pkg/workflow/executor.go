@@ -2,9 +2,13 @@ package workflow
 
 import (
 	"fmt"
+	"io"
 	"net/http"
-	"sync"
+	"os"
+	"sync/atomic"
 	"time"
+
+	"github.com/CliForge/cliforge/pkg/cli/interactive"
 )
 
 // Executor executes workflows.
@@ -14,6 +18,11 @@ type Executor struct {
 	stepExecutor *StepExecutor
 	rollback     *RollbackManager
 	state        *StateManager
+	events       *eventEmitter
+	warnings     io.Writer
+
+	// workflowID is the ID the run is saved under; empty generates one.
+	workflowID string
 }
 
 // NewExecutor creates a new workflow executor.
@@ -29,21 +38,133 @@ func NewExecutor(workflow *Workflow, httpClient *http.Client, pluginExecutor int
 		workflow:     workflow,
 		dag:          dag,
 		stepExecutor: NewStepExecutor(httpClient, pluginExecutor),
-		rollback:     NewRollbackManager(),
+		rollback:     newRollbackManager(workflow, dag),
 		state:        NewStateManager(),
+		events:       newEventEmitter(),
+		warnings:     os.Stderr,
+	}
+	executor.stepExecutor.events = executor.events
+	executor.SetRollbackObserver(nil)
+
+	if workflow.Settings != nil {
+		executor.stepExecutor.SetMaxConcurrency(workflow.Settings.MaxConcurrency)
+		executor.stepExecutor.SetRateLimiter(NewHostRateLimiterFromConfig(workflow.Settings.RateLimit))
+	}
+	executor.stepExecutor.state = executor.state
+	for name, sub := range workflow.Workflows {
+		executor.stepExecutor.RegisterWorkflow(name, sub)
 	}
 
 	return executor, nil
 }
 
+// newRollbackManager creates a rollback manager that follows the workflow's
+// DAG and settings.
+func newRollbackManager(workflow *Workflow, dag *DAG) *RollbackManager {
+	rollback := NewRollbackManager()
+	rollback.SetDAG(dag)
+	if workflow.Settings != nil {
+		rollback.SetParallel(workflow.Settings.ParallelExecution)
+		rollback.SetMaxConcurrency(workflow.Settings.MaxConcurrency)
+		rollback.SetDefaultRetry(workflow.Settings.RollbackRetry)
+	}
+	return rollback
+}
+
+// SetRollbackObserver sets the observer notified as compensations run,
+// including those of called sub-workflows. By default rollback progress is
+// only emitted as events, so that it never mixes with formatted output.
+func (e *Executor) SetRollbackObserver(observer RollbackObserver) {
+	e.rollback.SetObserver(&rollbackEvents{events: e.events, next: observer})
+	e.stepExecutor.rollbackObserver = observer
+}
+
+// SetWarningOutput sets where warnings, such as a failure to save state,
+// are written. It defaults to stderr so warnings never mix with formatted
+// output; nil discards them. Warnings are also emitted as events.
+func (e *Executor) SetWarningOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	e.warnings = w
+}
+
+// AddEventListener registers a listener for the executor's event stream.
+// The same events are written to a JSON-lines log next to the saved state.
+func (e *Executor) AddEventListener(listener EventListener) {
+	e.events.addListener(listener)
+}
+
+// SetPrompter sets the prompter used by prompt steps.
+func (e *Executor) SetPrompter(prompter *interactive.Prompter) {
+	e.stepExecutor.SetPrompter(prompter)
+}
+
+// SetAssumeYes makes prompt steps auto-answer, as with a --yes flag.
+func (e *Executor) SetAssumeYes(assumeYes bool) {
+	e.stepExecutor.SetAssumeYes(assumeYes)
+}
+
+// RegisterWorkflow makes a named workflow available to call steps.
+func (e *Executor) RegisterWorkflow(name string, wf *Workflow) {
+	e.stepExecutor.RegisterWorkflow(name, wf)
+}
+
+// EvaluateOutputs evaluates the workflow's declared outputs against ctx.
+func (e *Executor) EvaluateOutputs(ctx *ExecutionContext) (map[string]interface{}, error) {
+	outputs := make(map[string]interface{}, len(e.workflow.Outputs))
+	evaluator := NewExprEvaluator(ctx)
+
+	for name, expression := range e.workflow.Outputs {
+		value, err := evaluator.EvaluateExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", name, err)
+		}
+		outputs[name] = value
+	}
+
+	return outputs, nil
+}
+
+// SetStateManager replaces the state manager used to persist execution state.
+func (e *Executor) SetStateManager(state *StateManager) {
+	e.state = state
+	e.stepExecutor.state = state
+}
+
+// SetRateLimiter replaces the executor's per-host rate limiter. Passing the
+// same limiter to several executors makes them share one budget per host.
+func (e *Executor) SetRateLimiter(limiter *HostRateLimiter) {
+	e.stepExecutor.SetRateLimiter(limiter)
+}
+
+// maxConcurrency returns the workflow-level concurrency limit.
+func (e *Executor) maxConcurrency() int {
+	if e.workflow.Settings == nil {
+		return 0
+	}
+	return e.workflow.Settings.MaxConcurrency
+}
+
 // Execute executes the workflow.
 func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
+	workflowID := e.workflowID
+	if workflowID == "" {
+		workflowID = fmt.Sprintf("workflow-%d", time.Now().Unix())
+	}
 	state := &ExecutionState{
-		WorkflowID:     fmt.Sprintf("workflow-%d", time.Now().Unix()),
+		WorkflowID:     workflowID,
 		StartTime:      time.Now(),
 		Status:         ExecutionStatusRunning,
 		CompletedSteps: make([]*StepResult, 0),
 	}
+	e.stepExecutor.workflowID = workflowID
+
+	if e.events.parentID == "" {
+		e.openEventLog(state.WorkflowID)
+		defer func() { _ = e.events.close() }()
+	}
+	e.events.emit(&Event{Type: EventWorkflowStarted})
 
 	// Get execution order from the already-parsed DAG
 	// Create a temporary parser to get execution order
@@ -54,11 +175,15 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 	executionOrder := parser.GetExecutionOrder()
 
 	// Execute steps level by level
-	for _, levelSteps := range executionOrder {
+	for level, levelSteps := range executionOrder {
 		if len(levelSteps) == 0 {
 			continue
 		}
 
+		for _, step := range levelSteps {
+			e.events.emit(&Event{Type: EventStepScheduled, StepID: step.ID, Level: level})
+		}
+
 		// Check if parallel execution is enabled
 		parallelEnabled := e.workflow.Settings != nil && e.workflow.Settings.ParallelExecution
 
@@ -73,16 +198,7 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 
 		if levelErr != nil {
 			// Handle failure
-			state.Status = ExecutionStatusFailed
-			state.Error = levelErr
-
-			// Trigger rollback
-			if err := e.rollback.ExecuteRollback(ctx, e.stepExecutor); err != nil {
-				state.Error = fmt.Errorf("workflow failed and rollback failed: %w (rollback error: %v)", levelErr, err)
-			} else {
-				state.Status = ExecutionStatusRolledBack
-			}
-
+			e.fail(state, ctx, levelErr, "workflow failed")
 			return state, levelErr
 		}
 
@@ -91,16 +207,7 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 			for _, step := range levelSteps {
 				result, exists := ctx.GetStepResult(step.ID)
 				if exists && !result.Success {
-					state.Status = ExecutionStatusFailed
-					state.Error = fmt.Errorf("step %s failed (fail-fast enabled)", step.ID)
-
-					// Trigger rollback
-					if err := e.rollback.ExecuteRollback(ctx, e.stepExecutor); err != nil {
-						state.Error = fmt.Errorf("workflow failed and rollback failed: %w (rollback error: %v)", state.Error, err)
-					} else {
-						state.Status = ExecutionStatusRolledBack
-					}
-
+					e.fail(state, ctx, fmt.Errorf("step %s failed (fail-fast enabled)", step.ID), "workflow failed")
 					return state, state.Error
 				}
 			}
@@ -110,16 +217,7 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 		if e.workflow.Settings != nil && e.workflow.Settings.Timeout > 0 {
 			elapsed := time.Since(state.StartTime).Seconds()
 			if elapsed > float64(e.workflow.Settings.Timeout) {
-				state.Status = ExecutionStatusFailed
-				state.Error = fmt.Errorf("workflow timeout after %d seconds", e.workflow.Settings.Timeout)
-
-				// Trigger rollback
-				if err := e.rollback.ExecuteRollback(ctx, e.stepExecutor); err != nil {
-					state.Error = fmt.Errorf("workflow timeout and rollback failed: %w (rollback error: %v)", state.Error, err)
-				} else {
-					state.Status = ExecutionStatusRolledBack
-				}
-
+				e.fail(state, ctx, fmt.Errorf("workflow timeout after %d seconds", e.workflow.Settings.Timeout), "workflow timeout")
 				return state, state.Error
 			}
 		}
@@ -127,7 +225,7 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 		// Save state after each level
 		if err := e.state.SaveState(state); err != nil {
 			// Log error but continue
-			fmt.Printf("Warning: failed to save state: %v\n", err)
+			e.warn(fmt.Errorf("failed to save state: %w", err))
 		}
 	}
 
@@ -136,12 +234,56 @@ func (e *Executor) Execute(ctx *ExecutionContext) (*ExecutionState, error) {
 	state.CompletedSteps = ctx.CompletedSteps
 
 	if err := e.state.SaveState(state); err != nil {
-		fmt.Printf("Warning: failed to save final state: %v\n", err)
+		e.warn(fmt.Errorf("failed to save final state: %w", err))
 	}
+	e.events.emit(&Event{Type: EventWorkflowCompleted, Duration: time.Since(state.StartTime)})
 
 	return state, nil
 }
 
+// openEventLog starts the event log of a top-level execution. Failing to
+// create it is reported as a warning; the workflow still runs.
+func (e *Executor) openEventLog(workflowID string) {
+	err := e.state.ensureStateDir()
+	if err == nil {
+		err = e.events.open(workflowID, e.state.GetEventLogPath(workflowID))
+	}
+	if err != nil {
+		e.warn(err)
+	}
+}
+
+// warn writes a warning and records it in the event stream.
+func (e *Executor) warn(err error) {
+	_, _ = fmt.Fprintf(e.warnings, "Warning: %v\n", err)
+	e.events.warn(err)
+}
+
+// fail rolls back a failed execution and saves its state, including the
+// rollback report, so failed compensations can be retried later.
+func (e *Executor) fail(state *ExecutionState, ctx *ExecutionContext, cause error, what string) {
+	state.Status = ExecutionStatusFailed
+	state.Error = cause
+	state.CompletedSteps = ctx.CompletedSteps
+	e.events.emit(&Event{Type: EventWorkflowFailed, Duration: time.Since(state.StartTime), Error: cause.Error()})
+
+	// Trigger rollback
+	report, err := e.rollback.ExecuteRollbackWithReport(ctx, e.stepExecutor)
+	if len(report.Compensations) > 0 {
+		state.Rollback = report
+	}
+	if err != nil {
+		state.Error = fmt.Errorf("%s and rollback failed: %w (rollback error: %v)", what, cause, err)
+	} else {
+		state.Status = ExecutionStatusRolledBack
+	}
+	state.ErrorMessage = state.Error.Error()
+
+	if err := e.state.SaveState(state); err != nil {
+		e.warn(fmt.Errorf("failed to save state: %w", err))
+	}
+}
+
 // executeLevelSequential executes steps in a level sequentially.
 func (e *Executor) executeLevelSequential(levelSteps []*Step, ctx *ExecutionContext, state *ExecutionState) error {
 	for _, step := range levelSteps {
@@ -157,14 +299,7 @@ func (e *Executor) executeLevelSequential(levelSteps []*Step, ctx *ExecutionCont
 		state.CompletedSteps = append(state.CompletedSteps, result)
 
 		// Add rollback action if step has rollback
-		if step.Rollback != nil && result.Success {
-			rollbackAction := &RollbackAction{
-				StepID: step.ID,
-				Action: step.Rollback,
-				Result: result,
-			}
-			ctx.AddRollbackAction(rollbackAction)
-		}
+		ctx.recordRollback(step, result)
 
 		// Check if step failed and is required
 		if !result.Success {
@@ -177,62 +312,53 @@ func (e *Executor) executeLevelSequential(levelSteps []*Step, ctx *ExecutionCont
 	return nil
 }
 
-// executeLevelParallel executes steps in a level in parallel.
+// executeLevelParallel executes steps in a level in parallel. As in
+// sequential execution, no further steps are started once one errors or a
+// required step fails; steps already running are waited for.
 func (e *Executor) executeLevelParallel(levelSteps []*Step, ctx *ExecutionContext, state *ExecutionState) error {
-	var wg sync.WaitGroup
-	resultsChan := make(chan *stepExecutionResult, len(levelSteps))
-	errorsChan := make(chan error, len(levelSteps))
-
-	for _, step := range levelSteps {
-		wg.Add(1)
-		go func(s *Step) {
-			defer wg.Done()
-
-			result, err := e.stepExecutor.ExecuteStep(s, ctx)
-			if err != nil {
-				errorsChan <- fmt.Errorf("step %s failed: %w", s.ID, err)
-				return
-			}
+	execResults := make([]*stepExecutionResult, len(levelSteps))
+
+	var failed atomic.Bool
+	runBounded(len(levelSteps), e.maxConcurrency(), failed.Load, func(i int) {
+		s := levelSteps[i]
+		result, err := e.stepExecutor.ExecuteStep(s, ctx)
+		execResults[i] = &stepExecutionResult{
+			step:   s,
+			result: result,
+			err:    err,
+		}
+		if err != nil || (!result.Success && s.Required) {
+			failed.Store(true)
+		}
+	})
 
-			resultsChan <- &stepExecutionResult{
-				step:   s,
-				result: result,
-				err:    err,
+	// Process the results of the steps that ran, so that those that
+	// succeeded are rolled back with the rest
+	var levelErr error
+	for _, execResult := range execResults {
+		if execResult == nil {
+			continue
+		}
+		if execResult.err != nil {
+			if levelErr == nil {
+				levelErr = fmt.Errorf("step %s failed: %w", execResult.step.ID, execResult.err)
 			}
-		}(step)
-	}
-
-	wg.Wait()
-	close(resultsChan)
-	close(errorsChan)
-
-	// Check for errors
-	for err := range errorsChan {
-		return err
-	}
+			continue
+		}
 
-	// Process results
-	for execResult := range resultsChan {
 		ctx.SetStepResult(execResult.step.ID, execResult.result)
 		state.CompletedSteps = append(state.CompletedSteps, execResult.result)
 
 		// Add rollback action
-		if execResult.step.Rollback != nil && execResult.result.Success {
-			rollbackAction := &RollbackAction{
-				StepID: execResult.step.ID,
-				Action: execResult.step.Rollback,
-				Result: execResult.result,
-			}
-			ctx.AddRollbackAction(rollbackAction)
-		}
+		ctx.recordRollback(execResult.step, execResult.result)
 
 		// Check if step failed and is required
-		if !execResult.result.Success && execResult.step.Required {
-			return fmt.Errorf("required step %s failed", execResult.step.ID)
+		if !execResult.result.Success && execResult.step.Required && levelErr == nil {
+			levelErr = fmt.Errorf("required step %s failed", execResult.step.ID)
 		}
 	}
 
-	return nil
+	return levelErr
 }
 
 // stepExecutionResult holds the result of a step execution.
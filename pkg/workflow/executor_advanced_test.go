@@ -1,8 +1,10 @@
 package workflow
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -726,3 +728,55 @@ func TestExecutor_Execute_LevelWithNoSteps(t *testing.T) {
 		t.Errorf("expected completed status, got %s", state.Status)
 	}
 }
+
+func TestExecutor_Execute_WorkflowMaxConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		active.Add(-1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	steps := make([]*Step, 5)
+	for i := range steps {
+		steps[i] = &Step{
+			ID:      fmt.Sprintf("step%d", i),
+			Type:    StepTypeAPICall,
+			APICall: &APICallStep{Endpoint: server.URL, Method: "GET"},
+		}
+	}
+
+	workflow := &Workflow{
+		Settings: &Settings{
+			ParallelExecution: true,
+			MaxConcurrency:    2,
+			RateLimit:         &RateLimitConfig{RequestsPerSecond: 1000, Burst: 10},
+		},
+		Steps: steps,
+	}
+
+	executor, err := NewExecutor(workflow, server.Client(), nil)
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	executor.state = NewStateManagerWithDir(t.TempDir())
+
+	state, err := executor.Execute(NewExecutionContext(map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	if state.Status != ExecutionStatusCompleted {
+		t.Errorf("expected status %s, got %s", ExecutionStatusCompleted, state.Status)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent steps, got %d", peak.Load())
+	}
+}
@@ -209,6 +209,9 @@ func (e *ExprEvaluator) buildEnvironment() map[string]interface{} {
 		return env
 	}
 
+	e.context.mu.RLock()
+	defer e.context.mu.RUnlock()
+
 	// Add flags
 	env["flags"] = e.context.Flags
 
@@ -0,0 +1,307 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/replay"
+	"gopkg.in/yaml.v3"
+)
+
+// TestSuite is a set of workflow test cases run against recorded fixtures.
+//
+// Example suite file:
+//
+//	fixtures: ./fixtures
+//	match: [method, path, query]
+//	cases:
+//	  - name: creates a cluster
+//	    operation: createCluster
+//	    flags:
+//	      name: demo
+//	    expect:
+//	      status: completed
+//	      steps:
+//	        create-cluster:
+//	          status_code: 201
+//	          response.id: c-1
+type TestSuite struct {
+	// Fixtures is the default fixture directory for cases.
+	Fixtures string `yaml:"fixtures"`
+	// Match lists the request attributes used to select fixtures.
+	Match []string `yaml:"match"`
+	// Cases are the test cases in the suite.
+	Cases []*TestCase `yaml:"cases"`
+
+	// dir is the directory of the suite file; relative paths resolve against it.
+	dir string
+}
+
+// TestCase runs one workflow against a fixture directory.
+type TestCase struct {
+	Name string `yaml:"name"`
+	// Operation is the operationId of an x-cli-workflow operation.
+	Operation string `yaml:"operation"`
+	// Workflow is an inline workflow definition, used instead of Operation.
+	Workflow map[string]interface{} `yaml:"workflow"`
+	// Flags are the flag values available to the workflow as {flags.*}.
+	Flags map[string]interface{} `yaml:"flags"`
+	// Fixtures overrides the suite fixture directory.
+	Fixtures string `yaml:"fixtures"`
+	// Match overrides the suite match attributes.
+	Match  []string         `yaml:"match"`
+	Expect *TestExpectation `yaml:"expect"`
+}
+
+// TestExpectation describes the expected outcome of a test case.
+type TestExpectation struct {
+	// Status is the expected execution status (default: completed).
+	Status ExecutionStatus `yaml:"status"`
+	// Steps maps step IDs to expected output values by dotted path.
+	Steps map[string]map[string]interface{} `yaml:"steps"`
+	// Outputs maps workflow output names to expected values.
+	Outputs map[string]interface{} `yaml:"outputs"`
+}
+
+// TestCaseResult is the outcome of a single test case.
+type TestCaseResult struct {
+	Name     string
+	Passed   bool
+	Failures []string
+	Duration time.Duration
+}
+
+// WorkflowResolver returns the workflow for an operationId.
+type WorkflowResolver func(operationID string) (*Workflow, error)
+
+// LoadTestSuite loads a test suite from a YAML file.
+func LoadTestSuite(path string) (*TestSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test suite: %w", err)
+	}
+
+	var suite TestSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test suite: %w", err)
+	}
+
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("test suite %s has no cases", path)
+	}
+
+	suite.dir = filepath.Dir(path)
+	return &suite, nil
+}
+
+// Run executes every case in the suite and returns the results in order.
+func (s *TestSuite) Run(resolve WorkflowResolver) []*TestCaseResult {
+	results := make([]*TestCaseResult, 0, len(s.Cases))
+	for i, tc := range s.Cases {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("case %d", i+1)
+		}
+
+		start := time.Now()
+		failures := s.runCase(tc, resolve)
+		results = append(results, &TestCaseResult{
+			Name:     name,
+			Passed:   len(failures) == 0,
+			Failures: failures,
+			Duration: time.Since(start),
+		})
+	}
+	return results
+}
+
+// runCase executes a single case and returns its failures.
+func (s *TestSuite) runCase(tc *TestCase, resolve WorkflowResolver) []string {
+	wf, err := s.caseWorkflow(tc, resolve)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	fixtures := tc.Fixtures
+	if fixtures == "" {
+		fixtures = s.Fixtures
+	}
+	if fixtures == "" {
+		return []string{"no fixture directory configured"}
+	}
+	if !filepath.IsAbs(fixtures) {
+		fixtures = filepath.Join(s.dir, fixtures)
+	}
+
+	matchAttrs := tc.Match
+	if len(matchAttrs) == 0 {
+		matchAttrs = s.Match
+	}
+	match, err := replay.ParseMatchConfig(strings.Join(matchAttrs, ","))
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	player, err := replay.NewPlayer(fixtures, match, nil)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	stateDir, err := os.MkdirTemp("", "cliforge-workflow-test-")
+	if err != nil {
+		return []string{fmt.Sprintf("failed to create state directory: %v", err)}
+	}
+	defer func() { _ = os.RemoveAll(stateDir) }()
+
+	executor, err := NewExecutor(wf, &http.Client{Transport: player}, nil)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	executor.SetStateManager(NewStateManagerWithDir(stateDir))
+	executor.SetAssumeYes(true)
+
+	ctx := NewExecutionContext(tc.Flags)
+	state, execErr := executor.Execute(ctx)
+
+	expect := tc.Expect
+	if expect == nil {
+		expect = &TestExpectation{}
+	}
+
+	var failures []string
+
+	wantStatus := expect.Status
+	if wantStatus == "" {
+		wantStatus = ExecutionStatusCompleted
+	}
+	if state != nil && state.Status != wantStatus {
+		failure := fmt.Sprintf("status: got %s, want %s", state.Status, wantStatus)
+		if execErr != nil {
+			failure += fmt.Sprintf(" (%v)", execErr)
+		}
+		failures = append(failures, failure)
+	}
+
+	for _, stepID := range sortedKeys(expect.Steps) {
+		result, ok := ctx.GetStepResult(stepID)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("step %s: did not run", stepID))
+			continue
+		}
+		for _, path := range sortedKeys(expect.Steps[stepID]) {
+			actual, found := lookupPath(result.Output, path)
+			if failure := compareValue(fmt.Sprintf("step %s: %s", stepID, path), actual, found, expect.Steps[stepID][path]); failure != "" {
+				failures = append(failures, failure)
+			}
+		}
+	}
+
+	if len(expect.Outputs) > 0 {
+		outputs, err := executor.EvaluateOutputs(ctx)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("outputs: %v", err))
+		} else {
+			for _, name := range sortedKeys(expect.Outputs) {
+				actual, found := outputs[name]
+				if failure := compareValue(fmt.Sprintf("output %s", name), actual, found, expect.Outputs[name]); failure != "" {
+					failures = append(failures, failure)
+				}
+			}
+		}
+	}
+
+	return failures
+}
+
+// caseWorkflow returns the workflow under test for a case.
+func (s *TestSuite) caseWorkflow(tc *TestCase, resolve WorkflowResolver) (*Workflow, error) {
+	if tc.Workflow != nil {
+		data, err := json.Marshal(tc.Workflow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inline workflow: %w", err)
+		}
+		var wf Workflow
+		if err := json.Unmarshal(data, &wf); err != nil {
+			return nil, fmt.Errorf("invalid inline workflow: %w", err)
+		}
+		return &wf, nil
+	}
+
+	if tc.Operation == "" {
+		return nil, fmt.Errorf("case must set operation or workflow")
+	}
+	if resolve == nil {
+		return nil, fmt.Errorf("no spec loaded to resolve operation %s", tc.Operation)
+	}
+	return resolve(tc.Operation)
+}
+
+// lookupPath walks a dotted path such as "response.items.0.id".
+func lookupPath(data interface{}, path string) (interface{}, bool) {
+	current := normalizeValue(data)
+	for _, part := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// compareValue returns a failure message if actual does not equal expected.
+func compareValue(label string, actual interface{}, found bool, expected interface{}) string {
+	if !found {
+		return fmt.Sprintf("%s: not found, want %v", label, expected)
+	}
+
+	got := normalizeValue(actual)
+	want := normalizeValue(expected)
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Sprintf("%s: got %v, want %v", label, got, want)
+	}
+	return ""
+}
+
+// normalizeValue round-trips a value through JSON so that numbers, maps and
+// slices compare consistently regardless of how they were decoded.
+func normalizeValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
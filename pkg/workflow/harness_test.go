@@ -0,0 +1,277 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/openapi"
+)
+
+func writeHarnessFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestTestSuite_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	writeHarnessFile(t, dir, "fixtures/0001-post-clusters.json", `{
+  "request": {"method": "POST", "path": "/clusters", "body": {"name": "demo"}},
+  "response": {"status": 201, "body": {"id": "c-1", "name": "demo"}}
+}`)
+	writeHarnessFile(t, dir, "fixtures/0002-get-clusters-c-1.json", `{
+  "request": {"method": "GET", "path": "/clusters/c-1"},
+  "response": {"status": 200, "body": {"id": "c-1", "state": "ready", "nodes": [{"id": "n-1"}]}}
+}`)
+
+	suitePath := writeHarnessFile(t, dir, "suite.yaml", `
+fixtures: fixtures
+match: [method, path, body]
+cases:
+  - name: creates and reads a cluster
+    flags:
+      name: demo
+    workflow:
+      steps:
+        - id: create
+          type: api-call
+          api-call:
+            method: POST
+            endpoint: http://api.example.com/clusters
+            body:
+              name: "{flags.name}"
+        - id: read
+          type: api-call
+          depends-on: [create]
+          api-call:
+            method: GET
+            endpoint: http://api.example.com/clusters/{steps.create.response.id}
+      outputs:
+        cluster_state: steps.read.response.state
+    expect:
+      steps:
+        create:
+          status_code: 201
+          response.id: c-1
+        read:
+          response.nodes.0.id: n-1
+      outputs:
+        cluster_state: ready
+  - name: wrong expectation
+    operation: createCluster
+    expect:
+      steps:
+        create:
+          response.id: c-2
+`)
+
+	suite, err := LoadTestSuite(suitePath)
+	if err != nil {
+		t.Fatalf("LoadTestSuite() error = %v", err)
+	}
+
+	resolve := func(operationID string) (*Workflow, error) {
+		return &Workflow{Steps: []*Step{{
+			ID:      "create",
+			Type:    StepTypeAPICall,
+			APICall: &APICallStep{Method: "POST", Endpoint: "http://api.example.com/clusters", Body: map[string]interface{}{"name": "demo"}},
+		}}}, nil
+	}
+
+	results := suite.Run(resolve)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if !results[0].Passed {
+		t.Errorf("case 1 should pass, failures: %v", results[0].Failures)
+	}
+
+	if results[1].Passed {
+		t.Fatal("case 2 should fail")
+	}
+	if len(results[1].Failures) != 1 || !strings.Contains(results[1].Failures[0], "got c-1, want c-2") {
+		t.Errorf("unexpected failures: %v", results[1].Failures)
+	}
+}
+
+func TestTestSuite_UnmatchedRequestFails(t *testing.T) {
+	dir := t.TempDir()
+	writeHarnessFile(t, dir, "fixtures/0001-get-other.json", `{
+  "request": {"method": "GET", "path": "/other"},
+  "response": {"status": 200, "body": {}}
+}`)
+	suitePath := writeHarnessFile(t, dir, "suite.yaml", `
+fixtures: fixtures
+cases:
+  - workflow:
+      steps:
+        - id: get
+          type: api-call
+          api-call:
+            method: GET
+            endpoint: http://api.example.com/missing
+`)
+
+	suite, err := LoadTestSuite(suitePath)
+	if err != nil {
+		t.Fatalf("LoadTestSuite() error = %v", err)
+	}
+
+	results := suite.Run(nil)
+	if results[0].Passed {
+		t.Fatal("expected case to fail")
+	}
+	if results[0].Name != "case 1" {
+		t.Errorf("Name = %q, want default name", results[0].Name)
+	}
+	if !strings.Contains(strings.Join(results[0].Failures, "\n"), "no fixture matches GET /missing") {
+		t.Errorf("unexpected failures: %v", results[0].Failures)
+	}
+}
+
+func TestTestSuite_StepTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeHarnessFile(t, dir, "fixtures/0001-post-networks.json", `{
+  "request": {"method": "POST", "path": "/networks", "body": {"name": "demo-net"}},
+  "response": {"status": 201, "body": {"id": "net-1"}}
+}`)
+
+	spec := `
+openapi: 3.0.0
+info:
+  title: Clusters
+  version: 1.0.0
+paths:
+  /clusters:
+    post:
+      operationId: createCluster
+      responses:
+        "201":
+          description: Created
+      x-cli-workflow:
+        workflows:
+          create-network:
+            steps:
+              - id: create
+                api-call:
+                  method: POST
+                  endpoint: http://api.example.com/networks
+                  body:
+                    name: "{flags.name}"
+            outputs:
+              network_id: steps.create.response.id
+        steps:
+          - id: region
+            type: prompt
+            prompt:
+              type: select
+              message: Region
+              options: [us-east-1, eu-west-1]
+              default: eu-west-1
+              variable: region
+          - id: approve
+            type: prompt
+            depends-on: [region]
+            prompt:
+              type: approval
+              message: "Create {flags.name} in {region}?"
+          - id: check
+            type: assert
+            depends-on: [approve]
+            assert:
+              condition: flags.replicas <= 3
+              message: "Too many replicas: {flags.replicas}"
+          - id: network
+            type: call
+            depends-on: [check]
+            call:
+              workflow: create-network
+              inputs:
+                name: "{flags.name}-net"
+`
+	parsed, err := openapi.NewParser().Parse(context.Background(), []byte(spec))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	operations, err := parsed.GetOperations()
+	if err != nil {
+		t.Fatalf("GetOperations() error = %v", err)
+	}
+	resolve := func(operationID string) (*Workflow, error) {
+		for _, op := range operations {
+			if op.OperationID == operationID {
+				return FromCLIWorkflow(op.CLIWorkflow)
+			}
+		}
+		return nil, fmt.Errorf("unknown operation %s", operationID)
+	}
+
+	suitePath := writeHarnessFile(t, dir, "suite.yaml", `
+fixtures: fixtures
+match: [method, path, body]
+cases:
+  - name: prompts take their defaults and the call returns its outputs
+    operation: createCluster
+    flags:
+      name: demo
+      replicas: 3
+    expect:
+      steps:
+        region:
+          value: eu-west-1
+        approve:
+          approved: true
+        check:
+          passed: true
+        network:
+          network_id: net-1
+          status: completed
+  - name: a failed assertion fails the workflow
+    operation: createCluster
+    flags:
+      name: demo
+      replicas: 5
+    expect:
+      status: rolled-back
+`)
+
+	suite, err := LoadTestSuite(suitePath)
+	if err != nil {
+		t.Fatalf("LoadTestSuite() error = %v", err)
+	}
+	for _, result := range suite.Run(resolve) {
+		if !result.Passed {
+			t.Errorf("%s: failures: %v", result.Name, result.Failures)
+		}
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	data := map[string]interface{}{
+		"response": map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": "a"}},
+		},
+	}
+
+	if got, ok := lookupPath(data, "response.items.0.id"); !ok || got != "a" {
+		t.Errorf("lookupPath() = %v, %v", got, ok)
+	}
+	if _, ok := lookupPath(data, "response.items.3.id"); ok {
+		t.Error("expected out-of-range index to be not found")
+	}
+	if _, ok := lookupPath(data, "response.missing"); ok {
+		t.Error("expected missing key to be not found")
+	}
+}
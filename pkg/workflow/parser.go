@@ -3,6 +3,7 @@ package workflow
 import (
 	"fmt"
 	"regexp"
+	"sort"
 )
 
 // Parser parses workflow definitions and builds execution graphs.
@@ -39,11 +40,21 @@ func (p *Parser) Parse() (*DAG, error) {
 		return nil, err
 	}
 
+	// Validate named sub-workflows used by call steps
+	if err := p.validateSubWorkflows(); err != nil {
+		return nil, err
+	}
+
 	// Validate: detect cycles
 	if err := p.detectCycles(); err != nil {
 		return nil, err
 	}
 
+	// Validate: nested steps must not create cycles between top-level steps
+	if err := p.dag.detectTopLevelCycles(); err != nil {
+		return nil, err
+	}
+
 	// Calculate node levels for topological ordering
 	if err := p.calculateLevels(); err != nil {
 		return nil, err
@@ -52,18 +63,33 @@ func (p *Parser) Parse() (*DAG, error) {
 	return p.dag, nil
 }
 
+// validateSubWorkflows parses every named sub-workflow so that errors
+// surface before execution starts.
+func (p *Parser) validateSubWorkflows() error {
+	for name, sub := range p.workflow.Workflows {
+		if sub == nil {
+			return fmt.Errorf("workflow %s is empty", name)
+		}
+		if _, err := NewParser(sub).Parse(); err != nil {
+			return fmt.Errorf("workflow %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // createNodes creates DAG nodes for all steps.
 func (p *Parser) createNodes() error {
 	for _, step := range p.workflow.Steps {
-		if err := p.createNodeRecursive(step); err != nil {
+		if err := p.createNodeRecursive(step, ""); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// createNodeRecursive creates nodes recursively for nested steps.
-func (p *Parser) createNodeRecursive(step *Step) error {
+// createNodeRecursive creates nodes recursively for nested steps. parent is
+// the ID of the enclosing step, or empty for top-level steps.
+func (p *Parser) createNodeRecursive(step *Step, parent string) error {
 	if step.ID == "" {
 		return fmt.Errorf("step ID is required")
 	}
@@ -72,10 +98,15 @@ func (p *Parser) createNodeRecursive(step *Step) error {
 		return fmt.Errorf("duplicate step ID: %s", step.ID)
 	}
 
+	if step.Rollback != nil && !step.Type.HasSideEffects() {
+		return fmt.Errorf("step %s: %s steps have no side effects and cannot define a rollback", step.ID, step.Type)
+	}
+
 	node := &DAGNode{
 		Step:         step,
 		Dependencies: make([]string, 0),
 		Dependents:   make([]string, 0),
+		Parent:       parent,
 	}
 
 	p.dag.Nodes[step.ID] = node
@@ -85,12 +116,12 @@ func (p *Parser) createNodeRecursive(step *Step) error {
 	case StepTypeConditional:
 		if step.Conditional != nil {
 			for _, thenStep := range step.Conditional.Then {
-				if err := p.createNodeRecursive(thenStep); err != nil {
+				if err := p.createNodeRecursive(thenStep, step.ID); err != nil {
 					return err
 				}
 			}
 			for _, elseStep := range step.Conditional.Else {
-				if err := p.createNodeRecursive(elseStep); err != nil {
+				if err := p.createNodeRecursive(elseStep, step.ID); err != nil {
 					return err
 				}
 			}
@@ -98,7 +129,7 @@ func (p *Parser) createNodeRecursive(step *Step) error {
 	case StepTypeLoop:
 		if step.Loop != nil {
 			for _, loopStep := range step.Loop.Steps {
-				if err := p.createNodeRecursive(loopStep); err != nil {
+				if err := p.createNodeRecursive(loopStep, step.ID); err != nil {
 					return err
 				}
 			}
@@ -106,7 +137,7 @@ func (p *Parser) createNodeRecursive(step *Step) error {
 	case StepTypeParallel:
 		if step.Parallel != nil {
 			for _, parallelStep := range step.Parallel.Steps {
-				if err := p.createNodeRecursive(parallelStep); err != nil {
+				if err := p.createNodeRecursive(parallelStep, step.ID); err != nil {
 					return err
 				}
 			}
@@ -138,6 +169,12 @@ func (p *Parser) buildExplicitDependencies() error {
 	return nil
 }
 
+// reservedRoots are expression roots that refer to workflow inputs rather
+// than step outputs.
+var reservedRoots = map[string]bool{
+	"flags": true,
+}
+
 // buildImplicitDependencies detects dependencies from output references.
 func (p *Parser) buildImplicitDependencies() error {
 	// Regular expression to find step output references: {step_id.output_name} or {steps.step_id.output_name}
@@ -154,6 +191,9 @@ func (p *Parser) buildImplicitDependencies() error {
 			for _, match := range matches {
 				if len(match) > 1 {
 					refStepID := match[1]
+					if reservedRoots[refStepID] {
+						continue
+					}
 					if refStepID != stepID { // Don't self-reference
 						referencedSteps[refStepID] = true
 					}
@@ -241,6 +281,30 @@ func (p *Parser) collectReferences(step *Step) []string {
 				refs = append(refs, step.Wait.Polling.Endpoint)
 			}
 		}
+	case StepTypePrompt:
+		if step.Prompt != nil {
+			refs = append(refs, step.Prompt.Message)
+			if def, ok := step.Prompt.Default.(string); ok {
+				refs = append(refs, def)
+			}
+		}
+	case StepTypeSet:
+		if step.Set != nil {
+			for _, expr := range step.Set.Variables {
+				refs = append(refs, expr)
+			}
+		}
+	case StepTypeAssert:
+		if step.Assert != nil {
+			refs = append(refs, step.Assert.Condition)
+			if step.Assert.Message != "" {
+				refs = append(refs, step.Assert.Message)
+			}
+		}
+	case StepTypeCall:
+		if step.Call != nil {
+			refs = append(refs, p.collectMapValues(step.Call.Inputs)...)
+		}
 	}
 
 	return refs
@@ -360,30 +424,144 @@ func (p *Parser) calculateLevels() error {
 	return nil
 }
 
-// GetExecutionOrder returns steps in the order they should be executed.
-// Steps at the same level can be executed in parallel.
+// GetExecutionOrder returns top-level steps in the order they should be
+// executed. Steps at the same level can be executed in parallel; within a
+// level, steps keep their declaration order. Nested steps run as part of
+// their enclosing step, so their dependencies on steps outside it are
+// attributed to the enclosing top-level step.
 func (p *Parser) GetExecutionOrder() [][]*Step {
 	if p.dag == nil {
 		return nil
 	}
 
-	// Group steps by level
-	levelMap := make(map[int][]*Step)
-	maxLevel := 0
+	levels := p.dag.topLevelLevels()
 
-	for _, node := range p.dag.Nodes {
-		level := node.Level
+	maxLevel := 0
+	for _, level := range levels {
 		if level > maxLevel {
 			maxLevel = level
 		}
-		levelMap[level] = append(levelMap[level], node.Step)
 	}
 
-	// Convert to ordered slice
 	order := make([][]*Step, maxLevel+1)
-	for level := 0; level <= maxLevel; level++ {
-		order[level] = levelMap[level]
+	for _, step := range p.workflow.Steps {
+		level, ok := levels[step.ID]
+		if !ok {
+			continue
+		}
+		order[level] = append(order[level], step)
 	}
 
 	return order
 }
+
+// RootOf returns the ID of the top-level step that contains stepID. Steps
+// that are not in the DAG are their own root.
+func (d *DAG) RootOf(stepID string) string {
+	for {
+		node, exists := d.Nodes[stepID]
+		if !exists || node.Parent == "" {
+			return stepID
+		}
+		stepID = node.Parent
+	}
+}
+
+// TopLevelDependencies returns the top-level steps each top-level step
+// depends on, including dependencies declared by its nested steps.
+func (d *DAG) TopLevelDependencies() map[string][]string {
+	deps := make(map[string]map[string]bool)
+	for stepID, node := range d.Nodes {
+		root := d.RootOf(stepID)
+		if deps[root] == nil {
+			deps[root] = make(map[string]bool)
+		}
+		for _, depID := range node.Dependencies {
+			if depRoot := d.RootOf(depID); depRoot != root {
+				deps[root][depRoot] = true
+			}
+		}
+	}
+
+	result := make(map[string][]string, len(deps))
+	for root, set := range deps {
+		list := make([]string, 0, len(set))
+		for depID := range set {
+			list = append(list, depID)
+		}
+		sort.Strings(list)
+		result[root] = list
+	}
+	return result
+}
+
+// detectTopLevelCycles reports cycles that only appear once the dependencies
+// of nested steps are attributed to their enclosing top-level steps.
+func (d *DAG) detectTopLevelCycles() error {
+	deps := d.TopLevelDependencies()
+	state := make(map[string]int) // 0 = unvisited, 1 = visiting, 2 = done
+
+	var visit func(string) error
+	visit = func(stepID string) error {
+		switch state[stepID] {
+		case 1:
+			return fmt.Errorf("circular dependency detected involving step: %s", stepID)
+		case 2:
+			return nil
+		}
+		state[stepID] = 1
+		for _, depID := range deps[stepID] {
+			if err := visit(depID); err != nil {
+				return err
+			}
+		}
+		state[stepID] = 2
+		return nil
+	}
+
+	roots := make([]string, 0, len(deps))
+	for stepID := range deps {
+		roots = append(roots, stepID)
+	}
+	sort.Strings(roots)
+
+	for _, stepID := range roots {
+		if err := visit(stepID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topLevelLevels computes the execution level of each top-level step. The
+// graph is known to be acyclic once Parse has succeeded.
+func (d *DAG) topLevelLevels() map[string]int {
+	deps := d.TopLevelDependencies()
+	levels := make(map[string]int, len(deps))
+
+	var levelOf func(string) int
+	levelOf = func(stepID string) int {
+		if level, ok := levels[stepID]; ok {
+			return level
+		}
+
+		// Guard against re-entry on graphs that were never validated.
+		levels[stepID] = 0
+
+		level := 0
+		for _, depID := range deps[stepID] {
+			if depLevel := levelOf(depID) + 1; depLevel > level {
+				level = depLevel
+			}
+		}
+
+		levels[stepID] = level
+		return level
+	}
+
+	for stepID := range deps {
+		levelOf(stepID)
+	}
+
+	return levels
+}
@@ -219,6 +219,46 @@ func TestParser_GetExecutionOrder(t *testing.T) {
 	}
 }
 
+func TestParser_GetExecutionOrder_NestedSteps(t *testing.T) {
+	workflow := &Workflow{
+		Steps: []*Step{
+			{
+				ID:   "each",
+				Type: StepTypeLoop,
+				Loop: &LoopStep{
+					Iterator:   "item",
+					Collection: "[1, 2]",
+					Steps: []*Step{
+						{ID: "inner", Type: StepTypeAPICall, DependsOn: []string{"setup"}},
+					},
+				},
+			},
+			{
+				ID:   "setup",
+				Type: StepTypeAPICall,
+			},
+		},
+	}
+
+	parser := NewParser(workflow)
+	dag, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if root := dag.RootOf("inner"); root != "each" {
+		t.Errorf("RootOf(inner) = %s, want each", root)
+	}
+
+	// Nested steps run as part of their parent; the parent inherits their
+	// dependencies.
+	order := parser.GetExecutionOrder()
+	if len(order) != 2 || len(order[0]) != 1 || order[0][0].ID != "setup" ||
+		len(order[1]) != 1 || order[1][0].ID != "each" {
+		t.Errorf("unexpected execution order: %v", order)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
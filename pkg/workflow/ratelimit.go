@@ -0,0 +1,189 @@
+package workflow
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// minRateFactor is the lowest fraction of the configured rate that adaptive
+// slow-down will reduce a host to.
+const minRateFactor = 1.0 / 16
+
+// HostRateLimiter is a token-bucket rate limiter keyed by request host.
+//
+// Each host gets its own bucket filled at RequestsPerSecond up to Burst
+// tokens. When a host answers 429 Too Many Requests, its rate is halved and,
+// if the response carried a Retry-After header, the host is paused until
+// then. Successful responses gradually restore the configured rate.
+type HostRateLimiter struct {
+	rate  float64
+	burst int
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+
+	// now and sleep are replaceable for tests.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// tokenBucket holds the limiter state for a single host.
+type tokenBucket struct {
+	tokens     float64
+	rate       float64
+	lastRefill time.Time
+	pausedTill time.Time
+}
+
+// NewHostRateLimiter creates a rate limiter allowing requestsPerSecond
+// requests to each host with the given burst size.
+func NewHostRateLimiter(requestsPerSecond float64, burst int) *HostRateLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &HostRateLimiter{
+		rate:    requestsPerSecond,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+// NewHostRateLimiterFromConfig creates a rate limiter from workflow settings.
+// It returns nil when rate limiting is not configured.
+func NewHostRateLimiterFromConfig(config *RateLimitConfig) *HostRateLimiter {
+	if config == nil || config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return NewHostRateLimiter(config.RequestsPerSecond, config.Burst)
+}
+
+// Wait blocks until a request to host is allowed.
+func (l *HostRateLimiter) Wait(host string) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+
+	for {
+		delay := l.reserve(host)
+		if delay <= 0 {
+			return
+		}
+		l.sleep(delay)
+	}
+}
+
+// reserve takes a token for host if one is available and otherwise returns
+// how long the caller should wait before trying again.
+func (l *HostRateLimiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket := l.bucket(host, now)
+
+	if now.Before(bucket.pausedTill) {
+		return bucket.pausedTill.Sub(now)
+	}
+
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens += elapsed * bucket.rate
+	if bucket.tokens > float64(l.burst) {
+		bucket.tokens = float64(l.burst)
+	}
+	bucket.lastRefill = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0
+	}
+
+	missing := 1 - bucket.tokens
+	return time.Duration(missing / bucket.rate * float64(time.Second))
+}
+
+// bucket returns the bucket for host, creating it if needed. Callers must
+// hold l.mu.
+func (l *HostRateLimiter) bucket(host string, now time.Time) *tokenBucket {
+	bucket, exists := l.buckets[host]
+	if !exists {
+		bucket = &tokenBucket{
+			tokens:     float64(l.burst),
+			rate:       l.rate,
+			lastRefill: now,
+		}
+		l.buckets[host] = bucket
+	}
+	return bucket
+}
+
+// Observe adjusts the rate for host based on a response. A 429 halves the
+// host's rate and honours Retry-After; any other status slowly restores it.
+func (l *HostRateLimiter) Observe(host string, resp *http.Response) {
+	if l == nil || l.rate <= 0 || resp == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	bucket := l.bucket(host, now)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		bucket.rate /= 2
+		if floor := l.rate * minRateFactor; bucket.rate < floor {
+			bucket.rate = floor
+		}
+		bucket.tokens = 0
+
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now); retryAfter > 0 {
+			bucket.pausedTill = now.Add(retryAfter)
+		}
+		return
+	}
+
+	if bucket.rate < l.rate {
+		bucket.rate += l.rate * 0.1
+		if bucket.rate > l.rate {
+			bucket.rate = l.rate
+		}
+	}
+}
+
+// CurrentRate returns the effective requests per second for host.
+func (l *HostRateLimiter) CurrentRate(host string) float64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, exists := l.buckets[host]; exists {
+		return bucket.rate
+	}
+	return l.rate
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for rate limiter tests.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept += d
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter(rps float64, burst int) (*HostRateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := NewHostRateLimiter(rps, burst)
+	limiter.now = clock.Now
+	limiter.sleep = clock.Sleep
+	return limiter, clock
+}
+
+func TestHostRateLimiter_BurstThenRate(t *testing.T) {
+	limiter, clock := newTestLimiter(10, 3)
+
+	for i := 0; i < 3; i++ {
+		limiter.Wait("api.example.com")
+	}
+	if clock.slept != 0 {
+		t.Errorf("expected burst requests not to wait, slept %v", clock.slept)
+	}
+
+	limiter.Wait("api.example.com")
+	if clock.slept != 100*time.Millisecond {
+		t.Errorf("expected 100ms wait after burst, slept %v", clock.slept)
+	}
+}
+
+func TestHostRateLimiter_PerHostBuckets(t *testing.T) {
+	limiter, clock := newTestLimiter(1, 1)
+
+	limiter.Wait("a.example.com")
+	limiter.Wait("b.example.com")
+
+	if clock.slept != 0 {
+		t.Errorf("expected independent hosts not to wait, slept %v", clock.slept)
+	}
+}
+
+func TestHostRateLimiter_AdaptiveSlowDown(t *testing.T) {
+	limiter, _ := newTestLimiter(8, 1)
+	host := "api.example.com"
+
+	limiter.Observe(host, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if rate := limiter.CurrentRate(host); rate != 4 {
+		t.Errorf("expected rate to halve to 4, got %v", rate)
+	}
+
+	for i := 0; i < 10; i++ {
+		limiter.Observe(host, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	}
+	if rate := limiter.CurrentRate(host); rate != 8*minRateFactor {
+		t.Errorf("expected rate floor %v, got %v", 8*minRateFactor, rate)
+	}
+
+	for i := 0; i < 20; i++ {
+		limiter.Observe(host, &http.Response{StatusCode: http.StatusOK})
+	}
+	if rate := limiter.CurrentRate(host); rate != 8 {
+		t.Errorf("expected rate to recover to 8, got %v", rate)
+	}
+}
+
+func TestHostRateLimiter_RetryAfter(t *testing.T) {
+	limiter, clock := newTestLimiter(100, 5)
+	host := "api.example.com"
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"2"}},
+	}
+	limiter.Observe(host, resp)
+	limiter.Wait(host)
+
+	if clock.slept < 2*time.Second {
+		t.Errorf("expected to wait at least 2s for Retry-After, slept %v", clock.slept)
+	}
+}
+
+func TestHostRateLimiter_NilIsNoop(t *testing.T) {
+	var limiter *HostRateLimiter
+	limiter.Wait("api.example.com")
+	limiter.Observe("api.example.com", &http.Response{StatusCode: http.StatusTooManyRequests})
+
+	if NewHostRateLimiterFromConfig(nil) != nil {
+		t.Error("expected nil limiter for nil config")
+	}
+	if NewHostRateLimiterFromConfig(&RateLimitConfig{}) != nil {
+		t.Error("expected nil limiter for zero rate")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"http date", now.Add(3 * time.Second).Format(http.TimeFormat), 3 * time.Second},
+		{"invalid", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
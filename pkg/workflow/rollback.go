@@ -2,69 +2,177 @@ package workflow
 
 import (
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CliForge/cliforge/pkg/secrets"
 )
 
-// RollbackManager manages rollback execution.
-type RollbackManager struct {
-	// Configuration
-	continueOnError bool
-}
+// CompensationStatus is the outcome of a single rollback action.
+type CompensationStatus string
 
-// NewRollbackManager creates a new rollback manager.
-func NewRollbackManager() *RollbackManager {
-	return &RollbackManager{
-		continueOnError: true, // Continue rolling back even if one rollback fails
-	}
+const (
+	// CompensationPending indicates the compensation has not run yet.
+	CompensationPending CompensationStatus = "pending"
+	// CompensationSucceeded indicates the compensation completed.
+	CompensationSucceeded CompensationStatus = "succeeded"
+	// CompensationFailed indicates the compensation failed after all retries.
+	CompensationFailed CompensationStatus = "failed"
+	// CompensationSkipped indicates the compensation was not run because an
+	// earlier one failed and the rollback stopped.
+	CompensationSkipped CompensationStatus = "skipped"
+)
+
+// CompensationRecord describes one rollback action and how it went.
+type CompensationRecord struct {
+	// ID identifies the record within a report, e.g. "create-vm[2]" for the
+	// second iteration of a loop.
+	ID        string             `json:"id"`
+	StepID    string             `json:"step-id"`
+	ParentID  string             `json:"parent-id,omitempty"`
+	Iteration int                `json:"iteration,omitempty"`
+	Status    CompensationStatus `json:"status"`
+	Attempts  int                `json:"attempts,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Duration  time.Duration      `json:"duration,omitempty"`
+
+	// Action is the compensating step.
+	Action *Step `json:"action,omitempty"`
+
+	// ContextRef names the context snapshot in the report the action runs in.
+	ContextRef string `json:"context,omitempty"`
+
+	// Masked is set when secrets were masked in the saved context of the
+	// action, which then cannot be retried from the report.
+	Masked bool `json:"masked,omitempty"`
 }
 
-// ExecuteRollback executes rollback actions in reverse order.
-func (rm *RollbackManager) ExecuteRollback(ctx *ExecutionContext, executor *StepExecutor) error {
-	actions := ctx.GetRollbackActions()
+// ContextSnapshot is the part of an execution context a compensation needs
+// to run again after the process that recorded it has exited.
+type ContextSnapshot struct {
+	Flags     map[string]interface{}            `json:"flags,omitempty"`
+	Variables map[string]interface{}            `json:"variables,omitempty"`
+	Steps     map[string]map[string]interface{} `json:"steps,omitempty"`
+}
 
-	if len(actions) == 0 {
-		// No rollback actions to execute
-		return nil
-	}
+// RollbackReport is the result of a rollback, saved with the workflow state.
+type RollbackReport struct {
+	StartTime time.Time `json:"start-time"`
+	EndTime   time.Time `json:"end-time"`
 
-	fmt.Printf("Executing rollback for %d steps...\n", len(actions))
+	// Compensations are listed in the order they were scheduled.
+	Compensations []*CompensationRecord `json:"compensations"`
 
-	var errors []error
+	// Contexts holds the snapshots referenced by CompensationRecord.ContextRef.
+	Contexts map[string]*ContextSnapshot `json:"contexts,omitempty"`
 
-	// Execute rollback actions in reverse order (LIFO)
-	for i, action := range actions {
-		fmt.Printf("Rolling back step %s (%d/%d)...\n", action.StepID, i+1, len(actions))
+	// Retry is the retry policy of compensations that do not declare
+	// their own, applied again when they are retried later.
+	Retry *RetryConfig `json:"retry,omitempty"`
+}
 
-		if action.Action == nil {
-			// No rollback action defined for this step
-			continue
+// Incomplete returns the compensations that failed or never ran.
+func (r *RollbackReport) Incomplete() []*CompensationRecord {
+	var records []*CompensationRecord
+	for _, record := range r.Compensations {
+		if record.Status != CompensationSucceeded {
+			records = append(records, record)
 		}
+	}
+	return records
+}
 
-		// Execute the rollback action
-		result, err := executor.ExecuteStep(action.Action, ctx)
+// RollbackObserver is notified as compensations run. Methods may be called
+// from several goroutines when independent branches roll back in parallel.
+type RollbackObserver interface {
+	OnRollbackStart(records []*CompensationRecord)
+	OnCompensationStart(record *CompensationRecord)
+	OnCompensationFinish(record *CompensationRecord)
+	OnRollbackComplete(report *RollbackReport)
+}
 
-		if err != nil || (result != nil && !result.Success) {
-			rollbackErr := fmt.Errorf("rollback of step %s failed: %w", action.StepID, err)
-			errors = append(errors, rollbackErr)
+// textRollbackObserver writes rollback progress as plain lines.
+type textRollbackObserver struct {
+	w     io.Writer
+	total int
+	done  int
+	mu    sync.Mutex
+}
 
-			// Log the error
-			fmt.Printf("Warning: %v\n", rollbackErr)
+// NewTextRollbackObserver returns an observer that writes one line per
+// compensation to w.
+func NewTextRollbackObserver(w io.Writer) RollbackObserver {
+	return &textRollbackObserver{w: w}
+}
 
-			if !rm.continueOnError {
-				// Stop rollback on first error
-				return fmt.Errorf("rollback aborted after %d actions: %w", i+1, rollbackErr)
-			}
-		} else {
-			fmt.Printf("Successfully rolled back step %s\n", action.StepID)
-		}
+func (o *textRollbackObserver) OnRollbackStart(records []*CompensationRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.total = len(records)
+	o.done = 0
+	_, _ = fmt.Fprintf(o.w, "Executing rollback for %d steps...\n", len(records))
+}
+
+func (o *textRollbackObserver) OnCompensationStart(record *CompensationRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done++
+	_, _ = fmt.Fprintf(o.w, "Rolling back step %s (%d/%d)...\n", record.ID, o.done, o.total)
+}
+
+func (o *textRollbackObserver) OnCompensationFinish(record *CompensationRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch record.Status {
+	case CompensationSucceeded:
+		_, _ = fmt.Fprintf(o.w, "Successfully rolled back step %s\n", record.ID)
+	case CompensationFailed:
+		_, _ = fmt.Fprintf(o.w, "Warning: rollback of step %s failed: %s\n", record.ID, record.Error)
 	}
+}
 
-	// Return combined errors if any occurred
-	if len(errors) > 0 {
-		return fmt.Errorf("rollback completed with %d error(s): %v", len(errors), errors)
+func (o *textRollbackObserver) OnRollbackComplete(report *RollbackReport) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if incomplete := len(report.Incomplete()); incomplete > 0 {
+		_, _ = fmt.Fprintf(o.w, "Rollback incomplete: %d of %d compensation(s) did not succeed\n", incomplete, len(report.Compensations))
+		return
 	}
+	_, _ = fmt.Fprintf(o.w, "Rollback completed successfully\n")
+}
 
-	fmt.Printf("Rollback completed successfully\n")
-	return nil
+// RollbackManager manages rollback execution.
+//
+// Compensations follow the workflow DAG in reverse: a top-level step is
+// rolled back only after every step that depends on it. Within a loop or
+// parallel step, each iteration or branch is undone in reverse recording
+// order, and independent iterations and branches may be undone
+// concurrently.
+type RollbackManager struct {
+	// Configuration
+	continueOnError bool
+	dag             *DAG
+	parallel        bool
+	maxConcurrency  int
+	defaultRetry    *RetryConfig
+	observer        RollbackObserver
+
+	// sleep waits between compensation attempts; replaced in tests.
+	sleep func(time.Duration)
+}
+
+// NewRollbackManager creates a new rollback manager. It reports no
+// progress until an observer is set.
+func NewRollbackManager() *RollbackManager {
+	return &RollbackManager{
+		continueOnError: true, // Continue rolling back even if one rollback fails
+		sleep:           time.Sleep,
+	}
 }
 
 // SetContinueOnError sets whether to continue rollback on errors.
@@ -72,6 +180,41 @@ func (rm *RollbackManager) SetContinueOnError(continueOnError bool) {
 	rm.continueOnError = continueOnError
 }
 
+// SetDAG sets the workflow graph used to order compensations. Without a
+// graph, actions are undone strictly in reverse recording order.
+func (rm *RollbackManager) SetDAG(dag *DAG) {
+	rm.dag = dag
+}
+
+// SetParallel allows independent top-level steps to be rolled back
+// concurrently.
+func (rm *RollbackManager) SetParallel(parallel bool) {
+	rm.parallel = parallel
+}
+
+// SetMaxConcurrency limits concurrent compensations; 0 means unlimited.
+func (rm *RollbackManager) SetMaxConcurrency(limit int) {
+	rm.maxConcurrency = limit
+}
+
+// SetDefaultRetry sets the retry policy for compensations that do not
+// declare their own.
+func (rm *RollbackManager) SetDefaultRetry(retry *RetryConfig) {
+	rm.defaultRetry = retry
+}
+
+// SetObserver replaces the observer notified of rollback progress; nil
+// reports nothing.
+func (rm *RollbackManager) SetObserver(observer RollbackObserver) {
+	rm.observer = observer
+}
+
+// ExecuteRollback executes rollback actions in reverse dependency order.
+func (rm *RollbackManager) ExecuteRollback(ctx *ExecutionContext, executor *StepExecutor) error {
+	_, err := rm.ExecuteRollbackWithReport(ctx, executor)
+	return err
+}
+
 // RollbackStatus represents the status of a rollback operation.
 type RollbackStatus struct {
 	TotalActions      int
@@ -83,56 +226,547 @@ type RollbackStatus struct {
 
 // ExecuteRollbackWithStatus executes rollback and returns detailed status.
 func (rm *RollbackManager) ExecuteRollbackWithStatus(ctx *ExecutionContext, executor *StepExecutor) (*RollbackStatus, error) {
-	actions := ctx.GetRollbackActions()
+	report, err := rm.ExecuteRollbackWithReport(ctx, executor)
 
 	status := &RollbackStatus{
-		TotalActions: len(actions),
+		TotalActions: len(report.Compensations),
 		Errors:       make([]error, 0),
 	}
+	for _, record := range report.Compensations {
+		switch record.Status {
+		case CompensationSucceeded:
+			status.ExecutedActions++
+			status.SuccessfulActions++
+		case CompensationFailed:
+			status.ExecutedActions++
+			status.FailedActions++
+			status.Errors = append(status.Errors, fmt.Errorf("rollback of step %s failed: %s", record.ID, record.Error))
+		}
+	}
+
+	return status, err
+}
+
+// ExecuteRollbackWithReport executes the rollback actions recorded in ctx
+// and returns a report of every compensation.
+func (rm *RollbackManager) ExecuteRollbackWithReport(ctx *ExecutionContext, executor *StepExecutor) (*RollbackReport, error) {
+	report := &RollbackReport{
+		StartTime: time.Now(),
+		Contexts:  make(map[string]*ContextSnapshot),
+		Retry:     rm.defaultRetry,
+	}
+
+	// Actions in recording order.
+	actions := ctx.GetRollbackActions()
+	for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+		actions[i], actions[j] = actions[j], actions[i]
+	}
 
 	if len(actions) == 0 {
-		return status, nil
+		report.EndTime = report.StartTime
+		return report, nil
+	}
+
+	plan := rm.plan(actions)
+
+	contexts := make(map[string]*ExecutionContext)
+	refs := make(map[*ExecutionContext]string)
+	masked := make(map[string]bool)
+	ids := make(map[string]int)
+	records := make(map[*RollbackAction]*CompensationRecord, len(actions))
+
+	for _, wave := range plan {
+		for _, root := range wave {
+			for _, group := range root.groups {
+				for _, action := range group {
+					actionCtx := actionContext(action, ctx)
+					ref, seen := refs[actionCtx]
+					if !seen {
+						ref = "root"
+						if actionCtx != ctx {
+							ref = fmt.Sprintf("ctx-%d", len(refs))
+						}
+						refs[actionCtx] = ref
+						contexts[ref] = actionCtx
+						report.Contexts[ref], masked[ref] = snapshotContext(actionCtx)
+					}
+
+					record := &CompensationRecord{
+						ID:         compensationID(action, ids),
+						StepID:     action.StepID,
+						ParentID:   action.ParentID,
+						Iteration:  action.Iteration,
+						Status:     CompensationPending,
+						Action:     action.Action,
+						ContextRef: ref,
+						Masked:     masked[ref] && action.Action != nil,
+					}
+					records[action] = record
+					report.Compensations = append(report.Compensations, record)
+				}
+			}
+		}
+	}
+
+	rm.notifyStart(report.Compensations)
+
+	var stopped atomic.Bool
+	var mu sync.Mutex
+	var firstErr error
+	executed := 0
+
+	run := func(action *RollbackAction) {
+		record := records[action]
+		if stopped.Load() {
+			record.Status = CompensationSkipped
+			if rm.observer != nil {
+				rm.observer.OnCompensationFinish(record)
+			}
+			return
+		}
+		rm.compensate(record, contexts[record.ContextRef], executor, rm.defaultRetry)
+
+		mu.Lock()
+		executed++
+		if record.Status == CompensationFailed && !rm.continueOnError && firstErr == nil {
+			firstErr = fmt.Errorf("rollback aborted after %d actions: rollback of step %s failed: %s", executed, record.ID, record.Error)
+			stopped.Store(true)
+		}
+		mu.Unlock()
+	}
+
+	for _, wave := range plan {
+		runBounded(len(wave), rm.maxConcurrency, nil, func(i int) {
+			root := wave[i]
+			limit := 1
+			if root.concurrent {
+				limit = rm.maxConcurrency
+			}
+			runBounded(len(root.groups), limit, nil, func(g int) {
+				for _, action := range root.groups[g] {
+					run(action)
+				}
+			})
+		})
+	}
+
+	report.EndTime = time.Now()
+	rm.notifyComplete(report)
+
+	if firstErr != nil {
+		return report, firstErr
+	}
+	if failed := countStatus(report, CompensationFailed); failed > 0 {
+		return report, fmt.Errorf("rollback completed with %d error(s)", failed)
+	}
+	return report, nil
+}
+
+// RetryFailed re-runs the compensations in report that failed or never
+// ran, in their original order, and updates report in place. Without a
+// default retry policy of its own, the manager uses the report's.
+// Compensations whose saved context had secrets masked are not run, since
+// they would send the masked values; they are reported in the error.
+func (rm *RollbackManager) RetryFailed(report *RollbackReport, executor *StepExecutor) error {
+	var incomplete []*CompensationRecord
+	var refused []string
+	for _, record := range report.Incomplete() {
+		if record.Masked {
+			refused = append(refused, record.ID)
+			continue
+		}
+		incomplete = append(incomplete, record)
+	}
+	var refusedErr error
+	if len(refused) > 0 {
+		refusedErr = fmt.Errorf("cannot retry %s: secrets in the saved context were masked, so undo them by hand", strings.Join(refused, ", "))
+	}
+	if len(incomplete) == 0 {
+		return refusedErr
+	}
+
+	defaultRetry := rm.defaultRetry
+	if defaultRetry == nil {
+		defaultRetry = report.Retry
+	}
+
+	contexts := make(map[string]*ExecutionContext)
+	for ref, snapshot := range report.Contexts {
+		contexts[ref] = snapshot.restore()
+	}
+
+	for _, record := range incomplete {
+		record.Status = CompensationPending
+		record.Error = ""
 	}
 
-	fmt.Printf("Executing rollback for %d steps...\n", len(actions))
+	rm.notifyStart(incomplete)
+
+	for i, record := range incomplete {
+		ctx, exists := contexts[record.ContextRef]
+		if !exists {
+			ctx = NewExecutionContext(nil)
+		}
+		rm.compensate(record, ctx, executor, defaultRetry)
+
+		if record.Status == CompensationFailed && !rm.continueOnError {
+			for _, rest := range incomplete[i+1:] {
+				rest.Status = CompensationSkipped
+			}
+			break
+		}
+	}
+
+	report.EndTime = time.Now()
+	rm.notifyComplete(report)
+
+	if refusedErr != nil {
+		return refusedErr
+	}
+	if remaining := len(report.Incomplete()); remaining > 0 {
+		return fmt.Errorf("rollback completed with %d error(s)", remaining)
+	}
+	return nil
+}
+
+// compensate runs a single compensation, retrying it according to its
+// retry policy, or defaultRetry when it has none.
+func (rm *RollbackManager) compensate(record *CompensationRecord, ctx *ExecutionContext, executor *StepExecutor, defaultRetry *RetryConfig) {
+	if rm.observer != nil {
+		rm.observer.OnCompensationStart(record)
+	}
+
+	start := time.Now()
+	defer func() {
+		record.Duration = time.Since(start)
+		if rm.observer != nil {
+			rm.observer.OnCompensationFinish(record)
+		}
+	}()
+
+	if record.Action == nil {
+		// No rollback action defined for this step
+		record.Status = CompensationSucceeded
+		return
+	}
+
+	retry := record.Action.Retry
+	if retry == nil {
+		retry = defaultRetry
+	}
+	maxAttempts := 1
+	if retry != nil && retry.MaxAttempts > 0 {
+		maxAttempts = retry.MaxAttempts
+	}
+
+	// The manager owns retries so that any failure is retried, not only
+	// the ones ExecuteStep considers retryable.
+	executor = executor.withoutEvents()
+	action := *record.Action
+	action.Retry = nil
+	backoffStep := &Step{Retry: retry}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			rm.sleep(executor.calculateBackoff(backoffStep, attempt-1))
+		}
+		record.Attempts = attempt
+
+		result, err := executor.ExecuteStep(&action, ctx)
+		if err == nil && result != nil && result.Success {
+			record.Status = CompensationSucceeded
+			record.Error = ""
+			return
+		}
+
+		record.Status = CompensationFailed
+		switch {
+		case err != nil:
+			record.Error = err.Error()
+		case result != nil && result.Error != nil:
+			record.Error = result.Error.Error()
+		default:
+			record.Error = "compensation did not succeed"
+		}
+	}
+}
+
+// rollbackRoot is the set of compensations belonging to one top-level step.
+type rollbackRoot struct {
+	id string
+	// groups are undone one after the other unless concurrent is set; each
+	// group is undone in reverse recording order.
+	groups     [][]*RollbackAction
+	concurrent bool
+	// last is the recording index of the root's most recent action.
+	last int
+}
+
+// plan orders the actions (given in recording order) into waves of
+// top-level steps. Every step in a wave may be rolled back once all
+// earlier waves are done.
+func (rm *RollbackManager) plan(actions []*RollbackAction) [][]*rollbackRoot {
+	roots := make(map[string]*rollbackRoot)
+	groupIndex := make(map[string]map[string]int)
+	var order []*rollbackRoot
 
-	// Execute rollback actions in reverse order (LIFO)
 	for i, action := range actions {
-		fmt.Printf("Rolling back step %s (%d/%d)...\n", action.StepID, i+1, len(actions))
-		status.ExecutedActions++
+		rootID := rm.rootOf(action)
+		root, exists := roots[rootID]
+		if !exists {
+			root = &rollbackRoot{id: rootID, concurrent: rm.concurrentRoot(rootID)}
+			roots[rootID] = root
+			groupIndex[rootID] = make(map[string]int)
+			order = append(order, root)
+		}
+		root.last = i
 
-		if action.Action == nil {
-			// No rollback action defined for this step
-			status.SuccessfulActions++
+		key := ""
+		if root.concurrent {
+			key = rm.groupKey(action, rootID)
+		}
+		g, exists := groupIndex[rootID][key]
+		if !exists {
+			g = len(root.groups)
+			groupIndex[rootID][key] = g
+			root.groups = append(root.groups, nil)
+		}
+		// Prepend so each group is in reverse recording order.
+		root.groups[g] = append([]*RollbackAction{action}, root.groups[g]...)
+	}
+
+	for _, root := range order {
+		// Later iterations and branches first.
+		for i, j := 0, len(root.groups)-1; i < j; i, j = i+1, j-1 {
+			root.groups[i], root.groups[j] = root.groups[j], root.groups[i]
+		}
+	}
+
+	ancestors := rm.ancestors()
+
+	var waves [][]*rollbackRoot
+	pending := order
+	for len(pending) > 0 {
+		var ready, blocked []*rollbackRoot
+		for _, root := range pending {
+			if dependedOn(root.id, pending, ancestors) {
+				blocked = append(blocked, root)
+			} else {
+				ready = append(ready, root)
+			}
+		}
+
+		sort.SliceStable(ready, func(i, j int) bool { return ready[i].last > ready[j].last })
+
+		if rm.parallel {
+			waves = append(waves, ready)
+			pending = blocked
 			continue
 		}
 
-		// Execute the rollback action
-		result, err := executor.ExecuteStep(action.Action, ctx)
+		// Sequential rollback undoes the most recently recorded ready
+		// step first, then re-evaluates.
+		waves = append(waves, ready[:1])
+		pending = append(ready[1:], blocked...)
+	}
 
-		if err != nil || (result != nil && !result.Success) {
-			rollbackErr := fmt.Errorf("rollback of step %s failed: %w", action.StepID, err)
-			status.Errors = append(status.Errors, rollbackErr)
-			status.FailedActions++
+	return waves
+}
+
+// rootOf returns the top-level step an action belongs to.
+func (rm *RollbackManager) rootOf(action *RollbackAction) string {
+	stepID := action.StepID
+	if action.ParentID != "" {
+		stepID = action.ParentID
+	}
+	if rm.dag == nil {
+		return stepID
+	}
+	return rm.dag.RootOf(stepID)
+}
 
-			// Log the error
-			fmt.Printf("Warning: %v\n", rollbackErr)
+// concurrentRoot reports whether the iterations or branches of a top-level
+// step are independent of each other.
+func (rm *RollbackManager) concurrentRoot(rootID string) bool {
+	if rm.dag == nil {
+		return false
+	}
+	node, exists := rm.dag.Nodes[rootID]
+	if !exists || node.Step == nil {
+		return false
+	}
+	switch node.Step.Type {
+	case StepTypeParallel:
+		return true
+	case StepTypeLoop:
+		return node.Step.Loop != nil && node.Step.Loop.Parallel
+	default:
+		return false
+	}
+}
+
+// groupKey identifies the loop iteration or parallel branch of an action
+// within its top-level step.
+func (rm *RollbackManager) groupKey(action *RollbackAction, rootID string) string {
+	if node := rm.dag.Nodes[rootID]; node.Step.Type == StepTypeLoop {
+		return fmt.Sprintf("%d", action.Iteration)
+	}
+
+	stepID := action.StepID
+	if action.ParentID != "" {
+		stepID = action.ParentID
+	}
+	for {
+		node, exists := rm.dag.Nodes[stepID]
+		if !exists || node.Parent == "" || node.Parent == rootID {
+			return stepID
+		}
+		stepID = node.Parent
+	}
+}
 
-			if !rm.continueOnError {
-				// Stop rollback on first error
-				return status, fmt.Errorf("rollback aborted after %d actions: %w", i+1, rollbackErr)
+// ancestors returns, for every top-level step, the top-level steps it
+// depends on directly or transitively.
+func (rm *RollbackManager) ancestors() map[string]map[string]bool {
+	result := make(map[string]map[string]bool)
+	if rm.dag == nil {
+		return result
+	}
+
+	deps := rm.dag.TopLevelDependencies()
+	var visit func(stepID string) map[string]bool
+	visit = func(stepID string) map[string]bool {
+		if set, done := result[stepID]; done {
+			return set
+		}
+		set := make(map[string]bool)
+		result[stepID] = set
+		for _, dep := range deps[stepID] {
+			set[dep] = true
+			for ancestor := range visit(dep) {
+				set[ancestor] = true
 			}
-		} else {
-			fmt.Printf("Successfully rolled back step %s\n", action.StepID)
-			status.SuccessfulActions++
+		}
+		return set
+	}
+	for stepID := range deps {
+		visit(stepID)
+	}
+
+	return result
+}
+
+// dependedOn reports whether any other pending root depends on rootID.
+func dependedOn(rootID string, pending []*rollbackRoot, ancestors map[string]map[string]bool) bool {
+	for _, other := range pending {
+		if other.id != rootID && ancestors[other.id][rootID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (rm *RollbackManager) notifyStart(records []*CompensationRecord) {
+	if rm.observer != nil {
+		rm.observer.OnRollbackStart(records)
+	}
+}
+
+func (rm *RollbackManager) notifyComplete(report *RollbackReport) {
+	if rm.observer != nil {
+		rm.observer.OnRollbackComplete(report)
+	}
+}
+
+// compensationID returns a unique, readable ID for an action's record.
+func compensationID(action *RollbackAction, seen map[string]int) string {
+	id := action.StepID
+	if action.Iteration > 0 {
+		id = fmt.Sprintf("%s[%d]", action.StepID, action.Iteration)
+	}
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id = fmt.Sprintf("%s#%d", id, n)
+	}
+	return id
+}
+
+// countStatus counts the compensations in report with the given status.
+func countStatus(report *RollbackReport, status CompensationStatus) int {
+	count := 0
+	for _, record := range report.Compensations {
+		if record.Status == status {
+			count++
 		}
 	}
+	return count
+}
 
-	// Return error if any rollbacks failed
-	if status.FailedActions > 0 {
-		return status, fmt.Errorf("rollback completed with %d error(s)", status.FailedActions)
+// actionContext returns the context a rollback action should run in.
+func actionContext(action *RollbackAction, ctx *ExecutionContext) *ExecutionContext {
+	if action.Context != nil {
+		return action.Context
+	}
+	return ctx
+}
+
+// snapshotContext captures the flags, variables and step outputs of ctx.
+// Secrets are masked, since the snapshot is saved to disk, and masked
+// reports whether there were any; compensations in such a context cannot
+// be retried from the report.
+func snapshotContext(ctx *ExecutionContext) (snapshot *ContextSnapshot, masked bool) {
+	ctx.mu.RLock()
+	defer ctx.mu.RUnlock()
+
+	detector := snapshotDetector()
+	mask := func(values map[string]interface{}) map[string]interface{} {
+		result := maskValues(detector, values)
+		if !reflect.DeepEqual(result, values) {
+			masked = true
+		}
+		return result
 	}
 
-	fmt.Printf("Rollback completed successfully\n")
-	return status, nil
+	snapshot = &ContextSnapshot{
+		Flags:     mask(ctx.Flags),
+		Variables: mask(ctx.Variables),
+		Steps:     make(map[string]map[string]interface{}, len(ctx.StepResults)),
+	}
+	for stepID, result := range ctx.StepResults {
+		if result != nil {
+			snapshot.Steps[stepID] = mask(result.Output)
+		}
+	}
+	return snapshot, masked
+}
+
+// snapshotDetector returns the detector masking secrets in snapshots.
+var snapshotDetector = sync.OnceValue(func() *secrets.Detector {
+	detector, err := secrets.NewDetector(secrets.DefaultSecretsBehavior())
+	if err != nil {
+		// The default patterns are static and always compile.
+		panic(fmt.Sprintf("workflow: invalid default secret patterns: %v", err))
+	}
+	return detector
+})
+
+// maskValues returns a copy of values with secrets masked.
+func maskValues(detector *secrets.Detector, values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	masked, _ := detector.MaskJSON(values).(map[string]interface{})
+	return masked
+}
+
+// restore rebuilds an execution context from a snapshot.
+func (s *ContextSnapshot) restore() *ExecutionContext {
+	ctx := NewExecutionContext(s.Flags)
+	for k, v := range s.Variables {
+		ctx.Variables[k] = v
+	}
+	for stepID, output := range s.Steps {
+		ctx.StepResults[stepID] = &StepResult{StepID: stepID, Success: true, Output: output}
+	}
+	return ctx
 }
@@ -2,7 +2,16 @@ package workflow
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestRollbackManager_ExecuteRollback_NoActions(t *testing.T) {
@@ -395,3 +404,423 @@ func TestRollbackManager_ExecuteOrder(t *testing.T) {
 		t.Errorf("expected third action to be step1, got %s", actions[2].StepID)
 	}
 }
+
+// recordingObserver collects compensation IDs in the order they finish.
+type recordingObserver struct {
+	mu       sync.Mutex
+	finished []string
+	report   *RollbackReport
+}
+
+func (o *recordingObserver) OnRollbackStart(_ []*CompensationRecord) {}
+
+func (o *recordingObserver) OnCompensationStart(_ *CompensationRecord) {}
+
+func (o *recordingObserver) OnCompensationFinish(record *CompensationRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.finished = append(o.finished, record.ID+":"+string(record.Status))
+}
+
+func (o *recordingObserver) OnRollbackComplete(report *RollbackReport) {
+	o.report = report
+}
+
+func TestRollbackManager_ReverseDAGOrder(t *testing.T) {
+	wf := &Workflow{Steps: []*Step{
+		{ID: "a", Type: StepTypeNoop},
+		{ID: "b", Type: StepTypeNoop, DependsOn: []string{"a"}},
+		{ID: "c", Type: StepTypeNoop},
+	}}
+	dag, err := NewParser(wf).Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	ctx := NewExecutionContext(nil)
+	for _, id := range []string{"a", "c", "b"} {
+		ctx.AddRollbackAction(&RollbackAction{StepID: id, Action: &Step{ID: "undo-" + id, Type: StepTypeNoop}})
+	}
+
+	observer := &recordingObserver{}
+	rm := NewRollbackManager()
+	rm.SetDAG(dag)
+	rm.SetObserver(observer)
+
+	if err := rm.ExecuteRollback(ctx, NewStepExecutor(nil, nil)); err != nil {
+		t.Fatalf("ExecuteRollback() error = %v", err)
+	}
+
+	want := []string{"b:succeeded", "c:succeeded", "a:succeeded"}
+	if fmt.Sprint(observer.finished) != fmt.Sprint(want) {
+		t.Errorf("order = %v, want %v", observer.finished, want)
+	}
+}
+
+// compensationServer creates resources on POST /create/<n> and deletes them
+// on DELETE /delete/<n>. Creating item "3" fails.
+type compensationServer struct {
+	mu          sync.Mutex
+	created     map[string]bool
+	deleted     []string
+	failDeletes int
+}
+
+func (s *compensationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := path.Base(r.URL.Path)
+	switch path.Dir(r.URL.Path) {
+	case "/create":
+		if item == "3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.created[item] = true
+	case "/delete":
+		if s.failDeletes > 0 {
+			s.failDeletes--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delete(s.created, item)
+		s.deleted = append(s.deleted, item)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{}`))
+}
+
+func newCompensationServer(t *testing.T) (*compensationServer, *httptest.Server) {
+	t.Helper()
+	handler := &compensationServer{created: make(map[string]bool)}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return handler, server
+}
+
+func createStep(id, baseURL, item string) *Step {
+	return &Step{
+		ID:       id,
+		Type:     StepTypeAPICall,
+		Required: true,
+		APICall:  &APICallStep{Method: "POST", Endpoint: baseURL + "/create/" + item},
+		Rollback: &Step{
+			ID:      "undo-" + id,
+			Type:    StepTypeAPICall,
+			APICall: &APICallStep{Method: "DELETE", Endpoint: baseURL + "/delete/" + item},
+		},
+	}
+}
+
+func newTestExecutor(t *testing.T, wf *Workflow, client *http.Client) (*Executor, *recordingObserver) {
+	t.Helper()
+	executor, err := NewExecutor(wf, client, nil)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	observer := &recordingObserver{}
+	executor.SetStateManager(NewStateManagerWithDir(t.TempDir()))
+	executor.SetRollbackObserver(observer)
+	executor.rollback.sleep = func(time.Duration) {}
+	return executor, observer
+}
+
+func TestExecutor_Rollback_PartialLoop(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	wf := &Workflow{Steps: []*Step{{
+		ID:   "create-all",
+		Type: StepTypeLoop,
+		Loop: &LoopStep{
+			Iterator:   "item",
+			Collection: "[1, 2, 3]",
+			Steps:      []*Step{createStep("create", server.URL, "{item}")},
+		},
+	}}}
+
+	executor, observer := newTestExecutor(t, wf, server.Client())
+	state, err := executor.Execute(NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected loop to fail")
+	}
+
+	if state.Status != ExecutionStatusRolledBack {
+		t.Errorf("Status = %s, want %s", state.Status, ExecutionStatusRolledBack)
+	}
+	if fmt.Sprint(handler.deleted) != "[2 1]" {
+		t.Errorf("deleted = %v, want only the succeeded iterations in reverse", handler.deleted)
+	}
+	want := []string{"create[2]:succeeded", "create[1]:succeeded"}
+	if fmt.Sprint(observer.finished) != fmt.Sprint(want) {
+		t.Errorf("compensations = %v, want %v", observer.finished, want)
+	}
+	if state.Rollback == nil || len(state.Rollback.Compensations) != 2 {
+		t.Fatalf("expected rollback report with 2 compensations, got %+v", state.Rollback)
+	}
+	if record := state.Rollback.Compensations[0]; record.ParentID != "create-all" || record.Iteration != 2 {
+		t.Errorf("record = %+v, want parent create-all iteration 2", record)
+	}
+}
+
+func TestExecutor_Rollback_ParallelBranches(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	wf := &Workflow{Steps: []*Step{{
+		ID:   "fan-out",
+		Type: StepTypeParallel,
+		Parallel: &ParallelStep{Steps: []*Step{
+			createStep("one", server.URL, "1"),
+			createStep("two", server.URL, "2"),
+			createStep("three", server.URL, "3"),
+		}},
+	}}}
+
+	executor, observer := newTestExecutor(t, wf, server.Client())
+	if _, err := executor.Execute(NewExecutionContext(nil)); err == nil {
+		t.Fatal("expected parallel step to fail")
+	}
+
+	if len(handler.created) != 0 {
+		t.Errorf("expected succeeded branches to be compensated, still created: %v", handler.created)
+	}
+	if len(observer.finished) != 2 {
+		t.Errorf("compensations = %v, want one per succeeded branch", observer.finished)
+	}
+}
+
+func TestExecutor_Rollback_RetryAndResume(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	wf := &Workflow{
+		Settings: &Settings{RollbackRetry: &RetryConfig{MaxAttempts: 2}},
+		Steps: []*Step{
+			createStep("one", server.URL, "1"),
+			createStep("two", server.URL, "2"),
+			{ID: "fail", Type: StepTypeAPICall, Required: true, DependsOn: []string{"one", "two"},
+				APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+		},
+	}
+
+	// Undoing "two" exhausts its attempts and is left for a later
+	// `workflow rollback`; undoing "one" succeeds on its second attempt.
+	handler.failDeletes = 3
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	state, err := executor.Execute(NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+	if state.Status != ExecutionStatusFailed {
+		t.Errorf("Status = %s, want %s", state.Status, ExecutionStatusFailed)
+	}
+	if state.ErrorMessage == "" {
+		t.Error("expected ErrorMessage to be set")
+	}
+
+	saved, err := executor.state.LoadState(state.WorkflowID)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	incomplete := saved.Rollback.Incomplete()
+	if len(incomplete) != 1 || incomplete[0].Attempts != 2 {
+		t.Fatalf("incomplete = %+v, want one compensation after 2 attempts", incomplete)
+	}
+
+	// The retry settings are saved with the report, so a later retry
+	// honors them too
+	if saved.Rollback.Retry == nil || saved.Rollback.Retry.MaxAttempts != 2 {
+		t.Fatalf("Retry = %+v, want the rollback-retry settings", saved.Rollback.Retry)
+	}
+	handler.failDeletes = 1
+
+	rm := NewRollbackManager()
+	rm.SetObserver(&recordingObserver{})
+	if err := rm.RetryFailed(saved.Rollback, NewStepExecutor(server.Client(), nil)); err != nil {
+		t.Fatalf("RetryFailed() error = %v", err)
+	}
+	if len(saved.Rollback.Incomplete()) != 0 {
+		t.Error("expected all compensations to succeed after retry")
+	}
+	if incomplete[0].Attempts != 2 {
+		t.Errorf("Attempts = %d, want the retry to take 2 attempts", incomplete[0].Attempts)
+	}
+	if len(handler.created) != 0 {
+		t.Errorf("still created: %v", handler.created)
+	}
+}
+
+func TestExecutor_Rollback_SavedStateMasksSecrets(t *testing.T) {
+	_, server := newCompensationServer(t)
+
+	wf := &Workflow{
+		Steps: []*Step{
+			createStep("one", server.URL, "1"),
+			{ID: "fail", Type: StepTypeAPICall, Required: true, DependsOn: []string{"one"},
+				APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+		},
+	}
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	dir := t.TempDir()
+	executor.SetStateManager(NewStateManagerWithDir(dir))
+
+	ctx := NewExecutionContext(map[string]interface{}{"region": "us-east-1", "password": "hunter2"})
+	ctx.SetVariable("api_token", "s3cr3t")
+	state, err := executor.Execute(ctx)
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+
+	info, err := os.Stat(filepath.Join(dir, state.WorkflowID+".json"))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("state file mode = %o, want 600", mode)
+	}
+
+	saved, err := executor.state.LoadState(state.WorkflowID)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if len(saved.Rollback.Contexts) == 0 {
+		t.Fatal("expected a context snapshot in the rollback report")
+	}
+	for ref, snapshot := range saved.Rollback.Contexts {
+		if snapshot.Flags["password"] == "hunter2" || snapshot.Variables["api_token"] == "s3cr3t" {
+			t.Errorf("snapshot %s holds unmasked secrets: %+v", ref, snapshot)
+		}
+		if snapshot.Flags["region"] != "us-east-1" {
+			t.Errorf("snapshot %s region = %v, want it kept", ref, snapshot.Flags["region"])
+		}
+	}
+}
+
+func TestExecutor_Rollback_SilentByDefault(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	wf := &Workflow{
+		Steps: []*Step{
+			createStep("one", server.URL, "1"),
+			{ID: "fail", Type: StepTypeAPICall, Required: true, DependsOn: []string{"one"},
+				APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+		},
+	}
+
+	// Rollback progress must not mix with output formatted on stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	executor, err := NewExecutor(wf, server.Client(), nil)
+	if err != nil {
+		t.Fatalf("NewExecutor() error = %v", err)
+	}
+	executor.SetStateManager(NewStateManagerWithDir(t.TempDir()))
+	_, execErr := executor.Execute(NewExecutionContext(nil))
+
+	os.Stdout = stdout
+	_ = writer.Close()
+	written, _ := io.ReadAll(reader)
+
+	if execErr == nil {
+		t.Fatal("expected workflow to fail")
+	}
+	if len(handler.created) != 0 {
+		t.Errorf("expected the workflow to roll back, still created: %v", handler.created)
+	}
+	if len(written) != 0 {
+		t.Errorf("expected nothing on stdout, got %q", written)
+	}
+}
+
+func TestRollbackManager_RetryFailed_RefusesMaskedContext(t *testing.T) {
+	handler, server := newCompensationServer(t)
+
+	wf := &Workflow{
+		Steps: []*Step{
+			createStep("one", server.URL, "1"),
+			{ID: "fail", Type: StepTypeAPICall, Required: true, DependsOn: []string{"one"},
+				APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+		},
+	}
+	wf.Steps[0].Rollback.APICall.Headers = map[string]string{"X-Password": "{flags.password}"}
+	handler.failDeletes = 1
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	state, err := executor.Execute(NewExecutionContext(map[string]interface{}{"password": "hunter2"}))
+	if err == nil {
+		t.Fatal("expected workflow to fail")
+	}
+
+	saved, err := executor.state.LoadState(state.WorkflowID)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	incomplete := saved.Rollback.Incomplete()
+	if len(incomplete) != 1 || !incomplete[0].Masked {
+		t.Fatalf("incomplete = %+v, want one compensation marked masked", incomplete)
+	}
+
+	// Retrying would send the masked password, so the compensation is
+	// refused and left as it was
+	err = NewRollbackManager().RetryFailed(saved.Rollback, NewStepExecutor(server.Client(), nil))
+	if err == nil || !strings.Contains(err.Error(), "masked") {
+		t.Fatalf("RetryFailed() error = %v, want a masked secrets error", err)
+	}
+	if incomplete[0].Status != CompensationFailed {
+		t.Errorf("Status = %s, want it left %s", incomplete[0].Status, CompensationFailed)
+	}
+	if _, exists := handler.created["1"]; !exists {
+		t.Error("expected the refused compensation not to run")
+	}
+}
+
+func TestExecutor_CallStep_SavesStateApart(t *testing.T) {
+	handler, server := newCompensationServer(t)
+	handler.failDeletes = 1
+
+	sub := &Workflow{Steps: []*Step{
+		createStep("make", server.URL, "2"),
+		{ID: "boom", Type: StepTypeAPICall, Required: true, DependsOn: []string{"make"},
+			APICall: &APICallStep{Method: "POST", Endpoint: server.URL + "/create/3"}},
+	}}
+	wf := &Workflow{
+		Workflows: map[string]*Workflow{"network": sub},
+		Steps: []*Step{
+			createStep("create", server.URL, "1"),
+			{ID: "network", Type: StepTypeCall, Required: true, DependsOn: []string{"create"},
+				Call: &CallStep{Workflow: "network"}},
+		},
+	}
+
+	executor, _ := newTestExecutor(t, wf, server.Client())
+	state, err := executor.Execute(NewExecutionContext(nil))
+	if err == nil {
+		t.Fatal("expected the workflow to fail")
+	}
+
+	// The failed compensation of the called workflow is kept in its own
+	// state, apart from the caller's
+	childID := state.WorkflowID + ".network-1"
+	child, err := executor.state.LoadState(childID)
+	if err != nil {
+		t.Fatalf("LoadState(%s) error = %v", childID, err)
+	}
+	if child.Rollback == nil || len(child.Rollback.Incomplete()) != 1 || child.Rollback.Incomplete()[0].StepID != "make" {
+		t.Errorf("child rollback = %+v, want the failed compensation of make", child.Rollback)
+	}
+
+	parent, err := executor.state.LoadState(state.WorkflowID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.Rollback == nil || len(parent.Rollback.Compensations) != 1 || parent.Rollback.Compensations[0].StepID != "create" {
+		t.Errorf("parent rollback = %+v, want the compensation of create", parent.Rollback)
+	}
+}
@@ -33,7 +33,7 @@ func NewStateManagerWithDir(dir string) *StateManager {
 
 // ensureStateDir ensures the state directory exists.
 func (sm *StateManager) ensureStateDir() error {
-	if err := os.MkdirAll(sm.stateDir, 0755); err != nil {
+	if err := os.MkdirAll(sm.stateDir, 0700); err != nil {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 	return nil
@@ -51,9 +51,9 @@ func (sm *StateManager) SaveState(state *ExecutionState) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	// Write to file
+	// Write to file, readable only by the user as it holds step outputs
 	filename := filepath.Join(sm.stateDir, fmt.Sprintf("%s.json", state.WorkflowID))
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := os.WriteFile(filename, data, 0600); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
@@ -87,6 +87,10 @@ func (sm *StateManager) DeleteState(workflowID string) error {
 		return fmt.Errorf("failed to delete state file: %w", err)
 	}
 
+	if err := os.Remove(sm.GetEventLogPath(workflowID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete event log: %w", err)
+	}
+
 	return nil
 }
 
@@ -151,7 +155,7 @@ func (sm *StateManager) CleanupOldStates(maxAge int) error {
 		if age > int64(maxAge) {
 			if err := sm.DeleteState(state.WorkflowID); err != nil {
 				// Log error but continue
-				fmt.Printf("Warning: failed to delete old state %s: %v\n", state.WorkflowID, err)
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: failed to delete old state %s: %v\n", state.WorkflowID, err)
 			}
 		}
 	}
@@ -164,6 +168,12 @@ func (sm *StateManager) GetStateFilePath(workflowID string) string {
 	return filepath.Join(sm.stateDir, fmt.Sprintf("%s.json", workflowID))
 }
 
+// GetEventLogPath returns the path to the JSON-lines event log of a
+// workflow execution.
+func (sm *StateManager) GetEventLogPath(workflowID string) string {
+	return filepath.Join(sm.stateDir, fmt.Sprintf("%s.events.jsonl", workflowID))
+}
+
 // StateExists checks if a state file exists.
 func (sm *StateManager) StateExists(workflowID string) bool {
 	filename := sm.GetStateFilePath(workflowID)
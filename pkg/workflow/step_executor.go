@@ -7,16 +7,45 @@ import (
 	"io"
 	"math"
 	"net/http"
-	"sync"
+	"sort"
+	"sync/atomic"
 	"time"
+
+	"github.com/CliForge/cliforge/pkg/cli/interactive"
 )
 
 // StepExecutor coordinates execution of all step types.
 type StepExecutor struct {
 	httpClient     *http.Client
 	pluginExecutor interface{}
+	rateLimiter    *HostRateLimiter
+	maxConcurrency int
+
+	// Prompt step configuration
+	prompter  *interactive.Prompter
+	assumeYes bool
+
+	// Call step configuration
+	workflows map[string]*Workflow
+	state     *StateManager
+	callDepth int
+
+	// workflowID is the ID of the run the steps belong to, and calls
+	// numbers the sub-workflows its call steps run.
+	workflowID string
+	calls      *atomic.Int64
+
+	// rollbackObserver is passed on to called sub-workflows.
+	rollbackObserver RollbackObserver
+
+	// events receives step events; nil discards them.
+	events *eventEmitter
 }
 
+// maxCallDepth limits how deeply call steps may nest, guarding against
+// workflows that call themselves.
+const maxCallDepth = 16
+
 // NewStepExecutor creates a new step executor.
 func NewStepExecutor(httpClient *http.Client, pluginExecutor interface{}) *StepExecutor {
 	if httpClient == nil {
@@ -28,7 +57,53 @@ func NewStepExecutor(httpClient *http.Client, pluginExecutor interface{}) *StepE
 	return &StepExecutor{
 		httpClient:     httpClient,
 		pluginExecutor: pluginExecutor,
+		calls:          new(atomic.Int64),
+	}
+}
+
+// SetRateLimiter sets the per-host rate limiter applied to HTTP requests.
+func (e *StepExecutor) SetRateLimiter(limiter *HostRateLimiter) {
+	e.rateLimiter = limiter
+}
+
+// SetMaxConcurrency sets the default concurrency limit for parallel steps
+// and parallel loops that do not declare their own. Zero means unbounded.
+func (e *StepExecutor) SetMaxConcurrency(limit int) {
+	e.maxConcurrency = limit
+}
+
+// SetPrompter sets the prompter used by prompt steps. Without a prompter,
+// or with interactive prompts disabled, prompt steps fail unless assumeYes
+// is set.
+func (e *StepExecutor) SetPrompter(prompter *interactive.Prompter) {
+	e.prompter = prompter
+}
+
+// SetAssumeYes makes prompt steps answer with their defaults and approve
+// approval prompts without asking, as with a --yes flag.
+func (e *StepExecutor) SetAssumeYes(assumeYes bool) {
+	e.assumeYes = assumeYes
+}
+
+// RegisterWorkflow makes a named workflow available to call steps.
+func (e *StepExecutor) RegisterWorkflow(name string, wf *Workflow) {
+	if e.workflows == nil {
+		e.workflows = make(map[string]*Workflow)
 	}
+	e.workflows[name] = wf
+}
+
+// doRequest sends an HTTP request through the rate limiter.
+func (e *StepExecutor) doRequest(req *http.Request) (*http.Response, error) {
+	e.rateLimiter.Wait(req.URL.Host)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e.rateLimiter.Observe(req.URL.Host, resp)
+	return resp, nil
 }
 
 // ExecuteStep executes a single step with retry logic.
@@ -38,7 +113,9 @@ func (e *StepExecutor) ExecuteStep(step *Step, ctx *ExecutionContext) (*StepResu
 		evaluator := NewExprEvaluator(ctx)
 		shouldExecute, err := evaluator.EvaluateCondition(step.Condition)
 		if err != nil {
-			return nil, fmt.Errorf("failed to evaluate step condition: %w", err)
+			err = fmt.Errorf("failed to evaluate step condition: %w", err)
+			e.events.emit(&Event{Type: EventStepFailed, StepID: step.ID, Error: err.Error()})
+			return nil, err
 		}
 
 		if !shouldExecute {
@@ -51,6 +128,7 @@ func (e *StepExecutor) ExecuteStep(step *Step, ctx *ExecutionContext) (*StepResu
 			}
 			result.EndTime = result.StartTime
 			result.Duration = 0
+			e.events.emit(&Event{Type: EventStepSkipped, StepID: step.ID})
 			return result, nil
 		}
 	}
@@ -64,11 +142,15 @@ func (e *StepExecutor) ExecuteStep(step *Step, ctx *ExecutionContext) (*StepResu
 		maxAttempts = step.Retry.MaxAttempts
 	}
 
+	start := time.Now()
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		if attempt > 0 {
 			// Wait before retry
 			backoffDuration := e.calculateBackoff(step, attempt)
 			time.Sleep(backoffDuration)
+			e.events.emit(&Event{Type: EventStepRetried, StepID: step.ID, Attempt: attempt + 1, Error: stepErrorMessage(result, err)})
+		} else {
+			e.events.emit(&Event{Type: EventStepStarted, StepID: step.ID, Attempt: 1})
 		}
 
 		// Execute the step
@@ -79,6 +161,7 @@ func (e *StepExecutor) ExecuteStep(step *Step, ctx *ExecutionContext) (*StepResu
 
 		// Check if successful
 		if err == nil && result != nil && result.Success {
+			e.events.emit(&Event{Type: EventStepSucceeded, StepID: step.ID, Attempt: attempt + 1, Duration: time.Since(start)})
 			return result, nil
 		}
 
@@ -91,9 +174,38 @@ func (e *StepExecutor) ExecuteStep(step *Step, ctx *ExecutionContext) (*StepResu
 	}
 
 	// All retries exhausted
+	attempts := 1
+	if result != nil {
+		attempts = result.Retries + 1
+	}
+	e.events.emit(&Event{Type: EventStepFailed, StepID: step.ID, Attempt: attempts, Duration: time.Since(start), Error: stepErrorMessage(result, err)})
 	return result, err
 }
 
+// withoutEvents returns a copy of e that does not emit step events. It runs
+// compensations, which are reported as step-rolled-back events instead.
+func (e *StepExecutor) withoutEvents() *StepExecutor {
+	quiet := *e
+	quiet.events = nil
+	return &quiet
+}
+
+// stepErrorMessage describes why a step attempt did not succeed.
+func stepErrorMessage(result *StepResult, err error) string {
+	if result != nil {
+		if message := errorMessage(err, result.Error); message != "" {
+			return message
+		}
+		if statusCode, ok := result.Output["status_code"].(int); ok {
+			return fmt.Sprintf("HTTP %d", statusCode)
+		}
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "step did not succeed"
+}
+
 // executeStepByType executes a step based on its type.
 func (e *StepExecutor) executeStepByType(step *Step, ctx *ExecutionContext) (*StepResult, error) {
 	switch step.Type {
@@ -111,6 +223,14 @@ func (e *StepExecutor) executeStepByType(step *Step, ctx *ExecutionContext) (*St
 		return e.executeParallel(step, ctx)
 	case StepTypeNoop:
 		return e.executeNoop(step)
+	case StepTypePrompt:
+		return e.executePrompt(step, ctx)
+	case StepTypeSet:
+		return e.executeSet(step, ctx)
+	case StepTypeAssert:
+		return e.executeAssert(step, ctx)
+	case StepTypeCall:
+		return e.executeCall(step, ctx)
 	default:
 		return nil, fmt.Errorf("unknown step type: %s", step.Type)
 	}
@@ -268,7 +388,7 @@ func (e *StepExecutor) executeAPICall(step *Step, ctx *ExecutionContext) (*StepR
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := e.httpClient.Do(req)
+	resp, err := e.doRequest(req)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to execute request: %w", err)
 		result.Success = false
@@ -486,6 +606,8 @@ func (e *StepExecutor) executeConditional(step *Step, ctx *ExecutionContext) (*S
 		}
 
 		branchResults = append(branchResults, stepResult)
+		ctx.SetStepResult(branchStep.ID, stepResult)
+		recordNestedRollback(ctx, step.ID, branchStep, stepResult)
 
 		if !stepResult.Success && branchStep.Required {
 			result.Error = fmt.Errorf("required step %s in %s branch failed", branchStep.ID, branchName)
@@ -502,6 +624,23 @@ func (e *StepExecutor) executeConditional(step *Step, ctx *ExecutionContext) (*S
 	return result, nil
 }
 
+// recordNestedRollback records the rollback action of a nested step that
+// runs in its parent's context.
+func recordNestedRollback(ctx *ExecutionContext, parentID string, step *Step, result *StepResult) {
+	if step.Rollback == nil {
+		return
+	}
+
+	before := len(ctx.GetRollbackActions())
+	ctx.recordRollback(step, result)
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	for _, action := range ctx.RollbackActions[before:] {
+		action.ParentID = parentID
+	}
+}
+
 // Loop execution
 
 func (e *StepExecutor) executeLoop(step *Step, ctx *ExecutionContext) (*StepResult, error) {
@@ -546,37 +685,26 @@ func (e *StepExecutor) executeLoop(step *Step, ctx *ExecutionContext) (*StepResu
 
 	result.Output["collection_size"] = len(collection)
 
-	iterationResults := make([]interface{}, 0)
-	for i, item := range collection {
-		iterCtx := ctx.Clone()
-		iterCtx.SetVariable(step.Loop.Iterator, item)
-		iterCtx.SetVariable(fmt.Sprintf("%s_index", step.Loop.Iterator), i)
-
-		for _, loopStep := range step.Loop.Steps {
-			stepResult, err := e.ExecuteStep(loopStep, iterCtx)
-			if err != nil {
-				result.Error = fmt.Errorf("iteration %d failed: %w", i, err)
-				result.Success = false
-				result.Output["iteration_results"] = iterationResults
-				return result, result.Error
-			}
+	var iterations [][]interface{}
+	var iterErr error
+	if step.Loop.Parallel {
+		iterations, iterErr = e.runLoopParallel(step, ctx, collection)
+	} else {
+		iterations, iterErr = e.runLoopSequential(step, ctx, collection)
+	}
 
-			if !stepResult.Success && loopStep.Required {
-				result.Error = fmt.Errorf("required step %s failed in iteration %d", loopStep.ID, i)
-				result.Success = false
-				result.Output["iteration_results"] = iterationResults
-				return result, result.Error
-			}
+	iterationResults := make([]interface{}, 0, len(collection))
+	for _, iteration := range iterations {
+		iterationResults = append(iterationResults, iteration...)
+	}
+	result.Output["iteration_results"] = iterationResults
 
-			iterationResults = append(iterationResults, map[string]interface{}{
-				"index":  i,
-				"item":   item,
-				"result": stepResult,
-			})
-		}
+	if iterErr != nil {
+		result.Error = iterErr
+		result.Success = false
+		return result, result.Error
 	}
 
-	result.Output["iteration_results"] = iterationResults
 	result.Success = true
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -584,6 +712,98 @@ func (e *StepExecutor) executeLoop(step *Step, ctx *ExecutionContext) (*StepResu
 	return result, nil
 }
 
+// runLoopSequential runs loop iterations one after another, stopping at the
+// first failure. It returns the results of each completed iteration.
+func (e *StepExecutor) runLoopSequential(step *Step, ctx *ExecutionContext, collection []interface{}) ([][]interface{}, error) {
+	iterations := make([][]interface{}, 0, len(collection))
+
+	for i, item := range collection {
+		iterCtx := ctx.Fork()
+		results, err := e.runLoopIteration(step, iterCtx, i, item)
+		ctx.AdoptRollbackActions(iterCtx, step.ID, i+1)
+		iterations = append(iterations, results)
+		if err != nil {
+			return iterations, err
+		}
+	}
+
+	return iterations, nil
+}
+
+// runLoopParallel runs loop iterations concurrently, bounded by the loop's
+// max-concurrency. Results are returned in collection order regardless of
+// completion order. After a failure no new iterations are started, and the
+// error of the lowest failing index is returned.
+func (e *StepExecutor) runLoopParallel(step *Step, ctx *ExecutionContext, collection []interface{}) ([][]interface{}, error) {
+	iterations := make([][]interface{}, len(collection))
+	errs := make([]error, len(collection))
+
+	var failed atomic.Bool
+	limit := effectiveConcurrency(step.Loop.MaxConcurrency, e.maxConcurrency)
+
+	forks := make([]*ExecutionContext, len(collection))
+
+	runBounded(len(collection), limit, failed.Load, func(i int) {
+		iterCtx := ctx.Fork()
+		forks[i] = iterCtx
+		results, err := e.runLoopIteration(step, iterCtx, i, collection[i])
+		iterations[i] = results
+		if err != nil {
+			errs[i] = err
+			failed.Store(true)
+		}
+	})
+
+	// Every iteration that ran may have side effects to undo, including
+	// those that finished after another iteration failed.
+	for i, iterCtx := range forks {
+		if iterCtx != nil {
+			ctx.AdoptRollbackActions(iterCtx, step.ID, i+1)
+		}
+	}
+
+	completed := make([][]interface{}, 0, len(collection))
+	for i, results := range iterations {
+		if errs[i] != nil {
+			return completed, errs[i]
+		}
+		if results != nil {
+			completed = append(completed, results)
+		}
+	}
+
+	return completed, nil
+}
+
+// runLoopIteration executes the loop body for a single item.
+func (e *StepExecutor) runLoopIteration(step *Step, iterCtx *ExecutionContext, index int, item interface{}) ([]interface{}, error) {
+	iterCtx.SetVariable(step.Loop.Iterator, item)
+	iterCtx.SetVariable(fmt.Sprintf("%s_index", step.Loop.Iterator), index)
+
+	results := make([]interface{}, 0, len(step.Loop.Steps))
+	for _, loopStep := range step.Loop.Steps {
+		stepResult, err := e.ExecuteStep(loopStep, iterCtx)
+		if err != nil {
+			return results, fmt.Errorf("iteration %d failed: %w", index, err)
+		}
+
+		iterCtx.SetStepResult(loopStep.ID, stepResult)
+		iterCtx.recordRollback(loopStep, stepResult)
+
+		if !stepResult.Success && loopStep.Required {
+			return results, fmt.Errorf("required step %s failed in iteration %d", loopStep.ID, index)
+		}
+
+		results = append(results, map[string]interface{}{
+			"index":  index,
+			"item":   item,
+			"result": stepResult,
+		})
+	}
+
+	return results, nil
+}
+
 // Wait execution
 
 func (e *StepExecutor) executeWait(step *Step, ctx *ExecutionContext) (*StepResult, error) {
@@ -649,7 +869,14 @@ func (e *StepExecutor) executePolling(step *Step, ctx *ExecutionContext, result
 			return result, result.Error
 		}
 
-		resp, err := e.httpClient.Get(endpoint)
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to create polling request: %w", err)
+			result.Success = false
+			return result, result.Error
+		}
+
+		resp, err := e.doRequest(req)
 		if err != nil {
 			time.Sleep(time.Duration(interval) * time.Second)
 			continue
@@ -747,43 +974,63 @@ func (e *StepExecutor) executeParallel(step *Step, ctx *ExecutionContext) (*Step
 		return result, nil
 	}
 
-	var wg sync.WaitGroup
-	resultsChan := make(chan *stepExecutionResult, len(step.Parallel.Steps))
+	execResults := make([]*stepExecutionResult, len(step.Parallel.Steps))
+	forks := make([]*ExecutionContext, len(step.Parallel.Steps))
+	limit := effectiveConcurrency(step.Parallel.MaxConcurrency, e.maxConcurrency)
 
-	for _, parallelStep := range step.Parallel.Steps {
-		wg.Add(1)
-		go func(s *Step) {
-			defer wg.Done()
+	// After a branch fails no new branches are started, as in parallel
+	// loops; branches already running are waited for.
+	var failed atomic.Bool
+	runBounded(len(step.Parallel.Steps), limit, failed.Load, func(i int) {
+		s := step.Parallel.Steps[i]
+		parallelCtx := ctx.Fork()
+		forks[i] = parallelCtx
 
-			parallelCtx := ctx.Clone()
+		stepResult, err := e.ExecuteStep(s, parallelCtx)
+		if err == nil {
+			parallelCtx.SetStepResult(s.ID, stepResult)
+			parallelCtx.recordRollback(s, stepResult)
+		}
+		if err != nil || stepResult == nil || !stepResult.Success {
+			failed.Store(true)
+		}
+		execResults[i] = &stepExecutionResult{
+			step:   s,
+			result: stepResult,
+			err:    err,
+		}
+	})
 
-			stepResult, err := e.ExecuteStep(s, parallelCtx)
-			resultsChan <- &stepExecutionResult{
-				step:   s,
-				result: stepResult,
-				err:    err,
-			}
-		}(parallelStep)
+	// Each branch that succeeded is compensated on its own if the workflow
+	// rolls back, even when a sibling branch failed.
+	for _, parallelCtx := range forks {
+		if parallelCtx != nil {
+			ctx.AdoptRollbackActions(parallelCtx, step.ID, 0)
+		}
 	}
 
-	wg.Wait()
-	close(resultsChan)
-
 	parallelResults := make(map[string]*StepResult)
 	var errors []error
 	allSuccess := true
 
-	for execResult := range resultsChan {
+	for _, execResult := range execResults {
+		if execResult == nil {
+			// Not started after another branch failed
+			allSuccess = false
+			continue
+		}
 		parallelResults[execResult.step.ID] = execResult.result
 
-		if execResult.err != nil || !execResult.result.Success {
+		if execResult.err != nil || execResult.result == nil || !execResult.result.Success {
 			allSuccess = false
 			if execResult.err != nil {
 				errors = append(errors, execResult.err)
 			}
 		}
 
-		ctx.SetStepResult(execResult.step.ID, execResult.result)
+		if execResult.result != nil {
+			ctx.SetStepResult(execResult.step.ID, execResult.result)
+		}
 	}
 
 	result.Output["parallel_results"] = parallelResults
@@ -805,3 +1052,327 @@ func (e *StepExecutor) executeParallel(step *Step, ctx *ExecutionContext) (*Step
 
 	return result, nil
 }
+
+// Prompt execution
+
+func (e *StepExecutor) executePrompt(step *Step, ctx *ExecutionContext) (*StepResult, error) {
+	if step.Prompt == nil {
+		return nil, fmt.Errorf("prompt step %s missing configuration", step.ID)
+	}
+
+	result := &StepResult{
+		StepID:    step.ID,
+		StartTime: time.Now(),
+		Output:    make(map[string]interface{}),
+	}
+
+	evaluator := NewExprEvaluator(ctx)
+
+	message, err := evaluator.InterpolateString(step.Prompt.Message)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to interpolate message: %w", err)
+		result.Success = false
+		return result, result.Error
+	}
+
+	defaultValue := step.Prompt.Default
+	if str, ok := defaultValue.(string); ok {
+		if defaultValue, err = evaluator.InterpolateString(str); err != nil {
+			result.Error = fmt.Errorf("failed to interpolate default: %w", err)
+			result.Success = false
+			return result, result.Error
+		}
+	}
+
+	value, err := e.answerPrompt(step, message, defaultValue)
+	if err != nil {
+		result.Error = err
+		result.Success = false
+		return result, result.Error
+	}
+
+	if step.Prompt.Type == "approval" {
+		approved, _ := value.(bool)
+		result.Output["approved"] = approved
+		if !approved {
+			result.Error = fmt.Errorf("step %s was not approved", step.ID)
+			result.Success = false
+			return result, result.Error
+		}
+	}
+
+	result.Output["value"] = value
+	if step.Prompt.Variable != "" {
+		ctx.SetVariable(step.Prompt.Variable, value)
+	}
+
+	result.Success = true
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	return result, nil
+}
+
+// answerPrompt returns the answer to a prompt, asking the user only when
+// running interactively without assumeYes.
+func (e *StepExecutor) answerPrompt(step *Step, message string, defaultValue interface{}) (interface{}, error) {
+	prompt := step.Prompt
+
+	if e.assumeYes {
+		if prompt.Type == "approval" {
+			return true, nil
+		}
+		if defaultValue == nil && prompt.Required {
+			return nil, fmt.Errorf("prompt step %s has no default to use with --yes", step.ID)
+		}
+		return defaultValue, nil
+	}
+
+	if e.prompter == nil || e.prompter.DisableInteractive {
+		return nil, fmt.Errorf("prompt step %s requires interactive input; rerun in a terminal or with --yes", step.ID)
+	}
+
+	if prompt.Type == "approval" {
+		return e.prompter.Confirm(&interactive.ConfirmPromptOptions{Message: message})
+	}
+
+	return e.prompter.PromptFromSpec(&interactive.PromptSpec{
+		Parameter:  prompt.Variable,
+		Type:       prompt.Type,
+		Message:    message,
+		Default:    defaultValue,
+		Validation: prompt.Validation,
+		Options:    prompt.Options,
+		Required:   prompt.Required,
+	})
+}
+
+// Set execution
+
+func (e *StepExecutor) executeSet(step *Step, ctx *ExecutionContext) (*StepResult, error) {
+	if step.Set == nil {
+		return nil, fmt.Errorf("set step %s missing configuration", step.ID)
+	}
+
+	result := &StepResult{
+		StepID:    step.ID,
+		StartTime: time.Now(),
+		Output:    make(map[string]interface{}),
+	}
+
+	// Assign in name order so the result does not depend on map iteration.
+	names := make([]string, 0, len(step.Set.Variables))
+	for name := range step.Set.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		evaluator := NewExprEvaluator(ctx)
+		value, err := evaluator.EvaluateExpression(step.Set.Variables[name])
+		if err != nil {
+			result.Error = fmt.Errorf("failed to evaluate variable %s: %w", name, err)
+			result.Success = false
+			return result, result.Error
+		}
+
+		ctx.SetVariable(name, value)
+		result.Output[name] = value
+	}
+
+	result.Success = true
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	return result, nil
+}
+
+// Assert execution
+
+func (e *StepExecutor) executeAssert(step *Step, ctx *ExecutionContext) (*StepResult, error) {
+	if step.Assert == nil {
+		return nil, fmt.Errorf("assert step %s missing configuration", step.ID)
+	}
+
+	result := &StepResult{
+		StepID:    step.ID,
+		StartTime: time.Now(),
+		Output:    make(map[string]interface{}),
+	}
+
+	evaluator := NewExprEvaluator(ctx)
+
+	passed, err := evaluator.EvaluateCondition(step.Assert.Condition)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to evaluate assertion: %w", err)
+		result.Success = false
+		return result, result.Error
+	}
+
+	result.Output["passed"] = passed
+
+	if !passed {
+		message := step.Assert.Message
+		if message == "" {
+			message = step.Assert.Condition
+		} else if interpolated, err := evaluator.InterpolateString(message); err == nil {
+			message = interpolated
+		}
+
+		result.Error = fmt.Errorf("assertion failed: %s", message)
+		result.Success = false
+		return result, result.Error
+	}
+
+	result.Success = true
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	return result, nil
+}
+
+// Call execution
+
+func (e *StepExecutor) executeCall(step *Step, ctx *ExecutionContext) (*StepResult, error) {
+	if step.Call == nil {
+		return nil, fmt.Errorf("call step %s missing configuration", step.ID)
+	}
+
+	result := &StepResult{
+		StepID:    step.ID,
+		StartTime: time.Now(),
+		Output:    make(map[string]interface{}),
+	}
+
+	if e.callDepth >= maxCallDepth {
+		result.Error = fmt.Errorf("call step %s exceeds maximum call depth of %d", step.ID, maxCallDepth)
+		result.Success = false
+		return result, result.Error
+	}
+
+	sub, exists := e.workflows[step.Call.Workflow]
+	if !exists {
+		result.Error = fmt.Errorf("call step %s references unknown workflow %s", step.ID, step.Call.Workflow)
+		result.Success = false
+		return result, result.Error
+	}
+
+	evaluator := NewExprEvaluator(ctx)
+
+	inputs := make(map[string]interface{})
+	if step.Call.Inputs != nil {
+		interpolated, err := evaluator.InterpolateMap(step.Call.Inputs)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to interpolate inputs: %w", err)
+			result.Success = false
+			return result, result.Error
+		}
+		inputs = interpolated
+	}
+
+	child, err := e.newCallExecutor(sub, step.ID)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to prepare workflow %s: %w", step.Call.Workflow, err)
+		result.Success = false
+		return result, result.Error
+	}
+
+	childCtx := NewExecutionContext(inputs)
+	childCtx.HTTPClient = ctx.HTTPClient
+	childCtx.PluginExecutor = ctx.PluginExecutor
+
+	state, err := child.Execute(childCtx)
+	result.Output["workflow"] = step.Call.Workflow
+	if state != nil {
+		result.Output["status"] = string(state.Status)
+	}
+	if err != nil {
+		// The sub-workflow has already rolled back its own steps.
+		result.Error = fmt.Errorf("workflow %s failed: %w", step.Call.Workflow, err)
+		result.Success = false
+		return result, result.Error
+	}
+
+	outputs, err := child.EvaluateOutputs(childCtx)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to evaluate outputs of workflow %s: %w", step.Call.Workflow, err)
+		result.Success = false
+		return result, result.Error
+	}
+
+	result.Output["outputs"] = outputs
+	for key, value := range outputs {
+		result.Output[key] = value
+	}
+
+	// Compensations of the sub-workflow run in its own context if the
+	// calling workflow later rolls back.
+	ctx.AdoptRollbackActions(childCtx, step.ID, 0)
+
+	result.Success = true
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	return result, nil
+}
+
+// newCallExecutor creates an executor for a called sub-workflow that
+// inherits this executor's configuration. The sub-workflow's state is saved
+// under its own ID, made of the calling run's ID, the call step's ID and
+// the number of the call.
+func (e *StepExecutor) newCallExecutor(sub *Workflow, parentID string) (*Executor, error) {
+	parser := NewParser(sub)
+	dag, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	stepExecutor := &StepExecutor{
+		httpClient:     e.httpClient,
+		pluginExecutor: e.pluginExecutor,
+		rateLimiter:    e.rateLimiter,
+		maxConcurrency: e.maxConcurrency,
+		prompter:       e.prompter,
+		assumeYes:      e.assumeYes,
+		workflows:      make(map[string]*Workflow),
+		state:          e.state,
+		callDepth:      e.callDepth + 1,
+		calls:          new(atomic.Int64),
+		events:         e.events.nested(parentID),
+	}
+	for name, wf := range e.workflows {
+		stepExecutor.workflows[name] = wf
+	}
+	for name, wf := range sub.Workflows {
+		stepExecutor.workflows[name] = wf
+	}
+	if sub.Settings != nil && sub.Settings.MaxConcurrency > 0 {
+		stepExecutor.maxConcurrency = sub.Settings.MaxConcurrency
+	}
+
+	state := e.state
+	if state == nil {
+		state = NewStateManager()
+	}
+	callerID := e.workflowID
+	if callerID == "" {
+		callerID = fmt.Sprintf("workflow-%d", time.Now().Unix())
+	}
+
+	child := &Executor{
+		workflow:     sub,
+		dag:          dag,
+		stepExecutor: stepExecutor,
+		rollback:     newRollbackManager(sub, dag),
+		state:        state,
+		events:       stepExecutor.events,
+		workflowID:   fmt.Sprintf("%s.%s-%d", callerID, parentID, e.calls.Add(1)),
+	}
+	if child.events == nil {
+		child.events = newEventEmitter()
+		stepExecutor.events = child.events
+	}
+	child.SetRollbackObserver(e.rollbackObserver)
+
+	return child, nil
+}
@@ -2,8 +2,10 @@ package workflow
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1088,3 +1090,204 @@ func TestStepExecutor_ExecuteAPICall_NonJSONResponse(t *testing.T) {
 		t.Errorf("expected plain text response, got %v", result.Output["response"])
 	}
 }
+
+func TestStepExecutor_ExecuteParallel_MaxConcurrency(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		active.Add(-1)
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	executor := NewStepExecutor(server.Client(), nil)
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	steps := make([]*Step, 6)
+	for i := range steps {
+		steps[i] = &Step{
+			ID:      fmt.Sprintf("p%d", i),
+			Type:    StepTypeAPICall,
+			APICall: &APICallStep{Endpoint: server.URL, Method: "GET"},
+		}
+	}
+
+	step := &Step{
+		ID:       "parallel-step",
+		Type:     StepTypeParallel,
+		Parallel: &ParallelStep{Steps: steps, MaxConcurrency: 2},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !result.Success {
+		t.Error("expected parallel step to succeed")
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", peak.Load())
+	}
+}
+
+func TestStepExecutor_ExecuteLoop_ParallelStableOrder(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later items finish first to exercise result ordering.
+		if r.URL.Query().Get("item") == "a" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		active.Add(-1)
+		_, _ = w.Write([]byte(`{"item": "` + r.URL.Query().Get("item") + `"}`))
+	}))
+	defer server.Close()
+
+	executor := NewStepExecutor(server.Client(), nil)
+	ctx := NewExecutionContext(map[string]interface{}{
+		"items": []interface{}{"a", "b", "c", "d", "e"},
+	})
+
+	step := &Step{
+		ID:   "loop-step",
+		Type: StepTypeLoop,
+		Loop: &LoopStep{
+			Iterator:       "item",
+			Collection:     "flags.items",
+			Parallel:       true,
+			MaxConcurrency: 3,
+			Steps: []*Step{
+				{
+					ID:   "fetch",
+					Type: StepTypeAPICall,
+					APICall: &APICallStep{
+						Endpoint: server.URL,
+						Method:   "GET",
+						Query:    map[string]string{"item": "{item}"},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	iterations, ok := result.Output["iteration_results"].([]interface{})
+	if !ok || len(iterations) != 5 {
+		t.Fatalf("expected 5 iteration results, got %v", result.Output["iteration_results"])
+	}
+	for i, iteration := range iterations {
+		entry := iteration.(map[string]interface{})
+		if entry["index"] != i {
+			t.Errorf("expected iteration %d at position %d, got %v", i, i, entry["index"])
+		}
+	}
+	if peak.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent iterations, got %d", peak.Load())
+	}
+}
+
+func TestStepExecutor_ExecuteLoop_ParallelFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("item") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	executor := NewStepExecutor(server.Client(), nil)
+	ctx := NewExecutionContext(map[string]interface{}{
+		"items": []interface{}{"ok", "bad", "ok"},
+	})
+
+	step := &Step{
+		ID:   "loop-step",
+		Type: StepTypeLoop,
+		Loop: &LoopStep{
+			Iterator:   "item",
+			Collection: "flags.items",
+			Parallel:   true,
+			Steps: []*Step{
+				{
+					ID:   "fetch",
+					Type: StepTypeAPICall,
+					APICall: &APICallStep{
+						Endpoint: server.URL,
+						Query:    map[string]string{"item": "{item}"},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err == nil {
+		t.Fatal("expected loop to fail")
+	}
+	if !strings.Contains(err.Error(), "iteration 1 failed") {
+		t.Errorf("expected iteration 1 failure, got: %v", err)
+	}
+	if result.Success {
+		t.Error("expected loop result to be unsuccessful")
+	}
+}
+
+func TestStepExecutor_ExecuteAPICall_RateLimited(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	limiter := NewHostRateLimiter(100, 1)
+	executor := NewStepExecutor(server.Client(), nil)
+	executor.SetRateLimiter(limiter)
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	status := http.StatusTooManyRequests
+	step := &Step{
+		ID:      "call",
+		Type:    StepTypeAPICall,
+		APICall: &APICallStep{Endpoint: server.URL},
+		Retry: &RetryConfig{
+			MaxAttempts:     2,
+			Backoff:         &BackoffConfig{Type: BackoffFixed},
+			RetryableErrors: []*ErrorMatch{{HTTPStatus: &status}},
+		},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got: %v", err)
+	}
+	if result.Retries != 1 {
+		t.Errorf("expected 1 retry, got %d", result.Retries)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if rate := limiter.CurrentRate(host); rate >= 100 {
+		t.Errorf("expected adaptive slow-down after 429, rate is %v", rate)
+	}
+}
@@ -0,0 +1,357 @@
+package workflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/CliForge/cliforge/pkg/cli/interactive"
+)
+
+func TestStepExecutor_ExecuteSet(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	ctx := NewExecutionContext(map[string]interface{}{"replicas": 3})
+
+	step := &Step{
+		ID:   "compute",
+		Type: StepTypeSet,
+		Set: &SetStep{
+			Variables: map[string]string{
+				"a_total": "flags.replicas * 2",
+				"b_label": `"replicas-" + string(a_total)`,
+			},
+		},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if value, _ := ctx.GetVariable("a_total"); value != 6 {
+		t.Errorf("expected a_total 6, got %v", value)
+	}
+	if value, _ := ctx.GetVariable("b_label"); value != "replicas-6" {
+		t.Errorf("expected b_label replicas-6, got %v", value)
+	}
+	if result.Output["a_total"] != 6 {
+		t.Errorf("expected a_total in output, got %v", result.Output)
+	}
+}
+
+func TestStepExecutor_ExecuteSet_InvalidExpression(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	step := &Step{
+		ID:   "compute",
+		Type: StepTypeSet,
+		Set:  &SetStep{Variables: map[string]string{"x": "1 +"}},
+	}
+
+	if _, err := executor.ExecuteStep(step, ctx); err == nil {
+		t.Error("expected error for invalid expression")
+	}
+}
+
+func TestStepExecutor_ExecuteAssert(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	ctx := NewExecutionContext(map[string]interface{}{"replicas": 2, "name": "prod"})
+
+	pass := &Step{
+		ID:     "check",
+		Type:   StepTypeAssert,
+		Assert: &AssertStep{Condition: "flags.replicas >= 2"},
+	}
+	result, err := executor.ExecuteStep(pass, ctx)
+	if err != nil || !result.Success {
+		t.Fatalf("expected assertion to pass, got: %v", err)
+	}
+
+	fail := &Step{
+		ID:   "check-more",
+		Type: StepTypeAssert,
+		Assert: &AssertStep{
+			Condition: "flags.replicas >= 3",
+			Message:   "cluster {flags.name} needs at least 3 replicas",
+		},
+	}
+	result, err = executor.ExecuteStep(fail, ctx)
+	if err == nil {
+		t.Fatal("expected assertion to fail")
+	}
+	if err.Error() != "assertion failed: cluster prod needs at least 3 replicas" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	if result.Output["passed"] != false {
+		t.Errorf("expected passed=false, got %v", result.Output["passed"])
+	}
+}
+
+func TestStepExecutor_ExecutePrompt_AssumeYes(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	executor.SetAssumeYes(true)
+	ctx := NewExecutionContext(map[string]interface{}{"region": "us-east-1"})
+
+	input := &Step{
+		ID:   "ask-region",
+		Type: StepTypePrompt,
+		Prompt: &PromptStep{
+			Type:     "text",
+			Message:  "Region?",
+			Variable: "region",
+			Default:  "{flags.region}",
+		},
+	}
+	if _, err := executor.ExecuteStep(input, ctx); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if value, _ := ctx.GetVariable("region"); value != "us-east-1" {
+		t.Errorf("expected default answer, got %v", value)
+	}
+
+	approval := &Step{
+		ID:     "approve",
+		Type:   StepTypePrompt,
+		Prompt: &PromptStep{Type: "approval", Message: "Proceed?"},
+	}
+	result, err := executor.ExecuteStep(approval, ctx)
+	if err != nil {
+		t.Fatalf("expected approval to be auto-approved, got: %v", err)
+	}
+	if result.Output["approved"] != true {
+		t.Errorf("expected approved=true, got %v", result.Output["approved"])
+	}
+
+	required := &Step{
+		ID:     "ask-name",
+		Type:   StepTypePrompt,
+		Prompt: &PromptStep{Type: "text", Message: "Name?", Required: true},
+	}
+	if _, err := executor.ExecuteStep(required, ctx); err == nil {
+		t.Error("expected error for required prompt without default under --yes")
+	}
+}
+
+func TestStepExecutor_ExecutePrompt_NonInteractive(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	step := &Step{
+		ID:     "approve",
+		Type:   StepTypePrompt,
+		Prompt: &PromptStep{Type: "approval", Message: "Proceed?"},
+	}
+
+	_, err := executor.ExecuteStep(step, ctx)
+	if err == nil || !strings.Contains(err.Error(), "requires interactive input") {
+		t.Errorf("expected non-interactive error without prompter, got: %v", err)
+	}
+
+	executor.SetPrompter(interactive.NewPrompter(&interactive.PrompterConfig{DisableInteractive: true}))
+	_, err = executor.ExecuteStep(step, ctx)
+	if err == nil || !strings.Contains(err.Error(), "requires interactive input") {
+		t.Errorf("expected non-interactive error with disabled prompter, got: %v", err)
+	}
+}
+
+func TestStepExecutor_ExecuteCall(t *testing.T) {
+	var deleted atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "net-` + r.URL.Query().Get("name") + `"}`))
+	}))
+	defer server.Close()
+
+	sub := &Workflow{
+		Steps: []*Step{
+			{
+				ID:   "create-network",
+				Type: StepTypeAPICall,
+				APICall: &APICallStep{
+					Endpoint: server.URL + "/networks",
+					Method:   "POST",
+					Query:    map[string]string{"name": "{flags.name}"},
+				},
+				Rollback: &Step{
+					ID:      "delete-network",
+					Type:    StepTypeAPICall,
+					APICall: &APICallStep{Endpoint: server.URL + "/networks/{steps['create-network'].response.id}", Method: "DELETE"},
+				},
+			},
+		},
+		Outputs: map[string]string{
+			"network_id": "steps['create-network'].response.id",
+		},
+	}
+
+	executor := NewStepExecutor(server.Client(), nil)
+	executor.RegisterWorkflow("create-network", sub)
+	executor.state = NewStateManagerWithDir(t.TempDir())
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	step := &Step{
+		ID:   "network",
+		Type: StepTypeCall,
+		Call: &CallStep{
+			Workflow: "create-network",
+			Inputs:   map[string]interface{}{"name": "blue"},
+		},
+	}
+
+	result, err := executor.ExecuteStep(step, ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if result.Output["network_id"] != "net-blue" {
+		t.Errorf("expected network_id net-blue, got %v", result.Output["network_id"])
+	}
+
+	actions := ctx.GetRollbackActions()
+	if len(actions) != 1 || actions[0].Context == nil {
+		t.Fatalf("expected sub-workflow rollback action with its own context, got %v", actions)
+	}
+
+	if err := NewRollbackManager().ExecuteRollback(ctx, executor); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if deleted.Load() != 1 {
+		t.Errorf("expected sub-workflow compensation to run once, ran %d times", deleted.Load())
+	}
+}
+
+func TestStepExecutor_ExecuteCall_UnknownWorkflow(t *testing.T) {
+	executor := NewStepExecutor(nil, nil)
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	step := &Step{
+		ID:   "call",
+		Type: StepTypeCall,
+		Call: &CallStep{Workflow: "missing"},
+	}
+
+	_, err := executor.ExecuteStep(step, ctx)
+	if err == nil || !strings.Contains(err.Error(), "unknown workflow missing") {
+		t.Errorf("expected unknown workflow error, got: %v", err)
+	}
+}
+
+func TestStepExecutor_ExecuteCall_Recursion(t *testing.T) {
+	recursive := &Workflow{
+		Steps: []*Step{
+			{ID: "again", Type: StepTypeCall, Call: &CallStep{Workflow: "loop"}},
+		},
+	}
+
+	executor := NewStepExecutor(nil, nil)
+	executor.RegisterWorkflow("loop", recursive)
+	executor.state = NewStateManagerWithDir(t.TempDir())
+	ctx := NewExecutionContext(map[string]interface{}{})
+
+	step := &Step{ID: "start", Type: StepTypeCall, Call: &CallStep{Workflow: "loop"}}
+
+	_, err := executor.ExecuteStep(step, ctx)
+	if err == nil || !strings.Contains(err.Error(), "maximum call depth") {
+		t.Errorf("expected call depth error, got: %v", err)
+	}
+}
+
+func TestExecutor_Execute_InlineSubWorkflow(t *testing.T) {
+	workflow := &Workflow{
+		Workflows: map[string]*Workflow{
+			"double": {
+				Steps: []*Step{
+					{ID: "calc", Type: StepTypeSet, Set: &SetStep{Variables: map[string]string{"result": "flags.value * 2"}}},
+				},
+				Outputs: map[string]string{"doubled": "result"},
+			},
+		},
+		Steps: []*Step{
+			{ID: "call-double", Type: StepTypeCall, Call: &CallStep{Workflow: "double", Inputs: map[string]interface{}{"value": 21}}},
+			{ID: "check", Type: StepTypeAssert, DependsOn: []string{"call-double"}, Assert: &AssertStep{Condition: "steps['call-double'].doubled == 42"}},
+		},
+	}
+
+	executor, err := NewExecutor(workflow, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	executor.state = NewStateManagerWithDir(t.TempDir())
+	executor.stepExecutor.state = executor.state
+
+	state, err := executor.Execute(NewExecutionContext(map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	if state.Status != ExecutionStatusCompleted {
+		t.Errorf("expected status %s, got %s", ExecutionStatusCompleted, state.Status)
+	}
+}
+
+func TestParser_RejectsRollbackOnSideEffectFreeSteps(t *testing.T) {
+	workflow := &Workflow{
+		Steps: []*Step{
+			{
+				ID:       "compute",
+				Type:     StepTypeSet,
+				Set:      &SetStep{Variables: map[string]string{"x": "1"}},
+				Rollback: &Step{ID: "undo", Type: StepTypeNoop},
+			},
+		},
+	}
+
+	_, err := NewParser(workflow).Parse()
+	if err == nil || !strings.Contains(err.Error(), "cannot define a rollback") {
+		t.Errorf("expected rollback error, got: %v", err)
+	}
+}
+
+func TestParser_ValidatesSubWorkflows(t *testing.T) {
+	workflow := &Workflow{
+		Workflows: map[string]*Workflow{
+			"broken": {Steps: []*Step{{ID: "a", Type: StepTypeNoop, DependsOn: []string{"missing"}}}},
+		},
+		Steps: []*Step{{ID: "call", Type: StepTypeCall, Call: &CallStep{Workflow: "broken"}}},
+	}
+
+	_, err := NewParser(workflow).Parse()
+	if err == nil || !strings.Contains(err.Error(), "workflow broken") {
+		t.Errorf("expected sub-workflow error, got: %v", err)
+	}
+}
+
+func TestParser_CollectReferences_NewStepTypes(t *testing.T) {
+	parser := NewParser(&Workflow{})
+
+	tests := []struct {
+		name string
+		step *Step
+		want string
+	}{
+		{"prompt", &Step{Type: StepTypePrompt, Prompt: &PromptStep{Message: "Delete {create.id}?"}}, "Delete {create.id}?"},
+		{"set", &Step{Type: StepTypeSet, Set: &SetStep{Variables: map[string]string{"x": "{create.id}"}}}, "{create.id}"},
+		{"assert", &Step{Type: StepTypeAssert, Assert: &AssertStep{Condition: "true", Message: "{create.id}"}}, "{create.id}"},
+		{"call", &Step{Type: StepTypeCall, Call: &CallStep{Inputs: map[string]interface{}{"id": "{create.id}"}}}, "{create.id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := parser.collectReferences(tt.step)
+			found := false
+			for _, ref := range refs {
+				if ref == tt.want {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected reference %q in %v", tt.want, refs)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Timeline is a per-step view of a workflow execution, built from its
+// event log.
+type Timeline struct {
+	WorkflowID string    `json:"workflow-id"`
+	Status     string    `json:"status"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+
+	// Steps are the top-level steps in the order they were scheduled.
+	Steps []*TimelineEntry `json:"steps"`
+
+	// Rollbacks are the compensations in the order they finished.
+	Rollbacks []*TimelineEntry `json:"rollbacks,omitempty"`
+}
+
+// TimelineEntry is one row of a timeline.
+type TimelineEntry struct {
+	StepID  string    `json:"step-id"`
+	Level   int       `json:"level"`
+	Status  string    `json:"status"`
+	Start   time.Time `json:"start,omitempty"`
+	End     time.Time `json:"end,omitempty"`
+	Retries int       `json:"retries,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Duration returns how long the entry ran, or 0 if it never finished.
+func (t *TimelineEntry) Duration() time.Duration {
+	if t.Start.IsZero() || t.End.IsZero() {
+		return 0
+	}
+	return t.End.Sub(t.Start)
+}
+
+// Statuses of timeline entries for steps. Compensations use
+// CompensationStatus values.
+const (
+	timelinePending   = "pending"
+	timelineRunning   = "running"
+	timelineSucceeded = "succeeded"
+	timelineFailed    = "failed"
+	timelineSkipped   = "skipped"
+)
+
+// BuildTimeline builds a timeline from events. Events of called
+// sub-workflows are folded into the call step that ran them.
+func BuildTimeline(events []*Event) *Timeline {
+	timeline := &Timeline{Status: timelineRunning}
+	entries := make(map[string]*TimelineEntry)
+
+	for _, event := range events {
+		if timeline.Start.IsZero() || event.Time.Before(timeline.Start) {
+			timeline.Start = event.Time
+		}
+		if event.Time.After(timeline.End) {
+			timeline.End = event.Time
+		}
+		if timeline.WorkflowID == "" {
+			timeline.WorkflowID = event.WorkflowID
+		}
+		if event.ParentID != "" {
+			continue
+		}
+
+		switch event.Type {
+		case EventWorkflowCompleted:
+			timeline.Status = string(ExecutionStatusCompleted)
+		case EventWorkflowFailed:
+			timeline.Status = string(ExecutionStatusFailed)
+		case EventStepScheduled:
+			entry := &TimelineEntry{StepID: event.StepID, Level: event.Level, Status: timelinePending}
+			entries[event.StepID] = entry
+			timeline.Steps = append(timeline.Steps, entry)
+		case EventStepRolledBack:
+			timeline.Rollbacks = append(timeline.Rollbacks, &TimelineEntry{
+				StepID:  event.StepID,
+				Status:  event.Status,
+				Start:   event.Time.Add(-event.Duration),
+				End:     event.Time,
+				Retries: max(event.Attempt-1, 0),
+				Error:   event.Error,
+			})
+		}
+
+		// Only top-level steps have rows; nested steps are part of them.
+		entry, exists := entries[event.StepID]
+		if !exists {
+			continue
+		}
+
+		switch event.Type {
+		case EventStepStarted:
+			if entry.Start.IsZero() {
+				entry.Start = event.Time
+			}
+			entry.Status = timelineRunning
+		case EventStepRetried:
+			entry.Retries = event.Attempt - 1
+		case EventStepSucceeded:
+			entry.Status = timelineSucceeded
+			entry.End = event.Time
+		case EventStepFailed:
+			entry.Status = timelineFailed
+			entry.End = event.Time
+			entry.Error = event.Error
+			if entry.Start.IsZero() {
+				entry.Start = event.Time
+			}
+		case EventStepSkipped:
+			entry.Status = timelineSkipped
+			entry.Start = event.Time
+			entry.End = event.Time
+		}
+	}
+
+	return timeline
+}
+
+// timelineBars maps entry statuses to the character their bar is drawn
+// with. Compensations that succeeded are drawn with '<'.
+var timelineBars = map[string]byte{
+	timelineSucceeded: '=',
+	timelineFailed:    'x',
+	timelineSkipped:   '-',
+	timelineRunning:   '>',
+}
+
+// Render writes the timeline as a text Gantt chart whose bars span width
+// columns.
+func (t *Timeline) Render(w io.Writer, width int) error {
+	if width < 10 {
+		width = 10
+	}
+
+	total := t.End.Sub(t.Start)
+
+	nameWidth := len("STEP")
+	for _, entry := range append(append([]*TimelineEntry(nil), t.Steps...), t.Rollbacks...) {
+		nameWidth = max(nameWidth, len(entry.StepID))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Workflow %s  %s  %s\n\n", t.WorkflowID, t.Status, formatTimelineDuration(total))
+
+	scaleEnd := formatTimelineDuration(total)
+	scale := "0s" + strings.Repeat(" ", max(width-2-len(scaleEnd), 1)) + scaleEnd
+	fmt.Fprintf(&b, "%-5s  %-*s  |%s|\n", "LEVEL", nameWidth, "STEP", scale)
+
+	for _, entry := range t.Steps {
+		fmt.Fprintf(&b, "%-5d  %-*s  |%s|  %s\n", entry.Level, nameWidth, entry.StepID, t.bar(entry, width, timelineBars[entry.Status]), entryDetails(entry))
+	}
+
+	if len(t.Rollbacks) > 0 {
+		fmt.Fprintf(&b, "\nROLLBACK\n")
+		for _, entry := range t.Rollbacks {
+			char := byte('<')
+			if entry.Status != string(CompensationSucceeded) {
+				char = timelineBars[entry.Status]
+			}
+			fmt.Fprintf(&b, "%-5s  %-*s  |%s|  %s\n", "", nameWidth, entry.StepID, t.bar(entry, width, char), entryDetails(entry))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bar draws an entry's span of the timeline with char.
+func (t *Timeline) bar(entry *TimelineEntry, width int, char byte) string {
+	line := []byte(strings.Repeat(" ", width))
+	if entry.Start.IsZero() {
+		return string(line)
+	}
+
+	end := entry.End
+	if end.IsZero() {
+		end = t.End
+	}
+
+	total := t.End.Sub(t.Start)
+	column := func(at time.Time) int {
+		if total <= 0 {
+			return 0
+		}
+		return int(float64(at.Sub(t.Start)) / float64(total) * float64(width))
+	}
+
+	from := min(column(entry.Start), width-1)
+	to := min(max(column(end), from+1), width)
+
+	if char == 0 {
+		char = '?'
+	}
+	for i := from; i < to; i++ {
+		line[i] = char
+	}
+	return string(line)
+}
+
+// entryDetails returns the text shown after an entry's bar.
+func entryDetails(entry *TimelineEntry) string {
+	details := []string{formatTimelineDuration(entry.Duration())}
+	switch entry.Retries {
+	case 0:
+	case 1:
+		details = append(details, "1 retry")
+	default:
+		details = append(details, fmt.Sprintf("%d retries", entry.Retries))
+	}
+	switch entry.Status {
+	case timelineSucceeded:
+	case timelineFailed:
+		if entry.Error != "" {
+			details = append(details, "failed: "+entry.Error)
+		} else {
+			details = append(details, "failed")
+		}
+	default:
+		details = append(details, entry.Status)
+	}
+	return strings.Join(details, "  ")
+}
+
+// formatTimelineDuration formats a duration for the chart.
+func formatTimelineDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Millisecond).String()
+}
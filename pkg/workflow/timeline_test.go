@@ -0,0 +1,70 @@
+package workflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	events := []*Event{
+		{Time: at(0), Type: EventWorkflowStarted, WorkflowID: "workflow-1"},
+		{Time: at(0), Type: EventStepScheduled, StepID: "create", Level: 0},
+		{Time: at(0), Type: EventStepStarted, StepID: "create", Attempt: 1},
+		{Time: at(200), Type: EventStepRetried, StepID: "create", Attempt: 2},
+		{Time: at(500), Type: EventStepSucceeded, StepID: "create", Attempt: 2},
+		{Time: at(500), Type: EventStepScheduled, StepID: "verify", Level: 1},
+		{Time: at(500), Type: EventStepStarted, StepID: "verify", Attempt: 1},
+		{Time: at(600), Type: EventStepStarted, StepID: "nested", ParentID: "verify"},
+		{Time: at(800), Type: EventStepFailed, StepID: "verify", Error: "HTTP 500"},
+		{Time: at(800), Type: EventWorkflowFailed, Error: "required step verify failed"},
+		{Time: at(1000), Type: EventStepRolledBack, StepID: "create", Duration: 200 * time.Millisecond, Attempt: 1, Status: "succeeded"},
+	}
+
+	timeline := BuildTimeline(events)
+
+	if timeline.WorkflowID != "workflow-1" || timeline.Status != "failed" {
+		t.Errorf("timeline = %s %s, want workflow-1 failed", timeline.WorkflowID, timeline.Status)
+	}
+	if len(timeline.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(timeline.Steps))
+	}
+
+	create := timeline.Steps[0]
+	if create.Retries != 1 || create.Duration() != 500*time.Millisecond || create.Status != "succeeded" {
+		t.Errorf("create = %+v", create)
+	}
+	verify := timeline.Steps[1]
+	if verify.Level != 1 || verify.Status != "failed" || verify.Error != "HTTP 500" {
+		t.Errorf("verify = %+v", verify)
+	}
+	if len(timeline.Rollbacks) != 1 || !timeline.Rollbacks[0].Start.Equal(at(800)) {
+		t.Errorf("rollbacks = %+v", timeline.Rollbacks)
+	}
+
+	var out bytes.Buffer
+	if err := timeline.Render(&out, 20); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+
+	wantLines := []string{
+		"Workflow workflow-1  failed  1s",
+		"",
+		"LEVEL  STEP    |0s                1s|",
+		"0      create  |==========          |  500ms  1 retry",
+		"1      verify  |          xxxxxx    |  300ms  failed: HTTP 500",
+		"",
+		"ROLLBACK",
+		"       create  |                <<<<|  200ms",
+	}
+	for i, want := range wantLines {
+		if i >= len(lines) || lines[i] != want {
+			t.Errorf("line %d = %q, want %q\n%s", i, lines[min(i, len(lines)-1)], want, out.String())
+		}
+	}
+}
@@ -12,10 +12,15 @@
 //   - loop: Iterate over collections
 //   - wait: Delay or poll for status changes
 //   - parallel: Execute multiple steps concurrently
+//   - prompt: Ask the user for input or approval mid-run
+//   - set: Compute variables with expressions
+//   - assert: Fail with a custom message when an expression is false
+//   - call: Run another named workflow and return its outputs
 //
 // # Workflow Features
 //
 //   - DAG-based dependency resolution
+//   - Bounded concurrency and per-host rate limiting
 //   - Automatic retry with exponential backoff
 //   - Rollback actions for failed steps
 //   - Output mapping between steps
@@ -53,14 +58,30 @@ import (
 type Workflow struct {
 	Steps    []*Step   `json:"steps"`
 	Settings *Settings `json:"settings,omitempty"`
+
+	// Outputs maps output names to expressions evaluated when the workflow
+	// completes. They are returned to callers of a call step.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// Workflows holds named sub-workflows that call steps can invoke.
+	Workflows map[string]*Workflow `json:"workflows,omitempty"`
 }
 
 // Settings contains workflow-level configuration.
 type Settings struct {
-	ParallelExecution bool `json:"parallel-execution,omitempty"`
-	FailFast          bool `json:"fail-fast,omitempty"`
-	Timeout           int  `json:"timeout,omitempty"` // seconds
-	DryRunSupported   bool `json:"dry-run-supported,omitempty"`
+	ParallelExecution bool             `json:"parallel-execution,omitempty"`
+	FailFast          bool             `json:"fail-fast,omitempty"`
+	Timeout           int              `json:"timeout,omitempty"` // seconds
+	DryRunSupported   bool             `json:"dry-run-supported,omitempty"`
+	MaxConcurrency    int              `json:"max-concurrency,omitempty"`
+	RateLimit         *RateLimitConfig `json:"rate-limit,omitempty"`
+	RollbackRetry     *RetryConfig     `json:"rollback-retry,omitempty"`
+}
+
+// RateLimitConfig configures the per-host token-bucket rate limiter.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests-per-second"`
+	Burst             int     `json:"burst,omitempty"`
 }
 
 // Step represents a single workflow step.
@@ -89,6 +110,10 @@ type Step struct {
 	Loop        *LoopStep        `json:"loop,omitempty"`
 	Wait        *WaitStep        `json:"wait,omitempty"`
 	Parallel    *ParallelStep    `json:"parallel,omitempty"`
+	Prompt      *PromptStep      `json:"prompt,omitempty"`
+	Set         *SetStep         `json:"set,omitempty"`
+	Assert      *AssertStep      `json:"assert,omitempty"`
+	Call        *CallStep        `json:"call,omitempty"`
 }
 
 // StepType defines the type of workflow step.
@@ -109,8 +134,27 @@ const (
 	StepTypeParallel StepType = "parallel"
 	// StepTypeNoop represents a no-operation step.
 	StepTypeNoop StepType = "noop"
+	// StepTypePrompt represents a step that asks the user for input or approval.
+	StepTypePrompt StepType = "prompt"
+	// StepTypeSet represents a step that computes workflow variables.
+	StepTypeSet StepType = "set"
+	// StepTypeAssert represents a step that fails when an expression is false.
+	StepTypeAssert StepType = "assert"
+	// StepTypeCall represents a step that runs another named workflow.
+	StepTypeCall StepType = "call"
 )
 
+// HasSideEffects reports whether steps of this type can change external
+// state. Steps without side effects never need a rollback action.
+func (t StepType) HasSideEffects() bool {
+	switch t {
+	case StepTypePrompt, StepTypeSet, StepTypeAssert, StepTypeNoop:
+		return false
+	default:
+		return true
+	}
+}
+
 // RetryConfig defines retry behavior for a step.
 type RetryConfig struct {
 	MaxAttempts     int            `json:"max-attempts,omitempty"`
@@ -169,9 +213,11 @@ type ConditionalStep struct {
 
 // LoopStep defines an iteration step.
 type LoopStep struct {
-	Iterator   string  `json:"iterator"`
-	Collection string  `json:"collection"`
-	Steps      []*Step `json:"steps"`
+	Iterator       string  `json:"iterator"`
+	Collection     string  `json:"collection"`
+	Steps          []*Step `json:"steps"`
+	Parallel       bool    `json:"parallel,omitempty"`
+	MaxConcurrency int     `json:"max-concurrency,omitempty"`
 }
 
 // WaitStep defines a waiting/polling step.
@@ -192,7 +238,38 @@ type PollingConfig struct {
 
 // ParallelStep defines concurrent execution of multiple steps.
 type ParallelStep struct {
-	Steps []*Step `json:"steps"`
+	Steps          []*Step `json:"steps"`
+	MaxConcurrency int     `json:"max-concurrency,omitempty"`
+}
+
+// PromptStep defines a step that asks the user for input or approval.
+type PromptStep struct {
+	// Type is one of text, password, select, confirm, number or approval.
+	// An approval prompt fails the step unless the user agrees.
+	Type       string      `json:"type"`
+	Message    string      `json:"message"`
+	Variable   string      `json:"variable,omitempty"`
+	Default    interface{} `json:"default,omitempty"`
+	Options    []string    `json:"options,omitempty"`
+	Validation string      `json:"validation,omitempty"`
+	Required   bool        `json:"required,omitempty"`
+}
+
+// SetStep defines a step that assigns expression results to variables.
+type SetStep struct {
+	Variables map[string]string `json:"variables"`
+}
+
+// AssertStep defines a step that fails when its condition is false.
+type AssertStep struct {
+	Condition string `json:"condition"`
+	Message   string `json:"message,omitempty"`
+}
+
+// CallStep defines a step that runs another named workflow.
+type CallStep struct {
+	Workflow string                 `json:"workflow"`
+	Inputs   map[string]interface{} `json:"inputs,omitempty"`
 }
 
 // StepResult represents the result of executing a step.
@@ -200,7 +277,7 @@ type StepResult struct {
 	StepID    string
 	Success   bool
 	Output    map[string]interface{}
-	Error     error
+	Error     error `json:"-"`
 	Retries   int
 	StartTime time.Time
 	EndTime   time.Time
@@ -214,7 +291,11 @@ type ExecutionState struct {
 	Status         ExecutionStatus
 	CompletedSteps []*StepResult
 	CurrentStep    string
-	Error          error
+	Error          error `json:"-"`
+	ErrorMessage   string
+
+	// Rollback is the report of the compensations run after a failure.
+	Rollback *RollbackReport `json:",omitempty"`
 }
 
 // ExecutionStatus defines the status of workflow execution.
@@ -245,6 +326,10 @@ type DAGNode struct {
 	Dependencies []string
 	Dependents   []string
 	Level        int // Depth in the graph for topological ordering
+
+	// Parent is the ID of the loop, parallel or conditional step that
+	// contains this step; empty for top-level steps.
+	Parent string
 }
 
 // RollbackAction represents a rollback action for a step.
@@ -252,4 +337,18 @@ type RollbackAction struct {
 	StepID string
 	Action *Step
 	Result *StepResult
+
+	// Context is the execution context the action runs in. It is set for
+	// actions recorded by a called sub-workflow, a loop iteration or a
+	// parallel branch; nil means the context of the workflow being rolled
+	// back.
+	Context *ExecutionContext
+
+	// ParentID is the loop, parallel, conditional or call step that ran
+	// StepID as a nested step; empty for top-level steps.
+	ParentID string
+
+	// Iteration is the 1-based loop iteration that recorded the action,
+	// or 0 outside loops.
+	Iteration int
 }